@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// compareOpts holds the settings shared by every variant
+	compareOpts converter.Options
+	// compareVariants are the settings of each variant, e.g. "colors=64,dither=none"
+	compareVariants []string
+)
+
+// variantResult is the output of a variant of a settings comparison
+type variantResult struct {
+	Variant string
+	File    string
+	Bytes   int64
+	SSIM    float64
+	PSNR    float64
+}
+
+var compareSettingsCmd = &cobra.Command{
+	Use:   "compare-settings",
+	Short: "Convert with several settings and compare their size and quality",
+	Long: `Convert the same PNG images once per --variant, writing out.variant-1.gif, out.variant-2.gif...,
+and list the size of each output with its SSIM and PSNR quality scores against the frames before quantization.
+A variant is a comma separated list of convert settings, e.g. "colors=64,dither=none".`,
+	Example: `  # Compare 64 colors without dithering to 256 dithered colors
+  go-togif compare-settings -i "*.png" -o demo.gif --variant "colors=64,dither=none" --variant "colors=256,dither=fs"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPattern, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+		outputFile, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		if len(compareVariants) == 0 {
+			return fmt.Errorf("at least one --variant is required")
+		}
+
+		// Check every variant before converting any
+		base := compareOpts
+		base.Score = true
+		variants := make([]converter.Options, len(compareVariants))
+		for i, variant := range compareVariants {
+			if variants[i], err = converter.ApplyVariant(base, variant); err != nil {
+				return fmt.Errorf("variant %d: %v", i+1, err)
+			}
+		}
+
+		inputFiles, delays, err := expandInputFiles(inputPattern)
+		if err != nil {
+			return err
+		}
+
+		results := make([]variantResult, len(variants))
+		for i, opts := range variants {
+			opts.FrameDelays = delays
+			report, err := converter.Convert(inputFiles, converter.VariantPath(outputFile, i+1), opts)
+			if err != nil {
+				return fmt.Errorf("variant %d: %v", i+1, err)
+			}
+			info, err := os.Stat(report.OutputFile)
+			if err != nil {
+				return fmt.Errorf("error checking output size: %v", err)
+			}
+			results[i] = variantResult{Variant: compareVariants[i], File: report.OutputFile, Bytes: info.Size(), SSIM: report.SSIM, PSNR: report.PSNR}
+		}
+
+		return printComparison(cmd.OutOrStdout(), results)
+	},
+}
+
+// printComparison writes the size and quality of every variant
+func printComparison(w io.Writer, results []variantResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIANT\tSIZE\tSSIM\tPSNR\tFILE")
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%.4f\t%.1f dB\t%s\n", result.Variant, formatBytes(result.Bytes), result.SSIM, result.PSNR, result.File)
+	}
+	return tw.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(compareSettingsCmd)
+
+	// Add flags
+	compareSettingsCmd.Flags().StringP("input", "i", "", "Input PNG, WebP, BMP or TIFF file(s) pattern, or @file listing one file per line (required)")
+	compareSettingsCmd.Flags().StringP("output", "o", "", "Output GIF file path, each variant is written next to it as out.variant-N.gif (required)")
+	compareSettingsCmd.Flags().StringArrayVar(&compareVariants, "variant", nil, "Settings of a variant as key=value pairs, repeated per variant, e.g. \"colors=64,dither=none\"")
+	addPatternFlags(compareSettingsCmd)
+	compareSettingsCmd.Flags().BoolVar(&compareOpts.IgnoreSidecars, "ignore-sidecars", false, "Ignore the per-frame options files next to the inputs (frame.png.json)")
+	compareSettingsCmd.Flags().IntVarP(&compareOpts.Delay, "delay", "d", 100, "Delay between frames in milliseconds, unless set by a variant")
+
+	// Mark required flags
+	compareSettingsCmd.MarkFlagRequired("input")
+	compareSettingsCmd.MarkFlagRequired("output")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareSettingsCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for i, name := range []string{"frame1.png", "frame2.png"} {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+		for x := 0; x < 16; x++ {
+			img.Set(x, i, color.RGBA{uint8(x * 16), 0, 0, 255})
+		}
+		file, err := os.Create(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatalf("Failed to encode frame: %v", err)
+		}
+		file.Close()
+	}
+	outputFile := filepath.Join(tempDir, "demo.gif")
+
+	tests := []struct {
+		name      string
+		variants  []string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{name: "two variants", variants: []string{"colors=4,dither=none", "colors=256,dither=fs"}, wantFiles: []string{"demo.variant-1.gif", "demo.variant-2.gif"}},
+		{name: "invalid variant", variants: []string{"colors=4", "speed=2"}, wantErr: true},
+		{name: "no variant", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compareVariants = nil
+			args := []string{"compare-settings", "-i", filepath.Join(tempDir, "frame*.png"), "-o", outputFile}
+			for _, variant := range tt.variants {
+				args = append(args, "--variant", variant)
+			}
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(args)
+			defer rootCmd.SetArgs(nil)
+
+			err := rootCmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compare-settings error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tt.wantFiles)+1 || !strings.HasPrefix(lines[0], "VARIANT") {
+				t.Fatalf("compare-settings output = %q, want a header and %d variants", out.String(), len(tt.wantFiles))
+			}
+			for i, name := range tt.wantFiles {
+				if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+					t.Errorf("variant %d output: %v", i+1, err)
+				}
+				if !strings.HasPrefix(lines[i+1], tt.variants[i]) || !strings.HasSuffix(lines[i+1], name) {
+					t.Errorf("line %d = %q, want variant %q and %s", i+1, lines[i+1], tt.variants[i], name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// confirmFrames is the number of frames above which a conversion asks for confirmation
+	confirmFrames = 1000
+	// confirmBytes is the estimated output size above which a conversion asks for confirmation
+	confirmBytes = 100 << 20
+)
+
+// interactive reports whether the user can answer a prompt, replaced in tests
+var interactive = func() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether file is a terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmLargeJob asks before converting more than confirmFrames frames or into
+// a GIF estimated above confirmBytes. It only prompts in an interactive
+// terminal, scripts and pipelines are never blocked.
+func confirmLargeJob(cmd *cobra.Command, inputFiles []string, opts converter.Options) error {
+	if !interactive() {
+		return nil
+	}
+
+	var reasons []string
+	if len(inputFiles) > confirmFrames {
+		reasons = append(reasons, fmt.Sprintf("%d frames", len(inputFiles)))
+	}
+	// The estimate is only a hint, conversion errors are reported by the conversion itself
+	if estimate, err := converter.EstimateConversion(inputFiles, opts); err == nil && estimate.Bytes > confirmBytes {
+		reasons = append(reasons, fmt.Sprintf("an estimated %s GIF", formatBytes(estimate.Bytes)))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "This conversion has %s. Continue? [y/N] ", strings.Join(reasons, " and "))
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("conversion cancelled, use --yes to skip the confirmation")
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+func TestConfirmLargeJob(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Missing frames only make the estimate fail, the frame count still asks
+	var many []string
+	for i := 0; i <= confirmFrames; i++ {
+		many = append(many, filepath.Join(tempDir, fmt.Sprintf("frame%04d.png", i)))
+	}
+
+	defer func(previous func() bool) { interactive = previous }(interactive)
+
+	tests := []struct {
+		name        string
+		interactive bool
+		files       []string
+		answer      string
+		wantPrompt  bool
+		wantErr     bool
+	}{
+		{name: "not a terminal", files: many},
+		{name: "small job", interactive: true, files: many[:3]},
+		{name: "confirmed", interactive: true, files: many, answer: "y\n", wantPrompt: true},
+		{name: "declined", interactive: true, files: many, answer: "n\n", wantPrompt: true, wantErr: true},
+		{name: "no answer", interactive: true, files: many, wantPrompt: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactive = func() bool { return tt.interactive }
+			var out bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&out)
+			cmd.SetIn(strings.NewReader(tt.answer))
+
+			err := confirmLargeJob(cmd, tt.files, converter.Options{Delay: 100})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("confirmLargeJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if prompted := strings.Contains(out.String(), "Continue?"); prompted != tt.wantPrompt {
+				t.Errorf("confirmLargeJob() output = %q, want prompt %v", out.String(), tt.wantPrompt)
+			}
+		})
+	}
+}
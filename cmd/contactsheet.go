@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+// sheetOpts holds the layout bound to the contact-sheet flags
+var sheetOpts converter.SheetOptions
+
+var contactSheetCmd = &cobra.Command{
+	Use:   "contact-sheet <input.gif>",
+	Short: "Lay the frames of a GIF out in a grid image",
+	Long: `Lay every Nth frame of a GIF out in a grid PNG image, each labeled with its frame index and when it
+is shown, to review a long animation at a glance. By default up to 36 evenly spaced frames are shown, in a grid
+about as wide as tall.`,
+	Example: `  # Review a long animation at a glance
+  go-togif contact-sheet demo.gif -o sheet.png
+
+  # Every 10th frame, 6 per row, as 160 pixel wide thumbnails
+  go-togif contact-sheet demo.gif -o sheet.png --every 10 --columns 6 --thumb-width 160`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFile, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		sheet, err := converter.WriteContactSheet(args[0], outputFile, sheetOpts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d of %d frames in a %dx%d grid (%dx%d pixels) to %s\n",
+			len(sheet.Tiles), sheet.Frames, sheet.Columns, sheet.Rows, sheet.Width, sheet.Height, outputFile)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(contactSheetCmd)
+
+	// Add flags
+	contactSheetCmd.Flags().StringP("output", "o", "", "Output PNG image")
+	contactSheetCmd.MarkFlagRequired("output")
+	contactSheetCmd.Flags().IntVar(&sheetOpts.Every, "every", 0, "Show every Nth frame, starting with the first one (default: enough to show at most 36 frames)")
+	contactSheetCmd.Flags().IntVar(&sheetOpts.Columns, "columns", 0, "Thumbnails per row (default: a grid about as wide as tall)")
+	contactSheetCmd.Flags().IntVar(&sheetOpts.ThumbWidth, "thumb-width", 0, "Width of every thumbnail in pixels (default: the frame width, up to 320)")
+}
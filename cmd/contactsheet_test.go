@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+func TestContactSheetCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFile := filepath.Join(tempDir, "bars.gif")
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"generate", "testpattern", "--pattern", "bars", "--width", "16", "--height", "8", "--frames", "6", "-o", inputFile})
+	defer rootCmd.SetArgs(nil)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("generate error = %v", err)
+	}
+
+	var out bytes.Buffer
+	outputFile := filepath.Join(tempDir, "sheet.png")
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"contact-sheet", inputFile, "-o", outputFile, "--every", "2", "--columns", "3"})
+	err = rootCmd.Execute()
+	sheetOpts = converter.SheetOptions{}
+	if err != nil {
+		t.Fatalf("contact-sheet error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Wrote 3 of 6 frames in a 3x1 grid") {
+		t.Errorf("output = %q, want 3 of 6 frames in a 3x1 grid", out.String())
+	}
+	if _, err := os.Stat(outputFile); err != nil {
+		t.Errorf("contact sheet not written: %v", err)
+	}
+}
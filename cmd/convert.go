@@ -1,22 +1,134 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"image"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/overlay"
+	"github.com/jparrill/go-togif/pkg/termrender"
 	"github.com/spf13/cobra"
 )
 
 var (
-	delay int
-	debug bool
+	// convertOpts holds the conversion settings bound to the command flags
+	convertOpts converter.Options
+	// startAt and endAt are parsed into convertOpts.Start and convertOpts.End
+	startAt string
+	endAt   string
+	// maxDuration is parsed into convertOpts.MaxDuration
+	maxDuration string
+	// captionColor and captionBackground are parsed into convertOpts.CaptionStyle
+	captionColor      string
+	captionBackground string
+	// cornerColor is parsed into convertOpts.CornerColor
+	cornerColor string
+	// textRegions are parsed into convertOpts.TextRegions
+	textRegions []string
+	// paletteFile is loaded into convertOpts.Palette
+	paletteFile string
+	// chaptersFile is loaded into convertOpts.Chapters
+	chaptersFile string
+	// sizes is parsed into convertOpts.Sizes
+	sizes string
+	// rois are parsed into convertOpts.ROIs
+	rois []string
+	// smartCrop is parsed into convertOpts.SmartCrop
+	smartCrop string
+	// memoryLimit is parsed into convertOpts.MemoryLimit
+	memoryLimit string
+	// reportJSON is where the conversion report is written as JSON
+	reportJSON string
+	// statsFile is the local file a line of usage statistics is appended to
+	// after every conversion
+	statsFile string
+	// fromVideo is the video file whose frames are converted instead of the
+	// input files
+	fromVideo string
+	// explain prints the resolved pipeline before converting
+	explain bool
+	// assumeYes skips the confirmation of large jobs
+	assumeYes bool
 )
 
 var convertCmd = &cobra.Command{
 	Use:   "convert",
-	Short: "Convert PNG images to GIF",
-	Long: `Convert one or more PNG images to a GIF file.
-You can use glob patterns (e.g., "*.png") or regex patterns (e.g., "^frame.*\\.png$") to specify input files.`,
+	Short: "Convert PNG, WebP, BMP or TIFF images to GIF",
+	Long: `Convert one or more PNG, WebP, BMP or TIFF images to a GIF file.
+You can use glob patterns (e.g., "*.png") or regex patterns (e.g., "^frame.*\\.png$") to specify input files.
+An asciinema recording (e.g., "session.cast") is rendered to terminal frames instead,
+and the frames of an animated WebP are converted with their own durations.`,
+	Example: `  # Convert frames matched by a glob pattern, 200ms apart
+  go-togif convert -i "frames/*.png" -o demo.gif -d 200
+
+  # Convert files matched by a regular expression, scaled to 600 pixels wide
+  go-togif convert -i "^frame.*\.png$" -o demo.gif --width 600
+
+  # Convert WebP or TIFF frames
+  go-togif convert -i "frames/*.webp" -o demo.gif
+  go-togif convert -i "scans/*.tif" -o scans.gif
+
+  # Turn an animated WebP into a GIF, keeping its frame durations
+  go-togif convert -i sticker.webp -o sticker.gif
+
+  # Hold frame 31 for two seconds
+  go-togif convert -i "*.png" -o demo.gif --timeline "0-30@100ms,31@2s,32-60@80ms"
+
+  # Hold the first frame of every step of a tutorial, and index the steps
+  go-togif convert -i "*.png" -o tutorial.gif --chapters chapters.yaml --manifest tutorial.json
+
+  # Preview the first 10 frames and check the composition for wide, square and vertical posts
+  go-togif convert -i "*.png" -o demo.gif --preview-frames 10 --guides 16:9,1:1,9:16
+
+  # Loop a screen recording seamlessly, ending it where it looks the most like its start
+  go-togif convert -i "*.png" -o demo.gif --loop-search 30 --smooth-loop 6
+
+  # Show when the narration of a screen recording speaks
+  go-togif convert -i "*.png" -o demo.gif --waveform narration.wav
+
+  # Zoom 3 times into where the activity is on a large desktop capture
+  go-togif convert -i "*.png" -o demo.gif --magnify-changes 3 --magnify-corner top-right
+
+  # Keep exactly one turn of a captured spinner
+  go-togif convert -i "spinner/*.png" -o spinner.gif --find-loop
+
+  # Fit a Slack emoji
+  go-togif convert -i "*.png" -o emoji.gif --preset slack-emoji
+
+  # One GIF per width, and a WebM next to it
+  go-togif convert -i "*.png" -o demo.gif --sizes 320,640,1280
+  go-togif convert -i "*.png" -o demo.gif --formats gif,webm
+
+  # A long capture as an MP4 instead, picked by the output extension
+  go-togif convert -i "capture/*.png" -o capture.mp4
+
+  # The terminal and browser panes of a screen capture, as demo-terminal.gif and demo-browser.gif
+  go-togif convert -i "*.png" -o demo.gif --roi terminal=0,0,960x1080 --roi browser=960,0,960x1080
+
+  # See the stages, quantizer, encoder and workers the flags resolve to
+  go-togif convert -i "*.png" -o demo.gif --auto --width 640 --explain
+
+  # Save the pipeline and convert other frames with the same settings
+  go-togif convert -i "*.png" -o demo.gif --auto --width 640 --explain > plan.yaml
+  go-togif convert --pipeline plan.yaml -i "next/*.png" -o next.gif
+
+  # Convert in the background on a server and follow the progress
+  nohup go-togif convert -i "frames/*.png" -o demo.gif --progress-log convert.log &
+  tail -f convert.log
+
+  # The first 5 seconds of a screen recording, at 15 frames per second
+  go-togif convert --from-video recording.mp4 -o demo.gif --video-fps 15 --end 5s
+
+  # Render an asciinema recording, shortening its pauses to two seconds
+  go-togif convert -i session.cast -o session.gif --term-font-size 14 --trim-idle 2s`,
+	// The flags of a saved pipeline count for the required ones
+	PreRunE: loadPipeline,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get input pattern from flag
 		inputPattern, err := cmd.Flags().GetString("input")
@@ -30,32 +142,386 @@ You can use glob patterns (e.g., "*.png") or regex patterns (e.g., "^frame.*\\.p
 			return err
 		}
 
-		// Expand input pattern
-		inputFiles, err := converter.ExpandInputPattern(inputPattern)
-		if err != nil {
-			return fmt.Errorf("error expanding pattern %s: %v", inputPattern, err)
+		// Parse the trimming window
+		if convertOpts.Start, err = converter.ParseTimestamp(startAt); err != nil {
+			return fmt.Errorf("invalid --start: %v", err)
+		}
+		if convertOpts.End, err = converter.ParseTimestamp(endAt); err != nil {
+			return fmt.Errorf("invalid --end: %v", err)
+		}
+		if convertOpts.MaxDuration, err = converter.ParseTimestamp(maxDuration); err != nil {
+			return fmt.Errorf("invalid --max-duration: %v", err)
+		}
+
+		// Parse the caption colors
+		if captionColor != "" {
+			if convertOpts.CaptionStyle.Foreground, err = overlay.ParseHexColor(captionColor); err != nil {
+				return fmt.Errorf("invalid --caption-color: %v", err)
+			}
+		}
+		if captionBackground != "" {
+			if convertOpts.CaptionStyle.Background, err = overlay.ParseHexColor(captionBackground); err != nil {
+				return fmt.Errorf("invalid --caption-background: %v", err)
+			}
+		}
+
+		// Parse the color outside the rounded corners
+		convertOpts.CornerColor = nil
+		if cornerColor != "" {
+			if convertOpts.CornerColor, err = overlay.ParseHexColor(cornerColor); err != nil {
+				return fmt.Errorf("invalid --corner-color: %v", err)
+			}
+		}
+
+		// Parse the text regions
+		convertOpts.TextRegions = nil
+		for _, region := range textRegions {
+			r, err := converter.ParseRegion(region)
+			if err != nil {
+				return fmt.Errorf("invalid --text-region: %v", err)
+			}
+			convertOpts.TextRegions = append(convertOpts.TextRegions, r)
+		}
+
+		if convertOpts.AutoAdjust && convertOpts.Check == "" {
+			return fmt.Errorf("--auto-adjust needs --check")
+		}
+
+		// Parse the smart crop window
+		convertOpts.SmartCrop = image.Point{}
+		if smartCrop != "" {
+			w, h, err := converter.ParseSize(smartCrop)
+			if err != nil {
+				return fmt.Errorf("invalid --smart-crop: %v", err)
+			}
+			convertOpts.SmartCrop = image.Pt(w, h)
+		}
+
+		// Parse the output sizes
+		if convertOpts.Sizes, err = converter.ParseSizes(sizes); err != nil {
+			return fmt.Errorf("invalid --sizes: %v", err)
+		}
+
+		// Parse the regions of interest
+		convertOpts.ROIs = nil
+		for _, spec := range rois {
+			roi, err := converter.ParseROI(spec)
+			if err != nil {
+				return fmt.Errorf("invalid --roi: %v", err)
+			}
+			convertOpts.ROIs = append(convertOpts.ROIs, roi)
+		}
+
+		// Parse the memory limit
+		if convertOpts.MemoryLimit, err = converter.ParseMemoryLimit(memoryLimit); err != nil {
+			return fmt.Errorf("invalid --memory-limit: %v", err)
+		}
+
+		// Load the palette to reuse
+		convertOpts.Palette = nil
+		if paletteFile != "" {
+			if convertOpts.Palette, err = converter.ReadPalette(paletteFile); err != nil {
+				return err
+			}
+		}
+
+		// Load the chapters to hold
+		convertOpts.Chapters = nil
+		if chaptersFile != "" {
+			if convertOpts.Chapters, err = converter.LoadChapters(chaptersFile); err != nil {
+				return err
+			}
+		}
+
+		// Without --format, the extension of the output picks its format, so
+		// that -o demo.mp4 writes an MP4 rather than a GIF named demo.mp4
+		if !cmd.Flags().Changed("format") {
+			convertOpts.Format = ""
+			if len(convertOpts.Formats) == 0 && convertOpts.Preset == "" {
+				convertOpts.Format = converter.FormatForPath(outputFile)
+			}
 		}
 
-		// Validate input files
-		if err := converter.ValidateInputFiles(inputFiles); err != nil {
+		// Check the options before expanding the inputs, telling right away
+		// which ones the others make useless
+		optionWarnings, err := converter.ValidateOptions(convertOpts)
+		if err != nil {
 			return err
 		}
+		for _, warning := range optionWarnings {
+			fmt.Fprintf(cmd.OutOrStdout(), "Warning: %s\n", warning)
+		}
+
+		// Terminal recordings are rendered and videos extracted by the converter,
+		// PNG patterns are expanded
+		var inputFiles []string
+		if fromVideo != "" {
+			inputFiles = []string{fromVideo}
+		} else if converter.IsCastFile(inputPattern) {
+			inputFiles = []string{inputPattern}
+		} else {
+			// Expand and validate the input pattern or manifest
+			if inputFiles, convertOpts.FrameDelays, err = expandInputFiles(inputPattern); err != nil {
+				return err
+			}
+		}
+
+		// Show the pipeline the flags resolve to
+		if explain {
+			plan := converter.Explain(len(inputFiles), outputFile, convertOpts)
+			plan.Command = commandLine(cmd)
+			if err := plan.WriteYAML(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+		}
+
+		// Ask before an unusually large job, an overly broad glob can take hours;
+		// previews are quick whatever the input
+		if !assumeYes && convertOpts.PreviewFrames == 0 {
+			if err := confirmLargeJob(cmd, inputFiles, convertOpts); err != nil {
+				return err
+			}
+		}
 
 		// Convert files
-		return converter.ConvertPNGsToGIF(inputFiles, outputFile, delay, debug)
+		start := time.Now()
+		var report *converter.Report
+		if fromVideo != "" {
+			report, err = converter.ConvertVideo(fromVideo, outputFile, convertOpts)
+		} else {
+			report, err = converter.Convert(inputFiles, outputFile, convertOpts)
+		}
+		if statsFile != "" {
+			if statsErr := appendStats(statsFile, newStatsRecord(start, len(inputFiles), convertOpts, report, err)); statsErr != nil && err == nil {
+				return statsErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		printReport(cmd.OutOrStdout(), report)
+		if convertOpts.HashName {
+			for _, output := range report.Outputs {
+				fmt.Fprintf(cmd.OutOrStdout(), "Output file: %s\n", output.File)
+			}
+		}
+		if reportJSON != "" {
+			return writeReportJSON(reportJSON, report)
+		}
+		return nil
 	},
 }
 
+// writeReportJSON writes the conversion report as indented JSON
+func writeReportJSON(path string, report *converter.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding report: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing report: %v", err)
+	}
+	return nil
+}
+
+// printReport writes a short summary of the generated GIF
+func printReport(w io.Writer, report *converter.Report) {
+	if len(report.Outputs) > 1 {
+		for _, output := range report.Outputs {
+			fmt.Fprintf(w, "Output: %s (%dx%d, %d frames)\n", output.File, output.Width, output.Height, report.Frames)
+		}
+	} else {
+		fmt.Fprintf(w, "Output: %dx%d, %d frames\n", report.Width, report.Height, report.Frames)
+	}
+	for _, output := range report.Outputs {
+		if output.Poster != "" {
+			fmt.Fprintf(w, "Poster: %s\n", output.Poster)
+		}
+		if output.Proof != "" {
+			fmt.Fprintf(w, "Proof: %s\n", output.Proof)
+		}
+	}
+	if report.Snippet != "" {
+		fmt.Fprintf(w, "Embed snippet: %s\n", report.Snippet)
+	}
+	if report.SSIM != 0 {
+		fmt.Fprintf(w, "Quality: SSIM %.4f, PSNR %.1f dB\n", report.SSIM, report.PSNR)
+	}
+	if report.Preview {
+		fmt.Fprintf(w, "Preview: %s, the first %d frames only; run again without --preview-frames for the full output\n", report.OutputFile, report.Frames)
+	}
+	if report.Guides != "" {
+		fmt.Fprintf(w, "Guides: %s\n", report.Guides)
+	}
+	if report.ResumedFrames > 0 {
+		fmt.Fprintf(w, "Resumed: %d quantized frames reused from the checkpoint\n", report.ResumedFrames)
+	}
+	if report.PeakRSS > 0 {
+		fmt.Fprintf(w, "Memory: peak heap %s, peak RSS %s\n", formatBytes(int64(report.PeakHeap)), formatBytes(int64(report.PeakRSS)))
+	} else {
+		fmt.Fprintf(w, "Memory: peak heap %s\n", formatBytes(int64(report.PeakHeap)))
+	}
+	if report.SpilledFrames > 0 {
+		fmt.Fprintf(w, "Spilled: %d frames moved to disk to stay below the memory limit\n", report.SpilledFrames)
+	}
+	for _, dir := range report.KeptTemp {
+		fmt.Fprintf(w, "Temporary files kept: %s\n", dir)
+	}
+	if report.Auto != nil {
+		fmt.Fprintf(w, "Auto: %s\n", report.Auto)
+	}
+	for _, segment := range report.Segments {
+		fmt.Fprintf(w, "Segment: %s\n", segment)
+	}
+	// Densities are compared as printed, to the DPI
+	effective, source := math.Round(report.EffectiveDPI), math.Round(report.SourceDPI)
+	switch {
+	case source == 0:
+		fmt.Fprintf(w, "Effective density: unknown (no pHYs chunk in input)\n")
+	case effective != source:
+		fmt.Fprintf(w, "Effective density: %.0f DPI (source %.0f DPI)\n", effective, source)
+	default:
+		fmt.Fprintf(w, "Effective density: %.0f DPI\n", source)
+	}
+	for _, client := range report.Clients {
+		if client.Animates {
+			fmt.Fprintf(w, "Email client: %s animates\n", client.Client)
+		} else {
+			fmt.Fprintf(w, "Email client: %s shows %s\n", client.Client, client.Note)
+		}
+	}
+	for _, preview := range report.A11yPreviews {
+		fmt.Fprintf(w, "Accessibility preview: %s\n", preview)
+	}
+	for _, warning := range report.Warnings {
+		// Ineffective options are printed before converting
+		if warning.Kind != converter.WarningIneffectiveOption {
+			fmt.Fprintf(w, "Warning: %s\n", warning)
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(convertCmd)
 
 	// Add flags
-	convertCmd.Flags().StringP("input", "i", "", "Input PNG file(s) pattern (required)")
+	convertCmd.Flags().StringP("input", "i", "", "Input PNG, WebP, BMP or TIFF file(s) pattern, or @file listing one file per line (required)")
 	convertCmd.Flags().StringP("output", "o", "", "Output GIF file path (required)")
-	convertCmd.Flags().IntVarP(&delay, "delay", "d", 100, "Delay between frames in milliseconds")
-	convertCmd.Flags().BoolVarP(&debug, "debug", "", false, "Enable debug mode to show detailed progress")
+	addPatternFlags(convertCmd)
+	convertCmd.Flags().BoolVar(&convertOpts.IgnoreSidecars, "ignore-sidecars", false, "Ignore the per-frame options files next to the inputs (frame.png.json)")
+	convertCmd.Flags().IntVarP(&convertOpts.Delay, "delay", "d", 100, "Delay between frames in milliseconds")
+	convertCmd.Flags().BoolVarP(&convertOpts.Debug, "debug", "", false, "Enable debug mode to show detailed progress")
+	convertCmd.Flags().StringVar(&convertOpts.ProgressLog, "progress-log", "", "Append timestamped progress records to this file, to follow a conversion running under nohup or tmux with tail -f")
+	convertCmd.Flags().StringVar(&convertOpts.Trace, "trace", "", "Write a line per frame with its decode time, resizing, colors, quantization error and encoded bytes to this file")
+	convertCmd.Flags().BoolVar(&convertOpts.HashName, "hash-name", false, "Insert a short content hash in the output file names for cache busting (demo.gif -> demo.3fa2c1.gif) and print them")
+	convertCmd.Flags().StringVar(&reportJSON, "report-json", "", "Also write the conversion report, including warnings, to this JSON file")
+	convertCmd.Flags().StringVar(&statsFile, "stats-file", "", "Append a JSON line of settings, timings and sizes to this local file after every conversion, to analyze your own workloads")
+	convertCmd.Flags().BoolVar(&convertOpts.CompatReport, "compat-report", false, "Warn about the features of the GIF outputs known to break in some players: old Android, Outlook, terminals and browsers")
+	convertCmd.Flags().BoolVar(&explain, "explain", false, "Print the resolved pipeline (stages, their parameters, quantizer, encoder and workers) as YAML before converting")
+	convertCmd.Flags().String("pipeline", "", "YAML file printed by --explain whose command line settings are used for the flags not given")
+	convertCmd.Flags().BoolVar(&assumeYes, "yes", false, "Convert without asking for confirmation of large jobs in a terminal")
+	convertCmd.Flags().IntVar(&convertOpts.Width, "width", 0, "Output width in pixels, keeping the aspect ratio (default input width)")
+	convertCmd.Flags().IntVar(&convertOpts.MaxWidth, "max-width", 0, "Scale wider outputs down to this width in pixels, keeping the aspect ratio")
+	convertCmd.Flags().StringVar(&convertOpts.Format, "format", "", "Output format: gif, webm, webp, mp4, avif (builds with libavif), pdf (a frame and its timing per page) or tiff (multi-page) (default: from the output extension, else gif)")
+	convertCmd.Flags().StringSliceVar(&convertOpts.Formats, "formats", nil, "Comma separated output formats written at once, e.g. gif,webp,mp4, writing out.gif, out.webp and out.mp4 from the same frames")
+	convertCmd.Flags().StringVar(&sizes, "sizes", "", "Comma separated output widths, e.g. 320,640,1280, writing out-320.gif, out-640.gif... with a shared palette")
+	convertCmd.Flags().StringArrayVar(&rois, "roi", nil, "Named region of interest as name=x,y,WxH, writing out-name.gif cropped from the same frames with its own palette instead of out.gif (can be specified multiple times)")
+	convertCmd.Flags().IntVar(&convertOpts.Colors, "colors", 0, "Maximum number of palette colors (default 256)")
+	convertCmd.Flags().IntVar(&convertOpts.MaxFrames, "max-frames", 0, "Keep at most this many evenly spaced frames, each shown until the next kept one")
+	convertCmd.Flags().IntVar(&convertOpts.PreviewFrames, "preview-frames", 0, "Quickly encode only the first N frames with every other setting, to out.preview.gif, to try settings before the full run")
+	convertCmd.Flags().StringSliceVar(&convertOpts.Guides, "guides", nil, "Comma separated aspect ratios, e.g. 16:9,1:1,9:16, whose safe areas are drawn over the first frame of a --preview-frames preview to out.preview.guides.png")
+	convertCmd.Flags().BoolVar(&convertOpts.Score, "score", false, "Compare the GIF output to the frames before quantization and print its SSIM and PSNR")
+	convertCmd.Flags().BoolVar(&convertOpts.Verify, "verify", false, "Decode every GIF written and fail when its frames, dimensions, delays or sampled pixels differ from the encoded ones")
+	convertCmd.Flags().Float64Var(&convertOpts.MinSSIM, "min-ssim", 0, "Fail when the SSIM of the GIF output is below this score, from 0 to 1, e.g. 0.95 as a CI quality gate")
+	convertCmd.Flags().BoolVar(&convertOpts.NoExec, "no-exec", false, "Never run external programs: outputs encoded by ffmpeg fail instead, for servers converting untrusted inputs")
+	convertCmd.Flags().DurationVar(&convertOpts.ExecTimeout, "exec-timeout", 0, "Stop an external program such as ffmpeg running longer than this (default 30m)")
+	convertCmd.Flags().StringVar(&convertOpts.Preset, "preset", "", "Fit the output to the size and file limits of a platform: "+presetNames())
+	convertCmd.Flags().IntVar(&convertOpts.Loops, "loops", 0, "Play the animation this many times (default 0, looping forever)")
+	convertCmd.Flags().StringVar(&convertOpts.Check, "check", "", "Fail when the output breaks the constraints of an ad network: iab (standard sizes, 150 KB, 30s, 3 loops)")
+	convertCmd.Flags().BoolVar(&convertOpts.AutoAdjust, "auto-adjust", false, "Adjust the size, loops, duration, frames and colors to pass --check instead of failing")
+	convertCmd.Flags().StringVar(&convertOpts.Quantizer, "quantizer", "", "Palette generation: frequency (most used colors, best for UI) or median-cut (best for photos) (default frequency)")
+	convertCmd.Flags().BoolVar(&convertOpts.Segments, "segments", false, "Quantize runs of slides and photos in a mixed sequence with their own palette, dithering and colors")
+	convertCmd.Flags().StringVar(&convertOpts.Dither, "dither", "", "Dithering: none or floyd-steinberg (default none)")
+	convertCmd.Flags().StringVar(&convertOpts.Scaler, "scaler", "", "Resizing: smooth or nearest (keeps pixel art crisp) (default smooth)")
+	convertCmd.Flags().BoolVar(&convertOpts.Auto, "auto", false, "Detect the content type (UI capture, photo, pixel art) and pick the quantizer, dithering, colors and scaling not set explicitly")
+	convertCmd.Flags().BoolVar(&convertOpts.RespectDPI, "respect-dpi", false, "Downscale high density (e.g. 2x retina) inputs to logical pixels using their PNG pHYs metadata")
+	convertCmd.Flags().IntVar(&convertOpts.PosterFrame, "poster-frame", 0, "Index (0-based) of the frame to show first, used by chat apps as the static preview")
+	convertCmd.Flags().StringVar(&convertOpts.Proof, "proof", "", "Also write the GIF as it looks in a narrower gamut, srgb or p3, next to it (out.gif -> out.proof-srgb.gif)")
+	convertCmd.Flags().StringVar(&convertOpts.SourceGamut, "source-gamut", "", "Gamut the inputs are encoded in: srgb, p3 or rec2020 (default srgb)")
+	convertCmd.Flags().BoolVar(&convertOpts.AutoPoster, "auto-poster", false, "Show the most detailed frame first when the first one has little detail, such as a fade in")
+	convertCmd.Flags().StringVar(&convertOpts.PosterMode, "poster-mode", converter.PosterDuplicate, "How to move the poster frame to the front: duplicate (brief copy, keeps order) or rotate (start the loop at it)")
+	convertCmd.Flags().BoolVar(&convertOpts.WithPoster, "with-poster", false, "Also write the poster frame as a full color PNG next to the GIF (out.gif -> out.png)")
+	convertCmd.Flags().StringVar(&convertOpts.GIFVersion, "gif-version", converter.GIF89a, "GIF version: 89a, or 87a for legacy decoders (no delays, looping or transparency)")
+	convertCmd.Flags().StringSliceVar(&convertOpts.GIFExtensions, "gif-extensions", nil, "GIF89a extension blocks to write: loop, control (default all)")
+	convertCmd.Flags().StringVar(&convertOpts.Encoder, "encoder", converter.EncoderNative, "GIF encoder: native (smaller files) or stdlib (image/gif)")
+	convertCmd.Flags().IntVar(&convertOpts.Workers, "workers", 0, "Frames decoded, transformed, quantized and compressed concurrently (default: number of CPUs)")
+	convertCmd.Flags().IntVar(&convertOpts.IOWorkers, "io-workers", 0, "Input files read concurrently ahead of the processing, independently of --workers (default: one at a time)")
+	convertCmd.Flags().IntVar(&convertOpts.QueueDepth, "queue-depth", converter.DefaultQueueDepth, "Frames waiting between the read, decode and transform stages, bounding the frames in flight but not the transformed frames kept until encoding")
+	convertCmd.Flags().StringVar(&convertOpts.WorkDir, "work-dir", "", "Save the palette and quantized frames here while converting so an interrupted run can be resumed")
+	convertCmd.Flags().BoolVar(&convertOpts.Resume, "resume", false, "Resume the interrupted conversion saved in --work-dir")
+	convertCmd.Flags().StringVar(&convertOpts.TmpDir, "tmp-dir", "", "Directory for temporary files such as frames spilled to disk (default: system temporary directory)")
+	convertCmd.Flags().BoolVar(&convertOpts.KeepTemp, "keep-temp", false, "Keep the temporary files after the conversion for debugging")
+	convertCmd.Flags().StringVar(&memoryLimit, "memory-limit", "", "Soft memory limit, e.g. 512MB or 2G: frames are moved to disk and fewer are encoded at once when it is approached")
+	convertCmd.Flags().IntVar(&convertOpts.InputLimits.MaxWidth, "max-input-width", 0, "Reject input images wider than this many pixels (default 16384)")
+	convertCmd.Flags().IntVar(&convertOpts.InputLimits.MaxHeight, "max-input-height", 0, "Reject input images taller than this many pixels (default 16384)")
+	convertCmd.Flags().IntVar(&convertOpts.InputLimits.MaxFrames, "max-input-frames", 0, "Reject inputs of more than this many frames (default no limit)")
+	convertCmd.Flags().Int64Var(&convertOpts.InputLimits.MaxPixels, "max-input-pixels", 0, "Reject inputs whose images add up to more than this many pixels, read from their headers before decoding (default no limit)")
+	convertCmd.Flags().DurationVar(&convertOpts.InputLimits.FrameTimeout, "frame-timeout", 0, "Give up on an input image taking longer than this to read and decode, e.g. 5s (default 1m)")
+	convertCmd.Flags().StringVar(&convertOpts.EmitSnippet, "emit-snippet", "", "Write a ready-to-paste embed snippet next to the output: md or html")
+	convertCmd.Flags().StringVar(&convertOpts.Timeline, "timeline", "", "Per-range frame delays, e.g. \"0-30@100ms,31@2s,32-60@80ms\" (0-based, inclusive ranges)")
+	convertCmd.Flags().DurationVar(&convertOpts.TrimIdle, "trim-idle", 0, "Cap the delays frames come with, such as the pauses of a terminal recording, input manifest or sidecars, at this long, e.g. 2s")
+	convertCmd.Flags().StringVar(&chaptersFile, "chapters", "", "YAML file of chapter labels and the 0-based frames they start at (\"Install: 0\"), held for --chapter-hold")
+	convertCmd.Flags().DurationVar(&convertOpts.ChapterHold, "chapter-hold", 0, "How long the first frame of every chapter is shown at least, unless set by --timeline (default 2s)")
+	convertCmd.Flags().StringVar(&convertOpts.Manifest, "manifest", "", "Write a JSON index of the GIF frames, their delays and the chapters they start to this path")
+	convertCmd.Flags().StringVar(&startAt, "start", "", "Only keep frames played from this position on (e.g. 00:00:05, 1:30 or 5.5)")
+	convertCmd.Flags().StringVar(&endAt, "end", "", "Only keep frames played before this position (e.g. 00:00:12)")
+	convertCmd.Flags().StringVar(&maxDuration, "max-duration", "", "Play within this long (e.g. 10s), dropping evenly spaced frames, or cutting the end when too few would be left")
+	convertCmd.Flags().StringVar(&convertOpts.Subtitles, "subtitles", "", "SRT file whose subtitles are burned onto the frames they are shown on")
+	convertCmd.Flags().StringVar(&convertOpts.FrameLabel, "frame-label", "", "Label drawn in the top left corner of every frame, e.g. \"{file} {time}\" ({index}, {frame}, {file}, {path}, {time}, {tag:name})")
+	convertCmd.Flags().StringVar(&convertOpts.Annotations, "annotations", "", "JSON file describing arrows, rectangles, highlights and text drawn on ranges of frames")
+	convertCmd.Flags().StringVar(&convertOpts.Stamp, "stamp", "", "Text drawn in a corner of every frame, expanding environment variables, e.g. \"branch:{{.GIT_BRANCH}} run:{{.CI_RUN}}\"")
+	convertCmd.Flags().StringVar(&convertOpts.StampCorner, "stamp-corner", converter.CornerBottomRight, "Corner of the stamp: "+strings.Join(converter.Corners, ", "))
+	convertCmd.Flags().StringVar(&convertOpts.Events, "events", "", "Keystroke/click events file (JSON or JSON Lines) rendered as keystroke badges and click markers")
+	convertCmd.Flags().StringVar(&convertOpts.Waveform, "waveform", "", "WAV audio track recorded with the capture, drawn as a scrolling waveform strip along the bottom of every frame")
+	convertCmd.Flags().StringVar(&captionColor, "caption-color", "", "Caption text color as #RRGGBB (default white)")
+	convertCmd.Flags().StringVar(&captionBackground, "caption-background", "", "Caption box color as #RRGGBB or #RRGGBBAA (default translucent black)")
+	convertCmd.Flags().BoolVar(&convertOpts.Normalize, "normalize", false, "Match the brightness of every frame to the first one, removing flicker between captures")
+	convertCmd.Flags().BoolVar(&convertOpts.Equalize, "equalize", false, "Spread the brightness of every frame over the whole range (histogram equalization)")
+	convertCmd.Flags().StringVar(&convertOpts.LUT, "lut", "", "3D LUT file (.cube) grading the colors of every frame (e.g., teal_orange.cube)")
+	convertCmd.Flags().Float64Var(&convertOpts.Vignette, "vignette", 0, "Darken the edges of every frame, from 0 (none) to 1 (black corners)")
+	convertCmd.Flags().IntVar(&convertOpts.CornerRadius, "corner-radius", 0, "Round the corners of every frame by this many pixels, fading them into --corner-color")
+	convertCmd.Flags().StringVar(&cornerColor, "corner-color", "", "Color outside the rounded corners, as #RRGGBB (default white)")
+	convertCmd.Flags().BoolVar(&convertOpts.Stabilize, "stabilize", false, "Cancel camera shake by shifting every frame onto the first one and cropping to the common area")
+	convertCmd.Flags().StringVar(&convertOpts.AlignOn, "align-on", "", "PNG image of a region to find in every frame, shifting the frames to keep it in place")
+	convertCmd.Flags().StringVar(&smartCrop, "smart-crop", "", "Crop every frame to a WxH window placed where the content has the most detail (e.g., 1080x1080)")
+	convertCmd.Flags().IntVar(&convertOpts.OnionSkin, "onion-skin", 0, "Blend every frame with faded copies of the previous N frames, leaving motion trails")
+	convertCmd.Flags().IntVar(&convertOpts.SmoothLoop, "smooth-loop", 0, "Crossfade the last N frames into the first one, so the GIF loops without a visible jump")
+	convertCmd.Flags().IntVar(&convertOpts.LoopSearch, "loop-search", 0, "Cut the frames after the one among the last N looking the most like the first one, where the loop jumps the least")
+	convertCmd.Flags().BoolVar(&convertOpts.FindLoop, "find-loop", false, "Trim captures of periodic animations, such as spinners, to exactly one cycle, starting where the loop seam is the least visible")
+	convertCmd.Flags().IntVar(&convertOpts.MagnifyChanges, "magnify-changes", 0, "Draw an inset of the area that changed since the previous frame, zoomed N times, in a corner of every frame")
+	convertCmd.Flags().StringVar(&convertOpts.MagnifyCorner, "magnify-corner", converter.CornerBottomRight, "Corner of the --magnify-changes inset: "+strings.Join(converter.Corners, ", "))
+	convertCmd.Flags().BoolVar(&convertOpts.VisualizeDiff, "visualize-diff", false, "Encode a heatmap of the pixels changed since the previous frame instead of the frames")
+	convertCmd.Flags().BoolVar(&convertOpts.PreserveText, "preserve-text", false, "Detect text areas and keep them crisp, dithering the background instead")
+	convertCmd.Flags().StringArrayVar(&textRegions, "text-region", nil, "Area to keep crisp like detected text, as x,y,WxH (can be specified multiple times)")
+	convertCmd.Flags().StringVar(&paletteFile, "palette", "", "Palette file (.png swatch, .gpl or hex list, e.g. from the palette command) used instead of generating one")
+	convertCmd.Flags().Float64Var(&convertOpts.TermFontSize, "term-font-size", termrender.DefaultFontSize, "Font size in pixels used to render .cast terminal recordings")
+	convertCmd.Flags().IntVar(&convertOpts.TermFPS, "term-fps", termrender.DefaultFPS, "Maximum frame rate used to render .cast terminal recordings")
+	convertCmd.Flags().StringVar(&fromVideo, "from-video", "", "Video file (MP4, WebM, MOV...) whose frames are extracted with ffmpeg and converted instead of input images")
+	convertCmd.Flags().Float64Var(&convertOpts.VideoFPS, "video-fps", converter.DefaultVideoFPS, "Frame rate the frames of --from-video are extracted at")
+	convertCmd.Flags().BoolVar(&convertOpts.A11yCheck, "a11y-check", false, "Write color-blind previews of sample frames and warn about low contrast overlay text")
 
 	// Mark required flags
-	convertCmd.MarkFlagRequired("input")
+	convertCmd.MarkFlagsOneRequired("input", "from-video")
+	convertCmd.MarkFlagsMutuallyExclusive("input", "from-video")
 	convertCmd.MarkFlagRequired("output")
 }
+
+// presetNames lists the names of the output presets
+func presetNames() string {
+	var names []string
+	for _, preset := range converter.Presets {
+		names = append(names, preset.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/jparrill/go-togif/pkg/converter"
 	"github.com/spf13/cobra"
 )
 
@@ -150,3 +154,73 @@ func TestConvertCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteReportJSON(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "report.json")
+	report := &converter.Report{OutputFile: "out.gif", Frames: 3, SourceColors: 60000, Warnings: []converter.Warning{{Kind: converter.WarningPaletteTruncated, Message: "banding"}}}
+	if err := writeReportJSON(path, report); err != nil {
+		t.Fatalf("writeReportJSON() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var got converter.Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if got.SourceColors != 60000 || len(got.Warnings) != 1 || got.Warnings[0].Kind != converter.WarningPaletteTruncated || !strings.Contains(string(data), `"sourceColors"`) {
+		t.Errorf("report = %s, want the colors and warnings", data)
+	}
+}
+
+func TestPrintReportWarnings(t *testing.T) {
+	report := &converter.Report{Warnings: []converter.Warning{
+		{Kind: converter.WarningIneffectiveOption, Message: "--normalize has no effect with --equalize"},
+		{Kind: converter.WarningResizedFrame, Message: "1 frame(s) are not 32x32", Frames: []int{2}},
+	}}
+	var buf bytes.Buffer
+	printReport(&buf, report)
+
+	// Ineffective options are printed before the conversion starts
+	if strings.Contains(buf.String(), "--normalize") {
+		t.Errorf("printReport() = %q, want the ineffective options left out", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Warning: 1 frame(s) are not 32x32") {
+		t.Errorf("printReport() = %q, want the resized frame warning", buf.String())
+	}
+}
+
+func TestPrintReportDensity(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    float64
+		effective float64
+		want      string
+	}{
+		{name: "no density", want: "Effective density: unknown (no pHYs chunk in input)\n"},
+		{name: "kept", source: 143.9926, effective: 143.9926, want: "Effective density: 144 DPI\n"},
+		{name: "kept with rounding", source: 143.9926, effective: 143.9926000001, want: "Effective density: 144 DPI\n"},
+		{name: "downscaled", source: 143.9926, effective: 71.9963, want: "Effective density: 72 DPI (source 144 DPI)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			printReport(&buf, &converter.Report{SourceDPI: tt.source, EffectiveDPI: tt.effective})
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("printReport() = %q, want %q", buf.String(), tt.want)
+			}
+			if tt.source > 0 && strings.Contains(buf.String(), "unknown") {
+				t.Errorf("printReport() = %q, want the detected density only", buf.String())
+			}
+		})
+	}
+}
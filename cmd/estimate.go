@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// estimateOpts holds the conversion settings to estimate
+	estimateOpts converter.Options
+	// estimateStart and estimateEnd are parsed into estimateOpts.Start and estimateOpts.End
+	estimateStart string
+	estimateEnd   string
+	// estimateChapters is loaded into estimateOpts.Chapters
+	estimateChapters string
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Predict the size and duration of a conversion",
+	Long: `Predict the output size and duration of converting PNG images by encoding a few sampled frames,
+without doing the full conversion. Accepts the same sizing and timing flags as convert.`,
+	Example: `  # Predict the size of a 600 pixels wide, 128 colors GIF
+  go-togif estimate -i "*.png" --width 600 --colors 128
+
+  # Only the first ten seconds
+  go-togif estimate -i "*.png" --end 10s`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPattern, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		// Parse the trimming window
+		if estimateOpts.Start, err = converter.ParseTimestamp(estimateStart); err != nil {
+			return fmt.Errorf("invalid --start: %v", err)
+		}
+		if estimateOpts.End, err = converter.ParseTimestamp(estimateEnd); err != nil {
+			return fmt.Errorf("invalid --end: %v", err)
+		}
+
+		// Load the chapters to hold
+		estimateOpts.Chapters = nil
+		if estimateChapters != "" {
+			if estimateOpts.Chapters, err = converter.LoadChapters(estimateChapters); err != nil {
+				return err
+			}
+		}
+
+		// Expand and validate the input pattern or manifest
+		inputFiles, delays, err := expandInputFiles(inputPattern)
+		if err != nil {
+			return err
+		}
+		estimateOpts.FrameDelays = delays
+
+		estimate, err := converter.EstimateConversion(inputFiles, estimateOpts)
+		if err != nil {
+			return err
+		}
+
+		printEstimate(cmd.OutOrStdout(), estimate)
+		return nil
+	},
+}
+
+// printEstimate writes the predicted conversion result
+func printEstimate(w io.Writer, estimate *converter.Estimate) {
+	fmt.Fprintf(w, "Output: %dx%d, %d frames, %d colors\n", estimate.Width, estimate.Height, estimate.Frames, estimate.Colors)
+	if estimate.Auto != nil {
+		fmt.Fprintf(w, "Auto: %s\n", estimate.Auto)
+	}
+	fmt.Fprintf(w, "Duration: %v\n", estimate.Duration)
+	fmt.Fprintf(w, "Estimated size: %s (from %d sampled frames)\n", formatBytes(estimate.Bytes), estimate.SampledFrames)
+}
+
+// formatBytes returns a human readable size
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	rootCmd.AddCommand(estimateCmd)
+
+	// Add flags
+	estimateCmd.Flags().StringP("input", "i", "", "Input PNG, WebP, BMP or TIFF file(s) pattern, or @file listing one file per line (required)")
+	addPatternFlags(estimateCmd)
+	estimateCmd.Flags().BoolVar(&estimateOpts.IgnoreSidecars, "ignore-sidecars", false, "Ignore the per-frame options files next to the inputs (frame.png.json)")
+	estimateCmd.Flags().IntVarP(&estimateOpts.Delay, "delay", "d", 100, "Delay between frames in milliseconds")
+	estimateCmd.Flags().IntVar(&estimateOpts.Width, "width", 0, "Output width in pixels, keeping the aspect ratio (default input width)")
+	estimateCmd.Flags().IntVar(&estimateOpts.Colors, "colors", 0, "Maximum number of palette colors (default 256)")
+	estimateCmd.Flags().StringVar(&estimateOpts.Quantizer, "quantizer", "", "Palette generation: frequency or median-cut (default frequency)")
+	estimateCmd.Flags().StringVar(&estimateOpts.Dither, "dither", "", "Dithering: none or floyd-steinberg (default none)")
+	estimateCmd.Flags().StringVar(&estimateOpts.Scaler, "scaler", "", "Resizing: smooth or nearest (default smooth)")
+	estimateCmd.Flags().BoolVar(&estimateOpts.Auto, "auto", false, "Pick the settings not set explicitly for the detected content type")
+	estimateCmd.Flags().BoolVar(&estimateOpts.RespectDPI, "respect-dpi", false, "Downscale high density inputs to logical pixels")
+	estimateCmd.Flags().BoolVar(&estimateOpts.PreserveText, "preserve-text", false, "Favor the colors of detected text areas")
+	estimateCmd.Flags().StringVar(&estimateOpts.Timeline, "timeline", "", "Per-range frame delays, e.g. \"0-30@100ms,31@2s\"")
+	estimateCmd.Flags().DurationVar(&estimateOpts.TrimIdle, "trim-idle", 0, "Cap the delays frames come with, such as the pauses of an input manifest, at this long")
+	estimateCmd.Flags().StringVar(&estimateChapters, "chapters", "", "YAML file of chapter labels and the frames they start at, held for --chapter-hold")
+	estimateCmd.Flags().DurationVar(&estimateOpts.ChapterHold, "chapter-hold", 0, "How long the first frame of every chapter is shown at least (default 2s)")
+	estimateCmd.Flags().StringVar(&estimateStart, "start", "", "Only count frames played from this position on")
+	estimateCmd.Flags().StringVar(&estimateEnd, "end", "", "Only count frames played before this position")
+
+	// Mark required flags
+	estimateCmd.MarkFlagRequired("input")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestPrintEstimate(t *testing.T) {
+	var out bytes.Buffer
+	printEstimate(&out, &converter.Estimate{
+		Frames:        120,
+		SampledFrames: 5,
+		Width:         600,
+		Height:        338,
+		Colors:        128,
+		Duration:      12 * time.Second,
+		Bytes:         2 * 1024 * 1024,
+	})
+
+	for _, want := range []string{"600x338, 120 frames, 128 colors", "Duration: 12s", "2.0 MiB (from 5 sampled frames)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printEstimate() output %q does not contain %q", out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// commandLine returns the command line reproducing the flags set on cmd,
+// leaving out --explain and --pipeline
+func commandLine(cmd *cobra.Command) string {
+	args := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "explain" || f.Name == "pipeline" {
+			return
+		}
+		flag := "--" + f.Name
+		switch value := f.Value.(type) {
+		case pflag.SliceValue:
+			// Arrays repeat the flag, slices list the values comma separated
+			if f.Value.Type() == "stringArray" {
+				for _, item := range value.GetSlice() {
+					args = append(args, flag, shellQuote(item))
+				}
+				return
+			}
+			args = append(args, flag, shellQuote(strings.Join(value.GetSlice(), ",")))
+		default:
+			if f.Value.Type() == "bool" {
+				if f.Value.String() == "true" {
+					args = append(args, flag)
+				} else {
+					args = append(args, flag+"=false")
+				}
+				return
+			}
+			args = append(args, flag, shellQuote(f.Value.String()))
+		}
+	})
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell when it has characters the shell
+// would interpret
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=,@%+") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// loadPipeline sets the flags of cmd from the command line of the plan in
+// the file of its --pipeline flag, as written by --explain. Flags given on
+// the command line are kept.
+func loadPipeline(cmd *cobra.Command, args []string) error {
+	path, err := cmd.Flags().GetString("pipeline")
+	if err != nil || path == "" {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading pipeline: %v", err)
+	}
+	var plan struct {
+		Command string `yaml:"command"`
+	}
+	// The conversion report may follow the plan in a saved --explain output
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&plan); err != nil {
+		return fmt.Errorf("invalid pipeline %s: %v", path, err)
+	}
+	if plan.Command == "" {
+		return fmt.Errorf("invalid pipeline %s: no command", path)
+	}
+	words, err := shellSplit(plan.Command)
+	if err != nil {
+		return fmt.Errorf("invalid pipeline %s: %v", path, err)
+	}
+	command := strings.Fields(cmd.CommandPath())
+	if len(words) < len(command) || strings.Join(words[:len(command)], " ") != cmd.CommandPath() {
+		return fmt.Errorf("invalid pipeline %s: %q is not a %s command", path, plan.Command, cmd.CommandPath())
+	}
+
+	given := make(map[string]bool)
+	cmd.Flags().Visit(func(f *pflag.Flag) { given[f.Name] = true })
+	words = words[len(command):]
+	for i := 0; i < len(words); i++ {
+		word, value, hasValue := strings.Cut(words[i], "=")
+		var flag *pflag.Flag
+		switch {
+		case strings.HasPrefix(word, "--"):
+			flag = cmd.Flags().Lookup(word[2:])
+		case len(word) == 2 && word[0] == '-':
+			flag = cmd.Flags().ShorthandLookup(word[1:])
+		default:
+			return fmt.Errorf("invalid pipeline %s: unexpected argument %q", path, words[i])
+		}
+		if flag == nil || flag.Name == "pipeline" {
+			return fmt.Errorf("invalid pipeline %s: unknown flag %s", path, word)
+		}
+		name := flag.Name
+		if !hasValue {
+			if flag.Value.Type() == "bool" {
+				value = "true"
+			} else if i++; i < len(words) {
+				value = words[i]
+			} else {
+				return fmt.Errorf("invalid pipeline %s: --%s has no value", path, name)
+			}
+		}
+		if given[name] {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("invalid pipeline %s: --%s: %v", path, name, err)
+		}
+	}
+	return nil
+}
+
+// shellSplit splits a command line into words like a POSIX shell, undoing
+// shellQuote
+func shellSplit(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ' ', '\t', '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %q", s)
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				// Only these characters are escaped within double quotes
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0 {
+					i++
+				}
+				word.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("unterminated quote in %q", s)
+			}
+			inWord = true
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				word.WriteByte(s[i])
+			}
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+// testConvertCommand returns a command with flags of each kind of convert,
+// loading --pipeline like it
+func testConvertCommand() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "go-togif"}
+	convert := &cobra.Command{
+		Use:     "convert",
+		PreRunE: loadPipeline,
+		RunE:    func(*cobra.Command, []string) error { return nil },
+	}
+	convert.Flags().StringP("input", "i", "", "")
+	convert.Flags().StringP("output", "o", "", "")
+	convert.Flags().String("stamp", "", "")
+	convert.Flags().Bool("explain", false, "")
+	convert.Flags().String("pipeline", "", "")
+	convert.Flags().Bool("auto", false, "")
+	convert.Flags().Bool("normalize", true, "")
+	convert.Flags().Int("colors", 0, "")
+	convert.Flags().StringSlice("formats", nil, "")
+	convert.Flags().StringArray("roi", nil, "")
+	root.AddCommand(convert)
+	return root, convert
+}
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no flags", args: nil, want: "go-togif convert"},
+		{
+			name: "values quoted for the shell",
+			args: []string{"-i", "*.png", "--stamp", "it's", "--explain", "-o", "out.gif"},
+			want: "go-togif convert --input '*.png' --output out.gif --stamp 'it'\\''s'",
+		},
+		{
+			name: "booleans, slices and arrays",
+			args: []string{"--auto", "--formats", "gif,webp", "--roi", "a=0,0,1x1", "--roi", "b=1,1,1x1", "--normalize=false"},
+			want: "go-togif convert --auto --formats gif,webp --normalize=false --roi a=0,0,1x1 --roi b=1,1,1x1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, convert := testConvertCommand()
+			root.SetArgs(append([]string{"convert"}, tt.args...))
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := commandLine(convert); got != tt.want {
+				t.Errorf("commandLine() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPipeline(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Save the plan of a conversion like --explain, followed by its report
+	root, convert := testConvertCommand()
+	root.SetArgs([]string{"convert", "-i", "*.png", "-o", "out.gif", "--stamp", "it's", "--colors", "32", "--auto",
+		"--formats", "gif,webp", "--roi", "a=0,0,1x1", "--roi", "b=1,1,1x1", "--normalize=false", "--explain"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	plan := converter.Explain(2, "out.gif", converter.Options{Colors: 32, Auto: true})
+	plan.Command = commandLine(convert)
+	var saved strings.Builder
+	if err := plan.WriteYAML(&saved); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+	saved.WriteString("Output: 320x240, 10 frames\n")
+
+	tests := []struct {
+		name    string
+		plan    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "round trip",
+			plan: saved.String(),
+			want: plan.Command,
+		},
+		{
+			name: "flags given override the plan",
+			plan: saved.String(),
+			args: []string{"-o", "next.gif", "--roi", "c=2,2,1x1"},
+			want: "go-togif convert --auto --colors 32 --formats gif,webp --input '*.png' --normalize=false --output next.gif --roi c=2,2,1x1 --stamp 'it'\\''s'",
+		},
+		{
+			name: "double quotes and escapes",
+			plan: `command: 'go-togif convert -o "my out.gif" --stamp a\ b'`,
+			want: "go-togif convert --output 'my out.gif' --stamp 'a b'",
+		},
+		{
+			name:    "no command",
+			plan:    "inputs: 3\n",
+			wantErr: "no command",
+		},
+		{
+			name:    "another command",
+			plan:    "command: go-togif split demo.gif\n",
+			wantErr: "is not a go-togif convert command",
+		},
+		{
+			name:    "unknown flag",
+			plan:    "command: go-togif convert --output out.gif --sparkles\n",
+			wantErr: "unknown flag --sparkles",
+		},
+		{
+			name:    "argument",
+			plan:    "command: go-togif convert -o out.gif demo.gif\n",
+			wantErr: "unexpected argument \"demo.gif\"",
+		},
+		{
+			name:    "unterminated quote",
+			plan:    "command: \"go-togif convert --stamp 'it\"\n",
+			wantErr: "unterminated quote",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, strings.ReplaceAll(tt.name, " ", "-")+".yaml")
+			if err := os.WriteFile(path, []byte(tt.plan), 0644); err != nil {
+				t.Fatalf("Failed to write plan: %v", err)
+			}
+			root, convert := testConvertCommand()
+			// The plan provides the required flags
+			convert.MarkFlagRequired("output")
+			root.SetArgs(append([]string{"convert", "--pipeline", path}, tt.args...))
+			err := root.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := commandLine(convert); got != tt.want {
+				t.Errorf("commandLine() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// Documentation formats of gen-docs
+const (
+	docsMan      = "man"
+	docsMarkdown = "markdown"
+)
+
+var (
+	// docsDir is the directory the documentation is written to
+	docsDir string
+	// docsFormat is one of the documentation formats
+	docsFormat string
+)
+
+var genDocsCmd = &cobra.Command{
+	Use:   "gen-docs",
+	Short: "Generate man pages or Markdown documentation of every command",
+	Long: `Generate the documentation of go-togif and every subcommand, one file per command, from the
+same descriptions, flags and examples as --help: man pages (go-togif-convert.1...) or Markdown
+(go-togif_convert.md...). Packagers can ship the man pages in man1; the date follows SOURCE_DATE_EPOCH
+when set, for reproducible builds.`,
+	Example: `  # Install the man pages
+  go-togif gen-docs --dir /usr/share/man/man1
+
+  # Write the Markdown reference of the website
+  go-togif gen-docs --format markdown --dir docs/cli`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := os.MkdirAll(docsDir, 0755); err != nil {
+			return fmt.Errorf("error creating docs directory: %v", err)
+		}
+		disableAutoGenTag(rootCmd)
+		switch strings.ToLower(docsFormat) {
+		case docsMan:
+			date, err := docsDate()
+			if err != nil {
+				return err
+			}
+			root := rootCmd.Name()
+			header := &doc.GenManHeader{
+				Section: "1",
+				Date:    &date,
+				Source:  root + " " + readBuildInfo().Version,
+				Manual:  root + " Manual",
+			}
+			if err := doc.GenManTree(rootCmd, header, docsDir); err != nil {
+				return fmt.Errorf("error writing man pages: %v", err)
+			}
+			return nil
+		case docsMarkdown, "md":
+			if err := doc.GenMarkdownTree(rootCmd, docsDir); err != nil {
+				return fmt.Errorf("error writing Markdown pages: %v", err)
+			}
+			return nil
+		default:
+			return fmt.Errorf("invalid --format %q: must be %s or %s", docsFormat, docsMan, docsMarkdown)
+		}
+	},
+}
+
+// docsDate returns the date of the man pages, from SOURCE_DATE_EPOCH when set
+func docsDate() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %v", epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// disableAutoGenTag leaves the generation date out of the pages of cmd and
+// its subcommands, which only the man page header should carry
+func disableAutoGenTag(cmd *cobra.Command) {
+	cmd.DisableAutoGenTag = true
+	for _, child := range cmd.Commands() {
+		disableAutoGenTag(child)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(genDocsCmd)
+
+	genDocsCmd.Flags().StringVar(&docsDir, "dir", "docs", "Directory to write the documentation to, created when missing")
+	genDocsCmd.Flags().StringVar(&docsFormat, "format", docsMan, "Documentation format: man or markdown")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGenDocsCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	tests := []struct {
+		name   string
+		format string
+		// files maps the files expected to what they contain
+		files   map[string][]string
+		wantErr bool
+	}{
+		{
+			name:   "man",
+			format: "man",
+			files: map[string][]string{
+				"go-togif.1":             {`.TH "GO-TOGIF" "1" "Nov 2023" "go-togif `, `\fBgo-togif-convert(1)\fP`},
+				"go-togif-convert.1":     {".SH EXAMPLE", `go-togif convert -i "frames/*.png"`, `\fB-i\fP, \fB--input\fP=""`},
+				"go-togif-generate-qr.1": {".SH OPTIONS INHERITED FROM PARENT COMMANDS", `\fB--data\fP`},
+			},
+		},
+		{
+			name:   "markdown",
+			format: "markdown",
+			files: map[string][]string{
+				"go-togif.md":         {"## go-togif", "* [go-togif split](go-togif_split.md)"},
+				"go-togif_convert.md": {"### Examples", "go-togif convert -i \"frames/*.png\"", "--input string"},
+			},
+		},
+		{
+			name:    "invalid format",
+			format:  "html",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(tempDir, tt.name)
+			rootCmd.SetArgs([]string{"gen-docs", "--format", tt.format, "--dir", dir})
+			defer rootCmd.SetArgs(nil)
+
+			err := rootCmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gen-docs error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for name, wants := range tt.files {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Errorf("Failed to read %s: %v", name, err)
+					continue
+				}
+				for _, want := range wants {
+					if !strings.Contains(string(data), want) {
+						t.Errorf("%s = %q, want %q", name, data, want)
+					}
+				}
+				// The generation time would make the pages differ on every run
+				if strings.Contains(string(data), "Auto generated") {
+					t.Errorf("%s = %q, want no generation tag", name, data)
+				}
+			}
+		})
+	}
+}
+
+// TestCommandExamples checks that every command has examples, whose flags
+// all exist
+func TestCommandExamples(t *testing.T) {
+	var check func(cmd *cobra.Command)
+	check = func(cmd *cobra.Command) {
+		// Cobra's own commands come without examples
+		if cmd.Name() == "help" || cmd.Name() == "completion" {
+			return
+		}
+		if cmd.Example == "" {
+			t.Errorf("%s has no examples", cmd.CommandPath())
+		}
+		for _, line := range strings.Split(cmd.Example, "\n") {
+			start := strings.Index(line, "go-togif ")
+			if start < 0 || strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			// Leave out the commands the output is piped to
+			line, _, _ = strings.Cut(line[start:], " | ")
+			args := strings.Fields(line)[1:]
+			target, _, err := rootCmd.Find(args)
+			if err != nil {
+				t.Errorf("example %q: %v", line, err)
+				continue
+			}
+			// --help is added on execution
+			target.InitDefaultHelpFlag()
+			for _, arg := range args {
+				if !strings.HasPrefix(arg, "-") || arg == "-" {
+					continue
+				}
+				var found bool
+				if name, ok := strings.CutPrefix(arg, "--"); ok {
+					name, _, _ = strings.Cut(name, "=")
+					found = target.Flags().Lookup(name) != nil || target.InheritedFlags().Lookup(name) != nil
+				} else {
+					found = target.Flags().ShorthandLookup(arg[1:]) != nil || target.InheritedFlags().ShorthandLookup(arg[1:]) != nil
+				}
+				if !found {
+					t.Errorf("example %q: %s has no flag %s", line, target.CommandPath(), arg)
+				}
+			}
+		}
+		for _, child := range cmd.Commands() {
+			check(child)
+		}
+	}
+	check(rootCmd)
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/generate"
+	"github.com/jparrill/go-togif/pkg/lottie"
+	"github.com/jparrill/go-togif/pkg/overlay"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// generateOpts holds the encoding settings shared by the generate subcommands
+	generateOpts converter.Options
+	// qrData and qrOpts are bound to the generate qr flags
+	qrData string
+	qrOpts generate.QROptions
+	// patternOpts and patternColor are bound to the generate testpattern flags
+	patternOpts  generate.TestPatternOptions
+	patternColor string
+	// patternLottie is the path of the Lottie export of the test pattern
+	patternLottie string
+	// generateFrames and generateManifest write the synthesized frames as images
+	generateFrames   string
+	generateManifest string
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate GIFs from synthesized frames",
+	Long:  `Generate animated GIFs without input images, such as animated QR codes and test patterns.`,
+	Example: `  # Generate an animated QR code
+  go-togif generate qr --data "https://example.com" -o qr.gif
+
+  # Generate color bars
+  go-togif generate testpattern -o bars.gif`,
+}
+
+var generateQRCmd = &cobra.Command{
+	Use:   "qr",
+	Short: "Generate an animated QR code",
+	Long: `Generate an animated QR code GIF.
+In finder mode a single code is shown with color cycling finder patterns.
+In parts mode the data is split over --frames codes, each prefixed with "i/n:" so readers can reassemble it.`,
+	Example: `  # A single code with color cycling finder patterns
+  go-togif generate qr --data "https://example.com" -o qr.gif
+
+  # Data split over five codes
+  go-togif generate qr --data "$(cat payload.txt)" --mode parts --frames 5 -o qr.gif`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		frames, err := generate.QR(qrData, qrOpts)
+		if err != nil {
+			return err
+		}
+		return writeGenerated(cmd, frames)
+	},
+}
+
+var generateTestPatternCmd = &cobra.Command{
+	Use:   "testpattern",
+	Short: "Generate a test pattern animation",
+	Long: `Generate a deterministic test pattern animation (solid color, color bars, gradient, checkerboard or moving box),
+useful to validate players and image pipelines.`,
+	Example: `  # Scrolling color bars to check a player
+  go-togif generate testpattern --pattern bars --width 640 --height 360 -o bars.gif
+
+  # Write the frames as PNG images too
+  go-togif generate testpattern --pattern checkerboard -o board.gif --frames-template "frames/frame-%04d.png"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := patternOpts
+		if patternColor != "" {
+			c, err := overlay.ParseHexColor(patternColor)
+			if err != nil {
+				return fmt.Errorf("invalid --color: %v", err)
+			}
+			opts.Color = c
+		}
+
+		if patternLottie != "" {
+			anim, err := generate.TestPatternLottie(opts, time.Duration(generateOpts.Delay)*time.Millisecond)
+			if err != nil {
+				return err
+			}
+			if err := writeLottie(cmd, anim, patternLottie); err != nil {
+				return err
+			}
+			if output, _ := cmd.Flags().GetString("output"); output == "" && generateFrames == "" {
+				return nil
+			}
+		}
+
+		frames, err := generate.TestPattern(opts)
+		if err != nil {
+			return err
+		}
+		return writeGenerated(cmd, frames)
+	},
+}
+
+// writeGenerated encodes synthesized frames to the --output file and saves
+// them as images with --frames-template
+func writeGenerated(cmd *cobra.Command, frames []converter.Frame) error {
+	outputFile, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	if outputFile == "" && generateFrames == "" {
+		return fmt.Errorf("--output or --frames-template is required")
+	}
+	if generateManifest != "" && generateFrames == "" {
+		return fmt.Errorf("--manifest requires --frames-template")
+	}
+
+	if generateFrames != "" {
+		delay := time.Duration(generateOpts.Delay) * time.Millisecond
+		if err := writeFrameFiles(cmd, frames, generateFrames, generateManifest, delay); err != nil {
+			return err
+		}
+		if outputFile == "" {
+			return nil
+		}
+	}
+
+	report, err := converter.ConvertFrames(frames, outputFile, generateOpts)
+	if err != nil {
+		return err
+	}
+
+	printReport(cmd.OutOrStdout(), report)
+	return nil
+}
+
+// writeLottie writes a vector export of a synthesized animation to path
+func writeLottie(cmd *cobra.Command, anim *lottie.Animation, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating Lottie file: %v", err)
+	}
+	defer file.Close()
+	if err := anim.Write(file); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing Lottie file: %v", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Lottie animation: %s\n", path)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+	generateCmd.AddCommand(generateQRCmd)
+	generateCmd.AddCommand(generateTestPatternCmd)
+
+	// Add flags shared by the generators
+	generateCmd.PersistentFlags().StringP("output", "o", "", "Output GIF file path (required unless --frames-template is set)")
+	generateCmd.PersistentFlags().IntVarP(&generateOpts.Delay, "delay", "d", 100, "Delay between frames in milliseconds")
+	generateCmd.PersistentFlags().BoolVar(&generateOpts.Debug, "debug", false, "Enable debug mode to show detailed progress")
+	generateCmd.PersistentFlags().StringVar(&generateFrames, "frames-template", "", "Also write every frame as a PNG named after this template: printf style (frame-%04d.png) or Go template ({{.Index}}, {{.DelayMs}})")
+	generateCmd.PersistentFlags().StringVar(&generateManifest, "manifest", "", "Write a JSON index of the frames written with --frames-template to this path")
+
+	generateQRCmd.Flags().StringVar(&qrData, "data", "", "Data to encode (required)")
+	generateQRCmd.Flags().IntVar(&qrOpts.Frames, "frames", generate.DefaultQRFrames, "Number of frames, or of parts in parts mode")
+	generateQRCmd.Flags().StringVar(&qrOpts.Mode, "mode", generate.QRFinder, "Animation: finder (color cycling finder patterns) or parts (data split over several codes)")
+	generateQRCmd.Flags().IntVar(&qrOpts.Scale, "scale", generate.DefaultQRScale, "Size of a QR module in pixels")
+	generateQRCmd.Flags().StringVar(&qrOpts.Level, "level", "M", "Error recovery level: L, M, Q or H")
+	generateQRCmd.MarkFlagRequired("data")
+
+	generateTestPatternCmd.Flags().StringVar(&patternOpts.Pattern, "pattern", generate.PatternBars, fmt.Sprintf("Pattern to draw: %s", strings.Join(generate.Patterns, ", ")))
+	generateTestPatternCmd.Flags().IntVar(&patternOpts.Width, "width", generate.DefaultPatternWidth, "Frame width in pixels")
+	generateTestPatternCmd.Flags().IntVar(&patternOpts.Height, "height", generate.DefaultPatternHeight, "Frame height in pixels")
+	generateTestPatternCmd.Flags().IntVar(&patternOpts.Frames, "frames", generate.DefaultPatternFrames, "Number of frames")
+	generateTestPatternCmd.Flags().IntVar(&patternOpts.CellSize, "cell-size", generate.DefaultCellSize, "Checkerboard square size in pixels")
+	generateTestPatternCmd.Flags().StringVar(&patternLottie, "lottie", "", "Also write the pattern as vector Lottie JSON to this path (-o is then optional)")
+	generateTestPatternCmd.Flags().StringVar(&patternColor, "color", "", "Color of the solid pattern and moving box as #RRGGBB (default white)")
+}
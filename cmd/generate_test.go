@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateQRCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputFile := filepath.Join(tempDir, "qr.gif")
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"generate", "qr", "--data", "hello", "--frames", "3", "-o", outputFile})
+	defer rootCmd.SetArgs(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("generate qr error = %v", err)
+	}
+	if _, err := os.Stat(outputFile); err != nil {
+		t.Errorf("output file not created: %v", err)
+	}
+	if !strings.Contains(out.String(), "3 frames") {
+		t.Errorf("report = %q, want 3 frames", out.String())
+	}
+}
+
+func TestGenerateTestPatternCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "bars", args: []string{"--pattern", "bars", "--frames", "2"}},
+		{name: "solid with color", args: []string{"--pattern", "solid", "--color", "#ff8800", "--frames", "1"}},
+		{name: "invalid color", args: []string{"--pattern", "solid", "--color", "orange"}, wantErr: true},
+		{name: "invalid pattern", args: []string{"--pattern", "zebra"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patternColor = ""
+			outputFile := filepath.Join(tempDir, strings.ReplaceAll(tt.name, " ", "-")+".gif")
+			rootCmd.SetOut(&bytes.Buffer{})
+			rootCmd.SetArgs(append([]string{"generate", "testpattern", "--width", "32", "--height", "16", "-o", outputFile}, tt.args...))
+			defer rootCmd.SetArgs(nil)
+
+			err := rootCmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generate testpattern error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				if _, err := os.Stat(outputFile); err != nil {
+					t.Errorf("output file not created: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateTestPatternLottie(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	lottieFile := filepath.Join(tempDir, "box.json")
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"generate", "testpattern", "--pattern", "box", "--frames", "3", "--lottie", lottieFile, "-o", ""})
+	defer rootCmd.SetArgs(nil)
+	defer func() { patternLottie = "" }()
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("generate testpattern error = %v", err)
+	}
+	data, err := os.ReadFile(lottieFile)
+	if err != nil {
+		t.Fatalf("Lottie file not created: %v", err)
+	}
+	if !strings.Contains(string(data), `"layers"`) {
+		t.Errorf("Lottie file = %.80s..., want an animation", data)
+	}
+	if !strings.Contains(out.String(), "Lottie animation: "+lottieFile) {
+		t.Errorf("output = %q, want the Lottie path", out.String())
+	}
+}
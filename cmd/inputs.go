@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+// matchOpts holds the pattern matching settings of the commands reading PNG patterns
+var matchOpts converter.PatternOptions
+
+// addPatternFlags adds the pattern matching flags to a command reading PNG patterns
+func addPatternFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&matchOpts.IgnoreCase, "ignore-case", false, "Match the file names of the input pattern regardless of case")
+	cmd.Flags().BoolVar(&matchOpts.FollowSymlinks, "follow-symlinks", false, "Include symbolic links matched by the input pattern and follow linked directories")
+	cmd.Flags().BoolVar(&matchOpts.IncludeHidden, "include-hidden", false, "Include hidden files and directories (starting with a dot) matched by the input pattern")
+}
+
+// expandInputFiles expands an input pattern, or reads an @manifest file, and
+// checks the matching files decode. Delays are the per-file delays of a
+// manifest, nil for patterns.
+func expandInputFiles(pattern string) ([]string, []time.Duration, error) {
+	inputFiles, delays, err := listInputFiles(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := converter.ValidateInputFiles(inputFiles); err != nil {
+		return nil, nil, err
+	}
+	return inputFiles, delays, nil
+}
+
+// listInputFiles expands an input pattern, or reads an @manifest file,
+// without checking the files
+func listInputFiles(pattern string) ([]string, []time.Duration, error) {
+	var inputFiles []string
+	var delays []time.Duration
+	if converter.IsManifest(pattern) {
+		entries, err := converter.ReadManifest(strings.TrimPrefix(pattern, converter.ManifestPrefix))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range entries {
+			inputFiles = append(inputFiles, entry.Path)
+			delays = append(delays, entry.Delay)
+		}
+	} else {
+		var err error
+		if inputFiles, err = converter.ExpandInputPatternWith(pattern, matchOpts); err != nil {
+			return nil, nil, fmt.Errorf("error expanding pattern %s: %v", pattern, err)
+		}
+	}
+	if len(inputFiles) == 0 {
+		return nil, nil, fmt.Errorf("no input files specified")
+	}
+	return inputFiles, delays, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+// lsIgnoreSidecars lists the files skipped by their sidecar too
+var lsIgnoreSidecars bool
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the files an input pattern matches",
+	Long: `List the PNG, WebP, BMP and TIFF files matching an input pattern in the order convert uses them, with their index,
+size and dimensions, without converting anything. Accepts the same pattern flags as convert.`,
+	Example: `  # Check which files a pattern matches, and in what order
+  go-togif ls -i "frames/frame_{001..100}.png"
+
+  # List the files named in a list file
+  go-togif ls -i @frames.txt`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputPattern, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		// Expand the input pattern the same way convert does, listing the
+		// files failing to decode rather than stopping at them
+		inputFiles, _, err := listInputFiles(inputPattern)
+		if err != nil {
+			return err
+		}
+
+		return listFiles(cmd.OutOrStdout(), inputFiles, lsIgnoreSidecars)
+	},
+}
+
+// listFiles writes the index, size and dimensions of each input file, and a
+// total. Files skipped by their sidecar are left out unless ignoreSidecars.
+func listFiles(w io.Writer, inputFiles []string, ignoreSidecars bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INDEX\tSIZE\tDIMENSIONS\tFILE")
+
+	var total int64
+	var listed, skipped int
+	for _, file := range inputFiles {
+		if !ignoreSidecars {
+			sidecar, err := converter.LoadSidecar(file)
+			if err != nil {
+				return err
+			}
+			if sidecar != nil && sidecar.Skip {
+				skipped++
+				continue
+			}
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("error reading file %s: %v", file, err)
+		}
+		total += info.Size()
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", listed, formatBytes(info.Size()), imageDimensions(file), file)
+		listed++
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%d files, %s", listed, formatBytes(total))
+	if skipped > 0 {
+		fmt.Fprintf(w, ", %d skipped by their sidecar", skipped)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// imageDimensions returns the size of an image read from its header, or why it cannot be read
+func imageDimensions(file string) string {
+	if _, err := os.Stat(file); err != nil {
+		return "unreadable"
+	}
+	config, err := converter.DecodeInputConfig(file)
+	if err != nil {
+		return "invalid image"
+	}
+	return fmt.Sprintf("%dx%d", config.Width, config.Height)
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+
+	// Add flags
+	lsCmd.Flags().StringP("input", "i", "", "Input PNG, WebP, BMP or TIFF file(s) pattern, or @file listing one file per line (required)")
+	addPatternFlags(lsCmd)
+	lsCmd.Flags().BoolVar(&lsIgnoreSidecars, "ignore-sidecars", false, "List the files skipped by their per-frame options file (frame.png.json) too")
+
+	// Mark required flags
+	lsCmd.MarkFlagRequired("input")
+}
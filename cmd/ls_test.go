@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLsCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, name := range []string{"frame2.png", "frame1.png"} {
+		file, err := os.Create(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+		if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+			t.Fatalf("Failed to encode frame: %v", err)
+		}
+		file.Close()
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "frame3.png"), []byte("not a png"), 0644); err != nil {
+		t.Fatalf("Failed to create frame: %v", err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"ls", "-i", filepath.Join(tempDir, "frame*.png")})
+	defer rootCmd.SetArgs(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("ls error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("ls output = %q, want a header, 3 files and a total", out.String())
+	}
+	for i, want := range []string{"frame1.png", "frame2.png", "frame3.png"} {
+		line := lines[i+1]
+		if !strings.HasPrefix(line, strconv.Itoa(i)+" ") || !strings.HasSuffix(line, want) {
+			t.Errorf("line %d = %q, want index %d and %s", i+1, line, i, want)
+		}
+	}
+	if !strings.Contains(lines[1], "4x3") || !strings.Contains(lines[3], "invalid image") {
+		t.Errorf("ls output = %q, want dimensions and invalid files", out.String())
+	}
+	if !strings.HasPrefix(lines[4], "3 files") {
+		t.Errorf("total line = %q, want 3 files", lines[4])
+	}
+}
+
+func TestLsManifest(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, name := range []string{"a.png", "b.png"} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "c.png"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create frame: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "c.png.json"), []byte(`{"skip": true}`), 0644); err != nil {
+		t.Fatalf("Failed to create sidecar: %v", err)
+	}
+	manifest := filepath.Join(tempDir, "frames.txt")
+	if err := os.WriteFile(manifest, []byte("# reversed\nc.png\nb.png 1s\na.png\n"), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"ls", "-i", "@" + manifest})
+	defer rootCmd.SetArgs(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("ls error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[1], "b.png") || !strings.HasSuffix(lines[2], "a.png") {
+		t.Errorf("ls output = %q, want the manifest order", out.String())
+	}
+	if !strings.Contains(lines[len(lines)-1], "1 skipped") {
+		t.Errorf("ls output = %q, want the skipped file counted", out.String())
+	}
+}
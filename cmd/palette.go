@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// paletteOpts holds the quantization settings bound to the palette flags
+	paletteOpts converter.Options
+	// paletteFormat overrides the format chosen from the output extension
+	paletteFormat string
+)
+
+var paletteCmd = &cobra.Command{
+	Use:   "palette <pattern>",
+	Short: "Extract the palette a conversion would use",
+	Long: `Run only the quantization stage on the PNG images matching the pattern and write the resulting palette
+as a PNG swatch, a GIMP palette (.gpl) or a list of hex colors. The palette can be reused with convert --palette.`,
+	Example: `  # Extract a GIMP palette and reuse it in another conversion
+  go-togif palette "intro/*.png" -o brand.gpl
+  go-togif convert -i "outro/*.png" -o outro.gif --palette brand.gpl
+
+  # Print the colors as hex
+  go-togif palette "*.png"
+
+  # Extract the palette of a 16 color GIF 320 pixels wide
+  go-togif palette "*.png" --colors 16 --width 320 -o small.gpl`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFile, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		format := paletteFormat
+		if format == "" {
+			format = converter.PaletteFormat(outputFile)
+		}
+		if format == converter.PalettePNG && outputFile == "" {
+			return fmt.Errorf("--output is required for PNG swatches")
+		}
+
+		// Expand and validate the input pattern
+		inputFiles, _, err := expandInputFiles(args[0])
+		if err != nil {
+			return err
+		}
+
+		palette, err := converter.ExtractPalette(inputFiles, paletteOpts)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = cmd.OutOrStdout()
+		if outputFile != "" {
+			file, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("error creating output file: %v", err)
+			}
+			defer file.Close()
+			w = file
+		}
+		if err := converter.WritePalette(w, palette, format); err != nil {
+			return fmt.Errorf("error writing palette: %v", err)
+		}
+		if outputFile != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d colors to %s\n", len(palette), outputFile)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(paletteCmd)
+
+	// Add flags
+	paletteCmd.Flags().StringP("output", "o", "", "Output palette file (.png, .gpl or hex list), stdout when empty")
+	addPatternFlags(paletteCmd)
+	paletteCmd.Flags().BoolVar(&paletteOpts.IgnoreSidecars, "ignore-sidecars", false, "Ignore the per-frame options files next to the inputs (frame.png.json)")
+	paletteCmd.Flags().StringVar(&paletteFormat, "format", "", "Palette format: png, gpl or hex (default from the output extension, hex for stdout)")
+	paletteCmd.Flags().BoolVar(&paletteOpts.RespectDPI, "respect-dpi", false, "Downscale high density inputs to logical pixels before quantizing")
+	paletteCmd.Flags().BoolVar(&paletteOpts.PreserveText, "preserve-text", false, "Favor the colors of detected text areas")
+	paletteCmd.Flags().IntVar(&paletteOpts.Colors, "colors", 0, "Maximum number of palette colors (default 256)")
+	paletteCmd.Flags().IntVar(&paletteOpts.Width, "width", 0, "Output width the palette is built at, keeping the aspect ratio (default input width)")
+	paletteCmd.Flags().StringVar(&paletteOpts.Quantizer, "quantizer", "", "Palette generation: frequency or median-cut (default frequency)")
+	paletteCmd.Flags().StringVar(&paletteOpts.Dither, "dither", "", "Dithering of the conversion: none or floyd-steinberg (default none)")
+	paletteCmd.Flags().StringVar(&paletteOpts.Scaler, "scaler", "", "Resizing: smooth or nearest (default smooth)")
+	paletteCmd.Flags().BoolVar(&paletteOpts.Auto, "auto", false, "Pick the settings not set explicitly for the detected content type")
+}
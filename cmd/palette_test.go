@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaletteCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{255, 0, 0, 255})
+	file, err := os.Create(filepath.Join(tempDir, "frame.png"))
+	if err != nil {
+		t.Fatalf("Failed to create frame: %v", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		t.Fatalf("Failed to encode frame: %v", err)
+	}
+	file.Close()
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"palette", filepath.Join(tempDir, "*.png")})
+	defer rootCmd.SetArgs(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("palette error = %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "#ff0000" {
+		t.Errorf("palette output = %q, want #ff0000", out.String())
+	}
+}
@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jparrill/go-togif/pkg/converter"
 	"github.com/spf13/cobra"
 )
 
@@ -9,14 +14,30 @@ var rootCmd = &cobra.Command{
 	Short: "Convert PNG images to GIF with high quality",
 	Long: `A CLI application that converts a series of PNG images into a high-quality GIF.
 The output GIF will maintain the same quality and dimensions as the input images.`,
+	Example: `  # Convert every PNG in a folder
+  go-togif convert -i "frames/*.png" -o demo.gif
+
+  # List the subcommands and the flags of one
+  go-togif convert --help`,
 }
 
 func Execute() error {
+	// Remove the temporary and lock files of an interrupted conversion before exiting
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+	go func() {
+		<-interrupts
+		converter.RemoveTempDirs()
+		converter.ReleaseLocks()
+		os.Exit(130)
+	}()
+
 	return rootCmd.Execute()
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringP("output", "o", "output.gif", "Output GIF file path")
 	rootCmd.PersistentFlags().IntP("delay", "d", 100, "Delay between frames in milliseconds")
-	rootCmd.PersistentFlags().StringSliceP("input", "i", []string{}, "Input PNG files (can be specified multiple times)")
+	rootCmd.PersistentFlags().StringSliceP("input", "i", []string{}, "Input PNG, WebP, BMP or TIFF files (can be specified multiple times)")
 }
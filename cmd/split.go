@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+// splitManifest is the path of the JSON index written by split
+var splitManifest string
+
+var splitCmd = &cobra.Command{
+	Use:   "split <input.gif>",
+	Short: "Extract the frames of a GIF as PNG images",
+	Long: `Extract every frame of a GIF as a fully composited PNG image.
+Frames are named after the --output template, either printf style with the zero-based index (frame-%04d.png)
+or a Go template with {{.Index}} and {{.DelayMs}}. --manifest writes a JSON index of the frames and their delays.`,
+	Example: `  # Extract the frames with a JSON index of their delays
+  go-togif split demo.gif -o "frames/frame-%04d.png" --manifest frames.json
+
+  # Name the frames after their delay
+  go-togif split demo.gif -o "frames/{{.Index}}-{{.DelayMs}}ms.png"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		frames, err := converter.SplitGIF(args[0])
+		if err != nil {
+			return err
+		}
+		return writeFrameFiles(cmd, frames, tmpl, splitManifest, 0)
+	},
+}
+
+// writeFrameFiles saves frames as PNG images named after tmpl and, when manifest
+// is set, writes their JSON index there
+func writeFrameFiles(cmd *cobra.Command, frames []converter.Frame, tmpl, manifest string, defaultDelay time.Duration) error {
+	infos, err := converter.WriteFrames(frames, tmpl, defaultDelay)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d frames (%s ... %s)\n", len(infos), infos[0].File, infos[len(infos)-1].File)
+
+	if manifest == "" {
+		return nil
+	}
+	file, err := os.Create(manifest)
+	if err != nil {
+		return fmt.Errorf("error creating manifest: %v", err)
+	}
+	defer file.Close()
+	if err := converter.WriteManifest(file, infos); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Manifest: %s\n", manifest)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+
+	// Add flags
+	splitCmd.Flags().StringP("output", "o", converter.DefaultFrameTemplate, "Frame file template: printf style (frame-%04d.png) or Go template ({{.Index}}, {{.DelayMs}})")
+	splitCmd.Flags().StringVar(&splitManifest, "manifest", "", "Write a JSON index of the frames (file, index and delay) to this path")
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Generate a GIF and its frames, then split the GIF back into frames
+	inputFile := filepath.Join(tempDir, "bars.gif")
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"generate", "testpattern", "--pattern", "bars", "--width", "8", "--height", "8", "--frames", "3", "-o", inputFile,
+		"--frames-template", filepath.Join(tempDir, "gen-%02d.png"), "--manifest", filepath.Join(tempDir, "generated.json")})
+	defer rootCmd.SetArgs(nil)
+	err = rootCmd.Execute()
+	generateFrames, generateManifest = "", ""
+	if err != nil {
+		t.Fatalf("generate error = %v", err)
+	}
+	for _, name := range []string{"gen-00.png", "gen-02.png"} {
+		if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+			t.Errorf("generated frame %s not written: %v", name, err)
+		}
+	}
+
+	manifestFile := filepath.Join(tempDir, "frames.json")
+	rootCmd.SetArgs([]string{"split", inputFile, "-o", filepath.Join(tempDir, "split", "{{.Index}}.png"), "--manifest", manifestFile})
+	err = rootCmd.Execute()
+	splitManifest = ""
+	if err != nil {
+		t.Fatalf("split error = %v", err)
+	}
+
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest converter.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if len(manifest.Frames) != 3 {
+		t.Fatalf("manifest has %d frames, want 3", len(manifest.Frames))
+	}
+	last := manifest.Frames[2]
+	if last.File != filepath.Join(tempDir, "split", "2.png") || last.DelayMs != 100 {
+		t.Errorf("last frame = %+v, want split/2.png shown for 100ms", last)
+	}
+}
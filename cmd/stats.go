@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+// statsRecord is the line appended to the --stats-file for every conversion.
+// It stays on the local disk: nothing is ever sent anywhere.
+type statsRecord struct {
+	Time time.Time `json:"time"`
+	// Settings are the options shaping the workload, not the file names
+	Settings statsSettings `json:"settings"`
+	Inputs   int           `json:"inputs"`
+	// Milliseconds is the wall clock time of the conversion
+	Milliseconds int64         `json:"milliseconds"`
+	Frames       int           `json:"frames,omitempty"`
+	Width        int           `json:"width,omitempty"`
+	Height       int           `json:"height,omitempty"`
+	Outputs      []statsOutput `json:"outputs,omitempty"`
+	PeakHeap     uint64        `json:"peakHeap,omitempty"`
+	SSIM         float64       `json:"ssim,omitempty"`
+	// Error is set when the conversion failed
+	Error string `json:"error,omitempty"`
+}
+
+// statsSettings are the conversion settings recorded in the stats file
+type statsSettings struct {
+	Format    string   `json:"format,omitempty"`
+	Formats   []string `json:"formats,omitempty"`
+	Preset    string   `json:"preset,omitempty"`
+	Width     int      `json:"width,omitempty"`
+	MaxWidth  int      `json:"maxWidth,omitempty"`
+	Colors    int      `json:"colors,omitempty"`
+	Delay     int      `json:"delay"`
+	Quantizer string   `json:"quantizer,omitempty"`
+	Dither    string   `json:"dither,omitempty"`
+	Scaler    string   `json:"scaler,omitempty"`
+	Encoder   string   `json:"encoder,omitempty"`
+	Auto      bool     `json:"auto,omitempty"`
+	Workers   int      `json:"workers,omitempty"`
+	MaxFrames int      `json:"maxFrames,omitempty"`
+}
+
+// statsOutput is the size of a file written by a conversion
+type statsOutput struct {
+	Format string `json:"format,omitempty"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// newStatsRecord describes a conversion of inputs files with opts, started at
+// start and ending with report or err
+func newStatsRecord(start time.Time, inputs int, opts converter.Options, report *converter.Report, err error) statsRecord {
+	record := statsRecord{
+		Time: start.UTC(),
+		Settings: statsSettings{
+			Format:    opts.Format,
+			Formats:   opts.Formats,
+			Preset:    opts.Preset,
+			Width:     opts.Width,
+			MaxWidth:  opts.MaxWidth,
+			Colors:    opts.Colors,
+			Delay:     opts.Delay,
+			Quantizer: opts.Quantizer,
+			Dither:    opts.Dither,
+			Scaler:    opts.Scaler,
+			Encoder:   opts.Encoder,
+			Auto:      opts.Auto,
+			Workers:   opts.Workers,
+			MaxFrames: opts.MaxFrames,
+		},
+		Inputs:       inputs,
+		Milliseconds: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+
+	record.Frames = report.Frames
+	record.Width = report.Width
+	record.Height = report.Height
+	record.PeakHeap = report.PeakHeap
+	record.SSIM = report.SSIM
+	for _, output := range report.Outputs {
+		// Output file names are left out, they may reveal what is converted
+		stat := statsOutput{Format: output.Format, Width: output.Width, Height: output.Height}
+		if info, err := os.Stat(output.File); err == nil {
+			stat.Bytes = info.Size()
+		}
+		record.Outputs = append(record.Outputs, stat)
+	}
+	return record
+}
+
+// appendStats appends record to the JSON Lines file at path, creating it
+func appendStats(path string, record statsRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding stats: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening stats file: %v", err)
+	}
+	// A single write keeps the lines of concurrent runs whole
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing stats: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing stats: %v", err)
+	}
+	return nil
+}
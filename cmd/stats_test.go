@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+func TestAppendStats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	output := filepath.Join(tempDir, "secret-project.gif")
+	if err := os.WriteFile(output, make([]byte, 1234), 0644); err != nil {
+		t.Fatalf("Failed to write output: %v", err)
+	}
+	report := &converter.Report{
+		OutputFile: output,
+		Outputs:    []converter.Output{{File: output, Width: 64, Height: 32, Format: "gif"}},
+		Frames:     10,
+		Width:      64,
+		Height:     32,
+	}
+	opts := converter.Options{Delay: 80, Colors: 64, Dither: "floyd-steinberg"}
+	start := time.Now().Add(-time.Second)
+
+	// Every run appends a line, failed ones included
+	path := filepath.Join(tempDir, "stats.jsonl")
+	records := []statsRecord{
+		newStatsRecord(start, 10, opts, report, nil),
+		newStatsRecord(start, 3, opts, nil, errors.New("no input files found")),
+	}
+	for _, record := range records {
+		if err := appendStats(path, record); err != nil {
+			t.Fatalf("appendStats() error = %v", err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open stats: %v", err)
+	}
+	defer file.Close()
+	var got []statsRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), "secret-project") {
+			t.Errorf("stats line %s contains the output file name", scanner.Text())
+		}
+		var record statsRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Failed to parse stats line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, record)
+	}
+	if len(got) != 2 {
+		t.Fatalf("stats file has %d lines, want 2", len(got))
+	}
+
+	success := got[0]
+	if success.Inputs != 10 || success.Frames != 10 || success.Error != "" {
+		t.Errorf("success record = %+v, want 10 inputs and frames", success)
+	}
+	if success.Milliseconds < 1000 {
+		t.Errorf("success record took %dms, want at least 1000ms", success.Milliseconds)
+	}
+	if success.Settings.Colors != 64 || success.Settings.Delay != 80 || success.Settings.Dither != "floyd-steinberg" {
+		t.Errorf("success settings = %+v, want the conversion options", success.Settings)
+	}
+	if len(success.Outputs) != 1 || success.Outputs[0].Bytes != 1234 || success.Outputs[0].Width != 64 {
+		t.Errorf("success outputs = %+v, want a 64 pixel wide output of 1234 bytes", success.Outputs)
+	}
+	if failure := got[1]; failure.Error != "no input files found" || failure.Inputs != 3 || len(failure.Outputs) != 0 {
+		t.Errorf("failure record = %+v, want the error and no outputs", failure)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/termrender"
+	"github.com/jparrill/go-togif/pkg/typing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// typeOpts holds the typing animation settings bound to the command flags
+	typeOpts typing.Options
+	// typeText is typed instead of the contents of --input
+	typeText string
+	// typeDebug enables detailed progress output
+	typeDebug bool
+	// typeLottie is the path of the Lottie export of the animation
+	typeLottie string
+)
+
+var typeCmd = &cobra.Command{
+	Use:   "type",
+	Short: "Generate a typing animation GIF from a text or code snippet",
+	Long: `Generate a GIF of a text or code snippet being typed, with syntax highlighting and a blinking cursor.
+The snippet is read from a file (--input, "-" for stdin) or given directly with --text.`,
+	Example: `  # Type a Go file with the dracula style
+  go-togif type -i main.go -o typing.gif --speed 30 --style dracula
+
+  # Type a command read from stdin
+  echo "make release" | go-togif type -i - -o command.gif --language bash`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inputFile, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+		outputFile, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		if outputFile == "" && typeLottie == "" {
+			return fmt.Errorf("--output or --lottie is required")
+		}
+
+		text, err := readSnippet(cmd.InOrStdin(), inputFile, typeText)
+		if err != nil {
+			return err
+		}
+		opts := typeOpts
+		if inputFile != "-" {
+			opts.Filename = inputFile
+		}
+
+		if typeLottie != "" {
+			anim, err := typing.Lottie(text, opts)
+			if err != nil {
+				return err
+			}
+			if err := writeLottie(cmd, anim, typeLottie); err != nil {
+				return err
+			}
+			if outputFile == "" {
+				return nil
+			}
+		}
+
+		frames, err := typing.Generate(text, opts)
+		if err != nil {
+			return err
+		}
+
+		report, err := converter.ConvertFrames(frames, outputFile, converter.Options{Debug: typeDebug})
+		if err != nil {
+			return err
+		}
+
+		printReport(cmd.OutOrStdout(), report)
+		return nil
+	},
+}
+
+// readSnippet returns the text given with --text or read from the input file
+func readSnippet(stdin io.Reader, inputFile, text string) (string, error) {
+	switch {
+	case inputFile != "" && text != "":
+		return "", fmt.Errorf("--input and --text are mutually exclusive")
+	case text != "":
+		return text, nil
+	case inputFile == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("error reading stdin: %v", err)
+		}
+		return string(data), nil
+	case inputFile != "":
+		data, err := os.ReadFile(inputFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading %s: %v", inputFile, err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("either --input or --text is required")
+}
+
+func init() {
+	rootCmd.AddCommand(typeCmd)
+
+	// Add flags
+	typeCmd.Flags().StringP("input", "i", "", "File containing the snippet to type, - for stdin")
+	typeCmd.Flags().StringP("output", "o", "", "Output GIF file path (required unless --lottie is set)")
+	typeCmd.Flags().StringVar(&typeText, "text", "", "Snippet to type, instead of --input")
+	typeCmd.Flags().StringVarP(&typeOpts.Language, "language", "l", "", "Language used for syntax highlighting (detected when empty)")
+	typeCmd.Flags().StringVar(&typeOpts.Style, "style", typing.DefaultStyle, "Syntax highlighting style (any chroma style, e.g. monokai, github, dracula)")
+	typeCmd.Flags().Float64Var(&typeOpts.Speed, "speed", typing.DefaultSpeed, "Typing speed in characters per second")
+	typeCmd.Flags().Float64Var(&typeOpts.FontSize, "font-size", termrender.DefaultFontSize, "Font size in pixels")
+	typeCmd.Flags().DurationVar(&typeOpts.Hold, "hold", typing.DefaultHold, "How long the finished snippet is shown before looping")
+	typeCmd.Flags().BoolVar(&typeOpts.NoBlink, "no-blink", false, "Keep the cursor solid instead of blinking at the end")
+	typeCmd.Flags().BoolVar(&typeDebug, "debug", false, "Enable debug mode to show detailed progress")
+	typeCmd.Flags().StringVar(&typeLottie, "lottie", "", "Also write the animation as vector Lottie JSON to this path")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSnippet(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	snippetFile := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(snippetFile, []byte("package main"), 0644); err != nil {
+		t.Fatalf("Failed to write snippet: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		stdin     string
+		inputFile string
+		text      string
+		want      string
+		wantErr   bool
+	}{
+		{name: "text", text: "hello", want: "hello"},
+		{name: "file", inputFile: snippetFile, want: "package main"},
+		{name: "stdin", inputFile: "-", stdin: "from stdin", want: "from stdin"},
+		{name: "both", inputFile: snippetFile, text: "hello", wantErr: true},
+		{name: "neither", wantErr: true},
+		{name: "missing file", inputFile: filepath.Join(tempDir, "missing.go"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readSnippet(strings.NewReader(tt.stdin), tt.inputFile, tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readSnippet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/spf13/cobra"
+)
+
+// version is the release of go-togif, set when building a release with
+// -ldflags "-X github.com/jparrill/go-togif/cmd.version=v1.2.3"
+var version = "dev"
+
+var (
+	// versionVerbose also lists the build settings and capabilities
+	versionVerbose bool
+	// versionJSON prints the version as JSON for scripts
+	versionJSON bool
+)
+
+// buildInfo describes how the binary was built
+type buildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commitTime,omitempty"`
+	// Modified is set when the commit had uncommitted changes
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	CGO       bool   `json:"cgo"`
+	Tags      string `json:"tags,omitempty"`
+}
+
+// versionReport is the output of version --json
+type versionReport struct {
+	buildInfo
+	Capabilities []converter.Capability `json:"capabilities,omitempty"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, build and capabilities of go-togif",
+	Long: `Print the version of go-togif.
+With --verbose, also print how it was built and which optional capabilities are available:
+output formats needing libavif or ffmpeg and its encoders, and vectorized code paths.
+Include it in bug reports; scripts can read it with --json.`,
+	Example: `  # Show the build and capabilities for a bug report
+  go-togif version --verbose
+
+  # Check for WebP support in a script
+  go-togif version --verbose --json | jq -e '.capabilities[] | select(.name == "webp") | .available'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report := versionReport{buildInfo: readBuildInfo()}
+		if versionVerbose {
+			report.Capabilities = converter.Capabilities()
+		}
+		if versionJSON {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error encoding version: %v", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return nil
+		}
+		printVersion(cmd.OutOrStdout(), report, versionVerbose)
+		return nil
+	},
+}
+
+// readBuildInfo returns the version and the build settings embedded by the
+// Go toolchain
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// go install module@version records the version without ldflags
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.CommitTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		case "CGO_ENABLED":
+			info.CGO = setting.Value == "1"
+		case "-tags":
+			info.Tags = setting.Value
+		}
+	}
+	return info
+}
+
+// printVersion writes the version, and the build and capabilities when verbose
+func printVersion(w io.Writer, report versionReport, verbose bool) {
+	fmt.Fprintf(w, "go-togif %s\n", report.Version)
+	if !verbose {
+		return
+	}
+
+	if report.Commit != "" {
+		commit := report.Commit
+		if report.CommitTime != "" {
+			commit += " (" + report.CommitTime + ")"
+		}
+		if report.Modified {
+			commit += ", modified"
+		}
+		fmt.Fprintf(w, "Commit: %s\n", commit)
+	}
+	cgo := "disabled"
+	if report.CGO {
+		cgo = "enabled"
+	}
+	fmt.Fprintf(w, "Built with: %s %s, cgo %s", report.GoVersion, report.Platform, cgo)
+	if report.Tags != "" {
+		fmt.Fprintf(w, ", tags %s", report.Tags)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Capabilities:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, capability := range report.Capabilities {
+		available := "no"
+		if capability.Available {
+			available = "yes"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", capability.Name, available, strings.TrimSpace(capability.Detail))
+	}
+	tw.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.Version = readBuildInfo().Version
+
+	versionCmd.Flags().BoolVarP(&versionVerbose, "verbose", "v", false, "Also print the build settings and the optional capabilities available")
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print the version as JSON, with the capabilities when --verbose is set")
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+func TestVersionCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	// Keep the capabilities independent of the ffmpeg installed
+	t.Setenv(converter.FFmpegEnv, filepath.Join(tempDir, "missing"))
+
+	tests := []struct {
+		name  string
+		args  []string
+		check func(t *testing.T, out string)
+	}{
+		{
+			name: "short",
+			args: []string{"version"},
+			check: func(t *testing.T, out string) {
+				if out != "go-togif "+readBuildInfo().Version+"\n" {
+					t.Errorf("version output = %q, want the version only", out)
+				}
+			},
+		},
+		{
+			name: "verbose",
+			args: []string{"version", "--verbose"},
+			check: func(t *testing.T, out string) {
+				for _, want := range []string{"Built with: go", "Capabilities:", "ffmpeg", "no", "simd"} {
+					if !strings.Contains(out, want) {
+						t.Errorf("version output = %q, want %q", out, want)
+					}
+				}
+			},
+		},
+		{
+			name: "json",
+			args: []string{"version", "--verbose", "--json"},
+			check: func(t *testing.T, out string) {
+				var report versionReport
+				if err := json.Unmarshal([]byte(out), &report); err != nil {
+					t.Fatalf("version output = %q, not JSON: %v", out, err)
+				}
+				if report.Version == "" || report.GoVersion == "" || len(report.Capabilities) == 0 {
+					t.Errorf("version report = %+v, want a version, a Go version and capabilities", report)
+				}
+				for _, capability := range report.Capabilities {
+					if capability.Name == "ffmpeg" && capability.Available {
+						t.Errorf("ffmpeg capability = %+v, want unavailable", capability)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versionVerbose, versionJSON = false, false
+			defer func() { versionVerbose, versionJSON = false, false }()
+
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(tt.args)
+			defer rootCmd.SetArgs(nil)
+
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("version error = %v", err)
+			}
+			tt.check(t, out.String())
+		})
+	}
+}
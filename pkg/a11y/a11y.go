@@ -0,0 +1,161 @@
+package a11y
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Deficiency is a type of color vision deficiency that can be simulated
+type Deficiency string
+
+const (
+	Protanopia   Deficiency = "protanopia"
+	Deuteranopia Deficiency = "deuteranopia"
+)
+
+// Deficiencies lists every simulated deficiency
+var Deficiencies = []Deficiency{Deuteranopia, Protanopia}
+
+// WCAG 2 contrast thresholds for AA conformance
+const (
+	MinContrastNormalText = 4.5
+	MinContrastLargeText  = 3.0
+	// LargeTextSize is the size in pixels (18pt) from which text counts as large
+	LargeTextSize = 24.0
+)
+
+// simulationMatrices holds the Machado et al. (2009) matrices for full severity, applied in linear RGB
+var simulationMatrices = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+}
+
+// Simulate returns how img is perceived with the given color vision deficiency
+func Simulate(img image.Image, d Deficiency) (*image.RGBA, error) {
+	m, ok := simulationMatrices[d]
+	if !ok {
+		return nil, fmt.Errorf("unknown color vision deficiency %q", d)
+	}
+
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.SetRGBA(x, y, simulateColor(img.At(x, y), m))
+		}
+	}
+	return out, nil
+}
+
+// SimulateColor returns how c is perceived with the given color vision deficiency
+func SimulateColor(c color.Color, d Deficiency) color.RGBA {
+	m, ok := simulationMatrices[d]
+	if !ok {
+		r, g, b, a := c.RGBA()
+		return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+	}
+	return simulateColor(c, m)
+}
+
+func simulateColor(c color.Color, m [3][3]float64) color.RGBA {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	lin := [3]float64{toLinear(nrgba.R), toLinear(nrgba.G), toLinear(nrgba.B)}
+
+	var out [3]uint8
+	for i := range out {
+		v := m[i][0]*lin[0] + m[i][1]*lin[1] + m[i][2]*lin[2]
+		out[i] = toSRGB(v)
+	}
+
+	// Back to premultiplied alpha
+	result := color.NRGBA{out[0], out[1], out[2], nrgba.A}
+	return color.RGBAModel.Convert(result).(color.RGBA)
+}
+
+// RelativeLuminance computes the WCAG relative luminance of an opaque color
+func RelativeLuminance(c color.Color) float64 {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return 0.2126*toLinear(nrgba.R) + 0.7152*toLinear(nrgba.G) + 0.0722*toLinear(nrgba.B)
+}
+
+// ContrastRatio computes the WCAG contrast ratio between two colors, from 1 to 21
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := RelativeLuminance(a), RelativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// MinContrast returns the threshold text of the given size in pixels must meet
+func MinContrast(textSize float64) float64 {
+	if textSize >= LargeTextSize {
+		return MinContrastLargeText
+	}
+	return MinContrastNormalText
+}
+
+// TextContrast returns the lowest contrast between the text color fg and the
+// backdrop inside region, after blending the translucent text background bg over it.
+// The check is repeated for every simulated deficiency.
+func TextContrast(fg, bg color.Color, backdrop image.Image, region image.Rectangle) float64 {
+	region = region.Intersect(backdrop.Bounds())
+	worst := math.Inf(1)
+	if region.Empty() {
+		return worst
+	}
+
+	// Sample the region on a coarse grid, captions are large enough for it
+	step := max(1, min(region.Dx(), region.Dy())/16)
+	for y := region.Min.Y; y < region.Max.Y; y += step {
+		for x := region.Min.X; x < region.Max.X; x += step {
+			under := blend(bg, backdrop.At(x, y))
+			worst = min(worst, ContrastRatio(fg, under))
+			for _, d := range Deficiencies {
+				worst = min(worst, ContrastRatio(SimulateColor(fg, d), SimulateColor(under, d)))
+			}
+		}
+	}
+	return worst
+}
+
+// blend composites the (possibly translucent) color top over the opaque color bottom
+func blend(top, bottom color.Color) color.RGBA {
+	tr, tg, tb, ta := top.RGBA()
+	br, bg, bb, _ := bottom.RGBA()
+	inv := 0xffff - ta
+	return color.RGBA{
+		R: uint8((tr + br*inv/0xffff) >> 8),
+		G: uint8((tg + bg*inv/0xffff) >> 8),
+		B: uint8((tb + bb*inv/0xffff) >> 8),
+		A: 255,
+	}
+}
+
+func toLinear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func toSRGB(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(v * 255))
+}
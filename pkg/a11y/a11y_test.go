@@ -0,0 +1,106 @@
+package a11y
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b color.Color
+		want float64
+	}{
+		{name: "Black on white", a: color.Black, b: color.White, want: 21},
+		{name: "Same color", a: color.RGBA{128, 64, 32, 255}, b: color.RGBA{128, 64, 32, 255}, want: 1},
+		{name: "Order does not matter", a: color.White, b: color.RGBA{118, 118, 118, 255}, want: 4.54},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContrastRatio(tt.a, tt.b); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("ContrastRatio() = %.3f, want %.3f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	red := color.RGBA{200, 40, 40, 255}
+	green := color.RGBA{40, 160, 40, 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, green)
+
+	for _, d := range Deficiencies {
+		t.Run(string(d), func(t *testing.T) {
+			simulated, err := Simulate(img, d)
+			if err != nil {
+				t.Fatalf("Simulate() error = %v", err)
+			}
+
+			// Red and green get much closer in hue, which is what makes them hard to tell apart
+			before := hueDistance(red, green)
+			after := hueDistance(simulated.RGBAAt(0, 0), simulated.RGBAAt(1, 0))
+			if after >= before/2 {
+				t.Errorf("Simulate() red/green distance %.1f, want well below %.1f", after, before)
+			}
+
+			// Neutral grays are not affected
+			if got := SimulateColor(color.RGBA{128, 128, 128, 255}, d); absDiff(got.R, 128) > 1 || absDiff(got.B, 128) > 1 {
+				t.Errorf("SimulateColor() gray = %v, want unchanged", got)
+			}
+		})
+	}
+
+	if _, err := Simulate(img, "tritanomaly"); err == nil {
+		t.Error("Simulate() expected an error for an unknown deficiency")
+	}
+}
+
+func TestTextContrast(t *testing.T) {
+	caption := color.RGBA{0, 0, 0, 180}
+	region := image.Rect(0, 0, 10, 10)
+
+	dark := image.NewRGBA(region)
+	draw.Draw(dark, region, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	light := image.NewRGBA(region)
+	draw.Draw(light, region, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	onDark := TextContrast(color.White, caption, dark, region)
+	onLight := TextContrast(color.White, caption, light, region)
+
+	if onDark < MinContrastNormalText {
+		t.Errorf("TextContrast() on dark backdrop = %.2f, want at least %.1f", onDark, MinContrastNormalText)
+	}
+	if onLight >= onDark {
+		t.Errorf("TextContrast() on light backdrop = %.2f, want below %.2f", onLight, onDark)
+	}
+}
+
+func TestMinContrast(t *testing.T) {
+	if got := MinContrast(16); got != MinContrastNormalText {
+		t.Errorf("MinContrast(16) = %v, want %v", got, MinContrastNormalText)
+	}
+	if got := MinContrast(32); got != MinContrastLargeText {
+		t.Errorf("MinContrast(32) = %v, want %v", got, MinContrastLargeText)
+	}
+}
+
+// hueDistance measures how far apart two colors are on the red/green axis
+func hueDistance(a, b color.RGBA) float64 {
+	da := float64(a.R) - float64(a.G)
+	db := float64(b.R) - float64(b.G)
+	return math.Abs(da - db)
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,263 @@
+// Package compat finds the features of a GIF known to break in some
+// renderers, such as old Android versions, Outlook or terminals
+package compat
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// Feature is a GIF feature some renderers play wrong
+type Feature string
+
+const (
+	// Animated GIFs have more than one frame
+	Animated Feature = "animated"
+	// Interlaced frames store their rows in four passes
+	Interlaced Feature = "interlaced"
+	// LocalPalette frames carry their own color table
+	LocalPalette Feature = "local-palette"
+	// Transparency frames have a transparent color index
+	Transparency Feature = "transparency"
+	// DisposalBackground frames are cleared once shown
+	DisposalBackground Feature = "disposal-background"
+	// DisposalPrevious frames are undone once shown
+	DisposalPrevious Feature = "disposal-previous"
+	// ShortDelay frames of an animation are shown for less than 20ms
+	ShortDelay Feature = "short-delay"
+	// PartialFrame frames cover only part of the logical screen
+	PartialFrame Feature = "partial-frame"
+)
+
+// Features lists every feature, in the order issues are reported
+var Features = []Feature{Animated, Interlaced, LocalPalette, Transparency, DisposalBackground, DisposalPrevious, ShortDelay, PartialFrame}
+
+// Usage lists the 0-based frames using each feature found in a GIF
+type Usage map[Feature][]int
+
+// ScanFile scans the GIF file at path
+func ScanFile(path string) (Usage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	usage, _, err := scan(data)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %v", path, err)
+	}
+	return usage, nil
+}
+
+// Scan reads a GIF from r and returns the features it uses, reading its
+// blocks without decoding the pixels
+func Scan(r io.Reader) (Usage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	usage, _, err := scan(data)
+	return usage, err
+}
+
+// scan returns the features data uses along with the offset of the image
+// descriptor of every frame
+func scan(data []byte) (Usage, []int, error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, nil, fmt.Errorf("not a GIF file")
+	}
+	width, height := int(binary.LittleEndian.Uint16(data[6:8])), int(binary.LittleEndian.Uint16(data[8:10]))
+	pos := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&7 + 1)
+	}
+
+	usage := make(Usage)
+	add := func(feature Feature, frame int) {
+		usage[feature] = append(usage[feature], frame)
+	}
+	var descriptors []int
+	var delays []int
+	// control holds the graphic control extension of the next frame
+	var control []byte
+	for {
+		if pos >= len(data) {
+			return nil, nil, fmt.Errorf("missing trailer")
+		}
+		switch data[pos] {
+		case 0x3b:
+			if len(descriptors) > 1 {
+				usage[Animated] = make([]int, len(descriptors))
+				for i := range descriptors {
+					usage[Animated][i] = i
+				}
+				for i, delay := range delays {
+					if delay < 2 {
+						add(ShortDelay, i)
+					}
+				}
+			}
+			return usage, descriptors, nil
+
+		case 0x21:
+			if pos+2 > len(data) {
+				return nil, nil, fmt.Errorf("truncated extension")
+			}
+			label := data[pos+1]
+			end, err := skipSubBlocks(data, pos+2)
+			if err != nil {
+				return nil, nil, err
+			}
+			if label == 0xf9 && data[pos+2] >= 4 {
+				control = data[pos+3 : pos+7]
+			}
+			pos = end
+
+		case 0x2c:
+			if pos+10 > len(data) {
+				return nil, nil, fmt.Errorf("truncated image descriptor")
+			}
+			frame := len(descriptors)
+			descriptors = append(descriptors, pos)
+			d := data[pos : pos+10]
+			x, y := int(binary.LittleEndian.Uint16(d[1:3])), int(binary.LittleEndian.Uint16(d[3:5]))
+			w, h := int(binary.LittleEndian.Uint16(d[5:7])), int(binary.LittleEndian.Uint16(d[7:9]))
+			if x != 0 || y != 0 || w != width || h != height {
+				add(PartialFrame, frame)
+			}
+			if d[9]&0x40 != 0 {
+				add(Interlaced, frame)
+			}
+			pos += 10
+			if d[9]&0x80 != 0 {
+				add(LocalPalette, frame)
+				pos += 3 << (d[9]&7 + 1)
+			}
+
+			delay := 0
+			if control != nil {
+				switch (control[0] >> 2) & 7 {
+				case 2:
+					add(DisposalBackground, frame)
+				case 3:
+					add(DisposalPrevious, frame)
+				}
+				if control[0]&1 != 0 {
+					add(Transparency, frame)
+				}
+				delay = int(binary.LittleEndian.Uint16(control[1:3]))
+				control = nil
+			}
+			delays = append(delays, delay)
+
+			// Skip the LZW minimum code size and the pixels
+			end, err := skipSubBlocks(data, pos+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			pos = end
+
+		default:
+			return nil, nil, fmt.Errorf("unknown block 0x%02x at offset %d", data[pos], pos)
+		}
+	}
+}
+
+// skipSubBlocks returns the offset after the sub-blocks starting at pos
+func skipSubBlocks(data []byte, pos int) (int, error) {
+	for {
+		if pos >= len(data) {
+			return 0, fmt.Errorf("truncated data sub-blocks")
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+}
+
+// Renderer is a GIF player with the features it is known to play wrong
+type Renderer struct {
+	Name        string
+	Description string
+	// Breaks describes the problem of every feature played wrong
+	Breaks map[Feature]string
+}
+
+// Renderers lists the renderers issues are reported for
+var Renderers = []Renderer{
+	{
+		Name:        "browsers",
+		Description: "Chrome, Firefox and Safari",
+		Breaks: map[Feature]string{
+			ShortDelay: "frames shown for less than 20ms are slowed down to 100ms",
+		},
+	},
+	{
+		Name:        "outlook",
+		Description: "Outlook 2007 to 2019 for Windows",
+		Breaks: map[Feature]string{
+			Animated: "only the first frame is shown, pick a meaningful one with --poster-frame",
+		},
+	},
+	{
+		Name:        "old-android",
+		Description: "Android before 9, through android.graphics.Movie",
+		Breaks: map[Feature]string{
+			Interlaced:         "interlaced frames are known to be drawn with misplaced rows",
+			LocalPalette:       "frames with their own palette are known to be drawn with the colors of the global one",
+			DisposalBackground: "cleared frames are filled with the background color instead of becoming transparent",
+			DisposalPrevious:   "frames restoring the previous one are left in place, leaving trails",
+		},
+	},
+	{
+		Name:        "terminals",
+		Description: "terminal image viewers such as sixel converters",
+		Breaks: map[Feature]string{
+			Animated:     "most viewers show the first frame only",
+			Transparency: "transparent pixels are shown black or in the terminal background color",
+			PartialFrame: "frames smaller than the screen are shown alone, without the frames before them",
+		},
+	},
+}
+
+// Issue is a feature of a GIF a renderer plays wrong
+type Issue struct {
+	Renderer string
+	Feature  Feature
+	Problem  string
+	// Frames are the 0-based frames using the feature
+	Frames []int
+}
+
+// String describes the issue
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s, %d %s)", i.Renderer, i.Problem, i.Feature, len(i.Frames), plural(len(i.Frames), "frame"))
+}
+
+// plural returns noun, with an s unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// Issues returns the issues of the features in use, by renderer then in
+// the order of Features
+func (u Usage) Issues() []Issue {
+	var issues []Issue
+	for _, renderer := range Renderers {
+		for _, feature := range Features {
+			problem, breaks := renderer.Breaks[feature]
+			if frames := u[feature]; breaks && len(frames) > 0 {
+				issues = append(issues, Issue{Renderer: renderer.Name, Feature: feature, Problem: problem, Frames: slices.Clone(frames)})
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,64 @@
+package compat
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	for _, s := range corpus() {
+		t.Run(s.name, func(t *testing.T) {
+			got, err := Scan(bytes.NewReader(encode(t, s)))
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, s.want) {
+				t.Errorf("Scan() = %v, want %v", got, s.want)
+			}
+		})
+	}
+}
+
+func TestScanInvalid(t *testing.T) {
+	valid := encode(t, corpus()[1])
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "not a GIF", data: []byte("\x89PNG\r\n\x1a\n0000000000"), wantErr: "not a GIF file"},
+		{name: "truncated", data: valid[:len(valid)-20], wantErr: "truncated"},
+		{name: "no trailer", data: valid[:len(valid)-1], wantErr: "missing trailer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Scan(bytes.NewReader(tt.data)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Scan() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIssues(t *testing.T) {
+	usage := Usage{Animated: {0, 1}, DisposalPrevious: {1}, Transparency: {0, 1}}
+	var got []string
+	for _, issue := range usage.Issues() {
+		got = append(got, issue.Renderer+" "+string(issue.Feature))
+	}
+	want := []string{"outlook animated", "old-android disposal-previous", "terminals animated", "terminals transparency"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Issues() = %v, want %v", got, want)
+	}
+
+	if issues := (Usage{}).Issues(); len(issues) != 0 {
+		t.Errorf("Issues() of a still GIF = %v, want none", issues)
+	}
+
+	issue := usage.Issues()[1]
+	if s := issue.String(); s != "old-android: frames restoring the previous one are left in place, leaving trails (disposal-previous, 1 frame)" {
+		t.Errorf("String() = %s", s)
+	}
+}
@@ -0,0 +1,176 @@
+package compat
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/encoder"
+)
+
+// sample is a GIF of the corpus, exercising some features
+type sample struct {
+	name string
+	g    *gif.GIF
+	// interlace stores the frames interlaced
+	interlace bool
+	want      Usage
+}
+
+var (
+	opaquePalette      = color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	otherPalette       = color.Palette{color.RGBA{255, 255, 255, 255}, color.RGBA{255, 255, 0, 255}}
+	transparentPalette = color.Palette{color.RGBA{0, 0, 0, 0}, color.RGBA{255, 0, 0, 255}}
+)
+
+// pattern returns a frame of the given bounds whose pixels cycle through the
+// palette, starting at offset
+func pattern(r image.Rectangle, p color.Palette, offset int) *image.Paletted {
+	img := image.NewPaletted(r, p)
+	for i := range img.Pix {
+		img.Pix[i] = uint8((i + offset) % len(p))
+	}
+	return img
+}
+
+// corpus returns a GIF for every feature the encoder writes
+func corpus() []sample {
+	screen := image.Rect(0, 0, 8, 8)
+	frames := func(n int) []*image.Paletted {
+		var images []*image.Paletted
+		for i := 0; i < n; i++ {
+			images = append(images, pattern(screen, opaquePalette, i))
+		}
+		return images
+	}
+	return []sample{
+		{name: "still", g: &gif.GIF{Image: frames(1), Delay: []int{0}}, want: Usage{}},
+		{
+			name: "animated",
+			g:    &gif.GIF{Image: frames(3), Delay: []int{10, 10, 10}},
+			want: Usage{Animated: {0, 1, 2}},
+		},
+		{
+			name: "short delays",
+			g:    &gif.GIF{Image: frames(3), Delay: []int{0, 1, 2}},
+			want: Usage{Animated: {0, 1, 2}, ShortDelay: {0, 1}},
+		},
+		{
+			name: "local palette",
+			g: &gif.GIF{
+				Image:  []*image.Paletted{pattern(screen, opaquePalette, 0), pattern(screen, otherPalette, 1)},
+				Delay:  []int{10, 10},
+				Config: image.Config{ColorModel: opaquePalette, Width: 8, Height: 8},
+			},
+			want: Usage{Animated: {0, 1}, LocalPalette: {1}},
+		},
+		{
+			name: "transparency",
+			g: &gif.GIF{
+				Image: []*image.Paletted{pattern(screen, transparentPalette, 0), pattern(screen, transparentPalette, 1)},
+				Delay: []int{10, 10},
+			},
+			want: Usage{Animated: {0, 1}, Transparency: {0, 1}},
+		},
+		{
+			name: "disposal modes",
+			g:    &gif.GIF{Image: frames(3), Delay: []int{10, 10, 10}, Disposal: []byte{gif.DisposalBackground, gif.DisposalPrevious, gif.DisposalNone}},
+			want: Usage{Animated: {0, 1, 2}, DisposalBackground: {0}, DisposalPrevious: {1}},
+		},
+		{
+			name: "partial frame",
+			g: &gif.GIF{
+				Image:  []*image.Paletted{pattern(screen, opaquePalette, 0), pattern(image.Rect(2, 3, 6, 5), opaquePalette, 1)},
+				Delay:  []int{10, 10},
+				Config: image.Config{Width: 8, Height: 8},
+			},
+			want: Usage{Animated: {0, 1}, PartialFrame: {1}},
+		},
+		{
+			name:      "interlaced",
+			g:         &gif.GIF{Image: frames(1), Delay: []int{0}},
+			interlace: true,
+			want:      Usage{Interlaced: {0}},
+		},
+	}
+}
+
+// interlacedRows returns the rows of an image of the given height in the
+// order interlaced GIFs store them
+func interlacedRows(height int) []int {
+	var rows []int
+	for _, pass := range []struct{ start, step int }{{0, 8}, {4, 8}, {2, 4}, {1, 2}} {
+		for y := pass.start; y < height; y += pass.step {
+			rows = append(rows, y)
+		}
+	}
+	return rows
+}
+
+// encode writes s with the native encoder, interlacing its frames when asked
+func encode(t *testing.T, s sample) []byte {
+	t.Helper()
+	g := *s.g
+	if s.interlace {
+		// Store the rows in pass order, then flag the frames as interlaced
+		g.Image = nil
+		for _, img := range s.g.Image {
+			stored := image.NewPaletted(img.Rect, img.Palette)
+			for i, y := range interlacedRows(img.Rect.Dy()) {
+				copy(stored.Pix[i*stored.Stride:(i+1)*stored.Stride], img.Pix[y*img.Stride:(y+1)*img.Stride])
+			}
+			g.Image = append(g.Image, stored)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := encoder.EncodeAll(&buf, &g, encoder.Options{}); err != nil {
+		t.Fatalf("EncodeAll() error = %v", err)
+	}
+	data := buf.Bytes()
+	if s.interlace {
+		_, descriptors, err := scan(data)
+		if err != nil {
+			t.Fatalf("scan() error = %v", err)
+		}
+		for _, d := range descriptors {
+			data[d+9] |= 0x40
+		}
+	}
+	return data
+}
+
+// TestCorpusConformance checks that image/gif decodes every GIF of the corpus
+// written by the native encoder to the frames it was given
+func TestCorpusConformance(t *testing.T) {
+	for _, s := range corpus() {
+		t.Run(s.name, func(t *testing.T) {
+			got, err := gif.DecodeAll(bytes.NewReader(encode(t, s)))
+			if err != nil {
+				t.Fatalf("DecodeAll() error = %v", err)
+			}
+			if len(got.Image) != len(s.g.Image) {
+				t.Fatalf("DecodeAll() got %d frames, want %d", len(got.Image), len(s.g.Image))
+			}
+			for i, want := range s.g.Image {
+				frame := got.Image[i]
+				if frame.Rect != want.Rect || !bytes.Equal(frame.Pix, want.Pix) {
+					t.Errorf("frame %d = %v %v, want %v %v", i, frame.Rect, frame.Pix, want.Rect, want.Pix)
+				}
+				for c := range want.Palette {
+					if color.RGBAModel.Convert(frame.Palette[c]) != color.RGBAModel.Convert(want.Palette[c]) {
+						t.Errorf("frame %d color %d = %v, want %v", i, c, frame.Palette[c], want.Palette[c])
+					}
+				}
+				if got.Delay[i] != s.g.Delay[i] {
+					t.Errorf("frame %d delay = %d, want %d", i, got.Delay[i], s.g.Delay[i])
+				}
+				if s.g.Disposal != nil && got.Disposal[i] != s.g.Disposal[i] {
+					t.Errorf("frame %d disposal = %d, want %d", i, got.Disposal[i], s.g.Disposal[i])
+				}
+			}
+		})
+	}
+}
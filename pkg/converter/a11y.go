@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/a11y"
+	"github.com/jparrill/go-togif/pkg/overlay"
+	xdraw "golang.org/x/image/draw"
+)
+
+// captionContrastOK reports whether caption text drawn over box of the original frame meets WCAG AA
+func captionContrastOK(frame image.Image, box image.Rectangle, style overlay.CaptionStyle) bool {
+	contrast := a11y.TextContrast(style.Foreground, style.Background, frame, box)
+	return contrast >= a11y.MinContrast(overlay.CaptionSize(frame.Bounds()))
+}
+
+// sampleFrames picks the first, middle and last frames
+func sampleFrames(frames []image.Image) []image.Image {
+	switch len(frames) {
+	case 0, 1, 2, 3:
+		return frames
+	default:
+		return []image.Image{frames[0], frames[len(frames)/2], frames[len(frames)-1]}
+	}
+}
+
+// writeA11yPreviews writes, next to the output file, one strip of sample frames per
+// simulated color vision deficiency and returns their paths
+func writeA11yPreviews(frames []image.Image, outputFile string) ([]string, error) {
+	samples := sampleFrames(frames)
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	// Lay the samples out side by side
+	bounds := samples[0].Bounds()
+	strip := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*len(samples), bounds.Dy()))
+	for i, img := range samples {
+		dst := image.Rect(i*bounds.Dx(), 0, (i+1)*bounds.Dx(), bounds.Dy())
+		xdraw.Draw(strip, dst, img, img.Bounds().Min, xdraw.Src)
+	}
+
+	base := strings.TrimSuffix(outputFile, filepath.Ext(outputFile))
+	var paths []string
+	for _, d := range a11y.Deficiencies {
+		simulated, err := a11y.Simulate(strip, d)
+		if err != nil {
+			return nil, err
+		}
+
+		path := fmt.Sprintf("%s.%s.png", base, d)
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("error creating accessibility preview: %v", err)
+		}
+		if err := png.Encode(file, simulated); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error encoding accessibility preview: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			return nil, fmt.Errorf("error writing accessibility preview: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
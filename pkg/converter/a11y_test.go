@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+func TestConvertA11yCheck(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Light gray text over a light translucent box on a white frame is hard to read
+	img := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 120; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	input := filepath.Join(tempDir, "frame.png")
+	f, err := os.Create(input)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	f.Close()
+
+	subtitles := filepath.Join(tempDir, "subs.srt")
+	if err := os.WriteFile(subtitles, []byte("1\n00:00:00,000 --> 00:00:10,000\nHello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write subtitles: %v", err)
+	}
+
+	output := filepath.Join(tempDir, "output.gif")
+	report, err := Convert([]string{input}, output, Options{
+		Delay:     100,
+		Subtitles: subtitles,
+		CaptionStyle: overlay.CaptionStyle{
+			Foreground: color.RGBA{200, 200, 200, 255},
+			Background: color.RGBA{40, 40, 40, 40},
+		},
+		A11yCheck: true,
+	})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	if len(report.A11yPreviews) != 2 {
+		t.Fatalf("Convert() wrote %d previews, want 2", len(report.A11yPreviews))
+	}
+	for _, preview := range report.A11yPreviews {
+		if _, err := os.Stat(preview); err != nil {
+			t.Errorf("Preview %s was not created: %v", preview, err)
+		}
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("Convert() warnings = %v, want one contrast warning", report.Warnings)
+	}
+}
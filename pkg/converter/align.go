@@ -0,0 +1,113 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+)
+
+const (
+	// alignCoarseSide is the side of the template after downscaling for the
+	// coarse search of every frame
+	alignCoarseSide = 16
+	// alignMaxDifference is the mean luminance difference per pixel above
+	// which the template is considered missing from a frame
+	alignMaxDifference = 24
+)
+
+// newTemplateAligner returns a stabilizer keeping the template image at the
+// same place in the frames [first, last) of src, finding it in every frame
+func newTemplateAligner(src source, first, last int, template image.Image) (*stabilizer, error) {
+	tmpl := grayImage(template)
+	offsets := make([]image.Point, last-first)
+	var size, origin image.Point
+	for i := first; i < last; i++ {
+		img, err := loadSized(src, i, &size)
+		if err != nil {
+			return nil, err
+		}
+		frame := grayImage(img)
+		if tmpl.Rect.Dx() > size.X || tmpl.Rect.Dy() > size.Y {
+			return nil, fmt.Errorf("alignment template of %dx%d is larger than the %dx%d frames",
+				tmpl.Rect.Dx(), tmpl.Rect.Dy(), size.X, size.Y)
+		}
+		at, difference := matchTemplate(frame, tmpl)
+		if difference > alignMaxDifference {
+			return nil, fmt.Errorf("alignment template not found in %s", src.names[i])
+		}
+		if i == first {
+			origin = at
+		}
+		offsets[i-first] = at.Sub(origin)
+	}
+	return shiftedStabilizer(size, offsets)
+}
+
+// matchTemplate returns where tmpl best matches frame and the mean luminance
+// difference there, searching downscaled images before refining around the
+// coarse match
+func matchTemplate(frame, tmpl *image.Gray) (image.Point, float64) {
+	scale := max(1, min(tmpl.Rect.Dx(), tmpl.Rect.Dy())/alignCoarseSide)
+	search := image.Rect(0, 0, frame.Rect.Dx()-tmpl.Rect.Dx()+1, frame.Rect.Dy()-tmpl.Rect.Dy()+1)
+	if scale > 1 {
+		coarse, _ := bestMatch(downscaleGray(frame, scale), downscaleGray(tmpl, scale), image.Rectangle{})
+		around := coarse.Mul(scale)
+		search = image.Rect(around.X-2*scale, around.Y-2*scale, around.X+2*scale+1, around.Y+2*scale+1).Intersect(search)
+	}
+	at, sum := bestMatch(frame, tmpl, search)
+	return at, float64(sum) / float64(tmpl.Rect.Dx()*tmpl.Rect.Dy())
+}
+
+// bestMatch returns the position among search with the smallest sum of
+// absolute differences between tmpl and frame, and that sum. An empty search
+// area tries every position.
+func bestMatch(frame, tmpl *image.Gray, search image.Rectangle) (image.Point, int64) {
+	if search.Empty() {
+		search = image.Rect(0, 0, frame.Rect.Dx()-tmpl.Rect.Dx()+1, frame.Rect.Dy()-tmpl.Rect.Dy()+1)
+	}
+	w, h := tmpl.Rect.Dx(), tmpl.Rect.Dy()
+	best, bestSum := search.Min, int64(math.MaxInt64)
+	for y := search.Min.Y; y < search.Max.Y; y++ {
+		for x := search.Min.X; x < search.Max.X; x++ {
+			var sum int64
+			for ty := 0; ty < h && sum < bestSum; ty++ {
+				row := frame.Pix[(y+ty)*frame.Stride+x:]
+				trow := tmpl.Pix[ty*tmpl.Stride:]
+				for tx := 0; tx < w; tx++ {
+					sum += int64(absDiff(row[tx], trow[tx]))
+				}
+			}
+			if sum < bestSum {
+				best, bestSum = image.Pt(x, y), sum
+			}
+		}
+	}
+	return best, bestSum
+}
+
+// grayImage returns the luminance of img moved to the origin
+func grayImage(img image.Image) *image.Gray {
+	gray := image.NewGray(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(gray, gray.Rect, img, img.Bounds().Min, draw.Src)
+	return gray
+}
+
+// downscaleGray returns img shrunk by an integer factor, averaging the blocks
+// of pixels
+func downscaleGray(img *image.Gray, factor int) *image.Gray {
+	small := image.NewGray(image.Rect(0, 0, img.Rect.Dx()/factor, img.Rect.Dy()/factor))
+	for y := 0; y < small.Rect.Dy(); y++ {
+		for x := 0; x < small.Rect.Dx(); x++ {
+			sum := 0
+			for by := 0; by < factor; by++ {
+				row := img.Pix[(y*factor+by)*img.Stride+x*factor:]
+				for bx := 0; bx < factor; bx++ {
+					sum += int(row[bx])
+				}
+			}
+			small.Pix[y*small.Stride+x] = uint8(sum / (factor * factor))
+		}
+	}
+	return small
+}
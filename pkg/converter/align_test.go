@@ -0,0 +1,88 @@
+package converter
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewTemplateAligner(t *testing.T) {
+	offsets := []image.Point{{0, 0}, {3, -2}, {-4, 1}, {6, 5}}
+	frames := shakyFrames(160, 120, offsets)
+	src, err := frameSource(frames)
+	if err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+	region, err := cropImage(frames[0].Image, image.Rect(50, 40, 110, 80), "frame 0")
+	if err != nil {
+		t.Fatalf("cropImage() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		template image.Image
+		wantErr  bool
+	}{
+		{name: "region of the first frame", template: region},
+		{name: "missing region", template: image.NewRGBA(image.Rect(0, 0, 40, 40)), wantErr: true},
+		{name: "larger than the frames", template: image.NewRGBA(image.Rect(0, 0, 200, 40)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newTemplateAligner(src, 0, len(offsets), tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newTemplateAligner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i, want := range offsets {
+				if s.offsets[i] != want {
+					t.Errorf("offset of frame %d = %v, want %v", i, s.offsets[i], want)
+				}
+			}
+			if want := image.Pt(160-10, 120-7); s.bounds().Size() != want {
+				t.Errorf("aligned size = %v, want %v", s.bounds().Size(), want)
+			}
+		})
+	}
+}
+
+func TestConvertAlignOn(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frames := shakyFrames(96, 64, []image.Point{{0, 0}, {2, 1}, {-1, 3}})
+	region, err := cropImage(frames[0].Image, image.Rect(30, 20, 70, 50), "frame 0")
+	if err != nil {
+		t.Fatalf("cropImage() error = %v", err)
+	}
+	templateFile := filepath.Join(tempDir, "region.png")
+	file, err := os.Create(templateFile)
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	if err := png.Encode(file, region); err != nil {
+		t.Fatalf("Failed to encode template: %v", err)
+	}
+	file.Close()
+
+	outputFile := filepath.Join(tempDir, "aligned.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{AlignOn: templateFile})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Width != 96-3 || report.Height != 64-3 {
+		t.Errorf("output size = %dx%d, want %dx%d", report.Width, report.Height, 96-3, 64-3)
+	}
+
+	if _, err := ConvertFrames(frames, outputFile, Options{AlignOn: templateFile, Stabilize: true}); err == nil {
+		t.Error("ConvertFrames() with both --align-on and --stabilize should fail")
+	}
+}
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+// errAnimatedWebP rejects an animated WebP among other input files, its
+// frames being only expanded when it is the single input
+var errAnimatedWebP = errors.New("animated WebPs are only converted on their own")
+
+// isAnimatedWebP reports whether path is an animated WebP, from its content
+func isAnimatedWebP(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	header := make([]byte, 21)
+	n, _ := io.ReadFull(file, header)
+	return decode.IsAnimatedWebP(header[:n])
+}
+
+// decodeAnimatedWebP decodes the frames of an animated WebP, composited on
+// its canvas, each shown for its own duration
+func decodeAnimatedWebP(path string, opts Options) ([]Frame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	// The frames of the animation are all the input pixels
+	limits := opts.InputLimits.decodeLimits()
+	if opts.InputLimits.MaxPixels > 0 {
+		limits.MaxPixels = opts.InputLimits.MaxPixels
+	}
+	anim, err := decode.AnimatedWebP(file, limits)
+	if err != nil {
+		var limit *decode.LimitError
+		if errors.As(err, &limit) {
+			limit.Input = path
+			return nil, limit
+		}
+		return nil, fmt.Errorf("error decoding animated WebP file %s: %v", path, err)
+	}
+	if err := opts.InputLimits.checkFrameCount(len(anim.Frames)); err != nil {
+		return nil, err
+	}
+
+	frames := make([]Frame, len(anim.Frames))
+	for i, img := range anim.Frames {
+		frames[i] = Frame{Image: img, Delay: anim.Delays[i]}
+	}
+	return frames, nil
+}
+
+// decodeStillWebP decodes a WebP frame file, failing on animated WebPs
+func decodeStillWebP(r io.Reader, limits decode.Limits) (image.Image, error) {
+	br := bufio.NewReader(r)
+	if header, _ := br.Peek(21); decode.IsAnimatedWebP(header) {
+		return nil, errAnimatedWebP
+	}
+	return decode.WebP(br, limits)
+}
@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"encoding/binary"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// animatedRedWebP encodes a 16x4 animated WebP of two frames: redWebP on
+// the left half for durations[0] milliseconds, then on the right half for
+// durations[1]
+func animatedRedWebP(durations [2]int) []byte {
+	chunk := func(id string, data []byte) []byte {
+		b := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		b = append(b, data...)
+		if len(data)%2 == 1 {
+			b = append(b, 0)
+		}
+		return b
+	}
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{0x12, 0, 0, 0, 15, 0, 0, 3, 0, 0})...)
+	body = append(body, chunk("ANIM", []byte{0, 0, 0, 0, 0, 0})...)
+	for i, duration := range durations {
+		anmf := []byte{byte(4 * i), 0, 0, 0, 0, 0, 7, 0, 0, 3, 0, 0, byte(duration), byte(duration >> 8), 0, 0}
+		anmf = append(anmf, redWebP[12:]...)
+		body = append(body, chunk("ANMF", anmf)...)
+	}
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestConvertAnimatedWebP(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	animated := filepath.Join(tempDir, "animated.webp")
+	still := filepath.Join(tempDir, "still.webp")
+	for file, data := range map[string][]byte{
+		animated: animatedRedWebP([2]int{200, 350}),
+		still:    redWebP,
+	} {
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		files      []string
+		opts       Options
+		wantDelays []int
+		wantErr    string
+	}{
+		{name: "frame durations", files: []string{animated}, wantDelays: []int{20, 35}},
+		{name: "too many frames", files: []string{animated}, opts: Options{InputLimits: InputLimits{MaxFrames: 1}}, wantErr: "2 frames, above the limit of 1"},
+		{name: "too many pixels", files: []string{animated}, opts: Options{InputLimits: InputLimits{MaxPixels: 100}}, wantErr: "128 pixels, above the limit of 100"},
+		{name: "among other files", files: []string{still, animated}, wantErr: "animated WebPs are only converted on their own"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(tempDir, "output.gif")
+			report, err := Convert(tt.files, output, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Convert() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if report.Frames != len(tt.wantDelays) || report.Width != 16 || report.Height != 4 {
+				t.Errorf("Convert() = %d frames of %dx%d, want %d of 16x4", report.Frames, report.Width, report.Height, len(tt.wantDelays))
+			}
+
+			file, err := os.Open(output)
+			if err != nil {
+				t.Fatalf("Failed to open output: %v", err)
+			}
+			defer file.Close()
+			g, err := gif.DecodeAll(file)
+			if err != nil {
+				t.Fatalf("Failed to decode output: %v", err)
+			}
+			if !slices.Equal(g.Delay, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", g.Delay, tt.wantDelays)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Content classes detected by Options.Auto
+const (
+	// ContentUI is a screen capture: large flat areas and sharp text
+	ContentUI = "ui"
+	// ContentPhoto is camera footage or rendered video: gradients and noise
+	ContentPhoto = "photo"
+	// ContentPixelArt is drawn with few exact colors
+	ContentPixelArt = "pixel-art"
+)
+
+const (
+	// autoSamples is the number of frames analyzed by Options.Auto
+	autoSamples = 5
+	// flatThreshold is the share of identical neighbor pixels above which content is considered flat
+	flatThreshold = 0.5
+	// maxPhotoWidth is the width photos are scaled down to when no width is set
+	maxPhotoWidth = 800
+)
+
+// AutoSettings reports the settings picked by Options.Auto
+type AutoSettings struct {
+	Content   string `json:"content"`
+	Quantizer string `json:"quantizer"`
+	Dither    string `json:"dither"`
+	Colors    int    `json:"colors"`
+	Scaler    string `json:"scaler"`
+	// Width is the output width picked, 0 when the size is kept
+	Width        int  `json:"width"`
+	PreserveText bool `json:"preserveText,omitempty"`
+}
+
+// String describes the choices in a single line
+func (a AutoSettings) String() string {
+	s := fmt.Sprintf("%s content: %s quantizer, %s dithering, %d colors, %s scaling",
+		a.Content, a.Quantizer, a.Dither, a.Colors, a.Scaler)
+	if a.Width > 0 {
+		s += fmt.Sprintf(", width %d", a.Width)
+	}
+	if a.PreserveText {
+		s += ", text preservation"
+	}
+	return s
+}
+
+// autoConfigure analyzes sample frames of src within [first, last) and applies
+// the settings matching their content
+func autoConfigure(src source, first, last int, opts Options) (Options, *AutoSettings, error) {
+	var samples []image.Image
+	for _, i := range sampleIndices(first, last, autoSamples) {
+		img, err := src.load(i)
+		if err != nil {
+			return opts, nil, err
+		}
+		samples = append(samples, img)
+	}
+
+	// Size decisions are made on logical pixels when RespectDPI is set
+	bounds := samples[0].Bounds()
+	if src.density != nil && opts.RespectDPI {
+		dpi, err := src.density(first)
+		if err != nil {
+			return opts, nil, err
+		}
+		bounds = outputBounds(bounds, dpi, Options{RespectDPI: true})
+	}
+
+	content, colors := classifyContent(samples)
+	opts, settings := applyAuto(opts, content, colors, bounds)
+	if opts.Debug {
+		fmt.Printf("Auto settings: %s\n", settings)
+	}
+	return opts, &settings, nil
+}
+
+// sampleIndices returns up to n evenly spaced indices within [first, last)
+func sampleIndices(first, last, n int) []int {
+	n = min(n, last-first)
+	indices := make([]int, n)
+	for s := range indices {
+		indices[s] = first + s*(last-first)/n
+	}
+	return indices
+}
+
+// classifyContent guesses the kind of content from sample frames, also returning
+// the number of distinct colors they use
+func classifyContent(samples []image.Image) (string, int) {
+	colors := make(map[color.RGBA]struct{})
+	equal, pairs := 0, 0
+	for _, img := range samples {
+		bounds := img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			var previous color.RGBA
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				// Stop counting once the answer can no longer change
+				if len(colors) <= MaxColors {
+					colors[c] = struct{}{}
+				}
+				if x > bounds.Min.X {
+					pairs++
+					if c == previous {
+						equal++
+					}
+				}
+				previous = c
+			}
+		}
+	}
+
+	flat := pairs > 0 && float64(equal)/float64(pairs) >= flatThreshold
+	switch {
+	case flat && len(colors) <= MaxColors:
+		return ContentPixelArt, len(colors)
+	case flat:
+		return ContentUI, len(colors)
+	default:
+		return ContentPhoto, len(colors)
+	}
+}
+
+// applyAuto fills in the settings left unset in opts for the detected content
+func applyAuto(opts Options, content string, colors int, bounds image.Rectangle) (Options, AutoSettings) {
+	quantizer, dither, scaler := QuantizerFrequency, DitherNone, ScalerSmooth
+	width, paletteColors := 0, MaxColors
+	preserveText := false
+
+	switch content {
+	case ContentPhoto:
+		quantizer, dither = QuantizerMedianCut, DitherFloydSteinberg
+		if bounds.Dx() > maxPhotoWidth {
+			width = maxPhotoWidth
+		}
+	case ContentPixelArt:
+		scaler = ScalerNearest
+		paletteColors = min(colors, MaxColors)
+	case ContentUI:
+		preserveText = true
+	}
+
+	if opts.Quantizer == "" {
+		opts.Quantizer = quantizer
+	}
+	if opts.Dither == "" {
+		opts.Dither = dither
+	}
+	if opts.Scaler == "" {
+		opts.Scaler = scaler
+	}
+	if opts.Colors == 0 {
+		opts.Colors = paletteColors
+	}
+	if opts.Width == 0 {
+		opts.Width = width
+	}
+	opts.PreserveText = opts.PreserveText || preserveText
+
+	settings := AutoSettings{
+		Content:      content,
+		Quantizer:    opts.Quantizer,
+		Dither:       opts.Dither,
+		Colors:       opts.Colors,
+		Scaler:       opts.Scaler,
+		Width:        opts.Width,
+		PreserveText: opts.PreserveText,
+	}
+	return opts, settings
+}
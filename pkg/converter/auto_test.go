@@ -0,0 +1,189 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// flatImage draws a few solid bands
+func flatImage(colors int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8(x * colors / 64 * (255 / max(1, colors-1)))
+			img.Set(x, y, color.RGBA{v, v, 255 - v, 255})
+		}
+	}
+	return img
+}
+
+// uiImage draws a flat background with a block of many colors, like a capture with an icon
+func uiImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.RGBA{240, 240, 240, 255}
+			if y < 24 {
+				c = color.RGBA{uint8(x * 4), uint8(y * 10), uint8(x + y), 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// noiseImage draws pseudo random pixels, like a photo
+func noiseImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	seed := uint32(1)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			seed = seed*1664525 + 1013904223
+			img.Set(x, y, color.RGBA{uint8(seed >> 24), uint8(seed >> 16), uint8(seed >> 8), 255})
+		}
+	}
+	return img
+}
+
+func TestClassifyContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		img        image.Image
+		want       string
+		wantColors int
+	}{
+		{"pixel art", flatImage(4), ContentPixelArt, 4},
+		{"ui", uiImage(), ContentUI, 0},
+		{"photo", noiseImage(), ContentPhoto, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, colors := classifyContent([]image.Image{tt.img})
+			if got != tt.want {
+				t.Errorf("classifyContent() = %s, want %s", got, tt.want)
+			}
+			if tt.wantColors > 0 && colors != tt.wantColors {
+				t.Errorf("classifyContent() colors = %d, want %d", colors, tt.wantColors)
+			}
+		})
+	}
+}
+
+func TestApplyAuto(t *testing.T) {
+	wide := image.Rect(0, 0, 1920, 1080)
+
+	opts, settings := applyAuto(Options{}, ContentPhoto, 100000, wide)
+	if opts.Quantizer != QuantizerMedianCut || opts.Dither != DitherFloydSteinberg || opts.Width != maxPhotoWidth {
+		t.Errorf("photo settings = %+v", settings)
+	}
+
+	opts, settings = applyAuto(Options{}, ContentPixelArt, 12, wide)
+	if opts.Scaler != ScalerNearest || opts.Colors != 12 || opts.Width != 0 {
+		t.Errorf("pixel art settings = %+v", settings)
+	}
+
+	opts, _ = applyAuto(Options{}, ContentUI, 5000, wide)
+	if !opts.PreserveText || opts.Dither != DitherNone {
+		t.Errorf("ui settings = %+v", opts)
+	}
+
+	// Explicit settings are kept
+	opts, settings = applyAuto(Options{Width: 1200, Dither: DitherNone, Colors: 64}, ContentPhoto, 100000, wide)
+	if opts.Width != 1200 || opts.Dither != DitherNone || opts.Colors != 64 {
+		t.Errorf("explicit settings were overridden: %+v", settings)
+	}
+}
+
+func TestSampleIndices(t *testing.T) {
+	tests := []struct {
+		first, last, n int
+		want           []int
+	}{
+		{0, 10, 5, []int{0, 2, 4, 6, 8}},
+		{2, 4, 5, []int{2, 3}},
+		{0, 1, 3, []int{0}},
+	}
+
+	for _, tt := range tests {
+		got := sampleIndices(tt.first, tt.last, tt.n)
+		if len(got) != len(tt.want) {
+			t.Errorf("sampleIndices(%d, %d, %d) = %v, want %v", tt.first, tt.last, tt.n, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("sampleIndices(%d, %d, %d) = %v, want %v", tt.first, tt.last, tt.n, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestConvertAuto(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 32, 32)
+	report, err := Convert(inputFiles, filepath.Join(tempDir, "output.gif"), Options{Delay: 100, Auto: true})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.Auto == nil || report.Auto.Content != ContentPhoto {
+		t.Fatalf("Convert() auto = %+v, want photo content", report.Auto)
+	}
+	if report.Auto.Quantizer != QuantizerMedianCut {
+		t.Errorf("Convert() auto quantizer = %s, want %s", report.Auto.Quantizer, QuantizerMedianCut)
+	}
+}
+
+func TestMedianCutPalette(t *testing.T) {
+	// A smooth gradient keeps colors from its whole range
+	img := image.NewRGBA(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		img.Set(x, 0, color.RGBA{uint8(x), uint8(x), uint8(x), 255})
+	}
+
+	palette := medianCutPalette([]image.Image{img}, 16)
+	if len(palette) != 16 {
+		t.Fatalf("medianCutPalette() got %d colors, want 16", len(palette))
+	}
+	var darkest, brightest uint8 = 255, 0
+	for _, c := range palette {
+		r := color.RGBAModel.Convert(c).(color.RGBA).R
+		darkest, brightest = min(darkest, r), max(brightest, r)
+	}
+	if darkest > 16 || brightest < 239 {
+		t.Errorf("palette covers %d-%d, want close to 0-255", darkest, brightest)
+	}
+
+	// Fewer colors than the limit are kept exactly
+	if got := medianCutPalette([]image.Image{flatImage(3)}, 16); len(got) != 3 {
+		t.Errorf("medianCutPalette() got %d colors, want 3", len(got))
+	}
+
+	// Colors sharing the value of the split channel are cut the same way every time
+	ties := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := range ties.Pix {
+		ties.Pix[i] = uint8(i * 37 % 251)
+		if i%4 == 0 {
+			ties.Pix[i] = uint8(i % 3 * 120)
+		}
+		if i%4 == 3 {
+			ties.Pix[i] = 255
+		}
+	}
+	want := medianCutPalette([]image.Image{ties}, 16)
+	for i := 0; i < 10; i++ {
+		if got := medianCutPalette([]image.Image{ties}, 16); !reflect.DeepEqual(got, want) {
+			t.Fatalf("medianCutPalette() = %v, then %v", want, got)
+		}
+	}
+}
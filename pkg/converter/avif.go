@@ -0,0 +1,32 @@
+package converter
+
+import "fmt"
+
+// avifDefaultQuality is the quality of AVIF outputs when Options.VideoCRF is 0
+const avifDefaultQuality = 60
+
+// validateAVIF checks that this build can write AVIF outputs, encoded by
+// libavif through cgo
+func validateAVIF() error {
+	if !avifAvailable {
+		return fmt.Errorf("AVIF output is not available in this build, it needs go-togif built with libavif 1.0 or newer: CGO_ENABLED=1 go build -tags avif")
+	}
+	return nil
+}
+
+// avifQuality returns the libavif quality of opts, from 0 to 100
+func avifQuality(opts Options) int {
+	if opts.VideoCRF > 0 {
+		return 100 - opts.VideoCRF*100/MaxVideoCRF
+	}
+	return avifDefaultQuality
+}
+
+// avifRepetitions returns how many times an AVIF repeats after playing once
+// to play loops times, -1 repeating forever
+func avifRepetitions(loops int) int {
+	if loops == 0 {
+		return -1
+	}
+	return loops - 1
+}
@@ -0,0 +1,87 @@
+//go:build avif && cgo
+
+package converter
+
+/*
+#cgo pkg-config: libavif
+#include <avif/avif.h>
+
+#if AVIF_VERSION < 1000000
+#error "AVIF output needs libavif 1.0 or newer"
+#endif
+
+// addFrame converts the straight alpha RGBA pixels of a frame to YUV and adds
+// it to the animation for duration timescale units
+static avifResult addFrame(avifEncoder *encoder, const uint8_t *pixels, uint32_t width, uint32_t height, uint64_t duration) {
+	avifImage *image = avifImageCreate(width, height, 8, AVIF_PIXEL_FORMAT_YUV420);
+	if (image == NULL) {
+		return AVIF_RESULT_OUT_OF_MEMORY;
+	}
+	avifRGBImage rgb;
+	avifRGBImageSetDefaults(&rgb, image);
+	rgb.format = AVIF_RGB_FORMAT_RGBA;
+	rgb.pixels = (uint8_t *)pixels;
+	rgb.rowBytes = width * 4;
+	avifResult result = avifImageRGBToYUV(image, &rgb);
+	if (result == AVIF_RESULT_OK) {
+		result = avifEncoderAddImage(encoder, image, duration, AVIF_ADD_IMAGE_FLAG_NONE);
+	}
+	avifImageDestroy(image);
+	return result;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"runtime"
+	"unsafe"
+)
+
+// avifAvailable reports whether this build encodes AVIF outputs
+const avifAvailable = true
+
+// avifVersion returns the version of the libavif library linked
+func avifVersion() string {
+	return C.GoString(C.avifVersion())
+}
+
+// encodeAVIF encodes the frames, shown for their delays in 100ths of a
+// second, to an animated AVIF at path
+func encodeAVIF(path string, frames []image.Image, delays []int, opts Options) error {
+	encoder := C.avifEncoderCreate()
+	if encoder == nil {
+		return fmt.Errorf("error creating AVIF encoder")
+	}
+	defer C.avifEncoderDestroy(encoder)
+	encoder.quality = C.int(avifQuality(opts))
+	encoder.qualityAlpha = C.int(avifQuality(opts))
+	encoder.maxThreads = C.int(runtime.NumCPU())
+	encoder.timescale = 100
+	encoder.repetitionCount = C.int(avifRepetitions(opts.Loops))
+
+	// libavif converts the pixels before returning, so a single buffer is reused
+	bounds := frames[0].Bounds()
+	nrgba := image.NewNRGBA(bounds)
+	for i, frame := range frames {
+		draw.Draw(nrgba, bounds, loaded(frame), bounds.Min, draw.Src)
+		result := C.addFrame(encoder, (*C.uint8_t)(unsafe.Pointer(&nrgba.Pix[0])),
+			C.uint32_t(bounds.Dx()), C.uint32_t(bounds.Dy()), C.uint64_t(max(1, delays[i])))
+		if result != C.AVIF_RESULT_OK {
+			return fmt.Errorf("error encoding AVIF frame %d: %s", i, C.GoString(C.avifResultToString(result)))
+		}
+	}
+
+	var output C.avifRWData
+	if result := C.avifEncoderFinish(encoder, &output); result != C.AVIF_RESULT_OK {
+		return fmt.Errorf("error encoding AVIF: %s", C.GoString(C.avifResultToString(result)))
+	}
+	defer C.avifRWDataFree(&output)
+	if err := os.WriteFile(path, C.GoBytes(unsafe.Pointer(output.data), C.int(output.size)), 0644); err != nil {
+		return fmt.Errorf("error writing AVIF: %v", err)
+	}
+	return nil
+}
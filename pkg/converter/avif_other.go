@@ -0,0 +1,18 @@
+//go:build !avif || !cgo
+
+package converter
+
+import "image"
+
+// avifAvailable reports whether this build encodes AVIF outputs
+const avifAvailable = false
+
+// avifVersion returns "", no libavif is linked
+func avifVersion() string {
+	return ""
+}
+
+// encodeAVIF fails, AVIF outputs need the libavif backend
+func encodeAVIF(path string, frames []image.Image, delays []int, opts Options) error {
+	return validateAVIF()
+}
@@ -0,0 +1,52 @@
+package converter
+
+import "testing"
+
+func TestAVIFQuality(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want int
+	}{
+		{name: "default", opts: Options{}, want: avifDefaultQuality},
+		{name: "best", opts: Options{VideoCRF: 1}, want: 99},
+		{name: "worst", opts: Options{VideoCRF: MaxVideoCRF}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := avifQuality(tt.opts); got != tt.want {
+				t.Errorf("avifQuality() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAVIFRepetitions(t *testing.T) {
+	tests := []struct {
+		loops int
+		want  int
+	}{
+		{loops: 0, want: -1},
+		{loops: 1, want: 0},
+		{loops: 3, want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := avifRepetitions(tt.loops); got != tt.want {
+			t.Errorf("avifRepetitions(%d) = %d, want %d", tt.loops, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAVIF(t *testing.T) {
+	for _, opts := range []Options{{Format: FormatAVIF}, {Formats: []string{FormatGIF, FormatAVIF}}} {
+		err := validateFormat(opts)
+		if avifAvailable && err != nil {
+			t.Errorf("validateFormat() error = %v in a build with libavif", err)
+		}
+		if !avifAvailable && err == nil {
+			t.Error("validateFormat() error = nil in a build without libavif")
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jparrill/go-togif/pkg/external"
+)
+
+// Capability is an optional feature of go-togif, which depends on how it was
+// built or on the programs installed
+type Capability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	// Detail tells what provides the capability, or what it needs
+	Detail string `json:"detail,omitempty"`
+}
+
+// Capabilities lists the output formats and optional features of this build
+// on this system, running ffmpeg to list its encoders
+func Capabilities() []Capability {
+	capabilities := []Capability{
+		{Name: FormatGIF, Available: true, Detail: "built in"},
+		{Name: FormatPDF, Available: true, Detail: "built in"},
+		{Name: FormatTIFF, Available: true, Detail: "built in"},
+	}
+
+	if avifAvailable {
+		capabilities = append(capabilities, Capability{Name: FormatAVIF, Available: true, Detail: "libavif " + avifVersion()})
+	} else {
+		capabilities = append(capabilities, Capability{Name: FormatAVIF, Detail: "needs a build with libavif: CGO_ENABLED=1 go build -tags avif"})
+	}
+
+	// Video formats need ffmpeg and its encoder for the format
+	path, version, err := ffmpeg.FindVersion()
+	if err != nil {
+		capabilities = append(capabilities, Capability{Name: "ffmpeg", Detail: err.Error()})
+	} else {
+		capabilities = append(capabilities, Capability{Name: "ffmpeg", Available: true, Detail: fmt.Sprintf("%s %s", path, version)})
+	}
+	var encoders []byte
+	if err == nil {
+		if encoders, err = (external.Runner{}).Output(ffmpeg, []string{"-hide_banner", "-encoders"}); err != nil {
+			err = fmt.Errorf("error listing the ffmpeg encoders: %v", err)
+		}
+	}
+	for _, format := range []string{FormatWebM, FormatWebP, FormatMP4} {
+		encoder := ffmpegEncoders[format]
+		switch {
+		case err != nil:
+			capabilities = append(capabilities, Capability{Name: format, Detail: "needs ffmpeg with " + encoder})
+		case !hasEncoder(encoders, encoder):
+			capabilities = append(capabilities, Capability{Name: format, Detail: "ffmpeg lacks the " + encoder + " encoder"})
+		default:
+			capabilities = append(capabilities, Capability{Name: format, Available: true, Detail: "ffmpeg " + encoder})
+		}
+	}
+
+	// Every image operation is plain Go, portable but without vector instructions
+	capabilities = append(capabilities, Capability{Name: "simd", Detail: "not used, pure Go processing"})
+	return capabilities
+}
+
+// hasEncoder reports whether the output of ffmpeg -encoders lists encoder
+func hasEncoder(encoders []byte, encoder string) bool {
+	// Encoders are listed as " V....D libx264  description"
+	return regexp.MustCompile(`(?m)^\s*\S{6}\s+` + regexp.QuoteMeta(encoder) + `\s`).Match(encoders)
+}
@@ -0,0 +1,74 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ffmpeg is a shell script")
+	}
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// An ffmpeg without the WebP encoder
+	script := filepath.Join(tempDir, "ffmpeg")
+	content := `#!/bin/sh
+[ "$1" = -version ] && echo "ffmpeg version 6.1.1" && exit
+echo "Encoders:"
+echo " V..... = Video"
+echo " ------"
+echo " V....D libx264              libx264 H.264 (codec h264)"
+echo " V....D libvpx-vp9           libvpx VP9 (codec vp9)"
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		ffmpeg string
+		want   map[string]bool
+		detail map[string]string
+	}{
+		{
+			name:   "ffmpeg found",
+			ffmpeg: script,
+			want:   map[string]bool{FormatGIF: true, "ffmpeg": true, FormatWebM: true, FormatMP4: true, FormatWebP: false, FormatAVIF: avifAvailable},
+			detail: map[string]string{"ffmpeg": script + " 6.1", FormatWebP: "lacks the libwebp_anim encoder"},
+		},
+		{
+			name:   "ffmpeg missing",
+			ffmpeg: filepath.Join(tempDir, "missing"),
+			want:   map[string]bool{FormatGIF: true, "ffmpeg": false, FormatWebM: false, FormatMP4: false},
+			detail: map[string]string{FormatMP4: "needs ffmpeg with libx264"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(FFmpegEnv, tt.ffmpeg)
+			got := make(map[string]Capability)
+			for _, capability := range Capabilities() {
+				got[capability.Name] = capability
+			}
+			for name, want := range tt.want {
+				if capability, ok := got[name]; !ok || capability.Available != want {
+					t.Errorf("capability %s = %+v, want available %v", name, capability, want)
+				}
+			}
+			for name, want := range tt.detail {
+				if !strings.Contains(got[name].Detail, want) {
+					t.Errorf("capability %s detail = %q, want %q", name, got[name].Detail, want)
+				}
+			}
+		})
+	}
+}
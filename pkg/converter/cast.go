@@ -0,0 +1,40 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/termrender"
+)
+
+// IsCastFile reports whether path is an asciinema recording
+func IsCastFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".cast")
+}
+
+// renderCast replays an asciinema recording into frames
+func renderCast(path string, opts Options) ([]Frame, error) {
+	cast, err := termrender.LoadCast(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := termrender.Render(cast, termrender.Options{
+		FontSize:  opts.TermFontSize,
+		FPS:       opts.TermFPS,
+		MaxFrames: opts.InputLimits.MaxFrames,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rendered) == 0 {
+		return nil, fmt.Errorf("recording %s has no output", path)
+	}
+
+	frames := make([]Frame, len(rendered))
+	for i, frame := range rendered {
+		frames[i] = Frame{Image: frame.Image, Delay: frame.Delay}
+	}
+	return frames, nil
+}
@@ -0,0 +1,130 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsCastFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"demo.cast", true},
+		{"dir/DEMO.CAST", true},
+		{"frame.png", false},
+		{"cast", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsCastFile(tt.path); got != tt.want {
+			t.Errorf("IsCastFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestConvertCast(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	castFile := filepath.Join(tempDir, "demo.cast")
+	content := `{"version": 2, "width": 20, "height": 4}
+[0.0, "o", "$ "]
+[0.5, "o", "echo hi\r\n"]
+[1.0, "o", "\u001b[32mhi\u001b[0m\r\n$ "]
+`
+	if err := os.WriteFile(castFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write recording: %v", err)
+	}
+
+	outputFile := filepath.Join(tempDir, "demo.gif")
+	report, err := Convert([]string{castFile}, outputFile, Options{Delay: 100, TermFontSize: 12})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.Frames != 3 {
+		t.Errorf("Convert() got %d frames, want 3", report.Frames)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output GIF: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output GIF: %v", err)
+	}
+	wantDelays := []int{50, 50, 100}
+	for i, want := range wantDelays {
+		if g.Delay[i] != want {
+			t.Errorf("frame %d delay = %d, want %d", i, g.Delay[i], want)
+		}
+	}
+
+	// The frame limit stops the rendering itself
+	_, err = Convert([]string{castFile}, outputFile, Options{Delay: 100, TermFontSize: 12, InputLimits: InputLimits{MaxFrames: 2}})
+	if err == nil || !strings.Contains(err.Error(), "more than 2 frames") {
+		t.Errorf("Convert() error = %v, want the frame limit", err)
+	}
+}
+
+func TestConvertFrames(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	newFrame := func(c color.Color) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				img.Set(x, y, c)
+			}
+		}
+		return img
+	}
+	frames := []Frame{
+		{Image: newFrame(color.RGBA{255, 0, 0, 255}), Delay: 250 * time.Millisecond},
+		{Image: newFrame(color.RGBA{0, 0, 255, 255})},
+	}
+
+	outputFile := filepath.Join(tempDir, "frames.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{Delay: 70})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Frames != 2 || report.Width != 8 || report.Height != 8 {
+		t.Errorf("ConvertFrames() report = %+v, want 2 frames of 8x8", report)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output GIF: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output GIF: %v", err)
+	}
+	if g.Delay[0] != 25 || g.Delay[1] != 7 {
+		t.Errorf("delays = %v, want [25 7]", g.Delay)
+	}
+
+	if _, err := ConvertFrames(nil, outputFile, Options{}); err == nil {
+		t.Error("ConvertFrames() expected error for no frames")
+	}
+	if _, err := ConvertFrames([]Frame{{}}, outputFile, Options{}); err == nil {
+		t.Error("ConvertFrames() expected error for a frame without image")
+	}
+}
@@ -0,0 +1,140 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultChapterHold is how long the first frame of a chapter is shown at
+// least, when Options.ChapterHold is zero
+const DefaultChapterHold = 2 * time.Second
+
+// Chapter labels the frame a step of a sequence starts at
+type Chapter struct {
+	Frame int    `json:"frame"`
+	Label string `json:"label"`
+}
+
+// LoadChapters reads a chapters file, a YAML mapping of labels to 0-based
+// frame indices ("Install: 0" lines, or "{Install: 0, Run: 12}"). The
+// chapters are returned in frame order.
+func LoadChapters(path string) ([]Chapter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening chapters file: %v", err)
+	}
+	// Decoded as nodes to keep the labels in their order and find duplicates
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid chapters file %s: %v", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("no chapters listed in %s", path)
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid chapters line %d: expected <label>: <frame>", mapping.Line)
+	}
+
+	var chapters []Chapter
+	labels := make(map[string]bool)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		chapter, err := parseChapter(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapters line %d: %v", key.Line, err)
+		}
+		if labels[chapter.Label] {
+			return nil, fmt.Errorf("invalid chapters line %d: duplicate label %q", key.Line, chapter.Label)
+		}
+		labels[chapter.Label] = true
+		chapters = append(chapters, chapter)
+	}
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("no chapters listed in %s", path)
+	}
+
+	slices.SortStableFunc(chapters, func(a, b Chapter) int { return a.Frame - b.Frame })
+	for i := 1; i < len(chapters); i++ {
+		if chapters[i].Frame == chapters[i-1].Frame {
+			return nil, fmt.Errorf("chapters %q and %q both start at frame %d", chapters[i-1].Label, chapters[i].Label, chapters[i].Frame)
+		}
+	}
+	return chapters, nil
+}
+
+// parseChapter parses the label and frame of a chapter
+func parseChapter(key, value *yaml.Node) (Chapter, error) {
+	if key.Kind != yaml.ScalarNode {
+		return Chapter{}, fmt.Errorf("expected <label>: <frame>")
+	}
+	label := key.Value
+	if label == "" {
+		return Chapter{}, fmt.Errorf("empty label")
+	}
+
+	var frame int
+	if value.Kind != yaml.ScalarNode || value.ShortTag() != "!!int" || value.Decode(&frame) != nil {
+		return Chapter{}, fmt.Errorf("invalid frame %q for %q: expected a frame index", value.Value, label)
+	}
+	if frame < 0 {
+		return Chapter{}, fmt.Errorf("frame of %q must be non-negative", label)
+	}
+	return Chapter{Frame: frame, Label: label}, nil
+}
+
+// applyChapters extends the delays (in 100ths of a second) of the first
+// frame of every chapter to at least hold
+func applyChapters(delays []int, chapters []Chapter, hold time.Duration) error {
+	if hold == 0 {
+		hold = DefaultChapterHold
+	}
+	for _, chapter := range chapters {
+		if chapter.Frame >= len(delays) {
+			return fmt.Errorf("chapter %q starts at frame %d, beyond the %d available frames", chapter.Label, chapter.Frame, len(delays))
+		}
+		delays[chapter.Frame] = max(delays[chapter.Frame], int(hold/(10*time.Millisecond)))
+	}
+	return nil
+}
+
+// outputChapters maps chapters to the frames of the output, the shown frames
+// being the inputs listed in shown with the poster frame moved to the front.
+// A chapter whose first frame was dropped starts at the next frame shown, the
+// later chapter winning when several start at the same frame, and chapters
+// after the last one are left out.
+func outputChapters(chapters []Chapter, shown []int, poster int, mode string) ([]Chapter, error) {
+	order := make([]int, len(shown))
+	for i := range order {
+		order[i] = i
+	}
+	order, _, err := applyPosterFrame(order, make([]int, len(order)), poster, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapped []Chapter
+	for _, chapter := range chapters {
+		k, found := slices.BinarySearch(shown, chapter.Frame)
+		if !found && k == len(shown) {
+			continue
+		}
+		// A duplicated poster frame is shown before the sequence, whose own
+		// copy of the frame comes last
+		var frame int
+		for j, i := range order {
+			if i == k {
+				frame = j
+			}
+		}
+		if len(mapped) > 0 && mapped[len(mapped)-1].Frame == frame {
+			mapped = mapped[:len(mapped)-1]
+		}
+		mapped = append(mapped, Chapter{Frame: frame, Label: chapter.Label})
+	}
+	return mapped, nil
+}
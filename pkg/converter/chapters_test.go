@@ -0,0 +1,202 @@
+package converter
+
+import (
+	"encoding/json"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLoadChapters(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []Chapter
+		wantErr bool
+	}{
+		{
+			name:    "Mapping in frame order",
+			content: "# Steps\n---\nConfigure: 31\nInstall: 0 # first\n\nRun: 60\n",
+			want:    []Chapter{{Frame: 0, Label: "Install"}, {Frame: 31, Label: "Configure"}, {Frame: 60, Label: "Run"}},
+		},
+		{
+			name:    "Quoted labels",
+			content: "\"Step 1: install # deps\": 0\n'Step 2': 12\n",
+			want:    []Chapter{{Frame: 0, Label: "Step 1: install # deps"}, {Frame: 12, Label: "Step 2"}},
+		},
+		{
+			name:    "Escaped quotes",
+			content: "\"Step \\\"1\\\"\": 3\n'It''s done': 9\n",
+			want:    []Chapter{{Frame: 3, Label: `Step "1"`}, {Frame: 9, Label: "It's done"}},
+		},
+		{
+			name:    "Flow mapping",
+			content: "{Install: 0, Run: 12}\n",
+			want:    []Chapter{{Frame: 0, Label: "Install"}, {Frame: 12, Label: "Run"}},
+		},
+		{name: "Empty file", content: "# nothing\n", wantErr: true},
+		{name: "Not a mapping", content: "- Install\n- Run\n", wantErr: true},
+		{name: "No frame", content: "Install:\n", wantErr: true},
+		{name: "Missing frame", content: "Install\n", wantErr: true},
+		{name: "Invalid frame", content: "Install: first\n", wantErr: true},
+		{name: "Negative frame", content: "Install: -1\n", wantErr: true},
+		{name: "Duplicate label", content: "Install: 0\nInstall: 5\n", wantErr: true},
+		{name: "Same frame", content: "Install: 3\nRun: 3\n", wantErr: true},
+		{name: "Unterminated quote", content: "\"Install: 0\n", wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "chapters"+strconv.Itoa(i)+".yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write chapters: %v", err)
+			}
+			got, err := LoadChapters(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadChapters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("LoadChapters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadChapters(filepath.Join(tempDir, "missing.yaml")); err == nil {
+		t.Error("LoadChapters() expected error for a missing file")
+	}
+}
+
+func TestApplyChapters(t *testing.T) {
+	chapters := []Chapter{{Frame: 0, Label: "Install"}, {Frame: 2, Label: "Run"}}
+
+	delays := []int{10, 10, 300, 10}
+	if err := applyChapters(delays, chapters, 0); err != nil {
+		t.Fatalf("applyChapters() error = %v", err)
+	}
+	// Longer delays are kept
+	if want := []int{200, 10, 300, 10}; !slices.Equal(delays, want) {
+		t.Errorf("applyChapters() delays = %v, want %v", delays, want)
+	}
+
+	delays = []int{10, 10, 10}
+	if err := applyChapters(delays, chapters, 500*time.Millisecond); err != nil {
+		t.Fatalf("applyChapters() error = %v", err)
+	}
+	if want := []int{50, 10, 50}; !slices.Equal(delays, want) {
+		t.Errorf("applyChapters() delays = %v, want %v", delays, want)
+	}
+
+	if err := applyChapters([]int{10, 10}, chapters, 0); err == nil {
+		t.Error("applyChapters() expected error for a chapter beyond the last frame")
+	}
+}
+
+func TestOutputChapters(t *testing.T) {
+	chapters := []Chapter{{Frame: 0, Label: "Intro"}, {Frame: 2, Label: "Install"}, {Frame: 3, Label: "Configure"}, {Frame: 6, Label: "Run"}, {Frame: 9, Label: "Outro"}}
+
+	tests := []struct {
+		name   string
+		shown  []int
+		poster int
+		mode   string
+		want   []Chapter
+	}{
+		{
+			name:  "Every frame shown",
+			shown: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			want:  chapters,
+		},
+		{
+			name:  "Dropped and trimmed frames",
+			shown: []int{1, 4, 6, 8},
+			// Intro started before frame 1, Install and Configure after it
+			want: []Chapter{{Frame: 0, Label: "Intro"}, {Frame: 1, Label: "Configure"}, {Frame: 2, Label: "Run"}},
+		},
+		{
+			name:   "Duplicated poster frame",
+			shown:  []int{0, 1, 2, 3},
+			poster: 2,
+			want:   []Chapter{{Frame: 1, Label: "Intro"}, {Frame: 3, Label: "Install"}, {Frame: 4, Label: "Configure"}},
+		},
+		{
+			name:   "Rotated poster frame",
+			shown:  []int{0, 1, 2, 3},
+			poster: 2,
+			mode:   PosterRotate,
+			want:   []Chapter{{Frame: 2, Label: "Intro"}, {Frame: 0, Label: "Install"}, {Frame: 1, Label: "Configure"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outputChapters(chapters, tt.shown, tt.poster, tt.mode)
+			if err != nil {
+				t.Fatalf("outputChapters() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("outputChapters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithChapters(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 4, 16, 8)
+	outputFile := filepath.Join(tempDir, "out.gif")
+	manifestFile := filepath.Join(tempDir, "out.json")
+	chapters := []Chapter{{Frame: 0, Label: "Install"}, {Frame: 2, Label: "Run"}}
+	report, err := Convert(inputFiles, outputFile, Options{
+		Delay: 100, Chapters: chapters, ChapterHold: time.Second, Timeline: "0@300ms", Manifest: manifestFile,
+	})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if !slices.Equal(report.Chapters, chapters) {
+		t.Errorf("report chapters = %v, want %v", report.Chapters, chapters)
+	}
+
+	// The timeline sets the delay of the first chapter
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if want := []int{30, 10, 100, 10}; !slices.Equal(g.Delay, want) {
+		t.Errorf("delays = %v, want %v", g.Delay, want)
+	}
+
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if len(manifest.Frames) != 4 || manifest.Frames[2].DelayMs != 1000 || manifest.Frames[2].File != "" {
+		t.Errorf("manifest frames = %+v, want 4 frames, the third shown for 1000ms", manifest.Frames)
+	}
+	if !slices.Equal(manifest.Chapters, chapters) {
+		t.Errorf("manifest chapters = %v, want %v", manifest.Chapters, chapters)
+	}
+}
@@ -0,0 +1,230 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Files of a checkpoint in the work directory
+const (
+	// checkpointFile records which conversion the work directory belongs to
+	checkpointFile = "checkpoint.json"
+	// checkpointPalette is the palette of the conversion, as a hex list
+	checkpointPalette = "palette.txt"
+	// checkpointFrames is the directory of the quantized frames of an output
+	checkpointFrames = "output-%d"
+	// checkpointFramesGlob matches the frame directories of every output
+	checkpointFramesGlob = "output-*"
+)
+
+// checkpointState is the content of checkpointFile
+type checkpointState struct {
+	Fingerprint string `json:"fingerprint"`
+}
+
+// checkpoint persists the palette and quantized frames of a conversion so an
+// interrupted run can resume where it stopped
+type checkpoint struct {
+	dir         string
+	fingerprint string
+}
+
+// openCheckpoint prepares the work directory of opts. When resuming a checkpoint
+// of the same conversion, its palette is returned. Any other checkpoint is discarded
+// unless opts.Resume is set, in which case it is an error.
+func openCheckpoint(src source, first, last int, opts Options) (*checkpoint, color.Palette, error) {
+	if opts.WorkDir == "" {
+		return nil, nil, nil
+	}
+	if err := os.MkdirAll(opts.WorkDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("error creating work directory: %v", err)
+	}
+
+	fingerprint, err := conversionFingerprint(src, first, last, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := &checkpoint{dir: opts.WorkDir, fingerprint: fingerprint}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, checkpointFile))
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !opts.Resume) {
+		// Nothing to resume, start from a clean directory
+		return c, nil, c.clear()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading checkpoint: %v", err)
+	}
+
+	var state checkpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, nil, fmt.Errorf("invalid checkpoint %s: %v", filepath.Join(c.dir, checkpointFile), err)
+	}
+	if state.Fingerprint != fingerprint {
+		return nil, nil, fmt.Errorf("work directory %s holds a checkpoint of a different conversion, remove it or run without --resume", c.dir)
+	}
+
+	palette, err := ReadPalette(filepath.Join(c.dir, checkpointPalette))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading checkpoint palette: %v", err)
+	}
+	return c, palette, nil
+}
+
+// conversionFingerprint identifies a conversion by its settings and inputs. Input,
+// overlay, LUT and alignment template files are identified by path, size and
+// modification time, in-memory frames by their pixels, and the stamp by its
+// expansion.
+func conversionFingerprint(src source, first, last int, opts Options) (string, error) {
+	// Settings that do not change the output
+	opts.WorkDir, opts.Resume, opts.Debug, opts.TmpDir, opts.KeepTemp = "", false, false, "", false
+	opts.Workers, opts.IOWorkers, opts.QueueDepth, opts.MemoryLimit = 0, 0, 0, 0
+	opts.ProgressLog = ""
+	// The frames carry the stamp as expanded with the environment of the run
+	if opts.Stamp != "" {
+		stamp, err := ExpandStamp(opts.Stamp, os.Environ())
+		if err != nil {
+			return "", err
+		}
+		opts.Stamp = stamp
+	}
+
+	h := sha256.New()
+	settings, err := json.Marshal(opts)
+	if err != nil {
+		return "", fmt.Errorf("error fingerprinting options: %v", err)
+	}
+	h.Write(settings)
+
+	for _, file := range []string{opts.Subtitles, opts.Annotations, opts.Events, opts.Waveform, opts.LUT, opts.AlignOn} {
+		if file == "" {
+			continue
+		}
+		if err := fingerprintFile(h, file); err != nil {
+			return "", err
+		}
+	}
+	if len(src.files) > 0 {
+		for _, file := range src.files {
+			if err := fingerprintFile(h, file); err != nil {
+				return "", err
+			}
+			// Sidecars change the frames too
+			if _, err := os.Stat(SidecarPath(file)); err == nil {
+				if err := fingerprintFile(h, SidecarPath(file)); err != nil {
+					return "", err
+				}
+			}
+		}
+	} else {
+		for i := first; i < last; i++ {
+			img, err := src.load(i)
+			if err != nil {
+				return "", err
+			}
+			rgba := image.NewRGBA(img.Bounds())
+			draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+			fmt.Fprintf(h, "%v\n", rgba.Bounds())
+			h.Write(rgba.Pix)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fingerprintFile adds the path, size and modification time of file to h
+func fingerprintFile(h io.Writer, file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("error reading input file: %v", err)
+	}
+	fmt.Fprintf(h, "%s\x00%d\x00%d\n", file, info.Size(), info.ModTime().UnixNano())
+	return nil
+}
+
+// savePalette records the palette along with the fingerprint of the conversion,
+// after which frames can be saved
+func (c *checkpoint) savePalette(palette color.Palette) error {
+	file, err := os.Create(filepath.Join(c.dir, checkpointPalette))
+	if err != nil {
+		return fmt.Errorf("error saving checkpoint palette: %v", err)
+	}
+	defer file.Close()
+	if err := WritePalette(file, palette, PaletteHex); err != nil {
+		return fmt.Errorf("error saving checkpoint palette: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error saving checkpoint palette: %v", err)
+	}
+
+	// The state file is written last, a checkpoint without it is never resumed
+	data, err := json.Marshal(checkpointState{Fingerprint: c.fingerprint})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, checkpointFile), data, 0644); err != nil {
+		return fmt.Errorf("error saving checkpoint: %v", err)
+	}
+	return nil
+}
+
+// clear removes the files of a previous checkpoint
+func (c *checkpoint) clear() error {
+	outputs, err := filepath.Glob(filepath.Join(c.dir, checkpointFramesGlob))
+	if err != nil {
+		return err
+	}
+	for _, path := range append(outputs, filepath.Join(c.dir, checkpointFile), filepath.Join(c.dir, checkpointPalette)) {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("error clearing work directory: %v", err)
+		}
+	}
+	return nil
+}
+
+// framePath returns the path of quantized frame i of an output
+func (c *checkpoint) framePath(output, i int) string {
+	return filepath.Join(c.dir, fmt.Sprintf(checkpointFrames, output), fmt.Sprintf("%06d.png", i))
+}
+
+// frame returns quantized frame i of an output if it was saved
+func (c *checkpoint) frame(output, i int) (*image.Paletted, bool) {
+	file, err := os.Open(c.framePath(output, i))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, false
+	}
+	paletted, ok := img.(*image.Paletted)
+	return paletted, ok
+}
+
+// saveFrame stores quantized frame i of an output. The file is renamed into
+// place once complete so an interruption never leaves a truncated frame.
+func (c *checkpoint) saveFrame(output, i int, img *image.Paletted) error {
+	path := c.framePath(output, i)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating checkpoint directory: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := writePNG(tmp, img); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error saving checkpoint frame: %v", err)
+	}
+	return nil
+}
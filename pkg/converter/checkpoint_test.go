@@ -0,0 +1,178 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConvertResume(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 16, 8)
+	workDir := filepath.Join(tempDir, "work")
+	opts := Options{Delay: 100, WorkDir: workDir}
+
+	// Writing into a missing directory fails after every frame was quantized
+	if _, err := Convert(inputFiles, filepath.Join(tempDir, "missing", "out.gif"), opts); err == nil {
+		t.Fatal("Convert() expected error for a missing output directory")
+	}
+	if _, err := os.Stat(filepath.Join(workDir, checkpointFile)); err != nil {
+		t.Fatalf("checkpoint not kept after a failed conversion: %v", err)
+	}
+
+	// A different conversion cannot resume the checkpoint
+	outputFile := filepath.Join(tempDir, "out.gif")
+	changed := opts
+	changed.Resume, changed.Colors = true, 8
+	if _, err := Convert(inputFiles, outputFile, changed); err == nil {
+		t.Error("Convert() expected error when resuming a different conversion")
+	}
+
+	opts.Resume = true
+	report, err := Convert(inputFiles, outputFile, opts)
+	if err != nil {
+		t.Fatalf("Convert() resuming error = %v", err)
+	}
+	if report.ResumedFrames != 3 {
+		t.Errorf("resumed %d frames, want 3", report.ResumedFrames)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, checkpointFile)); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after a successful conversion: %v", err)
+	}
+
+	if report.Frames != 3 {
+		t.Errorf("resumed output has %d frames, want 3", report.Frames)
+	}
+}
+
+func TestCheckpointDiscarded(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 2, 8, 8)
+	workDir := filepath.Join(tempDir, "work")
+	if _, err := Convert(inputFiles, filepath.Join(tempDir, "missing", "out.gif"), Options{WorkDir: workDir}); err == nil {
+		t.Fatal("Convert() expected error for a missing output directory")
+	}
+
+	// Without Resume the previous checkpoint is ignored
+	report, err := Convert(inputFiles, filepath.Join(tempDir, "out.gif"), Options{WorkDir: workDir})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.ResumedFrames != 0 {
+		t.Errorf("resumed %d frames without Resume, want 0", report.ResumedFrames)
+	}
+
+	if _, err := Convert(inputFiles, filepath.Join(tempDir, "out.gif"), Options{Resume: true}); err == nil {
+		t.Error("Convert() expected error when resuming without a work directory")
+	}
+}
+
+func TestConversionFingerprint(t *testing.T) {
+	solid := func(c color.RGBA) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+		return img
+	}
+	frames := []Frame{{Image: solid(color.RGBA{255, 0, 0, 255})}, {Image: solid(color.RGBA{0, 255, 0, 255})}}
+	src, err := frameSource(frames)
+	if err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+
+	base, err := conversionFingerprint(src, 0, 2, Options{Delay: 100})
+	if err != nil {
+		t.Fatalf("conversionFingerprint() error = %v", err)
+	}
+	same, _ := conversionFingerprint(src, 0, 2, Options{Delay: 100, Workers: 8, Debug: true, WorkDir: "elsewhere"})
+	if same != base {
+		t.Error("settings that do not change the output changed the fingerprint")
+	}
+
+	otherOpts, _ := conversionFingerprint(src, 0, 2, Options{Delay: 50})
+	frames[1].Image = solid(color.RGBA{0, 0, 255, 255})
+	otherPixels, _ := conversionFingerprint(src, 0, 2, Options{Delay: 100})
+	if otherOpts == base || otherPixels == base {
+		t.Error("different settings or frames gave the same fingerprint")
+	}
+
+	// A stamp is fingerprinted as expanded, with the variables of the run
+	stamped := Options{Delay: 100, Stamp: "run {{.CI_RUN}}"}
+	t.Setenv("CI_RUN", "1")
+	firstRun, err := conversionFingerprint(src, 0, 2, stamped)
+	if err != nil {
+		t.Fatalf("conversionFingerprint() error = %v", err)
+	}
+	t.Setenv("CI_RUN", "2")
+	secondRun, _ := conversionFingerprint(src, 0, 2, stamped)
+	if secondRun == firstRun {
+		t.Error("changing a variable of the stamp kept the fingerprint")
+	}
+	t.Setenv("OTHER_VARIABLE", "changed")
+	if again, _ := conversionFingerprint(src, 0, 2, stamped); again != secondRun {
+		t.Error("changing a variable outside the stamp changed the fingerprint")
+	}
+}
+
+func TestConversionFingerprintFiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	src, err := frameSource([]Frame{{Image: image.NewRGBA(image.Rect(0, 0, 8, 8))}})
+	if err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+	file := filepath.Join(tempDir, "setting")
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "LUT", opts: Options{LUT: file}},
+		{name: "alignment template", opts: Options{AlignOn: file}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(file, []byte("before"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			before, err := conversionFingerprint(src, 0, 1, tt.opts)
+			if err != nil {
+				t.Fatalf("conversionFingerprint() error = %v", err)
+			}
+
+			// Editing the file changes the output, so the checkpoint is stale
+			if err := os.WriteFile(file, []byte("after edit"), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			later := time.Now().Add(time.Minute)
+			if err := os.Chtimes(file, later, later); err != nil {
+				t.Fatalf("Failed to touch file: %v", err)
+			}
+			after, err := conversionFingerprint(src, 0, 1, tt.opts)
+			if err != nil {
+				t.Fatalf("conversionFingerprint() error = %v", err)
+			}
+			if after == before {
+				t.Errorf("editing the %s kept the fingerprint", tt.name)
+			}
+		})
+	}
+}
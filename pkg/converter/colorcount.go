@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+const (
+	// ManyColors is the number of distinct source colors above which a 256
+	// color GIF visibly bands
+	ManyColors = 50000
+	// colorSamples is the number of frames whose colors are counted
+	colorSamples = 5
+)
+
+// countSourceColors returns the number of distinct colors of a few evenly
+// spaced frames, counting stopping past ManyColors
+func countSourceColors(frames []image.Image) int {
+	counts := make(map[color.RGBA]int)
+	for _, i := range sampleIndices(0, len(frames), colorSamples) {
+		if !countColors(counts, loaded(frames[i]), ManyColors) {
+			break
+		}
+	}
+	return len(counts)
+}
+
+// colorAdvice returns a warning about source material with too many colors
+// for a GIF, suggesting the settings of opts that reduce banding, or "" when
+// there are few enough colors
+func colorAdvice(colors int, opts Options) string {
+	if colors <= ManyColors {
+		return ""
+	}
+
+	var advice []string
+	if opts.Dither == "" || opts.Dither == DitherNone {
+		advice = append(advice, "--dither floyd-steinberg to hide banding")
+	}
+	if opts.Quantizer == "" || opts.Quantizer == QuantizerFrequency {
+		advice = append(advice, "--quantizer median-cut to spread the palette over the whole image")
+	}
+	// Presets and adjusted checks reduce the colors to fit their file size
+	if opts.Colors > 0 && opts.Colors < MaxColors && opts.Preset == "" && !opts.AutoAdjust {
+		advice = append(advice, fmt.Sprintf("--colors %d to use the full palette", MaxColors))
+	}
+	message := fmt.Sprintf("the input has more than %d colors, the %d color GIF palette will show banding", ManyColors, paletteSize(opts))
+	if len(advice) == 0 {
+		return message + "; consider a video format for this content"
+	}
+	return message + "; try " + strings.Join(advice, ", ")
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColorAdvice(t *testing.T) {
+	tests := []struct {
+		name   string
+		colors int
+		opts   Options
+		want   []string
+	}{
+		{name: "few colors", colors: 1000},
+		{name: "defaults", colors: ManyColors + 1, want: []string{"--dither floyd-steinberg", "--quantizer median-cut"}},
+		{name: "reduced palette", colors: ManyColors + 1, opts: Options{Dither: DitherFloydSteinberg, Quantizer: QuantizerMedianCut, Colors: 64}, want: []string{"64 color", "--colors 256"}},
+		{name: "nothing left to try", colors: ManyColors + 1, opts: Options{Dither: DitherFloydSteinberg, Quantizer: QuantizerMedianCut}, want: []string{"video format"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := colorAdvice(tt.colors, tt.opts)
+			if (got == "") != (len(tt.want) == 0) {
+				t.Fatalf("colorAdvice() = %q, want advice %v", got, tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("colorAdvice() = %q, does not contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertColorWarning(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A smooth gradient with a distinct color per pixel
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), uint8(x ^ y), 255})
+		}
+	}
+
+	report, err := ConvertFrames([]Frame{{Image: img}}, filepath.Join(tempDir, "output.gif"), Options{Delay: 100})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.SourceColors <= ManyColors || len(report.Warnings) == 0 || report.Warnings[0].Kind != WarningPaletteTruncated || !strings.Contains(report.Warnings[0].Message, "banding") {
+		t.Errorf("SourceColors = %d, Warnings = %v, want a banding warning", report.SourceColors, report.Warnings)
+	}
+}
@@ -0,0 +1,23 @@
+package converter
+
+import (
+	"github.com/jparrill/go-togif/pkg/compat"
+)
+
+// compatReport warns about the features of the GIF outputs of report known
+// to break in some renderers (see compat.Renderers)
+func compatReport(report *Report) error {
+	for _, output := range report.Outputs {
+		if !isGIF(output.Format) {
+			continue
+		}
+		usage, err := compat.ScanFile(output.File)
+		if err != nil {
+			return err
+		}
+		for _, issue := range usage.Issues() {
+			report.warn(WarningCompat, nil, "%s: %s", output.File, issue)
+		}
+	}
+	return nil
+}
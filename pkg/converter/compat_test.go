@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvertCompatReport(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for _, c := range []color.RGBA{{255, 0, 0, 255}, {0, 0, 255, 255}} {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for p := 0; p < len(img.Pix); p += 4 {
+			img.Pix[p], img.Pix[p+1], img.Pix[p+2], img.Pix[p+3] = c.R, c.G, c.B, c.A
+		}
+		frames = append(frames, Frame{Image: img, Delay: 10 * time.Millisecond})
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "off", opts: Options{}, want: nil},
+		{
+			name: "animation with short delays",
+			opts: Options{CompatReport: true},
+			want: []string{"browsers: frames shown for less than 20ms", "outlook: only the first frame is shown", "terminals: most viewers show the first frame only"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(tempDir, strings.ReplaceAll(tt.name, " ", "-")+".gif")
+			report, err := ConvertFrames(frames, output, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertFrames() error = %v", err)
+			}
+			var got []string
+			for _, warning := range report.Warnings {
+				if warning.Kind == WarningCompat {
+					got = append(got, warning.Message)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ConvertFrames() compat warnings = %v, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(got[i], output+": "+want) {
+					t.Errorf("warning %d = %s, want it to start with %s", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+// CheckIAB validates the output against the IAB display ad guidelines
+const CheckIAB = "iab"
+
+// adSpec holds the constraints of an ad network on animated GIFs
+type adSpec struct {
+	name string
+	// sizes are the accepted dimensions
+	sizes []image.Point
+	// maxBytes is the largest file accepted
+	maxBytes int64
+	// maxDuration is the longest playback, every loop included
+	maxDuration time.Duration
+	// maxLoops is the number of plays allowed, infinite looping being rejected
+	maxLoops int
+}
+
+// iabSpec follows the IAB display guidelines for standard ad units
+var iabSpec = adSpec{
+	name: "IAB",
+	sizes: []image.Point{
+		{300, 250}, {336, 280}, {728, 90}, {970, 90}, {970, 250}, {468, 60}, {234, 60},
+		{160, 600}, {120, 600}, {300, 600}, {300, 1050}, {320, 50}, {300, 50}, {320, 100},
+		{320, 480}, {250, 250}, {200, 200}, {180, 150}, {125, 125}, {88, 31},
+	},
+	maxBytes:    150 << 10,
+	maxDuration: 30 * time.Second,
+	maxLoops:    3,
+}
+
+// lookupCheck returns the constraints checked by Options.Check
+func lookupCheck(name string) (adSpec, error) {
+	if name == CheckIAB {
+		return iabSpec, nil
+	}
+	return adSpec{}, fmt.Errorf("invalid check %q: expected %s", name, CheckIAB)
+}
+
+// checkCompliance converts src and validates the output against the ad
+// constraints of opts.Check, failing on violations. With opts.AutoAdjust, the
+// output is first converted again at the closest accepted size, with fewer
+// loops, cut to the longest duration and reduced to fit the file size.
+func checkCompliance(src source, outputFile string, opts Options) (*Report, error) {
+	spec, err := lookupCheck(opts.Check)
+	if err != nil {
+		return nil, err
+	}
+	report, err := convertSource(src, outputFile, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.AutoAdjust {
+		playback, err := readPlayback(outputFile)
+		if err != nil {
+			return nil, err
+		}
+		adjusted := spec.adjust(opts, playback)
+		if report, err = fitFileSize(src, outputFile, adjusted, spec.maxBytes, spec.name); err != nil {
+			return nil, err
+		}
+	}
+
+	playback, err := readPlayback(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	if violations := spec.violations(playback); len(violations) > 0 {
+		return nil, fmt.Errorf("%s fails the %s checks: %s", outputFile, spec.name, strings.Join(violations, "; "))
+	}
+	return report, nil
+}
+
+// gifPlayback describes how a written GIF plays
+type gifPlayback struct {
+	size  image.Point
+	bytes int64
+	// duration is the length of one loop
+	duration time.Duration
+	// loops is the number of plays, 0 when looping forever
+	loops int
+}
+
+// readPlayback reads the size, duration and looping of the GIF at path
+func readPlayback(path string) (gifPlayback, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return gifPlayback{}, fmt.Errorf("error checking output: %v", err)
+	}
+	reader, err := gifutil.Open(path)
+	if err != nil {
+		return gifPlayback{}, err
+	}
+	p := gifPlayback{size: reader.Bounds().Size(), bytes: info.Size()}
+	for _, delay := range reader.GIF().Delay {
+		p.duration += time.Duration(delay) * 10 * time.Millisecond
+	}
+	switch count := reader.LoopCount(); {
+	case count == 0:
+		p.loops = 0
+	case count < 0:
+		p.loops = 1
+	default:
+		p.loops = count + 1
+	}
+	return p, nil
+}
+
+// violations lists the constraints of s the playback breaks
+func (s adSpec) violations(p gifPlayback) []string {
+	var violations []string
+	if !s.acceptsSize(p.size) {
+		violations = append(violations, fmt.Sprintf("%dx%d is not a standard ad size", p.size.X, p.size.Y))
+	}
+	if p.bytes > s.maxBytes {
+		violations = append(violations, fmt.Sprintf("%d KB is above the %d KB limit", (p.bytes+1023)>>10, s.maxBytes>>10))
+	}
+	switch {
+	case p.loops == 0:
+		violations = append(violations, fmt.Sprintf("loops forever, at most %d loops are allowed", s.maxLoops))
+	case p.loops > s.maxLoops:
+		violations = append(violations, fmt.Sprintf("plays %d times, at most %d loops are allowed", p.loops, s.maxLoops))
+	}
+	if total := p.duration * time.Duration(max(1, p.loops)); total > s.maxDuration {
+		violations = append(violations, fmt.Sprintf("plays for %v, above the %v limit", total, s.maxDuration))
+	}
+	return violations
+}
+
+// acceptsSize reports whether size is one of the accepted dimensions
+func (s adSpec) acceptsSize(size image.Point) bool {
+	for _, accepted := range s.sizes {
+		if size == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// adjust returns opts changed so that the output of the given playback
+// respects the size, duration and loop constraints of s
+func (s adSpec) adjust(opts Options, p gifPlayback) Options {
+	if !s.acceptsSize(p.size) {
+		opts.Width = 0
+		opts.Fit = s.closestSize(p.size)
+	}
+	if p.duration > s.maxDuration {
+		opts.MaxDuration = s.maxDuration
+		p.duration = s.maxDuration
+	}
+	loops := s.maxLoops
+	if p.duration > 0 {
+		loops = max(1, min(loops, int(s.maxDuration/p.duration)))
+	}
+	if opts.Loops == 0 || opts.Loops > loops {
+		opts.Loops = loops
+	}
+	return opts
+}
+
+// closestSize returns the accepted size whose aspect ratio is the closest to
+// the one of size, the largest one on ties
+func (s adSpec) closestSize(size image.Point) image.Point {
+	ratio := func(p image.Point) float64 { return math.Log(float64(p.X) / float64(p.Y)) }
+	best := s.sizes[0]
+	for _, candidate := range s.sizes[1:] {
+		d, bestD := math.Abs(ratio(candidate)-ratio(size)), math.Abs(ratio(best)-ratio(size))
+		if d < bestD-1e-9 || math.Abs(d-bestD) <= 1e-9 && candidate.X*candidate.Y > best.X*best.Y {
+			best = candidate
+		}
+	}
+	return best
+}
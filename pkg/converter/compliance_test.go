@@ -0,0 +1,145 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdSpecViolations(t *testing.T) {
+	tests := []struct {
+		name     string
+		playback gifPlayback
+		want     int
+	}{
+		{
+			name:     "compliant",
+			playback: gifPlayback{size: image.Pt(300, 250), bytes: 100 << 10, duration: 10 * time.Second, loops: 3},
+		},
+		{
+			name:     "non standard size",
+			playback: gifPlayback{size: image.Pt(301, 250), bytes: 100 << 10, duration: 10 * time.Second, loops: 1},
+			want:     1,
+		},
+		{
+			name:     "too large",
+			playback: gifPlayback{size: image.Pt(728, 90), bytes: 151 << 10, duration: time.Second, loops: 1},
+			want:     1,
+		},
+		{
+			name:     "loops forever",
+			playback: gifPlayback{size: image.Pt(728, 90), bytes: 1 << 10, duration: time.Second},
+			want:     1,
+		},
+		{
+			name:     "too long with its loops",
+			playback: gifPlayback{size: image.Pt(160, 600), bytes: 1 << 10, duration: 11 * time.Second, loops: 3},
+			want:     1,
+		},
+		{
+			name:     "everything",
+			playback: gifPlayback{size: image.Pt(100, 100), bytes: 1 << 20, duration: time.Minute, loops: 5},
+			want:     4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := iabSpec.violations(tt.playback); len(got) != tt.want {
+				t.Errorf("violations() = %q, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdSpecAdjust(t *testing.T) {
+	tests := []struct {
+		name         string
+		playback     gifPlayback
+		wantFit      image.Point
+		wantLoops    int
+		wantDuration time.Duration
+	}{
+		{
+			name:      "standard size",
+			playback:  gifPlayback{size: image.Pt(300, 250), duration: 5 * time.Second},
+			wantLoops: 3,
+		},
+		{
+			name:      "banner",
+			playback:  gifPlayback{size: image.Pt(1600, 200), duration: 12 * time.Second},
+			wantFit:   image.Pt(728, 90),
+			wantLoops: 2,
+		},
+		{
+			name:         "too long",
+			playback:     gifPlayback{size: image.Pt(600, 500), duration: time.Minute},
+			wantFit:      image.Pt(336, 280),
+			wantLoops:    1,
+			wantDuration: 30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := iabSpec.adjust(Options{Width: 400}, tt.playback)
+			if opts.Fit != tt.wantFit {
+				t.Errorf("Fit = %v, want %v", opts.Fit, tt.wantFit)
+			}
+			if opts.Loops != tt.wantLoops {
+				t.Errorf("Loops = %d, want %d", opts.Loops, tt.wantLoops)
+			}
+			if opts.MaxDuration != tt.wantDuration {
+				t.Errorf("MaxDuration = %v, want %v", opts.MaxDuration, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestConvertCheck(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 4; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+		for y := 0; y < 300; y++ {
+			for x := 0; x < 400; x++ {
+				img.Set(x, y, color.RGBA{uint8(x + i*20), uint8(y), 128, 255})
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 500 * time.Millisecond})
+	}
+	outputFile := filepath.Join(tempDir, "ad.gif")
+
+	_, err = ConvertFrames(frames, outputFile, Options{Check: CheckIAB})
+	if err == nil || !strings.Contains(err.Error(), "400x300") || !strings.Contains(err.Error(), "loops forever") {
+		t.Errorf("ConvertFrames() error = %v, want the size and looping violations", err)
+	}
+
+	report, err := ConvertFrames(frames, outputFile, Options{Check: CheckIAB, AutoAdjust: true})
+	if err != nil {
+		t.Fatalf("ConvertFrames() with auto adjust error = %v", err)
+	}
+	if report.Width != 336 || report.Height != 280 {
+		t.Errorf("output size = %dx%d, want 336x280", report.Width, report.Height)
+	}
+	playback, err := readPlayback(outputFile)
+	if err != nil {
+		t.Fatalf("readPlayback() error = %v", err)
+	}
+	if playback.loops != 3 {
+		t.Errorf("loops = %d, want 3", playback.loops)
+	}
+
+	if _, err := ConvertFrames(frames, outputFile, Options{Check: CheckIAB, Preset: "slack-emoji"}); err == nil {
+		t.Error("ConvertFrames() with a check and a preset should fail")
+	}
+}
@@ -0,0 +1,144 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+	"github.com/jparrill/go-togif/pkg/overlay"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// DefaultSheetFrames is the most frames a contact sheet shows when
+	// SheetOptions.Every is zero
+	DefaultSheetFrames = 36
+	// DefaultSheetThumbWidth is the widest a thumbnail is when
+	// SheetOptions.ThumbWidth is zero, smaller frames keeping their size
+	DefaultSheetThumbWidth = 320
+	// sheetGap is the space between and around the thumbnails, in pixels
+	sheetGap = 4
+)
+
+// sheetBackground fills the space between the thumbnails
+var sheetBackground = color.RGBA{32, 32, 32, 255}
+
+// SheetOptions configures a contact sheet
+type SheetOptions struct {
+	// Every shows every Nth frame, starting with the first one; zero picks
+	// it to show at most DefaultSheetFrames frames
+	Every int
+	// Columns is the number of thumbnails per row; zero lays them out in a
+	// grid about as wide as tall
+	Columns int
+	// ThumbWidth is the width of every thumbnail in pixels; zero keeps the
+	// frame width, up to DefaultSheetThumbWidth
+	ThumbWidth int
+}
+
+// SheetTile is a frame shown on a contact sheet
+type SheetTile struct {
+	// Frame is the index of the frame in the GIF
+	Frame int `json:"frame"`
+	// At is when the frame is first shown
+	At time.Duration `json:"at"`
+}
+
+// Sheet describes a contact sheet written by WriteContactSheet
+type Sheet struct {
+	Tiles   []SheetTile `json:"tiles"`
+	Columns int         `json:"columns"`
+	Rows    int         `json:"rows"`
+	Width   int         `json:"width"`
+	Height  int         `json:"height"`
+	// Frames is the number of frames of the GIF
+	Frames int `json:"frames"`
+}
+
+// WriteContactSheet lays every Nth frame of the GIF at inputFile out in a
+// grid, each labeled with its index and when it is shown, and writes the
+// grid as a PNG image to outputFile
+func WriteContactSheet(inputFile, outputFile string, opts SheetOptions) (Sheet, error) {
+	if opts.Every < 0 || opts.Columns < 0 || opts.ThumbWidth < 0 {
+		return Sheet{}, fmt.Errorf("every, columns and thumbnail width must be non-negative")
+	}
+	reader, err := gifutil.Open(inputFile)
+	if err != nil {
+		return Sheet{}, err
+	}
+	img, sheet, err := contactSheet(reader, opts)
+	if err != nil {
+		return Sheet{}, err
+	}
+	if err := writePNG(outputFile, img); err != nil {
+		return Sheet{}, err
+	}
+	return sheet, nil
+}
+
+// contactSheet renders the contact sheet of the frames read from reader
+func contactSheet(reader *gifutil.Reader, opts SheetOptions) (*image.RGBA, Sheet, error) {
+	sheet := Sheet{Frames: reader.Len()}
+	if sheet.Frames == 0 {
+		return nil, sheet, fmt.Errorf("the GIF has no frames")
+	}
+	every := opts.Every
+	if every == 0 {
+		every = (sheet.Frames + DefaultSheetFrames - 1) / DefaultSheetFrames
+	}
+
+	// Size the thumbnails, keeping the aspect ratio of the frames
+	bounds := reader.Bounds()
+	width := opts.ThumbWidth
+	if width == 0 {
+		width = min(bounds.Dx(), DefaultSheetThumbWidth)
+	}
+	thumb := image.Rect(0, 0, width, max(1, int(float64(bounds.Dy())*float64(width)/float64(bounds.Dx())+0.5)))
+
+	// Keep only the thumbnails of the frames shown, reading the others past
+	var thumbs []image.Image
+	var at time.Duration
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, sheet, err
+		}
+		if frame.Index%every == 0 {
+			sheet.Tiles = append(sheet.Tiles, SheetTile{Frame: frame.Index, At: at})
+			thumbs = append(thumbs, resizeTo(frame.Image, thumb, ScalerSmooth))
+		}
+		at += frame.Delay
+	}
+
+	sheet.Columns = opts.Columns
+	if sheet.Columns == 0 {
+		sheet.Columns = int(math.Ceil(math.Sqrt(float64(len(thumbs)))))
+	}
+	sheet.Columns = min(sheet.Columns, len(thumbs))
+	sheet.Rows = (len(thumbs) + sheet.Columns - 1) / sheet.Columns
+	sheet.Width = sheet.Columns*(thumb.Dx()+sheetGap) + sheetGap
+	sheet.Height = sheet.Rows*(thumb.Dy()+sheetGap) + sheetGap
+
+	img := image.NewRGBA(image.Rect(0, 0, sheet.Width, sheet.Height))
+	overlay.FillRect(img, img.Bounds(), sheetBackground)
+	labelSize := overlay.CaptionSize(thumb) * 0.75
+	for i, t := range thumbs {
+		origin := image.Pt(sheetGap+i%sheet.Columns*(thumb.Dx()+sheetGap), sheetGap+i/sheet.Columns*(thumb.Dy()+sheetGap))
+		tile := thumb.Add(origin)
+		// Transparent areas show the background
+		xdraw.Draw(img, tile, t, t.Bounds().Min, xdraw.Over)
+
+		label := fmt.Sprintf("#%d %s", sheet.Tiles[i].Frame, formatTimestamp(sheet.Tiles[i].At))
+		if _, err := overlay.DrawLabel(img, label, tile.Min.X, tile.Min.Y, labelSize, overlay.DefaultCaptionStyle); err != nil {
+			return nil, sheet, fmt.Errorf("error drawing contact sheet label: %v", err)
+		}
+	}
+	return img, sheet, nil
+}
@@ -0,0 +1,123 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeCountingGIF writes a GIF of n frames of the given size, frame i
+// showing its index in the red channel for delay 100ths of a second
+func writeCountingGIF(t *testing.T, path string, n, width, height, delay int) {
+	t.Helper()
+	g := &gif.GIF{}
+	for i := 0; i < n; i++ {
+		palette := color.Palette{color.RGBA{uint8(i), 0, 0, 255}}
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, width, height), palette))
+		g.Delay = append(g.Delay, delay)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create GIF: %v", err)
+	}
+	defer file.Close()
+	if err := gif.EncodeAll(file, g); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+}
+
+func TestWriteContactSheet(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFile := filepath.Join(tempDir, "in.gif")
+	writeCountingGIF(t, inputFile, 100, 40, 20, 5)
+
+	tests := []struct {
+		name       string
+		opts       SheetOptions
+		wantFrames []int
+		wantGrid   image.Point
+		wantSize   image.Point
+		wantErr    bool
+	}{
+		{
+			name:       "Every 25th frame",
+			opts:       SheetOptions{Every: 25},
+			wantFrames: []int{0, 25, 50, 75},
+			wantGrid:   image.Pt(2, 2),
+			wantSize:   image.Pt(2*44+4, 2*24+4),
+		},
+		{
+			name:       "Columns and thumbnail width",
+			opts:       SheetOptions{Every: 40, Columns: 5, ThumbWidth: 20},
+			wantFrames: []int{0, 40, 80},
+			wantGrid:   image.Pt(3, 1),
+			wantSize:   image.Pt(3*24+4, 14+4),
+		},
+		{
+			name:     "At most the default frames",
+			wantGrid: image.Pt(6, 6),
+		},
+		{name: "Negative", opts: SheetOptions{Every: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, "sheet.png")
+			sheet, err := WriteContactSheet(inputFile, outputFile, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteContactSheet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if sheet.Frames != 100 || image.Pt(sheet.Columns, sheet.Rows) != tt.wantGrid {
+				t.Errorf("sheet = %d frames in a %dx%d grid, want 100 in %v", sheet.Frames, sheet.Columns, sheet.Rows, tt.wantGrid)
+			}
+			if tt.wantFrames != nil {
+				var frames []int
+				for _, tile := range sheet.Tiles {
+					frames = append(frames, tile.Frame)
+					// Every frame is shown for 50ms
+					if want := time.Duration(tile.Frame) * 50 * time.Millisecond; tile.At != want {
+						t.Errorf("frame %d at %v, want %v", tile.Frame, tile.At, want)
+					}
+				}
+				if !slices.Equal(frames, tt.wantFrames) {
+					t.Errorf("sheet frames = %v, want %v", frames, tt.wantFrames)
+				}
+			}
+
+			file, err := os.Open(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to open sheet: %v", err)
+			}
+			defer file.Close()
+			img, err := png.Decode(file)
+			if err != nil {
+				t.Fatalf("Failed to decode sheet: %v", err)
+			}
+			if img.Bounds().Size() != image.Pt(sheet.Width, sheet.Height) {
+				t.Errorf("sheet image is %v, report %dx%d", img.Bounds().Size(), sheet.Width, sheet.Height)
+			}
+			if tt.wantSize != (image.Point{}) && img.Bounds().Size() != tt.wantSize {
+				t.Errorf("sheet image is %v, want %v", img.Bounds().Size(), tt.wantSize)
+			}
+		})
+	}
+
+	if _, err := WriteContactSheet(filepath.Join(tempDir, "missing.gif"), filepath.Join(tempDir, "sheet.png"), SheetOptions{}); err == nil {
+		t.Error("WriteContactSheet() expected error for a missing GIF")
+	}
+}
@@ -1,317 +1,1867 @@
 package converter
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/gif"
-	"image/png"
 	"os"
 	"path/filepath"
-	"regexp"
-	"sort"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/jparrill/go-togif/pkg/a11y"
+	"github.com/jparrill/go-togif/pkg/decode"
+	"github.com/jparrill/go-togif/pkg/gamut"
+	"github.com/jparrill/go-togif/pkg/overlay"
 	"github.com/jparrill/go-togif/pkg/ui"
 	xdraw "golang.org/x/image/draw"
 )
 
+// Scalers select how frames are resized
+const (
+	// ScalerSmooth resamples with a Catmull-Rom filter
+	ScalerSmooth = "smooth"
+	// ScalerNearest repeats or drops pixels, keeping pixel art crisp
+	ScalerNearest = "nearest"
+)
+
+// Options holds the settings used by Convert
+type Options struct {
+	// Delay between frames in milliseconds
+	Delay int
+	// Debug enables detailed progress output
+	Debug bool
+	// ProgressLog is a file timestamped progress records are appended to,
+	// independently of the terminal UI, so that a conversion running in the
+	// background can be followed with tail -f
+	ProgressLog string
+	// Trace is the file the per-frame decode time, resizing, colors,
+	// quantization error and encoded size are written to, a line per frame
+	Trace string
+	// RespectDPI downscales high density inputs (e.g. 2x retina captures) to logical pixels
+	RespectDPI bool
+	// PosterFrame is the index of the frame to show first, used by apps as the static preview
+	PosterFrame int
+	// AutoPoster shows the most detailed frame first when the first frame has
+	// little detail, such as a fade in, and PosterFrame is 0. It is set by Preset.
+	AutoPoster bool
+	// PosterMode selects how the poster frame is moved to the front (PosterDuplicate or PosterRotate)
+	PosterMode string
+	// IgnoreSidecars ignores the per-frame options files next to the inputs (see Sidecar)
+	IgnoreSidecars bool
+	// FrameDelays sets the delay of each input file, zero meaning Delay, as
+	// listed in an input manifest (see ReadManifest)
+	FrameDelays []time.Duration
+	// TrimIdle caps the delays frames come with, such as the pauses of a
+	// terminal recording or of an input manifest, at this long; 0 keeps them.
+	// Delay, Timeline and Chapters are not capped.
+	TrimIdle time.Duration
+	// Timeline overrides the delay of frame ranges, e.g. "0-30@100ms,31@2s" (see ParseTimeline)
+	Timeline string
+	// Chapters label the frames the steps of a tutorial start at, each shown
+	// for at least ChapterHold unless Timeline sets its delay (see LoadChapters)
+	Chapters []Chapter
+	// ChapterHold is how long the first frame of every chapter is shown at
+	// least, DefaultChapterHold when zero
+	ChapterHold time.Duration
+	// Manifest is the path of a JSON index of the frames of the first GIF
+	// output, their delays and the chapters they start (see Manifest)
+	Manifest string
+	// Start and End trim the sequence to the frames played within [Start, End); zero values disable trimming
+	Start time.Duration
+	End   time.Duration
+	// Subtitles is the path of an SRT file burned onto the frames, timed against the untrimmed sequence
+	Subtitles string
+	// Annotations is the path of a JSON file describing arrows, rectangles, highlights and
+	// text drawn on ranges of frames (see overlay.Annotation)
+	Annotations string
+	// FrameLabel is a template drawn in the top left corner of every frame,
+	// such as "{file} {time}" (see FrameMeta.Label)
+	FrameLabel string
+	// Stamp is a template expanded with the environment variables, such as
+	// "branch:{{.GIT_BRANCH}} run:{{.CI_RUN}}", drawn in a corner of every
+	// frame so that CI artifacts identify their build (see ExpandStamp)
+	Stamp string
+	// StampCorner is the corner the stamp is drawn in, CornerBottomRight when empty
+	StampCorner string
+	// Events is the path of a keystroke/click events file recorded with the capture,
+	// rendered as keystroke badges and click markers (see overlay.Event)
+	Events string
+	// Waveform is the path of a WAV audio track recorded with the capture,
+	// such as the narration of a screen recording, drawn as a scrolling
+	// waveform strip along the bottom of every frame (see overlay.DrawWaveform)
+	Waveform string
+	// CaptionStyle sets the colors of burned-in captions, DefaultCaptionStyle when unset
+	CaptionStyle overlay.CaptionStyle
+	// PreserveText detects text areas and keeps them crisp, trading quality in the background
+	PreserveText bool
+	// TextRegions are areas handled like detected text, in output frame coordinates
+	TextRegions []image.Rectangle
+	// Normalize matches the brightness of every frame to the first one, removing
+	// the flicker of captures taken with different monitor profiles
+	Normalize bool
+	// Equalize spreads the luma of every frame over the whole range (histogram
+	// equalization), raising the contrast of dull frames
+	Equalize bool
+	// LUT is a .cube file with a 3D color lookup table grading every frame
+	LUT string
+	// Vignette darkens the edges of every frame, from 0 (none) to 1 (black corners)
+	Vignette float64
+	// CornerRadius rounds the corners of every frame by this many pixels of
+	// the output, fading them into CornerColor
+	CornerRadius int
+	// CornerColor is shown outside the rounded corners, white when unset
+	CornerColor color.Color
+	// Stabilize cancels the camera shake of handheld sequences, shifting every
+	// frame onto the first one and cropping to the area they all show
+	Stabilize bool
+	// AlignOn is a PNG image found in every frame, which are shifted to keep
+	// it in place and cropped to the area they all show, like Stabilize
+	AlignOn string
+	// SmartCrop crops every frame to a window of this size, placed once for the
+	// whole sequence where the content has the most detail; zero keeps the frames whole
+	SmartCrop image.Point
+	// OnionSkin blends every frame with faded copies of this many previous
+	// frames, leaving motion trails; 0 disables it
+	OnionSkin int
+	// SmoothLoop crossfades the last this many frames into the first one, so
+	// the loop closes without a visible jump; 0 disables it
+	SmoothLoop int
+	// LoopSearch cuts the sequence before the frame among the last this many
+	// looking the most like the first one, so it loops with the smallest
+	// jump; 0 keeps every frame
+	LoopSearch int
+	// FindLoop trims periodic content, such as a spinner, to exactly one
+	// cycle, starting where looping back is the least visible
+	FindLoop bool
+	// MagnifyChanges draws in a corner of every frame an inset of the area
+	// where it changed since the previous frame, zoomed this many times, so
+	// small interactions stay visible in large captures; 0 disables it
+	MagnifyChanges int
+	// MagnifyCorner is the corner of the inset, CornerBottomRight when empty
+	MagnifyCorner string
+	// VisualizeDiff encodes a heatmap of the pixels changed since the previous
+	// frame instead of every frame, for debugging flaky visual tests
+	VisualizeDiff bool
+	// FrameHook lets programs embedding go-togif draw over every frame after
+	// the built-in transforms and overlays, before the frames are quantized.
+	// It is called once per frame, in order, with the index of the frame in
+	// the untrimmed sequence (see FrameMeta.Index); an error fails the
+	// conversion. A checkpoint cannot tell hooks apart, so resume only with
+	// the same hook.
+	FrameHook func(index int, img draw.Image) error `json:"-"`
+	// MaxDuration keeps the playback within this long by dropping evenly
+	// spaced frames, or when fewer than two would be left, the frames shown
+	// after it, shortening the last one kept; 0 keeps every frame. It is set
+	// by Preset.
+	MaxDuration time.Duration
+	// MaxFrames keeps at most this many evenly spaced frames, each shown for
+	// as long as the frames dropped after it; 0 keeps every frame
+	MaxFrames int
+	// Score compares the first GIF output, decoded, to the frames it was
+	// quantized from, setting Report.SSIM and Report.PSNR
+	Score bool
+	// Verify decodes every GIF output once written and fails the conversion
+	// when its frame count, dimensions, delays or a sample of the pixels of
+	// its composited frames differ from the encoded frames
+	Verify bool
+	// MinSSIM fails the conversion when the SSIM of the first GIF output is
+	// below it, from 0 to 1, scoring it even without Score
+	MinSSIM float64
+	// PreviewFrames encodes only the first this many frames of the output, with
+	// every other setting, to PreviewPath of the output file; 0 encodes every
+	// frame. Settings picked from the content, like the palette and Auto, are
+	// picked from these frames only.
+	PreviewFrames int
+	// Guides are aspect ratios, such as "16:9", whose largest centered area
+	// and safe area are drawn over the first frame of a preview, written to
+	// GuidesPath of the output file
+	Guides []string
+	// Fit crops every frame to the aspect ratio of this size, keeping the most
+	// detailed area like SmartCrop, and scales it to exactly this size. It is
+	// set by Preset.
+	Fit image.Point
+	// Format is the output format, FormatGIF when empty. FormatWebM,
+	// FormatWebP and FormatMP4 are encoded through ffmpeg (see FFmpegEnv),
+	// skipping the palette.
+	Format string
+	// Formats writes the frames in every listed format at once, sharing the
+	// decoding and transformations, to outputFile with the extension of each
+	// format (see FormatPath). It excludes Format and Sizes.
+	Formats []string
+	// VideoCRF is the constant rate factor of video outputs, from 1 (best) to
+	// MaxVideoCRF, DefaultVideoCRF when 0
+	VideoCRF int
+	// NoExec disables running external tools, failing the outputs encoded by
+	// ffmpeg instead
+	NoExec bool
+	// ExecTimeout bounds each run of an external tool, DefaultExecTimeout when 0
+	ExecTimeout time.Duration
+	// Preset fits the output to the limits of a platform (see Presets),
+	// converting again with fewer colors and frames until the file is small enough
+	Preset string
+	// Proof also writes, next to every GIF, the quantized frames as they look
+	// once mapped to this narrower gamut (see ProofPath and the gamut package),
+	// to check that brand colors survive
+	Proof string
+	// SourceGamut is the gamut the inputs are encoded in, gamut.SRGB when empty
+	SourceGamut string
+	// HashName inserts a short hash of their content in the name of the
+	// outputs, e.g. demo.3fa2c1.gif, for cache busting (see HashedPath)
+	HashName bool
+	// Loops plays the animation this many times, 0 looping forever
+	Loops int
+	// Check validates the output against the constraints of an ad network
+	// (CheckIAB): standard sizes, file size, duration and looping, failing when
+	// the output breaks them
+	Check string
+	// AutoAdjust makes Check convert again at the closest standard size, with
+	// fewer loops, frames and colors, instead of failing
+	AutoAdjust bool
+	// Width scales the output to this many pixels wide, keeping the aspect ratio; 0 keeps the input size
+	Width int
+	// MaxWidth scales outputs wider than this many pixels down to it, keeping
+	// the aspect ratio; 0 means no limit. It is set by Preset.
+	MaxWidth int
+	// Colors limits the size of the generated palette, 0 meaning MaxColors
+	Colors int
+	// Quantizer selects how the palette is generated, QuantizerFrequency when empty
+	Quantizer string
+	// Dither selects how frames are mapped to the palette, DitherNone when empty
+	Dither string
+	// Scaler selects how frames are resized, ScalerSmooth when empty
+	Scaler string
+	// Auto analyzes a few frames and picks the quantizer, dithering, colors and
+	// scaling left unset for the detected content (see AutoSettings)
+	Auto bool
+	// Segments splits sequences alternating between slides and photos into
+	// runs of the same content, each quantized with its own palette and the
+	// quantizer, dithering, colors and text preservation left unset picked
+	// for its content (see Segment)
+	Segments bool
+	// WithPoster also writes the poster frame, with overlays and scaling applied,
+	// as a full color PNG next to every GIF (see PosterPath)
+	WithPoster bool
+	// EmitSnippet writes a Markdown (SnippetMarkdown) or HTML (SnippetHTML) embed
+	// snippet next to the output (see SnippetPath)
+	EmitSnippet string
+	// GIFVersion selects GIF89a (default) or strict GIF87a output without extension blocks
+	GIFVersion string
+	// GIFExtensions lists the GIF89a extension blocks to write (see Extensions), all when nil
+	GIFExtensions []string
+	// Encoder selects the GIF encoder, EncoderNative or EncoderStdlib, EncoderNative when empty
+	Encoder string
+	// Workers is the number of frames decoded, transformed, quantized and
+	// compressed by the native encoder concurrently, runtime.NumCPU() when 0
+	Workers int
+	// IOWorkers is the number of input files read concurrently ahead of the
+	// processing, independently of Workers. Files are read one at a time when 0.
+	IOWorkers int
+	// QueueDepth is the number of frames waiting between two stages of the
+	// pipeline reading, decoding and transforming frames, DefaultQueueDepth when 0.
+	// It bounds the frames in flight only: every transformed frame is kept
+	// until all are quantized and encoded, spilled to disk near MemoryLimit.
+	QueueDepth int
+	// WorkDir is where the palette and quantized frames are saved while converting,
+	// so that an interrupted conversion can be resumed. Nothing is saved when empty.
+	WorkDir string
+	// Resume reuses the checkpoint of the same conversion found in WorkDir
+	Resume bool
+	// TmpDir is where temporary files, such as frames spilled to disk, are
+	// written, the system temporary directory when empty
+	TmpDir string
+	// KeepTemp keeps the temporary files for debugging instead of removing them
+	KeepTemp bool
+	// MemoryLimit is a soft limit of the heap size in bytes (see ParseMemoryLimit).
+	// When approached, decoded frames are moved to disk and fewer frames are
+	// encoded concurrently. No limit when 0.
+	MemoryLimit int64
+	// InputLimits bounds the size, number and decoding time of the inputs
+	InputLimits InputLimits
+	// Sizes writes one GIF per output width instead of outputFile, named by
+	// SizedOutputPath, sharing the decoding and palette work
+	Sizes []int
+	// ROIs writes one GIF per region of interest instead of outputFile, named
+	// by ROIOutputPath, each cropped from the same frames and quantized with
+	// its own palette
+	ROIs []ROI
+	// Palette replaces the palette generated from the frames (see ReadPalette)
+	Palette color.Palette
+	// CompatReport scans the GIF outputs for the features known to break in
+	// some renderers, such as old Android versions, Outlook and terminals,
+	// adding a WarningCompat warning for each (see compat.Renderers)
+	CompatReport bool
+	// A11yCheck writes color vision deficiency previews of sample frames and
+	// warns about overlay text below the WCAG contrast thresholds
+	A11yCheck bool
+	// TermFontSize is the font size in pixels used to render terminal recordings
+	TermFontSize float64
+	// TermFPS is the maximum frame rate used to render terminal recordings
+	TermFPS int
+	// VideoFPS is the rate frames are extracted from the video inputs of
+	// ConvertVideo at, DefaultVideoFPS when 0
+	VideoFPS float64
+}
+
+// Report summarizes the result of a conversion
+type Report struct {
+	OutputFile string `json:"outputFile"`
+	// Outputs lists every GIF written, one per size when Options.Sizes is set
+	// and one per region of interest with Options.ROIs
+	Outputs []Output `json:"outputs"`
+	Frames  int      `json:"frames"`
+	Width   int      `json:"width"`
+	Height  int      `json:"height"`
+	// SourceDPI is the density declared by the first input, 0 if unknown
+	SourceDPI float64 `json:"sourceDPI,omitempty"`
+	// EffectiveDPI is the density of the generated GIF, 0 if unknown
+	EffectiveDPI float64 `json:"effectiveDPI,omitempty"`
+	// Auto holds the settings picked by Options.Auto
+	Auto *AutoSettings `json:"auto,omitempty"`
+	// Snippet is the embed snippet written with Options.EmitSnippet
+	Snippet string `json:"snippet,omitempty"`
+	// SourceColors is the number of distinct colors of the input, counted on a
+	// few frames and up to just past ManyColors, 0 when not counted
+	SourceColors int `json:"sourceColors,omitempty"`
+	// ResumedFrames counts the quantized frames reused from a checkpoint
+	ResumedFrames int `json:"resumedFrames,omitempty"`
+	// PeakHeap is the largest heap size sampled during the conversion, in bytes
+	PeakHeap uint64 `json:"peakHeap,omitempty"`
+	// PeakRSS is the peak resident set size of the process in bytes, 0 if unknown
+	PeakRSS uint64 `json:"peakRSS,omitempty"`
+	// SpilledFrames counts the frames moved to disk to stay below Options.MemoryLimit
+	SpilledFrames int `json:"spilledFrames,omitempty"`
+	// KeptTemp lists the temporary directories kept with Options.KeepTemp
+	KeptTemp []string `json:"keptTemp,omitempty"`
+	// Clients tells which email clients animate the output, listed with the
+	// email preset
+	Clients []ClientSupport `json:"clients,omitempty"`
+	// A11yPreviews lists the color vision deficiency previews written by the accessibility check
+	A11yPreviews []string `json:"a11yPreviews,omitempty"`
+	// SSIM is the mean structural similarity of the frames of the first GIF
+	// output to the frames they were quantized from, set with Options.Score
+	SSIM float64 `json:"ssim,omitempty"`
+	// PSNR is the mean peak signal-to-noise ratio, in dB, of the same frames
+	PSNR float64 `json:"psnr,omitempty"`
+	// Preview is set when only the first Options.PreviewFrames frames were encoded
+	Preview bool `json:"preview,omitempty"`
+	// Guides is the first frame of a preview with Options.Guides drawn over it
+	Guides string `json:"guides,omitempty"`
+	// Chapters are the Options.Chapters shown, by their frame in the output
+	Chapters []Chapter `json:"chapters,omitempty"`
+	// Segments are the runs of frames quantized with their own settings with
+	// Options.Segments
+	Segments []Segment `json:"segments,omitempty"`
+	// Warnings collects the problems found during the conversion
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// Output is a GIF written by a conversion
+type Output struct {
+	File   string `json:"file"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Poster is the static preview written with Options.WithPoster
+	Poster string `json:"poster,omitempty"`
+	// Proof is the gamut proof written with Options.Proof
+	Proof string `json:"proof,omitempty"`
+	// Format is the format of the file (see Formats)
+	Format string `json:"format,omitempty"`
+	// Region names the region of interest of Options.ROIs the file shows
+	Region string `json:"region,omitempty"`
+}
+
 // ConvertPNGsToGIF converts a series of PNG images to a GIF
 func ConvertPNGsToGIF(inputFiles []string, outputFile string, delay int, debug bool) error {
+	_, err := Convert(inputFiles, outputFile, Options{Delay: delay, Debug: debug})
+	return err
+}
+
+// Frame is an in-memory image passed to ConvertFrames
+type Frame struct {
+	Image image.Image
+	// Delay is how long the frame is shown, Options.Delay when zero
+	Delay time.Duration
+	// Tags are values passed to the overlays in FrameMeta.Tags
+	Tags map[string]string
+}
+
+// source describes the frames of a conversion, loaded one at a time
+type source struct {
+	// names identify the frames in progress updates
+	names []string
+	// delays are in 100ths of a second, zero meaning Options.Delay
+	delays []int
+	load   func(i int) (image.Image, error)
+	// read returns the encoded data of a frame and decode turns it into an
+	// image, so that I/O and decoding run in separate pipeline stages. Sources
+	// without I/O leave them nil and only use load.
+	read   func(i int) ([]byte, error)
+	decode func(i int, data []byte) (image.Image, error)
+	// density returns the DPI of a frame, nil when unknown
+	density func(i int) (float64, error)
+	// files are the input files the frames come from, empty for in-memory frames
+	files []string
+	// tags are the user values of each frame, nil when there are none
+	tags []map[string]string
+	// captions replace the subtitles of each frame, nil when there are none
+	captions []string
+}
+
+// Convert converts a series of PNG, WebP, BMP or TIFF images, or a single
+// asciinema recording or animated WebP, to a GIF using the given options
+func Convert(inputFiles []string, outputFile string, opts Options) (*Report, error) {
 	if len(inputFiles) == 0 {
-		return fmt.Errorf("no input files specified")
+		return nil, fmt.Errorf("no input files specified")
+	}
+
+	// Terminal recordings are rendered into frames first
+	if len(inputFiles) == 1 && IsCastFile(inputFiles[0]) {
+		frames, err := renderCast(inputFiles[0], opts)
+		if err != nil {
+			return nil, err
+		}
+		src, err := frameSource(frames)
+		if err != nil {
+			return nil, err
+		}
+		src.files = inputFiles
+		return run(src, outputFile, opts)
+	}
+
+	// Animated WebPs are expanded into their frames, with their durations
+	if len(inputFiles) == 1 && isAnimatedWebP(inputFiles[0]) {
+		frames, err := decodeAnimatedWebP(inputFiles[0], opts)
+		if err != nil {
+			return nil, err
+		}
+		src, err := frameSource(frames)
+		if err != nil {
+			return nil, err
+		}
+		src.files = inputFiles
+		return run(src, outputFile, opts)
+	}
+
+	src, err := inputSource(inputFiles, opts)
+	if err != nil {
+		return nil, err
+	}
+	return run(src, outputFile, opts)
+}
+
+// fileDelays returns the delays of the input files, in 100ths of a second,
+// from Options.FrameDelays
+func fileDelays(inputFiles []string, opts Options) ([]int, error) {
+	delays := make([]int, len(inputFiles))
+	if len(opts.FrameDelays) == 0 {
+		return delays, nil
+	}
+	if len(opts.FrameDelays) != len(inputFiles) {
+		return nil, fmt.Errorf("got %d frame delays for %d input files", len(opts.FrameDelays), len(inputFiles))
+	}
+	for i, delay := range opts.FrameDelays {
+		delays[i] = centiseconds(delay)
+	}
+	return delays, nil
+}
+
+// fileSource reads frames from PNG, WebP, BMP and TIFF files, decoding them within limits
+func fileSource(inputFiles []string, limits decode.Limits) source {
+	return source{
+		names:  inputFiles,
+		delays: make([]int, len(inputFiles)),
+		load:   func(i int) (image.Image, error) { return decodeFrame(inputFiles[i], limits) },
+		read: func(i int) ([]byte, error) {
+			data, err := os.ReadFile(inputFiles[i])
+			if err != nil {
+				return nil, fmt.Errorf("error opening file %s: %v", inputFiles[i], err)
+			}
+			return data, nil
+		},
+		decode:  func(i int, data []byte) (image.Image, error) { return decodeFrameData(inputFiles[i], data, limits) },
+		density: func(i int) (float64, error) { return readDensity(inputFiles[i]) },
+		files:   inputFiles,
+	}
+}
+
+// ConvertFrames converts in-memory frames to a GIF using the given options
+func ConvertFrames(frames []Frame, outputFile string, opts Options) (*Report, error) {
+	src, err := frameSource(frames)
+	if err != nil {
+		return nil, err
+	}
+	return run(src, outputFile, opts)
+}
+
+// frameSource reads frames from memory
+func frameSource(frames []Frame) (source, error) {
+	if len(frames) == 0 {
+		return source{}, fmt.Errorf("no frames specified")
+	}
+
+	src := source{
+		names:  make([]string, len(frames)),
+		delays: make([]int, len(frames)),
+		load:   func(i int) (image.Image, error) { return frames[i].Image, nil },
+	}
+	for i, frame := range frames {
+		if frame.Image == nil {
+			return source{}, fmt.Errorf("frame %d has no image", i)
+		}
+		src.names[i] = fmt.Sprintf("frame %d", i)
+		src.delays[i] = centiseconds(frame.Delay)
+		if frame.Tags != nil {
+			if src.tags == nil {
+				src.tags = make([]map[string]string, len(frames))
+			}
+			src.tags[i] = frame.Tags
+		}
+	}
+	return src, nil
+}
+
+// centiseconds converts a duration to GIF delay units, keeping non-zero durations non-zero
+func centiseconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
 	}
+	return max(1, int((d+5*time.Millisecond)/(10*time.Millisecond)))
+}
 
+// validateOptions checks the settings that do not depend on the input
+func validateOptions(opts Options) error {
 	// Validate delay
-	if delay < 0 {
+	if opts.Delay < 0 {
 		return fmt.Errorf("delay must be non-negative")
 	}
 
-	// Create a channel for progress updates
-	progressChan := ui.RunUI(debug, len(inputFiles))
+	// Validate the output size and colors
+	if opts.MaxWidth < 0 {
+		return fmt.Errorf("max width must be non-negative")
+	}
+	if opts.Width < 0 {
+		return fmt.Errorf("width must be non-negative")
+	}
+	if opts.Colors < 0 || opts.Colors > MaxColors {
+		return fmt.Errorf("colors must be between 1 and %d", MaxColors)
+	}
 
-	// First, read all images and get dimensions
-	var firstImgBounds image.Rectangle
-	var images []*image.Paletted
-	var err error
+	if err := validateVideoFPS(opts); err != nil {
+		return err
+	}
 
-	// Create a color map to store unique colors
-	colorMap := make(map[color.Color]bool)
-	var palette []color.Color
+	if opts.Vignette < 0 || opts.Vignette > 1 {
+		return fmt.Errorf("vignette must be between 0 and 1")
+	}
+	if opts.CornerRadius < 0 {
+		return fmt.Errorf("corner radius must be non-negative")
+	}
+	if opts.OnionSkin < 0 {
+		return fmt.Errorf("onion skin must be non-negative")
+	}
+	if opts.SmoothLoop < 0 {
+		return fmt.Errorf("smooth loop must be non-negative")
+	}
+	if opts.MagnifyChanges < 0 || opts.MagnifyChanges == 1 {
+		return fmt.Errorf("magnify changes must be 0 or a zoom of at least 2")
+	}
+	if err := validateCorner("magnify", opts.MagnifyCorner); err != nil {
+		return err
+	}
+	if opts.LoopSearch < 0 {
+		return fmt.Errorf("loop search must be non-negative")
+	}
+	if opts.FindLoop && opts.LoopSearch > 0 {
+		return fmt.Errorf("find loop and loop search are mutually exclusive")
+	}
+	if opts.Stabilize && opts.AlignOn != "" {
+		return fmt.Errorf("stabilize and align on are mutually exclusive")
+	}
+	if opts.SmartCrop.X < 0 || opts.SmartCrop.Y < 0 {
+		return fmt.Errorf("smart crop size must be positive")
+	}
 
-	// Process each image
-	for i, inputFile := range inputFiles {
-		// Update progress
-		progressChan <- ui.ProgressMsg{
-			CurrentFile: inputFile,
-			Processed:   i,
-			Total:       len(inputFiles),
+	if opts.MaxDuration < 0 {
+		return fmt.Errorf("max duration must be non-negative")
+	}
+	if opts.TrimIdle < 0 {
+		return fmt.Errorf("trim idle must be non-negative")
+	}
+	if opts.TrimIdle > 0 && opts.TrimIdle < 10*time.Millisecond {
+		return fmt.Errorf("trim idle must be at least 10ms, the shortest GIF delay")
+	}
+	if opts.ChapterHold < 0 {
+		return fmt.Errorf("chapter hold must be non-negative")
+	}
+	if opts.MaxFrames < 0 {
+		return fmt.Errorf("max frames must be non-negative")
+	}
+	if opts.PreviewFrames < 0 {
+		return fmt.Errorf("preview frames must be non-negative")
+	}
+	if opts.PreviewFrames > 0 && opts.Check != "" {
+		return fmt.Errorf("check validates the full output, it cannot be combined with preview frames")
+	}
+	if err := validateGuides(opts.Guides); err != nil {
+		return err
+	}
+	if opts.Fit.X < 0 || opts.Fit.Y < 0 {
+		return fmt.Errorf("fit size must be positive")
+	}
+	if opts.Preset != "" {
+		if _, err := LookupPreset(opts.Preset); err != nil {
+			return err
+		}
+		if opts.Width > 0 || len(opts.Sizes) > 0 {
+			return fmt.Errorf("preset sets the output size, it cannot be combined with width or sizes")
+		}
+		if opts.Format != "" || len(opts.Formats) > 0 {
+			return fmt.Errorf("preset sets the output format, it cannot be combined with format or formats")
+		}
+	}
+
+	if opts.Loops < 0 || opts.Loops > maxLoops {
+		return fmt.Errorf("loops must be between 0 and %d", maxLoops)
+	}
+	if opts.Check != "" {
+		if _, err := lookupCheck(opts.Check); err != nil {
+			return err
+		}
+		if opts.Preset != "" {
+			return fmt.Errorf("check and preset are mutually exclusive")
+		}
+		if !isGIF(opts.Format) || len(opts.Formats) > 0 || len(opts.Sizes) > 0 || len(opts.ROIs) > 0 {
+			return fmt.Errorf("check validates a single GIF, it cannot be combined with other formats, sizes or regions of interest")
+		}
+	}
+
+	if opts.Width > 0 && len(opts.Sizes) > 0 {
+		return fmt.Errorf("width and sizes are mutually exclusive")
+	}
+	for _, size := range opts.Sizes {
+		if size <= 0 {
+			return fmt.Errorf("sizes must be positive")
+		}
+	}
+	if len(opts.Sizes) > 0 && len(opts.ROIs) > 0 {
+		return fmt.Errorf("sizes and regions of interest are mutually exclusive")
+	}
+	if err := validateROIs(opts.ROIs); err != nil {
+		return err
+	}
+	if opts.EmitSnippet != "" && len(opts.ROIs) > 0 {
+		return fmt.Errorf("emit snippet embeds a single animation, it cannot be combined with regions of interest")
+	}
+
+	switch opts.EmitSnippet {
+	case "", SnippetMarkdown, SnippetHTML:
+	default:
+		return fmt.Errorf("invalid snippet format %q: expected %s or %s%s", opts.EmitSnippet, SnippetMarkdown, SnippetHTML,
+			suggest(opts.EmitSnippet, SnippetMarkdown, SnippetHTML))
+	}
+
+	if err := validateGIFVersion(opts); err != nil {
+		return err
+	}
+	if err := validateEncoder(opts); err != nil {
+		return err
+	}
+	if err := validateFormat(opts); err != nil {
+		return err
+	}
+	if err := validateProof(opts); err != nil {
+		return err
+	}
+	if err := validateQuality(opts); err != nil {
+		return err
+	}
+	if err := validateStamp(opts); err != nil {
+		return err
+	}
+	if opts.Resume && opts.WorkDir == "" {
+		return fmt.Errorf("resuming requires a work directory")
+	}
+	if opts.IOWorkers < 0 {
+		return fmt.Errorf("I/O workers must not be negative")
+	}
+	if opts.QueueDepth < 0 {
+		return fmt.Errorf("queue depth must not be negative")
+	}
+	if opts.MemoryLimit < 0 {
+		return fmt.Errorf("memory limit must not be negative")
+	}
+	if err := validateLimits(opts); err != nil {
+		return err
+	}
+
+	// Validate the processing choices
+	switch opts.Quantizer {
+	case "", QuantizerFrequency, QuantizerMedianCut:
+	default:
+		return fmt.Errorf("invalid quantizer %q: expected %s or %s%s", opts.Quantizer, QuantizerFrequency, QuantizerMedianCut,
+			suggest(opts.Quantizer, QuantizerFrequency, QuantizerMedianCut))
+	}
+	switch opts.Dither {
+	case "", DitherNone, DitherFloydSteinberg:
+	default:
+		return fmt.Errorf("invalid dithering %q: expected %s or %s%s", opts.Dither, DitherNone, DitherFloydSteinberg,
+			suggest(opts.Dither, DitherNone, DitherFloydSteinberg))
+	}
+	switch opts.Scaler {
+	case "", ScalerSmooth, ScalerNearest:
+	default:
+		return fmt.Errorf("invalid scaler %q: expected %s or %s%s", opts.Scaler, ScalerSmooth, ScalerNearest,
+			suggest(opts.Scaler, ScalerSmooth, ScalerNearest))
+	}
+
+	// Validate the palette
+	if len(opts.Palette) > MaxColors {
+		return fmt.Errorf("palette has %d colors, GIF supports at most %d", len(opts.Palette), MaxColors)
+	}
+	return nil
+}
+
+// playback returns the delay and playback position of every frame, given the
+// frames' own delays (0 meaning Options.Delay), along with the [first, last)
+// range of frames kept by Start and End
+func playback(frameDelays []int, opts Options) ([]int, []time.Duration, int, int, error) {
+	// Parse the timeline before doing any work
+	timeline, err := ParseTimeline(opts.Timeline)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+
+	// Frames without their own delay use the configured one, and the pauses
+	// of recorded frames are cut down to TrimIdle
+	delays := make([]int, len(frameDelays))
+	idle, trimmed := centiseconds(opts.TrimIdle), 0
+	for i := range delays {
+		delays[i] = frameDelays[i]
+		if delays[i] == 0 {
+			delays[i] = opts.Delay / 10 // Convert to 100ths of a second
+		} else if idle > 0 && delays[i] > idle {
+			trimmed += delays[i] - idle
+			delays[i] = idle
 		}
+	}
+	if opts.Debug && trimmed > 0 {
+		fmt.Printf("Trimmed %v of idle time\n", time.Duration(trimmed)*10*time.Millisecond)
+	}
+
+	// Hold the first frame of every chapter, unless the timeline sets its delay
+	if err := applyChapters(delays, opts.Chapters, opts.ChapterHold); err != nil {
+		return nil, nil, 0, 0, err
+	}
+
+	// Apply per-range delays
+	if err := applyTimeline(delays, timeline); err != nil {
+		return nil, nil, 0, 0, err
+	}
+
+	// Find the frames within the requested time window
+	positions := framePositions(delays)
+	first, last, err := trimByTime(positions, opts.Start, opts.End)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	return delays, positions, first, last, nil
+}
 
-		// Open and decode the PNG file
-		file, err := os.Open(inputFile)
+// outputBounds returns the size of the output frames for a first frame of the
+// given bounds and density, applying RespectDPI and Width, or Fit
+func outputBounds(bounds image.Rectangle, dpi float64, opts Options) image.Rectangle {
+	if opts.RespectDPI && dpi > BaseDPI {
+		bounds = logicalBounds(bounds, dpi)
+	}
+	if opts.Fit != (image.Point{}) {
+		return image.Rectangle{Max: opts.Fit}
+	}
+	width := opts.Width
+	if opts.MaxWidth > 0 && max(width, bounds.Dx()) > opts.MaxWidth {
+		width = opts.MaxWidth
+	}
+	if width > 0 && width != bounds.Dx() {
+		height := max(1, int(float64(bounds.Dy())*float64(width)/float64(bounds.Dx())+0.5))
+		bounds = image.Rect(0, 0, width, height)
+	}
+	return bounds
+}
+
+// run encodes the frames of src to outputFile, fitting them to opts.Preset
+// or checking them against opts.Check when set, and naming the outputs after
+// their content with opts.HashName
+func run(src source, outputFile string, opts Options) (*Report, error) {
+	warnings, err := ValidateOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Previews never overwrite the full output
+	if opts.PreviewFrames > 0 {
+		outputFile = PreviewPath(outputFile)
+	}
+
+	// Fail before any work when the progress log cannot be written
+	start := time.Now()
+	if opts.ProgressLog != "" {
+		if err := appendProgress(opts.ProgressLog, "started converting %d frames to %s", len(src.names), outputFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var report *Report
+	switch {
+	case opts.Preset != "":
+		report, err = fitPreset(src, outputFile, opts)
+	case opts.Check != "":
+		report, err = checkCompliance(src, outputFile, opts)
+	default:
+		report, err = convertSource(src, outputFile, opts)
+	}
+	if opts.ProgressLog != "" {
 		if err != nil {
-			return fmt.Errorf("error opening file %s: %v", inputFile, err)
+			appendProgress(opts.ProgressLog, "failed after %v: %v", time.Since(start).Round(time.Second), err)
+		} else {
+			appendProgress(opts.ProgressLog, "finished %d frames to %s in %v", report.Frames, report.OutputFile, time.Since(start).Round(time.Second))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.MinSSIM > 0 && report.SSIM < opts.MinSSIM {
+		return nil, fmt.Errorf("%s scores SSIM %.4f, below the minimum of %.4f", report.OutputFile, report.SSIM, opts.MinSSIM)
+	}
+	report.Preview = opts.PreviewFrames > 0
+	report.Warnings = append(warnings, report.Warnings...)
+	if opts.HashName {
+		if report, err = hashOutputs(report, outputFile, opts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.CompatReport {
+		if err := compatReport(report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// convertSource encodes the frames of src to outputFile with validated
+// options, once
+func convertSource(src source, outputFile string, opts Options) (*Report, error) {
+	// Work out when each frame is shown and which ones are kept
+	delays, positions, first, last, err := playback(src.delays, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// inputs maps the frames of src to their index in the inputs, which the
+	// warnings refer to
+	inputs := make([]int, len(src.names))
+	for i := range inputs {
+		inputs[i] = i
+	}
+	keep := func(indices []int) {
+		kept := make([]int, len(indices))
+		for k, i := range indices {
+			kept[k] = inputs[i]
 		}
-		defer file.Close()
+		inputs = kept
+	}
+	var warnings []Warning
 
-		img, err := png.Decode(file)
+	if opts.MaxDuration > 0 {
+		if indices := subsampleDuration(delays, first, last, opts.MaxDuration); indices != nil {
+			if opts.Debug {
+				fmt.Printf("Keeping %d of %d frames to play within %v\n", len(indices), last-first, opts.MaxDuration)
+			}
+			dropped := droppedFrames(inputs, first, last, indices)
+			warnings = append(warnings, Warning{Kind: WarningSkippedFrame, Frames: dropped,
+				Message: fmt.Sprintf("skipped %d of %d frames to play within %v", len(dropped), last-first, opts.MaxDuration)})
+			var kept source
+			kept, positions = keepFrames(src, positions, indices)
+			for k, i := range indices {
+				kept.delays[k] = delays[i]
+			}
+			src, delays, first, last = kept, kept.delays, 0, len(indices)
+			keep(indices)
+		} else if clipped := clipDuration(delays, positions, first, last, opts.MaxDuration); clipped < last {
+			warnings = append(warnings, Warning{Kind: WarningSkippedFrame, Frames: inputs[clipped:last],
+				Message: fmt.Sprintf("cut the last %d of %d frames to play within %v", last-clipped, last-first, opts.MaxDuration)})
+			last = clipped
+		}
+	}
+	if opts.MaxFrames > 0 && last-first > opts.MaxFrames {
+		indices := sampleIndices(first, last, opts.MaxFrames)
+		dropped := droppedFrames(inputs, first, last, indices)
+		warnings = append(warnings, Warning{Kind: WarningSkippedFrame, Frames: dropped,
+			Message: fmt.Sprintf("skipped %d of %d frames to keep at most %d", len(dropped), last-first, opts.MaxFrames)})
+		src, delays, positions = dropFrames(src, delays, positions, first, last, opts.MaxFrames)
+		first, last = 0, opts.MaxFrames
+		keep(indices)
+	}
+	if opts.LoopSearch > 0 {
+		loop, err := findLoopPoint(src, first, last, opts.LoopSearch)
+		if err != nil {
+			return nil, err
+		}
+		if loop < last {
+			if opts.Debug {
+				fmt.Printf("Looping before frame %d, the most like the first one\n", inputs[loop])
+			}
+			warnings = append(warnings, Warning{Kind: WarningSkippedFrame, Frames: inputs[loop:last],
+				Message: fmt.Sprintf("cut the last %d of %d frames to loop before frame %d, the most like the first one", last-loop, last-first, inputs[loop])})
+			last = loop
+		}
+	}
+	if opts.FindLoop {
+		start, period, ok, err := findCycle(src, first, last)
 		if err != nil {
-			return fmt.Errorf("error decoding PNG file %s: %v", inputFile, err)
+			return nil, err
 		}
+		if !ok && last-first >= 4 {
+			warnings = append(warnings, Warning{Kind: WarningNoCycle,
+				Message: fmt.Sprintf("found no repeating cycle in the %d frames, keeping them all", last-first)})
+		}
+		if ok && period < last-first {
+			if opts.Debug {
+				fmt.Printf("Found a cycle of %d frames starting at frame %d\n", period, inputs[start])
+			}
+			skipped := append(slices.Clone(inputs[first:start]), inputs[start+period:last]...)
+			warnings = append(warnings, Warning{Kind: WarningSkippedFrame, Frames: skipped,
+				Message: fmt.Sprintf("skipped %d of %d frames to keep the one cycle of frames %d-%d", len(skipped), last-first, inputs[start], inputs[start+period-1])})
+			first, last = start, start+period
+		}
+	}
+	if opts.PreviewFrames > 0 && last-first > opts.PreviewFrames {
+		if opts.Debug {
+			fmt.Printf("Previewing the first %d of %d frames\n", opts.PreviewFrames, last-first)
+		}
+		last = first + opts.PreviewFrames
+	}
+	// Drop the frames outside of the requested time window
+	metas := frameMetas(src, positions, first, last)
+	names, delays := src.names[first:last], delays[first:last]
+
+	// Fill in the caption colors left unset
+	if opts.CaptionStyle.Foreground == nil {
+		opts.CaptionStyle.Foreground = overlay.DefaultCaptionStyle.Foreground
+	}
+	if opts.CaptionStyle.Background == nil {
+		opts.CaptionStyle.Background = overlay.DefaultCaptionStyle.Background
+	}
 
-		// If this is the first image, store its bounds
-		if i == 0 {
-			firstImgBounds = img.Bounds()
+	// Load subtitles before doing any work
+	var subtitles []overlay.Cue
+	if opts.Subtitles != "" {
+		if subtitles, err = overlay.LoadSRT(opts.Subtitles); err != nil {
+			return nil, err
 		}
+	}
 
-		// Resize image if dimensions don't match
-		if img.Bounds().Dx() != firstImgBounds.Dx() || img.Bounds().Dy() != firstImgBounds.Dy() {
-			resized := image.NewRGBA(firstImgBounds)
-			xdraw.CatmullRom.Scale(resized, resized.Bounds(), img, img.Bounds(), xdraw.Over, nil)
-			img = resized
+	// Load annotations before doing any work
+	var annotations []overlay.Annotation
+	if opts.Annotations != "" {
+		if annotations, err = overlay.LoadAnnotations(opts.Annotations); err != nil {
+			return nil, err
 		}
+	}
 
-		// Sample colors from the image
-		bounds := img.Bounds()
-		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-			for x := bounds.Min.X; x < bounds.Max.X; x++ {
-				colorMap[img.At(x, y)] = true
-			}
+	// Load recorded input events before doing any work
+	var events []overlay.Event
+	if opts.Events != "" {
+		if events, err = overlay.LoadEvents(opts.Events); err != nil {
+			return nil, err
 		}
 	}
 
-	// Convert color map to palette
-	for c := range colorMap {
-		palette = append(palette, c)
+	// Load the audio track before doing any work
+	var waveform *overlay.Waveform
+	if opts.Waveform != "" {
+		if waveform, err = overlay.LoadWAV(opts.Waveform); err != nil {
+			return nil, err
+		}
 	}
 
-	// Ensure we have at least one color in the palette
-	if len(palette) == 0 {
-		// Add basic colors if no colors were found
-		palette = []color.Color{
-			color.RGBA{0, 0, 0, 255},       // Black
-			color.RGBA{255, 255, 255, 255}, // White
+	// The stamp is the same on every frame
+	var stamp string
+	if opts.Stamp != "" {
+		if stamp, err = ExpandStamp(opts.Stamp, os.Environ()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Load the color grading before doing any work
+	var lut *colorLUT
+	if opts.LUT != "" {
+		if lut, err = loadCubeLUT(opts.LUT); err != nil {
+			return nil, err
 		}
 	}
 
-	// If we have too many colors, reduce the palette
-	if len(palette) > 256 {
-		// Sort colors by frequency
-		colorFreq := make(map[color.Color]int)
-		for _, inputFile := range inputFiles {
-			file, err := os.Open(inputFile)
+	// Fail fast when another conversion is writing the same output
+	lock, err := lockOutput(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.release()
+
+	trace, err := newTracer(opts.Trace, names)
+	if err != nil {
+		return nil, err
+	}
+	// Frames traced before a failure are still written
+	defer trace.close()
+
+	report := &Report{OutputFile: outputFile, Warnings: warnings}
+
+	// Segments pick their own settings where the user left them unset, even
+	// those picked by Auto for the whole sequence
+	unsetOpts := opts
+
+	// Pick the settings matching the content
+	if opts.Auto {
+		if opts, report.Auto, err = autoConfigure(src, first, last, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// Prepare the checkpoint, resuming the palette of an interrupted run
+	cp, resumedPalette, err := openCheckpoint(src, first, last, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Track the memory used from here on, spilling frames near the limit
+	mem := startMemoryMonitor(opts)
+	defer mem.Stop()
+
+	// Create a channel for progress updates
+	progressChan, uiDone := ui.StartUI(opts.Debug, len(names))
+	if opts.ProgressLog != "" {
+		progressChan = logProgress(progressChan, opts.ProgressLog)
+	}
+
+	// Estimate the camera motion and pick the window of interest, which set
+	// the area kept of every frame
+	reframe := func(i int, img image.Image) (image.Image, error) { return img, nil }
+	reframeSrc := src
+	if opts.Stabilize || opts.AlignOn != "" || opts.SmartCrop != (image.Point{}) || opts.Fit != (image.Point{}) {
+		// Offsets and windows are estimated on frames of the size of the first
+		// one, other frames being scaled to it first
+		img, err := src.load(first)
+		if err != nil {
+			return nil, err
+		}
+		bounds := img.Bounds()
+		reframe = func(i int, img image.Image) (image.Image, error) {
+			return resizeTo(img, bounds, opts.Scaler), nil
+		}
+		reframeSrc.load = reframedLoader(src, reframe)
+	}
+	var align *stabilizer
+	if opts.Stabilize {
+		if align, err = newStabilizer(reframeSrc, first, last); err != nil {
+			return nil, err
+		}
+	}
+	if opts.AlignOn != "" {
+		template, err := decodeFrame(opts.AlignOn, opts.InputLimits.decodeLimits())
+		if err != nil {
+			return nil, err
+		}
+		if align, err = newTemplateAligner(reframeSrc, first, last, template); err != nil {
+			return nil, err
+		}
+	}
+	if align != nil {
+		scale := reframe
+		reframe = func(i int, img image.Image) (image.Image, error) {
+			img, err := scale(i, img)
 			if err != nil {
-				return fmt.Errorf("error opening file %s: %v", inputFile, err)
+				return nil, err
 			}
-			defer file.Close()
+			return align.apply(i-first, img, src.names[i])
+		}
+	}
+	if opts.SmartCrop != (image.Point{}) {
+		if reframe, err = smartCropped(src, first, last, reframe, opts.SmartCrop, opts.Debug); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Fit != (image.Point{}) {
+		img, err := reframedLoader(src, reframe)(first)
+		if err != nil {
+			return nil, err
+		}
+		window := fitWindow(img.Bounds().Size(), opts.Fit)
+		if reframe, err = smartCropped(src, first, last, reframe, window, opts.Debug); err != nil {
+			return nil, err
+		}
+	}
 
-			img, err := png.Decode(file)
+	// The first image determines the output dimensions
+	progressChan <- ui.ProgressMsg{CurrentFile: names[0], Processed: 0, Total: len(names)}
+	loadStart := time.Now()
+	img, err := src.load(first)
+	trace.decoded(0, time.Since(loadStart))
+	if err != nil {
+		return nil, err
+	}
+	firstInputSize := img.Bounds().Size()
+	if img, err = reframe(first, img); err != nil {
+		return nil, err
+	}
+	var dpi float64
+	if src.density != nil {
+		if dpi, err = src.density(first); err != nil {
+			return nil, err
+		}
+	}
+	firstImgBounds := outputBounds(img.Bounds(), dpi, opts)
+	report.SourceDPI = dpi
+	report.EffectiveDPI = dpi * float64(firstImgBounds.Dx()) / float64(img.Bounds().Dx())
+	if opts.Debug && firstImgBounds.Size() != img.Bounds().Size() {
+		fmt.Printf("Scaling %dx%d input to %dx%d\n", img.Bounds().Dx(), img.Bounds().Dy(), firstImgBounds.Dx(), firstImgBounds.Dy())
+	}
+
+	// Frames of another size than the first one are scaled to its size,
+	// before reframing them when the frames are reframed
+	firstSize := img.Bounds().Size()
+	resized := make([]bool, len(names))
+
+	// The first frame is the brightness reference of the others
+	var normalize *normalizer
+	if opts.Normalize {
+		normalize = newNormalizer(resizeTo(img, firstImgBounds, opts.Scaler))
+	}
+
+	equalize := newEqualizer()
+	style := newStylizer(firstImgBounds, opts)
+
+	// Scale every frame to the dimensions of the first one and draw the overlays
+	lowContrast := make([]bool, len(names))
+	transform := func(meta FrameMeta, img image.Image) (image.Image, error) {
+		trace.resized(meta.Index-first, img.Bounds(), firstImgBounds, opts.Scaler)
+		resized[meta.Index-first] = resized[meta.Index-first] || img.Bounds().Size() != firstSize
+		img = resizeTo(img, firstImgBounds, opts.Scaler)
+
+		// Match the brightness of the first frame and grade the colors, before
+		// drawing the overlays
+		if normalize != nil {
+			img = normalize.apply(img)
+		}
+		if opts.Equalize {
+			img = equalize.apply(img)
+		}
+		if lut != nil {
+			img = lut.apply(img)
+		}
+		if style != nil {
+			img = style.apply(img)
+		}
+
+		var rgba *image.RGBA
+		drawable := func() *image.RGBA {
+			if rgba == nil {
+				rgba = toRGBA(img)
+			}
+			return rgba
+		}
+
+		// Show the sound around the frame's playback position, under the captions
+		if waveform != nil {
+			overlay.DrawWaveform(drawable(), waveform, meta.Timestamp)
+		}
+
+		// Burn the frame's caption, or the subtitle active at its playback position
+		text := meta.Caption
+		if text == "" {
+			text = overlay.TextAt(subtitles, meta.Timestamp)
+		}
+		if text != "" {
+			box, err := overlay.DrawCaption(drawable(), text, opts.CaptionStyle)
 			if err != nil {
-				return fmt.Errorf("error decoding PNG file %s: %v", inputFile, err)
+				return nil, err
 			}
+			lowContrast[meta.Index-first] = opts.A11yCheck && !captionContrastOK(img, box, opts.CaptionStyle)
+		}
 
-			bounds := img.Bounds()
-			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-				for x := bounds.Min.X; x < bounds.Max.X; x++ {
-					colorFreq[img.At(x, y)]++
+		// Draw the annotations, frame ranges refer to the untrimmed sequence
+		for _, annotation := range annotations {
+			if annotation.ShownOn(meta.Index) {
+				if err := annotation.Draw(drawable()); err != nil {
+					return nil, err
 				}
 			}
 		}
 
-		// Sort colors by frequency
-		type colorCount struct {
-			color color.Color
-			count int
+		// Label the frame with its metadata
+		if opts.FrameLabel != "" {
+			if text := meta.Label(opts.FrameLabel); text != "" {
+				size := overlay.CaptionSize(firstImgBounds)
+				margin := firstImgBounds.Min.Add(image.Pt(int(size/2), int(size/2)))
+				if _, err := overlay.DrawLabel(drawable(), text, margin.X, margin.Y, size, opts.CaptionStyle); err != nil {
+					return nil, err
+				}
+			}
 		}
-		var sortedColors []colorCount
-		for c, count := range colorFreq {
-			sortedColors = append(sortedColors, colorCount{c, count})
+
+		// Render the keystrokes and clicks happening at the frame's playback position
+		if len(events) > 0 {
+			if err := overlay.DrawEvents(drawable(), events, meta.Timestamp); err != nil {
+				return nil, err
+			}
 		}
-		sort.Slice(sortedColors, func(i, j int) bool {
-			return sortedColors[i].count > sortedColors[j].count
+
+		// Identify the build the frames come from
+		if stamp != "" {
+			if err := drawStamp(drawable(), stamp, firstImgBounds, opts.StampCorner, opts.CaptionStyle); err != nil {
+				return nil, err
+			}
+		}
+
+		if rgba != nil {
+			img = rgba
+		}
+		return mem.keep(img)
+	}
+
+	frames := make([]image.Image, len(names))
+	if frames[0], err = transform(metas[0], img); err != nil {
+		return nil, err
+	}
+
+	// Read, decode and transform the other frames in a pipeline, the queues
+	// between the stages bounding the frames in flight
+	workers := mem.workers(opts.Workers, 4*firstImgBounds.Dx()*firstImgBounds.Dy())
+	p := newPipeline(queueDepth(opts))
+	jobs := p.source(first+1, last)
+	if src.read != nil {
+		jobs = p.stage(jobs, max(1, opts.IOWorkers), func(j *job) (err error) {
+			start := time.Now()
+			j.data, err = src.read(j.index)
+			trace.decoded(j.index-first, time.Since(start))
+			return err
 		})
+	}
+	jobs = p.stage(jobs, workers, func(j *job) (err error) {
+		start := time.Now()
+		if j.data != nil {
+			j.img, err = src.decode(j.index, j.data)
+			j.data = nil
+		} else {
+			j.img, err = src.load(j.index)
+		}
+		trace.decoded(j.index-first, time.Since(start))
+		return err
+	})
+	jobs = p.stage(jobs, workers, func(j *job) (err error) {
+		resized[j.index-first] = j.img.Bounds().Size() != firstInputSize
+		if j.img, err = reframe(j.index, j.img); err != nil {
+			return err
+		}
+		j.img, err = transform(metas[j.index-first], j.img)
+		return err
+	})
+	for processed := 1; ; processed++ {
+		j, ok := <-jobs
+		if !ok {
+			break
+		}
+		progressChan <- ui.ProgressMsg{CurrentFile: names[j.index-first], Processed: processed, Total: len(names)}
+		frames[j.index-first] = j.img
+	}
+	if err := p.wait(); err != nil {
+		return nil, err
+	}
+
+	// Leave the trails of the previous frames, which needs them all transformed
+	if err := onionSkin(frames, opts.OnionSkin, mem.keep); err != nil {
+		return nil, err
+	}
+
+	// Fade the end of the loop into its start
+	if err := smoothLoop(frames, opts.SmoothLoop, mem.keep); err != nil {
+		return nil, err
+	}
+
+	// Zoom into where the activity is
+	if err := magnifyChanges(frames, opts.MagnifyChanges, opts.MagnifyCorner, mem.keep); err != nil {
+		return nil, err
+	}
 
-		// Take the most frequent colors
-		palette = make([]color.Color, 0, 256)
-		for i := 0; i < len(sortedColors) && i < 256; i++ {
-			palette = append(palette, sortedColors[i].color)
+	// Show what changed between frames instead of the frames themselves
+	if opts.VisualizeDiff {
+		if err := visualizeDiff(frames, mem.keep); err != nil {
+			return nil, err
 		}
 	}
 
-	if debug {
-		fmt.Printf("Generated palette with %d colors\n", len(palette))
+	// Let the embedding program draw its own overlays last
+	if opts.FrameHook != nil {
+		if err := runFrameHook(frames, metas, opts.FrameHook, mem.keep); err != nil {
+			return nil, err
+		}
+	}
+
+	// flagged returns the inputs of the frames whose flag is set
+	flagged := func(flags []bool) []int {
+		var frames []int
+		for i, flag := range flags {
+			if flag {
+				frames = append(frames, inputs[first+i])
+			}
+		}
+		return frames
+	}
+	if resizedFrames := flagged(resized); len(resizedFrames) > 0 {
+		report.warn(WarningResizedFrame, resizedFrames, "%d frame(s) are not %dx%d like the first one and were scaled to it, starting at frame %d",
+			len(resizedFrames), firstSize.X, firstSize.Y, resizedFrames[0])
+	}
+	if lowContrastFrames := flagged(lowContrast); len(lowContrastFrames) > 0 {
+		report.warn(WarningLowContrast, lowContrastFrames, "subtitle contrast is below the WCAG AA threshold of %.1f:1 on %d frame(s), starting at frame %d",
+			a11y.MinContrast(overlay.CaptionSize(firstImgBounds)), len(lowContrastFrames), lowContrastFrames[0])
 	}
 
-	// Process each image again with the final palette
-	for _, inputFile := range inputFiles {
-		file, err := os.Open(inputFile)
+	// Write color vision deficiency previews of a few sample frames
+	if opts.A11yCheck {
+		previews, err := writeA11yPreviews(frames, outputFile)
 		if err != nil {
-			return fmt.Errorf("error opening file %s: %v", inputFile, err)
+			return nil, err
 		}
-		defer file.Close()
+		report.A11yPreviews = previews
+	}
 
-		img, err := png.Decode(file)
+	// Draw the guides over the first frame of a preview to check the
+	// composition before the full run
+	if len(opts.Guides) > 0 && opts.PreviewFrames > 0 {
+		guides, err := writeGuides(loaded(frames[0]), opts.Guides, outputFile)
 		if err != nil {
-			return fmt.Errorf("error decoding PNG file %s: %v", inputFile, err)
+			return nil, err
 		}
+		report.Guides = guides
+	}
 
-		// Resize image if dimensions don't match
-		if img.Bounds().Dx() != firstImgBounds.Dx() || img.Bounds().Dy() != firstImgBounds.Dy() {
-			resized := image.NewRGBA(firstImgBounds)
-			xdraw.CatmullRom.Scale(resized, resized.Bounds(), img, img.Bounds(), xdraw.Over, nil)
-			img = resized
+	// Video and flipbook formats keep every color and are encoded while the
+	// GIF is, sharing the transformed frames
+	formats := outputFormats(opts)
+	formatPath := func(format string) (string, error) {
+		path := outputFile
+		if len(formats) > 1 {
+			path = FormatPath(outputFile, format)
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("error getting absolute path: %v", err)
 		}
+		return absPath, nil
+	}
+	var videos []Output
+	for _, format := range formats {
+		if !isGIF(format) {
+			path, err := formatPath(format)
+			if err != nil {
+				return nil, err
+			}
+			videos = append(videos, Output{File: path, Width: firstImgBounds.Dx(), Height: firstImgBounds.Dy(), Format: format})
+		}
+	}
+	var videoWG sync.WaitGroup
+	videoErrs := make([]error, len(videos))
+	defer videoWG.Wait()
+	for i, video := range videos {
+		videoWG.Add(1)
+		go func() {
+			defer videoWG.Done()
+			videoErrs[i] = encodeOutput(video.File, video.Format, frames, delays, opts)
+		}()
+	}
+	waitVideos := func() error {
+		videoWG.Wait()
+		return errors.Join(videoErrs...)
+	}
 
-		// Create a paletted image with our color palette
-		paletted := image.NewPaletted(img.Bounds(), palette)
-		xdraw.Draw(paletted, paletted.Bounds(), img, img.Bounds().Min, xdraw.Src)
+	if !slices.Contains(formats, FormatGIF) {
+		progressChan <- ui.ProgressMsg{
+			CurrentFile: "Creating output " + strings.ToUpper(videos[0].Format),
+			Processed:   len(names),
+			Total:       len(names),
+			OutputFile:  videos[0].File,
+		}
+		<-uiDone
+		if err := waitVideos(); err != nil {
+			return nil, err
+		}
+		if err := mem.err(); err != nil {
+			return nil, err
+		}
+		if cp != nil {
+			if err := cp.clear(); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := trace.close(); err != nil {
+			return nil, err
+		}
 
-		images = append(images, paletted)
+		if report.Chapters, err = outputChapters(opts.Chapters, inputs[first:last], 0, ""); err != nil {
+			return nil, err
+		}
+		report.OutputFile = videos[0].File
+		report.Outputs = videos
+		report.Frames = len(frames)
+		report.Width = firstImgBounds.Dx()
+		report.Height = firstImgBounds.Dy()
+		mem.finish(report)
+		return report, nil
 	}
 
-	// Create the output GIF
-	outGif := &gif.GIF{
-		Image: images,
-		Delay: make([]int, len(images)),
+	// Apps showing only the first frame should show a meaningful one
+	if opts.AutoPoster && opts.PosterFrame == 0 {
+		if opts.PosterFrame = choosePoster(frames); opts.PosterFrame > 0 {
+			report.warn(WarningPosterFrame, []int{inputs[first+opts.PosterFrame]},
+				"the first frame has little detail, showing frame %d first for apps that only show one frame", opts.PosterFrame)
+		}
+	}
+	if report.Chapters, err = outputChapters(opts.Chapters, inputs[first:last], opts.PosterFrame, opts.PosterMode); err != nil {
+		return nil, err
 	}
 
-	// Set the same delay for all frames
-	for i := range outGif.Delay {
-		outGif.Delay[i] = delay / 10 // Convert to 100ths of a second
+	// Frames using few enough colors are indexed as is, skipping the quantization
+	var exact *colorIndex
+	if len(opts.Palette) == 0 && resumedPalette == nil {
+		exact, _ = exactPalette(frames, paletteSize(opts))
 	}
 
-	// Create the output file
-	outFile, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("error creating output file: %v", err)
+	// Warn about inputs with far more colors than a GIF can show
+	if exact != nil {
+		report.SourceColors = len(exact.palette)
+	} else if len(opts.Palette) == 0 {
+		report.SourceColors = countSourceColors(frames)
+		if advice := colorAdvice(report.SourceColors, opts); advice != "" {
+			report.warn(WarningPaletteTruncated, nil, "%s", advice)
+		}
+	}
+
+	// Runs of slides and photos are quantized with their own settings
+	segmented := opts.Segments && exact == nil && len(opts.Palette) == 0 && len(opts.ROIs) == 0
+
+	// Find the areas whose colors must be kept exact, only needed by the
+	// quantization and resized outputs, per segment when segmented
+	var regions [][]image.Rectangle
+	if !segmented && (exact == nil || len(opts.Sizes) > 0) {
+		regions = findTextRegions(frames, opts)
+	}
+
+	// Use the provided, resumed or exact palette, or build one from the frames
+	palette := opts.Palette
+	if resumedPalette != nil {
+		palette = resumedPalette
+	}
+	if exact != nil {
+		palette = exact.palette
+		if opts.Debug {
+			fmt.Printf("Frames use %d colors, skipping quantization\n", len(palette))
+		}
+	}
+	// Quantize every run of slides or photos with its own settings and palette
+	var segments []frameSegment
+	segmentOf := make([]int, len(frames))
+	if segmented {
+		base := opts
+		base.Quantizer, base.Dither, base.Colors = unsetOpts.Quantizer, unsetOpts.Dither, unsetOpts.Colors
+		base.PreserveText = unsetOpts.PreserveText
+		segments = detectSegments(frames, base)
+		for s, segment := range segments {
+			for i := segment.first; i < segment.last; i++ {
+				segmentOf[i] = s
+			}
+			report.Segments = append(report.Segments, segment.report(inputs[first:last]))
+		}
+		// The checkpoint records the palette of the first segment
+		if resumedPalette == nil {
+			palette = segments[0].palette
+		}
+	}
+	// Quantize every region of interest with a palette built from its own area
+	var roiPalettes []color.Palette
+	for _, roi := range opts.ROIs {
+		if !roi.Rect.Add(firstImgBounds.Min).In(firstImgBounds) {
+			return nil, fmt.Errorf("region of interest %q %v is outside the %dx%d frames", roi.Name, roi.Rect, firstImgBounds.Dx(), firstImgBounds.Dy())
+		}
+		if exact != nil || len(opts.Palette) > 0 {
+			continue
+		}
+		cropped, err := cropFrames(frames, roi)
+		if err != nil {
+			return nil, err
+		}
+		var roiRegions [][]image.Rectangle
+		if regions != nil {
+			roiRegions = make([][]image.Rectangle, len(regions))
+			for i := range regions {
+				roiRegions[i] = cropRegions(regions[i], roi.Rect)
+			}
+		}
+		roiPalettes = append(roiPalettes, generatePalette(cropped, roiRegions, opts))
+		if opts.Debug {
+			fmt.Printf("Generated palette with %d colors for region %s\n", len(roiPalettes[len(roiPalettes)-1]), roi.Name)
+		}
+	}
+	// The checkpoint records the palette of the first region
+	if roiPalettes != nil && resumedPalette == nil {
+		palette = roiPalettes[0]
+	}
+	if len(palette) == 0 {
+		palette = generatePalette(frames, regions, opts)
+		if opts.Debug {
+			fmt.Printf("Generated palette with %d colors\n", len(palette))
+		}
+	}
+	if cp != nil && resumedPalette == nil {
+		if err := cp.savePalette(palette); err != nil {
+			return nil, err
+		}
 	}
-	defer outFile.Close()
 
-	// Get absolute path for the output file
-	absOutputPath, err := filepath.Abs(outputFile)
+	// Resolve the output files, one per width when Sizes is set
+	gifPath, err := formatPath(FormatGIF)
 	if err != nil {
-		return fmt.Errorf("error getting absolute path: %v", err)
+		return nil, err
+	}
+	outputs := []Output{{File: gifPath, Width: firstImgBounds.Dx(), Height: firstImgBounds.Dy(), Format: FormatGIF}}
+	if len(opts.Sizes) > 0 {
+		outputs = sizedOutputs(outputFile, firstImgBounds, opts.Sizes)
+	}
+	if len(opts.ROIs) > 0 {
+		outputs = roiOutputs(outputFile, opts.ROIs)
+	}
+	for i := range outputs {
+		// Get absolute path for the output file
+		absOutputPath, err := filepath.Abs(outputs[i].File)
+		if err != nil {
+			return nil, fmt.Errorf("error getting absolute path: %v", err)
+		}
+		outputs[i].File = absOutputPath
 	}
 
 	// Update progress for final step
 	progressChan <- ui.ProgressMsg{
 		CurrentFile: "Creating output GIF",
-		Processed:   len(inputFiles),
-		Total:       len(inputFiles),
-		OutputFile:  absOutputPath,
-	}
-
-	// Encode the GIF
-	if err := gif.EncodeAll(outFile, outGif); err != nil {
-		return fmt.Errorf("error encoding GIF: %v", err)
+		Processed:   len(names),
+		Total:       len(names),
+		OutputFile:  outputs[0].File,
 	}
+	<-uiDone
 
-	return nil
-}
-
-// ExpandInputPattern expands a glob pattern or regex into a list of matching PNG files
-func ExpandInputPattern(pattern string) ([]string, error) {
-	// Get the directory and base pattern
-	dir := "."
-	basePattern := pattern
-	if strings.Contains(pattern, "/") {
-		dir = filepath.Dir(pattern)
-		basePattern = filepath.Base(pattern)
-	}
+	// Encode every output with the shared palette
+	var images []*image.Paletted
+	var outDelays []int
+	for o := range outputs {
+		output := &outputs[o]
+		size := image.Rect(0, 0, output.Width, output.Height)
+		var roi *ROI
+		if len(opts.ROIs) > 0 {
+			roi = &opts.ROIs[o]
+		}
 
-	// Ensure the directory exists
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("directory does not exist: %s", dir)
-	}
+		// Work on fewer frames at once when the memory left is short
+		encodeOpts := opts
+		encodeOpts.Workers = mem.workers(opts.Workers, 2*frameBytes(size))
 
-	var matches []string
+		// Convert every frame to a paletted image with the final palette
+		images = make([]*image.Paletted, len(frames))
+		var resumed atomic.Int32
+		err = parallel(len(frames), encodeOpts.Workers, func(i int) error {
+			if cp != nil {
+				if quantized, ok := cp.frame(o, i); ok {
+					images[i] = quantized
+					resumed.Add(1)
+					return nil
+				}
+			}
 
-	// Try glob pattern first
-	globMatches, err := filepath.Glob(filepath.Join(dir, basePattern))
-	if err == nil && len(globMatches) > 0 {
-		// Filter for PNG files
-		for _, match := range globMatches {
-			if strings.HasSuffix(strings.ToLower(match), ".png") {
-				matches = append(matches, match)
+			unquantized := frames[i]
+			switch {
+			case roi != nil:
+				cropped, err := cropImage(loaded(frames[i]), roi.Rect, roi.Name)
+				if err != nil {
+					return err
+				}
+				unquantized = cropped
+				if exact != nil {
+					images[i] = exact.index(cropped)
+					break
+				}
+				framePalette, frameRegions := palette, []image.Rectangle(nil)
+				if roiPalettes != nil {
+					framePalette = roiPalettes[o]
+				}
+				if regions != nil {
+					frameRegions = cropRegions(regions[i], roi.Rect)
+				}
+				images[i] = quantizeFrame(cropped, framePalette, frameRegions, opts)
+			case exact != nil && size.Size() == firstImgBounds.Size():
+				images[i] = exact.index(frames[i])
+			default:
+				framePalette, frameOpts, frameRegions := palette, opts, []image.Rectangle(nil)
+				if regions != nil {
+					frameRegions = scaleRegions(regions[i], firstImgBounds, size)
+				}
+				if segments != nil {
+					segment := segments[segmentOf[i]]
+					framePalette, frameOpts = segment.palette, segment.opts
+					if segment.regions != nil {
+						frameRegions = scaleRegions(segment.regions[i-segment.first], firstImgBounds, size)
+					}
+				}
+				unquantized = resizeTo(loaded(frames[i]), size, opts.Scaler)
+				images[i] = quantizeFrame(unquantized, framePalette, frameRegions, frameOpts)
+			}
+			if o == 0 {
+				trace.quantized(i, unquantized, images[i])
 			}
+			if cp != nil {
+				return cp.saveFrame(o, i, images[i])
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-		if len(matches) > 0 {
-			sort.Strings(matches)
-			return matches, nil
+		// Frames lost on disk would be encoded as transparent
+		if err := mem.err(); err != nil {
+			return nil, err
 		}
-	}
+		report.ResumedFrames += int(resumed.Load())
 
-	// If glob pattern didn't work, try regex
-	if strings.HasPrefix(basePattern, "^") || strings.ContainsAny(basePattern, ".*+?[](){}|") {
-		re, err := regexp.Compile(basePattern)
+		// Move the poster frame to the front
+		images, outDelays, err = applyPosterFrame(images, delays, opts.PosterFrame, opts.PosterMode)
 		if err != nil {
-			return nil, fmt.Errorf("invalid regex pattern: %v", err)
+			return nil, err
 		}
 
-		// Read all files in the directory
-		files, err := os.ReadDir(dir)
-		if err != nil {
-			return nil, fmt.Errorf("error reading directory: %v", err)
+		// Write the static preview from the unquantized frame
+		if opts.WithPoster {
+			poster := resizeTo(frames[opts.PosterFrame], size, opts.Scaler)
+			if roi != nil {
+				if poster, err = cropImage(loaded(frames[opts.PosterFrame]), roi.Rect, roi.Name); err != nil {
+					return nil, err
+				}
+			}
+			output.Poster = PosterPath(output.File)
+			if err := writePoster(output.Poster, poster); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writeGIF(output.File, &gif.GIF{Image: images, Delay: outDelays, LoopCount: loopCount(opts.Loops)}, encodeOpts); err != nil {
+			return nil, err
+		}
+		if opts.Verify {
+			if err := verifyGIF(output.File, images, outDelays, opts); err != nil {
+				return nil, err
+			}
+		}
+		if o == 0 {
+			if err := trace.encoded(output.File, opts); err != nil {
+				return nil, err
+			}
+			if opts.Manifest != "" {
+				if err := writeGIFManifest(opts.Manifest, outDelays, report.Chapters); err != nil {
+					return nil, err
+				}
+			}
 		}
 
-		for _, file := range files {
-			if !file.IsDir() && strings.HasSuffix(strings.ToLower(file.Name()), ".png") {
-				if re.MatchString(file.Name()) {
-					matches = append(matches, filepath.Join(dir, file.Name()))
+		// Compare what players show to the frames before quantization
+		if scored(opts) && o == 0 {
+			reference := frames
+			if roi != nil {
+				if reference, err = cropFrames(frames, *roi); err != nil {
+					return nil, err
 				}
 			}
+			reference, _, err := applyPosterFrame(reference, delays, opts.PosterFrame, opts.PosterMode)
+			if err != nil {
+				return nil, err
+			}
+			if report.SSIM, report.PSNR, err = scoreGIF(output.File, reference, size, opts.Scaler); err != nil {
+				return nil, err
+			}
+		}
+
+		// Write the quantized frames as they look in the proof gamut
+		if opts.Proof != "" {
+			proofs, clipped := proofImages(images, sourceGamut(opts), gamut.Gamut(opts.Proof))
+			output.Proof = ProofPath(output.File, opts.Proof)
+			if err := writeGIF(output.Proof, &gif.GIF{Image: proofs, Delay: outDelays, LoopCount: loopCount(opts.Loops)}, encodeOpts); err != nil {
+				return nil, err
+			}
+			if clipped > 0 && o == 0 {
+				report.warn(WarningGamutClipped, nil, "%.1f%% of the pixels are outside the %s gamut, %s shows them clipped",
+					clipped*100, opts.Proof, output.Proof)
+			}
 		}
-		if len(matches) > 0 {
-			sort.Strings(matches)
-			return matches, nil
+	}
+
+	// Write the embed snippet referencing every output
+	if opts.EmitSnippet != "" {
+		absOutputPath, err := filepath.Abs(outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("error getting absolute path: %v", err)
+		}
+		if report.Snippet, err = writeSnippet(absOutputPath, outputs, opts.EmitSnippet); err != nil {
+			return nil, err
+		}
+	}
+
+	// The checkpoint is no longer needed once every output is written
+	if cp != nil {
+		if err := cp.clear(); err != nil {
+			return nil, err
 		}
 	}
 
-	// If no matches found, read the directory manually for simple patterns
-	files, err := os.ReadDir(dir)
+	if len(images) > 1 && !gifExtensions(opts)[ExtensionControl] {
+		report.warn(WarningNoDelays, nil, "frame delays are not stored without the control extension, players choose their own speed")
+	}
+
+	if err := waitVideos(); err != nil {
+		return nil, err
+	}
+	if err := mem.err(); err != nil {
+		return nil, err
+	}
+
+	if err := trace.close(); err != nil {
+		return nil, err
+	}
+
+	report.OutputFile = outputs[0].File
+	report.Outputs = append(outputs, videos...)
+	report.Frames = len(images)
+	report.Width = outputs[0].Width
+	report.Height = outputs[0].Height
+	mem.finish(report)
+
+	return report, nil
+}
+
+// writeGIF encodes g to a new file at path with the GIF version of opts
+func writeGIF(path string, g *gif.GIF, opts Options) error {
+	// Create the output file
+	outFile, err := os.Create(path)
 	if err != nil {
-		return nil, fmt.Errorf("error reading directory: %v", err)
+		return fmt.Errorf("error creating output file: %v", err)
 	}
+	defer outFile.Close()
 
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(strings.ToLower(file.Name()), ".png") {
-			// For *.png pattern, match all PNG files
-			if basePattern == "*.png" {
-				matches = append(matches, filepath.Join(dir, file.Name()))
-				continue
-			}
+	// Encode the GIF
+	if err := encodeGIF(outFile, g, opts); err != nil {
+		return fmt.Errorf("error encoding GIF: %v", err)
+	}
+	return outFile.Close()
+}
 
-			// For other patterns, try to match the filename
-			matched, err := filepath.Match(basePattern, file.Name())
-			if err == nil && matched {
-				matches = append(matches, filepath.Join(dir, file.Name()))
-			}
+// runFrameHook calls hook on a drawable copy of every frame, which replaces it
+func runFrameHook(frames []image.Image, metas []FrameMeta, hook func(int, draw.Image) error, keep func(image.Image) (image.Image, error)) error {
+	for i := range frames {
+		img := toRGBA(loaded(frames[i]))
+		if err := hook(metas[i].Index, img); err != nil {
+			return fmt.Errorf("frame hook failed on frame %d: %v", metas[i].Index, err)
+		}
+		var err error
+		if frames[i], err = keep(img); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if len(matches) == 0 {
-		return nil, fmt.Errorf("no PNG files found matching pattern: %s", pattern)
+// decodeFrame opens and decodes a frame file within limits
+func decodeFrame(inputFile string, limits decode.Limits) (image.Image, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %v", inputFile, err)
 	}
+	defer file.Close()
 
-	// Sort matches for consistent ordering
-	sort.Strings(matches)
-	return matches, nil
+	format, err := frameFormatOf(inputFile, file)
+	if err != nil {
+		return nil, err
+	}
+	img, err := format.decode(file, limits)
+	if err != nil {
+		return nil, decodeError(inputFile, format, err)
+	}
+	return img, nil
 }
 
-// ValidateInputFiles checks if all input files exist and are PNGs
+// decodeFrameData decodes the content of a frame file read beforehand
+// within limits
+func decodeFrameData(inputFile string, data []byte, limits decode.Limits) (image.Image, error) {
+	format, err := sniffFrameFormat(inputFile, data)
+	if err != nil {
+		return nil, err
+	}
+	img, err := format.decode(bytes.NewReader(data), limits)
+	if err != nil {
+		return nil, decodeError(inputFile, format, err)
+	}
+	return img, nil
+}
+
+// resizeTo scales img to the given bounds with the given scaler, returning it untouched if the dimensions already match
+func resizeTo(img image.Image, bounds image.Rectangle, scaler string) image.Image {
+	if img.Bounds().Dx() == bounds.Dx() && img.Bounds().Dy() == bounds.Dy() {
+		return img
+	}
+	var interpolator xdraw.Interpolator = xdraw.CatmullRom
+	if scaler == ScalerNearest {
+		interpolator = xdraw.NearestNeighbor
+	}
+	resized := image.NewRGBA(bounds)
+	interpolator.Scale(resized, resized.Bounds(), img, img.Bounds(), xdraw.Over, nil)
+	return resized
+}
+
+// toRGBA returns a drawable copy of img
+func toRGBA(img image.Image) *image.RGBA {
+	rgba := image.NewRGBA(img.Bounds())
+	xdraw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, xdraw.Src)
+	return rgba
+}
+
+// ValidateInputFiles checks if all input files exist and are PNG, WebP, BMP
+// or TIFF images whose header decodes, whatever their extension. The error
+// lists every file failing to decode.
 func ValidateInputFiles(inputFiles []string) error {
 	if len(inputFiles) == 0 {
 		return fmt.Errorf("no input files specified")
 	}
 
+	var failed []string
 	for _, file := range inputFiles {
 		if _, err := os.Stat(file); os.IsNotExist(err) {
 			return err
 		}
-		if !strings.HasSuffix(strings.ToLower(file), ".png") {
-			return fmt.Errorf("file %s is not a PNG", file)
+		if _, err := DecodeInputConfig(file); err != nil {
+			failed = append(failed, err.Error())
 		}
 	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d input files cannot be decoded: %s", len(failed), len(inputFiles), strings.Join(failed, "; "))
+	}
 	return nil
 }
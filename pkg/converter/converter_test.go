@@ -1,13 +1,22 @@
 package converter
 
 import (
+	"bytes"
+	"errors"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/gif"
 	"image/png"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
 )
 
 func TestExpandInputPattern(t *testing.T) {
@@ -26,6 +35,9 @@ func TestExpandInputPattern(t *testing.T) {
 		"frame1.png",
 		"frame2.png",
 		"frame3.png",
+		"frame4.webp",
+		"frame5.bmp",
+		"frame6.tiff",
 	}
 
 	for _, file := range testFiles {
@@ -57,6 +69,27 @@ func TestExpandInputPattern(t *testing.T) {
 			want:    2,
 			wantErr: false,
 		},
+		{
+			name:    "Glob pattern all WebPs",
+			pattern: "*.webp",
+			dir:     tempDir,
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:    "Glob pattern all TIFFs",
+			pattern: "*.tiff",
+			dir:     tempDir,
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:    "Glob pattern all frames",
+			pattern: "frame*",
+			dir:     tempDir,
+			want:    6,
+			wantErr: false,
+		},
 		{
 			name:    "Regex pattern frames",
 			pattern: "frame[0-9]+\\.png",
@@ -105,15 +138,37 @@ func TestValidateInputFiles(t *testing.T) {
 
 	// Create test files
 	validPNG := filepath.Join(tempDir, "valid.png")
+	validWebP := filepath.Join(tempDir, "valid.webp")
+	validTIFF := filepath.Join(tempDir, "valid.TIF")
+	misnamed := filepath.Join(tempDir, "screenshot.jpg")
+	corrupt := filepath.Join(tempDir, "corrupt.png")
+	truncated := filepath.Join(tempDir, "truncated.png")
 	invalidExt := filepath.Join(tempDir, "invalid.txt")
 	nonexistent := filepath.Join(tempDir, "nonexistent.png")
 
-	// Create a valid PNG file
-	f, err := os.Create(validPNG)
-	if err != nil {
-		t.Fatalf("Failed to create test file: %v", err)
+	// Create valid PNG, WebP and TIFF files, a PNG with another extension and
+	// files with a wrong or truncated content
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var pngData, tiffData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	if err := tiff.Encode(&tiffData, img, nil); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	for file, data := range map[string][]byte{
+		validPNG:   pngData.Bytes(),
+		validWebP:  redWebP,
+		validTIFF:  tiffData.Bytes(),
+		misnamed:   pngData.Bytes(),
+		corrupt:    []byte("not a png"),
+		truncated:  pngData.Bytes()[:12],
+		invalidExt: []byte("notes"),
+	} {
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
 	}
-	f.Close()
 
 	tests := []struct {
 		name    string
@@ -126,10 +181,30 @@ func TestValidateInputFiles(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name:    "Invalid extension",
+			name:    "Valid PNG, WebP and TIFF files",
+			files:   []string{validPNG, validWebP, validTIFF},
+			wantErr: false,
+		},
+		{
+			name:    "PNG with another extension",
+			files:   []string{misnamed},
+			wantErr: false,
+		},
+		{
+			name:    "Not an image",
 			files:   []string{invalidExt},
 			wantErr: true,
 		},
+		{
+			name:    "Corrupt PNG",
+			files:   []string{corrupt},
+			wantErr: true,
+		},
+		{
+			name:    "Truncated PNG",
+			files:   []string{truncated},
+			wantErr: true,
+		},
 		{
 			name:    "Nonexistent file",
 			files:   []string{nonexistent},
@@ -155,6 +230,13 @@ func TestValidateInputFiles(t *testing.T) {
 			}
 		})
 	}
+
+	// Every file failing to decode is listed
+	err = ValidateInputFiles([]string{corrupt, validPNG, truncated})
+	if err == nil || !strings.HasPrefix(err.Error(), "2 of 3 input files cannot be decoded") ||
+		!strings.Contains(err.Error(), "corrupt.png is not a PNG") || !strings.Contains(err.Error(), "error decoding PNG file "+truncated) {
+		t.Errorf("ValidateInputFiles() error = %v, want both invalid files listed", err)
+	}
 }
 
 func TestConvertPNGsToGIF(t *testing.T) {
@@ -310,6 +392,71 @@ func TestConvertPNGsToGIF(t *testing.T) {
 	}
 }
 
+// redWebP is a lossless 8x4 red WebP image
+var redWebP = []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f\x07\xc0\x00\x10\x28\x40\xff\x0b\xd0\xff\x02\x00\x00")
+
+func TestConvertFrameFormats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A WebP frame followed by blue PNG, BMP and TIFF frames of the same
+	// size, their 128 pixels read from the headers of every format
+	inputFiles := []string{filepath.Join(tempDir, "frame1.webp")}
+	if err := os.WriteFile(inputFiles[0], redWebP, 0644); err != nil {
+		t.Fatalf("Failed to write WebP frame: %v", err)
+	}
+	blue := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for i := 0; i < len(blue.Pix); i += 4 {
+		blue.Pix[i+2], blue.Pix[i+3] = 255, 255
+	}
+	encoders := []struct {
+		name   string
+		encode func(io.Writer, image.Image) error
+	}{
+		{"frame2.png", png.Encode},
+		{"frame3.BMP", bmp.Encode},
+		{"frame4.tif", func(w io.Writer, img image.Image) error { return tiff.Encode(w, img, nil) }},
+	}
+	for _, e := range encoders {
+		var buf bytes.Buffer
+		if err := e.encode(&buf, blue); err != nil {
+			t.Fatalf("Failed to encode %s: %v", e.name, err)
+		}
+		file := filepath.Join(tempDir, e.name)
+		if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", e.name, err)
+		}
+		inputFiles = append(inputFiles, file)
+	}
+
+	output := filepath.Join(tempDir, "output.gif")
+	if _, err := Convert(inputFiles, output, Options{Delay: 100, InputLimits: InputLimits{MaxPixels: 128}}); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output GIF: %v", err)
+	}
+	defer f.Close()
+	g, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("Failed to decode output GIF: %v", err)
+	}
+	if len(g.Image) != len(inputFiles) {
+		t.Fatalf("got %d frames, want %d", len(g.Image), len(inputFiles))
+	}
+	if b := g.Image[0].Bounds(); b.Dx() != 8 || b.Dy() != 4 {
+		t.Errorf("first frame is %dx%d, want 8x4", b.Dx(), b.Dy())
+	}
+	if r, g, b, _ := g.Image[0].At(0, 0).RGBA(); r>>8 < 200 || g>>8 > 50 || b>>8 > 50 {
+		t.Errorf("first frame pixel = (%d, %d, %d), want red", r>>8, g>>8, b>>8)
+	}
+}
+
 func TestPaletteGeneration(t *testing.T) {
 	// Create a test image with specific colors
 	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
@@ -397,3 +544,59 @@ func TestPaletteGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestFrameHook(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		for p := 3; p < len(img.Pix); p += 4 {
+			img.Pix[p] = 255
+		}
+		frames = append(frames, Frame{Image: img})
+	}
+	outputFile := filepath.Join(tempDir, "hooked.gif")
+
+	// The hook marks the top left pixel of every frame
+	var indices []int
+	hook := func(index int, img draw.Image) error {
+		indices = append(indices, index)
+		img.Set(0, 0, color.White)
+		return nil
+	}
+	if _, err := ConvertFrames(frames, outputFile, Options{Delay: 10, FrameHook: hook}); err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if !slices.Equal(indices, []int{0, 1, 2}) {
+		t.Errorf("hook called on frames %v, want [0 1 2]", indices)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output GIF: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output GIF: %v", err)
+	}
+	if r, _, _, _ := g.Image[0].At(0, 0).RGBA(); r>>8 != 255 {
+		t.Errorf("hooked pixel = %v, want white", g.Image[0].At(0, 0))
+	}
+
+	failing := func(index int, img draw.Image) error {
+		if index == 1 {
+			return errors.New("out of ink")
+		}
+		return nil
+	}
+	_, err = ConvertFrames(frames, outputFile, Options{Delay: 10, FrameHook: failing})
+	if err == nil || !strings.Contains(err.Error(), "frame hook failed on frame 1: out of ink") {
+		t.Errorf("ConvertFrames() error = %v, want the hook error", err)
+	}
+}
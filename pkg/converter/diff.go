@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+)
+
+// diffBackground is the share of the luma of a frame kept under its
+// difference heatmap, showing where the changes are
+const diffBackground = 0.25
+
+// visualizeDiff replaces every frame with a heatmap of the pixels that
+// changed since the previous frame over a dimmed copy of the frame, the
+// first frame showing no change
+func visualizeDiff(frames []image.Image, keep func(image.Image) (image.Image, error)) error {
+	// Going backwards leaves the previous frames untouched when they are compared
+	for i := len(frames) - 1; i >= 0; i-- {
+		current := toRGBA(loaded(frames[i]))
+		previous := current
+		if i > 0 {
+			previous = toRGBA(loaded(frames[i-1]))
+		}
+
+		bounds := current.Bounds()
+		heatmap := image.NewRGBA(bounds)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c, p := current.RGBAAt(x, y), previous.RGBAAt(x, y)
+				change := max(absDiff(c.R, p.R), absDiff(c.G, p.G), absDiff(c.B, p.B), absDiff(c.A, p.A))
+				if change == 0 {
+					l := uint8(diffBackground * float64(luma(c.R, c.G, c.B)))
+					heatmap.SetRGBA(x, y, color.RGBA{l, l, l, 255})
+				} else {
+					heatmap.SetRGBA(x, y, heatColor(change))
+				}
+			}
+		}
+
+		var err error
+		if frames[i], err = keep(heatmap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heatColor maps a change of a channel to a color going from dark red for
+// the slightest changes through yellow to white
+func heatColor(change uint8) color.RGBA {
+	// Even a change of 1 stands out from the dimmed background
+	t := 0.25 + 0.75*float64(change)/255
+	channel := func(v float64) uint8 { return uint8(255 * max(0, min(1, v))) }
+	return color.RGBA{channel(3 * t), channel(3*t - 1), channel(3*t - 2), 255}
+}
@@ -0,0 +1,71 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVisualizeDiff(t *testing.T) {
+	// Two screenshots differing in a single pixel by one level
+	first := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range first.Pix {
+		first.Pix[i] = 200
+	}
+	second := image.NewRGBA(first.Bounds())
+	copy(second.Pix, first.Pix)
+	second.SetRGBA(2, 1, color.RGBA{200, 201, 200, 200})
+
+	frames := []image.Image{first, second}
+	keep := func(img image.Image) (image.Image, error) { return img, nil }
+	if err := visualizeDiff(frames, keep); err != nil {
+		t.Fatalf("visualizeDiff() error = %v", err)
+	}
+
+	dimmed := color.RGBA{50, 50, 50, 255}
+	tests := []struct {
+		name  string
+		frame int
+		at    image.Point
+		want  color.RGBA
+	}{
+		{name: "first frame shows no change", frame: 0, at: image.Pt(2, 1), want: dimmed},
+		{name: "unchanged pixel is dimmed", frame: 1, at: image.Pt(0, 0), want: dimmed},
+		{name: "changed pixel stands out", frame: 1, at: image.Pt(2, 1), want: heatColor(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frames[tt.frame].(*image.RGBA).RGBAAt(tt.at.X, tt.at.Y); got != tt.want {
+				t.Errorf("pixel %v = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+
+	if c := heatColor(1); c.R < 150 || c.G != 0 {
+		t.Errorf("heatColor(1) = %v, want a visible red", c)
+	}
+	if c := heatColor(255); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("heatColor(255) = %v, want white", c)
+	}
+}
+
+func TestConvertVisualizeDiff(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 16, 8)
+	outputFile := filepath.Join(tempDir, "diff.gif")
+	report, err := Convert(inputFiles, outputFile, Options{VisualizeDiff: true})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.Frames != 3 {
+		t.Errorf("frames = %d, want 3", report.Frames)
+	}
+}
@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// BaseDPI is the density of a logical (1x) pixel
+const BaseDPI = 72.0
+
+// pngSignature is the 8 byte header every PNG file starts with
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// readDensity returns the horizontal density declared in the pHYs chunk of a
+// PNG file, or 0 if the file does not declare a physical density or is of
+// another format
+func readDensity(inputFile string) (float64, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file %s: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	format, err := frameFormatOf(inputFile, file)
+	if err != nil {
+		return 0, err
+	}
+	if format.name != "PNG" {
+		return 0, nil
+	}
+	dpi, err := parsePNGDensity(file)
+	if err != nil {
+		return 0, fmt.Errorf("error reading density of %s: %v", inputFile, err)
+	}
+	return dpi, nil
+}
+
+// parsePNGDensity walks the PNG chunks until it finds pHYs or the image data starts
+func parsePNGDensity(r io.Reader) (float64, error) {
+	sig := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, sig); err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(sig, pngSignature) {
+		return 0, fmt.Errorf("not a PNG file")
+	}
+
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return 0, err
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		chunkType := string(header[4:8])
+
+		switch chunkType {
+		case "pHYs":
+			if length != 9 {
+				return 0, fmt.Errorf("invalid pHYs chunk length %d", length)
+			}
+			data := make([]byte, 9)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return 0, err
+			}
+			// Unit 1 means pixels per meter; unit 0 only defines the aspect ratio
+			if data[8] != 1 {
+				return 0, nil
+			}
+			ppm := binary.BigEndian.Uint32(data[:4])
+			return float64(ppm) * 0.0254, nil
+		case "IDAT", "IEND":
+			// pHYs must appear before the image data
+			return 0, nil
+		}
+
+		// Skip the chunk data and its CRC
+		if _, err := io.CopyN(io.Discard, r, int64(length)+4); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// logicalBounds scales bounds captured at dpi down to BaseDPI
+func logicalBounds(bounds image.Rectangle, dpi float64) image.Rectangle {
+	scale := dpi / BaseDPI
+	return image.Rect(0, 0,
+		max(1, int(float64(bounds.Dx())/scale+0.5)),
+		max(1, int(float64(bounds.Dy())/scale+0.5)))
+}
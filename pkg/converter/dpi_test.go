@@ -0,0 +1,153 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+// writePNGWithDensity writes a PNG file declaring the given DPI in a pHYs chunk
+func writePNGWithDensity(t *testing.T, path string, img image.Image, dpi float64) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	data := buf.Bytes()
+
+	// pHYs data: pixels per unit X, pixels per unit Y, unit (1 = meter)
+	ppm := uint32(dpi/0.0254 + 0.5)
+	chunkData := make([]byte, 9)
+	binary.BigEndian.PutUint32(chunkData[0:4], ppm)
+	binary.BigEndian.PutUint32(chunkData[4:8], ppm)
+	chunkData[8] = 1
+
+	chunk := make([]byte, 0, 21)
+	chunk = binary.BigEndian.AppendUint32(chunk, 9)
+	chunk = append(chunk, "pHYs"...)
+	chunk = append(chunk, chunkData...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// Insert the chunk right after IHDR (8 byte signature + 25 byte IHDR chunk)
+	out := append([]byte{}, data[:33]...)
+	out = append(out, chunk...)
+	out = append(out, data[33:]...)
+
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+}
+
+func TestReadDensity(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	withDensity := filepath.Join(tempDir, "retina.png")
+	writePNGWithDensity(t, withDensity, img, 144)
+
+	withoutDensity := filepath.Join(tempDir, "plain.png")
+	f, err := os.Create(withoutDensity)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	f.Close()
+
+	// The format is told by the content, not the extension
+	misnamed := filepath.Join(tempDir, "retina.bmp")
+	writePNGWithDensity(t, misnamed, img, 144)
+	bitmap := filepath.Join(tempDir, "bitmap.png")
+	var buf bytes.Buffer
+	if err := bmp.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	if err := os.WriteFile(bitmap, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    float64
+		wantErr bool
+	}{
+		{name: "pHYs chunk present", file: withDensity, want: 144},
+		{name: "No pHYs chunk", file: withoutDensity, want: 0},
+		{name: "PNG named .bmp", file: misnamed, want: 144},
+		{name: "BMP named .png", file: bitmap, want: 0},
+		{name: "Nonexistent file", file: filepath.Join(tempDir, "missing.png"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readDensity(tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readDensity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got < tt.want-0.5 || got > tt.want+0.5 {
+				t.Errorf("readDensity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRespectDPI(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 6), uint8(y * 12), 0, 255})
+		}
+	}
+	input := filepath.Join(tempDir, "retina.png")
+	writePNGWithDensity(t, input, img, 144)
+
+	tests := []struct {
+		name       string
+		respectDPI bool
+		wantWidth  int
+		wantHeight int
+		wantDPI    float64
+	}{
+		{name: "Keep physical pixels", respectDPI: false, wantWidth: 40, wantHeight: 20, wantDPI: 144},
+		{name: "Downscale to logical pixels", respectDPI: true, wantWidth: 20, wantHeight: 10, wantDPI: BaseDPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(tempDir, "output.gif")
+			report, err := Convert([]string{input}, output, Options{Delay: 100, RespectDPI: tt.respectDPI})
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if report.Width != tt.wantWidth || report.Height != tt.wantHeight {
+				t.Errorf("Convert() size = %dx%d, want %dx%d", report.Width, report.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if report.EffectiveDPI < tt.wantDPI-0.5 || report.EffectiveDPI > tt.wantDPI+0.5 {
+				t.Errorf("Convert() effective DPI = %v, want %v", report.EffectiveDPI, tt.wantDPI)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"image"
+)
+
+const (
+	// posterSamples is the number of frames compared to pick a static preview
+	posterSamples = 16
+	// posterMinDetail is the share of the detail of the most detailed frame
+	// the first frame needs to be kept as the static preview
+	posterMinDetail = 0.5
+	// posterDetailSide is the longest side frames are measured at
+	posterDetailSide = 128
+)
+
+// ClientSupport tells whether an email client animates the output
+type ClientSupport struct {
+	Client   string `json:"client"`
+	Animates bool   `json:"animates"`
+	// Note tells what the client shows instead of the animation
+	Note string `json:"note,omitempty"`
+}
+
+// emailClient describes how an email client shows animated GIFs
+type emailClient struct {
+	name string
+	// firstFrameOnly is set for clients showing only the first frame
+	firstFrameOnly bool
+}
+
+// emailClients lists the major email clients
+var emailClients = []emailClient{
+	{name: "Apple Mail"},
+	{name: "iOS Mail"},
+	{name: "Gmail"},
+	{name: "Outlook.com"},
+	{name: "Outlook for Mac"},
+	{name: "Outlook 365 for Windows"},
+	{name: "Outlook 2007-2019 for Windows", firstFrameOnly: true},
+	{name: "Yahoo Mail"},
+	{name: "Samsung Email"},
+}
+
+// emailSupport returns which email clients animate a GIF of the given
+// number of frames
+func emailSupport(frames int) []ClientSupport {
+	support := make([]ClientSupport, len(emailClients))
+	for i, client := range emailClients {
+		support[i] = ClientSupport{Client: client.name, Animates: frames > 1 && !client.firstFrameOnly}
+		switch {
+		case frames < 2:
+			support[i].Note = "a single frame"
+		case client.firstFrameOnly:
+			support[i].Note = "only the first frame"
+		}
+	}
+	return support
+}
+
+// choosePoster returns the frame to show first to apps showing only one
+// frame: 0, unless it has much less detail than the most detailed of a few
+// evenly spaced frames, which is returned instead
+func choosePoster(frames []image.Image) int {
+	if len(frames) < 2 {
+		return 0
+	}
+	firstDetail := frameDetail(loaded(frames[0]))
+	best, bestDetail := 0, firstDetail
+	for _, i := range sampleIndices(1, len(frames), posterSamples) {
+		if detail := frameDetail(loaded(frames[i])); detail > bestDetail {
+			best, bestDetail = i, detail
+		}
+	}
+	if firstDetail >= bestDetail*posterMinDetail {
+		return 0
+	}
+	return best
+}
+
+// frameDetail returns the mean luminance gradient of img, low for blank,
+// faded or uniform frames
+func frameDetail(img image.Image) float64 {
+	gray := grayImage(img)
+	if side := max(gray.Rect.Dx(), gray.Rect.Dy()); side > posterDetailSide {
+		gray = downscaleGray(gray, (side+posterDetailSide-1)/posterDetailSide)
+	}
+	if gray.Rect.Empty() {
+		return 0
+	}
+	saliency := make([]int64, gray.Rect.Dx()*gray.Rect.Dy())
+	addEdges(saliency, gray.Rect, gray)
+	var sum int64
+	for _, edge := range saliency {
+		sum += edge
+	}
+	return float64(sum) / float64(len(saliency))
+}
@@ -0,0 +1,123 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+// fadeInFrames returns n frames of a checkerboard fading in from black
+func fadeInFrames(w, h, n int) []Frame {
+	var frames []Frame
+	for i := 0; i < n; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		level := uint8(255 * i / (n - 1))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				c := color.RGBA{A: 255}
+				if (x/8+y/8)%2 == 0 {
+					c = color.RGBA{level, level, level, 255}
+				}
+				img.Set(x, y, c)
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+	return frames
+}
+
+func TestChoosePoster(t *testing.T) {
+	images := func(frames []Frame) []image.Image {
+		var imgs []image.Image
+		for _, frame := range frames {
+			imgs = append(imgs, frame.Image)
+		}
+		return imgs
+	}
+	fadeIn := images(fadeInFrames(64, 48, 6))
+	fadeOut := make([]image.Image, len(fadeIn))
+	for i := range fadeIn {
+		fadeOut[i] = fadeIn[len(fadeIn)-1-i]
+	}
+
+	tests := []struct {
+		name   string
+		frames []image.Image
+		want   int
+	}{
+		{name: "fade in", frames: fadeIn, want: 5},
+		{name: "detailed first frame", frames: fadeOut, want: 0},
+		{name: "single frame", frames: fadeIn[:1], want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := choosePoster(tt.frames); got != tt.want {
+				t.Errorf("choosePoster() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailSupport(t *testing.T) {
+	for _, client := range emailSupport(1) {
+		if client.Animates {
+			t.Errorf("%s animates a single frame", client.Client)
+		}
+	}
+
+	animated := 0
+	for _, client := range emailSupport(10) {
+		if client.Animates {
+			animated++
+		} else if client.Note == "" {
+			t.Errorf("%s does not animate without a note", client.Client)
+		}
+	}
+	if animated == 0 || animated == len(emailClients) {
+		t.Errorf("%d of %d clients animate, want some but not all", animated, len(emailClients))
+	}
+}
+
+func TestConvertEmailPreset(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputFile := filepath.Join(tempDir, "newsletter.gif")
+	report, err := ConvertFrames(fadeInFrames(800, 200, 6), outputFile, Options{Preset: "email"})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Width != 600 || report.Height != 150 {
+		t.Errorf("output size = %dx%d, want 600x150", report.Width, report.Height)
+	}
+	if len(report.Clients) != len(emailClients) {
+		t.Errorf("got %d email clients, want %d", len(report.Clients), len(emailClients))
+	}
+	if len(report.Warnings) == 0 {
+		t.Error("expected a warning about the blank first frame")
+	}
+
+	playback, err := readPlayback(outputFile)
+	if err != nil {
+		t.Fatalf("readPlayback() error = %v", err)
+	}
+	if playback.loops != 3 {
+		t.Errorf("loops = %d, want 3", playback.loops)
+	}
+	reader, err := gifutil.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	if detail := frameDetail(reader.GIF().Image[0]); detail == 0 {
+		t.Error("the first frame of the output is blank")
+	}
+}
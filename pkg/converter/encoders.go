@@ -0,0 +1,49 @@
+package converter
+
+import (
+	"fmt"
+	"image/gif"
+	"io"
+
+	"github.com/jparrill/go-togif/pkg/encoder"
+)
+
+// GIF encoders
+const (
+	// EncoderNative is pkg/encoder, which compresses frames concurrently
+	EncoderNative = "native"
+	// EncoderStdlib is image/gif
+	EncoderStdlib = "stdlib"
+)
+
+// validateEncoder checks the encoder and its workers
+func validateEncoder(opts Options) error {
+	if opts.Workers < 0 {
+		return fmt.Errorf("workers must be non-negative")
+	}
+	switch opts.Encoder {
+	case "", EncoderNative, EncoderStdlib:
+	default:
+		return fmt.Errorf("invalid encoder %q: expected %s or %s%s", opts.Encoder, EncoderNative, EncoderStdlib, suggest(opts.Encoder, EncoderNative, EncoderStdlib))
+	}
+	return nil
+}
+
+// encodeAll writes g with the encoder selected by opts
+func encodeAll(w io.Writer, g *gif.GIF, opts Options) error {
+	if opts.Encoder == EncoderStdlib {
+		// Store the palette of the first frame once, as pkg/encoder does,
+		// rather than in every frame
+		if g.Config.ColorModel == nil {
+			global := *g
+			global.Config.ColorModel = g.Image[0].Palette
+			if global.Config.Width == 0 && global.Config.Height == 0 {
+				corner := g.Image[0].Bounds().Max
+				global.Config.Width, global.Config.Height = corner.X, corner.Y
+			}
+			g = &global
+		}
+		return gif.EncodeAll(w, g)
+	}
+	return encoder.EncodeAll(w, g, encoder.Options{Workers: opts.Workers})
+}
@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"image/color/palette"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateEncoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "default", opts: Options{}},
+		{name: "native", opts: Options{Encoder: EncoderNative}},
+		{name: "stdlib", opts: Options{Encoder: EncoderStdlib}},
+		{name: "workers", opts: Options{Workers: 4}},
+		{name: "negative workers", opts: Options{Workers: -1}, wantErr: true},
+		{name: "unknown encoder", opts: Options{Encoder: "ffmpeg"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateEncoder(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("validateEncoder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertEncoders(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 4, 32, 16)
+	sizes := make(map[string]int64)
+	for _, enc := range []string{EncoderNative, EncoderStdlib} {
+		outputFile := filepath.Join(tempDir, enc+".gif")
+		// A fixed palette keeps the frames of both conversions the same
+		if _, err := Convert(inputFiles, outputFile, Options{Delay: 100, Encoder: enc, Palette: palette.Plan9}); err != nil {
+			t.Fatalf("Convert() with %s encoder error = %v", enc, err)
+		}
+		info, err := os.Stat(outputFile)
+		if err != nil {
+			t.Fatalf("output not written: %v", err)
+		}
+		sizes[enc] = info.Size()
+	}
+
+	// Both encoders store the shared palette once
+	if sizes[EncoderNative] != sizes[EncoderStdlib] {
+		t.Errorf("native output is %d bytes, want the %d bytes of image/gif", sizes[EncoderNative], sizes[EncoderStdlib])
+	}
+}
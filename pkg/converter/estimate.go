@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"time"
+)
+
+// estimateSamples is the number of frames encoded to estimate the output size
+const estimateSamples = 5
+
+// Estimate is the predicted result of a conversion
+type Estimate struct {
+	// Frames is the number of frames the GIF would have
+	Frames int
+	// SampledFrames is the number of frames actually encoded for the estimate
+	SampledFrames int
+	Width         int
+	Height        int
+	// Colors is the size of the palette generated from the samples
+	Colors int
+	// Duration is the length of one loop of the animation
+	Duration time.Duration
+	// Bytes is the predicted size of the GIF
+	Bytes int64
+	// Auto holds the settings picked by Options.Auto
+	Auto *AutoSettings
+}
+
+// EstimateConversion predicts the size and duration of converting inputFiles with opts
+// by encoding a few evenly spaced frames, without doing the full conversion
+func EstimateConversion(inputFiles []string, opts Options) (*Estimate, error) {
+	if len(inputFiles) == 0 {
+		return nil, fmt.Errorf("no input files specified")
+	}
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+
+	src, err := inputSource(inputFiles, opts)
+	if err != nil {
+		return nil, err
+	}
+	delays, _, first, last, err := playback(src.delays, opts)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &Estimate{Frames: last - first}
+
+	// Pick the settings matching the content
+	if opts.Auto {
+		if opts, estimate.Auto, err = autoConfigure(src, first, last, opts); err != nil {
+			return nil, err
+		}
+	}
+	for _, delay := range delays[first:last] {
+		estimate.Duration += time.Duration(delay) * 10 * time.Millisecond
+	}
+
+	// Pick evenly spaced samples within the kept frames
+	indices := sampleIndices(first, last, estimateSamples)
+	samples := len(indices)
+	var bounds image.Rectangle
+	frames := make([]image.Image, 0, samples)
+	for s, i := range indices {
+		img, err := src.load(i)
+		if err != nil {
+			return nil, err
+		}
+		if s == 0 {
+			dpi, err := src.density(i)
+			if err != nil {
+				return nil, err
+			}
+			bounds = outputBounds(img.Bounds(), dpi, opts)
+		}
+		frames = append(frames, resizeTo(img, bounds, opts.Scaler))
+	}
+
+	regions := findTextRegions(frames, opts)
+	palette := opts.Palette
+	if len(palette) == 0 {
+		palette = generatePalette(frames, regions, opts)
+	}
+
+	// Encode the samples and scale their size to the whole sequence
+	sample := &gif.GIF{}
+	for i, img := range frames {
+		var frameRegions []image.Rectangle
+		if regions != nil {
+			frameRegions = regions[i]
+		}
+		sample.Image = append(sample.Image, quantizeFrame(img, palette, frameRegions, opts))
+		sample.Delay = append(sample.Delay, 0)
+	}
+	var buf bytes.Buffer
+	if err := encodeGIF(&buf, sample, opts); err != nil {
+		return nil, fmt.Errorf("error encoding GIF: %v", err)
+	}
+
+	estimate.SampledFrames = samples
+	estimate.Width = bounds.Dx()
+	estimate.Height = bounds.Dy()
+	estimate.Colors = len(palette)
+	estimate.Bytes = int64(buf.Len()) * int64(estimate.Frames) / int64(samples)
+	return estimate, nil
+}
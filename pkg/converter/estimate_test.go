@@ -0,0 +1,99 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeNoiseFrames writes n PNG frames of the given size with a varying pattern
+func writeNoiseFrames(t *testing.T, dir string, n, width, height int) []string {
+	t.Helper()
+
+	var files []string
+	for i := 0; i < n; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				img.Set(x, y, color.RGBA{uint8(x*7 + i*13), uint8(y * 5), uint8((x ^ y) + i), 255})
+			}
+		}
+		path := filepath.Join(dir, "frame"+string(rune('a'+i))+".png")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatalf("Failed to encode frame: %v", err)
+		}
+		file.Close()
+		files = append(files, path)
+	}
+	return files
+}
+
+func TestEstimateConversion(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 10, 64, 32)
+	opts := Options{Delay: 100, Width: 32, Colors: 16}
+
+	estimate, err := EstimateConversion(inputFiles, opts)
+	if err != nil {
+		t.Fatalf("EstimateConversion() error = %v", err)
+	}
+	if estimate.Frames != 10 || estimate.SampledFrames != estimateSamples {
+		t.Errorf("frames = %d (sampled %d), want 10 (sampled %d)", estimate.Frames, estimate.SampledFrames, estimateSamples)
+	}
+	if estimate.Width != 32 || estimate.Height != 16 {
+		t.Errorf("size = %dx%d, want 32x16", estimate.Width, estimate.Height)
+	}
+	if estimate.Colors > 16 {
+		t.Errorf("colors = %d, want at most 16", estimate.Colors)
+	}
+	if estimate.Duration != time.Second {
+		t.Errorf("duration = %v, want 1s", estimate.Duration)
+	}
+
+	// The estimate should be in the same ballpark as the real output
+	outputFile := filepath.Join(tempDir, "output.gif")
+	if _, err := Convert(inputFiles, outputFile, opts); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	info, err := os.Stat(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to stat output: %v", err)
+	}
+	if ratio := float64(estimate.Bytes) / float64(info.Size()); ratio < 0.5 || ratio > 2 {
+		t.Errorf("estimated %d bytes, actual %d bytes", estimate.Bytes, info.Size())
+	}
+}
+
+func TestEstimateConversionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		opts  Options
+	}{
+		{"no files", nil, Options{}},
+		{"too many colors", []string{"a.png"}, Options{Colors: 300}},
+		{"negative width", []string{"a.png"}, Options{Width: -1}},
+		{"missing file", []string{"missing.png"}, Options{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EstimateConversion(tt.files, tt.opts); err == nil {
+				t.Error("EstimateConversion() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// colorIndex is the palette of frames using few enough colors to be stored
+// exactly, with the index of every color
+type colorIndex struct {
+	palette color.Palette
+	indexes map[color.RGBA]uint8
+}
+
+// exactPalette collects the colors of frames, most used first, when there are
+// at most maxColors of them. Such frames are indexed directly instead of going
+// through palette generation and quantization.
+func exactPalette(frames []image.Image, maxColors int) (*colorIndex, bool) {
+	counts := make(map[color.RGBA]int)
+	for _, img := range frames {
+		if !countColors(counts, loaded(img), maxColors) {
+			return nil, false
+		}
+	}
+	if len(counts) == 0 {
+		return nil, false
+	}
+
+	colors := make([]color.RGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	// Ties are broken by value so the palette does not depend on map order
+	sort.Slice(colors, func(i, j int) bool {
+		a, b := colors[i], colors[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return uint32(a.R)<<24|uint32(a.G)<<16|uint32(a.B)<<8|uint32(a.A) < uint32(b.R)<<24|uint32(b.G)<<16|uint32(b.B)<<8|uint32(b.A)
+	})
+
+	index := &colorIndex{palette: make(color.Palette, len(colors)), indexes: make(map[color.RGBA]uint8, len(colors))}
+	for i, c := range colors {
+		index.palette[i] = c
+		index.indexes[c] = uint8(i)
+	}
+	return index, true
+}
+
+// countColors adds the pixels of img to counts, returning false as soon as
+// there are more than maxColors colors
+func countColors(counts map[color.RGBA]int, img image.Image, maxColors int) bool {
+	bounds := img.Bounds()
+	switch img := img.(type) {
+	case *image.Paletted:
+		var used [MaxColors]int
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			offset := img.PixOffset(bounds.Min.X, y)
+			for _, i := range img.Pix[offset : offset+bounds.Dx()] {
+				used[i]++
+			}
+		}
+		for i, n := range used {
+			if n > 0 {
+				if i >= len(img.Palette) {
+					return false
+				}
+				counts[color.RGBAModel.Convert(img.Palette[i]).(color.RGBA)] += n
+			}
+		}
+	case *image.RGBA:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+			for x := 0; x < bounds.Dx(); x++ {
+				counts[color.RGBA{pix[4*x], pix[4*x+1], pix[4*x+2], pix[4*x+3]}]++
+			}
+			if len(counts) > maxColors {
+				return false
+			}
+		}
+	default:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				counts[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+			}
+			if len(counts) > maxColors {
+				return false
+			}
+		}
+	}
+	return len(counts) <= maxColors
+}
+
+// index maps img onto the exact palette without any color search
+func (c *colorIndex) index(img image.Image) *image.Paletted {
+	img = loaded(img)
+	if src, ok := img.(*image.Paletted); ok {
+		if paletted, ok := remapPaletted(src, c.palette); ok {
+			return paletted
+		}
+	}
+
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, c.palette)
+	rgba, isRGBA := img.(*image.RGBA)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		out := paletted.Pix[paletted.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			var px color.RGBA
+			if isRGBA {
+				p := rgba.Pix[rgba.PixOffset(bounds.Min.X+x, y):]
+				px = color.RGBA{p[0], p[1], p[2], p[3]}
+			} else {
+				px = color.RGBAModel.Convert(img.At(bounds.Min.X+x, y)).(color.RGBA)
+			}
+			i, ok := c.indexes[px]
+			if !ok {
+				// Only frames the palette was built from are indexed
+				i = uint8(c.palette.Index(px))
+			}
+			out[x] = i
+		}
+	}
+	return paletted
+}
@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// fewColorFrames returns RGBA frames drawing stripes of the given colors
+func fewColorFrames(count, width, height int, colors []color.RGBA) []image.Image {
+	frames := make([]image.Image, count)
+	for f := range frames {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				img.SetRGBA(x, y, colors[(x+y+f)%len(colors)])
+			}
+		}
+		frames[f] = img
+	}
+	return frames
+}
+
+func TestExactPalette(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{blue, red, color.White})
+	for i := range paletted.Pix {
+		paletted.Pix[i] = uint8(i % 2)
+	}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(nrgba, nrgba.Bounds(), image.NewUniform(green), image.Point{}, draw.Src)
+
+	many := make([]color.RGBA, 300)
+	for i := range many {
+		many[i] = color.RGBA{uint8(i), uint8(i >> 8), 0, 255}
+	}
+
+	tests := []struct {
+		name      string
+		frames    []image.Image
+		maxColors int
+		want      color.Palette
+	}{
+		{name: "rgba", frames: fewColorFrames(2, 3, 3, []color.RGBA{red, green, blue}), maxColors: 256, want: color.Palette{blue, green, red}},
+		{name: "mixed types", frames: []image.Image{paletted, nrgba}, maxColors: 256, want: color.Palette{blue, red, green}},
+		{name: "limited colors", frames: fewColorFrames(1, 4, 4, []color.RGBA{red, green, blue}), maxColors: 2},
+		{name: "too many colors", frames: fewColorFrames(1, 300, 2, many), maxColors: 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, ok := exactPalette(tt.frames, tt.maxColors)
+			if ok != (tt.want != nil) {
+				t.Fatalf("exactPalette() ok = %v, want %v", ok, tt.want != nil)
+			}
+			if !ok {
+				return
+			}
+			if len(index.palette) != len(tt.want) {
+				t.Fatalf("palette = %v, want %v", index.palette, tt.want)
+			}
+			for i := range tt.want {
+				if !sameColor(index.palette[i], tt.want[i]) {
+					t.Errorf("palette[%d] = %v, want %v", i, index.palette[i], tt.want[i])
+				}
+			}
+
+			// Indexing matches mapping every pixel through the palette
+			for f, img := range tt.frames {
+				got := index.index(img)
+				want := image.NewPaletted(img.Bounds(), index.palette)
+				draw.Draw(want, want.Bounds(), img, img.Bounds().Min, draw.Src)
+				if !bytes.Equal(got.Pix, want.Pix) {
+					t.Errorf("frame %d indexed as %v, want %v", f, got.Pix, want.Pix)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkExactPalette(b *testing.B) {
+	colors := make([]color.RGBA, 200)
+	for i := range colors {
+		colors[i] = color.RGBA{uint8(i), uint8(i * 3), uint8(255 - i), 255}
+	}
+	frames := fewColorFrames(10, 640, 480, colors)
+
+	b.Run("quantize", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			palette := generatePalette(frames, nil, Options{})
+			for _, img := range frames {
+				quantizeFrame(img, palette, nil, Options{})
+			}
+		}
+	})
+	b.Run("exact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			index, _ := exactPalette(frames, MaxColors)
+			for _, img := range frames {
+				index.index(img)
+			}
+		}
+	})
+}
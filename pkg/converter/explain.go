@@ -0,0 +1,319 @@
+package converter
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plan is the pipeline a conversion runs, with the defaults of its options
+// resolved, as printed by --explain
+type Plan struct {
+	// Command is the command line producing the plan, left out when empty
+	Command string
+	// Inputs is the number of input files or frames
+	Inputs int
+	// Outputs are the files written
+	Outputs []string
+	// Formats are the output formats
+	Formats []string
+	// Quantizer, Dither and Colors generate the palette of GIF outputs,
+	// QuantizerAuto when picked from the content
+	Quantizer string
+	Dither    string
+	Colors    int
+	// Scaler resizes the frames
+	Scaler string
+	// Encoder writes the GIF outputs
+	Encoder string
+	// Workers, IOWorkers and QueueDepth size the stages of the pipeline
+	Workers    int
+	IOWorkers  int
+	QueueDepth int
+	// Stages are the stages frames go through, in order, only those enabled
+	// by the options being listed
+	Stages []PlanStage
+}
+
+// PlanStage is a stage of a Plan with the options it runs with
+type PlanStage struct {
+	Name   string
+	Params []PlanParam
+}
+
+// PlanParam is an option of a PlanStage, named like its command line flag
+type PlanParam struct {
+	Key   string
+	Value any
+}
+
+// QuantizerAuto is the quantizer of a Plan when Auto or Segments pick it
+// from the content
+const QuantizerAuto = "auto"
+
+// Explain returns the pipeline converting inputs frames to outputFile with
+// valid opts, without reading them. Settings picked from the content, like
+// those of Auto, are reported as QuantizerAuto or left to their defaults.
+func Explain(inputs int, outputFile string, opts Options) *Plan {
+	plan := &Plan{
+		Inputs:     inputs,
+		Formats:    effectiveFormats(opts),
+		Quantizer:  orDefault(opts.Quantizer, QuantizerFrequency),
+		Dither:     orDefault(opts.Dither, DitherNone),
+		Colors:     opts.Colors,
+		Scaler:     orDefault(opts.Scaler, ScalerSmooth),
+		Encoder:    orDefault(opts.Encoder, EncoderNative),
+		Workers:    opts.Workers,
+		IOWorkers:  max(1, opts.IOWorkers),
+		QueueDepth: opts.QueueDepth,
+	}
+	if (opts.Auto || opts.Segments) && opts.Quantizer == "" {
+		plan.Quantizer = QuantizerAuto
+	}
+	if plan.Colors == 0 {
+		plan.Colors = MaxColors
+	}
+	if plan.Workers == 0 {
+		plan.Workers = runtime.NumCPU()
+	}
+	if plan.QueueDepth == 0 {
+		plan.QueueDepth = DefaultQueueDepth
+	}
+	plan.Outputs = plannedOutputs(outputFile, plan.Formats, opts)
+
+	stage := func(name string, params ...PlanParam) {
+		var set []PlanParam
+		for _, p := range params {
+			if p.Value != nil {
+				set = append(set, p)
+			}
+		}
+		if len(set) > 0 {
+			plan.Stages = append(plan.Stages, PlanStage{Name: name, Params: set})
+		}
+	}
+
+	stage("decode",
+		param("files", inputs),
+		param("io-workers", plan.IOWorkers),
+		param("max-input-width", opts.InputLimits.MaxWidth),
+		param("max-input-height", opts.InputLimits.MaxHeight),
+		param("max-input-pixels", opts.InputLimits.MaxPixels),
+		param("frame-timeout", opts.InputLimits.FrameTimeout))
+	stage("timing",
+		param("delay", time.Duration(opts.Delay)*time.Millisecond),
+		param("manifest-delays", len(opts.FrameDelays)),
+		param("timeline", opts.Timeline),
+		param("trim-idle", opts.TrimIdle),
+		param("chapters", len(opts.Chapters)),
+		param("chapter-hold", opts.ChapterHold))
+	stage("select",
+		param("start", opts.Start),
+		param("end", opts.End),
+		param("max-duration", opts.MaxDuration),
+		param("max-frames", opts.MaxFrames),
+		param("loop-search", opts.LoopSearch),
+		param("find-loop", opts.FindLoop),
+		param("preview-frames", opts.PreviewFrames))
+	stage("reframe",
+		param("stabilize", opts.Stabilize),
+		param("align-on", opts.AlignOn),
+		param("smart-crop", sizeString(opts.SmartCrop.X, opts.SmartCrop.Y)),
+		param("fit", sizeString(opts.Fit.X, opts.Fit.Y)))
+	stage("resize",
+		param("scaler", plan.Scaler),
+		param("width", opts.Width),
+		param("max-width", opts.MaxWidth),
+		param("respect-dpi", opts.RespectDPI))
+	stage("grade",
+		param("normalize", opts.Normalize),
+		param("equalize", opts.Equalize),
+		param("lut", opts.LUT),
+		param("vignette", opts.Vignette),
+		param("corner-radius", opts.CornerRadius))
+	stage("overlays",
+		param("waveform", opts.Waveform),
+		param("subtitles", opts.Subtitles),
+		param("annotations", opts.Annotations),
+		param("events", opts.Events),
+		param("frame-label", opts.FrameLabel),
+		param("stamp", opts.Stamp),
+		param("stamp-corner", opts.StampCorner))
+	stage("effects",
+		param("onion-skin", opts.OnionSkin),
+		param("smooth-loop", opts.SmoothLoop),
+		param("magnify-changes", opts.MagnifyChanges),
+		param("visualize-diff", opts.VisualizeDiff),
+		param("frame-hook", opts.FrameHook != nil))
+	stage("quantize",
+		param("quantizer", plan.Quantizer),
+		param("dither", plan.Dither),
+		param("colors", plan.Colors),
+		param("palette", len(opts.Palette)),
+		param("preserve-text", opts.PreserveText),
+		param("text-regions", len(opts.TextRegions)),
+		param("segments", opts.Segments),
+		param("source-gamut", opts.SourceGamut))
+	stage("encode",
+		param("formats", plan.Formats),
+		param("encoder", plan.Encoder),
+		param("gif-version", opts.GIFVersion),
+		param("gif-extensions", opts.GIFExtensions),
+		param("loops", opts.Loops),
+		param("verify", opts.Verify),
+		param("workers", plan.Workers),
+		param("memory-limit", opts.MemoryLimit))
+	stage("fit",
+		param("preset", opts.Preset),
+		param("check", opts.Check),
+		param("auto-adjust", opts.AutoAdjust),
+		param("min-ssim", opts.MinSSIM))
+	return plan
+}
+
+// orDefault returns value, or fallback when it is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// sizeString returns a size as WxH, empty when it is zero
+func sizeString(width, height int) string {
+	if width == 0 && height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", width, height)
+}
+
+// param returns the parameter of a stage, with a nil value when it is the
+// zero value of its type and so leaves the stage alone
+func param(key string, value any) PlanParam {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			value = nil
+		}
+	case int:
+		if v == 0 {
+			value = nil
+		}
+	case int64:
+		if v == 0 {
+			value = nil
+		}
+	case float64:
+		if v == 0 {
+			value = nil
+		}
+	case bool:
+		if !v {
+			value = nil
+		}
+	case time.Duration:
+		if v == 0 {
+			value = nil
+		}
+	case []string:
+		if len(v) == 0 {
+			value = nil
+		}
+	}
+	return PlanParam{Key: key, Value: value}
+}
+
+// plannedOutputs returns the files a conversion of opts writes for outputFile
+func plannedOutputs(outputFile string, formats []string, opts Options) []string {
+	if opts.PreviewFrames > 0 {
+		outputFile = PreviewPath(outputFile)
+	}
+	var outputs []string
+	switch {
+	case len(opts.Sizes) > 0:
+		for _, width := range opts.Sizes {
+			outputs = append(outputs, SizedOutputPath(outputFile, width))
+		}
+	case len(opts.ROIs) > 0:
+		for _, roi := range opts.ROIs {
+			outputs = append(outputs, ROIOutputPath(outputFile, roi.Name))
+		}
+	case len(formats) > 1:
+		for _, format := range formats {
+			outputs = append(outputs, FormatPath(outputFile, format))
+		}
+	default:
+		outputs = []string{outputFile}
+	}
+	return outputs
+}
+
+// WriteYAML writes the plan as a YAML document, explicitly ended so that
+// YAML parsers stop before what is printed after it
+func (p *Plan) WriteYAML(w io.Writer) error {
+	var b strings.Builder
+	if p.Command != "" {
+		fmt.Fprintf(&b, "command: %s\n", yamlValue(p.Command))
+	}
+	fmt.Fprintf(&b, "inputs: %d\n", p.Inputs)
+	fmt.Fprintf(&b, "outputs: %s\n", yamlValue(p.Outputs))
+	fmt.Fprintf(&b, "formats: %s\n", yamlValue(p.Formats))
+	fmt.Fprintf(&b, "quantizer: %s\n", yamlValue(p.Quantizer))
+	fmt.Fprintf(&b, "dither: %s\n", yamlValue(p.Dither))
+	fmt.Fprintf(&b, "colors: %d\n", p.Colors)
+	fmt.Fprintf(&b, "scaler: %s\n", yamlValue(p.Scaler))
+	fmt.Fprintf(&b, "encoder: %s\n", yamlValue(p.Encoder))
+	fmt.Fprintf(&b, "workers: %d\n", p.Workers)
+	fmt.Fprintf(&b, "io-workers: %d\n", p.IOWorkers)
+	fmt.Fprintf(&b, "queue-depth: %d\n", p.QueueDepth)
+	b.WriteString("stages:\n")
+	for _, stage := range p.Stages {
+		fmt.Fprintf(&b, "  - name: %s\n", yamlValue(stage.Name))
+		for _, param := range stage.Params {
+			fmt.Fprintf(&b, "    %s: %s\n", param.Key, yamlValue(param.Value))
+		}
+	}
+	b.WriteString("...\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// yamlValue formats a value as a YAML scalar or flow sequence, quoting the
+// strings a YAML parser would read as another type or misparse
+func yamlValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" || strings.ContainsAny(v, ":#{}[],&*!|>'\"%@`\\\n\t") || strings.TrimSpace(v) != v ||
+			strings.HasPrefix(v, "-") || strings.HasPrefix(v, "?") || !isPlainYAML(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = yamlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case time.Duration:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// isPlainYAML reports whether a plain YAML scalar s is read back as the
+// string s rather than as a number, boolean or null
+func isPlainYAML(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return false
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		wantStages []string
+		check      func(t *testing.T, plan *Plan)
+	}{
+		{
+			name:       "defaults",
+			opts:       Options{Delay: 100},
+			wantStages: []string{"decode", "timing", "resize", "quantize", "encode"},
+			check: func(t *testing.T, plan *Plan) {
+				if plan.Quantizer != QuantizerFrequency || plan.Dither != DitherNone || plan.Scaler != ScalerSmooth || plan.Encoder != EncoderNative {
+					t.Errorf("Explain() = %s, %s, %s, %s, want the defaults", plan.Quantizer, plan.Dither, plan.Scaler, plan.Encoder)
+				}
+				if plan.Colors != MaxColors || plan.Workers != runtime.NumCPU() || plan.IOWorkers != 1 || plan.QueueDepth != DefaultQueueDepth {
+					t.Errorf("Explain() colors %d, workers %d/%d, queue depth %d, want the defaults", plan.Colors, plan.Workers, plan.IOWorkers, plan.QueueDepth)
+				}
+				if len(plan.Outputs) != 1 || plan.Outputs[0] != "out.gif" {
+					t.Errorf("Explain() outputs = %v, want [out.gif]", plan.Outputs)
+				}
+			},
+		},
+		{
+			name:       "auto with overlays and several formats",
+			opts:       Options{Delay: 100, Auto: true, Stamp: "ci", OnionSkin: 2, Formats: []string{FormatGIF, FormatWebP}, Workers: 3},
+			wantStages: []string{"decode", "timing", "resize", "overlays", "effects", "quantize", "encode"},
+			check: func(t *testing.T, plan *Plan) {
+				if plan.Quantizer != QuantizerAuto {
+					t.Errorf("Explain() quantizer = %s, want %s", plan.Quantizer, QuantizerAuto)
+				}
+				if plan.Workers != 3 {
+					t.Errorf("Explain() workers = %d, want 3", plan.Workers)
+				}
+				if len(plan.Outputs) != 2 || plan.Outputs[1] != "out.webp" {
+					t.Errorf("Explain() outputs = %v, want out.gif and out.webp", plan.Outputs)
+				}
+			},
+		},
+		{
+			name:       "preview of a trimmed window",
+			opts:       Options{Delay: 100, Start: time.Second, PreviewFrames: 5, Preset: "slack-emoji"},
+			wantStages: []string{"decode", "timing", "select", "resize", "quantize", "encode", "fit"},
+			check: func(t *testing.T, plan *Plan) {
+				if plan.Outputs[0] != PreviewPath("out.gif") {
+					t.Errorf("Explain() outputs = %v, want the preview", plan.Outputs)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := Explain(10, "out.gif", tt.opts)
+			var stages []string
+			for _, stage := range plan.Stages {
+				stages = append(stages, stage.Name)
+			}
+			if strings.Join(stages, ",") != strings.Join(tt.wantStages, ",") {
+				t.Errorf("Explain() stages = %v, want %v", stages, tt.wantStages)
+			}
+			tt.check(t, plan)
+		})
+	}
+}
+
+func TestPlanWriteYAML(t *testing.T) {
+	plan := Explain(3, "out.gif", Options{Delay: 100, Workers: 2, Stamp: "run: 5", Timeline: "0-1@2s"})
+	plan.Command = "go-togif convert -i '*.png' -o out.gif"
+
+	var b strings.Builder
+	if err := plan.WriteYAML(&b); err != nil {
+		t.Fatalf("WriteYAML() error = %v", err)
+	}
+	for _, want := range []string{
+		"command: \"go-togif convert -i '*.png' -o out.gif\"\n",
+		"inputs: 3\n",
+		"outputs: [out.gif]\n",
+		"workers: 2\n",
+		"stages:\n  - name: decode\n    files: 3\n    io-workers: 1\n",
+		"    delay: 100ms\n    timeline: \"0-1@2s\"\n",
+		"    stamp: \"run: 5\"\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteYAML() = %s, want it to contain %q", b.String(), want)
+		}
+	}
+}
+
+func TestYAMLValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{value: "frequency", want: "frequency"},
+		{value: "", want: `""`},
+		{value: "a: b", want: `"a: b"`},
+		{value: "256", want: `"256"`},
+		{value: "yes", want: `"yes"`},
+		{value: "-x", want: `"-x"`},
+		{value: []string{"gif", "no"}, want: `[gif, "no"]`},
+		{value: 2 * time.Second, want: "2s"},
+		{value: 0.5, want: "0.5"},
+		{value: true, want: "true"},
+	}
+
+	for _, tt := range tests {
+		if got := yamlValue(tt.value); got != tt.want {
+			t.Errorf("yamlValue(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/flipbook"
+)
+
+// isFlipbook reports whether format is a document with a page per frame
+func isFlipbook(format string) bool {
+	return format == FormatPDF || format == FormatTIFF
+}
+
+// writeFlipbook writes the frames as the pages of a PDF or multi-page TIFF
+// at path, noting when each frame is shown and for how long
+func writeFlipbook(path, format string, frames []image.Image, delays []int) error {
+	pages := make([]flipbook.Page, len(frames))
+	var start time.Duration
+	for i, frame := range frames {
+		delay := time.Duration(delays[i]) * 10 * time.Millisecond
+		pages[i] = flipbook.Page{Image: loaded(frame), Start: start, Delay: delay}
+		start += delay
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %v", err)
+	}
+	defer file.Close()
+	if format == FormatPDF {
+		err = flipbook.WritePDF(file, pages)
+	} else {
+		err = flipbook.WriteTIFF(file, pages)
+	}
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package converter
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConvertFlipbook(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	// No ffmpeg is needed for flipbooks
+	t.Setenv(FFmpegEnv, filepath.Join(tempDir, "missing"))
+
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		frames = append(frames, Frame{Image: image.NewRGBA(image.Rect(0, 0, 30, 20)), Delay: 200 * time.Millisecond})
+	}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		files  []string
+		header []byte
+	}{
+		{name: "pdf", opts: Options{Format: FormatPDF}, files: []string{"out.pdf"}, header: []byte("%PDF-")},
+		{name: "tiff", opts: Options{Format: FormatTIFF}, files: []string{"out.tiff"}, header: []byte{'I', 'I', 42, 0}},
+		{name: "with a gif", opts: Options{Formats: []string{FormatGIF, FormatPDF, FormatTIFF}}, files: []string{"out.gif", "out.pdf", "out.tiff"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, "out."+tt.opts.Format)
+			if tt.opts.Format == "" {
+				outputFile = filepath.Join(tempDir, "out.gif")
+			}
+			report, err := ConvertFrames(frames, outputFile, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertFrames() error = %v", err)
+			}
+			if len(report.Outputs) != len(tt.files) {
+				t.Fatalf("got %d outputs, want %d", len(report.Outputs), len(tt.files))
+			}
+			for i, output := range report.Outputs {
+				if filepath.Base(output.File) != tt.files[i] {
+					t.Errorf("output %d = %s, want %s", i, output.File, tt.files[i])
+				}
+				data, err := os.ReadFile(output.File)
+				if err != nil {
+					t.Fatalf("Failed to read output: %v", err)
+				}
+				if tt.header != nil && !bytes.HasPrefix(data, tt.header) {
+					t.Errorf("output %s starts with %q, want %q", output.File, data[:min(len(data), 8)], tt.header)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+// frameFormat decodes the frame files of a format
+type frameFormat struct {
+	name string
+	// magic is the start of the files of the format, ? matching any byte
+	magic  string
+	decode func(io.Reader, decode.Limits) (image.Image, error)
+	config func(io.Reader, decode.Limits) (image.Config, error)
+}
+
+// frameFormats are the formats of the frame files, told by their content
+var frameFormats = []frameFormat{
+	{"PNG", "\x89PNG\r\n\x1a\n", decode.PNG, decode.PNGConfig},
+	{"WebP", "RIFF????WEBP", decodeStillWebP, decode.WebPConfig},
+	{"BMP", "BM", decode.BMP, decode.BMPConfig},
+	{"TIFF", "II*\x00", decode.TIFF, decode.TIFFConfig},
+	{"TIFF", "MM\x00*", decode.TIFF, decode.TIFFConfig},
+}
+
+// frameExtensions are the lower case extensions of the files input patterns
+// match
+var frameExtensions = []string{".png", ".webp", ".bmp", ".tif", ".tiff"}
+
+// hasFrameExtension reports whether name ends with one of frameExtensions,
+// in any case
+func hasFrameExtension(name string) bool {
+	return slices.Contains(frameExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// sniffFrameFormat returns the format of inputFile, whose content starts
+// with header, whatever its extension
+func sniffFrameFormat(inputFile string, header []byte) (frameFormat, error) {
+	for _, format := range frameFormats {
+		if matchMagic(format.magic, header) {
+			return format, nil
+		}
+	}
+	return frameFormat{}, fmt.Errorf("file %s is not a PNG, WebP, BMP or TIFF image", inputFile)
+}
+
+// matchMagic reports whether header starts with magic, ? matching any byte
+func matchMagic(magic string, header []byte) bool {
+	if len(header) < len(magic) {
+		return false
+	}
+	for i := 0; i < len(magic); i++ {
+		if magic[i] != '?' && magic[i] != header[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// frameFormatOf returns the format of the open inputFile from its first
+// bytes, leaving its offset untouched
+func frameFormatOf(inputFile string, file io.ReaderAt) (frameFormat, error) {
+	header := make([]byte, 12)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return frameFormat{}, fmt.Errorf("error reading file %s: %v", inputFile, err)
+	}
+	return sniffFrameFormat(inputFile, header[:n])
+}
+
+// DecodeInputConfig returns the dimensions of an input file of any of the
+// supported formats, told by its content, read from its header only
+func DecodeInputConfig(inputFile string) (image.Config, error) {
+	return readFrameConfig(inputFile, decode.Limits{})
+}
@@ -0,0 +1,40 @@
+package converter
+
+import "testing"
+
+func TestSniffFrameFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{name: "png", header: "\x89PNG\r\n\x1a\n\x00\x00\x00\x0d", want: "PNG"},
+		{name: "webp", header: "RIFF\x22\x00\x00\x00WEBP", want: "WebP"},
+		{name: "bmp", header: "BM\x36\x00\x00\x00", want: "BMP"},
+		{name: "little endian tiff", header: "II*\x00\x08\x00\x00\x00", want: "TIFF"},
+		{name: "big endian tiff", header: "MM\x00*\x00\x00\x00\x08", want: "TIFF"},
+		{name: "wav is not webp", header: "RIFF\x22\x00\x00\x00WAVE", wantErr: true},
+		{name: "jpeg", header: "\xff\xd8\xff\xe0", wantErr: true},
+		{name: "truncated png", header: "\x89PNG", wantErr: true},
+		{name: "empty", header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sniffFrameFormat("frame", []byte(tt.header))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("sniffFrameFormat() = %s, want an error", got.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sniffFrameFormat() error = %v", err)
+			}
+			if got.name != tt.want {
+				t.Errorf("sniffFrameFormat() = %s, want %s", got.name, tt.want)
+			}
+		})
+	}
+}
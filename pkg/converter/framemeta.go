@@ -0,0 +1,83 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// FrameMeta describes a frame to the overlay stages: where it comes from,
+// when it is shown and the values attached to it by the user
+type FrameMeta struct {
+	// Index is the position of the frame in the untrimmed sequence
+	Index int
+	// Source is the input file of the frame, empty for in-memory frames
+	Source string
+	// Timestamp is when the frame is shown, in the untrimmed sequence
+	Timestamp time.Duration
+	// Tags are user values attached to the frame (see Frame.Tags and Sidecar.Tags)
+	Tags map[string]string
+	// Caption is the text burned onto the frame instead of the subtitles (see Sidecar.Caption)
+	Caption string
+}
+
+// labelField matches the {field} and {tag:name} placeholders of a frame label
+var labelField = regexp.MustCompile(`\{(index|frame|file|path|time|tag:[^{}]+)\}`)
+
+// Label expands the placeholders of a frame label template: {index} (0-based),
+// {frame} (1-based), {file} and {path} of the source, {time} as MM:SS.mmm and
+// {tag:name}. Unknown tags expand to nothing.
+func (m FrameMeta) Label(template string) string {
+	return labelField.ReplaceAllStringFunc(template, func(field string) string {
+		name := field[1 : len(field)-1]
+		switch name {
+		case "index":
+			return strconv.Itoa(m.Index)
+		case "frame":
+			return strconv.Itoa(m.Index + 1)
+		case "file":
+			if m.Source == "" {
+				return ""
+			}
+			return filepath.Base(m.Source)
+		case "path":
+			return m.Source
+		case "time":
+			return formatTimestamp(m.Timestamp)
+		default:
+			return m.Tags[name[len("tag:"):]]
+		}
+	})
+}
+
+// formatTimestamp formats a playback position as MM:SS.mmm
+func formatTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
+
+// frameMetas returns the metadata of the frames [first, last) of src, given
+// the playback position of every frame
+func frameMetas(src source, positions []time.Duration, first, last int) []FrameMeta {
+	metas := make([]FrameMeta, 0, last-first)
+	for i := first; i < last; i++ {
+		meta := FrameMeta{Index: i, Timestamp: positions[i]}
+		switch len(src.files) {
+		case len(src.names):
+			meta.Source = src.files[i]
+		case 1:
+			// Frames rendered from a single recording
+			meta.Source = src.files[0]
+		}
+		if src.tags != nil {
+			meta.Tags = src.tags[i]
+		}
+		if src.captions != nil {
+			meta.Caption = src.captions[i]
+		}
+		metas = append(metas, meta)
+	}
+	return metas
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFrameMetaLabel(t *testing.T) {
+	meta := FrameMeta{
+		Index:     4,
+		Source:    filepath.Join("shots", "frame5.png"),
+		Timestamp: 61*time.Second + 250*time.Millisecond,
+		Tags:      map[string]string{"step": "login"},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{index}/{frame}", "4/5"},
+		{"{file} at {time}", "frame5.png at 01:01.250"},
+		{"{path}", filepath.Join("shots", "frame5.png")},
+		{"step: {tag:step}{tag:missing}", "step: login"},
+		{"{unknown} {index", "{unknown} {index"},
+	}
+
+	for _, tt := range tests {
+		if got := meta.Label(tt.template); got != tt.want {
+			t.Errorf("Label(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestFrameMetas(t *testing.T) {
+	src, err := frameSource([]Frame{
+		{Image: image.NewRGBA(image.Rect(0, 0, 1, 1))},
+		{Image: image.NewRGBA(image.Rect(0, 0, 1, 1)), Tags: map[string]string{"step": "two"}},
+		{Image: image.NewRGBA(image.Rect(0, 0, 1, 1))},
+	})
+	if err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+	positions := []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond}
+
+	metas := frameMetas(src, positions, 1, 3)
+	if len(metas) != 2 || metas[0].Index != 1 || metas[0].Timestamp != positions[1] || metas[0].Tags["step"] != "two" {
+		t.Errorf("frameMetas() = %+v, want frames 1 and 2 with their tags", metas)
+	}
+	if metas[1].Tags != nil || metas[1].Source != "" {
+		t.Errorf("frameMetas() = %+v, want no tags or source for frame 2", metas[1])
+	}
+}
+
+func TestConvertFrameLabel(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	white := image.NewRGBA(image.Rect(0, 0, 120, 60))
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+	frames := []Frame{{Image: white, Tags: map[string]string{"step": "login"}}, {Image: white}}
+	outputFile := filepath.Join(tempDir, "output.gif")
+	opts := Options{Delay: 100, FrameLabel: "{tag:step}"}
+	if _, err := ConvertFrames(frames, outputFile, opts); err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+
+	// Only the tagged frame gets a label, drawn over the white background
+	for i, want := range []bool{true, false} {
+		img := g.Image[i]
+		at := image.Pt(8, 8)
+		labeled := at.In(img.Bounds()) && !sameColor(img.At(at.X, at.Y), color.White)
+		if labeled != want {
+			t.Errorf("frame %d labeled = %v, want %v", i, labeled, want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"image/gif"
+	"io"
+	"slices"
+	"strings"
+)
+
+// GIF versions written by the encoder
+const (
+	// GIF87a has no extension blocks: no delays, looping or transparency, for legacy decoders
+	GIF87a = "87a"
+	// GIF89a is the default, with the extension blocks enabled by Options.GIFExtensions
+	GIF89a = "89a"
+)
+
+// GIF89a extension blocks that can be turned off
+const (
+	// ExtensionLoop is the NETSCAPE2.0 application extension making the animation loop
+	ExtensionLoop = "loop"
+	// ExtensionControl is the graphic control extension holding frame delays and disposal
+	ExtensionControl = "control"
+)
+
+// maxLoops is the most plays the NETSCAPE2.0 extension can store
+const maxLoops = 1 << 16
+
+// loopCount returns the gif.GIF loop count playing the animation the given
+// number of times, 0 looping forever
+func loopCount(loops int) int {
+	if loops == 0 {
+		return 0
+	}
+	if loops == 1 {
+		return -1
+	}
+	return loops - 1
+}
+
+// Extensions lists the GIF89a extension blocks in the order they are documented
+var Extensions = []string{ExtensionLoop, ExtensionControl}
+
+// GIF block markers and extension labels
+const (
+	blockExtension   = 0x21
+	blockImage       = 0x2c
+	blockTrailer     = 0x3b
+	labelControl     = 0xf9
+	labelApplication = 0xff
+)
+
+// gifExtensions returns the set of extension blocks written with opts
+func gifExtensions(opts Options) map[string]bool {
+	enabled := make(map[string]bool, len(Extensions))
+	if opts.GIFVersion == GIF87a {
+		return enabled
+	}
+	if opts.GIFExtensions == nil {
+		for _, ext := range Extensions {
+			enabled[ext] = true
+		}
+		return enabled
+	}
+	for _, ext := range opts.GIFExtensions {
+		enabled[ext] = true
+	}
+	return enabled
+}
+
+// validateGIFVersion checks that the requested features can be stored in the GIF version
+func validateGIFVersion(opts Options) error {
+	switch opts.GIFVersion {
+	case "", GIF89a:
+		for _, ext := range opts.GIFExtensions {
+			if !slices.Contains(Extensions, ext) {
+				return fmt.Errorf("invalid GIF extension %q: expected one of %s", ext, strings.Join(Extensions, ", "))
+			}
+		}
+	case GIF87a:
+		if len(opts.GIFExtensions) > 0 {
+			return fmt.Errorf("GIF87a does not support extension blocks (%s)", strings.Join(opts.GIFExtensions, ", "))
+		}
+	default:
+		return fmt.Errorf("invalid GIF version %q: expected %s or %s", opts.GIFVersion, GIF87a, GIF89a)
+	}
+
+	// Per frame timing only exists in the graphic control extension
+	if gifExtensions(opts)[ExtensionControl] {
+		return nil
+	}
+	missing := "the control extension"
+	if opts.GIFVersion == GIF87a {
+		missing = "GIF89a"
+	}
+	if opts.Timeline != "" {
+		return fmt.Errorf("timeline delays require %s", missing)
+	}
+	if opts.PosterFrame > 0 && opts.PosterMode != PosterRotate {
+		return fmt.Errorf("poster mode %s relies on a frame delay and requires %s, use %s", PosterDuplicate, missing, PosterRotate)
+	}
+	return nil
+}
+
+// encodeGIF writes g in the GIF version and with the extension blocks selected by opts
+func encodeGIF(w io.Writer, g *gif.GIF, opts Options) error {
+	enabled := gifExtensions(opts)
+	if opts.GIFVersion != GIF87a && enabled[ExtensionLoop] && enabled[ExtensionControl] {
+		return encodeAll(w, g, opts)
+	}
+
+	var buf bytes.Buffer
+	if err := encodeAll(&buf, g, opts); err != nil {
+		return err
+	}
+	data, err := filterBlocks(buf.Bytes(), opts.GIFVersion, enabled)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// filterBlocks rewrites an encoded GIF, dropping the extension blocks that are
+// not enabled and setting the version of the header
+func filterBlocks(data []byte, version string, enabled map[string]bool) ([]byte, error) {
+	// Header and logical screen descriptor, followed by the global color table
+	if len(data) < 13 || !bytes.HasPrefix(data, []byte("GIF")) {
+		return nil, fmt.Errorf("invalid GIF header")
+	}
+	pos := 13
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1)
+	}
+	if pos > len(data) {
+		return nil, fmt.Errorf("truncated GIF color table")
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:pos]...)
+	if version == GIF87a {
+		copy(out[3:6], GIF87a)
+	}
+
+	for pos < len(data) {
+		start := pos
+		switch data[pos] {
+		case blockExtension:
+			if pos+2 > len(data) {
+				return nil, fmt.Errorf("truncated GIF extension")
+			}
+			label := data[pos+1]
+			end, err := skipSubBlocks(data, pos+2)
+			if err != nil {
+				return nil, err
+			}
+			pos = end
+			if keepExtension(data[start:end], label, version, enabled) {
+				out = append(out, data[start:end]...)
+			}
+		case blockImage:
+			// Image descriptor, local color table, LZW code size and image data
+			if pos+11 > len(data) {
+				return nil, fmt.Errorf("truncated GIF image descriptor")
+			}
+			pos += 10
+			if data[start+9]&0x80 != 0 {
+				pos += 3 << (data[start+9]&0x07 + 1)
+			}
+			end, err := skipSubBlocks(data, pos+1)
+			if err != nil {
+				return nil, err
+			}
+			pos = end
+			out = append(out, data[start:end]...)
+		case blockTrailer:
+			return append(out, blockTrailer), nil
+		default:
+			return nil, fmt.Errorf("unknown GIF block 0x%02x", data[pos])
+		}
+	}
+	return nil, fmt.Errorf("missing GIF trailer")
+}
+
+// keepExtension reports whether the extension block with the given label is enabled
+func keepExtension(block []byte, label byte, version string, enabled map[string]bool) bool {
+	switch label {
+	case labelControl:
+		return enabled[ExtensionControl]
+	case labelApplication:
+		if bytes.Contains(block, []byte("NETSCAPE2.0")) {
+			return enabled[ExtensionLoop]
+		}
+	}
+	// Other extensions are not written by the encoder, only GIF87a drops them
+	return version != GIF87a
+}
+
+// skipSubBlocks returns the position after the data sub-blocks starting at pos
+func skipSubBlocks(data []byte, pos int) (int, error) {
+	for {
+		if pos >= len(data) {
+			return 0, fmt.Errorf("truncated GIF data sub-blocks")
+		}
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, nil
+		}
+		pos += size
+	}
+}
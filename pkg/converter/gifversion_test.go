@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func TestValidateGIFVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "default", opts: Options{}},
+		{name: "89a with timeline", opts: Options{GIFVersion: GIF89a, Timeline: "0:500"}},
+		{name: "89a without loop", opts: Options{GIFExtensions: []string{ExtensionControl}}},
+		{name: "87a", opts: Options{GIFVersion: GIF87a}},
+		{name: "87a with rotated poster", opts: Options{GIFVersion: GIF87a, PosterFrame: 2, PosterMode: PosterRotate}},
+		{name: "unknown version", opts: Options{GIFVersion: "90a"}, wantErr: true},
+		{name: "unknown extension", opts: Options{GIFExtensions: []string{"comment"}}, wantErr: true},
+		{name: "87a with extensions", opts: Options{GIFVersion: GIF87a, GIFExtensions: []string{ExtensionLoop}}, wantErr: true},
+		{name: "87a with timeline", opts: Options{GIFVersion: GIF87a, Timeline: "0:500"}, wantErr: true},
+		{name: "87a with duplicated poster", opts: Options{GIFVersion: GIF87a, PosterFrame: 2}, wantErr: true},
+		{name: "no control with timeline", opts: Options{GIFExtensions: []string{ExtensionLoop}, Timeline: "0:500"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGIFVersion(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("validateGIFVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodeGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+			image.NewPaletted(image.Rect(0, 0, 4, 4), palette),
+		},
+		Delay: []int{10, 20},
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantHeader string
+		wantLoop   int
+		wantDelay  int
+	}{
+		{name: "89a", opts: Options{}, wantHeader: "GIF89a", wantLoop: 0, wantDelay: 20},
+		{name: "89a without loop", opts: Options{GIFExtensions: []string{ExtensionControl}}, wantHeader: "GIF89a", wantLoop: -1, wantDelay: 20},
+		{name: "89a without control", opts: Options{GIFExtensions: []string{ExtensionLoop}}, wantHeader: "GIF89a", wantLoop: 0, wantDelay: 0},
+		{name: "87a", opts: Options{GIFVersion: GIF87a}, wantHeader: "GIF87a", wantLoop: -1, wantDelay: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeGIF(&buf, g, tt.opts); err != nil {
+				t.Fatalf("encodeGIF() error = %v", err)
+			}
+			if header := string(buf.Bytes()[:6]); header != tt.wantHeader {
+				t.Errorf("header = %q, want %q", header, tt.wantHeader)
+			}
+
+			decoded, err := gif.DecodeAll(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("output does not decode: %v", err)
+			}
+			if len(decoded.Image) != 2 {
+				t.Errorf("got %d frames, want 2", len(decoded.Image))
+			}
+			if decoded.LoopCount != tt.wantLoop {
+				t.Errorf("loop count = %d, want %d", decoded.LoopCount, tt.wantLoop)
+			}
+			if decoded.Delay[1] != tt.wantDelay {
+				t.Errorf("delay = %d, want %d", decoded.Delay[1], tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestFilterBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "not a GIF", data: []byte("PNG")},
+		{name: "truncated color table", data: append([]byte("GIF89a"), 1, 0, 1, 0, 0x87, 0, 0)},
+		{name: "unknown block", data: append([]byte("GIF89a"), 1, 0, 1, 0, 0, 0, 0, 0x42)},
+		{name: "missing trailer", data: append([]byte("GIF89a"), 1, 0, 1, 0, 0, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := filterBlocks(tt.data, GIF87a, nil); err == nil {
+				t.Error("filterBlocks() expected error")
+			}
+		})
+	}
+}
+
+func TestLoopCount(t *testing.T) {
+	tests := []struct {
+		loops int
+		want  int
+	}{
+		{loops: 0, want: 0},
+		{loops: 1, want: -1},
+		{loops: 3, want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := loopCount(tt.loops); got != tt.want {
+			t.Errorf("loopCount(%d) = %d, want %d", tt.loops, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// maxRangeSize is the largest number of values a {first..last} range expands to
+const maxRangeSize = 100000
+
+// braceRange matches a numeric brace range such as {001..100}
+var braceRange = regexp.MustCompile(`\{(-?\d+)\.\.(-?\d+)\}`)
+
+// posixClasses are the POSIX character classes accepted in bracket expressions
+var posixClasses = strings.NewReplacer(
+	"[:digit:]", "0-9",
+	"[:alpha:]", "a-zA-Z",
+	"[:alnum:]", "a-zA-Z0-9",
+	"[:upper:]", "A-Z",
+	"[:lower:]", "a-z",
+	"[:xdigit:]", "0-9a-fA-F",
+	"[:space:]", " \t",
+)
+
+// globPattern prepares a file name glob for matchGlob, expanding numeric
+// ranges ({1..10}, {001..100}) into brace alternatives and POSIX classes
+// ([[:digit:]]) into character ranges
+func globPattern(pattern string) (string, error) {
+	var err error
+	pattern = braceRange.ReplaceAllStringFunc(pattern, func(r string) string {
+		bounds := braceRange.FindStringSubmatch(r)
+		values, rangeErr := expandRange(bounds[1], bounds[2])
+		if rangeErr != nil {
+			err = rangeErr
+			return r
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	})
+	if err != nil {
+		return "", err
+	}
+	return posixClasses.Replace(pattern), nil
+}
+
+// expandRange returns the numbers from first to last, counting down when last
+// is lower. Bounds written with leading zeros pad every value to their width.
+func expandRange(first, last string) ([]string, error) {
+	from, err1 := strconv.Atoi(first)
+	to, err2 := strconv.Atoi(last)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("invalid range {%s..%s}", first, last)
+	}
+	step := 1
+	if to < from {
+		step = -1
+	}
+	if (to-from)*step >= maxRangeSize {
+		return nil, fmt.Errorf("range {%s..%s} has more than %d values", first, last, maxRangeSize)
+	}
+
+	width := 0
+	if isZeroPadded(first) || isZeroPadded(last) {
+		width = max(len(first), len(last))
+	}
+	var values []string
+	for n := from; ; n += step {
+		values = append(values, fmt.Sprintf("%0*d", width, n))
+		if n == to {
+			return values, nil
+		}
+	}
+}
+
+// isZeroPadded reports whether a number is written with leading zeros
+func isZeroPadded(n string) bool {
+	n = strings.TrimPrefix(n, "-")
+	return len(n) > 1 && n[0] == '0'
+}
+
+// matchGlob reports whether a file name matches a pattern prepared by
+// globPattern. Besides * and ?, patterns support {a,b} alternatives and
+// character classes, negated with [!...] or [^...]. An invalid pattern
+// matches nothing.
+func matchGlob(pattern, name string) bool {
+	matched, err := doublestar.Match(pattern, name)
+	return err == nil && matched
+}
@@ -0,0 +1,82 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "no range", pattern: "frame_*.png", want: "frame_*.png"},
+		{name: "range", pattern: "frame_{1..3}.png", want: "frame_{1,2,3}.png"},
+		{name: "padded range", pattern: "frame_{08..11}.png", want: "frame_{08,09,10,11}.png"},
+		{name: "descending range", pattern: "{3..1}", want: "{3,2,1}"},
+		{name: "alternatives", pattern: "{intro,outro}_*.png", want: "{intro,outro}_*.png"},
+		{name: "posix class", pattern: "frame[[:digit:]].png", want: "frame[0-9].png"},
+		{name: "range too large", pattern: "{0..100000}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := globPattern(tt.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("globPattern() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("globPattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandInputPatternGlob(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	testFiles := []string{"intro.png", "outro.png", "credits.png"}
+	for i := 1; i <= 120; i++ {
+		testFiles = append(testFiles, fmt.Sprintf("frame_%03d.png", i))
+	}
+	for _, file := range testFiles {
+		if err := os.WriteFile(filepath.Join(tempDir, file), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", file, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    int
+		wantErr bool
+	}{
+		{name: "numeric range", pattern: "frame_{001..100}.png", want: 100},
+		{name: "alternatives", pattern: "{intro,outro}.png", want: 2},
+		{name: "nested alternatives", pattern: "{intro,frame_{001..005}}.png", want: 6},
+		{name: "negated class", pattern: "[!f]*.png", want: 3},
+		{name: "caret class", pattern: "[^fi]*.png", want: 2},
+		{name: "posix class", pattern: "frame_00[[:digit:]].png", want: 9},
+		{name: "range too large", pattern: "frame_{0..999999}.png", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInputPattern(filepath.Join(tempDir, tt.pattern))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandInputPattern() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ExpandInputPattern() = %v, want %d files", got, tt.want)
+			}
+		})
+	}
+}
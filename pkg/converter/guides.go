@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+// GuideSafeArea is the share of the width and height of a guide inside its
+// safe area, where captions and key content stay clear of the player and
+// platform controls
+const GuideSafeArea = 0.9
+
+// guideColors are the colors of the guides, in order
+var guideColors = []color.RGBA{
+	{255, 0, 255, 255},
+	{0, 255, 255, 255},
+	{255, 255, 0, 255},
+	{0, 255, 0, 255},
+}
+
+// parseGuide parses an aspect ratio guide such as "16:9"
+func parseGuide(guide string) (image.Point, error) {
+	w, h, ok := strings.Cut(guide, ":")
+	if !ok {
+		return image.Point{}, fmt.Errorf("invalid guide %q: expected an aspect ratio such as 16:9, 1:1 or 9:16", guide)
+	}
+	width, errW := strconv.Atoi(strings.TrimSpace(w))
+	height, errH := strconv.Atoi(strings.TrimSpace(h))
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return image.Point{}, fmt.Errorf("invalid guide %q: the width and height of the ratio must be positive integers", guide)
+	}
+	return image.Pt(width, height), nil
+}
+
+// validateGuides checks every guide is a distinct aspect ratio
+func validateGuides(guides []string) error {
+	seen := make(map[image.Point]string)
+	for _, guide := range guides {
+		ratio, err := parseGuide(guide)
+		if err != nil {
+			return err
+		}
+		// 16:9 and 32:18 draw the same guide
+		d := gcd(ratio.X, ratio.Y)
+		reduced := image.Pt(ratio.X/d, ratio.Y/d)
+		if previous, ok := seen[reduced]; ok {
+			return fmt.Errorf("guides %s and %s are the same aspect ratio", previous, guide)
+		}
+		seen[reduced] = guide
+	}
+	return nil
+}
+
+// guideRect returns the largest rectangle of the aspect ratio centered in bounds
+func guideRect(bounds image.Rectangle, ratio image.Point) image.Rectangle {
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*ratio.Y > h*ratio.X {
+		w = h * ratio.X / ratio.Y
+	} else {
+		h = w * ratio.Y / ratio.X
+	}
+	origin := bounds.Min.Add(image.Pt((bounds.Dx()-w)/2, (bounds.Dy()-h)/2))
+	return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(w, h))}
+}
+
+// safeArea returns r shrunk around its center to GuideSafeArea of its size
+func safeArea(r image.Rectangle) image.Rectangle {
+	inset := image.Pt(int(float64(r.Dx())*(1-GuideSafeArea)/2), int(float64(r.Dy())*(1-GuideSafeArea)/2))
+	return image.Rectangle{Min: r.Min.Add(inset), Max: r.Max.Sub(inset)}
+}
+
+// drawGuides returns a copy of img with the outline of every guide, its safe
+// area and its label drawn over it
+func drawGuides(img image.Image, guides []string) (*image.RGBA, error) {
+	dst := toRGBA(img)
+	bounds := dst.Bounds()
+	thickness := max(1, float64(min(bounds.Dx(), bounds.Dy()))/200)
+	labelSize := overlay.CaptionSize(bounds) * 0.75
+
+	for i, guide := range guides {
+		ratio, err := parseGuide(guide)
+		if err != nil {
+			return nil, err
+		}
+		c := guideColors[i%len(guideColors)]
+		r := guideRect(bounds, ratio)
+		safe := safeArea(r)
+
+		overlay.DrawRect(dst, r, 2*thickness, c)
+		overlay.DrawRect(dst, safe, thickness, color.RGBA{c.R / 2, c.G / 2, c.B / 2, 128})
+		style := overlay.CaptionStyle{Foreground: color.RGBA{0, 0, 0, 255}, Background: c}
+		if _, err := overlay.DrawLabel(dst, guide, safe.Min.X, safe.Min.Y, labelSize, style); err != nil {
+			return nil, fmt.Errorf("error drawing guide label: %v", err)
+		}
+	}
+	return dst, nil
+}
+
+// GuidesPath returns the path the guides of outputFile are written to, e.g.
+// demo.preview.gif -> demo.preview.guides.png
+func GuidesPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".guides.png"
+}
+
+// writeGuides writes img with the guides drawn over it to GuidesPath of
+// outputFile, returning the path
+func writeGuides(img image.Image, guides []string, outputFile string) (string, error) {
+	guided, err := drawGuides(img, guides)
+	if err != nil {
+		return "", err
+	}
+
+	path := GuidesPath(outputFile)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("error creating guides: %v", err)
+	}
+	if err := png.Encode(file, guided); err != nil {
+		file.Close()
+		return "", fmt.Errorf("error encoding guides: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("error writing guides: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseGuide(t *testing.T) {
+	tests := []struct {
+		guide   string
+		want    image.Point
+		wantErr bool
+	}{
+		{guide: "16:9", want: image.Pt(16, 9)},
+		{guide: "1:1", want: image.Pt(1, 1)},
+		{guide: "9 : 16", want: image.Pt(9, 16)},
+		{guide: "16x9", wantErr: true},
+		{guide: "0:1", wantErr: true},
+		{guide: "4:-3", wantErr: true},
+		{guide: "1.5:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseGuide(tt.guide)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGuide(%q) error = %v, wantErr %v", tt.guide, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseGuide(%q) = %v, want %v", tt.guide, got, tt.want)
+		}
+	}
+}
+
+func TestGuideRect(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds image.Rectangle
+		ratio  image.Point
+		want   image.Rectangle
+	}{
+		{name: "Square in wide", bounds: image.Rect(0, 0, 160, 90), ratio: image.Pt(1, 1), want: image.Rect(35, 0, 125, 90)},
+		{name: "Vertical in wide", bounds: image.Rect(0, 0, 160, 90), ratio: image.Pt(9, 16), want: image.Rect(55, 0, 105, 90)},
+		{name: "Wide in square", bounds: image.Rect(0, 0, 160, 160), ratio: image.Pt(16, 9), want: image.Rect(0, 35, 160, 125)},
+		{name: "Same ratio", bounds: image.Rect(0, 0, 160, 90), ratio: image.Pt(16, 9), want: image.Rect(0, 0, 160, 90)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guideRect(tt.bounds, tt.ratio); got != tt.want {
+				t.Errorf("guideRect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := safeArea(image.Rect(0, 0, 160, 90)), image.Rect(8, 4, 152, 86); got != want {
+		t.Errorf("safeArea() = %v, want %v", got, want)
+	}
+}
+
+func TestConvertWithGuides(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 160, 90))
+		for y := 0; y < 90; y++ {
+			for x := 0; x < 160; x++ {
+				img.Set(x, y, color.RGBA{40, 40, 40, 255})
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	outputFile := filepath.Join(tempDir, "demo.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{PreviewFrames: 2, Guides: []string{"16:9", "9:16"}})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if want := filepath.Join(tempDir, "demo.preview.guides.png"); report.Guides != want {
+		t.Fatalf("report guides = %q, want %q", report.Guides, want)
+	}
+
+	file, err := os.Open(report.Guides)
+	if err != nil {
+		t.Fatalf("Failed to open guides: %v", err)
+	}
+	defer file.Close()
+	guided, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode guides: %v", err)
+	}
+	if guided.Bounds() != image.Rect(0, 0, 160, 90) {
+		t.Fatalf("guides size = %v, want the frame size", guided.Bounds())
+	}
+	// The 16:9 guide outlines the whole frame, the 9:16 one its center
+	if r, g, b, _ := guided.At(10, 0).RGBA(); r>>8 != 255 || g>>8 != 0 || b>>8 != 255 {
+		t.Errorf("16:9 guide color = %d,%d,%d, want magenta", r>>8, g>>8, b>>8)
+	}
+	if r, g, b, _ := guided.At(55, 45).RGBA(); r>>8 != 0 || g>>8 != 255 || b>>8 != 255 {
+		t.Errorf("9:16 guide color = %d,%d,%d, want cyan", r>>8, g>>8, b>>8)
+	}
+	// The frames themselves are left untouched
+	if got := frames[0].Image.(*image.RGBA).RGBAAt(80, 0); got != (color.RGBA{40, 40, 40, 255}) {
+		t.Errorf("first frame = %v, want it unchanged", got)
+	}
+}
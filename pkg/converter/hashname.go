@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashNameLength is the number of hex digits of the content hash in file names
+const hashNameLength = 6
+
+// HashedPath returns path with a short hash of content inserted before its
+// extension, e.g. demo.gif -> demo.3fa2c1.gif
+func HashedPath(path string, content io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", fmt.Errorf("error hashing output: %v", err)
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + hex.EncodeToString(hash.Sum(nil))[:hashNameLength] + ext, nil
+}
+
+// hashOutputs renames every output of report after its content, for cache
+// busting, writing the embed snippet of outputFile again to reference them
+func hashOutputs(report *Report, outputFile string, opts Options) (*Report, error) {
+	for i := range report.Outputs {
+		output := &report.Outputs[i]
+		file, err := os.Open(output.File)
+		if err != nil {
+			return nil, fmt.Errorf("error hashing output: %v", err)
+		}
+		hashed, err := HashedPath(output.File, file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Rename(output.File, hashed); err != nil {
+			return nil, fmt.Errorf("error renaming output: %v", err)
+		}
+		output.File = hashed
+	}
+	if len(report.Outputs) > 0 {
+		report.OutputFile = report.Outputs[0].File
+	}
+
+	if report.Snippet != "" {
+		absOutputPath, err := filepath.Abs(outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("error getting absolute path: %v", err)
+		}
+		// The snippet embeds the GIFs only
+		var gifs []Output
+		for _, output := range report.Outputs {
+			if output.Format == FormatGIF {
+				gifs = append(gifs, output)
+			}
+		}
+		if report.Snippet, err = writeSnippet(absOutputPath, gifs, opts.EmitSnippet); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,69 @@
+package converter
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestHashedPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{path: "demo.gif", content: "GIF89a", want: `^demo\.[0-9a-f]{6}\.gif$`},
+		{path: "out/demo.v2.webm", content: "webm", want: `^out/demo\.v2\.[0-9a-f]{6}\.webm$`},
+	}
+
+	for _, tt := range tests {
+		got, err := HashedPath(tt.path, strings.NewReader(tt.content))
+		if err != nil {
+			t.Fatalf("HashedPath() error = %v", err)
+		}
+		if !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("HashedPath(%q) = %q, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	a, _ := HashedPath("demo.gif", strings.NewReader("a"))
+	b, _ := HashedPath("demo.gif", strings.NewReader("b"))
+	if a == b {
+		t.Errorf("HashedPath() = %q for different contents", a)
+	}
+}
+
+func TestConvertHashName(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frames := []Frame{{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))}}
+	outputFile := filepath.Join(tempDir, "demo.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{HashName: true, EmitSnippet: SnippetMarkdown})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+
+	if !regexp.MustCompile(`demo\.[0-9a-f]{6}\.gif$`).MatchString(report.OutputFile) {
+		t.Errorf("output = %q, want a hashed name", report.OutputFile)
+	}
+	if _, err := os.Stat(report.OutputFile); err != nil {
+		t.Errorf("hashed output missing: %v", err)
+	}
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Errorf("unhashed output %s should be renamed", outputFile)
+	}
+	snippet, err := os.ReadFile(report.Snippet)
+	if err != nil {
+		t.Fatalf("Failed to read snippet: %v", err)
+	}
+	if !strings.Contains(string(snippet), filepath.Base(report.OutputFile)) {
+		t.Errorf("snippet %q does not reference %s", snippet, filepath.Base(report.OutputFile))
+	}
+}
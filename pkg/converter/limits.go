@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+// InputLimits bounds the resources spent on untrusted inputs. Inputs beyond a
+// limit fail the conversion with a *decode.LimitError. Zero fields keep the
+// limits of decode.DefaultLimits, or no limit when it has none.
+type InputLimits struct {
+	// MaxWidth and MaxHeight bound the size of every input image
+	MaxWidth  int
+	MaxHeight int
+	// MaxFrames bounds the number of input frames
+	MaxFrames int
+	// MaxPixels bounds the pixels of all the input images together, read
+	// from their headers before any is decoded
+	MaxPixels int64
+	// FrameTimeout bounds the time spent reading and decoding each input image
+	FrameTimeout time.Duration
+}
+
+// validateLimits checks the input limits of opts
+func validateLimits(opts Options) error {
+	l := opts.InputLimits
+	if l.MaxWidth < 0 || l.MaxHeight < 0 || l.MaxFrames < 0 || l.MaxPixels < 0 || l.FrameTimeout < 0 {
+		return fmt.Errorf("input limits must not be negative")
+	}
+	return nil
+}
+
+// decodeLimits returns the limits decoding each input image
+func (l InputLimits) decodeLimits() decode.Limits {
+	limits := decode.DefaultLimits
+	if l.MaxWidth > 0 {
+		limits.MaxWidth = l.MaxWidth
+	}
+	if l.MaxHeight > 0 {
+		limits.MaxHeight = l.MaxHeight
+	}
+	if l.FrameTimeout > 0 {
+		limits.Timeout = l.FrameTimeout
+	}
+	return limits
+}
+
+// checkFrameCount checks the number of input frames against the limits
+func (l InputLimits) checkFrameCount(frames int) error {
+	if l.MaxFrames > 0 && frames > l.MaxFrames {
+		return &decode.LimitError{Limit: decode.LimitFrames, Value: int64(frames), Max: int64(l.MaxFrames)}
+	}
+	return nil
+}
+
+// checkInputFiles checks the number of input files and, when MaxPixels is
+// set, the size of every image from its header, before decoding any
+func (l InputLimits) checkInputFiles(inputFiles []string) error {
+	if err := l.checkFrameCount(len(inputFiles)); err != nil {
+		return err
+	}
+	if l.MaxPixels == 0 {
+		return nil
+	}
+
+	var total int64
+	for _, inputFile := range inputFiles {
+		config, err := readFrameConfig(inputFile, l.decodeLimits())
+		if err != nil {
+			return err
+		}
+		total += int64(config.Width) * int64(config.Height)
+		if total > l.MaxPixels {
+			return &decode.LimitError{Limit: decode.LimitPixels, Value: total, Max: l.MaxPixels}
+		}
+	}
+	return nil
+}
+
+// readFrameConfig returns the dimensions of a frame file, checked against
+// limits
+func readFrameConfig(inputFile string, limits decode.Limits) (image.Config, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("error opening file %s: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	format, err := frameFormatOf(inputFile, file)
+	if err != nil {
+		return image.Config{}, err
+	}
+	config, err := format.config(file, limits)
+	if err != nil {
+		return config, decodeError(inputFile, format, err)
+	}
+	return config, nil
+}
+
+// decodeError returns the error of decoding inputFile of the format, naming
+// the file in limit errors so they keep their type
+func decodeError(inputFile string, format frameFormat, err error) error {
+	var limit *decode.LimitError
+	if errors.As(err, &limit) {
+		limit.Input = inputFile
+		return limit
+	}
+	return fmt.Errorf("error decoding %s file %s: %v", format.name, inputFile, err)
+}
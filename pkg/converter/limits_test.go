@@ -0,0 +1,73 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+func TestConvertInputLimits(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Three 20x10 frames
+	var inputFiles []string
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(tempDir, fmt.Sprintf("frame%d.png", i))
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+		if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 20, 10))); err != nil {
+			t.Fatalf("Failed to encode frame: %v", err)
+		}
+		file.Close()
+		inputFiles = append(inputFiles, path)
+	}
+
+	tests := []struct {
+		name      string
+		limits    InputLimits
+		wantLimit string
+		wantInput string
+		wantErr   bool
+	}{
+		{name: "within the limits", limits: InputLimits{MaxWidth: 20, MaxHeight: 10, MaxFrames: 3, MaxPixels: 600}},
+		{name: "too wide", limits: InputLimits{MaxWidth: 19}, wantLimit: decode.LimitWidth, wantInput: inputFiles[0]},
+		{name: "too tall", limits: InputLimits{MaxHeight: 9}, wantLimit: decode.LimitHeight, wantInput: inputFiles[0]},
+		{name: "too many frames", limits: InputLimits{MaxFrames: 2}, wantLimit: decode.LimitFrames},
+		{name: "too many pixels", limits: InputLimits{MaxPixels: 599}, wantLimit: decode.LimitPixels},
+		{name: "too wide before decoding", limits: InputLimits{MaxWidth: 19, MaxPixels: 1000}, wantLimit: decode.LimitWidth, wantInput: inputFiles[0]},
+		{name: "negative", limits: InputLimits{MaxFrames: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert(inputFiles, filepath.Join(tempDir, "out.gif"), Options{InputLimits: tt.limits})
+			var limit *decode.LimitError
+			switch {
+			case tt.wantErr:
+				if err == nil || errors.As(err, &limit) {
+					t.Errorf("Convert() error = %v, want a validation error", err)
+				}
+			case tt.wantLimit == "":
+				if err != nil {
+					t.Errorf("Convert() error = %v", err)
+				}
+			case !errors.As(err, &limit):
+				t.Errorf("Convert() error = %v, want a LimitError", err)
+			case limit.Limit != tt.wantLimit || limit.Input != tt.wantInput:
+				t.Errorf("Convert() error = %+v, want limit %q of %q", limit, tt.wantLimit, tt.wantInput)
+			}
+		})
+	}
+}
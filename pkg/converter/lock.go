@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// lockSuffix is appended to the output path to name its lock file
+const lockSuffix = ".lock"
+
+// errLocked is returned by lockFile when another conversion holds the lock
+var errLocked = errors.New("locked")
+
+// locks tracks the lock files held by running conversions so that ReleaseLocks
+// can remove them when the process is interrupted
+var locks = struct {
+	sync.Mutex
+	files map[string]*os.File
+}{files: make(map[string]*os.File)}
+
+// outputLock is a lock file preventing two conversions from writing the same
+// output. The file is locked by the system as long as it is open, so the lock
+// of a killed conversion is released with it and its leftover file is reused.
+type outputLock struct {
+	path string
+}
+
+// lockOutput locks the lock file of outputFile, failing if another conversion
+// holds it. When the output directory does not exist there is nothing to lock
+// and writing the output reports the error.
+func lockOutput(outputFile string) (*outputLock, error) {
+	path := outputFile + lockSuffix
+	file, err := openLock(path)
+	if errors.Is(err, errLocked) {
+		owner, _ := os.ReadFile(path)
+		return nil, fmt.Errorf("%s is being written by another conversion (%s)", outputFile, strings.TrimSpace(string(owner)))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return &outputLock{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking output: %v", err)
+	}
+
+	host, _ := os.Hostname()
+	if err := file.Truncate(0); err != nil {
+		removeLock(file, path)
+		return nil, fmt.Errorf("error locking output: %v", err)
+	}
+	fmt.Fprintf(file, "pid %d on %s\n", os.Getpid(), host)
+
+	locks.Lock()
+	defer locks.Unlock()
+	locks.files[path] = file
+	return &outputLock{path: path}, nil
+}
+
+// openLock opens and locks the lock file at path, failing with errLocked when
+// another conversion holds it
+func openLock(path string) (*os.File, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+		// The conversion holding the lock before may have removed the file in
+		// between, leaving this one locked but no longer in the directory
+		opened, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if current, err := os.Stat(path); err == nil && os.SameFile(opened, current) {
+			return file, nil
+		}
+		file.Close()
+	}
+}
+
+// release removes the lock file and unlocks it
+func (l *outputLock) release() {
+	if l.path == "" {
+		return
+	}
+	locks.Lock()
+	defer locks.Unlock()
+	if file, ok := locks.files[l.path]; ok {
+		removeLock(file, l.path)
+		delete(locks.files, l.path)
+	}
+}
+
+// ReleaseLocks removes the output lock files of the conversions still running.
+// It is meant to be called before exiting on an interruption.
+func ReleaseLocks() {
+	locks.Lock()
+	defer locks.Unlock()
+	for path, file := range locks.files {
+		removeLock(file, path)
+		delete(locks.files, path)
+	}
+}
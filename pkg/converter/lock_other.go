@@ -0,0 +1,24 @@
+//go:build !(darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || windows)
+
+package converter
+
+import "os"
+
+// lockFile reports a lock file naming its owner as locked, lacking file locks
+// on this system. The file of a killed conversion has to be removed by hand.
+func lockFile(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > 0 {
+		return errLocked
+	}
+	return nil
+}
+
+// removeLock removes the lock file at path
+func removeLock(file *os.File, path string) {
+	os.Remove(path)
+	file.Close()
+}
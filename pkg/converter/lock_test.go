@@ -0,0 +1,150 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockOutput(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputFile := filepath.Join(tempDir, "out.gif")
+	lock, err := lockOutput(outputFile)
+	if err != nil {
+		t.Fatalf("lockOutput() error = %v", err)
+	}
+
+	// A second conversion of the same output fails naming the owner
+	_, err = lockOutput(outputFile)
+	if err == nil {
+		t.Fatal("lockOutput() expected error for a locked output")
+	}
+	if want := fmt.Sprintf("pid %d", os.Getpid()); !strings.Contains(err.Error(), want) {
+		t.Errorf("lockOutput() error = %v, want it to mention %s", err, want)
+	}
+
+	lock.release()
+	if lock, err = lockOutput(outputFile); err != nil {
+		t.Fatalf("lockOutput() after release error = %v", err)
+	}
+
+	// Interruptions remove the lock files
+	ReleaseLocks()
+	if _, err := os.Stat(outputFile + lockSuffix); !os.IsNotExist(err) {
+		t.Errorf("lock file not removed: %v", err)
+	}
+	lock.release()
+
+	// The file left by a killed conversion is no longer locked
+	if err := os.WriteFile(outputFile+lockSuffix, []byte("pid 1 on ci\n"), 0644); err != nil {
+		t.Fatalf("Failed to write lock file: %v", err)
+	}
+	if lock, err = lockOutput(outputFile); err != nil {
+		t.Fatalf("lockOutput() over a leftover lock file error = %v", err)
+	}
+	owner, err := os.ReadFile(outputFile + lockSuffix)
+	if err != nil {
+		t.Fatalf("Failed to read lock file: %v", err)
+	}
+	if want := fmt.Sprintf("pid %d", os.Getpid()); !strings.HasPrefix(string(owner), want) {
+		t.Errorf("lock file = %q, want it to name %s", owner, want)
+	}
+	lock.release()
+
+	// Missing directories are reported when writing the output
+	missing, err := lockOutput(filepath.Join(tempDir, "missing", "out.gif"))
+	if err != nil {
+		t.Fatalf("lockOutput() in a missing directory error = %v", err)
+	}
+	missing.release()
+}
+
+// TestLockKilledHolder kills a process holding a lock, which another
+// conversion can then take
+func TestLockKilledHolder(t *testing.T) {
+	if path := os.Getenv("GO_TOGIF_LOCK_HOLDER"); path != "" {
+		if _, err := lockOutput(path); err != nil {
+			t.Fatalf("lockOutput() error = %v", err)
+		}
+		fmt.Println("locked")
+		time.Sleep(time.Minute)
+		return
+	}
+
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	outputFile := filepath.Join(tempDir, "out.gif")
+
+	holder := exec.Command(os.Args[0], "-test.run=^TestLockKilledHolder$")
+	holder.Env = append(os.Environ(), "GO_TOGIF_LOCK_HOLDER="+outputFile)
+	stdout, err := holder.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe() error = %v", err)
+	}
+	if err := holder.Start(); err != nil {
+		t.Fatalf("Failed to start the holder: %v", err)
+	}
+	defer holder.Process.Kill()
+	if line, _ := bufio.NewReader(stdout).ReadString('\n'); line != "locked\n" {
+		t.Fatalf("holder printed %q, want locked", line)
+	}
+
+	if _, err := lockOutput(outputFile); err == nil {
+		t.Fatal("lockOutput() expected error for an output locked by another process")
+	}
+	holder.Process.Kill()
+	holder.Wait()
+
+	if _, err := os.Stat(outputFile + lockSuffix); err != nil {
+		t.Fatalf("lock file of the killed holder: %v", err)
+	}
+	lock, err := lockOutput(outputFile)
+	if err != nil {
+		t.Fatalf("lockOutput() after the holder was killed error = %v", err)
+	}
+	lock.release()
+}
+
+func TestConvertLocked(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 2, 8, 8)
+	outputFile := filepath.Join(tempDir, "out.gif")
+	lock, err := lockOutput(outputFile)
+	if err != nil {
+		t.Fatalf("lockOutput() error = %v", err)
+	}
+
+	if _, err := Convert(inputFiles, outputFile, Options{}); err == nil {
+		t.Fatal("Convert() expected error for a locked output")
+	}
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Errorf("locked output was written: %v", err)
+	}
+
+	// The lock is released after a conversion
+	lock.release()
+	if _, err := Convert(inputFiles, outputFile, Options{}); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if _, err := os.Stat(outputFile + lockSuffix); !os.IsNotExist(err) {
+		t.Errorf("lock file left after the conversion: %v", err)
+	}
+}
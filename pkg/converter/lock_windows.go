@@ -0,0 +1,27 @@
+package converter
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes an exclusive lock of file, released when it is closed. The
+// byte locked is far past the owner written in the file, which other
+// conversions can then still read.
+func lockFile(file *os.File) error {
+	err := windows.LockFileEx(windows.Handle(file.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY,
+		0, 1, 0, &windows.Overlapped{OffsetHigh: 1})
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// removeLock unlocks the file at path then removes it. Open files cannot be
+// removed, so the file stays while another conversion is about to lock it.
+func removeLock(file *os.File, path string) {
+	file.Close()
+	os.Remove(path)
+}
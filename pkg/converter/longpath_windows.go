@@ -0,0 +1,26 @@
+package converter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath is the longest directory path Windows APIs accept without the
+// \\?\ prefix, MAX_PATH minus room for an 8.3 file name
+const maxShortPath = 248
+
+// longPath returns path with the \\?\ prefix when it is too long for the
+// Windows APIs without it. UNC paths (\\server\share) become \\?\UNC\server\share.
+func longPath(path string) string {
+	if len(path) < maxShortPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
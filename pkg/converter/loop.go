@@ -0,0 +1,150 @@
+package converter
+
+import (
+	"image"
+
+	"github.com/jparrill/go-togif/pkg/quality"
+)
+
+const (
+	// loopThumbWidth is the width frames are compared at when searching for
+	// a loop point
+	loopThumbWidth = 64
+	// cycleTolerance is how much larger than the smallest one the difference
+	// of the frames a period apart may be, so that the shortest cycle is
+	// picked rather than a multiple of it
+	cycleTolerance = 1.25
+	// cycleContrast is the largest fraction of the mean difference of
+	// consecutive frames the frames a period apart may differ by, so that
+	// random or steadily moving content is not taken for a short cycle
+	cycleContrast = 0.5
+)
+
+// loopThumbnail returns frame i of src scaled down to be compared quickly
+func loopThumbnail(src source, i int) (image.Image, error) {
+	img, err := src.load(i)
+	if err != nil {
+		return nil, err
+	}
+	bounds := img.Bounds()
+	width := min(bounds.Dx(), loopThumbWidth)
+	size := image.Rect(0, 0, width, max(1, bounds.Dy()*width/bounds.Dx()))
+	return resizeTo(img, size, ScalerSmooth), nil
+}
+
+// findLoopPoint returns the frame among the last window frames of [first,
+// last) looking the most like the first one: cutting the sequence right
+// before it, the loop jumps back to the first frame as if it were that one.
+// Ties keep the later frame, and last is returned when there is no candidate.
+func findLoopPoint(src source, first, last, window int) (int, error) {
+	if last-first < 3 || window <= 0 {
+		return last, nil
+	}
+
+	start, err := loopThumbnail(src, first)
+	if err != nil {
+		return 0, err
+	}
+
+	// Keep at least two frames
+	best, bestMSE := last, 0.0
+	for j := max(first+2, last-window); j < last; j++ {
+		img, err := loopThumbnail(src, j)
+		if err != nil {
+			return 0, err
+		}
+		if mse := quality.MSE(img, start); best == last || mse <= bestMSE {
+			best, bestMSE = j, mse
+		}
+	}
+	return best, nil
+}
+
+// findCycle finds the shortest cycle of the periodic content of the frames
+// [first, last), returning the frame it starts at and its length. The period
+// is the shortest whose frames differ from the ones a period later about as
+// little as for the best one, and the cycle starts at the frame differing the
+// least from the one a period later, the earliest on ties, so that looping
+// from its last frame back to its first one is seamless. ok is false with
+// fewer than 4 frames, too few for two cycles, and when the frames a period
+// apart differ about as much as consecutive ones, as nothing repeats.
+func findCycle(src source, first, last int) (start, period int, ok bool, err error) {
+	if last-first < 4 {
+		return first, last - first, false, nil
+	}
+
+	thumbs := make([]image.Image, last-first)
+	for i := range thumbs {
+		if thumbs[i], err = loopThumbnail(src, first+i); err != nil {
+			return 0, 0, false, err
+		}
+	}
+
+	// Score every period by the mean difference of the frames a period apart,
+	// at least two cycles being needed to tell one
+	scores := make([]float64, len(thumbs))
+	best := -1
+	for p := 2; p <= len(thumbs)/2; p++ {
+		for i := 0; i+p < len(thumbs); i++ {
+			scores[p] += quality.MSE(thumbs[i], thumbs[i+p])
+		}
+		scores[p] /= float64(len(thumbs) - p)
+		if best < 0 || scores[p] < scores[best] {
+			best = p
+		}
+	}
+
+	// Periodic content repeats far more closely than consecutive frames match
+	consecutive := 0.0
+	for i := 0; i+1 < len(thumbs); i++ {
+		consecutive += quality.MSE(thumbs[i], thumbs[i+1])
+	}
+	consecutive /= float64(len(thumbs) - 1)
+	if scores[best] >= consecutive*cycleContrast {
+		return first, last - first, false, nil
+	}
+
+	period = best
+	for p := 2; p < best; p++ {
+		if scores[p] <= scores[best]*cycleTolerance+1 {
+			period = p
+			break
+		}
+	}
+
+	// Start where the seam is the least visible
+	start, seam := 0, 0.0
+	for i := 0; i+period < len(thumbs); i++ {
+		if mse := quality.MSE(thumbs[i], thumbs[i+period]); i == 0 || mse < seam {
+			start, seam = i, mse
+		}
+	}
+	return first + start, period, true, nil
+}
+
+// smoothLoop crossfades the last n frames into the first one, each blended
+// with it a bit more than the previous one, so the loop closes without a
+// visible jump. The first frame weighs k/(n+1) in the kth of them.
+func smoothLoop(frames []image.Image, n int, keep func(image.Image) (image.Image, error)) error {
+	n = min(n, len(frames)-1)
+	if n <= 0 {
+		return nil
+	}
+
+	start := toRGBA(loaded(frames[0]))
+	for k := 1; k <= n; k++ {
+		i := len(frames) - n - 1 + k
+		blended := toRGBA(loaded(frames[i]))
+		if blended.Rect != start.Rect {
+			continue
+		}
+		for p := range blended.Pix {
+			blended.Pix[p] = uint8((int(blended.Pix[p])*(n+1-k) + int(start.Pix[p])*k + (n+1)/2) / (n + 1))
+		}
+		var err error
+		if frames[i], err = keep(blended); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package converter
+
+import (
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// grayFrame returns a frame of a single gray level
+func grayFrame(level uint8) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for p := range img.Pix {
+		img.Pix[p] = level
+		if p%4 == 3 {
+			img.Pix[p] = 255
+		}
+	}
+	return img
+}
+
+func TestFindLoopPoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []uint8
+		window int
+		want   int
+	}{
+		{name: "Closest to the first frame", levels: []uint8{0, 100, 200, 150, 10, 120}, window: 3, want: 4},
+		{name: "Window past the first frames", levels: []uint8{0, 20, 200, 150, 60, 120}, window: 10, want: 4},
+		{name: "Ties keep the later frame", levels: []uint8{0, 50, 0, 80, 0}, window: 3, want: 4},
+		{name: "Too few frames", levels: []uint8{0, 50}, window: 3, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := source{load: func(i int) (image.Image, error) { return grayFrame(tt.levels[i]), nil }}
+			got, err := findLoopPoint(src, 0, len(tt.levels), tt.window)
+			if err != nil {
+				t.Fatalf("findLoopPoint() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("findLoopPoint() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		levels     []uint8
+		wantStart  int
+		wantPeriod int
+		wantOK     bool
+	}{
+		{
+			name:   "Cycle after a lead-in",
+			levels: []uint8{255, 5, 10, 80, 160, 240, 10, 80, 160, 240, 10, 80},
+			// Two cycles are as seamless, the shortest one is kept
+			wantStart: 2, wantPeriod: 4, wantOK: true,
+		},
+		{
+			name:   "Noisy cycle",
+			levels: []uint8{0, 100, 200, 2, 98, 203, 1, 101, 199},
+			// Frame 3 looks the most like the one a cycle later
+			wantStart: 3, wantPeriod: 3, wantOK: true,
+		},
+		{name: "Too few frames", levels: []uint8{0, 50, 0}, wantStart: 0, wantPeriod: 3},
+		// Nothing repeats, the frames are kept whole
+		{name: "Monotonic frames", levels: []uint8{0, 20, 40, 60, 80, 100, 120, 140, 160, 180}, wantStart: 0, wantPeriod: 10},
+		{name: "Still frames", levels: []uint8{90, 90, 90, 90, 90, 90}, wantStart: 0, wantPeriod: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := source{load: func(i int) (image.Image, error) { return grayFrame(tt.levels[i]), nil }}
+			start, period, ok, err := findCycle(src, 0, len(tt.levels))
+			if err != nil {
+				t.Fatalf("findCycle() error = %v", err)
+			}
+			if start != tt.wantStart || period != tt.wantPeriod || ok != tt.wantOK {
+				t.Errorf("findCycle() = %d, %d, %v, want %d, %d, %v", start, period, ok, tt.wantStart, tt.wantPeriod, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSmoothLoop(t *testing.T) {
+	keep := func(img image.Image) (image.Image, error) { return img, nil }
+
+	tests := []struct {
+		name string
+		n    int
+		// want is the gray level of every frame
+		want []uint8
+	}{
+		{name: "disabled", n: 0, want: []uint8{255, 0, 0, 0, 0}},
+		{name: "two frames", n: 2, want: []uint8{255, 0, 0, 85, 170}},
+		{name: "more frames than available", n: 10, want: []uint8{255, 51, 102, 153, 204}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := []image.Image{grayFrame(255), grayFrame(0), grayFrame(0), grayFrame(0), grayFrame(0)}
+			if err := smoothLoop(frames, tt.n, keep); err != nil {
+				t.Fatalf("smoothLoop() error = %v", err)
+			}
+			var got []uint8
+			for _, frame := range frames {
+				got = append(got, frame.(*image.RGBA).RGBAAt(0, 0).R)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("smoothLoop() levels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWithLoopSearch(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for _, level := range []uint8{0, 60, 120, 180, 10, 200, 250} {
+		frames = append(frames, Frame{Image: grayFrame(level), Delay: 100 * time.Millisecond})
+	}
+
+	outputFile := filepath.Join(tempDir, "loop.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{LoopSearch: 4, SmoothLoop: 1})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Frames != 4 {
+		t.Errorf("report frames = %d, want 4, cut before frame 4", report.Frames)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Kind != WarningSkippedFrame || !slices.Equal(report.Warnings[0].Frames, []int{4, 5, 6}) {
+		t.Errorf("report warnings = %v, want frames 4-6 skipped", report.Warnings)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	// The last frame is halfway to the first one
+	if r, _, _, _ := g.Image[3].At(0, 0).RGBA(); r>>8 != 90 {
+		t.Errorf("last frame level = %d, want 90", r>>8)
+	}
+}
+
+func TestConvertWithFindLoop(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for _, level := range []uint8{255, 5, 10, 80, 160, 240, 10, 80, 160, 240, 10, 80} {
+		frames = append(frames, Frame{Image: grayFrame(level), Delay: 100 * time.Millisecond})
+	}
+
+	report, err := ConvertFrames(frames, filepath.Join(tempDir, "spinner.gif"), Options{FindLoop: true})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Frames != 4 {
+		t.Errorf("report frames = %d, want one cycle of 4", report.Frames)
+	}
+	if len(report.Warnings) != 1 || !slices.Equal(report.Warnings[0].Frames, []int{0, 1, 6, 7, 8, 9, 10, 11}) {
+		t.Errorf("report warnings = %v, want frames 0, 1 and 6-11 skipped", report.Warnings)
+	}
+}
+
+func TestConvertWithFindLoopWithoutCycle(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Frames of random noise, no two alike
+	var frames []Frame
+	seed := uint32(7)
+	for i := 0; i < 12; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+		for p := range img.Pix {
+			seed = seed*1664525 + 1013904223
+			img.Pix[p] = uint8(seed >> 24)
+			if p%4 == 3 {
+				img.Pix[p] = 255
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	report, err := ConvertFrames(frames, filepath.Join(tempDir, "noise.gif"), Options{FindLoop: true})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Frames != len(frames) {
+		t.Errorf("report frames = %d, want all %d kept", report.Frames, len(frames))
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Kind != WarningNoCycle {
+		t.Errorf("report warnings = %v, want a %s warning", report.Warnings, WarningNoCycle)
+	}
+}
@@ -0,0 +1,161 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxLUTSize is the largest number of entries per axis of a 3D LUT
+const maxLUTSize = 256
+
+// colorLUT is a 3D color lookup table read from an Adobe/Resolve .cube file
+type colorLUT struct {
+	// size is the number of entries per axis
+	size int
+	// domainMin and domainMax are the input range of every channel
+	domainMin, domainMax [3]float64
+	// table holds the output colors, red changing fastest, then green and blue
+	table [][3]float64
+}
+
+// loadCubeLUT reads a 3D LUT from a .cube file
+func loadCubeLUT(path string) (*colorLUT, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening LUT %s: %v", path, err)
+	}
+	defer file.Close()
+
+	lut := &colorLUT{domainMax: [3]float64{1, 1, 1}}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "TITLE", "LUT_3D_INPUT_RANGE":
+			// Informative only
+		case "LUT_1D_SIZE":
+			return nil, fmt.Errorf("LUT %s: 1D LUTs are not supported", path)
+		case "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("LUT %s: invalid line %d", path, line)
+			}
+			if lut.size, err = strconv.Atoi(fields[1]); err != nil || lut.size < 2 || lut.size > maxLUTSize {
+				return nil, fmt.Errorf("LUT %s: invalid size %q, expected 2 to %d", path, fields[1], maxLUTSize)
+			}
+		case "DOMAIN_MIN", "DOMAIN_MAX":
+			values, err := parseLUTValues(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("LUT %s: invalid line %d: %v", path, line, err)
+			}
+			if fields[0] == "DOMAIN_MIN" {
+				lut.domainMin = values
+			} else {
+				lut.domainMax = values
+			}
+		default:
+			values, err := parseLUTValues(fields)
+			if err != nil {
+				return nil, fmt.Errorf("LUT %s: invalid line %d: %v", path, line, err)
+			}
+			lut.table = append(lut.table, values)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading LUT %s: %v", path, err)
+	}
+
+	if lut.size == 0 {
+		return nil, fmt.Errorf("LUT %s: missing LUT_3D_SIZE", path)
+	}
+	if want := lut.size * lut.size * lut.size; len(lut.table) != want {
+		return nil, fmt.Errorf("LUT %s: got %d entries, want %d for size %d", path, len(lut.table), want, lut.size)
+	}
+	for c := range lut.domainMin {
+		if lut.domainMax[c] <= lut.domainMin[c] {
+			return nil, fmt.Errorf("LUT %s: empty domain", path)
+		}
+	}
+	return lut, nil
+}
+
+// parseLUTValues parses the three numbers of a color
+func parseLUTValues(fields []string) ([3]float64, error) {
+	var values [3]float64
+	if len(fields) != 3 {
+		return values, fmt.Errorf("expected 3 values, got %d", len(fields))
+	}
+	for i, field := range fields {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return values, fmt.Errorf("invalid value %q", field)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// apply returns a copy of img graded through the LUT, interpolating
+// trilinearly between its entries
+func (l *colorLUT) apply(img image.Image) *image.RGBA {
+	rgba := toRGBA(img)
+	bounds := rgba.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		pix := rgba.Pix[rgba.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			p := pix[4*x : 4*x+4]
+			a := p[3]
+			if a == 0 {
+				continue
+			}
+			// The LUT applies to straight colors, RGBA stores them premultiplied
+			var in [3]float64
+			for c := range in {
+				in[c] = float64(p[c]) / float64(a)
+			}
+			out := l.lookup(in)
+			for c := range out {
+				p[c] = uint8(max(0, min(1, out[c]))*float64(a) + 0.5)
+			}
+		}
+	}
+	return rgba
+}
+
+// lookup returns the graded color of a color with channels from 0 to 1
+func (l *colorLUT) lookup(in [3]float64) [3]float64 {
+	var index [3]int
+	var frac [3]float64
+	for c := range in {
+		v := (in[c] - l.domainMin[c]) / (l.domainMax[c] - l.domainMin[c]) * float64(l.size-1)
+		v = max(0, min(float64(l.size-1), v))
+		index[c] = min(int(v), l.size-2)
+		frac[c] = v - float64(index[c])
+	}
+
+	var out [3]float64
+	for corner := 0; corner < 8; corner++ {
+		weight := 1.0
+		var at [3]int
+		for c := range at {
+			if corner&(1<<c) != 0 {
+				at[c] = index[c] + 1
+				weight *= frac[c]
+			} else {
+				at[c] = index[c]
+				weight *= 1 - frac[c]
+			}
+		}
+		entry := l.table[at[0]+at[1]*l.size+at[2]*l.size*l.size]
+		for c := range out {
+			out[c] += weight * entry[c]
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cubeFile returns a .cube LUT of the given size mapping colors through f
+func cubeFile(size int, f func(r, g, b float64) (float64, float64, float64)) string {
+	var sb strings.Builder
+	sb.WriteString("# Generated\nTITLE \"test\"\n")
+	fmt.Fprintf(&sb, "LUT_3D_SIZE %d\n", size)
+	for b := 0; b < size; b++ {
+		for g := 0; g < size; g++ {
+			for r := 0; r < size; r++ {
+				or, og, ob := f(float64(r)/float64(size-1), float64(g)/float64(size-1), float64(b)/float64(size-1))
+				fmt.Fprintf(&sb, "%f %f %f\n", or, og, ob)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func TestLoadCubeLUT(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	identity := func(r, g, b float64) (float64, float64, float64) { return r, g, b }
+	invert := func(r, g, b float64) (float64, float64, float64) { return 1 - r, 1 - g, 1 - b }
+
+	tests := []struct {
+		name    string
+		content string
+		in      color.RGBA
+		want    color.RGBA
+		wantErr bool
+	}{
+		{name: "identity", content: cubeFile(2, identity), in: color.RGBA{10, 128, 250, 255}, want: color.RGBA{10, 128, 250, 255}},
+		{name: "invert", content: cubeFile(17, invert), in: color.RGBA{10, 128, 250, 255}, want: color.RGBA{245, 127, 5, 255}},
+		{name: "premultiplied colors", content: cubeFile(2, invert), in: color.RGBA{0, 64, 128, 128}, want: color.RGBA{128, 64, 0, 128}},
+		{name: "domain", content: "DOMAIN_MIN 0 0 0\nDOMAIN_MAX 2 2 2\n" + cubeFile(2, identity), in: color.RGBA{200, 200, 200, 255}, want: color.RGBA{100, 100, 100, 255}},
+		{name: "missing size", content: "0 0 0\n1 1 1\n", wantErr: true},
+		{name: "missing entries", content: "LUT_3D_SIZE 2\n0 0 0\n", wantErr: true},
+		{name: "invalid value", content: strings.Replace(cubeFile(2, identity), "1.000000", "one", 1), wantErr: true},
+		{name: "1D LUT", content: "LUT_1D_SIZE 2\n0 0 0\n1 1 1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, strings.ReplaceAll(tt.name, " ", "_")+".cube")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write LUT: %v", err)
+			}
+			lut, err := loadCubeLUT(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadCubeLUT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+			img.SetRGBA(0, 0, tt.in)
+			got := lut.apply(img).RGBAAt(0, 0)
+			for c, v := range []uint8{got.R, got.G, got.B, got.A} {
+				want := []uint8{tt.want.R, tt.want.G, tt.want.B, tt.want.A}[c]
+				if absDiff(v, want) > 1 {
+					t.Errorf("apply(%v) = %v, want %v", tt.in, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
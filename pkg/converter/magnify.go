@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// magnifyThreshold is the smallest change of a channel counted as
+	// activity, leaving out compression noise and dithering
+	magnifyThreshold = 24
+	// magnifyInsetShare is the share of the width and height of the frames
+	// the magnifier inset covers
+	magnifyInsetShare = 1.0 / 3
+)
+
+// magnifyColor outlines the inset and the region it magnifies
+var magnifyColor = color.RGBA{255, 200, 0, 255}
+
+// changeRect returns the bounding box of the pixels of current differing
+// from previous by at least magnifyThreshold, empty when none does
+func changeRect(current, previous *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	bounds := current.Bounds()
+	if previous.Bounds() != bounds {
+		return r
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c, p := current.RGBAAt(x, y), previous.RGBAAt(x, y)
+			if max(absDiff(c.R, p.R), absDiff(c.G, p.G), absDiff(c.B, p.B), absDiff(c.A, p.A)) >= magnifyThreshold {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+// changeRegions returns where the activity is on every frame: the region
+// changed since the previous frame, or the last one seen when nothing
+// changed, the frames before the first change showing it too. It returns nil
+// when the frames never change.
+func changeRegions(frames []image.Image) []image.Rectangle {
+	regions := make([]image.Rectangle, len(frames))
+	first := -1
+	previous := toRGBA(loaded(frames[0]))
+	for i := 1; i < len(frames); i++ {
+		current := toRGBA(loaded(frames[i]))
+		regions[i] = changeRect(current, previous)
+		if regions[i].Empty() {
+			regions[i] = regions[i-1]
+		} else if first < 0 {
+			first = i
+		}
+		previous = current
+	}
+	if first < 0 {
+		return nil
+	}
+	for i := 0; i < first; i++ {
+		regions[i] = regions[first]
+	}
+	return regions
+}
+
+// magnifiedRect returns the rectangle of the given size centered on change,
+// moved inside bounds
+func magnifiedRect(bounds, change image.Rectangle, size image.Point) image.Rectangle {
+	center := change.Min.Add(change.Max).Div(2)
+	origin := center.Sub(size.Div(2))
+	origin.X = max(bounds.Min.X, min(origin.X, bounds.Max.X-size.X))
+	origin.Y = max(bounds.Min.Y, min(origin.Y, bounds.Max.Y-size.Y))
+	return image.Rectangle{Min: origin, Max: origin.Add(size)}
+}
+
+// magnifyChanges draws in a corner of every frame an inset showing the area
+// around where it changed zoomed zoom times, with pixels kept sharp, and
+// outlines that area on the frame. Frames that never change are left alone.
+func magnifyChanges(frames []image.Image, zoom int, corner string, keep func(image.Image) (image.Image, error)) error {
+	if zoom < 2 || len(frames) < 2 {
+		return nil
+	}
+	regions := changeRegions(frames)
+	if regions == nil {
+		return nil
+	}
+
+	for i := range frames {
+		dst := toRGBA(loaded(frames[i]))
+		bounds := dst.Bounds()
+		source := image.Pt(max(1, int(float64(bounds.Dx())*magnifyInsetShare)/zoom), max(1, int(float64(bounds.Dy())*magnifyInsetShare)/zoom))
+		area := magnifiedRect(bounds, regions[i], source)
+		thickness := max(1, float64(min(bounds.Dx(), bounds.Dy()))/200)
+
+		// Copy the area before outlining it
+		inset := image.NewRGBA(image.Rectangle{Max: source.Mul(zoom)})
+		xdraw.NearestNeighbor.Scale(inset, inset.Bounds(), dst, area, xdraw.Src, nil)
+		overlay.DrawRect(dst, area, thickness, magnifyColor)
+
+		margin := int(overlay.CaptionSize(bounds) / 2)
+		r := inset.Bounds().Add(stampOrigin(bounds, inset.Bounds().Size(), corner, margin))
+		xdraw.Draw(dst, r, inset, image.Point{}, xdraw.Src)
+		overlay.DrawRect(dst, r.Inset(-int(2*thickness)), 2*thickness, magnifyColor)
+
+		var err error
+		if frames[i], err = keep(dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
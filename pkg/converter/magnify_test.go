@@ -0,0 +1,123 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+// dotFrame returns a black 120x60 frame with a white pixel at dot, or none
+// when dot is outside it
+func dotFrame(dot image.Point) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 120, 60))
+	for p := 3; p < len(img.Pix); p += 4 {
+		img.Pix[p] = 255
+	}
+	img.Set(dot.X, dot.Y, color.White)
+	return img
+}
+
+func TestChangeRegions(t *testing.T) {
+	none := image.Pt(-1, -1)
+	tests := []struct {
+		name string
+		dots []image.Point
+		want []image.Rectangle
+	}{
+		{name: "no change", dots: []image.Point{none, none, none}, want: nil},
+		{
+			name: "held and shown before",
+			dots: []image.Point{none, none, image.Pt(5, 6), image.Pt(5, 6)},
+			want: []image.Rectangle{image.Rect(5, 6, 6, 7), image.Rect(5, 6, 6, 7), image.Rect(5, 6, 6, 7), image.Rect(5, 6, 6, 7)},
+		},
+		{
+			name: "moving dot",
+			dots: []image.Point{image.Pt(1, 1), image.Pt(4, 2)},
+			want: []image.Rectangle{image.Rect(1, 1, 5, 3), image.Rect(1, 1, 5, 3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var frames []image.Image
+			for _, dot := range tt.dots {
+				frames = append(frames, dotFrame(dot))
+			}
+			got := changeRegions(frames)
+			if len(got) != len(tt.want) {
+				t.Fatalf("changeRegions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("changeRegions()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMagnifiedRect(t *testing.T) {
+	bounds := image.Rect(0, 0, 60, 30)
+	size := image.Pt(10, 4)
+	tests := []struct {
+		name   string
+		change image.Rectangle
+		want   image.Rectangle
+	}{
+		{name: "centered", change: image.Rect(30, 15, 32, 17), want: image.Rect(26, 14, 36, 18)},
+		{name: "top left corner", change: image.Rect(0, 0, 1, 1), want: image.Rect(0, 0, 10, 4)},
+		{name: "bottom right corner", change: image.Rect(59, 29, 60, 30), want: image.Rect(50, 26, 60, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := magnifiedRect(bounds, tt.change, size); got != tt.want {
+				t.Errorf("magnifiedRect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMagnifyChanges(t *testing.T) {
+	keep := func(img image.Image) (image.Image, error) { return img, nil }
+
+	t.Run("inset", func(t *testing.T) {
+		frames := []image.Image{dotFrame(image.Pt(-1, -1)), dotFrame(image.Pt(90, 45))}
+		if err := magnifyChanges(frames, 2, CornerTopLeft, keep); err != nil {
+			t.Fatalf("magnifyChanges() error = %v", err)
+		}
+
+		// The 40x20 inset shows the 20x10 area around the dot, from (80, 40)
+		last := frames[1].(*image.RGBA)
+		margin := int(overlay.CaptionSize(last.Bounds()) / 2)
+		for _, p := range []image.Point{{20, 10}, {21, 10}, {20, 11}, {21, 11}} {
+			if c := last.RGBAAt(margin+p.X, margin+p.Y); c != (color.RGBA{255, 255, 255, 255}) {
+				t.Errorf("inset pixel %v = %v, want the zoomed white dot", p, c)
+			}
+		}
+		if c := last.RGBAAt(margin+2, margin+2); c != (color.RGBA{0, 0, 0, 255}) {
+			t.Errorf("inset pixel (2, 2) = %v, want black", c)
+		}
+		// The magnified area is outlined
+		if c := last.RGBAAt(80, 40); c != magnifyColor {
+			t.Errorf("outline pixel = %v, want %v", c, magnifyColor)
+		}
+		// The first frame shows the region of the first change
+		if c := frames[0].(*image.RGBA).RGBAAt(80, 40); c != magnifyColor {
+			t.Errorf("first frame outline pixel = %v, want %v", c, magnifyColor)
+		}
+	})
+
+	t.Run("no change", func(t *testing.T) {
+		frame := dotFrame(image.Pt(3, 3))
+		frames := []image.Image{frame, dotFrame(image.Pt(3, 3))}
+		if err := magnifyChanges(frames, 2, "", keep); err != nil {
+			t.Fatalf("magnifyChanges() error = %v", err)
+		}
+		if frames[0] != image.Image(frame) {
+			t.Errorf("magnifyChanges() replaced a frame that never changes")
+		}
+	})
+}
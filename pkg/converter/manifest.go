@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestPrefix marks an input read from a manifest file, as in --input @frames.txt
+const ManifestPrefix = "@"
+
+// ManifestEntry is an input file listed in a manifest
+type ManifestEntry struct {
+	Path string
+	// Delay is how long the frame is shown, Options.Delay when zero
+	Delay time.Duration
+}
+
+// IsManifest reports whether an input names a manifest file rather than a pattern
+func IsManifest(input string) bool {
+	return strings.HasPrefix(input, ManifestPrefix) && len(input) > len(ManifestPrefix)
+}
+
+// ReadManifest reads the input files listed in a manifest, one path per line
+// in the order they are played. A path may be followed by a delay overriding
+// Options.Delay ("frame1.png 2s"). Blank lines and lines starting with # are
+// ignored, and relative paths are relative to the manifest.
+func ReadManifest(path string) ([]ManifestEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening manifest: %v", err)
+	}
+	defer file.Close()
+
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry, err := parseManifestLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest line %d: %v", line, err)
+		}
+		if !filepath.IsAbs(entry.Path) {
+			entry.Path = filepath.Join(filepath.Dir(path), entry.Path)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading manifest: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no input files listed in manifest %s", path)
+	}
+	return entries, nil
+}
+
+// parseManifestLine splits a manifest line into its path and optional delay.
+// The last word is the delay when it parses as a duration, so paths may
+// contain spaces.
+func parseManifestLine(text string) (ManifestEntry, error) {
+	split := strings.LastIndexAny(text, " \t")
+	if split < 0 {
+		return ManifestEntry{Path: text}, nil
+	}
+	delay, err := time.ParseDuration(text[split+1:])
+	if err != nil {
+		return ManifestEntry{Path: text}, nil
+	}
+	if delay < 0 {
+		return ManifestEntry{}, fmt.Errorf("delay must be non-negative")
+	}
+	return ManifestEntry{Path: strings.TrimSpace(text[:split]), Delay: delay}, nil
+}
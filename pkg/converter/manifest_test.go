@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReadManifest(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     []ManifestEntry
+		wantErr  bool
+	}{
+		{
+			name:     "paths and delays",
+			manifest: "# intro\nintro.png 2s\n\nframes/frame 1.png\n  frames/frame2.png\t150ms\n/abs/outro.png\n",
+			want: []ManifestEntry{
+				{Path: filepath.Join(tempDir, "intro.png"), Delay: 2 * time.Second},
+				{Path: filepath.Join(tempDir, "frames", "frame 1.png")},
+				{Path: filepath.Join(tempDir, "frames", "frame2.png"), Delay: 150 * time.Millisecond},
+				{Path: "/abs/outro.png"},
+			},
+		},
+		{name: "negative delay", manifest: "frame.png -1s\n", wantErr: true},
+		{name: "only comments", manifest: "# nothing yet\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, "frames.txt")
+			if err := os.WriteFile(path, []byte(tt.manifest), 0644); err != nil {
+				t.Fatalf("Failed to write manifest: %v", err)
+			}
+
+			got, err := ReadManifest(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ReadManifest() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvertFrameDelays(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 4, 4)
+	outputFile := filepath.Join(tempDir, "output.gif")
+	opts := Options{Delay: 100, FrameDelays: []time.Duration{0, 2 * time.Second, 0}}
+	if _, err := Convert(inputFiles, outputFile, opts); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if want := []int{10, 200, 10}; !reflect.DeepEqual(g.Delay, want) {
+		t.Errorf("delays = %v, want %v", g.Delay, want)
+	}
+
+	opts.FrameDelays = opts.FrameDelays[:2]
+	if _, err := Convert(inputFiles, outputFile, opts); err == nil {
+		t.Error("Convert() with fewer delays than files succeeded")
+	}
+}
@@ -0,0 +1,125 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// colorBox is a set of histogram entries split by the median cut quantizer
+type colorBox struct {
+	entries []histogramEntry
+	count   int
+}
+
+// histogramEntry is an opaque color and the number of pixels using it
+type histogramEntry struct {
+	rgb   [3]uint8
+	count int
+}
+
+// medianCutPalette generates a palette of at most maxColors colors by
+// recursively splitting the color space at the median of its widest channel.
+// It represents smooth gradients better than picking the most frequent colors.
+func medianCutPalette(frames []image.Image, maxColors int) color.Palette {
+	histogram := make(map[[3]uint8]int)
+	for _, img := range frames {
+		bounds := img.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				histogram[[3]uint8{c.R, c.G, c.B}]++
+			}
+		}
+	}
+	if len(histogram) == 0 {
+		return color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}}
+	}
+
+	box := colorBox{}
+	for rgb, count := range histogram {
+		box.entries = append(box.entries, histogramEntry{rgb, count})
+		box.count += count
+	}
+	boxes := []colorBox{box}
+
+	for len(boxes) < maxColors {
+		// Split the box with the most pixels that still has more than one color
+		target := -1
+		for i, b := range boxes {
+			if len(b.entries) > 1 && (target < 0 || b.count > boxes[target].count) {
+				target = i
+			}
+		}
+		if target < 0 {
+			break
+		}
+		low, high := boxes[target].split()
+		boxes[target] = low
+		boxes = append(boxes, high)
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		palette[i] = b.average()
+	}
+	return palette
+}
+
+// split divides the box at the pixel median of its widest channel
+func (b colorBox) split() (colorBox, colorBox) {
+	channel := 0
+	widest := -1
+	for c := 0; c < 3; c++ {
+		lo, hi := uint8(255), uint8(0)
+		for _, e := range b.entries {
+			lo, hi = min(lo, e.rgb[c]), max(hi, e.rgb[c])
+		}
+		if int(hi)-int(lo) > widest {
+			channel, widest = c, int(hi)-int(lo)
+		}
+	}
+
+	// Ties are broken by value so the cut does not depend on map order
+	sort.Slice(b.entries, func(i, j int) bool {
+		x, y := b.entries[i].rgb, b.entries[j].rgb
+		if x[channel] != y[channel] {
+			return x[channel] < y[channel]
+		}
+		return uint32(x[0])<<16|uint32(x[1])<<8|uint32(x[2]) < uint32(y[0])<<16|uint32(y[1])<<8|uint32(y[2])
+	})
+
+	// Keep at least one entry on each side
+	half, seen, cut := b.count/2, 0, 1
+	for i, e := range b.entries[:len(b.entries)-1] {
+		seen += e.count
+		cut = i + 1
+		if seen >= half {
+			break
+		}
+	}
+
+	low := colorBox{entries: b.entries[:cut]}
+	high := colorBox{entries: b.entries[cut:]}
+	for _, e := range low.entries {
+		low.count += e.count
+	}
+	high.count = b.count - low.count
+	return low, high
+}
+
+// average returns the pixel weighted mean color of the box
+func (b colorBox) average() color.Color {
+	var sum [3]int
+	for _, e := range b.entries {
+		for c := range sum {
+			sum[c] += int(e.rgb[c]) * e.count
+		}
+	}
+	return color.RGBA{
+		R: uint8((sum[0] + b.count/2) / b.count),
+		G: uint8((sum[1] + b.count/2) / b.count),
+		B: uint8((sum[2] + b.count/2) / b.count),
+		A: 255,
+	}
+}
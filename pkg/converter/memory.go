@@ -0,0 +1,350 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// memorySampleInterval is how often the heap size is sampled
+	memorySampleInterval = 20 * time.Millisecond
+	// spillThreshold is the fraction of the memory limit above which frames are moved to disk
+	spillThreshold = 0.8
+	// spillCacheSize is the number of spilled frames kept decoded
+	spillCacheSize = 2
+)
+
+// memoryUnits are the suffixes accepted by ParseMemoryLimit
+var memoryUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+}
+
+// ParseMemoryLimit parses a size such as "512MB" or "2G", units being powers of 1024
+func ParseMemoryLimit(s string) (int64, error) {
+	spec := strings.ToUpper(strings.TrimSpace(s))
+	if spec == "" {
+		return 0, nil
+	}
+	split := strings.IndexFunc(spec, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if split < 0 {
+		split = len(spec)
+	}
+	unit, ok := memoryUnits[strings.TrimSpace(spec[split:])]
+	value, err := strconv.ParseFloat(spec[:split], 64)
+	if !ok || err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid memory limit %q: expected a size such as 512MB or 2G", s)
+	}
+	return int64(value * float64(unit)), nil
+}
+
+// memoryMonitor samples the heap size while a conversion runs and spills
+// frames to disk when it gets close to the limit
+type memoryMonitor struct {
+	// opts selects where frames are spilled
+	opts  Options
+	limit int64
+	heap  atomic.Uint64
+	peak  atomic.Uint64
+	stop  chan struct{}
+	done  chan struct{}
+	// previousLimit is the Go runtime memory limit to restore
+	previousLimit int64
+	// spill stores the frames moved to disk, created on first use
+	mu      sync.Mutex
+	spill   *spillStore
+	spilled int
+	// kept lists the temporary directories kept with Options.KeepTemp
+	kept []string
+}
+
+// startMemoryMonitor starts sampling the heap. With opts.MemoryLimit, the Go
+// runtime is asked to keep the heap below it until stopped.
+func startMemoryMonitor(opts Options) *memoryMonitor {
+	m := &memoryMonitor{opts: opts, limit: opts.MemoryLimit, stop: make(chan struct{}), done: make(chan struct{}), previousLimit: -1}
+	if m.limit > 0 {
+		m.previousLimit = debug.SetMemoryLimit(m.limit)
+	}
+	m.sample()
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(memorySampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sample()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+// sample records the current heap size
+func (m *memoryMonitor) sample() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	m.heap.Store(stats.HeapAlloc)
+	for {
+		peak := m.peak.Load()
+		if stats.HeapAlloc <= peak || m.peak.CompareAndSwap(peak, stats.HeapAlloc) {
+			return stats.HeapAlloc
+		}
+	}
+}
+
+// Stop ends the sampling, restores the runtime memory limit and removes spilled
+// frames unless they are kept
+func (m *memoryMonitor) Stop() {
+	select {
+	case <-m.stop:
+		return
+	default:
+	}
+	close(m.stop)
+	<-m.done
+	m.sample()
+	if m.previousLimit >= 0 {
+		debug.SetMemoryLimit(m.previousLimit)
+	}
+	if m.spill != nil && removeTempDir(m.spill.dir) {
+		m.kept = append(m.kept, m.spill.dir)
+	}
+}
+
+// err returns the error of a spilled frame that could not be read back, which
+// leaves it transparent in whatever used it
+func (m *memoryMonitor) err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.spill == nil {
+		return nil
+	}
+	return m.spill.failed()
+}
+
+// nearLimit reports whether the heap is close to the memory limit
+func (m *memoryMonitor) nearLimit() bool {
+	return m.limit > 0 && float64(m.heap.Load()) >= spillThreshold*float64(m.limit)
+}
+
+// finish stops the monitor and fills in the memory statistics of report
+func (m *memoryMonitor) finish(report *Report) {
+	m.Stop()
+	report.SpilledFrames = m.spilled
+	report.PeakHeap = m.peak.Load()
+	report.KeptTemp = m.kept
+	report.PeakRSS = peakRSS()
+}
+
+// keep returns img, or a disk-backed copy of it when the heap is close to the limit
+func (m *memoryMonitor) keep(img image.Image) (image.Image, error) {
+	if _, ok := img.(*diskImage); ok || !m.nearLimit() {
+		return img, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.spill == nil {
+		store, err := newSpillStore(m.opts)
+		if err != nil {
+			return nil, err
+		}
+		m.spill = store
+	}
+	spilled, err := m.spill.store(img)
+	if err != nil {
+		return nil, err
+	}
+	m.spilled++
+	// Let the next sample see the memory released by the spilled frame
+	m.sample()
+	return spilled, nil
+}
+
+// workers returns how many frames may be processed concurrently when each
+// worker needs workerBytes of the memory left
+func (m *memoryMonitor) workers(requested int, workerBytes int) int {
+	if m.limit <= 0 || workerBytes <= 0 {
+		return requested
+	}
+	if requested <= 0 {
+		requested = runtime.NumCPU()
+	}
+	headroom := m.limit - int64(m.sample())
+	fit := headroom / int64(workerBytes)
+	return int(max(1, min(int64(requested), fit)))
+}
+
+// peakRSS returns the peak resident set size of the process, 0 when unknown
+func peakRSS() uint64 {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmHWM:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb << 10
+		}
+	}
+	return 0
+}
+
+// spillStore keeps frames as files in a temporary directory
+type spillStore struct {
+	dir string
+	n   int
+	// cache holds the most recently used decoded frames
+	mu    sync.Mutex
+	cache []*diskImage
+	// err is the first failure to read a frame back
+	err error
+}
+
+// newSpillStore creates the temporary directory of spilled frames
+func newSpillStore(opts Options) (*spillStore, error) {
+	dir, err := makeTempDir(opts, "go-togif-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spillStore{dir: dir}, nil
+}
+
+// store writes img to disk and returns an image reading it back on demand
+func (s *spillStore) store(img image.Image) (*diskImage, error) {
+	path := filepath.Join(s.dir, fmt.Sprintf("%06d.png", s.n))
+	s.n++
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error spilling frame: %v", err)
+	}
+	defer file.Close()
+	encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := encoder.Encode(file, img); err != nil {
+		return nil, fmt.Errorf("error spilling frame: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("error spilling frame: %v", err)
+	}
+	return &diskImage{store: s, path: path, bounds: img.Bounds()}, nil
+}
+
+// load returns the decoded pixels of d, evicting the least recently used frame.
+// A frame that cannot be read back shows as transparent, the error being kept
+// for the conversion to fail on.
+func (s *spillStore) load(d *diskImage) image.Image {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, cached := range s.cache {
+		if cached == d {
+			copy(s.cache[1:i+1], s.cache[:i])
+			s.cache[0] = d
+			return d.pixels
+		}
+	}
+	// Reading more frames is pointless once one is lost
+	if s.err != nil {
+		return image.NewRGBA(d.bounds)
+	}
+
+	pixels, err := readSpilled(d.path)
+	if err != nil {
+		s.err = err
+		return image.NewRGBA(d.bounds)
+	}
+	d.pixels = pixels
+	if len(s.cache) == spillCacheSize {
+		s.cache[len(s.cache)-1].pixels = nil
+		s.cache = s.cache[:len(s.cache)-1]
+	}
+	s.cache = append([]*diskImage{d}, s.cache...)
+	return d.pixels
+}
+
+// readSpilled decodes the spilled frame at path
+func readSpilled(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spilled frame: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spilled frame %s: %v", path, err)
+	}
+	return img, nil
+}
+
+// failed returns the error of the first spilled frame that could not be read back
+func (s *spillStore) failed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// diskImage is a frame spilled to disk, decoded again when its pixels are read
+type diskImage struct {
+	store  *spillStore
+	path   string
+	bounds image.Rectangle
+	pixels image.Image
+}
+
+// ColorModel implements image.Image
+func (d *diskImage) ColorModel() color.Model { return color.RGBAModel }
+
+// Bounds implements image.Image
+func (d *diskImage) Bounds() image.Rectangle { return d.bounds }
+
+// At implements image.Image
+func (d *diskImage) At(x, y int) color.Color {
+	return d.store.load(d).At(x, y)
+}
+
+// loaded returns the decoded pixels of a spilled frame, or img itself
+func loaded(img image.Image) image.Image {
+	if d, ok := img.(*diskImage); ok {
+		return d.store.load(d)
+	}
+	return img
+}
+
+// frameBytes returns the size of the encoded data of a frame of the given bounds,
+// at worst about one and a half bytes per pixel. The encoder keeps up to two
+// buffers per worker.
+func frameBytes(bounds image.Rectangle) int {
+	return bounds.Dx() * bounds.Dy() * 3 / 2
+}
@@ -0,0 +1,185 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: 0},
+		{name: "bytes", input: "4096", want: 4096},
+		{name: "megabytes", input: "512MB", want: 512 << 20},
+		{name: "short unit", input: "2g", want: 2 << 30},
+		{name: "binary unit", input: "64 MiB", want: 64 << 20},
+		{name: "fraction", input: "1.5G", want: 3 << 29},
+		{name: "unknown unit", input: "10TB", wantErr: true},
+		{name: "zero", input: "0MB", wantErr: true},
+		{name: "not a number", input: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMemoryLimit(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemoryLimit(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMemoryLimit(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpillStore(t *testing.T) {
+	store, err := newSpillStore(Options{})
+	if err != nil {
+		t.Fatalf("newSpillStore() error = %v", err)
+	}
+	defer removeTempDir(store.dir)
+
+	// More frames than the cache holds, read back out of order
+	var originals []*image.RGBA
+	var spilled []*diskImage
+	for i := 0; i < spillCacheSize+2; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+		img.Set(1, 2, color.RGBA{uint8(i * 40), 10, 200, 255})
+		d, err := store.store(img)
+		if err != nil {
+			t.Fatalf("store() error = %v", err)
+		}
+		originals = append(originals, img)
+		spilled = append(spilled, d)
+	}
+
+	for _, i := range []int{3, 0, 2, 0, 1} {
+		if spilled[i].Bounds() != originals[i].Bounds() {
+			t.Errorf("frame %d bounds = %v, want %v", i, spilled[i].Bounds(), originals[i].Bounds())
+		}
+		got := color.RGBAModel.Convert(spilled[i].At(1, 2))
+		if want := originals[i].At(1, 2); got != want {
+			t.Errorf("frame %d pixel = %v, want %v", i, got, want)
+		}
+	}
+	if len(store.cache) > spillCacheSize {
+		t.Errorf("cache holds %d frames, want at most %d", len(store.cache), spillCacheSize)
+	}
+}
+
+func TestSpillStoreLostFrame(t *testing.T) {
+	store, err := newSpillStore(Options{})
+	if err != nil {
+		t.Fatalf("newSpillStore() error = %v", err)
+	}
+	defer removeTempDir(store.dir)
+
+	d, err := store.store(image.NewRGBA(image.Rect(0, 0, 4, 3)))
+	if err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+	if err := os.Remove(d.path); err != nil {
+		t.Fatalf("Failed to remove spilled frame: %v", err)
+	}
+	if _, _, _, a := loaded(d).At(1, 2).RGBA(); a != 0 {
+		t.Errorf("lost frame alpha = %d, want 0", a)
+	}
+	if store.failed() == nil {
+		t.Error("failed() = nil, want the error reading the lost frame")
+	}
+}
+
+func TestMemoryMonitorWorkers(t *testing.T) {
+	unlimited := &memoryMonitor{}
+	if got := unlimited.workers(0, 1<<20); got != 0 {
+		t.Errorf("workers without a limit = %d, want the requested 0", got)
+	}
+
+	// A limit already exceeded by the heap leaves a single worker
+	tight := &memoryMonitor{limit: 1}
+	if got := tight.workers(8, 1<<20); got != 1 {
+		t.Errorf("workers with no memory left = %d, want 1", got)
+	}
+
+	// Workers are limited to the memory left
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	fitting := &memoryMonitor{limit: int64(stats.HeapAlloc) + 100<<20}
+	if got := fitting.workers(16, 40<<20); got < 1 || got > 3 {
+		t.Errorf("workers with 100MB left = %d, want at most 3", got)
+	}
+
+	// Plenty of memory keeps the requested workers
+	loose := &memoryMonitor{limit: 1 << 40}
+	if got := loose.workers(4, 1<<20); got != 4 {
+		t.Errorf("workers with memory left = %d, want 4", got)
+	}
+}
+
+func TestConvertMemoryLimit(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 16, 8)
+	outputFile := filepath.Join(tempDir, "out.gif")
+
+	report, err := Convert(inputFiles, outputFile, Options{Delay: 100})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.PeakHeap == 0 {
+		t.Error("PeakHeap not reported")
+	}
+	if report.SpilledFrames != 0 {
+		t.Errorf("spilled %d frames without a limit, want 0", report.SpilledFrames)
+	}
+
+	// A limit below the heap size spills every frame
+	report, err = Convert(inputFiles, outputFile, Options{Delay: 100, MemoryLimit: 1})
+	if err != nil {
+		t.Fatalf("Convert() with a memory limit error = %v", err)
+	}
+	if report.SpilledFrames != 3 {
+		t.Errorf("spilled %d frames, want 3", report.SpilledFrames)
+	}
+	if report.Frames != 3 {
+		t.Errorf("output has %d frames, want 3", report.Frames)
+	}
+
+	// Spilled frames lost before they are encoded fail the conversion
+	spillDir := filepath.Join(tempDir, "spill")
+	if err := os.Mkdir(spillDir, 0755); err != nil {
+		t.Fatalf("Failed to create spill dir: %v", err)
+	}
+	lose := func(index int, img draw.Image) error {
+		if index > 0 {
+			return nil
+		}
+		lost, err := filepath.Glob(filepath.Join(spillDir, "go-togif-spill-*", "*.png"))
+		for _, path := range lost {
+			os.Remove(path)
+		}
+		return err
+	}
+	_, err = Convert(inputFiles, outputFile, Options{Delay: 100, MemoryLimit: 1, TmpDir: spillDir, FrameHook: lose})
+	if err == nil || !strings.Contains(err.Error(), "spilled frame") {
+		t.Errorf("Convert() with lost frames error = %v, want a spilled frame error", err)
+	}
+
+	if _, err := Convert(inputFiles, outputFile, Options{MemoryLimit: -1}); err == nil {
+		t.Error("Convert() expected error for a negative memory limit")
+	}
+}
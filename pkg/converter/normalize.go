@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"image"
+)
+
+// normalizer matches the brightness of frames to a reference frame, removing
+// the flicker of captures taken with different monitor profiles
+type normalizer struct {
+	// reference is the cumulative luma distribution of the reference frame
+	reference [256]float64
+}
+
+// newNormalizer returns a normalizer matching frames to reference
+func newNormalizer(reference image.Image) *normalizer {
+	return &normalizer{reference: lumaCDF(toRGBA(reference))}
+}
+
+// newEqualizer returns a normalizer spreading the luma of frames evenly over
+// every level, which is histogram equalization
+func newEqualizer() *normalizer {
+	n := &normalizer{}
+	for v := range n.reference {
+		n.reference[v] = float64(v+1) / float64(len(n.reference))
+	}
+	return n
+}
+
+// apply returns a copy of img whose luma histogram matches the reference one
+func (n *normalizer) apply(img image.Image) *image.RGBA {
+	rgba := toRGBA(img)
+	cdf := lumaCDF(rgba)
+
+	// Map every level to the reference level with the same rank
+	var lut [256]uint8
+	r := 0
+	for v := range lut {
+		for r < 255 && n.reference[r] < cdf[v] {
+			r++
+		}
+		lut[v] = uint8(r)
+	}
+
+	// Pixels are scaled to their target luma, keeping the hues
+	bounds := rgba.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		pix := rgba.Pix[rgba.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			p := pix[4*x : 4*x+3]
+			l := luma(p[0], p[1], p[2])
+			if lut[l] == l {
+				continue
+			}
+			for c := range p {
+				if l == 0 {
+					// Black has no hue to keep
+					p[c] = lut[0]
+				} else {
+					p[c] = uint8(min(255, float64(p[c])*float64(lut[l])/float64(l)+0.5))
+				}
+			}
+		}
+	}
+	return rgba
+}
+
+// lumaCDF returns the cumulative distribution of the luma of img, from 0 to 1
+func lumaCDF(img *image.RGBA) [256]float64 {
+	var histogram [256]int
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		pix := img.Pix[img.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			histogram[luma(pix[4*x], pix[4*x+1], pix[4*x+2])]++
+		}
+	}
+
+	var cdf [256]float64
+	total := float64(bounds.Dx() * bounds.Dy())
+	sum := 0
+	for v, n := range histogram {
+		sum += n
+		cdf[v] = float64(sum) / total
+	}
+	return cdf
+}
+
+// luma returns the perceived brightness of an 8-bit color (ITU-R BT.601)
+func luma(r, g, b uint8) uint8 {
+	return uint8((299*int(r) + 587*int(g) + 114*int(b)) / 1000)
+}
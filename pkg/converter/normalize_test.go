@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// meanLuma returns the average luma of img
+func meanLuma(img *image.RGBA) float64 {
+	var sum int
+	for i := 0; i < len(img.Pix); i += 4 {
+		sum += int(luma(img.Pix[i], img.Pix[i+1], img.Pix[i+2]))
+	}
+	return float64(sum) / float64(len(img.Pix)/4)
+}
+
+func TestNormalizer(t *testing.T) {
+	// The same gradient captured with a darker profile
+	reference := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	dark := image.NewRGBA(reference.Bounds())
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8(x*2 + y*2)
+			reference.Set(x, y, color.RGBA{v, v / 2, 255 - v, 255})
+			dark.Set(x, y, color.RGBA{uint8(float64(v) * 0.6), uint8(float64(v/2) * 0.6), uint8(float64(255-v) * 0.6), 255})
+		}
+	}
+
+	n := newNormalizer(reference)
+	before := math.Abs(meanLuma(dark) - meanLuma(reference))
+	after := math.Abs(meanLuma(n.apply(dark)) - meanLuma(reference))
+	if after > 3 || after >= before {
+		t.Errorf("mean luma difference = %.1f after normalizing, %.1f before, want below 3", after, before)
+	}
+
+	// The reference itself is left unchanged
+	if same := n.apply(reference); meanLuma(same) != meanLuma(reference) {
+		t.Errorf("normalizing the reference changed its mean luma from %.1f to %.1f", meanLuma(reference), meanLuma(same))
+	}
+}
+
+func TestEqualizer(t *testing.T) {
+	// A dull gradient using only a narrow range of levels
+	dull := image.NewRGBA(image.Rect(0, 0, 64, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8(100 + x*40/64)
+			dull.Set(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+
+	equalized := newEqualizer().apply(dull)
+	darkest, brightest := equalized.RGBAAt(0, 0).R, equalized.RGBAAt(63, 0).R
+	if darkest > 20 || brightest < 235 {
+		t.Errorf("equalized levels span %d to %d, want about 0 to 255", darkest, brightest)
+	}
+}
@@ -0,0 +1,48 @@
+package converter
+
+import (
+	"image"
+)
+
+// onionSkin blends every frame with faded copies of up to n previous frames,
+// leaving motion trails. The weights fall linearly with age: the frame itself
+// weighs n+1, the one before n, down to 1 for the oldest one, so that static
+// areas keep their colors.
+func onionSkin(frames []image.Image, n int, keep func(image.Image) (image.Image, error)) error {
+	if n <= 0 {
+		return nil
+	}
+
+	// Going backwards leaves the previous frames unblended when they are used
+	for i := len(frames) - 1; i > 0; i-- {
+		current := toRGBA(loaded(frames[i]))
+		bounds := current.Bounds()
+		sums := make([]int, len(current.Pix))
+		total := 0
+		for age := 0; age <= min(n, i); age++ {
+			frame := current
+			if age > 0 {
+				frame = toRGBA(loaded(frames[i-age]))
+			}
+			weight := n + 1 - age
+			total += weight
+			for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+				row := frame.Pix[frame.PixOffset(bounds.Min.X, y):]
+				sum := sums[(y-bounds.Min.Y)*4*bounds.Dx():]
+				for x := 0; x < 4*bounds.Dx(); x++ {
+					sum[x] += weight * int(row[x])
+				}
+			}
+		}
+
+		blended := image.NewRGBA(bounds)
+		for p := range blended.Pix {
+			blended.Pix[p] = uint8((sums[p] + total/2) / total)
+		}
+		var err error
+		if frames[i], err = keep(blended); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOnionSkin(t *testing.T) {
+	// A white dot moving right over a black background
+	dotFrames := func() []image.Image {
+		var frames []image.Image
+		for i := 0; i < 4; i++ {
+			img := image.NewRGBA(image.Rect(0, 0, 8, 1))
+			for x := 0; x < 8; x++ {
+				img.Set(x, 0, color.Black)
+			}
+			img.Set(i, 0, color.White)
+			frames = append(frames, img)
+		}
+		return frames
+	}
+	keep := func(img image.Image) (image.Image, error) { return img, nil }
+
+	tests := []struct {
+		name string
+		n    int
+		// want is the gray level of the pixels of the last frame
+		want []uint8
+	}{
+		{name: "disabled", n: 0, want: []uint8{0, 0, 0, 255, 0, 0, 0, 0}},
+		{name: "one frame", n: 1, want: []uint8{0, 0, 85, 170, 0, 0, 0, 0}},
+		{name: "three frames", n: 3, want: []uint8{26, 51, 77, 102, 0, 0, 0, 0}},
+		{name: "more frames than available", n: 10, want: []uint8{54, 60, 67, 74, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames := dotFrames()
+			if err := onionSkin(frames, tt.n, keep); err != nil {
+				t.Fatalf("onionSkin() error = %v", err)
+			}
+			last := frames[len(frames)-1].(*image.RGBA)
+			for x, want := range tt.want {
+				if got := last.RGBAAt(x, 0).R; got != want {
+					t.Errorf("pixel %d = %d, want %d", x, got, want)
+				}
+			}
+			if first := frames[0].(*image.RGBA); first.RGBAAt(0, 0).R != 255 {
+				t.Errorf("first frame changed, pixel 0 = %d, want 255", first.RGBAAt(0, 0).R)
+			}
+		})
+	}
+}
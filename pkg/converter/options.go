@@ -0,0 +1,182 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidateOptions checks opts like a conversion does before reading any
+// input, failing on invalid values and combinations. It returns a
+// WarningIneffectiveOption warning for every option ignored by the other
+// ones, naming the options by their command line flags; Convert adds them to
+// its report.
+func ValidateOptions(opts Options) ([]Warning, error) {
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+	return ineffectiveOptions(opts), nil
+}
+
+// ineffectiveOptions returns a warning for each option of valid opts that
+// has no effect with the other ones
+func ineffectiveOptions(opts Options) []Warning {
+	var warnings []Warning
+	warn := func(format string, args ...any) {
+		warnings = append(warnings, Warning{Kind: WarningIneffectiveOption, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Only GIF outputs are quantized and encoded by the GIF encoder
+	formats := effectiveFormats(opts)
+	if !slices.ContainsFunc(formats, isGIF) {
+		var flags []string
+		for flag, set := range map[string]bool{
+			"--dither":         opts.Dither != "",
+			"--quantizer":      opts.Quantizer != "",
+			"--colors":         opts.Colors > 0,
+			"--palette":        len(opts.Palette) > 0,
+			"--encoder":        opts.Encoder == EncoderStdlib,
+			"--gif-version":    opts.GIFVersion == GIF87a,
+			"--gif-extensions": len(opts.GIFExtensions) > 0,
+			"--poster-frame":   opts.PosterFrame > 0,
+			"--auto-poster":    opts.AutoPoster,
+			"--with-poster":    opts.WithPoster,
+			"--preserve-text":  opts.PreserveText,
+			"--text-region":    len(opts.TextRegions) > 0,
+			"--emit-snippet":   opts.EmitSnippet != "",
+			"--manifest":       opts.Manifest != "",
+			"--segments":       opts.Segments,
+			"--compat-report":  opts.CompatReport,
+			"--verify":         opts.Verify,
+			"--roi":            len(opts.ROIs) > 0,
+		} {
+			if set {
+				flags = append(flags, flag)
+			}
+		}
+		if len(flags) > 0 {
+			slices.Sort(flags)
+			verb := "has"
+			if len(flags) > 1 {
+				verb = "have"
+			}
+			warn("%s %s no effect on %s output, only GIF outputs use them; add gif to --formats to write one",
+				strings.Join(flags, ", "), verb, strings.Join(formats, " and "))
+		}
+	}
+
+	// Loops are stored by GIF, WebP and AVIF outputs, in the GIF loop extension
+	if opts.Loops > 0 {
+		if !slices.ContainsFunc(formats, func(format string) bool {
+			return isGIF(format) || format == FormatWebP || format == FormatAVIF
+		}) {
+			warn("--loops has no effect on %s output, which players loop on their own", strings.Join(formats, " and "))
+		} else if slices.ContainsFunc(formats, isGIF) && !gifExtensions(opts)[ExtensionLoop] {
+			warn("--loops has no effect on GIF outputs without the loop extension, which play once; remove --gif-version %s or add loop to --gif-extensions", GIF87a)
+		}
+	}
+
+	// The palette replaces the generated one
+	if len(opts.Palette) > 0 {
+		if opts.Colors > 0 {
+			warn("--colors has no effect with --palette, which sets the colors; reduce the palette file instead")
+		}
+		if opts.Quantizer != "" {
+			warn("--quantizer has no effect with --palette, which sets the colors")
+		}
+		if opts.Segments {
+			warn("--segments has no effect with --palette, which sets the colors of every frame")
+		}
+	}
+	if opts.Segments && len(opts.ROIs) > 0 && len(opts.Palette) == 0 {
+		warn("--segments has no effect with --roi, every region is quantized with its own palette")
+	}
+	if opts.Dither != "" && (opts.PreserveText || len(opts.TextRegions) > 0) {
+		warn("--dither has no effect with --preserve-text or --text-region, which dither everything but the text")
+	}
+
+	if opts.PosterMode == PosterRotate && opts.PosterFrame == 0 && !opts.AutoPoster {
+		warn("--poster-mode has no effect without --poster-frame or --auto-poster")
+	}
+	if opts.Normalize && opts.Equalize {
+		warn("--normalize has no effect with --equalize, which spreads the brightness of every frame on its own")
+	}
+	if opts.CornerColor != nil && opts.CornerRadius == 0 {
+		warn("--corner-color has no effect without --corner-radius")
+	}
+	if opts.StampCorner != "" && opts.StampCorner != CornerBottomRight && opts.Stamp == "" {
+		warn("--stamp-corner has no effect without --stamp")
+	}
+	if opts.ChapterHold > 0 && len(opts.Chapters) == 0 {
+		warn("--chapter-hold has no effect without --chapters")
+	}
+	if len(opts.Guides) > 0 && opts.PreviewFrames == 0 {
+		warn("--guides has no effect without --preview-frames")
+	}
+	if opts.AutoAdjust && opts.Check == "" {
+		warn("--auto-adjust has no effect without --check")
+	}
+	return warnings
+}
+
+// effectiveFormats returns the formats written by opts, the format of its
+// preset included
+func effectiveFormats(opts Options) []string {
+	if opts.Preset != "" {
+		if preset, err := LookupPreset(opts.Preset); err == nil && preset.Format != "" {
+			return []string{preset.Format}
+		}
+	}
+	return outputFormats(opts)
+}
+
+// suggest returns a hint naming the choice closest to an invalid value, as
+// " (did you mean x?)", or "" when none is close
+func suggest(value string, choices ...string) string {
+	value = strings.ToLower(value)
+	best, bestDistance := "", 0
+	for _, choice := range choices {
+		distance := editDistance(value, choice)
+		// A prefix, such as "floyd" for "floyd-steinberg", is as good as a typo
+		if len(value) >= 2 && strings.HasPrefix(choice, value) {
+			distance = 1
+		}
+		if distance > max(2, len(choice)/3) {
+			continue
+		}
+		if best == "" || distance < bestDistance {
+			best, bestDistance = choice, distance
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", best)
+}
+
+// editDistance returns the number of characters to insert, delete, replace
+// or swap with the next one to turn a into b
+func editDistance(a, b string) int {
+	// rows[i][j] is the distance between a[:i] and b[:j]
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
@@ -0,0 +1,117 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		// want lists the text of each expected warning, in order
+		want    []string
+		wantErr string
+	}{
+		{name: "defaults", opts: Options{}},
+		{name: "GIF settings", opts: Options{Dither: DitherFloydSteinberg, Colors: 64, Loops: 2}},
+		{name: "dither on a video", opts: Options{Format: FormatWebM, Dither: DitherFloydSteinberg},
+			want: []string{"--dither has no effect on webm output"}},
+		{name: "GIF settings on videos", opts: Options{Formats: []string{FormatWebM, FormatMP4}, Colors: 64, Encoder: EncoderStdlib},
+			want: []string{"--colors, --encoder have no effect on webm and mp4 output"}},
+		{name: "GIF settings with a GIF among the formats", opts: Options{Formats: []string{FormatGIF, FormatMP4}, Colors: 64}},
+		{name: "compat report on a video", opts: Options{Format: FormatWebM, CompatReport: true},
+			want: []string{"--compat-report has no effect on webm output"}},
+		{name: "verify a video", opts: Options{Format: FormatMP4, Verify: true},
+			want: []string{"--verify has no effect on mp4 output"}},
+		{name: "loops on a video", opts: Options{Format: FormatMP4, Loops: 3},
+			want: []string{"--loops has no effect on mp4 output"}},
+		{name: "loops on WebP", opts: Options{Format: FormatWebP, Loops: 3}},
+		{name: "loops without the loop extension", opts: Options{GIFVersion: GIF87a, Loops: 3, PosterMode: PosterRotate},
+			want: []string{"--loops has no effect on GIF outputs without the loop extension", "--poster-mode has no effect"}},
+		{name: "colors with a palette", opts: Options{Palette: color.Palette{color.Black, color.White}, Colors: 2},
+			want: []string{"--colors has no effect with --palette"}},
+		{name: "dither with preserved text", opts: Options{PreserveText: true, Dither: DitherNone},
+			want: []string{"--dither has no effect with --preserve-text"}},
+		{name: "normalize and equalize", opts: Options{Normalize: true, Equalize: true},
+			want: []string{"--normalize has no effect with --equalize"}},
+		{name: "corner color without radius", opts: Options{CornerColor: color.Black},
+			want: []string{"--corner-color has no effect without --corner-radius"}},
+		{name: "stamp corner without stamp", opts: Options{StampCorner: CornerTopLeft},
+			want: []string{"--stamp-corner has no effect without --stamp"}},
+		{name: "auto adjust without check", opts: Options{AutoAdjust: true},
+			want: []string{"--auto-adjust has no effect without --check"}},
+		{name: "segments with palette", opts: Options{Segments: true, Palette: color.Palette{color.Black}},
+			want: []string{"--segments has no effect with --palette, which sets the colors of every frame"}},
+		{name: "negative trim idle", opts: Options{TrimIdle: -time.Second}, wantErr: "trim idle must be non-negative"},
+		{name: "trim idle below a GIF delay", opts: Options{TrimIdle: time.Millisecond}, wantErr: "at least 10ms"},
+		{name: "chapter hold without chapters", opts: Options{ChapterHold: time.Second},
+			want: []string{"--chapter-hold has no effect without --chapters"}},
+		{name: "negative chapter hold", opts: Options{Chapters: []Chapter{{Label: "Intro"}}, ChapterHold: -time.Second}, wantErr: "chapter hold must be non-negative"},
+		{name: "guides without preview", opts: Options{Guides: []string{"16:9"}},
+			want: []string{"--guides has no effect without --preview-frames"}},
+		{name: "invalid guide", opts: Options{Guides: []string{"wide"}, PreviewFrames: 1}, wantErr: `invalid guide "wide"`},
+		{name: "same guides", opts: Options{Guides: []string{"16:9", "32:18"}, PreviewFrames: 1}, wantErr: "guides 16:9 and 32:18 are the same aspect ratio"},
+		{name: "segments with regions of interest", opts: Options{Segments: true, ROIs: []ROI{{Name: "pane", Rect: image.Rect(0, 0, 8, 8)}}},
+			want: []string{"--segments has no effect with --roi, every region is quantized with its own palette"}},
+		{name: "regions of interest with a snippet", opts: Options{EmitSnippet: SnippetHTML, ROIs: []ROI{{Name: "pane", Rect: image.Rect(0, 0, 8, 8)}}},
+			wantErr: "cannot be combined with regions of interest"},
+		{name: "negative smooth loop", opts: Options{SmoothLoop: -1}, wantErr: "smooth loop must be non-negative"},
+		{name: "magnify zoom of 1", opts: Options{MagnifyChanges: 1}, wantErr: "magnify changes must be 0 or a zoom of at least 2"},
+		{name: "invalid magnify corner", opts: Options{MagnifyChanges: 2, MagnifyCorner: "middle"}, wantErr: `invalid magnify corner "middle"`},
+		{name: "negative loop search", opts: Options{LoopSearch: -1}, wantErr: "loop search must be non-negative"},
+		{name: "find loop with loop search", opts: Options{FindLoop: true, LoopSearch: 5}, wantErr: "mutually exclusive"},
+		{name: "misspelled dithering", opts: Options{Dither: "floyd"}, wantErr: "(did you mean floyd-steinberg?)"},
+		{name: "misspelled quantizer", opts: Options{Quantizer: "median_cut"}, wantErr: "(did you mean median-cut?)"},
+		{name: "misspelled format", opts: Options{Format: "wepb"}, wantErr: "(did you mean webp?)"},
+		{name: "unknown scaler", opts: Options{Scaler: "bicubic"}, wantErr: `invalid scaler "bicubic": expected smooth or nearest`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := ValidateOptions(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ValidateOptions() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateOptions() error = %v", err)
+			}
+			if len(warnings) != len(tt.want) {
+				t.Fatalf("ValidateOptions() warnings = %v, want %d", warnings, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if warnings[i].Kind != WarningIneffectiveOption || !strings.Contains(warnings[i].Message, want) {
+					t.Errorf("warning %d = %+v, want an ineffective option warning containing %q", i, warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	choices := []string{"smooth", "nearest"}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "nearst", want: " (did you mean nearest?)"},
+		{value: "SMOOTH", want: " (did you mean smooth?)"},
+		{value: "near", want: " (did you mean nearest?)"},
+		{value: "lanczos", want: ""},
+		{value: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := suggest(tt.value, choices...); got != tt.want {
+				t.Errorf("suggest(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+)
+
+// Quantizers select how the palette is generated
+const (
+	// QuantizerFrequency keeps the most used colors, exact for flat UI captures
+	QuantizerFrequency = "frequency"
+	// QuantizerMedianCut splits the color space evenly, better for photos and gradients
+	QuantizerMedianCut = "median-cut"
+)
+
+// Dithering modes
+const (
+	// DitherNone maps every pixel to its closest palette color
+	DitherNone = "none"
+	// DitherFloydSteinberg diffuses the quantization error to the neighbor pixels
+	DitherFloydSteinberg = "floyd-steinberg"
+)
+
+const (
+	// textWeight is how much more a pixel inside a text region counts when selecting the palette
+	textWeight = 16
+	// MaxColors is the largest palette a GIF frame can use
+	MaxColors = 256
+)
+
+// buildPalette generates a palette of at most 256 colors from the given frames
+func buildPalette(frames []image.Image) color.Palette {
+	return buildWeightedPalette(frames, nil, MaxColors)
+}
+
+// buildWeightedPalette generates a palette of at most maxColors colors from the given frames,
+// favoring the colors used inside regions[i] of frame i
+func buildWeightedPalette(frames []image.Image, regions [][]image.Rectangle, maxColors int) color.Palette {
+	// Count how often each color is used
+	colorFreq := make(map[color.Color]int)
+	for i, img := range frames {
+		bounds := img.Bounds()
+		var mask []bool
+		if i < len(regions) && len(regions[i]) > 0 {
+			mask = regionMask(bounds, regions[i])
+		}
+
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				weight := 1
+				if mask != nil && mask[(y-bounds.Min.Y)*bounds.Dx()+(x-bounds.Min.X)] {
+					weight = textWeight
+				}
+				colorFreq[img.At(x, y)] += weight
+			}
+		}
+	}
+
+	// Ensure we have at least one color in the palette
+	if len(colorFreq) == 0 {
+		// Add basic colors if no colors were found
+		return color.Palette{
+			color.RGBA{0, 0, 0, 255},       // Black
+			color.RGBA{255, 255, 255, 255}, // White
+		}
+	}
+
+	// Sort colors by frequency
+	type colorCount struct {
+		color color.Color
+		count int
+	}
+	var sortedColors []colorCount
+	for c, count := range colorFreq {
+		sortedColors = append(sortedColors, colorCount{c, count})
+	}
+	// Ties are broken by value so the palette does not depend on map order
+	sort.Slice(sortedColors, func(i, j int) bool {
+		a, b := sortedColors[i], sortedColors[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return colorOrder(a.color) < colorOrder(b.color)
+	})
+
+	// Take the most frequent colors
+	palette := make(color.Palette, 0, maxColors)
+	for i := 0; i < len(sortedColors) && i < maxColors; i++ {
+		palette = append(palette, sortedColors[i].color)
+	}
+	return palette
+}
+
+// colorOrder is a sort key of c, its 16-bit RGBA channels packed together
+func colorOrder(c color.Color) uint64 {
+	r, g, b, a := c.RGBA()
+	return uint64(r)<<48 | uint64(g)<<32 | uint64(b)<<16 | uint64(a)
+}
+
+// generatePalette builds the palette for frames with the configured quantizer
+func generatePalette(frames []image.Image, regions [][]image.Rectangle, opts Options) color.Palette {
+	if opts.Quantizer == QuantizerMedianCut {
+		return medianCutPalette(frames, paletteSize(opts))
+	}
+	return buildWeightedPalette(frames, regions, paletteSize(opts))
+}
+
+// quantizeFrame maps img onto palette, keeping the colors of the text regions exact
+func quantizeFrame(img image.Image, palette color.Palette, regions []image.Rectangle, opts Options) *image.Paletted {
+	// Indexed inputs whose colors are all in the palette keep their pixels as is,
+	// whatever the dithering or text regions
+	if src, ok := img.(*image.Paletted); ok {
+		if paletted, ok := remapPaletted(src, palette); ok {
+			return paletted
+		}
+	}
+
+	paletted := image.NewPaletted(img.Bounds(), palette)
+	switch {
+	case opts.PreserveText || len(opts.TextRegions) > 0:
+		drawPreservingText(paletted, img, regions)
+	case opts.Dither == DitherFloydSteinberg:
+		draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, img.Bounds().Min)
+	default:
+		draw.Draw(paletted, paletted.Bounds(), img, img.Bounds().Min, draw.Src)
+	}
+	return paletted
+}
+
+// remapPaletted maps the pixel indices of src onto palette without looking up
+// every pixel. It reports false when a color used by src is not exactly in
+// palette. When every used index is unchanged, the pixels are shared with src.
+func remapPaletted(src *image.Paletted, palette color.Palette) (*image.Paletted, bool) {
+	var used [MaxColors]bool
+	bounds := src.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		offset := src.PixOffset(bounds.Min.X, y)
+		for _, index := range src.Pix[offset : offset+bounds.Dx()] {
+			used[index] = true
+		}
+	}
+
+	var mapping [MaxColors]uint8
+	identity := true
+	for index, isUsed := range used {
+		if !isUsed {
+			continue
+		}
+		if index >= len(src.Palette) {
+			return nil, false
+		}
+		target := palette.Index(src.Palette[index])
+		if !sameColor(src.Palette[index], palette[target]) {
+			return nil, false
+		}
+		mapping[index] = uint8(target)
+		identity = identity && target == index
+	}
+
+	if identity {
+		return &image.Paletted{Pix: src.Pix, Stride: src.Stride, Rect: src.Rect, Palette: palette}, true
+	}
+	paletted := image.NewPaletted(bounds, palette)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		in := src.Pix[src.PixOffset(bounds.Min.X, y):]
+		out := paletted.Pix[paletted.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			out[x] = mapping[in[x]]
+		}
+	}
+	return paletted, true
+}
+
+// sameColor reports whether a and b are the same color
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// paletteSize returns the number of colors to generate for opts
+func paletteSize(opts Options) int {
+	if opts.Colors > 0 {
+		return opts.Colors
+	}
+	return MaxColors
+}
+
+// regionMask returns a row-major mask of bounds marking the pixels inside any of the regions
+func regionMask(bounds image.Rectangle, regions []image.Rectangle) []bool {
+	mask := make([]bool, bounds.Dx()*bounds.Dy())
+	for _, r := range regions {
+		r = r.Intersect(bounds)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			row := (y - bounds.Min.Y) * bounds.Dx()
+			for x := r.Min.X; x < r.Max.X; x++ {
+				mask[row+x-bounds.Min.X] = true
+			}
+		}
+	}
+	return mask
+}
@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func TestQuantizeFramePaletted(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	white := color.RGBA{255, 255, 255, 255}
+
+	src := image.NewPaletted(image.Rect(2, 1, 10, 5), color.Palette{red, green, blue, white})
+	for i := range src.Pix {
+		// White is in the source palette but never used
+		src.Pix[i] = uint8(i % 3)
+	}
+
+	tests := []struct {
+		name    string
+		palette color.Palette
+		opts    Options
+		// shared is set when the pixels are used as is
+		shared bool
+		// remapped is set when the fast path applies
+		remapped bool
+	}{
+		{name: "same palette", palette: color.Palette{red, green, blue}, shared: true, remapped: true},
+		{name: "reordered palette", palette: color.Palette{blue, white, red, green}, remapped: true},
+		{name: "dithering", palette: color.Palette{green, blue, red}, opts: Options{Dither: DitherFloydSteinberg}, remapped: true},
+		{name: "preserving text", palette: color.Palette{green, red, blue}, opts: Options{PreserveText: true}, remapped: true},
+		{name: "missing color", palette: color.Palette{red, green, color.RGBA{0, 0, 250, 255}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, remapped := remapPaletted(src, tt.palette)
+			if remapped != tt.remapped {
+				t.Errorf("remapPaletted() = %v, want %v", remapped, tt.remapped)
+			}
+
+			got := quantizeFrame(src, tt.palette, nil, tt.opts)
+			if shared := &got.Pix[0] == &src.Pix[0]; shared != tt.shared {
+				t.Errorf("pixels shared = %v, want %v", shared, tt.shared)
+			}
+
+			// The result matches mapping every pixel through the palette
+			want := image.NewPaletted(src.Bounds(), tt.palette)
+			draw.Draw(want, want.Bounds(), src, src.Bounds().Min, draw.Src)
+			if got.Bounds() != want.Bounds() || !bytes.Equal(got.Pix, want.Pix) {
+				t.Errorf("quantizeFrame() pixels = %v, want %v", got.Pix, want.Pix)
+			}
+		})
+	}
+}
+
+func BenchmarkQuantizeFramePaletted(b *testing.B) {
+	palette := make(color.Palette, MaxColors)
+	for i := range palette {
+		palette[i] = color.RGBA{uint8(i), uint8(255 - i), uint8(i * 7), 255}
+	}
+	src := image.NewPaletted(image.Rect(0, 0, 640, 480), palette)
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 31)
+	}
+	rgba := toRGBA(src)
+	reversed := make(color.Palette, len(palette))
+	for i, c := range palette {
+		reversed[len(palette)-1-i] = c
+	}
+
+	b.Run("rgba", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			quantizeFrame(rgba, reversed, nil, Options{})
+		}
+	})
+	b.Run("paletted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			quantizeFrame(src, reversed, nil, Options{})
+		}
+	})
+}
+
+func TestBuildWeightedPaletteTies(t *testing.T) {
+	// Every color is used once, the palette keeps the lowest values
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.RGBA{200, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{0, 0, 9, 255})
+	img.Set(2, 0, color.RGBA{0, 50, 0, 255})
+	img.Set(3, 0, color.RGBA{100, 0, 0, 255})
+	want := color.Palette{color.RGBA{0, 0, 9, 255}, color.RGBA{0, 50, 0, 255}}
+
+	for i := 0; i < 20; i++ {
+		got := buildWeightedPalette([]image.Image{img}, nil, 2)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("buildWeightedPalette() = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package converter
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+// Palette file formats
+const (
+	// PaletteHex is one #RRGGBB color per line
+	PaletteHex = "hex"
+	// PaletteGPL is a GIMP palette
+	PaletteGPL = "gpl"
+	// PalettePNG is a swatch image with one square per color
+	PalettePNG = "png"
+)
+
+const (
+	// swatchSize is the side of a color square in PNG swatches
+	swatchSize = 16
+	// swatchColumns is the number of squares per row in PNG swatches
+	swatchColumns = 16
+)
+
+// ExtractPalette runs only the quantization stage, returning the palette Convert would generate
+func ExtractPalette(inputFiles []string, opts Options) (color.Palette, error) {
+	if len(inputFiles) == 0 {
+		return nil, fmt.Errorf("no input files specified")
+	}
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+
+	// Skipped and cropped frames are left out like in a conversion
+	src, err := inputSource(inputFiles, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Auto {
+		if opts, _, err = autoConfigure(src, 0, len(src.names), opts); err != nil {
+			return nil, err
+		}
+	}
+
+	var bounds image.Rectangle
+	frames := make([]image.Image, 0, len(src.names))
+	for i := range src.names {
+		img, err := src.load(i)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			dpi, err := src.density(i)
+			if err != nil {
+				return nil, err
+			}
+			bounds = outputBounds(img.Bounds(), dpi, opts)
+		}
+		frames = append(frames, resizeTo(img, bounds, opts.Scaler))
+	}
+
+	return generatePalette(frames, findTextRegions(frames, opts), opts), nil
+}
+
+// PaletteFormat returns the palette format matching the extension of path, PaletteHex when unknown
+func PaletteFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gpl":
+		return PaletteGPL
+	case ".png":
+		return PalettePNG
+	}
+	return PaletteHex
+}
+
+// WritePalette writes palette to w in the given format
+func WritePalette(w io.Writer, palette color.Palette, format string) error {
+	switch format {
+	case PaletteHex:
+		for _, c := range palette {
+			n := color.NRGBAModel.Convert(c).(color.NRGBA)
+			if _, err := fmt.Fprintf(w, "#%02x%02x%02x\n", n.R, n.G, n.B); err != nil {
+				return err
+			}
+		}
+		return nil
+	case PaletteGPL:
+		if _, err := fmt.Fprintf(w, "GIMP Palette\nName: go-togif\nColumns: %d\n#\n", swatchColumns); err != nil {
+			return err
+		}
+		for _, c := range palette {
+			n := color.NRGBAModel.Convert(c).(color.NRGBA)
+			if _, err := fmt.Fprintf(w, "%3d %3d %3d\t#%02x%02x%02x\n", n.R, n.G, n.B, n.R, n.G, n.B); err != nil {
+				return err
+			}
+		}
+		return nil
+	case PalettePNG:
+		if len(palette) == 0 {
+			return fmt.Errorf("cannot write an empty palette as PNG")
+		}
+		cols := min(len(palette), swatchColumns)
+		rows := (len(palette) + swatchColumns - 1) / swatchColumns
+		img := image.NewNRGBA(image.Rect(0, 0, cols*swatchSize, rows*swatchSize))
+		for i, c := range palette {
+			x, y := i%swatchColumns*swatchSize, i/swatchColumns*swatchSize
+			draw.Draw(img, image.Rect(x, y, x+swatchSize, y+swatchSize), image.NewUniform(c), image.Point{}, draw.Src)
+		}
+		return png.Encode(w, img)
+	}
+	return fmt.Errorf("invalid palette format %q: expected %s, %s or %s", format, PaletteHex, PaletteGPL, PalettePNG)
+}
+
+// ReadPalette loads a palette written by WritePalette, the format is chosen by extension
+func ReadPalette(path string) (color.Palette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening palette %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var palette color.Palette
+	switch PaletteFormat(path) {
+	case PalettePNG:
+		palette, err = readSwatch(file)
+	default:
+		palette, err = readPaletteText(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading palette %s: %v", path, err)
+	}
+	if len(palette) == 0 {
+		return nil, fmt.Errorf("palette %s has no colors", path)
+	}
+	if len(palette) > MaxColors {
+		return nil, fmt.Errorf("palette %s has %d colors, GIF supports at most %d", path, len(palette), MaxColors)
+	}
+	return palette, nil
+}
+
+// readPaletteText parses hex lists and GIMP palettes
+func readPaletteText(r io.Reader) (color.Palette, error) {
+	var palette color.Palette
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", line == "GIMP Palette", strings.HasPrefix(line, "Name:"), strings.HasPrefix(line, "Columns:"):
+			continue
+		case strings.HasPrefix(line, "#") && len(line) != 7 && len(line) != 9:
+			// GIMP comment
+			continue
+		case strings.HasPrefix(line, "#"):
+			c, err := overlay.ParseHexColor(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			palette = append(palette, c)
+		default:
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("line %d: expected R G B values", lineNo)
+			}
+			var rgb [3]uint8
+			for i := range rgb {
+				v, err := strconv.ParseUint(fields[i], 10, 8)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid color component %q", lineNo, fields[i])
+				}
+				rgb[i] = uint8(v)
+			}
+			palette = append(palette, color.RGBA{rgb[0], rgb[1], rgb[2], 255})
+		}
+	}
+	return palette, scanner.Err()
+}
+
+// readSwatch returns the distinct colors of a swatch image in reading order, skipping transparent pixels
+func readSwatch(r io.Reader) (color.Palette, error) {
+	img, err := decode.PNG(r, decode.DefaultLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	var palette color.Palette
+	seen := make(map[color.RGBA]bool)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			if c.A == 0 || seen[c] {
+				continue
+			}
+			seen[c] = true
+			palette = append(palette, c)
+		}
+	}
+	return palette, nil
+}
@@ -0,0 +1,241 @@
+package converter
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPaletteRoundTrip(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	palette := color.Palette{
+		color.RGBA{255, 0, 0, 255},
+		color.RGBA{0, 128, 255, 255},
+		color.RGBA{10, 20, 30, 255},
+	}
+	for i := 0; i < 20; i++ {
+		palette = append(palette, color.RGBA{uint8(i), 200, 100, 255})
+	}
+
+	for _, name := range []string{"palette.hex", "palette.gpl", "palette.png"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(tempDir, name)
+			var buf bytes.Buffer
+			if err := WritePalette(&buf, palette, PaletteFormat(path)); err != nil {
+				t.Fatalf("WritePalette() error = %v", err)
+			}
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("Failed to write palette: %v", err)
+			}
+
+			got, err := ReadPalette(path)
+			if err != nil {
+				t.Fatalf("ReadPalette() error = %v", err)
+			}
+			if len(got) != len(palette) {
+				t.Fatalf("ReadPalette() got %d colors, want %d", len(got), len(palette))
+			}
+			for i := range palette {
+				if color.RGBAModel.Convert(got[i]) != palette[i] {
+					t.Errorf("color %d = %v, want %v", i, got[i], palette[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWritePaletteFormats(t *testing.T) {
+	palette := color.Palette{color.RGBA{255, 136, 0, 255}}
+
+	var hex bytes.Buffer
+	if err := WritePalette(&hex, palette, PaletteHex); err != nil {
+		t.Fatalf("WritePalette(hex) error = %v", err)
+	}
+	if hex.String() != "#ff8800\n" {
+		t.Errorf("hex = %q, want %q", hex.String(), "#ff8800\n")
+	}
+
+	var gpl bytes.Buffer
+	if err := WritePalette(&gpl, palette, PaletteGPL); err != nil {
+		t.Fatalf("WritePalette(gpl) error = %v", err)
+	}
+	if !strings.HasPrefix(gpl.String(), "GIMP Palette\n") || !strings.Contains(gpl.String(), "255 136   0\t#ff8800") {
+		t.Errorf("gpl = %q, want a GIMP palette", gpl.String())
+	}
+
+	var swatch bytes.Buffer
+	if err := WritePalette(&swatch, palette, PalettePNG); err != nil {
+		t.Fatalf("WritePalette(png) error = %v", err)
+	}
+	img, err := png.Decode(&swatch)
+	if err != nil {
+		t.Fatalf("Failed to decode swatch: %v", err)
+	}
+	if img.Bounds() != image.Rect(0, 0, swatchSize, swatchSize) {
+		t.Errorf("swatch bounds = %v, want one square", img.Bounds())
+	}
+
+	if err := WritePalette(&bytes.Buffer{}, palette, "aco"); err == nil {
+		t.Error("WritePalette() expected error for an unknown format")
+	}
+}
+
+func TestReadPaletteErrors(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty.hex", ""},
+		{"invalid.hex", "#zzzzzz\n"},
+		{"invalid.gpl", "GIMP Palette\n300 0 0\tred\n"},
+		{"too-many.hex", strings.Repeat("#000000\n", 257)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write palette: %v", err)
+			}
+			if _, err := ReadPalette(path); err == nil {
+				t.Error("ReadPalette() expected error")
+			}
+		})
+	}
+}
+
+func TestExtractPaletteAndReuse(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Two solid frames
+	var inputFiles []string
+	for i, c := range []color.RGBA{{255, 0, 0, 255}, {0, 0, 255, 255}} {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		for p := 0; p < len(img.Pix); p += 4 {
+			img.Pix[p], img.Pix[p+1], img.Pix[p+2], img.Pix[p+3] = c.R, c.G, c.B, c.A
+		}
+		path := filepath.Join(tempDir, "frame"+string(rune('0'+i))+".png")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatalf("Failed to encode frame: %v", err)
+		}
+		file.Close()
+		inputFiles = append(inputFiles, path)
+	}
+
+	palette, err := ExtractPalette(inputFiles, Options{})
+	if err != nil {
+		t.Fatalf("ExtractPalette() error = %v", err)
+	}
+	if len(palette) != 2 {
+		t.Fatalf("ExtractPalette() got %d colors, want 2", len(palette))
+	}
+
+	// A provided palette is used as is
+	custom := color.Palette{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, color.RGBA{0, 255, 0, 255}}
+	outputFile := filepath.Join(tempDir, "output.gif")
+	if _, err := Convert(inputFiles, outputFile, Options{Delay: 100, Palette: custom}); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output GIF: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output GIF: %v", err)
+	}
+	// The encoder pads palettes to a power of two
+	for i, c := range custom {
+		if got := color.RGBAModel.Convert(g.Image[0].Palette[i]); got != c {
+			t.Errorf("output palette color %d = %v, want %v", i, got, c)
+		}
+	}
+
+	big := make(color.Palette, 257)
+	for i := range big {
+		big[i] = color.Black
+	}
+	if _, err := Convert(inputFiles, outputFile, Options{Palette: big}); err == nil {
+		t.Error("Convert() expected error for a palette with more than 256 colors")
+	}
+}
+
+func TestExtractPaletteSettings(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Noise with far more than 256 colors is detected as a photo
+	inputFiles := writeNoiseFrames(t, tempDir, 2, 64, 32)
+
+	frequency, err := ExtractPalette(inputFiles, Options{})
+	if err != nil {
+		t.Fatalf("ExtractPalette() error = %v", err)
+	}
+	medianCut, err := ExtractPalette(inputFiles, Options{Quantizer: QuantizerMedianCut})
+	if err != nil {
+		t.Fatalf("ExtractPalette() with median cut error = %v", err)
+	}
+	if reflect.DeepEqual(frequency, medianCut) {
+		t.Error("ExtractPalette() ignored the quantizer")
+	}
+
+	// Auto picks the settings of a conversion
+	auto, err := ExtractPalette(inputFiles, Options{Auto: true})
+	if err != nil {
+		t.Fatalf("ExtractPalette() with auto error = %v", err)
+	}
+	if !reflect.DeepEqual(auto, medianCut) {
+		t.Error("ExtractPalette() with auto differs from the median cut palette of photos")
+	}
+
+	// The palette has the colors and is built at the size of the output
+	small, err := ExtractPalette(inputFiles, Options{Colors: 8})
+	if err != nil {
+		t.Fatalf("ExtractPalette() with 8 colors error = %v", err)
+	}
+	if len(small) != 8 {
+		t.Errorf("ExtractPalette() with 8 colors got %d colors", len(small))
+	}
+	// Two frames scaled down to a single pixel each
+	thumbnail, err := ExtractPalette(inputFiles, Options{Width: 1})
+	if err != nil {
+		t.Fatalf("ExtractPalette() with a width error = %v", err)
+	}
+	if len(thumbnail) > 2 {
+		t.Errorf("ExtractPalette() at 1 pixel wide got %d colors, want at most 2", len(thumbnail))
+	}
+
+	if _, err := ExtractPalette(inputFiles, Options{Scaler: "bicubic"}); err == nil {
+		t.Error("ExtractPalette() expected error for an invalid scaler")
+	}
+}
@@ -0,0 +1,318 @@
+package converter
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// PatternOptions controls how input patterns are matched against file names
+type PatternOptions struct {
+	// IgnoreCase matches file names regardless of case. It applies to the
+	// file name part of the pattern, not to its directories.
+	IgnoreCase bool
+	// FollowSymlinks includes symbolic links to files matched by a wildcard,
+	// and descends into linked directories of a ** pattern
+	FollowSymlinks bool
+	// IncludeHidden includes files and directories whose name starts with a
+	// dot when matched by a wildcard. A pattern starting with a dot always
+	// matches hidden files.
+	IncludeHidden bool
+}
+
+// recursiveDir is the directory name matching a directory and all its subdirectories
+const recursiveDir = "**"
+
+// ExpandInputPattern expands a glob pattern or regex into a list of matching PNG, WebP, BMP and TIFF files
+func ExpandInputPattern(pattern string) ([]string, error) {
+	return ExpandInputPatternWith(pattern, PatternOptions{})
+}
+
+// ExpandInputPatternWith expands a glob pattern or regex into a list of matching
+// PNG, WebP, BMP and TIFF files with the given matching options. Names are compared in Unicode NFC
+// form, so patterns typed on one system match the decomposed names other file
+// systems (macOS) store. A ** directory (frames/**/*.png) matches the files of
+// the directory before it and of all its subdirectories.
+func ExpandInputPatternWith(pattern string, opts PatternOptions) ([]string, error) {
+	// Get the directory and base pattern, prefixing long Windows paths
+	dir, basePattern := splitPattern(pattern)
+	dir = longPath(dir)
+
+	dirs := []string{dir}
+	recursive := filepath.Base(dir) == recursiveDir
+	if recursive {
+		dir = filepath.Dir(dir)
+	}
+
+	// Ensure the directory exists
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", dir)
+	}
+	if recursive {
+		var err error
+		if dirs, err = walkDirs(dir, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	for _, dir := range dirs {
+		found, err := matchDir(dir, basePattern, recursive, opts)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, found...)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no PNG, WebP, BMP or TIFF files found matching pattern: %s", pattern)
+	}
+
+	// Sort matches for consistent ordering
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// matchDir returns the PNG, WebP, BMP and TIFF files of dir matching
+// basePattern. In a recursive walk, a pattern that is neither a matching glob
+// nor a valid regex matches nothing in dir rather than failing, as the other
+// directories may hold matches.
+func matchDir(dir, basePattern string, recursive bool, opts PatternOptions) ([]string, error) {
+	// Read all files in the directory
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %v", err)
+	}
+
+	// Try glob pattern first
+	glob, err := globPattern(basePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern: %v", err)
+	}
+	var matches []string
+	for _, match := range matchNames(dir, files, glob, opts) {
+		// Filter for PNG, WebP, BMP and TIFF files
+		if hasFrameExtension(match) && allowedMatch(match, basePattern, opts) {
+			matches = append(matches, match)
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+
+	// If glob pattern didn't work, try regex
+	if strings.HasPrefix(basePattern, "^") || strings.ContainsAny(basePattern, ".*+?[](){}|") {
+		expr := norm.NFC.String(basePattern)
+		if opts.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		// A glob such as *.png is no regex, which only fails a single directory
+		re, err := regexp.Compile(expr)
+		if err != nil && !recursive {
+			return nil, fmt.Errorf("invalid regex pattern: %v", err)
+		}
+
+		for _, file := range files {
+			if re != nil && !file.IsDir() && hasFrameExtension(file.Name()) {
+				path := filepath.Join(dir, file.Name())
+				if re.MatchString(norm.NFC.String(file.Name())) && allowedMatch(path, basePattern, opts) {
+					matches = append(matches, path)
+				}
+			}
+		}
+		if len(matches) > 0 {
+			return matches, nil
+		}
+	}
+
+	// A *.png, *.webp, *.bmp or *.tif(f) pattern matches the files of that
+	// extension whatever its case
+	if ext, ok := strings.CutPrefix(basePattern, "*"); ok && hasFrameExtension(ext) && filepath.Ext(ext) == ext {
+		for _, file := range files {
+			path := filepath.Join(dir, file.Name())
+			if !file.IsDir() && strings.EqualFold(filepath.Ext(file.Name()), ext) && allowedMatch(path, basePattern, opts) {
+				matches = append(matches, path)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// allowedMatch reports whether a file matched by basePattern passes the hidden
+// file and symbolic link policies. A pattern naming a single file always
+// matches it, as long as it is a file.
+func allowedMatch(path, basePattern string, opts PatternOptions) bool {
+	literal := !strings.ContainsAny(basePattern, `*?[]{}()|+^$\`)
+	if !literal && !opts.IncludeHidden && isHidden(filepath.Base(path)) && !isHidden(basePattern) {
+		return false
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		if !literal && !opts.FollowSymlinks {
+			return false
+		}
+		// Skip broken links and links to directories
+		info, err = os.Stat(path)
+		if err != nil {
+			return false
+		}
+	}
+	return info.Mode().IsRegular()
+}
+
+// walkDirs returns root and its subdirectories, skipping hidden directories
+// unless opts.IncludeHidden and linked ones unless opts.FollowSymlinks. Each
+// directory is listed once, so links back to a parent do not loop.
+func walkDirs(root string, opts PatternOptions) ([]string, error) {
+	var dirs []string
+	visited := make(map[string]bool)
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		// Directories are identified by their path with links resolved
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return fmt.Errorf("error reading directory: %v", err)
+		}
+		if visited[real] {
+			return nil
+		}
+		visited[real] = true
+		dirs = append(dirs, dir)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("error reading directory: %v", err)
+		}
+		for _, entry := range entries {
+			if !opts.IncludeHidden && isHidden(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if entry.Type()&fs.ModeSymlink != 0 {
+				if !opts.FollowSymlinks {
+					continue
+				}
+				if info, err := os.Stat(path); err != nil || !info.IsDir() {
+					continue
+				}
+			} else if !entry.IsDir() {
+				continue
+			}
+			if err := walk(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// isHidden reports whether a file name is hidden by the dot convention
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// matchNames returns the paths of the files matching a glob pattern prepared
+// by globPattern, comparing names with foldName
+func matchNames(dir string, files []os.DirEntry, glob string, opts PatternOptions) []string {
+	var matches []string
+	glob = foldName(glob, opts)
+	for _, file := range files {
+		if !file.IsDir() && matchGlob(glob, foldName(file.Name(), opts)) {
+			matches = append(matches, filepath.Join(dir, file.Name()))
+		}
+	}
+	return matches
+}
+
+// foldName returns name in the form compared to patterns: NFC normalized, and
+// lower case when opts.IgnoreCase is set
+func foldName(name string, opts PatternOptions) string {
+	name = norm.NFC.String(name)
+	if opts.IgnoreCase {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// regexEscapes are the characters escaped with a backslash in regex patterns
+const regexEscapes = `.*+?()[]{}|\`
+
+// regexClasses are the letters of the regex character class escapes (\d, \w, \s, \b)
+const regexClasses = "dDwWsSbB"
+
+// splitPattern separates the directory of an input pattern from the file name
+// pattern. On Windows a backslash is both a path separator and the escape of
+// regex patterns (C:\frames\frame\d+\.png): a backslash followed by a regex
+// metacharacter or by a class letter ending a word is a regex escape, any
+// other one, and those around a ** directory, a separator. Directories whose
+// name starts that way can be written with forward slashes.
+func splitPattern(pattern string) (dir, base string) {
+	volume := filepath.VolumeName(pattern)
+	last := -1
+	for i := len(volume); i < len(pattern); i++ {
+		if pattern[i] == '\\' && !nextToRecursiveDir(pattern, i) && isRegexEscape(pattern[i+1:]) {
+			// Skip the escaped character
+			i++
+			continue
+		}
+		if os.IsPathSeparator(pattern[i]) {
+			last = i
+		}
+	}
+
+	switch {
+	case last == len(volume):
+		// Root directory, keeping its separator
+		return pattern[:last+1], pattern[last+1:]
+	case last >= 0:
+		return pattern[:last], pattern[last+1:]
+	case volume != "":
+		// Drive relative pattern such as C:*.png
+		return volume, pattern[len(volume):]
+	default:
+		return ".", pattern
+	}
+}
+
+// isRegexEscape reports whether a backslash followed by rest is a regex escape
+func isRegexEscape(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	if strings.IndexByte(regexEscapes, rest[0]) >= 0 {
+		return true
+	}
+	if strings.IndexByte(regexClasses, rest[0]) < 0 {
+		return false
+	}
+	// \d+ is a class, \data a directory
+	return len(rest) == 1 || !isWordChar(rest[1])
+}
+
+// nextToRecursiveDir reports whether the character at i of pattern is right
+// before or after a ** directory
+func nextToRecursiveDir(pattern string, i int) bool {
+	n := len(recursiveDir)
+	before := strings.HasPrefix(pattern[i+1:], recursiveDir) && (i+1+n == len(pattern) || os.IsPathSeparator(pattern[i+1+n]))
+	after := strings.HasSuffix(pattern[:i], recursiveDir) && (i == n || os.IsPathSeparator(pattern[i-n-1]))
+	return before || after
+}
+
+// isWordChar reports whether c can continue a file name word
+func isWordChar(c byte) bool {
+	return c == '_' || c == '-' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
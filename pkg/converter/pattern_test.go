@@ -0,0 +1,198 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitPattern(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frames := filepath.Join(tempDir, "frames")
+	if err := os.Mkdir(frames, 0755); err != nil {
+		t.Fatalf("Failed to create frames dir: %v", err)
+	}
+	sep := string(os.PathSeparator)
+
+	tests := []struct {
+		name     string
+		pattern  string
+		wantDir  string
+		wantBase string
+	}{
+		{name: "file name only", pattern: "*.png", wantDir: ".", wantBase: "*.png"},
+		{name: "glob in a directory", pattern: frames + sep + "*.png", wantDir: frames, wantBase: "*.png"},
+		{name: "regex in a directory", pattern: frames + sep + `^frame\d+\.png$`, wantDir: frames, wantBase: `^frame\d+\.png$`},
+		{name: "missing directory", pattern: filepath.Join(tempDir, "missing", "*.png"), wantDir: filepath.Join(tempDir, "missing"), wantBase: "*.png"},
+		{name: "root directory", pattern: sep + "*.png", wantDir: sep, wantBase: "*.png"},
+		{name: "regex with a slash", pattern: "frames/frame[0-9]+\\.png", wantDir: "frames", wantBase: "frame[0-9]+\\.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, base := splitPattern(tt.pattern)
+			if dir != tt.wantDir || base != tt.wantBase {
+				t.Errorf("splitPattern(%q) = %q, %q, want %q, %q", tt.pattern, dir, base, tt.wantDir, tt.wantBase)
+			}
+		})
+	}
+}
+
+func TestExpandInputPatternWith(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// café.png is stored decomposed, as macOS file systems do
+	testFiles := []string{"Frame1.PNG", "frame2.png", "FRAME3.Png", "café.png", "notes.txt"}
+	for _, file := range testFiles {
+		if err := os.WriteFile(filepath.Join(tempDir, file), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", file, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		opts    PatternOptions
+		want    int
+		wantErr bool
+	}{
+		{name: "case sensitive glob", pattern: "frame*.png", want: 1},
+		{name: "case insensitive glob", pattern: "frame*.png", opts: PatternOptions{IgnoreCase: true}, want: 3},
+		{name: "case insensitive regex", pattern: `^FRAME\d`, opts: PatternOptions{IgnoreCase: true}, want: 3},
+		{name: "case sensitive extension", pattern: "*.png", want: 2},
+		{name: "case insensitive extension", pattern: "*.PNG", opts: PatternOptions{IgnoreCase: true}, want: 4},
+		{name: "composed pattern", pattern: "caf\u00e9.png", want: 1},
+		{name: "composed regex", pattern: "^caf\u00e9", want: 1},
+		{name: "case sensitive miss", pattern: "FRAME2.PNG", wantErr: true},
+		{name: "case insensitive name", pattern: "FRAME2.PNG", opts: PatternOptions{IgnoreCase: true}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInputPatternWith(filepath.Join(tempDir, tt.pattern), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandInputPatternWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ExpandInputPatternWith() = %v, want %d files", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandInputPatternPolicies(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// frames/.cache and frames/nested hold more frames, frames/loop links back to frames
+	frames := filepath.Join(tempDir, "frames")
+	for _, dir := range []string{frames, filepath.Join(frames, ".cache"), filepath.Join(frames, "nested"), filepath.Join(tempDir, "other")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir %s: %v", dir, err)
+		}
+	}
+	testFiles := []string{"frames/frame1.png", "frames/.frame2.png", "frames/.cache/frame3.png", "frames/nested/frame4.png", "other/frame5.png"}
+	for _, file := range testFiles {
+		if err := os.WriteFile(filepath.Join(tempDir, file), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", file, err)
+		}
+	}
+	links := map[string]string{
+		"frames/link.png":   filepath.Join(tempDir, "other", "frame5.png"),
+		"frames/broken.png": filepath.Join(tempDir, "missing.png"),
+		"frames/loop":       frames,
+		"frames/other":      filepath.Join(tempDir, "other"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(tempDir, link)); err != nil {
+			t.Skipf("Symbolic links not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		opts    PatternOptions
+		want    int
+		wantErr bool
+	}{
+		{name: "defaults", pattern: "frames/*.png", want: 1},
+		{name: "include hidden", pattern: "frames/*.png", opts: PatternOptions{IncludeHidden: true}, want: 2},
+		{name: "hidden pattern", pattern: "frames/.*.png", want: 1},
+		{name: "hidden regex", pattern: `frames/^frame\d`, opts: PatternOptions{IncludeHidden: true}, want: 1},
+		{name: "follow symlinks", pattern: "frames/*.png", opts: PatternOptions{FollowSymlinks: true}, want: 2},
+		{name: "named symlink", pattern: "frames/link.png", want: 1},
+		{name: "broken symlink", pattern: "frames/broken.png", wantErr: true},
+		{name: "recursive", pattern: "frames/**/*.png", want: 2},
+		{name: "recursive hidden", pattern: "frames/**/*.png", opts: PatternOptions{IncludeHidden: true}, want: 4},
+		{name: "recursive with links", pattern: "frames/**/frame*.png", opts: PatternOptions{FollowSymlinks: true}, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInputPatternWith(filepath.Join(tempDir, filepath.FromSlash(tt.pattern)), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandInputPatternWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ExpandInputPatternWith() = %v, want %d files", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandInputPatternRecursiveGaps(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Only the deepest directory holds frames, the root and the middle
+	// directory hold subdirectories and unrelated files
+	for _, file := range []string{"shots/notes.txt", "shots/day1/readme.md", "shots/day1/take2/frame1.png", "shots/day1/take2/frame2.png"} {
+		path := filepath.Join(tempDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", file, err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", file, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    int
+		wantErr bool
+	}{
+		{name: "glob", pattern: "shots/**/*.png", want: 2},
+		{name: "regex", pattern: `shots/**/^frame\d+\.png$`, want: 2},
+		{name: "no match anywhere", pattern: "shots/**/*.webp", wantErr: true},
+		{name: "glob outside of a walk", pattern: "shots/*.png", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandInputPatternWith(filepath.Join(tempDir, filepath.FromSlash(tt.pattern)), PatternOptions{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandInputPatternWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ExpandInputPatternWith() = %v, want %d files", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitPatternWindows(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name     string
+		pattern  string
+		wantDir  string
+		wantBase string
+	}{
+		{name: "backslashes", pattern: tempDir + `\*.png`, wantDir: tempDir, wantBase: "*.png"},
+		{name: "forward slashes", pattern: filepath.ToSlash(tempDir) + "/*.png", wantDir: filepath.ToSlash(tempDir), wantBase: "*.png"},
+		{name: "regex escapes", pattern: tempDir + `\^frame\d+\.png$`, wantDir: tempDir, wantBase: `^frame\d+\.png$`},
+		{name: "directory starting like a class", pattern: `C:\data\d-1\*.png`, wantDir: `C:\data\d-1`, wantBase: "*.png"},
+		{name: "missing directory", pattern: `C:\missing\nested\*.png`, wantDir: `C:\missing\nested`, wantBase: "*.png"},
+		{name: "recursive directory", pattern: tempDir + `\**\*.png`, wantDir: tempDir + `\**`, wantBase: "*.png"},
+		{name: "drive root", pattern: `C:\*.png`, wantDir: `C:\`, wantBase: "*.png"},
+		{name: "drive relative", pattern: `C:*.png`, wantDir: "C:", wantBase: "*.png"},
+		{name: "UNC share", pattern: `\\server\share\frames\*.png`, wantDir: `\\server\share\frames`, wantBase: "*.png"},
+		{name: "long path prefix", pattern: `\\?\` + tempDir + `\frame?.png`, wantDir: `\\?\` + tempDir, wantBase: "frame?.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, base := splitPattern(tt.pattern)
+			if dir != tt.wantDir || base != tt.wantBase {
+				t.Errorf("splitPattern(%q) = %q, %q, want %q, %q", tt.pattern, dir, base, tt.wantDir, tt.wantBase)
+			}
+		})
+	}
+}
+
+func TestLongPath(t *testing.T) {
+	long := `C:\` + strings.Repeat(`frames\`, 40) + "out"
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "short path", path: `C:\frames`, want: `C:\frames`},
+		{name: "long path", path: long, want: `\\?\` + long},
+		{name: "long UNC path", path: `\\server\share\` + long[3:], want: `\\?\UNC\server\share\` + long[3:]},
+		{name: "already prefixed", path: `\\?\` + long, want: `\\?\` + long},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longPath(tt.path); got != tt.want {
+				t.Errorf("longPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandInputPatternLongPath(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(`\\?\` + tempDir)
+
+	// A directory deeper than MAX_PATH
+	dir := `\\?\` + tempDir + strings.Repeat(`\frames-directory`, 20)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create long directory: %v", err)
+	}
+	for _, name := range []string{"frame1.png", "frame2.png", "notes.txt"} {
+		if err := os.WriteFile(dir+`\`+name, nil, 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	plain := strings.TrimPrefix(dir, `\\?\`)
+	for _, pattern := range []string{plain + `\*.png`, dir + `\*.png`, plain + `\frame\d\.png`} {
+		got, err := ExpandInputPattern(pattern)
+		if err != nil {
+			t.Fatalf("ExpandInputPattern(%q) error = %v", pattern, err)
+		}
+		if len(got) != 2 {
+			t.Errorf("ExpandInputPattern(%q) = %v, want 2 files", pattern, got)
+		}
+	}
+}
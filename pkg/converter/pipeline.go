@@ -0,0 +1,149 @@
+package converter
+
+import (
+	"image"
+	"runtime"
+	"sync"
+)
+
+// DefaultQueueDepth is the number of frames waiting between two pipeline stages
+const DefaultQueueDepth = 4
+
+// queueDepth returns the pipeline queue depth of opts
+func queueDepth(opts Options) int {
+	if opts.QueueDepth > 0 {
+		return opts.QueueDepth
+	}
+	return DefaultQueueDepth
+}
+
+// job is a frame moving through the stages of a pipeline
+type job struct {
+	index int
+	data  []byte
+	img   image.Image
+}
+
+// pipeline runs jobs through stages connected by bounded channels, so that a
+// slow stage holds back the previous ones instead of piling up frames. The
+// first error cancels the remaining work.
+type pipeline struct {
+	depth int
+	done  chan struct{}
+	once  sync.Once
+	err   error
+	wg    sync.WaitGroup
+}
+
+// newPipeline returns a pipeline whose queues hold up to depth jobs
+func newPipeline(depth int) *pipeline {
+	return &pipeline{depth: depth, done: make(chan struct{})}
+}
+
+// source emits a job for every index from start to end
+func (p *pipeline) source(start, end int) <-chan job {
+	out := make(chan job, p.depth)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(out)
+		for i := start; i < end; i++ {
+			select {
+			case out <- job{index: i}:
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// stage runs fn on the jobs of in with the given number of workers, runtime.NumCPU()
+// when 0, and emits them in the order they complete
+func (p *pipeline) stage(in <-chan job, workers int, fn func(*job) error) <-chan job {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	out := make(chan job, p.depth)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer wg.Done()
+			for j := range in {
+				if err := fn(&j); err != nil {
+					p.fail(err)
+					return
+				}
+				select {
+				case out <- j:
+				case <-p.done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// fail cancels the pipeline, keeping the first error
+func (p *pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		close(p.done)
+	})
+}
+
+// wait stops the pipeline once its output is consumed and returns the first error
+func (p *pipeline) wait() error {
+	p.fail(nil)
+	p.wg.Wait()
+	return p.err
+}
+
+// parallel calls fn for every index below n with the given number of workers,
+// returning the first error
+func parallel(n, workers int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, n)
+
+	indexes := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := fn(i); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	var err error
+	for i := 0; i < n && err == nil; i++ {
+		select {
+		case indexes <- i:
+		case err = <-errs:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	close(errs)
+	if err == nil {
+		err = <-errs
+	}
+	return err
+}
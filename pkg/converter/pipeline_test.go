@@ -0,0 +1,125 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		depth   int
+		fail    int
+	}{
+		{name: "single worker", workers: 1, depth: 1, fail: -1},
+		{name: "several workers", workers: 4, depth: 2, fail: -1},
+		{name: "default workers", workers: 0, depth: 8, fail: -1},
+		{name: "error", workers: 3, depth: 1, fail: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPipeline(tt.depth)
+			jobs := p.source(2, 12)
+			jobs = p.stage(jobs, tt.workers, func(j *job) error {
+				j.data = []byte{byte(j.index)}
+				return nil
+			})
+			jobs = p.stage(jobs, tt.workers, func(j *job) error {
+				if j.index == tt.fail {
+					return fmt.Errorf("frame %d failed", j.index)
+				}
+				j.img = image.NewRGBA(image.Rect(0, 0, int(j.data[0]), 1))
+				return nil
+			})
+
+			seen := make(map[int]bool)
+			for j := range jobs {
+				if j.img.Bounds().Dx() != j.index {
+					t.Errorf("job %d carries frame %d", j.index, j.img.Bounds().Dx())
+				}
+				seen[j.index] = true
+			}
+			err := p.wait()
+			if (err != nil) != (tt.fail >= 0) {
+				t.Fatalf("wait() error = %v", err)
+			}
+			if err == nil && len(seen) != 10 {
+				t.Errorf("pipeline emitted %d jobs, want 10", len(seen))
+			}
+		})
+	}
+}
+
+func TestParallel(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		fail    int
+	}{
+		{name: "one worker", workers: 1, fail: -1},
+		{name: "more workers than items", workers: 50, fail: -1},
+		{name: "default workers", workers: 0, fail: -1},
+		{name: "error", workers: 3, fail: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make([]atomic.Bool, 20)
+			err := parallel(len(done), tt.workers, func(i int) error {
+				if i == tt.fail {
+					return errors.New("failed")
+				}
+				done[i].Store(true)
+				return nil
+			})
+			if (err != nil) != (tt.fail >= 0) {
+				t.Fatalf("parallel() error = %v", err)
+			}
+			if err != nil {
+				return
+			}
+			for i := range done {
+				if !done[i].Load() {
+					t.Errorf("item %d not processed", i)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertPipeline(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 5, 16, 8)
+	report, err := Convert(inputFiles, filepath.Join(tempDir, "out.gif"), Options{IOWorkers: 3, Workers: 2, End: 250 * time.Millisecond, Delay: 100})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.Frames != 3 {
+		t.Errorf("output has %d frames, want 3", report.Frames)
+	}
+
+	// A corrupt frame in the middle stops the pipeline
+	if err := os.WriteFile(inputFiles[2], []byte("not a png"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt frame: %v", err)
+	}
+	if _, err := Convert(inputFiles, filepath.Join(tempDir, "out.gif"), Options{IOWorkers: 2, QueueDepth: 1}); err == nil {
+		t.Error("Convert() expected error for a corrupt frame")
+	}
+
+	if _, err := Convert(inputFiles, filepath.Join(tempDir, "out.gif"), Options{IOWorkers: -1}); err == nil {
+		t.Error("Convert() expected error for negative I/O workers")
+	}
+}
@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Poster modes supported by applyPosterFrame
+const (
+	// PosterDuplicate shows a copy of the poster frame briefly before the original sequence
+	PosterDuplicate = "duplicate"
+	// PosterRotate starts the loop at the poster frame, keeping the cyclic order of the frames
+	PosterRotate = "rotate"
+)
+
+// posterDelay is the shortest delay (in 100ths of a second) honored by most GIF players
+const posterDelay = 2
+
+// applyPosterFrame makes frame n the first frame of the animation so that apps showing
+// only the first frame as a static preview show a meaningful image
+func applyPosterFrame[T any](images []T, delays []int, n int, mode string) ([]T, []int, error) {
+	if n < 0 || n >= len(images) {
+		return nil, nil, fmt.Errorf("poster frame %d out of range (0-%d)", n, len(images)-1)
+	}
+	if n == 0 {
+		return images, delays, nil
+	}
+
+	switch mode {
+	case PosterDuplicate, "":
+		// Prepend a copy of the poster frame that is only visible for a moment,
+		// so playback order is preserved
+		outImages := append([]T{images[n]}, images...)
+		outDelays := append([]int{posterDelay}, delays...)
+		return outImages, outDelays, nil
+	case PosterRotate:
+		// Looping animations play identically from the second cycle on
+		outImages := append(append([]T{}, images[n:]...), images[:n]...)
+		outDelays := append(append([]int{}, delays[n:]...), delays[:n]...)
+		return outImages, outDelays, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown poster mode %q (use %s or %s)", mode, PosterDuplicate, PosterRotate)
+	}
+}
+
+// PosterPath returns the path of the static preview written next to a GIF, e.g. out.gif -> out.png
+func PosterPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".png"
+}
+
+// writePoster saves img as a PNG file
+func writePoster(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating poster image: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("error encoding poster image: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,128 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+func TestApplyPosterFrame(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	var images []*image.Paletted
+	for i := 0; i < 4; i++ {
+		images = append(images, image.NewPaletted(image.Rect(0, 0, 1, 1), palette))
+	}
+	delays := []int{10, 11, 12, 13}
+
+	tests := []struct {
+		name       string
+		n          int
+		mode       string
+		wantFirst  int
+		wantDelays []int
+		wantErr    bool
+	}{
+		{name: "First frame is a no-op", n: 0, mode: PosterDuplicate, wantFirst: 0, wantDelays: []int{10, 11, 12, 13}},
+		{name: "Duplicate", n: 2, mode: PosterDuplicate, wantFirst: 2, wantDelays: []int{posterDelay, 10, 11, 12, 13}},
+		{name: "Rotate", n: 2, mode: PosterRotate, wantFirst: 2, wantDelays: []int{12, 13, 10, 11}},
+		{name: "Out of range", n: 4, mode: PosterDuplicate, wantErr: true},
+		{name: "Unknown mode", n: 1, mode: "shuffle", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotImages, gotDelays, err := applyPosterFrame(images, delays, tt.n, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyPosterFrame() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotImages[0] != images[tt.wantFirst] {
+				t.Errorf("applyPosterFrame() first frame is not frame %d", tt.wantFirst)
+			}
+			if len(gotDelays) != len(tt.wantDelays) || len(gotImages) != len(gotDelays) {
+				t.Fatalf("applyPosterFrame() delays = %v, want %v", gotDelays, tt.wantDelays)
+			}
+			for i := range gotDelays {
+				if gotDelays[i] != tt.wantDelays[i] {
+					t.Errorf("applyPosterFrame() delays = %v, want %v", gotDelays, tt.wantDelays)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPosterPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"out.gif", "out.png"},
+		{"dir/demo-320.gif", "dir/demo-320.png"},
+		{"noext", "noext.png"},
+	}
+
+	for _, tt := range tests {
+		if got := PosterPath(tt.path); got != tt.want {
+			t.Errorf("PosterPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestConvertWithPoster(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 32, 16)
+	outputFile := filepath.Join(tempDir, "out.gif")
+	report, err := Convert(inputFiles, outputFile, Options{Delay: 100, PosterFrame: 2, WithPoster: true, Sizes: []int{32, 16}})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	for _, output := range report.Outputs {
+		if output.Poster != PosterPath(output.File) {
+			t.Errorf("poster = %q, want %q", output.Poster, PosterPath(output.File))
+		}
+		file, err := os.Open(output.Poster)
+		if err != nil {
+			t.Fatalf("Failed to open poster: %v", err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode poster: %v", err)
+		}
+		if img.Bounds().Dx() != output.Width || img.Bounds().Dy() != output.Height {
+			t.Errorf("poster size = %v, want %dx%d", img.Bounds(), output.Width, output.Height)
+		}
+	}
+
+	// The full size poster is the unquantized poster frame
+	file, err := os.Open(report.Outputs[0].Poster)
+	if err != nil {
+		t.Fatalf("Failed to open poster: %v", err)
+	}
+	defer file.Close()
+	poster, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode poster: %v", err)
+	}
+	source, err := decodeFrame(inputFiles[2], decode.DefaultLimits)
+	if err != nil {
+		t.Fatalf("Failed to decode input: %v", err)
+	}
+	if color.RGBAModel.Convert(poster.At(5, 5)) != color.RGBAModel.Convert(source.At(5, 5)) {
+		t.Errorf("poster pixel = %v, want %v", poster.At(5, 5), source.At(5, 5))
+	}
+}
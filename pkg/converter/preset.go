@@ -0,0 +1,217 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// presetMinColors is the smallest palette tried to fit a preset file size
+	presetMinColors = 32
+	// presetMinFrames is the fewest frames kept to fit a preset file size
+	presetMinFrames = 8
+	// presetCRFStep is how much the video CRF grows to fit a preset file size
+	presetCRFStep = 8
+)
+
+// Preset bundles the limits of a platform showing animated images
+type Preset struct {
+	Name        string
+	Description string
+	// Width and Height are the exact output size, 0 keeping the aspect ratio
+	Width, Height int
+	// MaxWidth scales wider outputs down when Width and Height are 0
+	MaxWidth int
+	// MaxFrames limits the number of frames, 0 meaning no limit
+	MaxFrames int
+	// Colors limits the palette, 0 meaning MaxColors
+	Colors int
+	// MaxBytes is the largest file the platform accepts
+	MaxBytes int64
+	// Format is the output format, FormatGIF when empty
+	Format string
+	// MaxDuration cuts longer animations, 0 meaning no limit
+	MaxDuration time.Duration
+	// Loops plays the animation this many times unless Options.Loops is set,
+	// 0 looping forever
+	Loops int
+	// Email shows a detailed first frame to clients showing only that one and
+	// reports which email clients animate the output
+	Email bool
+}
+
+// Presets lists the supported platforms
+var Presets = []Preset{
+	{Name: "slack-emoji", Description: "Slack custom emoji", Width: 128, Height: 128, MaxBytes: 128 << 10},
+	{Name: "discord-emoji", Description: "Discord custom emoji", Width: 128, Height: 128, MaxBytes: 256 << 10},
+	{Name: "discord-sticker", Description: "Discord server sticker", Width: 320, Height: 320, MaxBytes: 512 << 10},
+	{Name: "twitch-emote", Description: "Twitch animated emote", Width: 112, Height: 112, MaxFrames: 60, MaxBytes: 1 << 20},
+	{Name: "telegram-sticker", Description: "Telegram video sticker", Width: 512, Height: 512, MaxBytes: 256 << 10, Format: FormatWebM, MaxDuration: 3 * time.Second},
+	{Name: "telegram-emoji", Description: "Telegram custom emoji", Width: 100, Height: 100, MaxBytes: 64 << 10, Format: FormatWebM, MaxDuration: 3 * time.Second},
+	{Name: "email", Description: "Email newsletter", MaxWidth: 600, MaxBytes: 1 << 20, Format: FormatGIF, Loops: 3, Email: true},
+}
+
+// LookupPreset returns the preset with the given name
+func LookupPreset(name string) (Preset, error) {
+	var names []string
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+		names = append(names, preset.Name)
+	}
+	return Preset{}, fmt.Errorf("unknown preset %q: expected one of %s%s", name, strings.Join(names, ", "), suggest(name, names...))
+}
+
+// fitPreset converts src with the size, duration, frame, color and loop limits
+// of a preset, converting again with fewer colors, or a lower video quality, and
+// then fewer frames until the output is small enough for the platform
+func fitPreset(src source, outputFile string, opts Options) (*Report, error) {
+	preset, err := LookupPreset(opts.Preset)
+	if err != nil {
+		return nil, err
+	}
+	opts.Fit = image.Pt(preset.Width, preset.Height)
+	if preset.MaxWidth > 0 && (opts.MaxWidth == 0 || opts.MaxWidth > preset.MaxWidth) {
+		opts.MaxWidth = preset.MaxWidth
+	}
+	if preset.MaxFrames > 0 && (opts.MaxFrames == 0 || opts.MaxFrames > preset.MaxFrames) {
+		opts.MaxFrames = preset.MaxFrames
+	}
+	if preset.Colors > 0 && paletteSize(opts) > preset.Colors {
+		opts.Colors = preset.Colors
+	}
+	if preset.Format != "" {
+		opts.Format = preset.Format
+	}
+	if preset.MaxDuration > 0 && (opts.MaxDuration == 0 || opts.MaxDuration > preset.MaxDuration) {
+		opts.MaxDuration = preset.MaxDuration
+	}
+	if preset.Loops > 0 && opts.Loops == 0 {
+		opts.Loops = preset.Loops
+	}
+	opts.AutoPoster = opts.AutoPoster || preset.Email
+
+	report, err := fitFileSize(src, outputFile, opts, preset.MaxBytes, preset.Name)
+	if err != nil {
+		return nil, err
+	}
+	if preset.Email {
+		report.Clients = emailSupport(report.Frames)
+	}
+	return report, nil
+}
+
+// fitFileSize converts src, converting again with fewer colors, or a lower
+// video quality, and then fewer frames until the output fits in maxBytes. The
+// report warns about the reductions, and when the output cannot fit, naming
+// the target whose limit it is.
+func fitFileSize(src source, outputFile string, opts Options, maxBytes int64, target string) (*Report, error) {
+	video := isVideo(opts.Format)
+	quality := func() string {
+		if video {
+			return fmt.Sprintf("CRF %d", videoCRF(opts))
+		}
+		return fmt.Sprintf("%d colors", paletteSize(opts))
+	}
+
+	for attempt := 1; ; attempt++ {
+		report, err := convertSource(src, outputFile, opts)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("error checking output size: %v", err)
+		}
+		if info.Size() <= maxBytes {
+			if attempt > 1 {
+				report.warn(WarningPresetReduced, nil, "reduced to %s and %d frames to fit the %d KB limit of %s",
+					quality(), report.Frames, maxBytes>>10, target)
+			}
+			return report, nil
+		}
+
+		// A lower quality degrades the frames less than missing motion
+		switch {
+		case video && videoCRF(opts) < MaxVideoCRF:
+			opts.VideoCRF = min(MaxVideoCRF, videoCRF(opts)+presetCRFStep)
+		case !video && paletteSize(opts) > presetMinColors:
+			opts.Colors = max(presetMinColors, paletteSize(opts)/2)
+		case report.Frames > presetMinFrames:
+			opts.MaxFrames = max(presetMinFrames, report.Frames*2/3)
+		default:
+			report.warn(WarningOverLimit, nil, "the output is %d KB, above the %d KB limit of %s",
+				(info.Size()+1023)>>10, maxBytes>>10, target)
+			return report, nil
+		}
+		if opts.Debug {
+			fmt.Printf("Output is %d bytes, above the %d of %s, retrying with %s and at most %d frames\n",
+				info.Size(), maxBytes, target, quality(), opts.MaxFrames)
+		}
+	}
+}
+
+// dropFrames keeps n evenly spaced frames of [first, last) of src, each
+// shown for as long as the frames dropped after it. It returns a source of the
+// kept frames with their delays and playback positions.
+func dropFrames(src source, delays []int, positions []time.Duration, first, last, n int) (source, []int, []time.Duration) {
+	indices := sampleIndices(first, last, n)
+	kept, keptPositions := keepFrames(src, positions, indices)
+	for k, i := range indices {
+		end := last
+		if k+1 < len(indices) {
+			end = indices[k+1]
+		}
+		for _, delay := range delays[i:end] {
+			kept.delays[k] += delay
+		}
+	}
+	return kept, kept.delays, keptPositions
+}
+
+// keepFrames returns a source of the frames of src at the given indices, with
+// zero delays, and their playback positions
+func keepFrames(src source, positions []time.Duration, indices []int) (source, []time.Duration) {
+	kept := source{
+		names:  make([]string, len(indices)),
+		delays: make([]int, len(indices)),
+		load:   func(k int) (image.Image, error) { return src.load(indices[k]) },
+		files:  src.files,
+	}
+	if src.read != nil {
+		kept.read = func(k int) ([]byte, error) { return src.read(indices[k]) }
+		kept.decode = func(k int, data []byte) (image.Image, error) { return src.decode(indices[k], data) }
+	}
+	if src.density != nil {
+		kept.density = func(k int) (float64, error) { return src.density(indices[k]) }
+	}
+	if len(src.files) == len(src.names) {
+		kept.files = make([]string, len(indices))
+	}
+	if src.tags != nil {
+		kept.tags = make([]map[string]string, len(indices))
+	}
+	if src.captions != nil {
+		kept.captions = make([]string, len(indices))
+	}
+
+	keptPositions := make([]time.Duration, len(indices))
+	for k, i := range indices {
+		kept.names[k] = src.names[i]
+		keptPositions[k] = positions[i]
+		if len(src.files) == len(src.names) {
+			kept.files[k] = src.files[i]
+		}
+		if src.tags != nil {
+			kept.tags[k] = src.tags[i]
+		}
+		if src.captions != nil {
+			kept.captions[k] = src.captions[i]
+		}
+	}
+	return kept, keptPositions
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"image"
+	"image/gif"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLookupPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    image.Point
+		wantErr bool
+	}{
+		{name: "slack-emoji", want: image.Pt(128, 128)},
+		{name: "discord-sticker", want: image.Pt(320, 320)},
+		{name: "myspace-banner", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preset, err := LookupPreset(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupPreset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := image.Pt(preset.Width, preset.Height); !tt.wantErr && got != tt.want {
+				t.Errorf("LookupPreset() size = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropFrames(t *testing.T) {
+	frames := make([]Frame, 10)
+	for i := range frames {
+		frames[i] = Frame{Image: image.NewRGBA(image.Rect(0, 0, 1, 1)), Tags: map[string]string{"n": string(rune('a' + i))}}
+	}
+	src, err := frameSource(frames)
+	if err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+	delays := []int{10, 10, 10, 10, 10, 10, 10, 10, 10, 50}
+	positions := framePositions(delays)
+
+	kept, keptDelays, keptPositions := dropFrames(src, delays, positions, 2, 10, 3)
+	if want := []int{20, 30, 70}; !reflect.DeepEqual(keptDelays, want) {
+		t.Errorf("delays = %v, want %v", keptDelays, want)
+	}
+	if want := []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 700 * time.Millisecond}; !reflect.DeepEqual(keptPositions, want) {
+		t.Errorf("positions = %v, want %v", keptPositions, want)
+	}
+	if want := []string{"frame 2", "frame 4", "frame 7"}; !reflect.DeepEqual(kept.names, want) {
+		t.Errorf("names = %v, want %v", kept.names, want)
+	}
+	if kept.tags[1]["n"] != "e" {
+		t.Errorf("tags of the second kept frame = %v, want those of frame 4", kept.tags[1])
+	}
+}
+
+func TestConvertPreset(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Noise compresses badly, forcing fewer colors and frames
+	rng := rand.New(rand.NewSource(1))
+	var frames []Frame
+	for i := 0; i < 24; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 200, 150))
+		rng.Read(img.Pix)
+		for p := 3; p < len(img.Pix); p += 4 {
+			img.Pix[p] = 255
+		}
+		frames = append(frames, Frame{Image: img, Delay: 50 * time.Millisecond})
+	}
+
+	outputFile := filepath.Join(tempDir, "emoji.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{Preset: "slack-emoji"})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Width != 128 || report.Height != 128 {
+		t.Errorf("output size = %dx%d, want 128x128", report.Width, report.Height)
+	}
+	if len(report.Warnings) == 0 {
+		t.Error("expected a warning about the reduced colors and frames")
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	total := 0
+	for _, delay := range g.Delay {
+		total += delay
+	}
+	if total != 24*5 {
+		t.Errorf("duration = %d, want %d, dropped frames should extend the kept ones", total, 24*5)
+	}
+
+	if _, err := ConvertFrames(frames, outputFile, Options{Preset: "slack-emoji", Width: 64}); err == nil {
+		t.Error("ConvertFrames() with a preset and a width should fail")
+	}
+}
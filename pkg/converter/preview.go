@@ -0,0 +1,12 @@
+package converter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// PreviewPath returns the path a preview of outputFile is written to, e.g.
+// demo.gif -> demo.preview.gif
+func PreviewPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".preview" + filepath.Ext(outputFile)
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPreviewPath(t *testing.T) {
+	tests := []struct {
+		outputFile string
+		want       string
+	}{
+		{outputFile: "demo.gif", want: "demo.preview.gif"},
+		{outputFile: "out/demo.webp", want: "out/demo.preview.webp"},
+		{outputFile: "demo", want: "demo.preview"},
+	}
+
+	for _, tt := range tests {
+		if got := PreviewPath(tt.outputFile); got != tt.want {
+			t.Errorf("PreviewPath(%q) = %q, want %q", tt.outputFile, got, tt.want)
+		}
+	}
+}
+
+func TestConvertPreviewFrames(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 6; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+		img.Set(i, 0, color.White)
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantFrames int
+		wantErr    bool
+	}{
+		{name: "first frames", opts: Options{PreviewFrames: 2}, wantFrames: 2},
+		{name: "more than the frames", opts: Options{PreviewFrames: 10}, wantFrames: 6},
+		{name: "after max frames", opts: Options{PreviewFrames: 2, MaxFrames: 3}, wantFrames: 2},
+		{name: "negative", opts: Options{PreviewFrames: -1}, wantErr: true},
+		{name: "with a check", opts: Options{PreviewFrames: 2, Check: CheckIAB}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, "demo.gif")
+			report, err := ConvertFrames(frames, outputFile, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertFrames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !report.Preview || report.OutputFile != PreviewPath(outputFile) {
+				t.Errorf("report = %s, preview %v, want a preview at %s", report.OutputFile, report.Preview, PreviewPath(outputFile))
+			}
+			if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+				t.Errorf("the preview wrote the full output: %v", err)
+			}
+
+			f, err := os.Open(report.OutputFile)
+			if err != nil {
+				t.Fatalf("Failed to open preview: %v", err)
+			}
+			defer f.Close()
+			decoded, err := gif.DecodeAll(f)
+			if err != nil {
+				t.Fatalf("Failed to decode preview: %v", err)
+			}
+			if len(decoded.Image) != tt.wantFrames || report.Frames != tt.wantFrames {
+				t.Errorf("preview has %d frames, report %d, want %d", len(decoded.Image), report.Frames, tt.wantFrames)
+			}
+			os.Remove(report.OutputFile)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/ui"
+)
+
+// appendProgress appends a timestamped record to the progress log at path,
+// opening it for every record so that it can be rotated while converting
+func appendProgress(path string, format string, args ...any) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening progress log: %v", err)
+	}
+	defer file.Close()
+
+	line := time.Now().Format(time.RFC3339) + " " + fmt.Sprintf(format, args...) + "\n"
+	if _, err := file.WriteString(line); err != nil {
+		return fmt.Errorf("error writing progress log: %v", err)
+	}
+	return file.Close()
+}
+
+// progressRecord describes msg, sent elapsed after the start of the frames
+func progressRecord(msg ui.ProgressMsg, elapsed time.Duration) string {
+	record := fmt.Sprintf("%d/%d frames", msg.Processed, msg.Total)
+	if msg.Total > 0 {
+		record += fmt.Sprintf(" (%.1f%%)", 100*float64(msg.Processed)/float64(msg.Total))
+	}
+	record += fmt.Sprintf(", elapsed %v", elapsed.Round(time.Second))
+	if msg.Processed > 0 && msg.Processed < msg.Total {
+		left := elapsed * time.Duration(msg.Total-msg.Processed) / time.Duration(msg.Processed)
+		record += fmt.Sprintf(", about %v left", left.Round(time.Second))
+	}
+	return record + ": " + msg.CurrentFile
+}
+
+// logProgress returns a channel forwarding the progress messages to the UI
+// channel after appending a record of each to the progress log at path.
+// Failing to write the log never fails the conversion.
+func logProgress(progressChan chan ui.ProgressMsg, path string) chan ui.ProgressMsg {
+	logged := make(chan ui.ProgressMsg)
+	start := time.Now()
+	go func() {
+		for msg := range logged {
+			appendProgress(path, "%s", progressRecord(msg, time.Since(start)))
+			progressChan <- msg
+		}
+	}()
+	return logged
+}
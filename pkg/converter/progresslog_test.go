@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/ui"
+)
+
+func TestProgressRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     ui.ProgressMsg
+		elapsed time.Duration
+		want    string
+	}{
+		{
+			name:    "started",
+			msg:     ui.ProgressMsg{CurrentFile: "a.png", Processed: 0, Total: 10},
+			elapsed: 0,
+			want:    "0/10 frames (0.0%), elapsed 0s: a.png",
+		},
+		{
+			name:    "halfway",
+			msg:     ui.ProgressMsg{CurrentFile: "e.png", Processed: 5, Total: 10},
+			elapsed: 90 * time.Second,
+			want:    "5/10 frames (50.0%), elapsed 1m30s, about 1m30s left: e.png",
+		},
+		{
+			name:    "done",
+			msg:     ui.ProgressMsg{CurrentFile: "Creating output GIF", Processed: 10, Total: 10},
+			elapsed: 3 * time.Minute,
+			want:    "10/10 frames (100.0%), elapsed 3m0s: Creating output GIF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := progressRecord(tt.msg, tt.elapsed); got != tt.want {
+				t.Errorf("progressRecord() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertProgressLog(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		frames = append(frames, Frame{Image: image.NewRGBA(image.Rect(0, 0, 8, 8))})
+	}
+	log := filepath.Join(tempDir, "progress.log")
+	output := filepath.Join(tempDir, "out.gif")
+
+	// Records are appended to the log of the previous runs
+	if _, err := ConvertFrames(frames, output, Options{Delay: 100, ProgressLog: log}); err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	hook := func(index int, img draw.Image) error { return fmt.Errorf("out of ink") }
+	if _, err := ConvertFrames(frames, output, Options{Delay: 100, ProgressLog: log, FrameHook: hook}); err == nil {
+		t.Fatalf("ConvertFrames() with a failing hook succeeded")
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("Failed to read progress log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	var records []string
+	for _, line := range lines {
+		timestamp, record, _ := strings.Cut(line, " ")
+		if _, err := time.Parse(time.RFC3339, timestamp); err != nil {
+			t.Errorf("record %q has no timestamp: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	if !strings.HasPrefix(records[0], "started converting 3 frames to "+output) {
+		t.Errorf("first record = %q, want the start", records[0])
+	}
+	if !strings.Contains(strings.Join(records, "\n"), "3/3 frames (100.0%)") {
+		t.Errorf("records = %q, want the last frame processed", records)
+	}
+	finished := -1
+	for i, record := range records {
+		if strings.HasPrefix(record, "finished 3 frames to ") {
+			finished = i
+		}
+	}
+	if finished < 0 || !strings.HasPrefix(records[finished+1], "started converting") {
+		t.Errorf("records = %q, want the first run finished before the second one", records)
+	}
+	if last := records[len(records)-1]; !strings.HasPrefix(last, "failed after") || !strings.Contains(last, "out of ink") {
+		t.Errorf("last record = %q, want the failure", last)
+	}
+
+	// An unwritable log fails before any work
+	missing := filepath.Join(tempDir, "missing", "progress.log")
+	if _, err := ConvertFrames(frames, output, Options{Delay: 100, ProgressLog: missing}); err == nil || !strings.Contains(err.Error(), "progress log") {
+		t.Errorf("ConvertFrames() error = %v, want a progress log error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/gamut"
+)
+
+// validateProof checks the gamuts of opts
+func validateProof(opts Options) error {
+	if opts.SourceGamut != "" {
+		if _, err := gamut.Parse(opts.SourceGamut); err != nil {
+			return fmt.Errorf("invalid source gamut: %v", err)
+		}
+	}
+	if opts.Proof == "" {
+		return nil
+	}
+	if _, err := gamut.Parse(opts.Proof); err != nil {
+		return fmt.Errorf("invalid proof gamut: %v", err)
+	}
+	if !isGIF(opts.Format) {
+		return fmt.Errorf("proofs are only written for GIF outputs")
+	}
+	return nil
+}
+
+// sourceGamut returns the gamut the inputs of opts are encoded in
+func sourceGamut(opts Options) gamut.Gamut {
+	if opts.SourceGamut != "" {
+		return gamut.Gamut(opts.SourceGamut)
+	}
+	return gamut.SRGB
+}
+
+// ProofPath returns the path of the gamut proof written next to a GIF, e.g.
+// out.gif -> out.proof-srgb.gif
+func ProofPath(outputFile, target string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".proof-" + target + filepath.Ext(outputFile)
+}
+
+// proofImages returns the quantized images with their palettes mapped from
+// the source to the target gamut, sharing their pixels, and the share of the
+// visible pixels whose color the target gamut clips
+func proofImages(images []*image.Paletted, source, target gamut.Gamut) ([]*image.Paletted, float64) {
+	type proofed struct {
+		color   color.Color
+		clipped bool
+	}
+	cache := make(map[color.Color]proofed)
+
+	proofs := make([]*image.Paletted, len(images))
+	visible, clipped := 0, 0
+	for i, img := range images {
+		palette := make(color.Palette, len(img.Palette))
+		clippedIndex := make([]bool, len(img.Palette))
+		for j, c := range img.Palette {
+			p, ok := cache[c]
+			if !ok {
+				p.color, p.clipped = gamut.Proof(c, source, target)
+				cache[c] = p
+			}
+			palette[j], clippedIndex[j] = p.color, p.clipped
+		}
+		proofs[i] = &image.Paletted{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect, Palette: palette}
+
+		// Transparent pixels of delta frames show the previous frame
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			row := img.Pix[(y-img.Rect.Min.Y)*img.Stride:]
+			for x := 0; x < img.Rect.Dx(); x++ {
+				index := row[x]
+				if int(index) >= len(img.Palette) {
+					continue
+				}
+				if _, _, _, a := img.Palette[index].RGBA(); a == 0 {
+					continue
+				}
+				visible++
+				if clippedIndex[index] {
+					clipped++
+				}
+			}
+		}
+	}
+	if visible == 0 {
+		return proofs, 0
+	}
+	return proofs, float64(clipped) / float64(visible)
+}
@@ -0,0 +1,84 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+func TestProofPath(t *testing.T) {
+	tests := []struct {
+		outputFile string
+		target     string
+		want       string
+	}{
+		{outputFile: "out.gif", target: "srgb", want: "out.proof-srgb.gif"},
+		{outputFile: "dir/banner.v2.gif", target: "p3", want: "dir/banner.v2.proof-p3.gif"},
+	}
+
+	for _, tt := range tests {
+		if got := ProofPath(tt.outputFile, tt.target); got != tt.want {
+			t.Errorf("ProofPath(%q, %q) = %q, want %q", tt.outputFile, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestConvertProof(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A saturated Display P3 red next to a gray that every gamut shows
+	var frames []Frame
+	for i := 0; i < 2; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 8; x++ {
+				img.Set(x, y, color.RGBA{128, 128, 128, 255})
+				if x < 4+i {
+					img.Set(x, y, color.RGBA{255, 0, 0, 255})
+				}
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	outputFile := filepath.Join(tempDir, "brand.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{Proof: "srgb", SourceGamut: "p3"})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	proofFile := ProofPath(report.OutputFile, "srgb")
+	if report.Outputs[0].Proof != proofFile {
+		t.Errorf("proof = %q, want %q", report.Outputs[0].Proof, proofFile)
+	}
+	if len(report.Warnings) == 0 {
+		t.Error("expected a warning about the clipped red")
+	}
+
+	reader, err := gifutil.Open(proofFile)
+	if err != nil {
+		t.Fatalf("Failed to open proof: %v", err)
+	}
+	frame, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Failed to read proof: %v", err)
+	}
+	if got := frame.Image.At(0, 0).(color.RGBA); got.G == 0 && got.B == 0 {
+		t.Errorf("proofed red = %v, want the clipped color", got)
+	}
+	if got := frame.Image.At(7, 0).(color.RGBA); got != (color.RGBA{128, 128, 128, 255}) {
+		t.Errorf("proofed gray = %v, want it unchanged", got)
+	}
+
+	if _, err := ConvertFrames(frames, outputFile, Options{Proof: "cmyk"}); err == nil {
+		t.Error("ConvertFrames() with an unknown proof gamut should fail")
+	}
+}
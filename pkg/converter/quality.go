@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"slices"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+	"github.com/jparrill/go-togif/pkg/quality"
+)
+
+// validateQuality checks the quality gate of opts
+func validateQuality(opts Options) error {
+	if opts.MinSSIM < 0 || opts.MinSSIM > 1 {
+		return fmt.Errorf("min SSIM must be between 0 and 1")
+	}
+	if !scored(opts) {
+		return nil
+	}
+	if !slices.ContainsFunc(effectiveFormats(opts), isGIF) {
+		return fmt.Errorf("quality is only scored for GIF outputs")
+	}
+	return nil
+}
+
+// scored reports whether the first GIF output of opts is compared to its frames
+func scored(opts Options) bool {
+	return opts.Score || opts.MinSSIM > 0
+}
+
+// scoreGIF returns the mean SSIM and PSNR of the composited frames of the GIF
+// at path to the reference frames, resized to size
+func scoreGIF(path string, reference []image.Image, size image.Rectangle, scaler string) (float64, float64, error) {
+	reader, err := gifutil.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error scoring output: %v", err)
+	}
+	if reader.Len() != len(reference) {
+		return 0, 0, fmt.Errorf("error scoring output: %d frames written, %d expected", reader.Len(), len(reference))
+	}
+
+	var ssim, psnr float64
+	for _, ref := range reference {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("error scoring output: %v", err)
+		}
+		resized := resizeTo(loaded(ref), size, scaler)
+		ssim += quality.SSIM(frame.Image, resized)
+		psnr += quality.PSNR(frame.Image, resized)
+	}
+	return ssim / float64(len(reference)), psnr / float64(len(reference)), nil
+}
@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/quality"
+)
+
+func TestConvertScore(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+		for x := 0; x < 32; x++ {
+			for y := 0; y < 16; y++ {
+				img.Set(x, y, color.RGBA{uint8(x * 8), uint8(y * 16), uint8(i * 60), 255})
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	full, err := ConvertFrames(frames, filepath.Join(tempDir, "full.gif"), Options{Score: true})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	few, err := ConvertFrames(frames, filepath.Join(tempDir, "few.gif"), Options{Score: true, Colors: 4})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if full.SSIM <= 0 || full.SSIM > 1 || full.PSNR <= 0 || full.PSNR > quality.MaxPSNR {
+		t.Errorf("scores with 256 colors = SSIM %v, PSNR %v, want within (0, 1] and (0, %d]", full.SSIM, full.PSNR, quality.MaxPSNR)
+	}
+	if few.SSIM >= full.SSIM || few.PSNR >= full.PSNR {
+		t.Errorf("scores with 4 colors = SSIM %v, PSNR %v, want below %v and %v with 256 colors", few.SSIM, few.PSNR, full.SSIM, full.PSNR)
+	}
+
+	unscored, err := ConvertFrames(frames, filepath.Join(tempDir, "unscored.gif"), Options{})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if unscored.SSIM != 0 || unscored.PSNR != 0 {
+		t.Errorf("scores without Score = SSIM %v, PSNR %v, want 0", unscored.SSIM, unscored.PSNR)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "above the minimum", opts: Options{MinSSIM: few.SSIM / 2, Colors: 4}},
+		{name: "below the minimum", opts: Options{MinSSIM: (few.SSIM + full.SSIM) / 2, Colors: 4}, wantErr: true},
+		{name: "out of range", opts: Options{MinSSIM: 1.5}, wantErr: true},
+		{name: "no GIF output", opts: Options{MinSSIM: 0.9, Format: FormatPDF}, wantErr: true},
+		{name: "GIF among the formats", opts: Options{MinSSIM: 0.1, Formats: []string{FormatPDF, FormatGIF}}},
+		{name: "preset of another format", opts: Options{Score: true, Preset: "telegram-sticker"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ConvertFrames(frames, filepath.Join(tempDir, "gate.gif"), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ConvertFrames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && report.SSIM < tt.opts.MinSSIM {
+				t.Errorf("SSIM = %v, want at least %v", report.SSIM, tt.opts.MinSSIM)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+)
+
+// ROI is a named region of interest of the frames, written to its own GIF
+// with Options.ROIs
+type ROI struct {
+	Name string
+	// Rect is the region, in output frame coordinates
+	Rect image.Rectangle
+}
+
+// ParseROI parses a region of interest given as "name=x,y,WxH"
+func ParseROI(s string) (ROI, error) {
+	name, region, ok := strings.Cut(s, "=")
+	if !ok {
+		return ROI{}, fmt.Errorf("invalid region of interest %q: expected name=x,y,WxH", s)
+	}
+	name = strings.TrimSpace(name)
+	if err := validateROIName(name); err != nil {
+		return ROI{}, err
+	}
+	r, err := ParseRegion(region)
+	if err != nil {
+		return ROI{}, err
+	}
+	return ROI{Name: name, Rect: r}, nil
+}
+
+// validateROIName checks name can be used in a file name
+func validateROIName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty region of interest name")
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Errorf("invalid region of interest name %q: use letters, digits, - and _ only", name)
+		}
+	}
+	return nil
+}
+
+// validateROIs checks every region of interest has a distinct valid name and
+// a positive size
+func validateROIs(rois []ROI) error {
+	seen := make(map[string]bool)
+	for _, roi := range rois {
+		if err := validateROIName(roi.Name); err != nil {
+			return err
+		}
+		if seen[roi.Name] {
+			return fmt.Errorf("duplicate region of interest %q", roi.Name)
+		}
+		seen[roi.Name] = true
+		if roi.Rect.Empty() || roi.Rect.Min.X < 0 || roi.Rect.Min.Y < 0 {
+			return fmt.Errorf("region of interest %q must be a positive area inside the frames", roi.Name)
+		}
+	}
+	return nil
+}
+
+// ROIOutputPath returns the path of the output of a region of interest, e.g.
+// out.gif -> out-terminal.gif
+func ROIOutputPath(outputFile, name string) string {
+	ext := filepath.Ext(outputFile)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(outputFile, ext), name, ext)
+}
+
+// roiOutputs returns one output per region of interest
+func roiOutputs(outputFile string, rois []ROI) []Output {
+	outputs := make([]Output, len(rois))
+	for i, roi := range rois {
+		outputs[i] = Output{
+			File:   ROIOutputPath(outputFile, roi.Name),
+			Width:  roi.Rect.Dx(),
+			Height: roi.Rect.Dy(),
+			Format: FormatGIF,
+			Region: roi.Name,
+		}
+	}
+	return outputs
+}
+
+// cropFrames returns copies of the frames cropped to the region of interest
+func cropFrames(frames []image.Image, roi ROI) ([]image.Image, error) {
+	cropped := make([]image.Image, len(frames))
+	for i, img := range frames {
+		var err error
+		if cropped[i], err = cropImage(loaded(img), roi.Rect, roi.Name); err != nil {
+			return nil, err
+		}
+	}
+	return cropped, nil
+}
+
+// cropRegions maps regions of full frames to the frames cropped to r,
+// leaving out those outside it
+func cropRegions(regions []image.Rectangle, r image.Rectangle) []image.Rectangle {
+	var cropped []image.Rectangle
+	for _, region := range regions {
+		if region = region.Intersect(r); !region.Empty() {
+			cropped = append(cropped, region.Sub(r.Min))
+		}
+	}
+	return cropped
+}
@@ -0,0 +1,145 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseROI(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ROI
+		wantErr bool
+	}{
+		{spec: "terminal=0,0,960x1080", want: ROI{Name: "terminal", Rect: image.Rect(0, 0, 960, 1080)}},
+		{spec: " browser_2 = 960,10,100x50", want: ROI{Name: "browser_2", Rect: image.Rect(960, 10, 1060, 60)}},
+		{spec: "0,0,960x1080", wantErr: true},
+		{spec: "=0,0,10x10", wantErr: true},
+		{spec: "../pane=0,0,10x10", wantErr: true},
+		{spec: "pane=0,0,0x10", wantErr: true},
+		{spec: "pane=-1,0,10x10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseROI(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseROI(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseROI(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestROIOutputPath(t *testing.T) {
+	if got, want := ROIOutputPath("out/demo.gif", "terminal"), "out/demo-terminal.gif"; got != want {
+		t.Errorf("ROIOutputPath() = %q, want %q", got, want)
+	}
+}
+
+func TestCropRegions(t *testing.T) {
+	regions := []image.Rectangle{image.Rect(0, 0, 10, 10), image.Rect(15, 5, 30, 10), image.Rect(50, 50, 60, 60)}
+	got := cropRegions(regions, image.Rect(5, 0, 25, 20))
+	if want := []image.Rectangle{image.Rect(0, 0, 5, 10), image.Rect(10, 5, 20, 10)}; !slices.Equal(got, want) {
+		t.Errorf("cropRegions() = %v, want %v", got, want)
+	}
+}
+
+func TestConvertWithROIs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A dark terminal pane on the left, a photo-like browser pane on the right
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 64; x++ {
+				c := color.RGBA{10, 10, 10, 255}
+				if x == i+2 && y < 8 {
+					c = color.RGBA{0, 255, 0, 255}
+				}
+				if x >= 32 {
+					c = color.RGBA{uint8(x*4 + i), uint8(y * 8), uint8(x ^ y), 255}
+				}
+				img.SetRGBA(x, y, c)
+			}
+		}
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "Two panes", opts: Options{ROIs: []ROI{
+			{Name: "terminal", Rect: image.Rect(0, 0, 32, 32)},
+			{Name: "browser", Rect: image.Rect(32, 0, 64, 32)},
+		}}},
+		{name: "Outside the frames", opts: Options{ROIs: []ROI{{Name: "pane", Rect: image.Rect(48, 0, 80, 32)}}}, wantErr: "outside the 64x32 frames"},
+		{name: "With sizes", opts: Options{ROIs: []ROI{{Name: "pane", Rect: image.Rect(0, 0, 8, 8)}}, Sizes: []int{32}}, wantErr: "mutually exclusive"},
+		{name: "Duplicate names", opts: Options{ROIs: []ROI{
+			{Name: "pane", Rect: image.Rect(0, 0, 8, 8)},
+			{Name: "pane", Rect: image.Rect(8, 0, 16, 8)},
+		}}, wantErr: `duplicate region of interest "pane"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, "demo.gif")
+			report, err := ConvertFrames(frames, outputFile, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ConvertFrames() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertFrames() error = %v", err)
+			}
+			if len(report.Outputs) != 2 {
+				t.Fatalf("report has %d outputs, want 2", len(report.Outputs))
+			}
+			if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+				t.Errorf("the regions wrote the full output: %v", err)
+			}
+
+			var palettes []int
+			for i, output := range report.Outputs {
+				roi := tt.opts.ROIs[i]
+				if output.Region != roi.Name || filepath.Base(output.File) != "demo-"+roi.Name+".gif" {
+					t.Errorf("output %d = %s of region %q, want demo-%s.gif", i, output.File, output.Region, roi.Name)
+				}
+				file, err := os.Open(output.File)
+				if err != nil {
+					t.Fatalf("Failed to open output: %v", err)
+				}
+				g, err := gif.DecodeAll(file)
+				file.Close()
+				if err != nil {
+					t.Fatalf("Failed to decode output: %v", err)
+				}
+				if len(g.Image) != 3 || g.Image[0].Bounds() != image.Rect(0, 0, 32, 32) {
+					t.Errorf("output %d has %d frames of %v, want 3 frames of 32x32", i, len(g.Image), g.Image[0].Bounds())
+				}
+				palettes = append(palettes, len(g.Image[0].Palette))
+			}
+			// The terminal pane only needs its own two colors
+			if palettes[0] != 2 || palettes[1] <= 2 {
+				t.Errorf("palettes have %v colors, want 2 for the terminal and more for the browser", palettes)
+			}
+		})
+	}
+}
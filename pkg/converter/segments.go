@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Segment is a run of frames of the same content, quantized with its own
+// palette and settings with Options.Segments
+type Segment struct {
+	// First and Last are the inclusive range of the segment, by the index of
+	// the frames in the inputs
+	First     int    `json:"first"`
+	Last      int    `json:"last"`
+	Content   string `json:"content"`
+	Quantizer string `json:"quantizer"`
+	Dither    string `json:"dither"`
+	Colors    int    `json:"colors"`
+	// PreserveText is set when the text areas of the segment are kept exact
+	PreserveText bool `json:"preserveText,omitempty"`
+}
+
+// String describes the segment in a single line
+func (s Segment) String() string {
+	text := fmt.Sprintf("frames %d-%d, %s content: %s quantizer, %s dithering, %d colors",
+		s.First, s.Last, s.Content, s.Quantizer, s.Dither, s.Colors)
+	if s.PreserveText {
+		text += ", text preservation"
+	}
+	return text
+}
+
+// frameSegment is a segment of the frames being converted, [first, last) by
+// their index in the frames
+type frameSegment struct {
+	first, last int
+	content     string
+	opts        Options
+	palette     color.Palette
+	regions     [][]image.Rectangle
+}
+
+// detectSegments splits frames into runs of consecutive frames of the same
+// content. Every run fills in the quantizer, dithering, colors and text
+// preservation of base left unset for its content, like Options.Auto, and
+// gets a palette built from its own frames.
+func detectSegments(frames []image.Image, base Options) []frameSegment {
+	var segments []frameSegment
+	for i, img := range frames {
+		content, _ := classifyContent([]image.Image{loaded(img)})
+		if i > 0 && segments[len(segments)-1].content == content {
+			segments[len(segments)-1].last = i + 1
+			continue
+		}
+		segments = append(segments, frameSegment{first: i, last: i + 1, content: content})
+	}
+
+	for s := range segments {
+		segment := &segments[s]
+		segmentFrames := frames[segment.first:segment.last]
+
+		// Count the colors on a few frames, like Options.Auto
+		var samples []image.Image
+		for _, i := range sampleIndices(segment.first, segment.last, autoSamples) {
+			samples = append(samples, loaded(frames[i]))
+		}
+		_, colors := classifyContent(samples)
+		opts, _ := applyAuto(base, segment.content, colors, frames[segment.first].Bounds())
+
+		// The output size and scaling are the same for every segment
+		segment.opts = base
+		segment.opts.Quantizer, segment.opts.Dither, segment.opts.Colors = opts.Quantizer, opts.Dither, opts.Colors
+		segment.opts.PreserveText = opts.PreserveText
+
+		segment.regions = findTextRegions(segmentFrames, segment.opts)
+		segment.palette = generatePalette(segmentFrames, segment.regions, segment.opts)
+		if base.Debug {
+			fmt.Printf("Segment of frames %d-%d: %s content, %d colors\n", segment.first, segment.last-1, segment.content, len(segment.palette))
+		}
+	}
+	return segments
+}
+
+// report describes the segment, mapping its frames to their index in the
+// inputs
+func (s frameSegment) report(inputs []int) Segment {
+	return Segment{
+		First:        inputs[s.first],
+		Last:         inputs[s.last-1],
+		Content:      s.content,
+		Quantizer:    s.opts.Quantizer,
+		Dither:       s.opts.Dither,
+		Colors:       s.opts.Colors,
+		PreserveText: s.opts.PreserveText,
+	}
+}
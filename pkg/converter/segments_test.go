@@ -0,0 +1,143 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// slideFrame returns a flat frame of two colors, like a presentation slide
+func slideFrame(width, height int, background color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := background
+			if y > height/3 && y < height/2 && x > width/4 {
+				c = color.RGBA{20, 20, 20, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// photoFrame returns a frame of noisy gradients, like a photo
+func photoFrame(width, height, seed int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x*7 + seed*13), uint8(y * 5), uint8((x ^ y) + seed), 255})
+		}
+	}
+	return img
+}
+
+func TestDetectSegments(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	frames := []image.Image{
+		slideFrame(32, 16, white), slideFrame(32, 16, white),
+		photoFrame(32, 16, 1), photoFrame(32, 16, 2),
+		slideFrame(32, 16, color.RGBA{0, 80, 160, 255}),
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []Segment
+	}{
+		{
+			name: "Settings per content",
+			want: []Segment{
+				{First: 0, Last: 1, Content: ContentPixelArt, Quantizer: QuantizerFrequency, Dither: DitherNone, Colors: 2},
+				{First: 2, Last: 3, Content: ContentPhoto, Quantizer: QuantizerMedianCut, Dither: DitherFloydSteinberg, Colors: MaxColors},
+				{First: 4, Last: 4, Content: ContentPixelArt, Quantizer: QuantizerFrequency, Dither: DitherNone, Colors: 2},
+			},
+		},
+		{
+			name: "Settings set explicitly are kept",
+			opts: Options{Dither: DitherNone, Colors: 64},
+			want: []Segment{
+				{First: 0, Last: 1, Content: ContentPixelArt, Quantizer: QuantizerFrequency, Dither: DitherNone, Colors: 64},
+				{First: 2, Last: 3, Content: ContentPhoto, Quantizer: QuantizerMedianCut, Dither: DitherNone, Colors: 64},
+				{First: 4, Last: 4, Content: ContentPixelArt, Quantizer: QuantizerFrequency, Dither: DitherNone, Colors: 64},
+			},
+		},
+	}
+
+	inputs := []int{0, 1, 2, 3, 4}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := detectSegments(frames, tt.opts)
+			if len(segments) != len(tt.want) {
+				t.Fatalf("detectSegments() = %d segments, want %d", len(segments), len(tt.want))
+			}
+			for s, segment := range segments {
+				if got := segment.report(inputs); got != tt.want[s] {
+					t.Errorf("segment %d = %v, want %v", s, got, tt.want[s])
+				}
+				if len(segment.palette) == 0 || len(segment.palette) > segment.opts.Colors {
+					t.Errorf("segment %d palette has %d colors, want 1-%d", s, len(segment.palette), segment.opts.Colors)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertWithSegments(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var inputFiles []string
+	for i, img := range []image.Image{
+		slideFrame(32, 16, color.RGBA{255, 255, 255, 255}), photoFrame(32, 16, 1), photoFrame(32, 16, 2),
+	} {
+		path := filepath.Join(tempDir, "frame"+strconv.Itoa(i)+".png")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create frame: %v", err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatalf("Failed to encode frame: %v", err)
+		}
+		file.Close()
+		inputFiles = append(inputFiles, path)
+	}
+
+	outputFile := filepath.Join(tempDir, "out.gif")
+	report, err := Convert(inputFiles, outputFile, Options{Delay: 100, Segments: true, Auto: true})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(report.Segments) != 2 || report.Segments[0].Content != ContentPixelArt || report.Segments[1].Content != ContentPhoto {
+		t.Fatalf("report segments = %v, want a slide and a photo segment", report.Segments)
+	}
+	// Auto picks settings for the whole sequence, the segments their own
+	if report.Segments[0].Dither == report.Segments[1].Dither {
+		t.Errorf("segments dither = %s and %s, want settings per segment", report.Segments[0].Dither, report.Segments[1].Dither)
+	}
+
+	// The slide keeps its exact colors in its own palette
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	g, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if len(g.Image[0].Palette) != 2 {
+		t.Errorf("slide palette has %d colors, want 2", len(g.Image[0].Palette))
+	}
+	if len(g.Image[1].Palette) <= 2 {
+		t.Errorf("photo palette has %d colors, want more than the slide", len(g.Image[1].Palette))
+	}
+}
@@ -0,0 +1,170 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// SidecarSuffix is appended to the name of an input file to find its sidecar
+const SidecarSuffix = ".json"
+
+// Sidecar holds the options of a single frame, read from a JSON file next to
+// its PNG (frame.png.json) and merged with the global options
+type Sidecar struct {
+	// Delay is how long the frame is shown, such as "2s", overriding Options.Delay
+	// but not the delay of an input manifest line
+	Delay string `json:"delay,omitempty"`
+	// Crop keeps only an area of the frame, given as "x,y,WxH" (see ParseRegion)
+	Crop string `json:"crop,omitempty"`
+	// Caption is burned onto the frame instead of the subtitles
+	Caption string `json:"caption,omitempty"`
+	// Skip leaves the frame out of the animation
+	Skip bool `json:"skip,omitempty"`
+	// Tags are passed to the overlays in FrameMeta.Tags
+	Tags map[string]string `json:"tags,omitempty"`
+
+	delay time.Duration
+	crop  image.Rectangle
+}
+
+// SidecarPath returns the path of the sidecar of an input file
+func SidecarPath(inputFile string) string {
+	return inputFile + SidecarSuffix
+}
+
+// LoadSidecar reads and validates the sidecar of an input file, nil when it has none
+func LoadSidecar(inputFile string) (*Sidecar, error) {
+	path := SidecarPath(inputFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading sidecar %s: %v", path, err)
+	}
+
+	// Unknown fields are rejected so that typos do not go unnoticed
+	var sidecar Sidecar
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&sidecar); err != nil {
+		return nil, fmt.Errorf("error parsing sidecar %s: %v", path, err)
+	}
+
+	if sidecar.Delay != "" {
+		if sidecar.delay, err = time.ParseDuration(sidecar.Delay); err != nil || sidecar.delay < 0 {
+			return nil, fmt.Errorf("sidecar %s: invalid delay %q", path, sidecar.Delay)
+		}
+	}
+	if sidecar.Crop != "" {
+		if sidecar.crop, err = ParseRegion(sidecar.Crop); err != nil {
+			return nil, fmt.Errorf("sidecar %s: %v", path, err)
+		}
+	}
+	return &sidecar, nil
+}
+
+// inputSource reads frames from PNG, WebP, BMP and TIFF files, applying their sidecars unless
+// opts.IgnoreSidecars: skipped files are left out, and the delays, crops,
+// captions and tags they set are attached to the frames
+func inputSource(inputFiles []string, opts Options) (source, error) {
+	delays, err := fileDelays(inputFiles, opts)
+	if err != nil {
+		return source{}, err
+	}
+	if opts.IgnoreSidecars {
+		if err := opts.InputLimits.checkInputFiles(inputFiles); err != nil {
+			return source{}, err
+		}
+		src := fileSource(inputFiles, opts.InputLimits.decodeLimits())
+		src.delays = delays
+		return src, nil
+	}
+
+	var files []string
+	var kept []int
+	var sidecars []*Sidecar
+	for i, file := range inputFiles {
+		sidecar, err := LoadSidecar(file)
+		if err != nil {
+			return source{}, err
+		}
+		if sidecar != nil && sidecar.Skip {
+			continue
+		}
+		// A manifest delay is more specific than the sidecar one
+		delay := delays[i]
+		if sidecar != nil && delay == 0 {
+			delay = centiseconds(sidecar.delay)
+		}
+		files = append(files, file)
+		kept = append(kept, delay)
+		sidecars = append(sidecars, sidecar)
+	}
+	if len(files) == 0 {
+		return source{}, fmt.Errorf("every input file is skipped by its sidecar")
+	}
+
+	if err := opts.InputLimits.checkInputFiles(files); err != nil {
+		return source{}, err
+	}
+	src := fileSource(files, opts.InputLimits.decodeLimits())
+	src.delays = kept
+	cropped := false
+	for i, sidecar := range sidecars {
+		if sidecar == nil {
+			continue
+		}
+		if sidecar.Caption != "" {
+			if src.captions == nil {
+				src.captions = make([]string, len(files))
+			}
+			src.captions[i] = sidecar.Caption
+		}
+		if sidecar.Tags != nil {
+			if src.tags == nil {
+				src.tags = make([]map[string]string, len(files))
+			}
+			src.tags[i] = sidecar.Tags
+		}
+		cropped = cropped || !sidecar.crop.Empty()
+	}
+
+	// Crop the frames as they are loaded
+	if cropped {
+		load, decode := src.load, src.decode
+		crop := func(i int, img image.Image, err error) (image.Image, error) {
+			if err != nil || sidecars[i] == nil || sidecars[i].crop.Empty() {
+				return img, err
+			}
+			return cropImage(img, sidecars[i].crop, files[i])
+		}
+		src.load = func(i int) (image.Image, error) {
+			img, err := load(i)
+			return crop(i, img, err)
+		}
+		src.decode = func(i int, data []byte) (image.Image, error) {
+			img, err := decode(i, data)
+			return crop(i, img, err)
+		}
+	}
+	return src, nil
+}
+
+// cropImage returns a copy of the area r of img, relative to its top-left
+// corner, moved to the origin
+func cropImage(img image.Image, r image.Rectangle, name string) (image.Image, error) {
+	bounds := img.Bounds()
+	if !r.Add(bounds.Min).In(bounds) {
+		return nil, fmt.Errorf("crop %v of %s is outside the %dx%d frame", r, name, bounds.Dx(), bounds.Dy())
+	}
+	cropped := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	xdraw.Draw(cropped, cropped.Bounds(), img, r.Min.Add(bounds.Min), xdraw.Src)
+	return cropped, nil
+}
@@ -0,0 +1,115 @@
+package converter
+
+import (
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSidecar(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name     string
+		sidecar  string
+		wantNil  bool
+		wantCrop image.Rectangle
+		wantErr  bool
+	}{
+		{name: "no sidecar", wantNil: true},
+		{name: "all options", sidecar: `{"delay": "2s", "crop": "10,20,30x40", "caption": "Login", "tags": {"step": "1"}}`, wantCrop: image.Rect(10, 20, 40, 60)},
+		{name: "skip", sidecar: `{"skip": true}`},
+		{name: "invalid delay", sidecar: `{"delay": "soon"}`, wantErr: true},
+		{name: "invalid crop", sidecar: `{"crop": "10x10"}`, wantErr: true},
+		{name: "unknown field", sidecar: `{"dealy": "2s"}`, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := filepath.Join(tempDir, "frame"+string(rune('a'+i))+".png")
+			if tt.sidecar != "" {
+				if err := os.WriteFile(SidecarPath(input), []byte(tt.sidecar), 0644); err != nil {
+					t.Fatalf("Failed to write sidecar: %v", err)
+				}
+			}
+
+			sidecar, err := LoadSidecar(input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSidecar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (sidecar == nil) != tt.wantNil {
+				t.Fatalf("LoadSidecar() = %+v, want nil %v", sidecar, tt.wantNil)
+			}
+			if sidecar != nil && sidecar.crop != tt.wantCrop {
+				t.Errorf("crop = %v, want %v", sidecar.crop, tt.wantCrop)
+			}
+		})
+	}
+}
+
+func TestConvertSidecars(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The second frame is skipped, the third shown longer, the first cropped
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 20, 10)
+	sidecars := map[int]string{
+		0: `{"crop": "0,0,10x5"}`,
+		1: `{"skip": true}`,
+		2: `{"delay": "1s", "caption": "Done"}`,
+	}
+	for i, sidecar := range sidecars {
+		if err := os.WriteFile(SidecarPath(inputFiles[i]), []byte(sidecar), 0644); err != nil {
+			t.Fatalf("Failed to write sidecar: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantDelays []int
+		wantWidth  int
+	}{
+		{name: "sidecars", opts: Options{Delay: 100}, wantDelays: []int{10, 100}, wantWidth: 10},
+		{name: "ignored", opts: Options{Delay: 100, IgnoreSidecars: true}, wantDelays: []int{10, 10, 10}, wantWidth: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, tt.name+".gif")
+			report, err := Convert(inputFiles, outputFile, tt.opts)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if report.Width != tt.wantWidth {
+				t.Errorf("width = %d, want %d", report.Width, tt.wantWidth)
+			}
+
+			file, err := os.Open(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to open output: %v", err)
+			}
+			defer file.Close()
+			g, err := gif.DecodeAll(file)
+			if err != nil {
+				t.Fatalf("Failed to decode output: %v", err)
+			}
+			if !reflect.DeepEqual(g.Delay, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", g.Delay, tt.wantDelays)
+			}
+		})
+	}
+}
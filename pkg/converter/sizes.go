@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseSizes parses a comma separated list of output widths, e.g. "320,640,1280"
+func ParseSizes(spec string) ([]int, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var sizes []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(spec, ",") {
+		width, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("invalid size %q: expected a positive width in pixels", part)
+		}
+		if seen[width] {
+			return nil, fmt.Errorf("duplicate size %d", width)
+		}
+		seen[width] = true
+		sizes = append(sizes, width)
+	}
+	return sizes, nil
+}
+
+// SizedOutputPath returns the path of the output of the given width, e.g. out.gif -> out-320.gif
+func SizedOutputPath(outputFile string, width int) string {
+	ext := filepath.Ext(outputFile)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outputFile, ext), width, ext)
+}
+
+// sizedOutputs returns one output per width, keeping the aspect ratio of bounds
+func sizedOutputs(outputFile string, bounds image.Rectangle, widths []int) []Output {
+	outputs := make([]Output, len(widths))
+	for i, width := range widths {
+		outputs[i] = Output{
+			File:   SizedOutputPath(outputFile, width),
+			Width:  width,
+			Height: max(1, int(float64(bounds.Dy())*float64(width)/float64(bounds.Dx())+0.5)),
+			Format: FormatGIF,
+		}
+	}
+	return outputs
+}
+
+// scaleRegions maps regions found in frames of size from to frames of size to
+func scaleRegions(regions []image.Rectangle, from, to image.Rectangle) []image.Rectangle {
+	if from.Size() == to.Size() {
+		return regions
+	}
+
+	fx := float64(to.Dx()) / float64(from.Dx())
+	fy := float64(to.Dy()) / float64(from.Dy())
+	scaled := make([]image.Rectangle, len(regions))
+	for i, r := range regions {
+		scaled[i] = image.Rect(
+			int(float64(r.Min.X)*fx), int(float64(r.Min.Y)*fy),
+			int(float64(r.Max.X)*fx+0.999), int(float64(r.Max.Y)*fy+0.999))
+	}
+	return scaled
+}
@@ -0,0 +1,110 @@
+package converter
+
+import (
+	"image"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSizes(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "320", want: []int{320}},
+		{spec: "320, 640,1280", want: []int{320, 640, 1280}},
+		{spec: "320,abc", wantErr: true},
+		{spec: "0", wantErr: true},
+		{spec: "320,320", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseSizes(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSizes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseSizes() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseSizes() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSizedOutputPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		width int
+		want  string
+	}{
+		{"out.gif", 320, "out-320.gif"},
+		{"dir/demo.v2.gif", 640, "dir/demo.v2-640.gif"},
+		{"noext", 100, "noext-100"},
+	}
+
+	for _, tt := range tests {
+		if got := SizedOutputPath(tt.path, tt.width); got != tt.want {
+			t.Errorf("SizedOutputPath(%q, %d) = %q, want %q", tt.path, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestScaleRegions(t *testing.T) {
+	from := image.Rect(0, 0, 100, 50)
+	to := image.Rect(0, 0, 50, 25)
+	got := scaleRegions([]image.Rectangle{image.Rect(10, 10, 21, 21)}, from, to)
+	if want := image.Rect(5, 5, 11, 11); got[0] != want {
+		t.Errorf("scaleRegions() = %v, want %v", got[0], want)
+	}
+}
+
+func TestConvertSizes(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 3, 64, 32)
+	outputFile := filepath.Join(tempDir, "out.gif")
+	report, err := Convert(inputFiles, outputFile, Options{Delay: 100, Sizes: []int{16, 32}})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(report.Outputs) != 2 {
+		t.Fatalf("Convert() got %d outputs, want 2", len(report.Outputs))
+	}
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Errorf("unsized output %s should not be written", outputFile)
+	}
+
+	for _, want := range []struct{ width, height int }{{16, 8}, {32, 16}} {
+		path := SizedOutputPath(outputFile, want.width)
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", path, err)
+		}
+		g, err := gif.DecodeAll(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", path, err)
+		}
+		if g.Config.Width != want.width || g.Config.Height != want.height || len(g.Image) != 3 {
+			t.Errorf("%s = %dx%d with %d frames, want %dx%d with 3 frames",
+				path, g.Config.Width, g.Config.Height, len(g.Image), want.width, want.height)
+		}
+	}
+
+	if _, err := Convert(inputFiles, outputFile, Options{Width: 10, Sizes: []int{16}}); err == nil {
+		t.Error("Convert() expected error for width and sizes together")
+	}
+}
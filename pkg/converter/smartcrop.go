@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+)
+
+// smartCropSamples is the number of frames whose saliency picks the crop window
+const smartCropSamples = 5
+
+// smartCrop returns the window of the given size showing the most salient
+// content of the frames [first, last), measured as the density of luminance
+// edges over a few evenly spaced frames. Ties keep the window closest to the
+// center.
+func smartCrop(load func(int) (image.Image, error), first, last int, size image.Point) (image.Rectangle, error) {
+	var saliency []int64
+	var bounds image.Rectangle
+	for _, i := range sampleIndices(first, last, smartCropSamples) {
+		img, err := load(i)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		if saliency == nil {
+			bounds = image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy())
+			if size.X > bounds.Dx() || size.Y > bounds.Dy() {
+				return image.Rectangle{}, fmt.Errorf("smart crop %dx%d is larger than the %dx%d frames", size.X, size.Y, bounds.Dx(), bounds.Dy())
+			}
+			saliency = make([]int64, bounds.Dx()*bounds.Dy())
+		}
+		addEdges(saliency, bounds, img)
+	}
+
+	// Sum the saliency of every window with a summed-area table
+	w, h := bounds.Dx(), bounds.Dy()
+	sums := make([]int64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		var row int64
+		for x := 0; x < w; x++ {
+			row += saliency[y*w+x]
+			sums[(y+1)*(w+1)+x+1] = sums[y*(w+1)+x+1] + row
+		}
+	}
+	sum := func(x, y int) int64 {
+		x2, y2 := x+size.X, y+size.Y
+		return sums[y2*(w+1)+x2] - sums[y*(w+1)+x2] - sums[y2*(w+1)+x] + sums[y*(w+1)+x]
+	}
+
+	center := image.Pt((w-size.X)/2, (h-size.Y)/2)
+	best, bestSum, bestDistance := center, sum(center.X, center.Y), 0
+	for y := 0; y <= h-size.Y; y++ {
+		for x := 0; x <= w-size.X; x++ {
+			s := sum(x, y)
+			distance := (x-center.X)*(x-center.X) + (y-center.Y)*(y-center.Y)
+			if s > bestSum || s == bestSum && distance < bestDistance {
+				best, bestSum, bestDistance = image.Pt(x, y), s, distance
+			}
+		}
+	}
+	return image.Rectangle{Min: best, Max: best.Add(size)}, nil
+}
+
+// addEdges adds the luminance gradient of every pixel of img to saliency,
+// laid out as the rows of bounds
+func addEdges(saliency []int64, bounds image.Rectangle, img image.Image) {
+	gray := image.NewGray(bounds)
+	draw.Draw(gray, bounds, img, img.Bounds().Min, draw.Src)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			l := gray.GrayAt(x, y).Y
+			var edge int64
+			if x+1 < bounds.Dx() {
+				edge += int64(absDiff(l, gray.GrayAt(x+1, y).Y))
+			}
+			if y+1 < bounds.Dy() {
+				edge += int64(absDiff(l, gray.GrayAt(x, y+1).Y))
+			}
+			saliency[y*bounds.Dx()+x] += edge
+		}
+	}
+}
+
+// smartCropped returns reframe followed by a crop to the window of the given
+// size showing the most salient content of the frames [first, last) of src
+func smartCropped(src source, first, last int, reframe func(int, image.Image) (image.Image, error), size image.Point, debug bool) (func(int, image.Image) (image.Image, error), error) {
+	window, err := smartCrop(reframedLoader(src, reframe), first, last, size)
+	if err != nil {
+		return nil, err
+	}
+	if debug {
+		fmt.Printf("Smart crop window: %v\n", window)
+	}
+	return func(i int, img image.Image) (image.Image, error) {
+		img, err := reframe(i, img)
+		if err != nil {
+			return nil, err
+		}
+		return cropImage(img, window, src.names[i])
+	}, nil
+}
+
+// reframedLoader returns a function loading the frames of src through reframe
+func reframedLoader(src source, reframe func(int, image.Image) (image.Image, error)) func(int) (image.Image, error) {
+	return func(i int) (image.Image, error) {
+		img, err := src.load(i)
+		if err != nil {
+			return nil, err
+		}
+		return reframe(i, img)
+	}
+}
+
+// fitWindow returns the largest window of a frame of the given size with the
+// aspect ratio of target
+func fitWindow(size, target image.Point) image.Point {
+	if size.X*target.Y > size.Y*target.X {
+		// Wider than the target
+		return image.Pt(max(1, size.Y*target.X/target.Y), size.Y)
+	}
+	return image.Pt(size.X, max(1, size.X*target.Y/target.X))
+}
@@ -0,0 +1,75 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// detailFrame returns a flat frame with a checkerboard over the area detail
+func detailFrame(width, height int, detail image.Rectangle) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{128, 128, 128, 255}
+			if image.Pt(x, y).In(detail) && (x/2+y/2)%2 == 0 {
+				c = color.RGBA{255, 255, 255, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestSmartCrop(t *testing.T) {
+	tests := []struct {
+		name    string
+		detail  image.Rectangle
+		size    image.Point
+		want    image.Rectangle
+		wantErr bool
+	}{
+		{name: "detail on the right", detail: image.Rect(70, 20, 90, 40), size: image.Pt(40, 40), want: image.Rect(50, 10, 90, 50)},
+		{name: "flat frames keep the center", size: image.Pt(40, 40), want: image.Rect(30, 10, 70, 50)},
+		{name: "whole frame", detail: image.Rect(0, 0, 10, 10), size: image.Pt(100, 60), want: image.Rect(0, 0, 100, 60)},
+		{name: "larger than the frames", size: image.Pt(120, 40), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := detailFrame(100, 60, tt.detail)
+			load := func(int) (image.Image, error) { return frame, nil }
+			got, err := smartCrop(load, 0, 3, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("smartCrop() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("smartCrop() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertSmartCrop(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frame := detailFrame(60, 100, image.Rect(10, 70, 30, 90))
+	frames := []Frame{{Image: frame}, {Image: frame}}
+	outputFile := filepath.Join(tempDir, "square.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{SmartCrop: image.Pt(60, 60)})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Width != 60 || report.Height != 60 {
+		t.Errorf("output size = %dx%d, want 60x60", report.Width, report.Height)
+	}
+}
@@ -0,0 +1,91 @@
+package converter
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Embed snippet formats
+const (
+	// SnippetMarkdown is a Markdown image, linking the animation from the poster when there is one
+	SnippetMarkdown = "md"
+	// SnippetHTML is a <picture> element showing the poster to users who prefer reduced motion
+	SnippetHTML = "html"
+)
+
+// SnippetPath returns the path of the embed snippet written next to a GIF, e.g. out.gif -> out.html
+func SnippetPath(outputFile, format string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "." + format
+}
+
+// writeSnippet writes the embed snippet for outputs next to outputFile and returns its path
+func writeSnippet(outputFile string, outputs []Output, format string) (string, error) {
+	snippet, err := renderSnippet(outputs, format, strings.TrimSuffix(filepath.Base(outputFile), filepath.Ext(outputFile)))
+	if err != nil {
+		return "", err
+	}
+
+	path := SnippetPath(outputFile, format)
+	if err := os.WriteFile(path, []byte(snippet), 0644); err != nil {
+		return "", fmt.Errorf("error writing snippet: %v", err)
+	}
+	return path, nil
+}
+
+// renderSnippet returns the embed code for outputs, referencing files by name
+// since the snippet sits next to them
+func renderSnippet(outputs []Output, format, alt string) (string, error) {
+	if len(outputs) == 0 {
+		return "", fmt.Errorf("no outputs to embed")
+	}
+
+	// The largest output is the default source
+	main := outputs[0]
+	for _, output := range outputs {
+		if output.Width > main.Width {
+			main = output
+		}
+	}
+	name := func(path string) string { return filepath.ToSlash(filepath.Base(path)) }
+
+	switch format {
+	case SnippetMarkdown:
+		if main.Poster != "" {
+			return fmt.Sprintf("[![%s](%s)](%s)\n", alt, name(main.Poster), name(main.File)), nil
+		}
+		return fmt.Sprintf("![%s](%s)\n", alt, name(main.File)), nil
+	case SnippetHTML:
+		// Several sizes let the browser pick the best one
+		var srcset string
+		if len(outputs) > 1 {
+			candidates := make([]string, len(outputs))
+			for i, output := range outputs {
+				candidates[i] = fmt.Sprintf("%s %dw", html.EscapeString(name(output.File)), output.Width)
+			}
+			srcset = strings.Join(candidates, ", ")
+		}
+
+		if main.Poster == "" {
+			attrs := ""
+			if srcset != "" {
+				attrs = fmt.Sprintf(` srcset="%s"`, srcset)
+			}
+			return imgTag(name(main.File), attrs, main, alt) + "\n", nil
+		}
+		if srcset == "" {
+			srcset = html.EscapeString(name(main.File))
+		}
+		return fmt.Sprintf("<picture>\n  <source srcset=\"%s\" media=\"(prefers-reduced-motion: no-preference)\">\n  %s\n</picture>\n",
+			srcset, imgTag(name(main.Poster), "", main, alt)), nil
+	}
+	return "", fmt.Errorf("invalid snippet format %q: expected %s or %s", format, SnippetMarkdown, SnippetHTML)
+}
+
+// imgTag returns an <img> element with the dimensions of output
+func imgTag(src, attrs string, output Output, alt string) string {
+	return fmt.Sprintf(`<img src="%s"%s width="%d" height="%d" alt="%s">`,
+		html.EscapeString(src), attrs, output.Width, output.Height, html.EscapeString(alt))
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderSnippet(t *testing.T) {
+	single := []Output{{File: "/docs/demo.gif", Width: 640, Height: 360}}
+	withPoster := []Output{{File: "/docs/demo.gif", Width: 640, Height: 360, Poster: "/docs/demo.png"}}
+	sized := []Output{
+		{File: "/docs/demo-320.gif", Width: 320, Height: 180, Poster: "/docs/demo-320.png"},
+		{File: "/docs/demo-640.gif", Width: 640, Height: 360, Poster: "/docs/demo-640.png"},
+	}
+
+	tests := []struct {
+		name    string
+		outputs []Output
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "markdown",
+			outputs: single,
+			format:  SnippetMarkdown,
+			want:    "![demo](demo.gif)\n",
+		},
+		{
+			name:    "markdown with poster",
+			outputs: withPoster,
+			format:  SnippetMarkdown,
+			want:    "[![demo](demo.png)](demo.gif)\n",
+		},
+		{
+			name:    "html",
+			outputs: single,
+			format:  SnippetHTML,
+			want:    `<img src="demo.gif" width="640" height="360" alt="demo">` + "\n",
+		},
+		{
+			name:    "html with poster",
+			outputs: withPoster,
+			format:  SnippetHTML,
+			want: "<picture>\n  <source srcset=\"demo.gif\" media=\"(prefers-reduced-motion: no-preference)\">\n" +
+				"  <img src=\"demo.png\" width=\"640\" height=\"360\" alt=\"demo\">\n</picture>\n",
+		},
+		{
+			name:    "html with sizes",
+			outputs: sized,
+			format:  SnippetHTML,
+			want: "<picture>\n  <source srcset=\"demo-320.gif 320w, demo-640.gif 640w\" media=\"(prefers-reduced-motion: no-preference)\">\n" +
+				"  <img src=\"demo-640.png\" width=\"640\" height=\"360\" alt=\"demo\">\n</picture>\n",
+		},
+		{
+			name:    "invalid format",
+			outputs: single,
+			format:  "rst",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderSnippet(tt.outputs, tt.format, "demo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderSnippet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("renderSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertEmitSnippet(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 2, 16, 8)
+	outputFile := filepath.Join(tempDir, "demo.gif")
+	report, err := Convert(inputFiles, outputFile, Options{Delay: 100, EmitSnippet: SnippetHTML, WithPoster: true})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	if report.Snippet != SnippetPath(report.OutputFile, SnippetHTML) {
+		t.Errorf("snippet = %q, want %q", report.Snippet, SnippetPath(report.OutputFile, SnippetHTML))
+	}
+	data, err := os.ReadFile(report.Snippet)
+	if err != nil {
+		t.Fatalf("Failed to read snippet: %v", err)
+	}
+	if !strings.Contains(string(data), `<img src="demo.png" width="16" height="8" alt="demo">`) {
+		t.Errorf("snippet = %q, want the poster with its dimensions", data)
+	}
+
+	if _, err := Convert(inputFiles, outputFile, Options{EmitSnippet: "rst"}); err == nil {
+		t.Error("Convert() expected error for an unknown snippet format")
+	}
+}
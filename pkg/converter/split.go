@@ -0,0 +1,157 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+// DefaultFrameTemplate names extracted frames by their zero-based index
+const DefaultFrameTemplate = "frame-%04d.png"
+
+// FrameInfo describes a frame written by WriteFrames
+type FrameInfo struct {
+	Index int `json:"index"`
+	// File is empty for the frames of a GIF indexed with Options.Manifest
+	File    string `json:"file,omitempty"`
+	DelayMs int    `json:"delayMs"`
+}
+
+// Manifest indexes the frames written by WriteFrames, or the frames of a GIF
+// written with Options.Manifest, for downstream scripts
+type Manifest struct {
+	Frames []FrameInfo `json:"frames"`
+	// Chapters lists the frames the Options.Chapters start at
+	Chapters []Chapter `json:"chapters,omitempty"`
+}
+
+// FrameName expands an output template for a frame. Templates are either printf
+// style with the index as the only argument (frame-%04d.png) or Go templates
+// with the fields of FrameInfo ({{.Index}}, {{.DelayMs}}).
+func FrameName(tmpl string, index int, delay time.Duration) (string, error) {
+	if strings.Contains(tmpl, "{{") {
+		t, err := template.New("frame").Option("missingkey=error").Parse(tmpl)
+		if err != nil {
+			return "", fmt.Errorf("invalid frame template %q: %v", tmpl, err)
+		}
+		var name bytes.Buffer
+		if err := t.Execute(&name, FrameInfo{Index: index, DelayMs: int(delay / time.Millisecond)}); err != nil {
+			return "", fmt.Errorf("invalid frame template %q: %v", tmpl, err)
+		}
+		return name.String(), nil
+	}
+	if !strings.Contains(tmpl, "%") {
+		return "", fmt.Errorf("frame template %q has no index placeholder such as %%04d or {{.Index}}", tmpl)
+	}
+	name := fmt.Sprintf(tmpl, index)
+	if strings.Contains(name, "%!") {
+		return "", fmt.Errorf("invalid frame template %q: %s", tmpl, name)
+	}
+	return name, nil
+}
+
+// WriteFrames saves frames as PNG files named after tmpl, creating missing
+// directories. Frames without a delay use defaultDelay.
+func WriteFrames(frames []Frame, tmpl string, defaultDelay time.Duration) ([]FrameInfo, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames to write")
+	}
+
+	// Name every frame first so a template producing duplicate names writes nothing
+	infos := make([]FrameInfo, len(frames))
+	seen := make(map[string]int, len(frames))
+	for i, frame := range frames {
+		delay := frame.Delay
+		if delay == 0 {
+			delay = defaultDelay
+		}
+		name, err := FrameName(tmpl, i, delay)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[name]; ok {
+			return nil, fmt.Errorf("frames %d and %d are both named %s", prev, i, name)
+		}
+		seen[name] = i
+		infos[i] = FrameInfo{Index: i, File: name, DelayMs: int(delay / time.Millisecond)}
+	}
+
+	for i, frame := range frames {
+		if dir := filepath.Dir(infos[i].File); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("error creating directory %s: %v", dir, err)
+			}
+		}
+		if err := writePNG(infos[i].File, frame.Image); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// WriteManifest writes the JSON index of written frames
+func WriteManifest(w io.Writer, frames []FrameInfo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Manifest{Frames: frames})
+}
+
+// writeGIFManifest writes to path the JSON index of GIF frames shown for
+// delays (in 100ths of a second), starting chapters
+func writeGIFManifest(path string, delays []int, chapters []Chapter) error {
+	manifest := Manifest{Frames: make([]FrameInfo, len(delays)), Chapters: chapters}
+	for i, delay := range delays {
+		manifest.Frames[i] = FrameInfo{Index: i, DelayMs: delay * 10}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing manifest: %v", err)
+	}
+	return nil
+}
+
+// SplitGIF decodes a GIF into full frames, compositing each one over the
+// previous according to its disposal method
+func SplitGIF(path string) ([]Frame, error) {
+	reader, err := gifutil.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := reader.All()
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]Frame, len(decoded))
+	for i, frame := range decoded {
+		frames[i] = Frame{Image: frame.Image, Delay: frame.Delay}
+	}
+	return frames, nil
+}
+
+// writePNG saves img as a PNG file
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating frame image: %v", err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("error encoding frame image: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,150 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFrameName(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{name: "printf", tmpl: "frame-%04d.png", want: "frame-0007.png"},
+		{name: "go template", tmpl: "out/{{.Index}}-{{.DelayMs}}ms.png", want: "out/7-120ms.png"},
+		{name: "printf index in template", tmpl: `{{printf "%03d" .Index}}.png`, want: "007.png"},
+		{name: "no placeholder", tmpl: "frame.png", wantErr: true},
+		{name: "bad verb", tmpl: "frame-%s-%d.png", wantErr: true},
+		{name: "unknown field", tmpl: "{{.Name}}.png", wantErr: true},
+		{name: "unparsable template", tmpl: "{{.Index.png", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FrameName(tt.tmpl, 7, 120*time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FrameName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want && !tt.wantErr {
+				t.Errorf("FrameName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFrames(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frames := []Frame{
+		{Image: image.NewRGBA(image.Rect(0, 0, 4, 4)), Delay: 50 * time.Millisecond},
+		{Image: image.NewRGBA(image.Rect(0, 0, 4, 4))},
+	}
+	tmpl := filepath.Join(tempDir, "frames", "{{.Index}}.png")
+	infos, err := WriteFrames(frames, tmpl, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WriteFrames() error = %v", err)
+	}
+
+	want := []FrameInfo{
+		{Index: 0, File: filepath.Join(tempDir, "frames", "0.png"), DelayMs: 50},
+		{Index: 1, File: filepath.Join(tempDir, "frames", "1.png"), DelayMs: 100},
+	}
+	for i, info := range infos {
+		if info != want[i] {
+			t.Errorf("frame %d = %+v, want %+v", i, info, want[i])
+		}
+		if _, err := os.Stat(info.File); err != nil {
+			t.Errorf("frame %d not written: %v", i, err)
+		}
+	}
+
+	var manifest bytes.Buffer
+	if err := WriteManifest(&manifest, infos); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	var decoded Manifest
+	if err := json.Unmarshal(manifest.Bytes(), &decoded); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if len(decoded.Frames) != 2 || decoded.Frames[1] != want[1] {
+		t.Errorf("manifest = %+v, want %+v", decoded.Frames, want)
+	}
+
+	if _, err := WriteFrames(frames, filepath.Join(tempDir, "{{.DelayMs}}.png"), 50*time.Millisecond); err == nil {
+		t.Error("WriteFrames() expected error for duplicate frame names")
+	}
+}
+
+func TestSplitGIF(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	palette := color.Palette{color.Transparent, red, blue}
+
+	// A full red frame followed by a blue pixel drawn over it, then removed again
+	first := image.NewPaletted(image.Rect(0, 0, 2, 2), palette)
+	for i := range first.Pix {
+		first.Pix[i] = 1
+	}
+	second := image.NewPaletted(image.Rect(1, 1, 2, 2), palette)
+	second.Pix[0] = 2
+	third := image.NewPaletted(image.Rect(0, 0, 1, 1), palette)
+
+	path := filepath.Join(tempDir, "in.gif")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create GIF: %v", err)
+	}
+	err = gif.EncodeAll(file, &gif.GIF{
+		Image:    []*image.Paletted{first, second, third},
+		Delay:    []int{10, 20, 30},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalPrevious, gif.DisposalNone},
+		Config:   image.Config{ColorModel: palette, Width: 2, Height: 2},
+	})
+	file.Close()
+	if err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+
+	frames, err := SplitGIF(path)
+	if err != nil {
+		t.Fatalf("SplitGIF() error = %v", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(frames))
+	}
+	if frames[1].Delay != 200*time.Millisecond {
+		t.Errorf("delay = %v, want 200ms", frames[1].Delay)
+	}
+	if got := color.RGBAModel.Convert(frames[1].Image.At(0, 0)); got != red {
+		t.Errorf("frame 1 (0,0) = %v, want the red of frame 0", got)
+	}
+	if got := color.RGBAModel.Convert(frames[1].Image.At(1, 1)); got != blue {
+		t.Errorf("frame 1 (1,1) = %v, want blue", got)
+	}
+	if got := color.RGBAModel.Convert(frames[2].Image.At(1, 1)); got != red {
+		t.Errorf("frame 2 (1,1) = %v, want red restored by the previous disposal", got)
+	}
+
+	if _, err := SplitGIF(filepath.Join(tempDir, "missing.gif")); err == nil {
+		t.Error("SplitGIF() expected error for a missing file")
+	}
+}
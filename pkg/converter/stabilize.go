@@ -0,0 +1,206 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/cmplx"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// stabilizeSize is the side of the grayscale thumbnails compared by phase
+// correlation, a power of two
+const stabilizeSize = 256
+
+// stabilizer shifts and crops frames so that their content stays in place
+type stabilizer struct {
+	// offsets are where the content of each frame is, relative to the first one
+	offsets []image.Point
+	// crop is the area of the first frame visible in every frame
+	crop image.Rectangle
+}
+
+// newStabilizer estimates the translation of each of the frames [first, last)
+// of src relative to the previous one by phase correlation, and the area they
+// all show
+func newStabilizer(src source, first, last int) (*stabilizer, error) {
+	offsets := make([]image.Point, last-first)
+	var size image.Point
+	var previous [][]complex128
+	var x, y float64
+	for i := first; i < last; i++ {
+		img, err := loadSized(src, i, &size)
+		if err != nil {
+			return nil, err
+		}
+		spectrum := frameSpectrum(img)
+		if previous != nil {
+			dx, dy := phaseCorrelation(previous, spectrum)
+			x += dx * float64(size.X) / stabilizeSize
+			y += dy * float64(size.Y) / stabilizeSize
+			offsets[i-first] = image.Pt(int(math.Round(x)), int(math.Round(y)))
+		}
+		previous = spectrum
+	}
+	return shiftedStabilizer(size, offsets)
+}
+
+// shiftedStabilizer returns a stabilizer for frames of the given size whose
+// content is moved by offsets
+func shiftedStabilizer(size image.Point, offsets []image.Point) (*stabilizer, error) {
+	s := &stabilizer{offsets: offsets, crop: image.Rectangle{Max: size}}
+	for _, offset := range offsets {
+		s.crop = s.crop.Intersect(image.Rectangle{Max: size}.Sub(offset))
+	}
+	if s.crop.Dx() < 2 || s.crop.Dy() < 2 {
+		return nil, fmt.Errorf("frames move too much to be aligned")
+	}
+	return s, nil
+}
+
+// loadSized loads frame i of src, checking that its size matches the one of
+// the first frame loaded, recorded in size
+func loadSized(src source, i int, size *image.Point) (image.Image, error) {
+	img, err := src.load(i)
+	if err != nil {
+		return nil, err
+	}
+	if *size == (image.Point{}) {
+		*size = img.Bounds().Size()
+	} else if img.Bounds().Size() != *size {
+		return nil, fmt.Errorf("alignment needs frames of the same size, %s is %dx%d instead of %dx%d",
+			src.names[i], img.Bounds().Dx(), img.Bounds().Dy(), size.X, size.Y)
+	}
+	return img, nil
+}
+
+// bounds returns the size of the stabilized frames
+func (s *stabilizer) bounds() image.Rectangle {
+	return image.Rect(0, 0, s.crop.Dx(), s.crop.Dy())
+}
+
+// apply returns the area of frame i matching the crop of the first frame
+func (s *stabilizer) apply(i int, img image.Image, name string) (image.Image, error) {
+	return cropImage(img, s.crop.Add(s.offsets[i]), name)
+}
+
+// frameSpectrum returns the 2D Fourier transform of a windowed grayscale
+// thumbnail of img
+func frameSpectrum(img image.Image) [][]complex128 {
+	gray := image.NewGray(image.Rect(0, 0, stabilizeSize, stabilizeSize))
+	xdraw.ApproxBiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+
+	// A Hann window keeps the image edges from dominating the correlation
+	window := make([]float64, stabilizeSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(stabilizeSize-1))
+	}
+	data := make([][]complex128, stabilizeSize)
+	for y := range data {
+		data[y] = make([]complex128, stabilizeSize)
+		for x := range data[y] {
+			data[y][x] = complex(float64(gray.Pix[y*gray.Stride+x])*window[x]*window[y], 0)
+		}
+	}
+	fft2D(data, false)
+	return data
+}
+
+// phaseCorrelation returns the translation of the content of b relative to a,
+// given their spectra, with sub-pixel precision
+func phaseCorrelation(a, b [][]complex128) (float64, float64) {
+	n := len(a)
+	cross := make([][]complex128, n)
+	for y := range cross {
+		cross[y] = make([]complex128, n)
+		for x := range cross[y] {
+			c := b[y][x] * cmplx.Conj(a[y][x])
+			if m := cmplx.Abs(c); m > 1e-12 {
+				c /= complex(m, 0)
+			}
+			cross[y][x] = c
+		}
+	}
+	fft2D(cross, true)
+
+	// The correlation peaks at the translation, wrapping around
+	px, py := 0, 0
+	for y := range cross {
+		for x := range cross[y] {
+			if real(cross[y][x]) > real(cross[py][px]) {
+				px, py = x, y
+			}
+		}
+	}
+	at := func(x, y int) float64 { return real(cross[(y+n)%n][(x+n)%n]) }
+	dx := float64(px) + peakOffset(at(px-1, py), at(px, py), at(px+1, py))
+	dy := float64(py) + peakOffset(at(px, py-1), at(px, py), at(px, py+1))
+	if dx > float64(n)/2 {
+		dx -= float64(n)
+	}
+	if dy > float64(n)/2 {
+		dy -= float64(n)
+	}
+	return dx, dy
+}
+
+// peakOffset returns the position of the top of the parabola through three
+// samples around a peak, relative to the middle one
+func peakOffset(before, peak, after float64) float64 {
+	d := before - 2*peak + after
+	if d == 0 {
+		return 0
+	}
+	return max(-0.5, min(0.5, (before-after)/(2*d)))
+}
+
+// fft2D transforms a square matrix of power of two size in place, or applies
+// the inverse transform
+func fft2D(data [][]complex128, inverse bool) {
+	n := len(data)
+	for _, row := range data {
+		fft(row, inverse)
+	}
+	column := make([]complex128, n)
+	for x := 0; x < n; x++ {
+		for y := range data {
+			column[y] = data[y][x]
+		}
+		fft(column, inverse)
+		for y := range data {
+			data[y][x] = column[y]
+		}
+	}
+}
+
+// fft computes the discrete Fourier transform of a power of two number of
+// values in place (iterative radix-2), unscaled in both directions
+func fft(a []complex128, inverse bool) {
+	n := len(a)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u, v := a[start+k], a[start+k+size/2]*w
+				a[start+k], a[start+k+size/2] = u+v, u-v
+				w *= step
+			}
+		}
+	}
+}
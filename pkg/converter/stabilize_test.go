@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"image"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// shakyFrames returns views of a textured scene whose content is moved by
+// each of the offsets, as a handheld camera would
+func shakyFrames(width, height int, offsets []image.Point) []Frame {
+	// Smoothed noise has a single best match, unlike periodic patterns
+	rng := rand.New(rand.NewSource(1))
+	noise := image.NewRGBA(image.Rect(0, 0, (width+80)/4, (height+80)/4))
+	for i := range noise.Pix {
+		noise.Pix[i] = uint8(rng.Intn(256))
+		if i%4 == 3 {
+			noise.Pix[i] = 255
+		}
+	}
+	scene := image.NewRGBA(image.Rect(0, 0, width+80, height+80))
+	xdraw.BiLinear.Scale(scene, scene.Bounds(), noise, noise.Bounds(), xdraw.Src, nil)
+
+	var frames []Frame
+	for _, offset := range offsets {
+		frame := image.NewRGBA(image.Rect(0, 0, width, height))
+		origin := image.Pt(40, 40).Sub(offset)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				frame.Set(x, y, scene.At(origin.X+x, origin.Y+y))
+			}
+		}
+		frames = append(frames, Frame{Image: frame})
+	}
+	return frames
+}
+
+func TestNewStabilizer(t *testing.T) {
+	offsets := []image.Point{{0, 0}, {5, -3}, {-7, 4}, {12, 9}}
+	src, err := frameSource(shakyFrames(160, 120, offsets))
+	if err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+
+	s, err := newStabilizer(src, 0, len(offsets))
+	if err != nil {
+		t.Fatalf("newStabilizer() error = %v", err)
+	}
+	for i, want := range offsets {
+		if got := s.offsets[i]; abs(got.X-want.X) > 1 || abs(got.Y-want.Y) > 1 {
+			t.Errorf("offset of frame %d = %v, want %v", i, got, want)
+		}
+	}
+	if got, want := s.bounds().Size(), image.Pt(160-19, 120-12); abs(got.X-want.X) > 2 || abs(got.Y-want.Y) > 2 {
+		t.Errorf("stabilized size = %v, want about %v", got, want)
+	}
+
+	// Frames of different sizes cannot be aligned
+	frames := shakyFrames(160, 120, offsets[:1])
+	frames = append(frames, Frame{Image: image.NewRGBA(image.Rect(0, 0, 80, 60))})
+	if src, err = frameSource(frames); err != nil {
+		t.Fatalf("frameSource() error = %v", err)
+	}
+	if _, err := newStabilizer(src, 0, 2); err == nil {
+		t.Error("newStabilizer() with frames of different sizes should fail")
+	}
+}
+
+func TestConvertStabilize(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frames := shakyFrames(96, 64, []image.Point{{0, 0}, {4, 2}, {-3, 5}})
+	tests := []struct {
+		name      string
+		stabilize bool
+		wantSize  image.Point
+	}{
+		{name: "shaky", stabilize: false, wantSize: image.Pt(96, 64)},
+		{name: "stabilized", stabilize: true, wantSize: image.Pt(96-7, 64-5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, tt.name+".gif")
+			report, err := ConvertFrames(frames, outputFile, Options{Stabilize: tt.stabilize})
+			if err != nil {
+				t.Fatalf("ConvertFrames() error = %v", err)
+			}
+			if got := image.Pt(report.Width, report.Height); abs(got.X-tt.wantSize.X) > 1 || abs(got.Y-tt.wantSize.Y) > 1 {
+				t.Errorf("output size = %v, want %v", got, tt.wantSize)
+			}
+		})
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func TestConvertReframeMixedSizes(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The second frame is a steady view captured at twice the size
+	frames := shakyFrames(96, 64, []image.Point{{0, 0}, {0, 0}, {0, 0}})
+	large := image.NewRGBA(image.Rect(0, 0, 192, 128))
+	xdraw.NearestNeighbor.Scale(large, large.Bounds(), frames[1].Image, frames[1].Image.Bounds(), xdraw.Src, nil)
+	frames[1].Image = large
+
+	tests := []struct {
+		name     string
+		opts     Options
+		wantSize image.Point
+	}{
+		{name: "stabilized", opts: Options{Stabilize: true}, wantSize: image.Pt(96, 64)},
+		{name: "smart crop", opts: Options{SmartCrop: image.Pt(60, 40)}, wantSize: image.Pt(60, 40)},
+		{name: "fit", opts: Options{Fit: image.Pt(32, 32)}, wantSize: image.Pt(32, 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(tempDir, "mixed.gif")
+			report, err := ConvertFrames(frames, outputFile, tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertFrames() error = %v", err)
+			}
+			if got := image.Pt(report.Width, report.Height); abs(got.X-tt.wantSize.X) > 1 || abs(got.Y-tt.wantSize.Y) > 1 {
+				t.Errorf("output size = %v, want %v", got, tt.wantSize)
+			}
+			resized := false
+			for _, warning := range report.Warnings {
+				resized = resized || warning.Kind == WarningResizedFrame && len(warning.Frames) == 1 && warning.Frames[0] == 1
+			}
+			if !resized {
+				t.Errorf("report warnings = %v, want frame 1 reported as resized", report.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+	"text/template"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+// Corners of the frames the stamp and the magnifier inset are drawn in
+const (
+	CornerTopLeft     = "top-left"
+	CornerTopRight    = "top-right"
+	CornerBottomLeft  = "bottom-left"
+	CornerBottomRight = "bottom-right"
+)
+
+// Corners lists the corners the stamp and the magnifier inset can be drawn in
+var Corners = []string{CornerTopLeft, CornerTopRight, CornerBottomLeft, CornerBottomRight}
+
+// validateStamp checks the stamp template and corner of opts
+func validateStamp(opts Options) error {
+	if err := validateCorner("stamp", opts.StampCorner); err != nil {
+		return err
+	}
+	if opts.Stamp == "" {
+		return nil
+	}
+	_, err := ExpandStamp(opts.Stamp, nil)
+	return err
+}
+
+// validateCorner checks the corner of what is named, empty being the default
+func validateCorner(what, corner string) error {
+	switch corner {
+	case "", CornerTopLeft, CornerTopRight, CornerBottomLeft, CornerBottomRight:
+		return nil
+	}
+	return fmt.Errorf("invalid %s corner %q: expected one of %s", what, corner, strings.Join(Corners, ", "))
+}
+
+// ExpandStamp expands a stamp template with environment variables given as
+// KEY=value, such as "branch:{{.GIT_BRANCH}}". Unset variables expand to
+// nothing.
+func ExpandStamp(tmpl string, environ []string) (string, error) {
+	t, err := template.New("stamp").Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid stamp template %q: %v", tmpl, err)
+	}
+	env := make(map[string]string, len(environ))
+	for _, variable := range environ {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			env[key] = value
+		}
+	}
+	var text bytes.Buffer
+	if err := t.Execute(&text, env); err != nil {
+		return "", fmt.Errorf("invalid stamp template %q: %v", tmpl, err)
+	}
+	return text.String(), nil
+}
+
+// stampOrigin returns the top left corner of a label of the given size drawn
+// in a corner of bounds, margin pixels away from its edges
+func stampOrigin(bounds image.Rectangle, size image.Point, corner string, margin int) image.Point {
+	origin := bounds.Min.Add(image.Pt(margin, margin))
+	if corner == "" || corner == CornerBottomRight || corner == CornerTopRight {
+		origin.X = bounds.Max.X - margin - size.X
+	}
+	if corner == "" || corner == CornerBottomRight || corner == CornerBottomLeft {
+		origin.Y = bounds.Max.Y - margin - size.Y
+	}
+	return origin
+}
+
+// drawStamp draws text in a corner of dst at the caption size of bounds
+func drawStamp(dst *image.RGBA, text string, bounds image.Rectangle, corner string, style overlay.CaptionStyle) error {
+	size := overlay.CaptionSize(bounds)
+	box, err := overlay.MeasureLabel(text, size)
+	if err != nil {
+		return err
+	}
+	origin := stampOrigin(bounds, box, corner, int(size/2))
+	_, err = overlay.DrawLabel(dst, text, origin.X, origin.Y, size, style)
+	return err
+}
@@ -0,0 +1,101 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+func TestExpandStamp(t *testing.T) {
+	environ := []string{"GIT_BRANCH=main", "CI_RUN=42", "EMPTY=", "EQUALS=a=b"}
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{tmpl: "branch:{{.GIT_BRANCH}} run:{{.CI_RUN}}", want: "branch:main run:42"},
+		{tmpl: "{{.EQUALS}}", want: "a=b"},
+		{tmpl: "run:{{.UNSET}}", want: "run:"},
+		{tmpl: "plain text", want: "plain text"},
+		{tmpl: "{{.GIT_BRANCH", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := ExpandStamp(tt.tmpl, environ)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandStamp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandStamp() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStampOrigin(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 50)
+	size := image.Pt(30, 10)
+	tests := []struct {
+		corner string
+		want   image.Point
+	}{
+		{corner: "", want: image.Pt(65, 35)},
+		{corner: CornerTopLeft, want: image.Pt(5, 5)},
+		{corner: CornerTopRight, want: image.Pt(65, 5)},
+		{corner: CornerBottomLeft, want: image.Pt(5, 35)},
+		{corner: CornerBottomRight, want: image.Pt(65, 35)},
+	}
+
+	for _, tt := range tests {
+		if got := stampOrigin(bounds, size, tt.corner, 5); got != tt.want {
+			t.Errorf("stampOrigin(%q) = %v, want %v", tt.corner, got, tt.want)
+		}
+	}
+}
+
+func TestConvertStamp(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	t.Setenv("GO_TOGIF_TEST_BRANCH", "main")
+
+	var frames []Frame
+	for i := 0; i < 2; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+		for p := range img.Pix {
+			img.Pix[p] = 255
+		}
+		frames = append(frames, Frame{Image: img})
+	}
+
+	outputFile := filepath.Join(tempDir, "stamped.gif")
+	if _, err := ConvertFrames(frames, outputFile, Options{Stamp: "branch:{{.GO_TOGIF_TEST_BRANCH}}"}); err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	reader, err := gifutil.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	frame, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	white := color.RGBA{255, 255, 255, 255}
+	if frame.Image.RGBAAt(190, 90) == white {
+		t.Error("expected the stamp in the bottom right corner")
+	}
+	if frame.Image.RGBAAt(10, 10) != white {
+		t.Error("expected nothing in the top left corner")
+	}
+
+	if _, err := ConvertFrames(frames, outputFile, Options{Stamp: "x", StampCorner: "center"}); err == nil {
+		t.Error("ConvertFrames() with an invalid stamp corner should fail")
+	}
+}
@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// vignetteStart is the distance from the center, relative to the corners,
+// from which the vignette starts darkening
+const vignetteStart = 0.3
+
+// stylizer darkens the edges of frames and rounds their corners, with the
+// weights of every pixel computed once for the output size
+type stylizer struct {
+	bounds image.Rectangle
+	// shade multiplies the colors of every pixel
+	shade []float64
+	// cover is the share of every pixel kept over the corner color
+	cover []float64
+	// corner is the color shown outside the rounded corners
+	corner color.RGBA
+}
+
+// newStylizer returns a stylizer of frames of the given bounds applying the
+// vignette and rounded corners of opts, nil when none is set
+func newStylizer(bounds image.Rectangle, opts Options) *stylizer {
+	if opts.Vignette == 0 && opts.CornerRadius == 0 {
+		return nil
+	}
+
+	s := &stylizer{
+		bounds: bounds,
+		shade:  make([]float64, bounds.Dx()*bounds.Dy()),
+		cover:  make([]float64, bounds.Dx()*bounds.Dy()),
+		corner: color.RGBAModel.Convert(color.White).(color.RGBA),
+	}
+	if opts.CornerColor != nil {
+		s.corner = color.RGBAModel.Convert(opts.CornerColor).(color.RGBA)
+	}
+
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	radius := float64(min(opts.CornerRadius, bounds.Dx()/2, bounds.Dy()/2))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+
+			// The vignette follows the shape of the frame, reaching its full
+			// strength in the corners
+			d := math.Hypot(px/w-0.5, py/h-0.5) / math.Sqrt2 * 2
+			t := max(0, min(1, (d-vignetteStart)/(1-vignetteStart)))
+			s.shade[y*bounds.Dx()+x] = 1 - opts.Vignette*t*t*(3-2*t)
+
+			// Pixels on the edge of a corner are partly covered, smoothing it
+			cover := 1.0
+			cx, cy := max(radius-px, px-(w-radius), 0), max(radius-py, py-(h-radius), 0)
+			if cx > 0 && cy > 0 {
+				cover = max(0, min(1, radius+0.5-math.Hypot(cx, cy)))
+			}
+			s.cover[y*bounds.Dx()+x] = cover
+		}
+	}
+	return s
+}
+
+// apply returns a copy of img with the vignette and rounded corners
+func (s *stylizer) apply(img image.Image) *image.RGBA {
+	rgba := toRGBA(img)
+	for y := 0; y < s.bounds.Dy(); y++ {
+		pix := rgba.Pix[rgba.PixOffset(s.bounds.Min.X, s.bounds.Min.Y+y):]
+		for x := 0; x < s.bounds.Dx(); x++ {
+			p := pix[4*x : 4*x+4]
+			shade, cover := s.shade[y*s.bounds.Dx()+x], s.cover[y*s.bounds.Dx()+x]
+			corner := [4]uint8{s.corner.R, s.corner.G, s.corner.B, s.corner.A}
+			for c := range p {
+				v := float64(p[c])
+				if c < 3 {
+					v *= shade
+				}
+				p[c] = uint8(v*cover + float64(corner[c])*(1-cover) + 0.5)
+			}
+		}
+	}
+	return rgba
+}
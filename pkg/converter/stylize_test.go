@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestStylizer(t *testing.T) {
+	bounds := image.Rect(0, 0, 40, 20)
+	gray := image.NewRGBA(bounds)
+	for i := range gray.Pix {
+		gray.Pix[i] = 200
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		// want are the colors expected at a few points
+		want map[image.Point]color.RGBA
+	}{
+		{
+			name: "vignette",
+			opts: Options{Vignette: 1},
+			want: map[image.Point]color.RGBA{
+				{20, 10}: {200, 200, 200, 200},
+				{0, 0}:   {2, 2, 2, 200},
+			},
+		},
+		{
+			name: "rounded corners",
+			opts: Options{CornerRadius: 6, CornerColor: color.RGBA{0, 0, 255, 255}},
+			want: map[image.Point]color.RGBA{
+				{20, 10}: {200, 200, 200, 200},
+				{0, 0}:   {0, 0, 255, 255},
+				{39, 19}: {0, 0, 255, 255},
+				{6, 0}:   {200, 200, 200, 200},
+				{0, 10}:  {200, 200, 200, 200},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newStylizer(bounds, tt.opts).apply(gray)
+			for at, want := range tt.want {
+				if c := got.RGBAAt(at.X, at.Y); c != want {
+					t.Errorf("pixel %v = %v, want %v", at, c, want)
+				}
+			}
+		})
+	}
+
+	if s := newStylizer(bounds, Options{}); s != nil {
+		t.Error("newStylizer() without styles should return nil")
+	}
+}
@@ -0,0 +1,61 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// tempDirs tracks the temporary directories of running conversions so that
+// RemoveTempDirs can delete them when the process is interrupted
+var tempDirs = struct {
+	sync.Mutex
+	// keep is set for the directories kept with Options.KeepTemp
+	keep map[string]bool
+}{keep: make(map[string]bool)}
+
+// makeTempDir creates a temporary directory under opts.TmpDir, the system
+// temporary directory when empty
+func makeTempDir(opts Options, pattern string) (string, error) {
+	if opts.TmpDir != "" {
+		if err := os.MkdirAll(opts.TmpDir, 0755); err != nil {
+			return "", fmt.Errorf("error creating temporary directory: %v", err)
+		}
+	}
+	dir, err := os.MkdirTemp(opts.TmpDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary directory: %v", err)
+	}
+
+	tempDirs.Lock()
+	defer tempDirs.Unlock()
+	tempDirs.keep[dir] = opts.KeepTemp
+	return dir, nil
+}
+
+// removeTempDir deletes a directory created by makeTempDir, unless it is kept.
+// It reports whether the directory was kept.
+func removeTempDir(dir string) bool {
+	tempDirs.Lock()
+	defer tempDirs.Unlock()
+	keep := tempDirs.keep[dir]
+	delete(tempDirs.keep, dir)
+	if !keep {
+		os.RemoveAll(dir)
+	}
+	return keep
+}
+
+// RemoveTempDirs deletes the temporary directories of the conversions still
+// running, except those kept with Options.KeepTemp. It is meant to be called
+// before exiting on an interruption.
+func RemoveTempDirs() {
+	tempDirs.Lock()
+	defer tempDirs.Unlock()
+	for dir, keep := range tempDirs.keep {
+		if !keep {
+			os.RemoveAll(dir)
+		}
+		delete(tempDirs.keep, dir)
+	}
+}
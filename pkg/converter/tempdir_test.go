@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTempDirs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name      string
+		keepTemp  bool
+		interrupt bool
+	}{
+		{name: "removed"},
+		{name: "kept", keepTemp: true},
+		{name: "interrupted", interrupt: true},
+		{name: "kept when interrupted", keepTemp: true, interrupt: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{TmpDir: filepath.Join(tempDir, "nested", "tmp"), KeepTemp: tt.keepTemp}
+			dir, err := makeTempDir(opts, "test-*")
+			if err != nil {
+				t.Fatalf("makeTempDir() error = %v", err)
+			}
+			if !strings.HasPrefix(dir, opts.TmpDir) {
+				t.Errorf("makeTempDir() = %s, want a directory in %s", dir, opts.TmpDir)
+			}
+
+			if tt.interrupt {
+				RemoveTempDirs()
+			} else if kept := removeTempDir(dir); kept != tt.keepTemp {
+				t.Errorf("removeTempDir() kept = %v, want %v", kept, tt.keepTemp)
+			}
+			if _, err := os.Stat(dir); os.IsNotExist(err) == tt.keepTemp {
+				t.Errorf("directory exists = %v, want %v", err == nil, tt.keepTemp)
+			}
+		})
+	}
+}
+
+func TestConvertTmpDir(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	inputFiles := writeNoiseFrames(t, tempDir, 2, 8, 8)
+	tmpDir := filepath.Join(tempDir, "tmp")
+	outputFile := filepath.Join(tempDir, "out.gif")
+
+	// Spilled frames are removed after the conversion
+	report, err := Convert(inputFiles, outputFile, Options{MemoryLimit: 1, TmpDir: tmpDir})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if report.SpilledFrames == 0 || len(report.KeptTemp) != 0 {
+		t.Fatalf("spilled %d frames, kept %v", report.SpilledFrames, report.KeptTemp)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+		t.Errorf("temporary directory not cleaned up: %v", entries)
+	}
+
+	// Failed conversions clean up too
+	if _, err := Convert(inputFiles, filepath.Join(tempDir, "missing", "out.gif"), Options{MemoryLimit: 1, TmpDir: tmpDir}); err == nil {
+		t.Fatal("Convert() expected error for a missing output directory")
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+		t.Errorf("temporary directory not cleaned up after a failure: %v", entries)
+	}
+
+	report, err = Convert(inputFiles, outputFile, Options{MemoryLimit: 1, TmpDir: tmpDir, KeepTemp: true})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(report.KeptTemp) != 1 {
+		t.Fatalf("kept %v, want the spill directory", report.KeptTemp)
+	}
+	if entries, _ := os.ReadDir(report.KeptTemp[0]); len(entries) != 2 {
+		t.Errorf("kept directory holds %d frames, want 2", len(entries))
+	}
+}
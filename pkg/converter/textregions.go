@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+const (
+	// textBlockSize is the side of the square blocks analyzed by detectTextRegions
+	textBlockSize = 8
+	// textEdgeStep is the luminance jump between neighbor pixels counted as a sharp edge
+	textEdgeStep = 64
+	// textEdgeDensity is the share of sharp edges from which a block is considered text
+	textEdgeDensity = 0.12
+)
+
+// ParseRegion parses a rectangle given as "x,y,WxH"
+func ParseRegion(s string) (image.Rectangle, error) {
+	parts := strings.Split(strings.TrimSpace(s), ",")
+	if len(parts) != 3 {
+		return image.Rectangle{}, fmt.Errorf("invalid region %q: expected x,y,WxH", s)
+	}
+	x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
+	y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
+	w, h, err := ParseSize(parts[2])
+	if errX != nil || errY != nil || err != nil || x < 0 || y < 0 {
+		return image.Rectangle{}, fmt.Errorf("invalid region %q: expected x,y,WxH", s)
+	}
+	return image.Rect(x, y, x+w, y+h), nil
+}
+
+// ParseSize parses positive dimensions given as "WxH"
+func ParseSize(s string) (int, int, error) {
+	wStr, hStr, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid size %q: expected WxH", s)
+	}
+	w, errW := strconv.Atoi(wStr)
+	h, errH := strconv.Atoi(hStr)
+	if errW != nil || errH != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q: expected positive WxH", s)
+	}
+	return w, h, nil
+}
+
+// detectTextRegions finds the blocks of img with a high density of sharp luminance
+// edges, which is typical of rendered text and UI line art
+func detectTextRegions(img image.Image) []image.Rectangle {
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+	draw.Draw(gray, bounds, img, bounds.Min, draw.Src)
+
+	var regions []image.Rectangle
+	for by := bounds.Min.Y; by < bounds.Max.Y; by += textBlockSize {
+		for bx := bounds.Min.X; bx < bounds.Max.X; bx += textBlockSize {
+			block := image.Rect(bx, by, bx+textBlockSize, by+textBlockSize).Intersect(bounds)
+
+			edges := 0
+			for y := block.Min.Y; y < block.Max.Y; y++ {
+				for x := block.Min.X; x < block.Max.X; x++ {
+					l := gray.GrayAt(x, y).Y
+					if x+1 < bounds.Max.X && absDiff(l, gray.GrayAt(x+1, y).Y) >= textEdgeStep {
+						edges++
+					}
+					if y+1 < bounds.Max.Y && absDiff(l, gray.GrayAt(x, y+1).Y) >= textEdgeStep {
+						edges++
+					}
+				}
+			}
+
+			if float64(edges) >= textEdgeDensity*float64(2*block.Dx()*block.Dy()) {
+				regions = append(regions, block)
+			}
+		}
+	}
+	return regions
+}
+
+// drawPreservingText draws img onto the paletted dst, dithering the background to make
+// up for the colors reserved for text while text regions are mapped to their nearest color
+func drawPreservingText(dst *image.Paletted, img image.Image, regions []image.Rectangle) {
+	draw.FloydSteinberg.Draw(dst, dst.Bounds(), img, img.Bounds().Min)
+	for _, r := range regions {
+		r = r.Intersect(dst.Bounds())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				dst.SetColorIndex(x, y, uint8(dst.Palette.Index(img.At(x, y))))
+			}
+		}
+	}
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// findTextRegions returns the areas of each frame whose colors must be kept exact,
+// nil when neither PreserveText nor TextRegions is set
+func findTextRegions(frames []image.Image, opts Options) [][]image.Rectangle {
+	if !opts.PreserveText && len(opts.TextRegions) == 0 {
+		return nil
+	}
+
+	regions := make([][]image.Rectangle, len(frames))
+	detected := 0
+	for i, img := range frames {
+		regions[i] = append(regions[i], opts.TextRegions...)
+		if opts.PreserveText {
+			found := detectTextRegions(img)
+			detected += len(found)
+			regions[i] = append(regions[i], found...)
+		}
+	}
+	if opts.Debug {
+		fmt.Printf("Detected %d text blocks\n", detected)
+	}
+	return regions
+}
@@ -0,0 +1,92 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseRegion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    image.Rectangle
+		wantErr bool
+	}{
+		{input: "10,20,300x40", want: image.Rect(10, 20, 310, 60)},
+		{input: " 0, 0, 5X5 ", want: image.Rect(0, 0, 5, 5)},
+		{input: "10,20", wantErr: true},
+		{input: "10,20,300", wantErr: true},
+		{input: "-1,0,5x5", wantErr: true},
+		{input: "0,0,0x5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRegion(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRegion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRegion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectTextRegions(t *testing.T) {
+	// Left half: smooth gradient, right half: black and white stripes like glyph strokes
+	img := image.NewRGBA(image.Rect(0, 0, 32, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.Gray{uint8(x * 8)})
+		}
+		for x := 16; x < 32; x++ {
+			if x%2 == 0 {
+				img.Set(x, y, color.Black)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+
+	regions := detectTextRegions(img)
+	if len(regions) == 0 {
+		t.Fatal("detectTextRegions() found no text blocks")
+	}
+	for _, r := range regions {
+		if r.Min.X < 16 {
+			t.Errorf("detectTextRegions() marked gradient block %v as text", r)
+		}
+	}
+}
+
+func TestBuildWeightedPalette(t *testing.T) {
+	// 300 background shades outnumber a small text area using a rare color
+	img := image.NewRGBA(image.Rect(0, 0, 300, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 300; x++ {
+			img.Set(x, y, color.RGBA{uint8(x % 256), uint8(x / 256), 100, 255})
+		}
+	}
+	textRed := color.RGBA{255, 0, 0, 255}
+	img.Set(299, 9, textRed)
+
+	contains := func(p color.Palette, c color.Color) bool {
+		for _, pc := range p {
+			if pc == c {
+				return true
+			}
+		}
+		return false
+	}
+
+	if plain := buildPalette([]image.Image{img}); len(plain) > 256 {
+		t.Fatalf("buildPalette() returned %d colors", len(plain))
+	}
+
+	regions := [][]image.Rectangle{{image.Rect(299, 9, 300, 10)}}
+	weighted := buildWeightedPalette([]image.Image{img}, regions, MaxColors)
+	if !contains(weighted, textRed) {
+		t.Error("buildWeightedPalette() dropped the text color")
+	}
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+// TimelineEntry assigns a delay to an inclusive range of frames
+type TimelineEntry struct {
+	First int
+	Last  int
+	Delay time.Duration
+}
+
+// ParseTimeline parses a compact per-range timing specification such as
+// "0-30@100ms,31@2s,32-60@80ms" into timeline entries
+func ParseTimeline(spec string) ([]TimelineEntry, error) {
+	var entries []TimelineEntry
+	if strings.TrimSpace(spec) == "" {
+		return entries, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		frames, duration, ok := strings.Cut(part, "@")
+		if !ok {
+			return nil, fmt.Errorf("invalid timeline entry %q: expected <frames>@<duration>", part)
+		}
+
+		delay, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeline entry %q: %v", part, err)
+		}
+		if delay < 0 {
+			return nil, fmt.Errorf("invalid timeline entry %q: delay must be non-negative", part)
+		}
+
+		first, last, err := overlay.ParseFrameRange(frames)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeline entry %q: %v", part, err)
+		}
+
+		entries = append(entries, TimelineEntry{First: first, Last: last, Delay: delay})
+	}
+	return entries, nil
+}
+
+// applyTimeline overrides the delays (in 100ths of a second) of the frames covered by entries
+func applyTimeline(delays []int, entries []TimelineEntry) error {
+	for _, entry := range entries {
+		if entry.Last >= len(delays) {
+			return fmt.Errorf("timeline range %d-%d exceeds the %d available frames", entry.First, entry.Last, len(delays))
+		}
+		for i := entry.First; i <= entry.Last; i++ {
+			delays[i] = centiseconds(entry.Delay)
+		}
+	}
+	return nil
+}
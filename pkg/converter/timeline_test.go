@@ -0,0 +1,125 @@
+package converter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []TimelineEntry
+		wantErr bool
+	}{
+		{
+			name: "Empty spec",
+			spec: "",
+			want: nil,
+		},
+		{
+			name: "Ranges and single frames",
+			spec: "0-30@100ms,31@2s,32-60@80ms",
+			want: []TimelineEntry{
+				{First: 0, Last: 30, Delay: 100 * time.Millisecond},
+				{First: 31, Last: 31, Delay: 2 * time.Second},
+				{First: 32, Last: 60, Delay: 80 * time.Millisecond},
+			},
+		},
+		{
+			name: "Whitespace is ignored",
+			spec: " 1 @ 1s , 2-3@50ms",
+			want: []TimelineEntry{
+				{First: 1, Last: 1, Delay: time.Second},
+				{First: 2, Last: 3, Delay: 50 * time.Millisecond},
+			},
+		},
+		{name: "Missing duration", spec: "0-3", wantErr: true},
+		{name: "Invalid duration", spec: "0@fast", wantErr: true},
+		{name: "Reversed range", spec: "5-2@1s", wantErr: true},
+		{name: "Negative index", spec: "-1@1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimeline(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseTimeline() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseTimeline()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyTimeline(t *testing.T) {
+	// Delays are rounded to 100ths of a second, short ones kept non-zero
+	entries, err := ParseTimeline("0-1@200ms,2@5ms,3@2s,4@19ms")
+	if err != nil {
+		t.Fatalf("ParseTimeline() error = %v", err)
+	}
+
+	delays := []int{10, 10, 10, 10, 10}
+	if err := applyTimeline(delays, entries); err != nil {
+		t.Fatalf("applyTimeline() error = %v", err)
+	}
+	want := []int{20, 20, 1, 200, 2}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Errorf("applyTimeline() delays = %v, want %v", delays, want)
+			break
+		}
+	}
+
+	if err := applyTimeline(delays, []TimelineEntry{{First: 2, Last: 5, Delay: time.Second}}); err == nil {
+		t.Error("applyTimeline() expected error for a range beyond the last frame")
+	}
+}
+
+func TestPlaybackTrimIdle(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []int
+	}{
+		{
+			name: "Pauses kept",
+			opts: Options{Delay: 100},
+			want: []int{5, 3000, 10, 250},
+		},
+		{
+			name: "Recorded pauses capped",
+			opts: Options{Delay: 100, TrimIdle: 2 * time.Second},
+			want: []int{5, 200, 10, 200},
+		},
+		{
+			name: "Timeline delays are not capped",
+			opts: Options{Delay: 100, TrimIdle: 2 * time.Second, Timeline: "3@5s"},
+			want: []int{5, 200, 10, 500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The third frame has no delay of its own
+			delays, positions, _, _, err := playback([]int{5, 3000, 0, 250}, tt.opts)
+			if err != nil {
+				t.Fatalf("playback() error = %v", err)
+			}
+			for i := range tt.want {
+				if delays[i] != tt.want[i] {
+					t.Fatalf("playback() delays = %v, want %v", delays, tt.want)
+				}
+			}
+			if want := time.Duration(tt.want[0]+tt.want[1]) * 10 * time.Millisecond; positions[2] != want {
+				t.Errorf("playback() position of frame 2 = %v, want %v", positions[2], want)
+			}
+		})
+	}
+}
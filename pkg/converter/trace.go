@@ -0,0 +1,168 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+	"github.com/jparrill/go-togif/pkg/quality"
+)
+
+// frameTrace holds the diagnostics of a frame written with Options.Trace
+type frameTrace struct {
+	file   string
+	decode time.Duration
+	// input is the size of the decoded frame, output the size it is scaled to
+	input, output image.Point
+	scaler        string
+	// colors is the number of distinct colors before quantization, at most
+	// ManyColors+1
+	colors int
+	// quantError is the mean squared error of the quantized frame, set with
+	// quantized
+	quantError float64
+	quantized  bool
+	bytes      int
+}
+
+// tracer collects per-frame diagnostics and writes them to the trace file,
+// once the frames are encoded or the conversion failed. A nil tracer records
+// nothing. Frames are traced by their index in the output, before the poster
+// frame is moved; each index is recorded by a single goroutine at a time.
+type tracer struct {
+	path   string
+	frames []frameTrace
+	closed bool
+}
+
+// newTracer returns a tracer writing to path the diagnostics of the frames
+// read from the files names, or nil when path is empty
+func newTracer(path string, names []string) (*tracer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	// Fail before any work when the trace cannot be written
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace file: %v", err)
+	}
+	file.Close()
+
+	t := &tracer{path: path, frames: make([]frameTrace, len(names))}
+	for i, name := range names {
+		t.frames[i].file = filepath.Base(name)
+	}
+	return t, nil
+}
+
+// decoded adds d to the time spent reading and decoding frame i
+func (t *tracer) decoded(i int, d time.Duration) {
+	if t != nil {
+		t.frames[i].decode += d
+	}
+}
+
+// resized records that frame i of the given bounds is scaled to output
+func (t *tracer) resized(i int, input, output image.Rectangle, scaler string) {
+	if t != nil {
+		t.frames[i].input, t.frames[i].output = input.Size(), output.Size()
+		t.frames[i].scaler = scaler
+	}
+}
+
+// quantized records the colors of frame i, img, and the error of its
+// quantization to paletted
+func (t *tracer) quantized(i int, img image.Image, paletted *image.Paletted) {
+	if t == nil {
+		return
+	}
+	img = loaded(img)
+	counts := make(map[color.RGBA]int)
+	countColors(counts, img, ManyColors)
+	t.frames[i].colors = len(counts)
+	t.frames[i].quantError = quality.MSE(paletted, img)
+	t.frames[i].quantized = true
+}
+
+// encoded records the bytes each frame takes in the GIF at path, whose
+// poster frame was moved to the front as opts tells
+func (t *tracer) encoded(path string, opts Options) error {
+	if t == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading output for the trace: %v", err)
+	}
+	sizes, err := decode.GIFFrameSizes(data)
+	if err != nil {
+		return fmt.Errorf("error reading output for the trace: %v", err)
+	}
+
+	// Map the encoded frames back to the frames they show
+	order := make([]int, len(t.frames))
+	for i := range order {
+		order[i] = i
+	}
+	order, _, err = applyPosterFrame(order, make([]int, len(order)), opts.PosterFrame, opts.PosterMode)
+	if err != nil {
+		return err
+	}
+	if len(order) != len(sizes) {
+		return fmt.Errorf("error reading output for the trace: %d frames encoded, expected %d", len(sizes), len(order))
+	}
+	for i := range t.frames {
+		t.frames[i].bytes = 0
+	}
+	for k, i := range order {
+		t.frames[i].bytes += sizes[k]
+	}
+	return nil
+}
+
+// close writes a line per frame to the trace file, the fields of the steps
+// the frame went through, once
+func (t *tracer) close() error {
+	if t == nil || t.closed {
+		return nil
+	}
+	t.closed = true
+
+	var b strings.Builder
+	for i, frame := range t.frames {
+		fmt.Fprintf(&b, "frame=%d file=%q decode=%s", i, frame.file, frame.decode.Round(time.Microsecond))
+		if frame.input != (image.Point{}) {
+			fmt.Fprintf(&b, " input=%dx%d", frame.input.X, frame.input.Y)
+			if frame.input == frame.output {
+				b.WriteString(" resize=none")
+			} else {
+				scaler := frame.scaler
+				if scaler == "" {
+					scaler = ScalerSmooth
+				}
+				fmt.Fprintf(&b, " resize=%dx%d/%s", frame.output.X, frame.output.Y, scaler)
+			}
+		}
+		if frame.quantized {
+			if frame.colors > ManyColors {
+				fmt.Fprintf(&b, " colors=%d+", ManyColors)
+			} else {
+				fmt.Fprintf(&b, " colors=%d", frame.colors)
+			}
+			fmt.Fprintf(&b, " quant-mse=%.2f", frame.quantError)
+		}
+		if frame.bytes > 0 {
+			fmt.Fprintf(&b, " bytes=%d", frame.bytes)
+		}
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(t.path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing trace file: %v", err)
+	}
+	return nil
+}
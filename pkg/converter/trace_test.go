@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// traceFields parses the key=value fields of the lines of a trace file
+func traceFields(t *testing.T, path string) []map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read trace: %v", err)
+	}
+	var lines []map[string]string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := make(map[string]string)
+		for _, field := range strings.Fields(line) {
+			key, value, _ := strings.Cut(field, "=")
+			fields[key] = value
+		}
+		lines = append(lines, fields)
+	}
+	return lines
+}
+
+func TestConvertTrace(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	files := writeNoiseFrames(t, tempDir, 3, 40, 20)
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantResize string
+	}{
+		{name: "defaults", opts: Options{}, wantResize: "none"},
+		{name: "scaled", opts: Options{Width: 20, Scaler: ScalerNearest}, wantResize: "20x10/nearest"},
+		{name: "poster frame", opts: Options{PosterFrame: 2}, wantResize: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracePath := filepath.Join(tempDir, "trace.log")
+			tt.opts.Trace = tracePath
+			outputFile := filepath.Join(tempDir, "out.gif")
+			if _, err := Convert(files, outputFile, tt.opts); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			lines := traceFields(t, tracePath)
+			if len(lines) != len(files) {
+				t.Fatalf("trace has %d lines, want %d", len(lines), len(files))
+			}
+			total := 0
+			for i, fields := range lines {
+				if fields["frame"] != strconv.Itoa(i) || fields["file"] != strconv.Quote(filepath.Base(files[i])) {
+					t.Errorf("line %d = %v, want frame %d of %s", i, fields, i, filepath.Base(files[i]))
+				}
+				if fields["input"] != "40x20" || fields["resize"] != tt.wantResize {
+					t.Errorf("line %d input %s resize %s, want 40x20 and %s", i, fields["input"], fields["resize"], tt.wantResize)
+				}
+				if _, ok := fields["decode"]; !ok {
+					t.Errorf("line %d has no decode time", i)
+				}
+				if _, ok := fields["colors"]; !ok {
+					t.Errorf("line %d has no color count", i)
+				}
+				if mse, err := strconv.ParseFloat(fields["quant-mse"], 64); err != nil || mse < 0 {
+					t.Errorf("line %d quant-mse = %q, want a positive error", i, fields["quant-mse"])
+				}
+				bytes, err := strconv.Atoi(fields["bytes"])
+				if err != nil || bytes <= 0 {
+					t.Errorf("line %d bytes = %q, want a size", i, fields["bytes"])
+				}
+				total += bytes
+			}
+
+			info, err := os.Stat(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to stat output: %v", err)
+			}
+			if int64(total) >= info.Size() {
+				t.Errorf("frames take %d bytes, want less than the %d bytes of the GIF", total, info.Size())
+			}
+		})
+	}
+}
+
+func TestConvertTraceFailure(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	files := writeNoiseFrames(t, tempDir, 3, 40, 20)
+
+	// A frame failing to decode is left out of the trace of the others
+	if err := os.WriteFile(files[2], []byte("not a PNG"), 0644); err != nil {
+		t.Fatalf("Failed to write broken frame: %v", err)
+	}
+	tracePath := filepath.Join(tempDir, "trace.log")
+	if _, err := Convert(files, filepath.Join(tempDir, "out.gif"), Options{Trace: tracePath}); err == nil {
+		t.Fatalf("Convert() error = nil, want a decoding error")
+	}
+	lines := traceFields(t, tracePath)
+	if len(lines) != len(files) || lines[0]["input"] != "40x20" {
+		t.Errorf("trace = %v, want a line per frame, the first one decoded", lines)
+	}
+
+	// The trace file is checked before any work
+	if _, err := Convert(files, filepath.Join(tempDir, "out.gif"), Options{Trace: filepath.Join(tempDir, "missing", "trace.log")}); err == nil || !strings.Contains(err.Error(), "trace file") {
+		t.Errorf("Convert() error = %v, want a trace file error", err)
+	}
+}
@@ -0,0 +1,137 @@
+package converter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimestamp parses a playback position given as HH:MM:SS[.mmm], MM:SS[.mmm],
+// plain seconds ("5.5") or a Go duration ("1m30s")
+func ParseTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	if strings.Contains(s, ":") {
+		parts := strings.Split(s, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		var seconds float64
+		for i, part := range parts {
+			// Only the seconds field may have a fractional part
+			if i < len(parts)-1 {
+				n, err := strconv.Atoi(part)
+				if err != nil || n < 0 {
+					return 0, fmt.Errorf("invalid timestamp %q", s)
+				}
+				seconds = seconds*60 + float64(n)
+				continue
+			}
+			f, err := strconv.ParseFloat(part, 64)
+			if err != nil || f < 0 {
+				return 0, fmt.Errorf("invalid timestamp %q", s)
+			}
+			seconds = seconds*60 + f
+		}
+		return time.Duration(math.Round(seconds * float64(time.Second))), nil
+	}
+
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q: must be non-negative", s)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timestamp %q: must be non-negative", s)
+	}
+	return d, nil
+}
+
+// framePositions returns the playback position of every frame given their delays (in 100ths of a second)
+func framePositions(delays []int) []time.Duration {
+	positions := make([]time.Duration, len(delays))
+	var position time.Duration
+	for i, delay := range delays {
+		positions[i] = position
+		position += time.Duration(delay) * 10 * time.Millisecond
+	}
+	return positions
+}
+
+// trimByTime returns the window [first, last) of frames whose playback starts within
+// [start, end), given their playback positions. An end of 0 means until the last frame.
+func trimByTime(positions []time.Duration, start, end time.Duration) (int, int, error) {
+	if end != 0 && end <= start {
+		return 0, 0, fmt.Errorf("end %v must be after start %v", end, start)
+	}
+
+	first, last := len(positions), len(positions)
+	for i, position := range positions {
+		if position >= start && first == len(positions) {
+			first = i
+		}
+		if end != 0 && position >= end {
+			last = i
+			break
+		}
+	}
+
+	if first >= last {
+		return 0, 0, fmt.Errorf("no frames between %v and %v", start, end)
+	}
+	return first, last, nil
+}
+
+// clipDuration shortens the playback of the frames [first, last) to limit,
+// dropping the frames shown later and shortening the delay of the last one
+// kept. It returns the new end of the frames.
+func clipDuration(delays []int, positions []time.Duration, first, last int, limit time.Duration) int {
+	end := positions[first] + limit
+	for i := first; i < last; i++ {
+		if positions[i] >= end && i > first {
+			return i
+		}
+		if positions[i]+time.Duration(delays[i])*10*time.Millisecond > end {
+			delays[i] = centiseconds(end - positions[i])
+			return i + 1
+		}
+	}
+	return last
+}
+
+// subsampleDuration returns the most evenly spaced frames of [first, last)
+// whose own delays add up to at most limit, speeding up the playback instead
+// of cutting its end. It returns nil when the frames already fit or when fewer
+// than two frames would, leaving the end to clipDuration.
+func subsampleDuration(delays []int, first, last int, limit time.Duration) []int {
+	total := 0
+	for _, delay := range delays[first:last] {
+		total += delay
+	}
+	if time.Duration(total)*10*time.Millisecond <= limit || total == 0 {
+		return nil
+	}
+
+	for n := last - first - 1; n >= 2; n-- {
+		indices := sampleIndices(first, last, n)
+		kept := 0
+		for _, i := range indices {
+			kept += delays[i]
+		}
+		if time.Duration(kept)*10*time.Millisecond <= limit {
+			return indices
+		}
+	}
+	return nil
+}
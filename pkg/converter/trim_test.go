@@ -0,0 +1,155 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "00:00:05", want: 5 * time.Second},
+		{input: "01:02:03.5", want: time.Hour + 2*time.Minute + 3500*time.Millisecond},
+		{input: "1:30", want: 90 * time.Second},
+		{input: "12.25", want: 12250 * time.Millisecond},
+		{input: "1m30s", want: 90 * time.Second},
+		{input: "1:2:3:4", wantErr: true},
+		{input: "00:aa:05", wantErr: true},
+		{input: "-5", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimestamp(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimByTime(t *testing.T) {
+	// Each frame lasts one second
+	positions := framePositions([]int{100, 100, 100, 100, 100})
+
+	tests := []struct {
+		name      string
+		start     time.Duration
+		end       time.Duration
+		wantFirst int
+		wantLast  int
+		wantErr   bool
+	}{
+		{name: "No trimming", wantFirst: 0, wantLast: 5},
+		{name: "Start only", start: 3 * time.Second, wantFirst: 3, wantLast: 5},
+		{name: "Start and end", start: time.Second, end: 3 * time.Second, wantFirst: 1, wantLast: 3},
+		{name: "Start within a frame", start: 1500 * time.Millisecond, wantFirst: 2, wantLast: 5},
+		{name: "End before start", start: 3 * time.Second, end: time.Second, wantErr: true},
+		{name: "Start after the end of the sequence", start: 10 * time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last, err := trimByTime(positions, tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("trimByTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (first != tt.wantFirst || last != tt.wantLast) {
+				t.Errorf("trimByTime() = [%d, %d), want [%d, %d)", first, last, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestClipDuration(t *testing.T) {
+	tests := []struct {
+		name       string
+		delays     []int
+		first      int
+		limit      time.Duration
+		wantLast   int
+		wantDelays []int
+	}{
+		{name: "shorter than the limit", delays: []int{50, 50}, limit: 3 * time.Second, wantLast: 2, wantDelays: []int{50, 50}},
+		{name: "last frame shortened", delays: []int{100, 100, 100, 100}, limit: 2500 * time.Millisecond, wantLast: 3, wantDelays: []int{100, 100, 50, 100}},
+		{name: "frames dropped", delays: []int{100, 100, 100, 100}, limit: 2 * time.Second, wantLast: 2, wantDelays: []int{100, 100, 100, 100}},
+		{name: "from the first kept frame", delays: []int{100, 100, 100}, first: 1, limit: 500 * time.Millisecond, wantLast: 2, wantDelays: []int{100, 50, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := append([]int(nil), tt.delays...)
+			last := clipDuration(delays, framePositions(delays), tt.first, len(delays), tt.limit)
+			if last != tt.wantLast {
+				t.Errorf("clipDuration() = %d, want %d", last, tt.wantLast)
+			}
+			if !reflect.DeepEqual(delays, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestSubsampleDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		delays []int
+		first  int
+		limit  time.Duration
+		want   []int
+	}{
+		{name: "shorter than the limit", delays: []int{50, 50}, limit: 3 * time.Second},
+		{name: "every other frame", delays: []int{10, 10, 10, 10, 10, 10}, limit: 300 * time.Millisecond, want: []int{0, 2, 4}},
+		{name: "from the first kept frame", delays: []int{10, 10, 10, 10, 10}, first: 1, limit: 200 * time.Millisecond, want: []int{1, 3}},
+		{name: "long frames are truncated", delays: []int{500, 500, 500}, limit: 3 * time.Second},
+		{name: "skips a long hold", delays: []int{10, 10, 300, 10, 10, 10}, limit: 500 * time.Millisecond, want: []int{0, 1, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subsampleDuration(tt.delays, tt.first, len(tt.delays), tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subsampleDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertMaxDuration(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for i := 0; i < 20; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		img.Set(i%4, i/4%4, color.RGBA{255, 0, 0, 255})
+		frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+	}
+
+	outputFile := filepath.Join(tempDir, "short.gif")
+	if _, err := ConvertFrames(frames, outputFile, Options{MaxDuration: time.Second}); err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	playback, err := readPlayback(outputFile)
+	if err != nil {
+		t.Fatalf("readPlayback() error = %v", err)
+	}
+	if playback.duration != time.Second {
+		t.Errorf("duration = %v, want %v", playback.duration, time.Second)
+	}
+}
@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// VariantKeys lists the settings a variant may set, named like the convert flags
+var VariantKeys = []string{"colors", "dither", "quantizer", "scaler", "width", "max-frames", "delay", "auto", "preserve-text"}
+
+// ditherAliases are the short names of dithering methods accepted in variants
+var ditherAliases = map[string]string{"fs": DitherFloydSteinberg}
+
+// ApplyVariant returns opts with the settings of variant, comma separated
+// key=value pairs named like the convert flags, e.g. "colors=64,dither=none"
+func ApplyVariant(opts Options, variant string) (Options, error) {
+	for _, setting := range strings.Split(variant, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(setting), "=")
+		if !ok || value == "" {
+			return opts, fmt.Errorf("invalid variant setting %q: expected key=value", setting)
+		}
+
+		var err error
+		switch key {
+		case "colors":
+			opts.Colors, err = strconv.Atoi(value)
+		case "dither":
+			if alias, ok := ditherAliases[value]; ok {
+				value = alias
+			}
+			opts.Dither = value
+		case "quantizer":
+			opts.Quantizer = value
+		case "scaler":
+			opts.Scaler = value
+		case "width":
+			opts.Width, err = strconv.Atoi(value)
+		case "max-frames":
+			opts.MaxFrames, err = strconv.Atoi(value)
+		case "delay":
+			opts.Delay, err = strconv.Atoi(value)
+		case "auto":
+			opts.Auto, err = strconv.ParseBool(value)
+		case "preserve-text":
+			opts.PreserveText, err = strconv.ParseBool(value)
+		default:
+			return opts, fmt.Errorf("unknown variant setting %q: expected one of %s", key, strings.Join(VariantKeys, ", "))
+		}
+		if err != nil {
+			return opts, fmt.Errorf("invalid variant setting %s: %v", setting, err)
+		}
+	}
+	return opts, validateOptions(opts)
+}
+
+// VariantPath returns the path the i-th variant, from 1, of outputFile is
+// written to, e.g. demo.gif -> demo.variant-1.gif
+func VariantPath(outputFile string, i int) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".variant-" + strconv.Itoa(i) + filepath.Ext(outputFile)
+}
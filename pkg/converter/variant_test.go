@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"testing"
+)
+
+func TestApplyVariant(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant string
+		want    Options
+		wantErr bool
+	}{
+		{name: "colors and dither", variant: "colors=64,dither=none", want: Options{Delay: 100, Colors: 64, Dither: DitherNone}},
+		{name: "dither alias", variant: "colors=256, dither=fs", want: Options{Delay: 100, Colors: 256, Dither: DitherFloydSteinberg}},
+		{name: "sizing and timing", variant: "width=320,max-frames=10,delay=50,scaler=nearest", want: Options{Delay: 50, Width: 320, MaxFrames: 10, Scaler: ScalerNearest}},
+		{name: "booleans", variant: "auto=true,preserve-text=true", want: Options{Delay: 100, Auto: true, PreserveText: true}},
+		{name: "unknown setting", variant: "speed=2", wantErr: true},
+		{name: "missing value", variant: "colors=", wantErr: true},
+		{name: "not a number", variant: "colors=many", wantErr: true},
+		{name: "invalid options", variant: "dither=ordered", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyVariant(Options{Delay: 100}, tt.variant)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyVariant() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Colors != tt.want.Colors || got.Dither != tt.want.Dither || got.Width != tt.want.Width ||
+				got.MaxFrames != tt.want.MaxFrames || got.Delay != tt.want.Delay || got.Scaler != tt.want.Scaler ||
+				got.Auto != tt.want.Auto || got.PreserveText != tt.want.PreserveText {
+				t.Errorf("ApplyVariant() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVariantPath(t *testing.T) {
+	tests := []struct {
+		outputFile string
+		i          int
+		want       string
+	}{
+		{outputFile: "demo.gif", i: 1, want: "demo.variant-1.gif"},
+		{outputFile: "out/demo.gif", i: 12, want: "out/demo.variant-12.gif"},
+	}
+
+	for _, tt := range tests {
+		if got := VariantPath(tt.outputFile, tt.i); got != tt.want {
+			t.Errorf("VariantPath(%q, %d) = %q, want %q", tt.outputFile, tt.i, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+// verifySamples is about the number of pixels of every frame compared by
+// verifyGIF
+const verifySamples = 4096
+
+// verifyGIF decodes the GIF written at path and checks that players show the
+// intended frames: their number, the dimensions, the delays, and a sample
+// of the pixels of every composited frame. It fails on the first difference,
+// which means the file is corrupt.
+func verifyGIF(path string, images []*image.Paletted, delays []int, opts Options) error {
+	reader, err := gifutil.Open(path)
+	if err != nil {
+		return fmt.Errorf("verification of %s failed: %v", path, err)
+	}
+	if reader.Len() != len(images) {
+		return fmt.Errorf("verification of %s failed: %d frames written, %d expected", path, reader.Len(), len(images))
+	}
+	bounds := images[0].Bounds()
+	if reader.Bounds().Size() != bounds.Size() {
+		return fmt.Errorf("verification of %s failed: %dx%d written, %dx%d expected", path,
+			reader.Bounds().Dx(), reader.Bounds().Dy(), bounds.Dx(), bounds.Dy())
+	}
+
+	// Delays and transparency are stored in the control extension
+	control := gifExtensions(opts)[ExtensionControl]
+	step := max(1, int(math.Sqrt(float64(bounds.Dx()*bounds.Dy())/verifySamples)))
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for i, img := range images {
+		frame, err := reader.Next()
+		if err != nil {
+			return fmt.Errorf("verification of %s failed: %v", path, err)
+		}
+		want := time.Duration(delays[i]) * 10 * time.Millisecond
+		if !control {
+			want = 0
+		}
+		if frame.Delay != want {
+			return fmt.Errorf("verification of %s failed: frame %d is shown for %v, %v expected", path, i, frame.Delay, want)
+		}
+
+		composite(canvas, img, control)
+		for y := 0; y < bounds.Dy(); y += step {
+			for x := 0; x < bounds.Dx(); x += step {
+				if got, want := frame.Image.RGBAAt(x, y), canvas.RGBAAt(x, y); got != want {
+					return fmt.Errorf("verification of %s failed: frame %d shows %v at (%d, %d), %v expected", path, i, got, x, y, want)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// composite draws img over canvas as GIF players do: the first fully
+// transparent color of its palette, when transparency is stored, shows the
+// canvas, and the other colors are opaque
+func composite(canvas *image.RGBA, img *image.Paletted, transparency bool) {
+	transparent := -1
+	colors := make([]color.RGBA, len(img.Palette))
+	for i, c := range img.Palette {
+		r, g, b, a := c.RGBA()
+		if a == 0 && transparent < 0 && transparency {
+			transparent = i
+		}
+		colors[i] = color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255}
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			index := int(img.ColorIndexAt(x, y))
+			if index != transparent {
+				canvas.SetRGBA(x-b.Min.X, y-b.Min.Y, colors[index])
+			}
+		}
+	}
+}
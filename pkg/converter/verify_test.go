@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyGIF(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	palette := color.Palette{color.RGBA{0, 0, 0, 0}, color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}}
+	frames := func() []*image.Paletted {
+		var images []*image.Paletted
+		for f := 0; f < 3; f++ {
+			img := image.NewPaletted(image.Rect(0, 0, 40, 30), palette)
+			for i := range img.Pix {
+				// The transparent pixels of the later frames show the earlier ones
+				img.Pix[i] = uint8((i/7 + f) % len(palette))
+			}
+			images = append(images, img)
+		}
+		return images
+	}
+	delays := []int{10, 20, 30}
+
+	tests := []struct {
+		name string
+		opts Options
+		// written replaces the frames and delays written
+		written func(g *gif.GIF)
+		wantErr string
+	}{
+		{name: "intact", opts: Options{}},
+		{name: "intact without the control extension", opts: Options{GIFVersion: GIF87a}},
+		{name: "missing frame", written: func(g *gif.GIF) { g.Image, g.Delay = g.Image[:2], g.Delay[:2] }, wantErr: "2 frames written, 3 expected"},
+		{name: "wrong delay", written: func(g *gif.GIF) { g.Delay[1] = 50 }, wantErr: "frame 1 is shown for 500ms, 200ms expected"},
+		{name: "wrong pixel", written: func(g *gif.GIF) { g.Image[2].Pix[0] = (g.Image[2].Pix[0] + 1) % 3 }, wantErr: "frame 2 shows"},
+		{
+			name: "wrong size",
+			written: func(g *gif.GIF) {
+				for i, img := range g.Image {
+					g.Image[i] = img.SubImage(image.Rect(0, 0, 40, 20)).(*image.Paletted)
+				}
+			},
+			wantErr: "40x20 written, 40x30 expected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gif.GIF{Image: frames(), Delay: append([]int(nil), delays...)}
+			if tt.written != nil {
+				tt.written(g)
+			}
+			path := filepath.Join(tempDir, strings.ReplaceAll(tt.name, " ", "-")+".gif")
+			if err := writeGIF(path, g, tt.opts); err != nil {
+				t.Fatalf("writeGIF() error = %v", err)
+			}
+
+			err := verifyGIF(path, frames(), delays, tt.opts)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("verifyGIF() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyGIF() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("truncated file", func(t *testing.T) {
+		path := filepath.Join(tempDir, "truncated.gif")
+		if err := writeGIF(path, &gif.GIF{Image: frames(), Delay: delays}, Options{}); err != nil {
+			t.Fatalf("writeGIF() error = %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read GIF: %v", err)
+		}
+		if err := os.WriteFile(path, data[:len(data)/2], 0644); err != nil {
+			t.Fatalf("Failed to truncate GIF: %v", err)
+		}
+		if err := verifyGIF(path, frames(), delays, Options{}); err == nil || !strings.Contains(err.Error(), "verification of") {
+			t.Errorf("verifyGIF() error = %v, want a verification error", err)
+		}
+	})
+}
+
+func TestConvertVerify(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var frames []Frame
+	for f := 0; f < 4; f++ {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+		for y := 0; y < 48; y++ {
+			for x := 0; x < 64; x++ {
+				img.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 5), uint8(f * 60), 255})
+			}
+		}
+		frames = append(frames, Frame{Image: img})
+	}
+
+	for _, opts := range []Options{
+		{Delay: 100, Verify: true},
+		{Delay: 100, Verify: true, PosterFrame: 2, Dither: DitherFloydSteinberg, Sizes: []int{32, 64}},
+		{Delay: 100, Verify: true, GIFVersion: GIF87a},
+	} {
+		if _, err := ConvertFrames(frames, filepath.Join(tempDir, "out.gif"), opts); err != nil {
+			t.Errorf("ConvertFrames() with %+v error = %v", opts, err)
+		}
+	}
+}
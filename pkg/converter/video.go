@@ -0,0 +1,307 @@
+package converter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/external"
+)
+
+// Output formats
+const (
+	FormatGIF  = "gif"
+	FormatWebM = "webm"
+	// FormatWebP is an animated WebP with alpha
+	FormatWebP = "webp"
+	// FormatMP4 is an H.264 video without transparency, played by every browser
+	FormatMP4 = "mp4"
+	// FormatPDF is a document with a frame and its timing per page
+	FormatPDF = "pdf"
+	// FormatTIFF is a multi-page TIFF with a frame per page
+	FormatTIFF = "tiff"
+	// FormatAVIF is an animated AVIF with alpha, encoded by libavif in builds
+	// with the avif tag
+	FormatAVIF = "avif"
+)
+
+// Formats lists the output formats
+var Formats = []string{FormatGIF, FormatWebM, FormatWebP, FormatMP4, FormatPDF, FormatTIFF, FormatAVIF}
+
+const (
+	// FFmpegEnv names the environment variable with the path of the ffmpeg
+	// executable encoding video outputs and reading video inputs, looked up
+	// in the PATH when unset
+	FFmpegEnv = "GO_TOGIF_FFMPEG"
+	// DefaultVideoCRF is the constant rate factor of video outputs when
+	// Options.VideoCRF is 0, a good quality for VP9
+	DefaultVideoCRF = 32
+	// MaxVideoCRF is the lowest quality of VP9
+	MaxVideoCRF = 63
+	// mp4DefaultCRF and mp4MaxCRF are the default and lowest quality of H.264
+	mp4DefaultCRF = 23
+	mp4MaxCRF     = 51
+	// webpDefaultQuality is the quality of WebP outputs when Options.VideoCRF is 0
+	webpDefaultQuality = 75
+	// videoMaxFPS is the highest frame rate of video outputs
+	videoMaxFPS = 30
+	// DefaultExecTimeout bounds each run of ffmpeg when Options.ExecTimeout is 0
+	DefaultExecTimeout = 30 * time.Minute
+)
+
+// ffmpeg encodes video outputs and extracts the frames of video inputs, run
+// with the arguments of encodeVideo and extractVideoFrames only
+var ffmpeg = &external.Tool{
+	Name:        "ffmpeg",
+	Env:         FFmpegEnv,
+	VersionArgs: []string{"-version"},
+	Version:     regexp.MustCompile(`ffmpeg version (?:n?(\d+)\.(\d+)|N-)`),
+	MinVersion:  [2]int{4, 0},
+	Flags: map[string]int{
+		"-y": 0, "-an": 0, "-loglevel": 1, "-f": 1, "-pix_fmt": 1, "-s": 1, "-framerate": 1, "-i": 1,
+		"-c:v": 1, "-b:v": 1, "-crf": 1, "-quality": 1, "-loop": 1, "-vf": 1, "-movflags": 1,
+		"-hide_banner": 0, "-encoders": 0, "-t": 1,
+	},
+	Timeout: DefaultExecTimeout,
+}
+
+// ffmpegEncoders are the ffmpeg encoders of the video formats
+var ffmpegEncoders = map[string]string{
+	FormatWebM: "libvpx-vp9",
+	FormatWebP: "libwebp_anim",
+	FormatMP4:  "libx264",
+}
+
+// validateFormat checks the output formats of opts
+func validateFormat(opts Options) error {
+	if len(opts.Formats) > 0 {
+		if opts.Format != "" {
+			return fmt.Errorf("format and formats are mutually exclusive")
+		}
+		if len(opts.Sizes) > 0 {
+			return fmt.Errorf("sizes are not supported with several formats")
+		}
+		seen := make(map[string]bool, len(opts.Formats))
+		for _, format := range opts.Formats {
+			if !slices.Contains(Formats, format) {
+				return fmt.Errorf("invalid format %q: expected one of %s%s", format, strings.Join(Formats, ", "), suggest(format, Formats...))
+			}
+			if seen[format] {
+				return fmt.Errorf("format %s is listed twice", format)
+			}
+			seen[format] = true
+		}
+	} else if opts.Format != "" && !slices.Contains(Formats, opts.Format) {
+		return fmt.Errorf("invalid format %q: expected one of %s%s", opts.Format, strings.Join(Formats, ", "), suggest(opts.Format, Formats...))
+	}
+
+	if slices.Contains(outputFormats(opts), FormatAVIF) {
+		if err := validateAVIF(); err != nil {
+			return err
+		}
+	}
+	if opts.ExecTimeout < 0 {
+		return fmt.Errorf("exec timeout must not be negative")
+	}
+	for _, format := range outputFormats(opts) {
+		if opts.NoExec && runsFFmpeg(format) {
+			return fmt.Errorf("%s output is encoded by ffmpeg, which no exec disables", format)
+		}
+	}
+	if !isGIF(opts.Format) && len(opts.Sizes) > 0 {
+		return fmt.Errorf("sizes are not supported with the %s format", opts.Format)
+	}
+	if opts.VideoCRF < 0 || opts.VideoCRF > MaxVideoCRF {
+		return fmt.Errorf("video CRF must be between 0 and %d", MaxVideoCRF)
+	}
+	return nil
+}
+
+// isGIF reports whether format is written as a GIF, the default
+func isGIF(format string) bool {
+	return format == "" || format == FormatGIF
+}
+
+// isVideo reports whether format is encoded at a rate factor, by ffmpeg or libavif
+func isVideo(format string) bool {
+	return !isGIF(format) && !isFlipbook(format)
+}
+
+// runsFFmpeg reports whether format is encoded by ffmpeg
+func runsFFmpeg(format string) bool {
+	return isVideo(format) && format != FormatAVIF
+}
+
+// outputFormats returns the formats written by opts, in order
+func outputFormats(opts Options) []string {
+	if len(opts.Formats) > 0 {
+		return opts.Formats
+	}
+	if opts.Format != "" {
+		return []string{opts.Format}
+	}
+	return []string{FormatGIF}
+}
+
+// FormatPath returns the path outputFile is written to in the given format
+// when writing several formats, e.g. demo.gif -> demo.webp
+func FormatPath(outputFile, format string) string {
+	return strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "." + format
+}
+
+// FormatForPath returns the output format named by the extension of
+// outputFile, in any case, or "" when it names none
+func FormatForPath(outputFile string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(outputFile), "."))
+	if ext == "tif" {
+		return FormatTIFF
+	}
+	if slices.Contains(Formats, ext) {
+		return ext
+	}
+	return ""
+}
+
+// videoCRF returns the constant rate factor of opts
+func videoCRF(opts Options) int {
+	if opts.VideoCRF > 0 {
+		return opts.VideoCRF
+	}
+	return DefaultVideoCRF
+}
+
+// runner returns the runner of the external tools of opts
+func runner(opts Options) external.Runner {
+	return external.Runner{Disabled: opts.NoExec, Timeout: opts.ExecTimeout, Debug: opts.Debug}
+}
+
+// encodeOutput writes the frames, shown for their delays in 100ths of a
+// second, to a file of the given full color format at path
+func encodeOutput(path, format string, frames []image.Image, delays []int, opts Options) error {
+	switch {
+	case isFlipbook(format):
+		return writeFlipbook(path, format, frames, delays)
+	case runsFFmpeg(format):
+		return encodeVideo(path, format, frames, delays, opts)
+	default:
+		return encodeAVIF(path, frames, delays, opts)
+	}
+}
+
+// encodeVideo encodes the frames, shown for their delays in 100ths of a
+// second, to a file of the given format at path by piping them to ffmpeg
+func encodeVideo(path, format string, frames []image.Image, delays []int, opts Options) error {
+	bounds := frames[0].Bounds()
+	fps := videoFrameRate(delays)
+	args := []string{
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%dx%d", bounds.Dx(), bounds.Dy()),
+		"-framerate", strconv.Itoa(fps),
+		"-i", "-",
+		"-an",
+	}
+	// A path starting with a dash would read as an option
+	if strings.HasPrefix(path, "-") {
+		path = "." + string(filepath.Separator) + path
+	}
+	args = append(append(args, codecArgs(format, opts)...), "-f", format, path)
+
+	// Frames longer than a video frame are repeated, ffmpeg reading straight alpha
+	stdin, frameWriter := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		var err error
+		nrgba := image.NewNRGBA(bounds)
+		for i, count := range videoFrameCounts(delays, fps) {
+			if count == 0 {
+				continue
+			}
+			draw.Draw(nrgba, bounds, loaded(frames[i]), bounds.Min, draw.Src)
+			for ; count > 0 && err == nil; count-- {
+				_, err = frameWriter.Write(nrgba.Pix)
+			}
+		}
+		frameWriter.Close()
+		written <- err
+	}()
+	err := runner(opts).Run(ffmpeg, args, stdin)
+	// Stop writing frames ffmpeg will not read
+	stdin.Close()
+	writeErr := <-written
+	if err != nil {
+		return fmt.Errorf("error encoding video: %v", err)
+	}
+	if writeErr != nil {
+		return fmt.Errorf("error encoding video: %v", writeErr)
+	}
+	return nil
+}
+
+// codecArgs returns the ffmpeg arguments encoding the given format
+func codecArgs(format string, opts Options) []string {
+	switch format {
+	case FormatWebP:
+		quality := webpDefaultQuality
+		if opts.VideoCRF > 0 {
+			quality = 100 - opts.VideoCRF*100/MaxVideoCRF
+		}
+		return []string{"-c:v", ffmpegEncoders[FormatWebP], "-pix_fmt", "yuva420p", "-quality", strconv.Itoa(quality),
+			"-loop", strconv.Itoa(opts.Loops)}
+	case FormatMP4:
+		crf := mp4DefaultCRF
+		if opts.VideoCRF > 0 {
+			crf = min(mp4MaxCRF, opts.VideoCRF)
+		}
+		// H.264 needs even dimensions, and the moov atom first to start playing while downloading
+		return []string{"-c:v", ffmpegEncoders[FormatMP4], "-pix_fmt", "yuv420p", "-crf", strconv.Itoa(crf),
+			"-vf", "scale=trunc(iw/2)*2:trunc(ih/2)*2", "-movflags", "+faststart"}
+	default:
+		return []string{"-c:v", ffmpegEncoders[FormatWebM], "-pix_fmt", "yuva420p", "-b:v", "0", "-crf", strconv.Itoa(videoCRF(opts))}
+	}
+}
+
+// videoFrameRate returns the frame rate showing every delay exactly, up to
+// videoMaxFPS
+func videoFrameRate(delays []int) int {
+	step := 0
+	for _, delay := range delays {
+		step = gcd(step, delay)
+	}
+	if step == 0 {
+		return videoMaxFPS
+	}
+	return max(1, min(videoMaxFPS, 100/step))
+}
+
+// videoFrameCounts returns how many video frames at fps show each frame,
+// rounding their start times; frames shorter than a video frame may get none
+func videoFrameCounts(delays []int, fps int) []int {
+	counts := make([]int, len(delays))
+	elapsed, shown := 0, 0
+	for i, delay := range delays {
+		elapsed += delay
+		end := (elapsed*fps + 50) / 100
+		counts[i] = end - shown
+		shown = end
+	}
+	if shown == 0 && len(counts) > 0 {
+		counts[0] = 1
+	}
+	return counts
+}
+
+// gcd returns the greatest common divisor of a and b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
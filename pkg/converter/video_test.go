@@ -0,0 +1,189 @@
+package converter
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFFmpeg installs a script standing in for ffmpeg, which records its
+// arguments next to the output and copies the raw frames to it
+func fakeFFmpeg(t *testing.T, dir string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ffmpeg is a shell script")
+	}
+	script := filepath.Join(dir, "ffmpeg")
+	content := "#!/bin/sh\n[ \"$1\" = -version ] && echo \"ffmpeg version 6.1\" && exit\nfor last; do :; done\necho \"$@\" > \"$last.args\"\ncat > \"$last\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+	t.Setenv(FFmpegEnv, script)
+}
+
+func TestVideoFrameCounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		delays     []int
+		wantFPS    int
+		wantCounts []int
+	}{
+		{name: "even delays", delays: []int{10, 10, 20}, wantFPS: 10, wantCounts: []int{1, 1, 2}},
+		{name: "capped frame rate", delays: []int{2, 2, 2, 4}, wantFPS: 30, wantCounts: []int{1, 0, 1, 1}},
+		{name: "uneven delays", delays: []int{4, 6}, wantFPS: 30, wantCounts: []int{1, 2}},
+		{name: "no delays", delays: []int{0, 0}, wantFPS: 30, wantCounts: []int{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fps := videoFrameRate(tt.delays)
+			if fps != tt.wantFPS {
+				t.Errorf("videoFrameRate() = %d, want %d", fps, tt.wantFPS)
+			}
+			if got := videoFrameCounts(tt.delays, fps); !reflect.DeepEqual(got, tt.wantCounts) {
+				t.Errorf("videoFrameCounts() = %v, want %v", got, tt.wantCounts)
+			}
+		})
+	}
+}
+
+func TestConvertWebMPreset(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	fakeFFmpeg(t, tempDir)
+
+	// Five seconds of frames, cut to the three of a Telegram sticker
+	var frames []Frame
+	for i := 0; i < 10; i++ {
+		frames = append(frames, Frame{Image: image.NewRGBA(image.Rect(0, 0, 60, 40)), Delay: 500 * time.Millisecond})
+	}
+	outputFile := filepath.Join(tempDir, "sticker.webm")
+	report, err := ConvertFrames(frames, outputFile, Options{Preset: "telegram-emoji"})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if report.Frames != 6 || report.Width != 100 || report.Height != 100 {
+		t.Errorf("report = %d frames of %dx%d, want 6 frames of 100x100", report.Frames, report.Width, report.Height)
+	}
+
+	args, err := os.ReadFile(outputFile + ".args")
+	if err != nil {
+		t.Fatalf("Failed to read ffmpeg arguments: %v", err)
+	}
+	for _, want := range []string{"-s 100x100", "-framerate 2", "libvpx-vp9", "yuva420p", "-f webm"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("ffmpeg arguments %q lack %q", args, want)
+		}
+	}
+	info, err := os.Stat(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to stat output: %v", err)
+	}
+	if want := int64(6 * 100 * 100 * 4); info.Size() != want {
+		t.Errorf("ffmpeg got %d bytes of frames, want %d", info.Size(), want)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "default", opts: Options{}},
+		{name: "single video format", opts: Options{Format: FormatMP4}},
+		{name: "several formats", opts: Options{Formats: []string{FormatGIF, FormatWebP, FormatMP4}}},
+		{name: "unknown format", opts: Options{Format: "avi"}, wantErr: true},
+		{name: "unknown format in the list", opts: Options{Formats: []string{FormatGIF, "avi"}}, wantErr: true},
+		{name: "duplicate format", opts: Options{Formats: []string{FormatGIF, FormatGIF}}, wantErr: true},
+		{name: "format and formats", opts: Options{Format: FormatGIF, Formats: []string{FormatWebP}}, wantErr: true},
+		{name: "formats and sizes", opts: Options{Formats: []string{FormatGIF}, Sizes: []int{100}}, wantErr: true},
+		{name: "video and sizes", opts: Options{Format: FormatWebM, Sizes: []int{100}}, wantErr: true},
+		{name: "flipbook", opts: Options{Format: FormatPDF}},
+		{name: "flipbook and sizes", opts: Options{Format: FormatTIFF, Sizes: []int{100}}, wantErr: true},
+		{name: "no exec", opts: Options{Formats: []string{FormatGIF, FormatPDF}, NoExec: true}},
+		{name: "no exec and video", opts: Options{Formats: []string{FormatGIF, FormatWebP}, NoExec: true}, wantErr: true},
+		{name: "negative exec timeout", opts: Options{Format: FormatWebM, ExecTimeout: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateFormat(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("validateFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	tests := []struct {
+		outputFile string
+		want       string
+	}{
+		{outputFile: "demo.gif", want: FormatGIF},
+		{outputFile: "demo.mp4", want: FormatMP4},
+		{outputFile: "out/Demo.WebM", want: FormatWebM},
+		{outputFile: "scan.tif", want: FormatTIFF},
+		{outputFile: "book.pdf", want: FormatPDF},
+		{outputFile: "demo.mov", want: ""},
+		{outputFile: "demo", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outputFile, func(t *testing.T) {
+			if got := FormatForPath(tt.outputFile); got != tt.want {
+				t.Errorf("FormatForPath(%q) = %q, want %q", tt.outputFile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertFormats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	fakeFFmpeg(t, tempDir)
+
+	var frames []Frame
+	for i := 0; i < 3; i++ {
+		frames = append(frames, Frame{Image: image.NewRGBA(image.Rect(0, 0, 30, 20)), Delay: 100 * time.Millisecond})
+	}
+	outputFile := filepath.Join(tempDir, "demo.gif")
+	report, err := ConvertFrames(frames, outputFile, Options{Formats: []string{FormatGIF, FormatWebP, FormatMP4}})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if len(report.Outputs) != 3 {
+		t.Fatalf("got %d outputs, want 3", len(report.Outputs))
+	}
+
+	wantArgs := map[string]string{FormatWebP: "libwebp_anim", FormatMP4: "libx264"}
+	for i, format := range []string{FormatGIF, FormatWebP, FormatMP4} {
+		output := report.Outputs[i]
+		if output.Format != format || filepath.Base(output.File) != "demo."+format {
+			t.Errorf("output %d = %s in %s, want demo.%s", i, output.File, output.Format, format)
+		}
+		if _, err := os.Stat(output.File); err != nil {
+			t.Errorf("output %s missing: %v", output.File, err)
+		}
+		if want, ok := wantArgs[format]; ok {
+			args, err := os.ReadFile(output.File + ".args")
+			if err != nil {
+				t.Fatalf("Failed to read ffmpeg arguments: %v", err)
+			}
+			if !strings.Contains(string(args), want) || !strings.Contains(string(args), "-f "+format) {
+				t.Errorf("ffmpeg arguments %q lack %q", args, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultVideoFPS is the rate frames are extracted from video inputs at
+	// when Options.VideoFPS is 0
+	DefaultVideoFPS = 10
+	// MaxVideoFPS is the highest rate frames are extracted from video inputs
+	// at, GIF delays being 100ths of a second and players slowing down the
+	// frames shown for less than 2 of them
+	MaxVideoFPS = 50
+)
+
+// videoFPS returns the rate frames are extracted from video inputs at
+func videoFPS(opts Options) float64 {
+	if opts.VideoFPS > 0 {
+		return opts.VideoFPS
+	}
+	return DefaultVideoFPS
+}
+
+// validateVideoFPS checks the video input frame rate of opts
+func validateVideoFPS(opts Options) error {
+	if opts.VideoFPS < 0 || opts.VideoFPS > MaxVideoFPS {
+		return fmt.Errorf("video FPS must be between 0 and %d", MaxVideoFPS)
+	}
+	return nil
+}
+
+// ConvertVideo extracts the frames of a video file, such as an MP4, WebM or
+// MOV, through ffmpeg (see FFmpegEnv) at Options.VideoFPS frames per second,
+// and converts them like Convert. The frames are written as PNGs to a
+// temporary directory under Options.TmpDir and read from there, so long
+// videos are not held in memory. Options.End stops the extraction, the
+// frames played later being left out.
+func ConvertVideo(inputFile, outputFile string, opts Options) (*Report, error) {
+	if err := validateVideoFPS(opts); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(inputFile); err != nil {
+		return nil, fmt.Errorf("error opening video: %v", err)
+	}
+
+	dir, err := makeTempDir(opts, "go-togif-video-*")
+	if err != nil {
+		return nil, err
+	}
+	defer removeTempDir(dir)
+
+	files, err := extractVideoFrames(inputFile, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every frame is shown for one period of the extraction rate
+	delay := time.Duration(float64(time.Second) / videoFPS(opts))
+	opts.FrameDelays = make([]time.Duration, len(files))
+	for i := range opts.FrameDelays {
+		opts.FrameDelays[i] = delay
+	}
+	opts.IgnoreSidecars = true
+
+	src, err := inputSource(files, opts)
+	if err != nil {
+		return nil, err
+	}
+	return run(src, outputFile, opts)
+}
+
+// extractVideoFrames writes the frames of inputFile to PNG files in dir with
+// ffmpeg and returns their paths, in order
+func extractVideoFrames(inputFile, dir string, opts Options) ([]string, error) {
+	args := []string{"-y", "-loglevel", "error"}
+	if opts.End > 0 {
+		args = append(args, "-t", strconv.FormatFloat(opts.End.Seconds(), 'f', -1, 64))
+	}
+	// A path starting with a dash would read as an option
+	if strings.HasPrefix(inputFile, "-") {
+		inputFile = "." + string(filepath.Separator) + inputFile
+	}
+	args = append(args,
+		"-i", inputFile,
+		"-an",
+		"-vf", "fps="+strconv.FormatFloat(videoFPS(opts), 'f', -1, 64),
+		"-f", "image2", filepath.Join(dir, "frame%06d.png"))
+	if err := runner(opts).Run(ffmpeg, args, nil); err != nil {
+		return nil, fmt.Errorf("error extracting video frames: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading video frames: %v", err)
+	}
+	// The names are numbered with leading zeros, ReadDir sorts them in order
+	var files []string
+	for _, entry := range entries {
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("video %s has no frames", inputFile)
+	}
+	if err := opts.InputLimits.checkFrameCount(len(files)); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"image/gif"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFFmpegFrames makes ffmpeg a script recording its arguments to
+// dir/args and copying the PNG files of frames to the directory of the
+// output pattern, as extracted frames
+func fakeFFmpegFrames(t *testing.T, dir, frames string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake ffmpeg is a shell script")
+	}
+	script := filepath.Join(dir, "ffmpeg")
+	content := "#!/bin/sh\n[ \"$1\" = -version ] && echo \"ffmpeg version 6.1\" && exit\nfor last; do :; done\necho \"$@\" > \"" + filepath.Join(dir, "args") + "\"\n" +
+		"n=0\nfor f in \"" + frames + "\"/*.png; do\n  [ -e \"$f\" ] || exit 0\n  n=$((n+1))\n  cp \"$f\" \"$(dirname \"$last\")/$(printf frame%06d.png $n)\"\ndone\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+	t.Setenv(FFmpegEnv, script)
+}
+
+func TestConvertVideo(t *testing.T) {
+	tests := []struct {
+		name       string
+		frames     int
+		opts       Options
+		wantFrames int
+		wantDelay  int
+		wantArgs   []string
+		wantErr    string
+	}{
+		{name: "default rate", frames: 3, wantFrames: 3, wantDelay: 10, wantArgs: []string{"-an", "-vf fps=10"}},
+		{name: "custom rate", frames: 2, opts: Options{VideoFPS: 25}, wantFrames: 2, wantDelay: 4, wantArgs: []string{"-vf fps=25"}},
+		{name: "end", frames: 2, opts: Options{End: 1500 * time.Millisecond}, wantFrames: 2, wantDelay: 10, wantArgs: []string{"-t 1.5 -i"}},
+		{name: "no frames", frames: 0, wantErr: "has no frames"},
+		{name: "too many frames", frames: 3, opts: Options{InputLimits: InputLimits{MaxFrames: 2}}, wantErr: "frames"},
+		{name: "invalid rate", frames: 1, opts: Options{VideoFPS: 60}, wantErr: "video FPS must be between 0 and 50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tempDir)
+
+			framesDir := filepath.Join(tempDir, "frames")
+			if err := os.Mkdir(framesDir, 0755); err != nil {
+				t.Fatalf("Failed to create frames dir: %v", err)
+			}
+			writeNoiseFrames(t, framesDir, tt.frames, 16, 12)
+			fakeFFmpegFrames(t, tempDir, framesDir)
+
+			input := filepath.Join(tempDir, "recording.mp4")
+			if err := os.WriteFile(input, []byte("not decoded by the fake"), 0644); err != nil {
+				t.Fatalf("Failed to write video: %v", err)
+			}
+			output := filepath.Join(tempDir, "output.gif")
+			tt.opts.TmpDir = tempDir
+
+			report, err := ConvertVideo(input, output, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ConvertVideo() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConvertVideo() error = %v", err)
+			}
+			if report.Frames != tt.wantFrames {
+				t.Errorf("Frames = %d, want %d", report.Frames, tt.wantFrames)
+			}
+
+			file, err := os.Open(output)
+			if err != nil {
+				t.Fatalf("Failed to open output: %v", err)
+			}
+			defer file.Close()
+			g, err := gif.DecodeAll(file)
+			if err != nil {
+				t.Fatalf("Failed to decode output: %v", err)
+			}
+			for i, delay := range g.Delay {
+				if delay != tt.wantDelay {
+					t.Errorf("frame %d delay = %d, want %d", i, delay, tt.wantDelay)
+				}
+			}
+
+			args, err := os.ReadFile(filepath.Join(tempDir, "args"))
+			if err != nil {
+				t.Fatalf("Failed to read ffmpeg arguments: %v", err)
+			}
+			for _, want := range tt.wantArgs {
+				if !strings.Contains(string(args), want) {
+					t.Errorf("ffmpeg arguments %q do not contain %q", args, want)
+				}
+			}
+
+			// The extracted frames are removed
+			entries, err := filepath.Glob(filepath.Join(tempDir, "go-togif-video-*"))
+			if err != nil || len(entries) > 0 {
+				t.Errorf("extracted frames left in %v", entries)
+			}
+		})
+	}
+}
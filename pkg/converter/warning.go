@@ -0,0 +1,88 @@
+package converter
+
+import "fmt"
+
+// WarningKind identifies the problem a Warning reports, so programs can react
+// to it without parsing its message
+type WarningKind string
+
+// Warning kinds
+const (
+	// WarningResizedFrame reports frames of another size than the first one,
+	// scaled to its size
+	WarningResizedFrame WarningKind = "resized-frame"
+	// WarningSkippedFrame reports input frames left out of the output to fit
+	// Options.MaxFrames or Options.MaxDuration
+	WarningSkippedFrame WarningKind = "skipped-frame"
+	// WarningPaletteTruncated reports inputs with far more colors than the
+	// palette keeps, likely to show banding
+	WarningPaletteTruncated WarningKind = "palette-truncated"
+	// WarningLowContrast reports subtitles below the WCAG contrast threshold
+	WarningLowContrast WarningKind = "low-contrast"
+	// WarningPosterFrame reports a poster frame picked by Options.AutoPoster
+	WarningPosterFrame WarningKind = "poster-frame"
+	// WarningGamutClipped reports pixels outside of the Options.Proof gamut
+	WarningGamutClipped WarningKind = "gamut-clipped"
+	// WarningNoDelays reports frame delays left out of the GIF with its
+	// control extension
+	WarningNoDelays WarningKind = "no-delays"
+	// WarningPresetReduced reports colors and frames reduced to fit the file
+	// size limit of Options.Preset
+	WarningPresetReduced WarningKind = "preset-reduced"
+	// WarningOverLimit reports an output still above the file size limit of
+	// Options.Preset at the lowest quality
+	WarningOverLimit WarningKind = "over-limit"
+	// WarningCompat reports a feature of a GIF output known to break in a
+	// renderer, found with Options.CompatReport
+	WarningCompat WarningKind = "compat"
+	// WarningNoCycle reports inputs in which Options.FindLoop found no
+	// repeating cycle, every frame being kept
+	WarningNoCycle WarningKind = "no-cycle"
+	// WarningIneffectiveOption reports an option ignored because of the other
+	// ones (see ValidateOptions)
+	WarningIneffectiveOption WarningKind = "ineffective-option"
+)
+
+// Warning is a problem found during a conversion which did not stop it
+type Warning struct {
+	Kind    WarningKind `json:"kind"`
+	Message string      `json:"message"`
+	// Frames lists the frames the warning is about, by their 0-based index in
+	// the inputs, when it is about some frames only
+	Frames []int `json:"frames,omitempty"`
+}
+
+// String returns the message of the warning
+func (w Warning) String() string {
+	return w.Message
+}
+
+// warn adds a warning of the given kind about frames to the report
+func (r *Report) warn(kind WarningKind, frames []int, format string, args ...any) {
+	r.Warnings = append(r.Warnings, Warning{Kind: kind, Message: fmt.Sprintf(format, args...), Frames: frames})
+}
+
+// HasWarning reports whether the report holds a warning of the given kind
+func (r *Report) HasWarning(kind WarningKind) bool {
+	for _, warning := range r.Warnings {
+		if warning.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// droppedFrames returns the frames of [first, last) missing from the sorted
+// kept indices, mapped to their index in the inputs
+func droppedFrames(inputs []int, first, last int, kept []int) []int {
+	var dropped []int
+	k := 0
+	for i := first; i < last; i++ {
+		if k < len(kept) && kept[k] == i {
+			k++
+			continue
+		}
+		dropped = append(dropped, inputs[i])
+	}
+	return dropped
+}
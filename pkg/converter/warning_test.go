@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDroppedFrames(t *testing.T) {
+	inputs := []int{0, 2, 4, 6, 8, 10}
+	tests := []struct {
+		name        string
+		first, last int
+		kept        []int
+		want        []int
+	}{
+		{name: "every other", first: 0, last: 6, kept: []int{0, 2, 4}, want: []int{2, 6, 10}},
+		{name: "window", first: 1, last: 4, kept: []int{1, 3}, want: []int{4}},
+		{name: "none dropped", first: 0, last: 3, kept: []int{0, 1, 2}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := droppedFrames(inputs, tt.first, tt.last, tt.kept); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("droppedFrames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertWarnings(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// frames returns n solid frames of 16x16 pixels, shown for 100ms, except
+	// for the given frames of 8x8 pixels
+	frames := func(n int, small ...int) []Frame {
+		var frames []Frame
+		for i := 0; i < n; i++ {
+			size := 16
+			for _, s := range small {
+				if s == i {
+					size = 8
+				}
+			}
+			img := image.NewRGBA(image.Rect(0, 0, size, size))
+			for p := range img.Pix {
+				img.Pix[p] = uint8(i * 20)
+			}
+			frames = append(frames, Frame{Image: img, Delay: 100 * time.Millisecond})
+		}
+		return frames
+	}
+
+	tests := []struct {
+		name       string
+		frames     []Frame
+		opts       Options
+		kind       WarningKind
+		wantFrames []int
+	}{
+		{name: "resized frames", frames: frames(4, 1, 3), kind: WarningResizedFrame, wantFrames: []int{1, 3}},
+		{name: "resized frame after trimming", frames: frames(4, 3), opts: Options{Start: 200 * time.Millisecond}, kind: WarningResizedFrame, wantFrames: []int{3}},
+		{name: "max frames", frames: frames(6), opts: Options{MaxFrames: 3}, kind: WarningSkippedFrame, wantFrames: []int{1, 3, 5}},
+		{name: "max duration subsampled", frames: frames(6), opts: Options{MaxDuration: 300 * time.Millisecond}, kind: WarningSkippedFrame, wantFrames: []int{1, 3, 5}},
+		{name: "resized frame after max frames", frames: frames(6, 4), opts: Options{MaxFrames: 3}, kind: WarningResizedFrame, wantFrames: []int{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ConvertFrames(tt.frames, filepath.Join(tempDir, "out.gif"), tt.opts)
+			if err != nil {
+				t.Fatalf("ConvertFrames() error = %v", err)
+			}
+			if !report.HasWarning(tt.kind) {
+				t.Fatalf("warnings = %v, want a %s warning", report.Warnings, tt.kind)
+			}
+			for _, warning := range report.Warnings {
+				if warning.Kind == tt.kind && !reflect.DeepEqual(warning.Frames, tt.wantFrames) {
+					t.Errorf("%s warning frames = %v, want %v", tt.kind, warning.Frames, tt.wantFrames)
+				}
+				if warning.String() == "" {
+					t.Errorf("%s warning has no message", warning.Kind)
+				}
+			}
+		})
+	}
+
+	// Frames of the same size convert without warnings
+	report, err := ConvertFrames(frames(3), filepath.Join(tempDir, "out.gif"), Options{})
+	if err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", report.Warnings)
+	}
+}
@@ -0,0 +1,20 @@
+package decode
+
+import (
+	"image"
+	"io"
+
+	"golang.org/x/image/bmp"
+)
+
+// BMP decodes a BMP image from r within limits, reading its header first
+// like PNG
+func BMP(r io.Reader, limits Limits) (image.Image, error) {
+	return decodeImage(r, limits, bmp.DecodeConfig, bmp.Decode)
+}
+
+// BMPConfig returns the dimensions of the BMP image of r, checked against
+// limits, reading only its header
+func BMPConfig(r io.Reader, limits Limits) (image.Config, error) {
+	return decodeConfig(r, limits, bmp.DecodeConfig)
+}
@@ -0,0 +1,100 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+// encodeBMP encodes a width x height image
+func encodeBMP(t testing.TB, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	if err := bmp.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode BMP: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withBMPSize returns the BMP data declaring a width x height image,
+// without the pixels to match
+func withBMPSize(data []byte, width, height uint32) []byte {
+	data = bytes.Clone(data)
+	// The info header follows the 14 bytes file header, the size after its length
+	binary.LittleEndian.PutUint32(data[18:], width)
+	binary.LittleEndian.PutUint32(data[22:], height)
+	return data
+}
+
+func TestBMP(t *testing.T) {
+	valid := encodeBMP(t, 8, 4)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		limits  Limits
+		wantErr string
+	}{
+		{name: "valid", data: valid, limits: DefaultLimits},
+		{name: "no limits", data: valid, limits: Limits{}},
+		{name: "too high", data: valid, limits: Limits{MaxHeight: 2}, wantErr: "4 pixels high, above the limit of 2"},
+		{name: "too many pixels", data: valid, limits: Limits{MaxPixels: 16}, wantErr: "32 pixels, above the limit of 16"},
+		// The header is checked before allocating 4 GiB of pixels
+		{name: "huge declared size", data: withBMPSize(valid, 1<<15, 1<<15), limits: DefaultLimits, wantErr: "32768 pixels wide"},
+		{name: "truncated", data: valid[:len(valid)-20], limits: DefaultLimits, wantErr: "EOF"},
+		{name: "not a BMP", data: []byte("GIF89a\x08\x00\x04\x00"), limits: DefaultLimits, wantErr: "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := BMP(bytes.NewReader(tt.data), tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("BMP() error = %v", err)
+				}
+				if img.Bounds() != image.Rect(0, 0, 8, 4) {
+					t.Errorf("BMP() bounds = %v, want 8x4", img.Bounds())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("BMP() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBMPConfig(t *testing.T) {
+	data := withBMPSize(encodeBMP(t, 2, 2), 1<<15, 10)
+	if _, err := BMPConfig(bytes.NewReader(data), Limits{}); err != nil {
+		t.Errorf("BMPConfig() without limits error = %v", err)
+	}
+	_, err := BMPConfig(bytes.NewReader(data), DefaultLimits)
+	limit, ok := err.(*LimitError)
+	if !ok || limit.Limit != LimitWidth || limit.Value != 1<<15 {
+		t.Errorf("BMPConfig() error = %#v, want a width LimitError", err)
+	}
+}
+
+func FuzzBMP(f *testing.F) {
+	f.Add(encodeBMP(f, 8, 4))
+	f.Add(withBMPSize(encodeBMP(f, 2, 2), 1<<20, 1))
+	limits := Limits{MaxWidth: 1024, MaxHeight: 1024, MaxPixels: 1 << 18, MaxBytes: 1 << 20}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := BMP(bytes.NewReader(data), limits)
+		if err != nil {
+			return
+		}
+		b := img.Bounds()
+		if b.Dx() > limits.MaxWidth || b.Dy() > limits.MaxHeight || int64(b.Dx()*b.Dy()) > limits.MaxPixels {
+			t.Fatalf("BMP() decoded %v, beyond the limits", b)
+		}
+	})
+}
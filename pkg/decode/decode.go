@@ -0,0 +1,197 @@
+// Package decode decodes untrusted PNG, WebP (still or animated), BMP, TIFF and GIF data, checking the dimensions
+// they declare before allocating their pixels and bounding the decoding time
+package decode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"time"
+)
+
+// Limits of LimitError
+const (
+	LimitWidth   = "width"
+	LimitHeight  = "height"
+	LimitPixels  = "pixels"
+	LimitBytes   = "bytes"
+	LimitTimeout = "timeout"
+	LimitFrames  = "frames"
+)
+
+// limitUnits describe the values of each limit in error messages
+var limitUnits = map[string]string{
+	LimitWidth:  "pixels wide",
+	LimitHeight: "pixels high",
+	LimitPixels: "pixels",
+	LimitBytes:  "bytes",
+	LimitFrames: "frames",
+}
+
+// LimitError reports an input beyond a limit, so that servers can tell
+// rejected inputs from failures with errors.As
+type LimitError struct {
+	// Input is the file beyond the limit, empty for limits of all the inputs
+	Input string
+	// Limit is one of LimitWidth, LimitHeight, LimitPixels, LimitBytes,
+	// LimitTimeout or LimitFrames
+	Limit string
+	// Value is the size of the input, 0 when only known to be above Max
+	Value int64
+	// Max is the limit, in nanoseconds for LimitTimeout
+	Max int64
+}
+
+// Error implements error
+func (e *LimitError) Error() string {
+	var msg string
+	switch {
+	case e.Limit == LimitTimeout:
+		msg = fmt.Sprintf("decoding took longer than %v", time.Duration(e.Max))
+	case e.Value == 0:
+		msg = fmt.Sprintf("more than %d %s", e.Max, limitUnits[e.Limit])
+	default:
+		msg = fmt.Sprintf("%d %s, above the limit of %d", e.Value, limitUnits[e.Limit], e.Max)
+	}
+	if e.Input != "" {
+		return e.Input + ": " + msg
+	}
+	return msg
+}
+
+// Limits bounds the resources spent decoding a file, zero fields are unlimited
+type Limits struct {
+	// MaxWidth and MaxHeight bound the size of an image, or of the logical
+	// screen of a GIF
+	MaxWidth  int
+	MaxHeight int
+	// MaxPixels bounds the pixels decoded from a file, each frame of a GIF
+	// counting as its whole logical screen, as players composite it
+	MaxPixels int64
+	// MaxBytes bounds the data read from a file
+	MaxBytes int64
+	// Timeout bounds the time spent reading and decoding a file
+	Timeout time.Duration
+}
+
+// DefaultLimits are generous for screen captures and animations while keeping
+// a crafted file from allocating gigabytes or decoding for minutes
+var DefaultLimits = Limits{
+	MaxWidth:  16384,
+	MaxHeight: 16384,
+	MaxPixels: 1 << 28,
+	MaxBytes:  1 << 30,
+	Timeout:   time.Minute,
+}
+
+// checkSize checks the declared size of an image against the limits
+func (l Limits) checkSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid dimensions %dx%d", width, height)
+	}
+	if l.MaxWidth > 0 && width > l.MaxWidth {
+		return &LimitError{Limit: LimitWidth, Value: int64(width), Max: int64(l.MaxWidth)}
+	}
+	if l.MaxHeight > 0 && height > l.MaxHeight {
+		return &LimitError{Limit: LimitHeight, Value: int64(height), Max: int64(l.MaxHeight)}
+	}
+	return nil
+}
+
+// checkPixels checks a pixel count against the limits
+func (l Limits) checkPixels(pixels int64) error {
+	if l.MaxPixels > 0 && pixels > l.MaxPixels {
+		return &LimitError{Limit: LimitPixels, Value: pixels, Max: l.MaxPixels}
+	}
+	return nil
+}
+
+// limitedReader fails reads past the byte and time limits, so decoders stop
+// at their next read
+type limitedReader struct {
+	r        io.Reader
+	limits   Limits
+	read     int64
+	deadline time.Time
+}
+
+// newLimitedReader returns r bounded by limits, the timeout starting now
+func newLimitedReader(r io.Reader, limits Limits) *limitedReader {
+	lr := &limitedReader{r: r, limits: limits}
+	if limits.Timeout > 0 {
+		lr.deadline = time.Now().Add(limits.Timeout)
+	}
+	return lr
+}
+
+// Read implements io.Reader
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if err := lr.checkTime(); err != nil {
+		return 0, err
+	}
+	if lr.limits.MaxBytes > 0 {
+		if lr.read >= lr.limits.MaxBytes {
+			// Data left past the limit is an error, the end of data is not
+			var probe [1]byte
+			if n, _ := lr.r.Read(probe[:]); n > 0 {
+				return 0, &LimitError{Limit: LimitBytes, Max: lr.limits.MaxBytes}
+			}
+			return 0, io.EOF
+		}
+		p = p[:min(int64(len(p)), lr.limits.MaxBytes-lr.read)]
+	}
+	n, err := lr.r.Read(p)
+	lr.read += int64(n)
+	return n, err
+}
+
+// checkTime fails once the timeout has passed
+func (lr *limitedReader) checkTime() error {
+	if !lr.deadline.IsZero() && time.Now().After(lr.deadline) {
+		return &LimitError{Limit: LimitTimeout, Max: int64(lr.limits.Timeout)}
+	}
+	return nil
+}
+
+// decodeImage decodes an image from r within limits with the decoders of its
+// format, reading its header first so oversized images are rejected before
+// their pixels are allocated
+func decodeImage(r io.Reader, limits Limits, config func(io.Reader) (image.Config, error), decode func(io.Reader) (image.Image, error)) (image.Image, error) {
+	lr := newLimitedReader(r, limits)
+
+	// Keep the header to decode it again along with the pixels
+	var header bytes.Buffer
+	cfg, err := config(io.TeeReader(lr, &header))
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.checkSize(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+	if err := limits.checkPixels(int64(cfg.Width) * int64(cfg.Height)); err != nil {
+		return nil, err
+	}
+
+	img, err := decode(io.MultiReader(&header, lr))
+	if err != nil {
+		return nil, err
+	}
+	if err := lr.checkTime(); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// decodeConfig returns the dimensions of the image of r, checked against
+// limits, reading only its header with the config decoder of its format
+func decodeConfig(r io.Reader, limits Limits, config func(io.Reader) (image.Config, error)) (image.Config, error) {
+	cfg, err := config(newLimitedReader(r, limits))
+	if err != nil {
+		return cfg, err
+	}
+	if err := limits.checkSize(cfg.Width, cfg.Height); err != nil {
+		return cfg, err
+	}
+	return cfg, limits.checkPixels(int64(cfg.Width) * int64(cfg.Height))
+}
@@ -0,0 +1,97 @@
+package decode
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader returns its data a byte at a time, sleeping before each read
+type slowReader struct {
+	data  []byte
+	sleep time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.sleep)
+	p[0], r.data = r.data[0], r.data[1:]
+	return 1, nil
+}
+
+func TestLimitedReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       io.Reader
+		limits  Limits
+		wantErr string
+	}{
+		{name: "unlimited", r: strings.NewReader("0123456789"), limits: Limits{}},
+		{name: "exactly the limit", r: strings.NewReader("0123456789"), limits: Limits{MaxBytes: 10}},
+		{name: "over the limit", r: strings.NewReader("0123456789"), limits: Limits{MaxBytes: 9}, wantErr: "more than 9 bytes"},
+		{name: "timeout", r: &slowReader{data: []byte("0123456789"), sleep: 5 * time.Millisecond}, limits: Limits{Timeout: 10 * time.Millisecond}, wantErr: "longer than 10ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := io.ReadAll(newLimitedReader(tt.r, tt.limits))
+			if tt.wantErr == "" {
+				if err != nil || string(data) != "0123456789" {
+					t.Errorf("ReadAll() = %q, %v, want all the data", data, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadAll() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSize(t *testing.T) {
+	limits := Limits{MaxWidth: 100, MaxHeight: 50}
+	tests := []struct {
+		width, height int
+		wantErr       bool
+	}{
+		{100, 50, false},
+		{101, 50, true},
+		{100, 51, true},
+		{0, 10, true},
+		{-1, 10, true},
+	}
+
+	for _, tt := range tests {
+		if err := limits.checkSize(tt.width, tt.height); (err != nil) != tt.wantErr {
+			t.Errorf("checkSize(%d, %d) error = %v, wantErr %v", tt.width, tt.height, err, tt.wantErr)
+		}
+	}
+	if err := (Limits{}).checkSize(1<<20, 1<<20); err != nil {
+		t.Errorf("checkSize() without limits error = %v", err)
+	}
+	if err := (Limits{MaxPixels: 10}).checkPixels(bytes.MinRead); err == nil {
+		t.Errorf("checkPixels() over the limit succeeded")
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	tests := []struct {
+		err  *LimitError
+		want string
+	}{
+		{&LimitError{Input: "a.png", Limit: LimitWidth, Value: 20000, Max: 16384}, "a.png: 20000 pixels wide, above the limit of 16384"},
+		{&LimitError{Limit: LimitFrames, Value: 12, Max: 10}, "12 frames, above the limit of 10"},
+		{&LimitError{Input: "a.gif", Limit: LimitBytes, Max: 1024}, "a.gif: more than 1024 bytes"},
+		{&LimitError{Limit: LimitTimeout, Max: int64(time.Second)}, "decoding took longer than 1s"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/gif"
+	"io"
+)
+
+// GIF block introducers
+const (
+	gifExtension  = 0x21
+	gifImage      = 0x2c
+	gifColorTable = 0x80
+)
+
+// GIF decodes all the frames of a GIF from r within limits, scanning its
+// blocks first so oversized screens and frame counts are rejected before
+// their pixels are allocated
+func GIF(r io.Reader, limits Limits) (*gif.GIF, error) {
+	lr := newLimitedReader(r, limits)
+	data, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height, frames, err := scanGIF(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.checkSize(width, height); err != nil {
+		return nil, err
+	}
+	if err := limits.checkPixels(int64(frames) * int64(width) * int64(height)); err != nil {
+		return nil, err
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := lr.checkTime(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// scanGIF returns the logical screen size and the number of frames of the GIF
+// data, reading only the block headers. Malformed data past the header is
+// left for the decoder to report.
+func scanGIF(data []byte) (width, height, frames int, err error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return 0, 0, 0, fmt.Errorf("gif: can't recognize format")
+	}
+	width = int(binary.LittleEndian.Uint16(data[6:8]))
+	height = int(binary.LittleEndian.Uint16(data[8:10]))
+	pos := 13 + colorTableSize(data[10])
+
+	for pos < len(data) {
+		switch data[pos] {
+		case gifExtension:
+			// Skip the label, then the sub-blocks
+			pos = skipSubBlocks(data, pos+2)
+		case gifImage:
+			if pos+10 > len(data) {
+				return width, height, frames, nil
+			}
+			frames++
+			// Skip the descriptor, the local color table and the LZW code size
+			pos = skipSubBlocks(data, pos+10+colorTableSize(data[pos+9])+1)
+		default:
+			// The trailer, or data the decoder rejects
+			return width, height, frames, nil
+		}
+	}
+	return width, height, frames, nil
+}
+
+// colorTableSize returns the size of the color table flagged by packed
+func colorTableSize(packed byte) int {
+	if packed&gifColorTable == 0 {
+		return 0
+	}
+	return 3 << (packed&7 + 1)
+}
+
+// skipSubBlocks returns the position after the sub-blocks starting at pos
+func skipSubBlocks(data []byte, pos int) int {
+	for pos < len(data) {
+		size := int(data[pos])
+		pos += 1 + size
+		if size == 0 {
+			break
+		}
+	}
+	return pos
+}
+
+// GIFFrameSizes returns the number of bytes each frame of the GIF data takes,
+// from its image descriptor to the end of its compressed pixels, without
+// decoding them
+func GIFFrameSizes(data []byte) ([]int, error) {
+	if _, _, _, err := scanGIF(data); err != nil {
+		return nil, err
+	}
+	var sizes []int
+	pos := 13 + colorTableSize(data[10])
+	for pos < len(data) {
+		switch data[pos] {
+		case gifExtension:
+			pos = skipSubBlocks(data, pos+2)
+		case gifImage:
+			if pos+10 > len(data) {
+				return nil, fmt.Errorf("gif: truncated image descriptor")
+			}
+			end := skipSubBlocks(data, pos+10+colorTableSize(data[pos+9])+1)
+			if end > len(data) {
+				return nil, fmt.Errorf("gif: truncated image data")
+			}
+			sizes = append(sizes, end-pos)
+			pos = end
+		default:
+			return sizes, nil
+		}
+	}
+	return sizes, nil
+}
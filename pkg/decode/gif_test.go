@@ -0,0 +1,149 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"strings"
+	"testing"
+)
+
+// encodeGIF encodes frames frames of width x height
+func encodeGIF(t testing.TB, width, height, frames int) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+		img.Pix[i%len(img.Pix)] = 1
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withScreen returns the GIF data declaring a width x height logical screen
+func withScreen(data []byte, width, height uint16) []byte {
+	data = bytes.Clone(data)
+	binary.LittleEndian.PutUint16(data[6:], width)
+	binary.LittleEndian.PutUint16(data[8:], height)
+	return data
+}
+
+func TestScanGIF(t *testing.T) {
+	tests := []struct {
+		name                        string
+		data                        []byte
+		wantWidth, wantHeight, want int
+		wantErr                     bool
+	}{
+		{name: "single frame", data: encodeGIF(t, 8, 4, 1), wantWidth: 8, wantHeight: 4, want: 1},
+		{name: "frames", data: encodeGIF(t, 3, 3, 5), wantWidth: 3, wantHeight: 3, want: 5},
+		{name: "truncated", data: encodeGIF(t, 3, 3, 5)[:60], wantWidth: 3, wantHeight: 3, want: 1},
+		{name: "not a GIF", data: []byte("\x89PNG\r\n\x1a\n00000"), wantErr: true},
+		{name: "empty", data: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, frames, err := scanGIF(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scanGIF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if width != tt.wantWidth || height != tt.wantHeight || frames != tt.want {
+				t.Errorf("scanGIF() = %dx%d, %d frames, want %dx%d, %d frames", width, height, frames, tt.wantWidth, tt.wantHeight, tt.want)
+			}
+		})
+	}
+}
+
+func TestGIFFrameSizes(t *testing.T) {
+	data := encodeGIF(t, 16, 16, 4)
+	sizes, err := GIFFrameSizes(data)
+	if err != nil {
+		t.Fatalf("GIFFrameSizes() error = %v", err)
+	}
+	if len(sizes) != 4 {
+		t.Fatalf("GIFFrameSizes() = %v, want 4 frames", sizes)
+	}
+	// The frames, their extensions and the header and trailer make the file
+	total := 0
+	for _, size := range sizes {
+		// A descriptor, a code size and at least an empty sub-block
+		if size < 12 {
+			t.Errorf("frame size %d, want at least 12 bytes", size)
+		}
+		total += size
+	}
+	if total >= len(data) {
+		t.Errorf("frames take %d bytes, want less than the %d bytes of the file", total, len(data))
+	}
+
+	if _, err := GIFFrameSizes(data[:len(data)-10]); err == nil {
+		t.Errorf("GIFFrameSizes() of truncated data error = nil, want an error")
+	}
+	if _, err := GIFFrameSizes([]byte("not a GIF")); err == nil {
+		t.Errorf("GIFFrameSizes() of other data error = nil, want an error")
+	}
+}
+
+func TestGIF(t *testing.T) {
+	valid := encodeGIF(t, 8, 4, 3)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		limits  Limits
+		wantErr string
+	}{
+		{name: "valid", data: valid, limits: DefaultLimits},
+		{name: "no limits", data: valid, limits: Limits{}},
+		{name: "too tall", data: valid, limits: Limits{MaxHeight: 2}, wantErr: "4 pixels high"},
+		// 3 frames of the whole screen
+		{name: "too many pixels", data: valid, limits: Limits{MaxPixels: 95}, wantErr: "96 pixels, above the limit of 95"},
+		{name: "too many bytes", data: valid, limits: Limits{MaxBytes: 30}, wantErr: "more than 30 bytes"},
+		{name: "huge declared screen", data: withScreen(valid, 65535, 65535), limits: DefaultLimits, wantErr: "65535 pixels wide"},
+		{name: "empty screen", data: withScreen(valid, 0, 4), limits: DefaultLimits, wantErr: "invalid dimensions"},
+		{name: "not a GIF", data: []byte("GIF"), limits: DefaultLimits, wantErr: "can't recognize"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := GIF(bytes.NewReader(tt.data), tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GIF() error = %v", err)
+				}
+				if len(g.Image) != 3 {
+					t.Errorf("GIF() got %d frames, want 3", len(g.Image))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GIF() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func FuzzGIF(f *testing.F) {
+	f.Add(encodeGIF(f, 8, 4, 3))
+	f.Add(withScreen(encodeGIF(f, 2, 2, 1), 65535, 1))
+	limits := Limits{MaxWidth: 1024, MaxHeight: 1024, MaxPixels: 1 << 18, MaxBytes: 1 << 20}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, err := GIF(bytes.NewReader(data), limits)
+		if err != nil {
+			return
+		}
+		pixels := int64(len(g.Image)) * int64(g.Config.Width) * int64(g.Config.Height)
+		if g.Config.Width > limits.MaxWidth || g.Config.Height > limits.MaxHeight || pixels > limits.MaxPixels {
+			t.Fatalf("GIF() decoded %d frames of %dx%d, beyond the limits", len(g.Image), g.Config.Width, g.Config.Height)
+		}
+	})
+}
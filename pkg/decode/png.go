@@ -0,0 +1,19 @@
+package decode
+
+import (
+	"image"
+	"image/png"
+	"io"
+)
+
+// PNG decodes a PNG image from r within limits, reading its header first so
+// oversized images are rejected before their pixels are allocated
+func PNG(r io.Reader, limits Limits) (image.Image, error) {
+	return decodeImage(r, limits, png.DecodeConfig, png.Decode)
+}
+
+// PNGConfig returns the dimensions of the PNG image of r, checked against
+// limits, reading only its header
+func PNGConfig(r io.Reader, limits Limits) (image.Config, error) {
+	return decodeConfig(r, limits, png.DecodeConfig)
+}
@@ -0,0 +1,103 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// encodePNG encodes a width x height image
+func encodePNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withSize returns the PNG data declaring a width x height image, without
+// the pixels to match
+func withSize(data []byte, width, height uint32) []byte {
+	data = bytes.Clone(data)
+	// The IHDR chunk follows the 8 bytes signature, its data after its length and type
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestPNG(t *testing.T) {
+	valid := encodePNG(t, 8, 4)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		limits  Limits
+		wantErr string
+	}{
+		{name: "valid", data: valid, limits: DefaultLimits},
+		{name: "no limits", data: valid, limits: Limits{}},
+		{name: "too wide", data: valid, limits: Limits{MaxWidth: 4}, wantErr: "8 pixels wide, above the limit of 4"},
+		{name: "too many pixels", data: valid, limits: Limits{MaxPixels: 16}, wantErr: "32 pixels, above the limit of 16"},
+		{name: "too many bytes", data: valid, limits: Limits{MaxBytes: 20}, wantErr: "more than 20 bytes"},
+		// The header is checked before allocating 4 GiB of pixels
+		{name: "huge declared size", data: withSize(valid, 1<<15, 1<<15), limits: DefaultLimits, wantErr: "32768 pixels wide"},
+		{name: "huge declared pixels", data: withSize(valid, 4096, 4096), limits: Limits{MaxPixels: 1 << 20}, wantErr: "16777216 pixels"},
+		{name: "truncated", data: valid[:len(valid)-20], limits: DefaultLimits, wantErr: "EOF"},
+		{name: "not a PNG", data: []byte("GIF89a\x08\x00\x04\x00"), limits: DefaultLimits, wantErr: "not a PNG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := PNG(bytes.NewReader(tt.data), tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("PNG() error = %v", err)
+				}
+				if img.Bounds() != image.Rect(0, 0, 8, 4) {
+					t.Errorf("PNG() bounds = %v, want 8x4", img.Bounds())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("PNG() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPNGConfig(t *testing.T) {
+	data := withSize(encodePNG(t, 2, 2), 1<<15, 10)
+	if _, err := PNGConfig(bytes.NewReader(data), Limits{}); err != nil {
+		t.Errorf("PNGConfig() without limits error = %v", err)
+	}
+	_, err := PNGConfig(bytes.NewReader(data), DefaultLimits)
+	limit, ok := err.(*LimitError)
+	if !ok || limit.Limit != LimitWidth || limit.Value != 1<<15 {
+		t.Errorf("PNGConfig() error = %#v, want a width LimitError", err)
+	}
+}
+
+func FuzzPNG(f *testing.F) {
+	f.Add(encodePNG(f, 8, 4))
+	f.Add(withSize(encodePNG(f, 2, 2), 1<<20, 1))
+	limits := Limits{MaxWidth: 1024, MaxHeight: 1024, MaxPixels: 1 << 18, MaxBytes: 1 << 20}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := PNG(bytes.NewReader(data), limits)
+		if err != nil {
+			return
+		}
+		b := img.Bounds()
+		if b.Dx() > limits.MaxWidth || b.Dy() > limits.MaxHeight || int64(b.Dx()*b.Dy()) > limits.MaxPixels {
+			t.Fatalf("PNG() decoded %v, beyond the limits", b)
+		}
+	})
+}
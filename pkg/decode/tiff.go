@@ -0,0 +1,56 @@
+package decode
+
+import (
+	"bytes"
+	"image"
+	"io"
+
+	"golang.org/x/image/tiff"
+)
+
+// TIFF decodes the first image of a TIFF file from r within limits, checking
+// its header first like PNG. The header may follow the pixel data, so the
+// file is read, but not decoded, before the header is checked.
+func TIFF(r io.Reader, limits Limits) (image.Image, error) {
+	lr := newLimitedReader(r, limits)
+	data, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := checkTIFF(data, limits); err != nil {
+		return nil, err
+	}
+
+	img, err := tiff.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := lr.checkTime(); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// TIFFConfig returns the dimensions of the first image of the TIFF file of
+// r, checked against limits
+func TIFFConfig(r io.Reader, limits Limits) (image.Config, error) {
+	data, err := io.ReadAll(newLimitedReader(r, limits))
+	if err != nil {
+		return image.Config{}, err
+	}
+	return checkTIFF(data, limits)
+}
+
+// checkTIFF returns the dimensions of the first image of the TIFF data,
+// checked against limits. The decoders are given the whole file: reading
+// from a plain io.Reader, they allocate up to any offset the file declares.
+func checkTIFF(data []byte, limits Limits) (image.Config, error) {
+	cfg, err := tiff.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return cfg, err
+	}
+	if err := limits.checkSize(cfg.Width, cfg.Height); err != nil {
+		return cfg, err
+	}
+	return cfg, limits.checkPixels(int64(cfg.Width) * int64(cfg.Height))
+}
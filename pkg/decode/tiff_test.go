@@ -0,0 +1,97 @@
+package decode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+// encodeTIFF encodes a width x height image
+func encodeTIFF(t testing.TB, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	if err := tiff.Encode(&buf, img, &tiff.Options{Compression: tiff.Deflate}); err != nil {
+		t.Fatalf("Failed to encode TIFF: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// hugeOffsetTIFF is a TIFF header pointing its first IFD 2 GiB away
+var hugeOffsetTIFF = []byte("II*\x00\xff\xff\xff\x7f")
+
+func TestTIFF(t *testing.T) {
+	valid := encodeTIFF(t, 8, 4)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		limits  Limits
+		wantErr string
+	}{
+		{name: "valid", data: valid, limits: DefaultLimits},
+		{name: "no limits", data: valid, limits: Limits{}},
+		{name: "too wide", data: valid, limits: Limits{MaxWidth: 4}, wantErr: "8 pixels wide, above the limit of 4"},
+		{name: "too many pixels", data: valid, limits: Limits{MaxPixels: 16}, wantErr: "32 pixels, above the limit of 16"},
+		{name: "too many bytes", data: valid, limits: Limits{MaxBytes: 20}, wantErr: "more than 20 bytes"},
+		// The decoder reads the IFD from the file rather than allocating up to its offset
+		{name: "IFD past the end", data: hugeOffsetTIFF, limits: DefaultLimits, wantErr: "EOF"},
+		{name: "not a TIFF", data: []byte("GIF89a\x08\x00\x04\x00"), limits: DefaultLimits, wantErr: "malformed header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := TIFF(bytes.NewReader(tt.data), tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("TIFF() error = %v", err)
+				}
+				if img.Bounds() != image.Rect(0, 0, 8, 4) {
+					t.Errorf("TIFF() bounds = %v, want 8x4", img.Bounds())
+				}
+				if r, _, _, _ := img.At(0, 0).RGBA(); r>>8 != 255 {
+					t.Errorf("TIFF() pixel = %v, want red", img.At(0, 0))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("TIFF() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTIFFConfig(t *testing.T) {
+	data := encodeTIFF(t, 30, 10)
+	config, err := TIFFConfig(bytes.NewReader(data), Limits{})
+	if err != nil || config.Width != 30 || config.Height != 10 {
+		t.Errorf("TIFFConfig() without limits = %dx%d, %v, want 30x10", config.Width, config.Height, err)
+	}
+	_, err = TIFFConfig(bytes.NewReader(data), Limits{MaxHeight: 8})
+	limit, ok := err.(*LimitError)
+	if !ok || limit.Limit != LimitHeight || limit.Value != 10 {
+		t.Errorf("TIFFConfig() error = %#v, want a height LimitError", err)
+	}
+}
+
+func FuzzTIFF(f *testing.F) {
+	f.Add(encodeTIFF(f, 8, 4))
+	f.Add(encodeTIFF(f, 2048, 1))
+	f.Add(hugeOffsetTIFF)
+	limits := Limits{MaxWidth: 1024, MaxHeight: 1024, MaxPixels: 1 << 18, MaxBytes: 1 << 20}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := TIFF(bytes.NewReader(data), limits)
+		if err != nil {
+			return
+		}
+		b := img.Bounds()
+		if b.Dx() > limits.MaxWidth || b.Dy() > limits.MaxHeight || int64(b.Dx()*b.Dy()) > limits.MaxPixels {
+			t.Fatalf("TIFF() decoded %v, beyond the limits", b)
+		}
+	})
+}
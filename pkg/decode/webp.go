@@ -0,0 +1,229 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"time"
+
+	"golang.org/x/image/webp"
+)
+
+// WebP decodes a still WebP image from r within limits, lossy or lossless,
+// reading its header first like PNG
+func WebP(r io.Reader, limits Limits) (image.Image, error) {
+	return decodeImage(r, limits, webp.DecodeConfig, webp.Decode)
+}
+
+// WebPConfig returns the dimensions of the WebP image of r, checked against
+// limits, reading only its header. The dimensions of an animated WebP are
+// those of its canvas.
+func WebPConfig(r io.Reader, limits Limits) (image.Config, error) {
+	return decodeConfig(r, limits, webp.DecodeConfig)
+}
+
+// WebPAnimation holds the frames of an animated WebP
+type WebPAnimation struct {
+	// Frames are the canvas as shown by every frame, composited like players
+	// do over the frames before it
+	Frames []*image.RGBA
+	// Delays are how long every frame is shown
+	Delays []time.Duration
+	// LoopCount is the number of times the animation plays, 0 for forever
+	LoopCount int
+}
+
+// ANMF frame flags
+const (
+	webpDispose = 1 << 0
+	webpNoBlend = 1 << 1
+)
+
+// IsAnimatedWebP reports whether header, the first 21 bytes or more of a
+// file, starts an animated WebP, as flagged in its VP8X chunk
+func IsAnimatedWebP(header []byte) bool {
+	return len(header) >= 21 && string(header[:4]) == "RIFF" && string(header[8:16]) == "WEBPVP8X" && header[20]&0x02 != 0
+}
+
+// AnimatedWebP decodes all the frames of an animated WebP from r within
+// limits, every frame counting as its whole canvas like GIF frames. The
+// frame chunks are listed first so oversized canvases and frame counts are
+// rejected before their pixels are allocated.
+func AnimatedWebP(r io.Reader, limits Limits) (*WebPAnimation, error) {
+	lr := newLimitedReader(r, limits)
+	data, err := io.ReadAll(lr)
+	if err != nil {
+		return nil, err
+	}
+	if !IsAnimatedWebP(data) {
+		return nil, fmt.Errorf("webp: not an animated WebP")
+	}
+	// The RIFF size covers at least the WEBP form type
+	size := 8 + int64(binary.LittleEndian.Uint32(data[4:8]))
+	if size < 12 {
+		return nil, fmt.Errorf("webp: invalid WebP")
+	}
+	if size < int64(len(data)) {
+		data = data[:size]
+	}
+	chunks, err := webpChunks(data[12:])
+	if err != nil {
+		return nil, err
+	}
+
+	var width, height int
+	anim := &WebPAnimation{}
+	var frames [][]byte
+	for _, chunk := range chunks {
+		switch chunk.id {
+		case "VP8X":
+			if len(chunk.data) < 10 {
+				return nil, fmt.Errorf("webp: invalid VP8X chunk")
+			}
+			width, height = 1+uint24(chunk.data[4:]), 1+uint24(chunk.data[7:])
+		case "ANIM":
+			if len(chunk.data) < 6 {
+				return nil, fmt.Errorf("webp: invalid ANIM chunk")
+			}
+			anim.LoopCount = int(binary.LittleEndian.Uint16(chunk.data[4:6]))
+		case "ANMF":
+			if len(chunk.data) < 16 {
+				return nil, fmt.Errorf("webp: invalid ANMF chunk")
+			}
+			frames = append(frames, chunk.data)
+		}
+	}
+	if err := limits.checkSize(width, height); err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("webp: animation has no frames")
+	}
+	if err := limits.checkPixels(int64(len(frames)) * int64(width) * int64(height)); err != nil {
+		return nil, err
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, frame := range frames {
+		x, y := 2*uint24(frame[0:]), 2*uint24(frame[3:])
+		bounds := image.Rect(x, y, x+1+uint24(frame[6:]), y+1+uint24(frame[9:]))
+		if !bounds.In(canvas.Bounds()) {
+			return nil, fmt.Errorf("webp: frame %d is outside of the canvas", i)
+		}
+		img, err := decodeWebPFrame(frame[16:], bounds.Dx(), bounds.Dy())
+		if err != nil {
+			return nil, fmt.Errorf("webp: frame %d: %v", i, err)
+		}
+
+		op := draw.Over
+		if frame[15]&webpNoBlend != 0 {
+			op = draw.Src
+		}
+		draw.Draw(canvas, bounds, img, img.Bounds().Min, op)
+		shown := image.NewRGBA(canvas.Bounds())
+		copy(shown.Pix, canvas.Pix)
+		anim.Frames = append(anim.Frames, shown)
+		anim.Delays = append(anim.Delays, time.Duration(uint24(frame[12:]))*time.Millisecond)
+
+		// Disposed frames are cleared to transparent, the background color
+		// being only a hint players ignore
+		if frame[15]&webpDispose != 0 {
+			draw.Draw(canvas, bounds, image.Transparent, image.Point{}, draw.Src)
+		}
+		if err := lr.checkTime(); err != nil {
+			return nil, err
+		}
+	}
+	return anim, nil
+}
+
+// webpChunk is a chunk of a RIFF container
+type webpChunk struct {
+	id   string
+	data []byte
+}
+
+// webpChunks splits data into its RIFF chunks, padded to an even size
+func webpChunks(data []byte) ([]webpChunk, error) {
+	var chunks []webpChunk
+	for pos := 0; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, fmt.Errorf("webp: truncated chunk header")
+		}
+		size := int64(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		if size > int64(len(data)-pos-8) {
+			return nil, fmt.Errorf("webp: truncated %s chunk", data[pos:pos+4])
+		}
+		chunks = append(chunks, webpChunk{id: string(data[pos : pos+4]), data: data[pos+8 : pos+8+int(size)]})
+		pos += 8 + int(size) + int(size&1)
+	}
+	return chunks, nil
+}
+
+// decodeWebPFrame decodes the frame data of an ANMF chunk, a VP8 or VP8L
+// bitstream with its optional ALPH chunk, by wrapping it in a still WebP of
+// the frame size
+func decodeWebPFrame(data []byte, width, height int) (image.Image, error) {
+	chunks, err := webpChunks(data)
+	if err != nil {
+		return nil, err
+	}
+	var alpha, bitstream *webpChunk
+	for i, chunk := range chunks {
+		switch chunk.id {
+		case "ALPH":
+			alpha = &chunks[i]
+		case "VP8 ", "VP8L":
+			bitstream = &chunks[i]
+		}
+	}
+	if bitstream == nil {
+		return nil, fmt.Errorf("missing VP8 or VP8L chunk")
+	}
+
+	var still []byte
+	if alpha != nil && bitstream.id == "VP8 " {
+		header := []byte{0x10, 0, 0, 0}
+		header = appendUint24(appendUint24(header, width-1), height-1)
+		still = appendWebPChunk(still, "VP8X", header)
+		still = appendWebPChunk(still, "ALPH", alpha.data)
+	}
+	still = appendWebPChunk(still, bitstream.id, bitstream.data)
+	riff := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(4+len(still)))...)
+	riff = append(append(riff, "WEBP"...), still...)
+
+	// The bitstream declares its own size, checked before its pixels are
+	// allocated
+	config, err := webp.DecodeConfig(bytes.NewReader(riff))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width != width || config.Height != height {
+		return nil, fmt.Errorf("%dx%d bitstream, %dx%d declared", config.Width, config.Height, width, height)
+	}
+	return webp.Decode(bytes.NewReader(riff))
+}
+
+// appendWebPChunk appends a RIFF chunk of data to dst, padded to an even size
+func appendWebPChunk(dst []byte, id string, data []byte) []byte {
+	dst = append(dst, id...)
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(data)))
+	dst = append(dst, data...)
+	if len(data)%2 == 1 {
+		dst = append(dst, 0)
+	}
+	return dst
+}
+
+// uint24 reads a little endian 24-bit integer
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+// appendUint24 appends v as a little endian 24-bit integer
+func appendUint24(dst []byte, v int) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16))
+}
@@ -0,0 +1,283 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bitWriter packs bits least significant first, like the WebP lossless format
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *bitWriter) write(value uint32, bits int) {
+	for i := 0; i < bits; i++ {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		w.data[len(w.data)-1] |= byte(value>>i&1) << (w.n % 8)
+		w.n++
+	}
+}
+
+// encodeWebP encodes a lossless width x height WebP image of a single color:
+// every prefix code has a single symbol, so the pixels take no bits
+func encodeWebP(width, height int, c color.NRGBA) []byte {
+	w := &bitWriter{}
+	w.write(0x2f, 8)
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	w.write(1, 1) // alpha is used
+	w.write(0, 3) // version
+	w.write(0, 1) // no transform
+	w.write(0, 1) // no color cache
+	w.write(0, 1) // no meta prefix codes
+	for _, symbol := range []uint8{c.G, c.R, c.B, c.A, 0} {
+		w.write(1, 1) // simple code
+		w.write(0, 1) // of one symbol
+		w.write(1, 1) // of 8 bits
+		w.write(uint32(symbol), 8)
+	}
+
+	chunk := append([]byte("VP8L"), binary.LittleEndian.AppendUint32(nil, uint32(len(w.data)))...)
+	chunk = append(chunk, w.data...)
+	if len(w.data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(4+len(chunk)))...)
+	data = append(data, "WEBP"...)
+	return append(data, chunk...)
+}
+
+func TestWebP(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	valid := encodeWebP(8, 4, red)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		limits  Limits
+		wantErr string
+	}{
+		{name: "valid", data: valid, limits: DefaultLimits},
+		{name: "no limits", data: valid, limits: Limits{}},
+		{name: "too wide", data: valid, limits: Limits{MaxWidth: 4}, wantErr: "8 pixels wide, above the limit of 4"},
+		{name: "too many pixels", data: valid, limits: Limits{MaxPixels: 16}, wantErr: "32 pixels, above the limit of 16"},
+		// The header is checked before allocating 1 GiB of pixels
+		{name: "huge declared size", data: encodeWebP(1<<14, 1<<14, red), limits: Limits{MaxPixels: 1 << 20}, wantErr: "268435456 pixels"},
+		{name: "not a WebP", data: []byte("GIF89a\x08\x00\x04\x00"), limits: DefaultLimits, wantErr: "missing RIFF chunk header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := WebP(bytes.NewReader(tt.data), tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("WebP() error = %v", err)
+				}
+				if img.Bounds() != image.Rect(0, 0, 8, 4) {
+					t.Errorf("WebP() bounds = %v, want 8x4", img.Bounds())
+				}
+				if got := color.NRGBAModel.Convert(img.At(7, 3)); got != red {
+					t.Errorf("WebP() pixel = %v, want %v", got, red)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("WebP() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebPConfig(t *testing.T) {
+	data := encodeWebP(1<<14, 10, color.NRGBA{A: 255})
+	config, err := WebPConfig(bytes.NewReader(data), Limits{})
+	if err != nil || config.Width != 1<<14 || config.Height != 10 {
+		t.Errorf("WebPConfig() without limits = %dx%d, %v, want 16384x10", config.Width, config.Height, err)
+	}
+	_, err = WebPConfig(bytes.NewReader(data), Limits{MaxWidth: 4096})
+	limit, ok := err.(*LimitError)
+	if !ok || limit.Limit != LimitWidth || limit.Value != 1<<14 {
+		t.Errorf("WebPConfig() error = %#v, want a width LimitError", err)
+	}
+}
+
+func FuzzWebP(f *testing.F) {
+	red := color.NRGBA{255, 0, 0, 255}
+	f.Add(encodeWebP(8, 4, red))
+	f.Add(encodeWebP(1<<14, 1<<14, red))
+	limits := Limits{MaxWidth: 1024, MaxHeight: 1024, MaxPixels: 1 << 18, MaxBytes: 1 << 20}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		img, err := WebP(bytes.NewReader(data), limits)
+		if err != nil {
+			return
+		}
+		b := img.Bounds()
+		if b.Dx() > limits.MaxWidth || b.Dy() > limits.MaxHeight || int64(b.Dx()*b.Dy()) > limits.MaxPixels {
+			t.Fatalf("WebP() decoded %v, beyond the limits", b)
+		}
+	})
+}
+
+// animFrame is a frame of a test animation, a single color rectangle
+type animFrame struct {
+	x, y, width, height int
+	color               color.NRGBA
+	duration            int
+	flags               byte
+}
+
+// encodeAnimatedWebP encodes an animated WebP of the frames on a width x
+// height canvas, looping loops times
+func encodeAnimatedWebP(width, height, loops int, frames []animFrame) []byte {
+	vp8x := []byte{0x12, 0, 0, 0}
+	vp8x = appendUint24(appendUint24(vp8x, width-1), height-1)
+	body := appendWebPChunk([]byte("WEBP"), "VP8X", vp8x)
+	body = appendWebPChunk(body, "ANIM", []byte{0, 0, 0, 0, byte(loops), byte(loops >> 8)})
+	for _, f := range frames {
+		anmf := appendUint24(appendUint24(nil, f.x/2), f.y/2)
+		anmf = appendUint24(appendUint24(anmf, f.width-1), f.height-1)
+		anmf = append(appendUint24(anmf, f.duration), f.flags)
+		// The VP8L chunk of a still image
+		anmf = append(anmf, encodeWebP(f.width, f.height, f.color)[12:]...)
+		body = appendWebPChunk(body, "ANMF", anmf)
+	}
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestAnimatedWebP(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	halfGreen := color.NRGBA{0, 255, 0, 128}
+	transparent := color.NRGBA{}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		limits     Limits
+		wantDelays []time.Duration
+		// wantPixels are the colors at (1, 1) and (5, 1) of every frame
+		wantPixels [][2]color.NRGBA
+		wantErr    string
+	}{
+		{
+			name: "frames kept",
+			data: encodeAnimatedWebP(8, 4, 3, []animFrame{
+				{width: 8, height: 4, color: red, duration: 100},
+				{x: 4, width: 4, height: 4, color: blue, duration: 250},
+			}),
+			limits:     DefaultLimits,
+			wantDelays: []time.Duration{100 * time.Millisecond, 250 * time.Millisecond},
+			wantPixels: [][2]color.NRGBA{{red, red}, {red, blue}},
+		},
+		{
+			name: "disposed frame",
+			data: encodeAnimatedWebP(8, 4, 0, []animFrame{
+				{width: 4, height: 4, color: red, duration: 50, flags: webpDispose},
+				{x: 4, width: 4, height: 4, color: blue, duration: 50},
+			}),
+			wantDelays: []time.Duration{50 * time.Millisecond, 50 * time.Millisecond},
+			wantPixels: [][2]color.NRGBA{{red, transparent}, {transparent, blue}},
+		},
+		{
+			name: "blending",
+			data: encodeAnimatedWebP(8, 4, 0, []animFrame{
+				{width: 8, height: 4, color: red},
+				{width: 4, height: 4, color: halfGreen},
+				{x: 4, width: 4, height: 4, color: halfGreen, flags: webpNoBlend},
+			}),
+			wantDelays: []time.Duration{0, 0, 0},
+			wantPixels: [][2]color.NRGBA{{red, red}, {{127, 128, 0, 255}, red}, {{127, 128, 0, 255}, halfGreen}},
+		},
+		{
+			name:    "too many pixels",
+			data:    encodeAnimatedWebP(8, 4, 0, []animFrame{{width: 8, height: 4, color: red}, {width: 8, height: 4, color: red}}),
+			limits:  Limits{MaxPixels: 48},
+			wantErr: "64 pixels, above the limit of 48",
+		},
+		{
+			name:    "frame outside of the canvas",
+			data:    encodeAnimatedWebP(8, 4, 0, []animFrame{{x: 6, width: 4, height: 4, color: red}}),
+			wantErr: "frame 0 is outside of the canvas",
+		},
+		{name: "no frames", data: encodeAnimatedWebP(8, 4, 0, nil), wantErr: "animation has no frames"},
+		{name: "RIFF size below the form type", data: truncatedAnimatedWebP, wantErr: "invalid WebP"},
+		{name: "still image", data: encodeWebP(8, 4, red), wantErr: "not an animated WebP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim, err := AnimatedWebP(bytes.NewReader(tt.data), tt.limits)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("AnimatedWebP() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnimatedWebP() error = %v", err)
+			}
+			if !slices.Equal(anim.Delays, tt.wantDelays) {
+				t.Errorf("Delays = %v, want %v", anim.Delays, tt.wantDelays)
+			}
+			if len(anim.Frames) != len(tt.wantPixels) {
+				t.Fatalf("got %d frames, want %d", len(anim.Frames), len(tt.wantPixels))
+			}
+			for i, frame := range anim.Frames {
+				if frame.Bounds() != image.Rect(0, 0, 8, 4) {
+					t.Errorf("frame %d bounds = %v, want the canvas", i, frame.Bounds())
+				}
+				for j, p := range []image.Point{{1, 1}, {5, 1}} {
+					if got := color.NRGBAModel.Convert(frame.At(p.X, p.Y)); got != tt.wantPixels[i][j] {
+						t.Errorf("frame %d pixel at %v = %v, want %v", i, p, got, tt.wantPixels[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+// truncatedAnimatedWebP flags an animation in its VP8X chunk while its RIFF
+// header declares no content at all
+var truncatedAnimatedWebP = []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x02\x00\x00\x00\x07\x00\x00\x03\x00\x00")
+
+func FuzzAnimatedWebP(f *testing.F) {
+	red := color.NRGBA{255, 0, 0, 255}
+	f.Add(encodeAnimatedWebP(8, 4, 0, []animFrame{
+		{width: 8, height: 4, color: red, duration: 100},
+		{x: 4, width: 4, height: 4, color: red, duration: 100, flags: webpDispose | webpNoBlend},
+	}))
+	f.Add(truncatedAnimatedWebP)
+	limits := Limits{MaxWidth: 1024, MaxHeight: 1024, MaxPixels: 1 << 18, MaxBytes: 1 << 20}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		anim, err := AnimatedWebP(bytes.NewReader(data), limits)
+		if err != nil {
+			return
+		}
+		b := anim.Frames[0].Bounds()
+		if b.Dx() > limits.MaxWidth || b.Dy() > limits.MaxHeight || int64(len(anim.Frames))*int64(b.Dx()*b.Dy()) > limits.MaxPixels {
+			t.Fatalf("AnimatedWebP() decoded %d frames of %v, beyond the limits", len(anim.Frames), b)
+		}
+	})
+}
+
+func TestIsAnimatedWebP(t *testing.T) {
+	animated := encodeAnimatedWebP(8, 4, 0, []animFrame{{width: 8, height: 4, color: color.NRGBA{A: 255}}})
+	if !IsAnimatedWebP(animated) {
+		t.Errorf("IsAnimatedWebP() = false for an animation")
+	}
+	if IsAnimatedWebP(encodeWebP(8, 4, color.NRGBA{A: 255})) {
+		t.Errorf("IsAnimatedWebP() = true for a still image")
+	}
+	if IsAnimatedWebP(animated[:20]) {
+		t.Errorf("IsAnimatedWebP() = true for a truncated header")
+	}
+}
@@ -0,0 +1,343 @@
+// Package encoder writes GIFs like image/gif, compressing their frames
+// concurrently. A palette shared by all frames is stored once, as the global
+// color table, even when the GIF does not set one.
+package encoder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"runtime"
+)
+
+// Options are the encoding settings
+type Options struct {
+	// Workers is the number of frames compressed concurrently, runtime.NumCPU() when 0
+	Workers int
+}
+
+// byteWriter is the destination of encoded blocks, a bufio.Writer or a bytes.Buffer
+type byteWriter interface {
+	io.Writer
+	io.ByteWriter
+}
+
+// encoder holds the state of a GIF being written
+type encoder struct {
+	w    *bufio.Writer
+	g    *gif.GIF
+	opts Options
+	// globalTable is the encoded global color table, empty when there is none
+	globalTable []byte
+}
+
+// EncodeAll writes the frames of g to w, accepting the same GIFs as gif.EncodeAll
+func EncodeAll(w io.Writer, g *gif.GIF, opts Options) error {
+	if len(g.Image) == 0 {
+		return fmt.Errorf("gif: must provide at least one image")
+	}
+	if len(g.Image) != len(g.Delay) {
+		return fmt.Errorf("gif: mismatched image and delay lengths")
+	}
+	if g.Disposal != nil && len(g.Image) != len(g.Disposal) {
+		return fmt.Errorf("gif: mismatched image and disposal lengths")
+	}
+
+	e := &encoder{w: bufio.NewWriter(w), g: g, opts: opts}
+	config := g.Config
+	if config.Width == 0 && config.Height == 0 {
+		corner := g.Image[0].Bounds().Max
+		config.Width, config.Height = corner.X, corner.Y
+	}
+
+	// Without a global palette, share the palette of the first frame
+	globalPalette := g.Image[0].Palette
+	if config.ColorModel != nil {
+		p, ok := config.ColorModel.(color.Palette)
+		if !ok {
+			return fmt.Errorf("gif: GIF color model must be a color.Palette")
+		}
+		globalPalette = p
+	}
+
+	if err := e.writeHeader(config, globalPalette); err != nil {
+		return err
+	}
+	if err := e.writeFrames(config); err != nil {
+		return err
+	}
+	if err := e.w.WriteByte(0x3b); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// writeHeader writes the header, the logical screen descriptor with the global
+// color table and the looping extension
+func (e *encoder) writeHeader(config image.Config, palette color.Palette) error {
+	if config.Width >= 1<<16 || config.Height >= 1<<16 {
+		return fmt.Errorf("gif: image is too large to encode")
+	}
+
+	var header [13]byte
+	copy(header[:6], "GIF89a")
+	binary.LittleEndian.PutUint16(header[6:8], uint16(config.Width))
+	binary.LittleEndian.PutUint16(header[8:10], uint16(config.Height))
+	if len(palette) > 0 {
+		table, size, err := encodeColorTable(palette)
+		if err != nil {
+			return err
+		}
+		e.globalTable = table
+		header[10] = 0x80 | size
+		header[11] = e.g.BackgroundIndex
+	}
+	e.w.Write(header[:])
+	e.w.Write(e.globalTable)
+
+	if len(e.g.Image) > 1 && e.g.LoopCount >= 0 {
+		e.w.Write([]byte{0x21, 0xff, 0x0b})
+		e.w.WriteString("NETSCAPE2.0")
+		e.w.Write([]byte{0x03, 0x01, byte(e.g.LoopCount), byte(e.g.LoopCount >> 8), 0x00})
+	}
+	return nil
+}
+
+// writeFrames compresses the frames concurrently into separate buffers and
+// writes them in order. At most two buffers per worker are pending at a time.
+func (e *encoder) writeFrames(config image.Config) error {
+	workers := e.opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(e.g.Image))
+	if workers == 1 {
+		for i := range e.g.Image {
+			if err := e.writeFrame(e.w, i, config); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type result struct {
+		buf  bytes.Buffer
+		err  error
+		done chan struct{}
+	}
+	results := make([]*result, len(e.g.Image))
+	for i := range results {
+		results[i] = &result{done: make(chan struct{})}
+	}
+
+	// The feeder stops handing out frames once too many wait to be written or
+	// an error stops the encoding
+	pending := make(chan struct{}, 2*workers)
+	next := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(next)
+		for i := range results {
+			select {
+			case pending <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case next <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range next {
+				r := results[i]
+				r.err = e.writeFrame(&r.buf, i, config)
+				close(r.done)
+			}
+		}()
+	}
+
+	for i, r := range results {
+		<-r.done
+		if r.err != nil {
+			return r.err
+		}
+		if _, err := e.w.Write(r.buf.Bytes()); err != nil {
+			return err
+		}
+		results[i] = nil
+		<-pending
+	}
+	return nil
+}
+
+// writeFrame writes the graphic control extension, image descriptor and
+// compressed pixels of frame i to w
+func (e *encoder) writeFrame(w byteWriter, i int, config image.Config) error {
+	frame, delay := e.g.Image[i], e.g.Delay[i]
+	var disposal byte
+	if e.g.Disposal != nil {
+		disposal = e.g.Disposal[i]
+	}
+
+	if len(frame.Palette) == 0 {
+		return fmt.Errorf("gif: cannot encode image block with empty palette")
+	}
+	b := frame.Bounds()
+	if b.Min.X < 0 || b.Max.X >= 1<<16 || b.Min.Y < 0 || b.Max.Y >= 1<<16 {
+		return fmt.Errorf("gif: image block is too large to encode")
+	}
+	if !b.In(image.Rect(0, 0, config.Width, config.Height)) {
+		return fmt.Errorf("gif: image block is out of bounds")
+	}
+
+	// The first fully transparent color becomes the transparent index
+	transparent := -1
+	for i, c := range frame.Palette {
+		if c == nil {
+			return fmt.Errorf("gif: cannot encode color table with nil entries")
+		}
+		if _, _, _, a := c.RGBA(); a == 0 {
+			transparent = i
+			break
+		}
+	}
+
+	if delay > 0 || disposal != 0 || transparent != -1 {
+		control := []byte{0x21, 0xf9, 0x04, disposal << 2, byte(delay), byte(delay >> 8), 0x00, 0x00}
+		if transparent != -1 {
+			control[3] |= 0x01
+			control[6] = byte(transparent)
+		}
+		w.Write(control)
+	}
+
+	var descriptor [10]byte
+	descriptor[0] = 0x2c
+	binary.LittleEndian.PutUint16(descriptor[1:3], uint16(b.Min.X))
+	binary.LittleEndian.PutUint16(descriptor[3:5], uint16(b.Min.Y))
+	binary.LittleEndian.PutUint16(descriptor[5:7], uint16(b.Dx()))
+	binary.LittleEndian.PutUint16(descriptor[7:9], uint16(b.Dy()))
+
+	// Only write a local color table when the frame palette differs from the global one
+	table, size, err := encodeColorTable(frame.Palette)
+	if err != nil {
+		return err
+	}
+	used := 3 * len(frame.Palette)
+	local := used > len(e.globalTable) || !tablesMatch(table[:used], e.globalTable[:used], transparent)
+	if local {
+		descriptor[9] = 0x80 | size
+	}
+	w.Write(descriptor[:])
+	if local {
+		w.Write(table)
+	}
+
+	litWidth := int(size) + 1
+	if litWidth < 2 {
+		litWidth = 2
+	}
+	w.WriteByte(byte(litWidth))
+
+	blocks := &blockWriter{w: w}
+	z := lzw.NewWriter(blocks, lzw.LSB, litWidth)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		offset := frame.PixOffset(b.Min.X, y)
+		if _, err := z.Write(frame.Pix[offset : offset+b.Dx()]); err != nil {
+			return err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	return blocks.Close()
+}
+
+// encodeColorTable returns the RGB triplets of p padded to a power of two,
+// along with the size field of the table
+func encodeColorTable(p color.Palette) ([]byte, byte, error) {
+	if len(p) > 256 {
+		return nil, 0, fmt.Errorf("gif: cannot encode color table with more than 256 entries")
+	}
+	var size byte
+	for 1<<(size+1) < len(p) {
+		size++
+	}
+
+	table := make([]byte, 3<<(size+1))
+	for i, c := range p {
+		if c == nil {
+			return nil, 0, fmt.Errorf("gif: cannot encode color table with nil entries")
+		}
+		r, g, b, _ := c.RGBA()
+		table[3*i], table[3*i+1], table[3*i+2] = byte(r>>8), byte(g>>8), byte(b>>8)
+	}
+	return table, size, nil
+}
+
+// tablesMatch reports whether two encoded color tables are equal, ignoring the
+// transparent entry which decoders never display
+func tablesMatch(a, b []byte, transparent int) bool {
+	if transparent < 0 {
+		return bytes.Equal(a, b)
+	}
+	skip := 3 * transparent
+	return bytes.Equal(a[:skip], b[:skip]) && bytes.Equal(a[skip+3:], b[skip+3:])
+}
+
+// blockWriter splits image data into sub-blocks of at most 255 bytes
+type blockWriter struct {
+	w   byteWriter
+	buf [256]byte
+	n   int
+}
+
+// WriteByte buffers c, writing a sub-block when it is full
+func (b *blockWriter) WriteByte(c byte) error {
+	b.buf[b.n+1] = c
+	b.n++
+	if b.n == 255 {
+		return b.flush()
+	}
+	return nil
+}
+
+// Write buffers p, writing sub-blocks as they fill up
+func (b *blockWriter) Write(p []byte) (int, error) {
+	for i, c := range p {
+		if err := b.WriteByte(c); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+// flush writes the pending sub-block
+func (b *blockWriter) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.buf[0] = byte(b.n)
+	_, err := b.w.Write(b.buf[:b.n+1])
+	b.n = 0
+	return err
+}
+
+// Close writes the pending sub-block and the block terminator
+func (b *blockWriter) Close() error {
+	if err := b.flush(); err != nil {
+		return err
+	}
+	return b.w.WriteByte(0)
+}
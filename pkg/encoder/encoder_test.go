@@ -0,0 +1,195 @@
+package encoder
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+// screenCapture draws frames looking like a terminal session quantized to 256
+// colors: lines of anti-aliased "text" appearing over a flat background
+func screenCapture(frames int) *gif.GIF {
+	palette := make(color.Palette, 256)
+	for i := range palette {
+		palette[i] = color.RGBA{uint8(i), uint8(255 - i), uint8(i / 2), 255}
+	}
+	rng := rand.New(rand.NewSource(1))
+	g := &gif.GIF{}
+	current := image.NewPaletted(image.Rect(0, 0, 480, 320), palette)
+	for f := 0; f < frames; f++ {
+		frame := image.NewPaletted(current.Rect, palette)
+		copy(frame.Pix, current.Pix)
+
+		// Type a line of glyph-like blocks
+		y := 8 + f*14%300
+		ink := uint8(1 + rng.Intn(200))
+		for x := 8; x < 8+rng.Intn(400); x += 7 {
+			glyph := rng.Intn(1 << 12)
+			for dy := 0; dy < 9; dy++ {
+				for dx := 0; dx < 5; dx++ {
+					if glyph>>((dy*5+dx)%12)&1 == 1 {
+						// Edge pixels get intermediate shades
+						frame.SetColorIndex(x+dx, y+dy, ink+uint8(rng.Intn(4)*(dx%2)))
+					}
+				}
+			}
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10)
+		current = frame
+	}
+	return g
+}
+
+func TestEncodeAll(t *testing.T) {
+	transparentPalette := color.Palette{color.Transparent, color.White, color.RGBA{255, 0, 0, 255}}
+	offset := image.NewPaletted(image.Rect(2, 1, 6, 3), transparentPalette)
+	offset.SetColorIndex(3, 2, 2)
+
+	tests := []struct {
+		name string
+		g    *gif.GIF
+	}{
+		{name: "screen capture", g: screenCapture(6)},
+		{name: "single frame", g: &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 3, 3), color.Palette{color.Black})}, Delay: []int{0}}},
+		{
+			name: "local palettes, disposal and transparency",
+			g: &gif.GIF{
+				Image:     []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 8, 4), color.Palette{color.Black, color.White}), offset},
+				Delay:     []int{5, 7},
+				Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground},
+				LoopCount: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeAll(&buf, tt.g, Options{}); err != nil {
+				t.Fatalf("EncodeAll() error = %v", err)
+			}
+			decoded, err := gif.DecodeAll(&buf)
+			if err != nil {
+				t.Fatalf("output does not decode: %v", err)
+			}
+
+			if len(decoded.Image) != len(tt.g.Image) {
+				t.Fatalf("got %d frames, want %d", len(decoded.Image), len(tt.g.Image))
+			}
+			wantLoop := tt.g.LoopCount
+			if len(tt.g.Image) == 1 {
+				wantLoop = -1
+			}
+			if decoded.LoopCount != wantLoop {
+				t.Errorf("loop count = %d, want %d", decoded.LoopCount, wantLoop)
+			}
+			for i, want := range tt.g.Image {
+				got := decoded.Image[i]
+				if got.Rect != want.Rect || !bytes.Equal(got.Pix, want.Pix) {
+					t.Errorf("frame %d pixels differ", i)
+				}
+				if decoded.Delay[i] != tt.g.Delay[i] {
+					t.Errorf("frame %d delay = %d, want %d", i, decoded.Delay[i], tt.g.Delay[i])
+				}
+				if tt.g.Disposal != nil && decoded.Disposal[i] != tt.g.Disposal[i] {
+					t.Errorf("frame %d disposal = %d, want %d", i, decoded.Disposal[i], tt.g.Disposal[i])
+				}
+				if _, _, _, a := got.Palette[0].RGBA(); (a == 0) != (want.Palette[0] == color.Transparent) {
+					t.Errorf("frame %d transparency not preserved", i)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeAllErrors(t *testing.T) {
+	frame := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black})
+	tests := []struct {
+		name string
+		g    *gif.GIF
+		opts Options
+	}{
+		{name: "no frames", g: &gif.GIF{}},
+		{name: "missing delays", g: &gif.GIF{Image: []*image.Paletted{frame}}},
+		{name: "missing disposal", g: &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}, Disposal: []byte{}}},
+		{name: "empty palette", g: &gif.GIF{Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 1, 1), nil)}, Delay: []int{0}}},
+		{name: "out of bounds", g: &gif.GIF{Image: []*image.Paletted{frame}, Delay: []int{0}, Config: image.Config{Width: 1, Height: 1}}},
+		{name: "pixel beyond the palette", g: &gif.GIF{Image: []*image.Paletted{{Pix: []uint8{0, 5, 0, 0}, Stride: 2, Rect: frame.Rect, Palette: frame.Palette}}, Delay: []int{0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := EncodeAll(&bytes.Buffer{}, tt.g, tt.opts); err == nil {
+				t.Error("EncodeAll() expected error")
+			}
+		})
+	}
+}
+
+// TestEncodeAllStdlib checks the output matches that of image/gif given the
+// shared palette as the global one
+func TestEncodeAllStdlib(t *testing.T) {
+	g := screenCapture(30)
+
+	global := *g
+	global.Config = image.Config{ColorModel: g.Image[0].Palette, Width: 480, Height: 320}
+	var stdlib bytes.Buffer
+	if err := gif.EncodeAll(&stdlib, &global); err != nil {
+		t.Fatalf("gif.EncodeAll() error = %v", err)
+	}
+	var native bytes.Buffer
+	if err := EncodeAll(&native, g, Options{}); err != nil {
+		t.Fatalf("EncodeAll() error = %v", err)
+	}
+	if !bytes.Equal(native.Bytes(), stdlib.Bytes()) {
+		t.Errorf("EncodeAll() wrote %d bytes, differing from the %d bytes of image/gif", native.Len(), stdlib.Len())
+	}
+}
+
+func TestEncodeAllWorkers(t *testing.T) {
+	g := screenCapture(12)
+
+	var sequential bytes.Buffer
+	if err := EncodeAll(&sequential, g, Options{Workers: 1}); err != nil {
+		t.Fatalf("EncodeAll() error = %v", err)
+	}
+	for _, workers := range []int{0, 3, 64} {
+		var concurrent bytes.Buffer
+		if err := EncodeAll(&concurrent, g, Options{Workers: workers}); err != nil {
+			t.Fatalf("EncodeAll() with %d workers error = %v", workers, err)
+		}
+		if !bytes.Equal(concurrent.Bytes(), sequential.Bytes()) {
+			t.Errorf("output with %d workers differs from the sequential one", workers)
+		}
+	}
+
+	// A failing frame stops the encoding
+	broken := *g
+	broken.Image = append([]*image.Paletted{}, g.Image...)
+	broken.Image[5] = image.NewPaletted(image.Rect(0, 0, 1, 1), nil)
+	if err := EncodeAll(&bytes.Buffer{}, &broken, Options{Workers: 4}); err == nil {
+		t.Error("EncodeAll() expected error for a frame without palette")
+	}
+}
+
+func BenchmarkEncodeAll(b *testing.B) {
+	g := screenCapture(50)
+	for _, workers := range []int{1, 0} {
+		name := "sequential"
+		if workers == 0 {
+			name = "concurrent"
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := EncodeAll(io.Discard, g, Options{Workers: workers}); err != nil {
+					b.Fatalf("EncodeAll() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package external runs the external tools go-togif shells out to, such as
+// ffmpeg, under explicit control: the executable is found in a known way and
+// its version checked, only allowed arguments are passed and every run is
+// bounded in time. External execution can be disabled altogether.
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisabled is returned instead of running a tool when external execution
+// is disabled
+var ErrDisabled = errors.New("external execution is disabled")
+
+// versionTimeout bounds the run printing the version of a tool
+const versionTimeout = 10 * time.Second
+
+// Tool is an external executable and the ways it may be run
+type Tool struct {
+	// Name is the executable looked up in the PATH
+	Name string
+	// Env names the environment variable with the path of the executable,
+	// used instead of the PATH when set
+	Env string
+	// VersionArgs make the tool print its version
+	VersionArgs []string
+	// Version matches the version in that output, its first two groups being
+	// the major and minor versions. A match with empty groups is a
+	// development build, accepted whatever its version.
+	Version *regexp.Regexp
+	// MinVersion is the oldest major and minor version supported
+	MinVersion [2]int
+	// Flags are the options the tool may be run with and the number of values
+	// each takes. Other arguments are values, which must not look like options.
+	Flags map[string]int
+	// Timeout bounds each run unless the runner sets its own, no limit when 0
+	Timeout time.Duration
+
+	// checked caches the versionCheck of the executables by path
+	checked sync.Map
+}
+
+// Find returns the path of the executable, from Env or the PATH, once its
+// version is checked
+func (t *Tool) Find() (string, error) {
+	path, _, err := t.FindVersion()
+	return path, err
+}
+
+// FindVersion returns the path of the executable like Find, and its version
+// as major.minor, "dev" for a development build or "" when the tool has no
+// Version
+func (t *Tool) FindVersion() (path, version string, err error) {
+	path = os.Getenv(t.Env)
+	if path == "" {
+		if path, err = exec.LookPath(t.Name); err != nil {
+			return "", "", fmt.Errorf("%s not found in the PATH, set its path in %s: %v", t.Name, t.Env, err)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid %s path %s: %v", t.Name, path, err)
+		}
+		path = abs
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid %s path: %v", t.Name, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", "", fmt.Errorf("invalid %s path %s: not a file", t.Name, path)
+	}
+
+	checked, ok := t.checked.Load(path)
+	if !ok {
+		version, err := t.checkVersion(path)
+		checked, _ = t.checked.LoadOrStore(path, versionCheck{version: version, err: err})
+	}
+	if err := checked.(versionCheck).err; err != nil {
+		return "", "", err
+	}
+	return path, checked.(versionCheck).version, nil
+}
+
+// versionCheck is the cached result of checking the version of an executable
+type versionCheck struct {
+	version string
+	err     error
+}
+
+// checkVersion runs the executable at path to check its version, and
+// returns it
+func (t *Tool) checkVersion(path string) (string, error) {
+	if t.Version == nil {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, t.VersionArgs...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error checking the version of %s: %v", path, err)
+	}
+
+	match := t.Version.FindSubmatch(out)
+	if match == nil {
+		return "", fmt.Errorf("%s does not look like %s: unrecognized version", path, t.Name)
+	}
+	if len(match) < 3 || len(match[1]) == 0 {
+		return "dev", nil
+	}
+	major, _ := strconv.Atoi(string(match[1]))
+	minor, _ := strconv.Atoi(string(match[2]))
+	if major < t.MinVersion[0] || (major == t.MinVersion[0] && minor < t.MinVersion[1]) {
+		return "", fmt.Errorf("%s %d.%d is too old, %d.%d or later is needed", t.Name, major, minor, t.MinVersion[0], t.MinVersion[1])
+	}
+	return fmt.Sprintf("%d.%d", major, minor), nil
+}
+
+// CheckArgs checks that args only use the allowed flags, with their values,
+// and that no other argument looks like an option, "-" standing for the
+// standard input or output
+func (t *Tool) CheckArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "-" || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		values, ok := t.Flags[arg]
+		if !ok {
+			return fmt.Errorf("%s argument %q is not allowed", t.Name, arg)
+		}
+		if i+values >= len(args) {
+			return fmt.Errorf("%s argument %s is missing its value", t.Name, arg)
+		}
+		// Values may look like options, e.g. negative numbers
+		i += values
+	}
+	return nil
+}
+
+// Runner runs tools, unless disabled
+type Runner struct {
+	// Disabled makes every run fail with ErrDisabled
+	Disabled bool
+	// Timeout bounds each run instead of the timeout of the tool when set
+	Timeout time.Duration
+	// Debug prints the commands run
+	Debug bool
+}
+
+// Run runs the tool with args, reading stdin, and returns what it wrote to
+// its standard error when it fails
+func (r Runner) Run(t *Tool, args []string, stdin io.Reader) error {
+	return r.run(t, args, stdin, nil)
+}
+
+// Output runs the tool with args like Run, and returns what it wrote to its
+// standard output
+func (r Runner) Output(t *Tool, args []string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := r.run(t, args, nil, &stdout); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// run runs the tool with args, reading stdin and writing its output to stdout
+func (r Runner) run(t *Tool, args []string, stdin io.Reader, stdout io.Writer) error {
+	if r.Disabled {
+		return fmt.Errorf("%s: %w", t.Name, ErrDisabled)
+	}
+	if err := t.CheckArgs(args); err != nil {
+		return err
+	}
+	path, err := t.Find()
+	if err != nil {
+		return err
+	}
+	if r.Debug {
+		fmt.Printf("Running %s %s\n", path, strings.Join(args, " "))
+	}
+
+	timeout := t.Timeout
+	if r.Timeout > 0 {
+		timeout = r.Timeout
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	var stderr bytes.Buffer
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s took longer than %v", t.Name, timeout)
+		}
+		return fmt.Errorf("%s failed: %v: %s", t.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
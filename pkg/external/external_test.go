@@ -0,0 +1,148 @@
+package external
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTool writes a shell script printing version, then running body, and
+// returns a tool running it
+func fakeTool(t *testing.T, version, body string) *Tool {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake tool is a shell script")
+	}
+	dir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	script := filepath.Join(dir, "tool")
+	content := "#!/bin/sh\n[ \"$1\" = -version ] && echo \"tool version " + version + "\" && exit\n" + body + "\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake tool: %v", err)
+	}
+	t.Setenv("GO_TOGIF_TEST_TOOL", script)
+	return &Tool{
+		Name:        "tool",
+		Env:         "GO_TOGIF_TEST_TOOL",
+		VersionArgs: []string{"-version"},
+		Version:     regexp.MustCompile(`tool version (?:(\d+)\.(\d+)|N-)`),
+		MinVersion:  [2]int{4, 2},
+		Flags:       map[string]int{"-y": 0, "-i": 1, "-crf": 1},
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		wantVersion string
+		wantErr     string
+	}{
+		{name: "supported", version: "6.1", wantVersion: "6.1"},
+		{name: "oldest supported", version: "4.2", wantVersion: "4.2"},
+		{name: "development build", version: "N-112345-gabcdef", wantVersion: "dev"},
+		{name: "too old", version: "4.1", wantErr: "tool 4.1 is too old, 4.2 or later is needed"},
+		{name: "something else", version: "", wantErr: "unrecognized version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := fakeTool(t, tt.version, "")
+			path, version, err := tool.FindVersion()
+			if tt.wantErr == "" {
+				if err != nil || path != os.Getenv(tool.Env) || version != tt.wantVersion {
+					t.Errorf("FindVersion() = %q, %q, %v, want %q, %q", path, version, err, os.Getenv(tool.Env), tt.wantVersion)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Find() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		tool := &Tool{Name: "go-togif-missing-tool", Env: "GO_TOGIF_TEST_TOOL"}
+		t.Setenv(tool.Env, "")
+		if _, err := tool.Find(); err == nil || !strings.Contains(err.Error(), "GO_TOGIF_TEST_TOOL") {
+			t.Errorf("Find() error = %v, want a hint at the environment variable", err)
+		}
+	})
+}
+
+func TestCheckArgs(t *testing.T) {
+	tool := &Tool{Name: "tool", Flags: map[string]int{"-y": 0, "-i": 1, "-crf": 1}}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "allowed", args: []string{"-y", "-i", "-", "-crf", "32", "out.webm"}},
+		{name: "negative value", args: []string{"-crf", "-1", "out.webm"}},
+		{name: "unknown flag", args: []string{"-y", "-filter_complex", "x", "out.webm"}, wantErr: true},
+		{name: "output looking like a flag", args: []string{"-y", "-evil.webm"}, wantErr: true},
+		{name: "missing value", args: []string{"out.webm", "-crf"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tool.CheckArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("CheckArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	tool := fakeTool(t, "6.1", `for last; do :; done
+[ "$last" = fail ] && echo "it broke" >&2 && exit 1
+[ "$last" = slow ] && exec sleep 5
+[ "$last" = print ] && echo printed && exit
+cat > "$last"`)
+	output := filepath.Join(filepath.Dir(os.Getenv(tool.Env)), "out.txt")
+
+	if err := (Runner{}).Run(tool, []string{"-y", "-i", "-", output}, strings.NewReader("frames")); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "frames" {
+		t.Errorf("output = %q, %v, want the standard input", data, err)
+	}
+
+	// The standard output is returned by Output
+	if out, err := (Runner{}).Output(tool, []string{"print"}); err != nil || string(out) != "printed\n" {
+		t.Errorf("Output() = %q, %v, want the standard output", out, err)
+	}
+
+	tests := []struct {
+		name    string
+		runner  Runner
+		args    []string
+		wantErr string
+	}{
+		{name: "disabled", runner: Runner{Disabled: true}, args: []string{output}, wantErr: "disabled"},
+		{name: "not allowed", args: []string{"-vf", "x", output}, wantErr: `"-vf" is not allowed`},
+		{name: "failure", args: []string{"fail"}, wantErr: "it broke"},
+		{name: "timeout", runner: Runner{Timeout: 50 * time.Millisecond}, args: []string{"slow"}, wantErr: "took longer than 50ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.runner.Run(tool, tt.args, strings.NewReader(""))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.runner.Disabled && !errors.Is(err, ErrDisabled) {
+				t.Errorf("Run() error = %v, want ErrDisabled", err)
+			}
+		})
+	}
+}
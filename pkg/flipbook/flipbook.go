@@ -0,0 +1,54 @@
+package flipbook
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/draw"
+	"time"
+)
+
+// Page is a frame of a flipbook with its timing
+type Page struct {
+	Image image.Image
+	// Start is when the frame is shown in the animation
+	Start time.Duration
+	// Delay is how long the frame is shown
+	Delay time.Duration
+}
+
+// Caption describes the timing of page i of n, e.g. "Frame 2/10 at 00:00.100 for 100 ms"
+func (p Page) Caption(i, n int) string {
+	ms := p.Start.Milliseconds()
+	return fmt.Sprintf("Frame %d/%d at %02d:%02d.%03d for %d ms", i+1, n, ms/60000, ms/1000%60, ms%1000, p.Delay.Milliseconds())
+}
+
+// nrgba returns the straight alpha pixels of img moved to the origin
+func nrgba(img image.Image) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) && n.Stride == 4*n.Rect.Dx() {
+		return n
+	}
+	n := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(n, n.Rect, img, img.Bounds().Min, draw.Src)
+	return n
+}
+
+// opaque reports whether every pixel of img is opaque
+func opaque(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// deflate returns data compressed as a zlib stream
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
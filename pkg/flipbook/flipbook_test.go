@@ -0,0 +1,62 @@
+package flipbook
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+// testPages returns n pages of 30x20 frames of increasing gray shown for 100ms,
+// the first one transparent
+func testPages(n int) []Page {
+	pages := make([]Page, n)
+	for i := range pages {
+		img := image.NewNRGBA(image.Rect(0, 0, 30, 20))
+		if i > 0 {
+			for j := 0; j < len(img.Pix); j += 4 {
+				img.Pix[j], img.Pix[j+1], img.Pix[j+2], img.Pix[j+3] = uint8(i*40), uint8(i*40), uint8(i*40), 255
+			}
+		}
+		pages[i] = Page{Image: img, Start: time.Duration(i) * 100 * time.Millisecond, Delay: 100 * time.Millisecond}
+	}
+	return pages
+}
+
+func TestCaption(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page
+		i, n int
+		want string
+	}{
+		{name: "first frame", page: Page{Delay: 100 * time.Millisecond}, i: 0, n: 3, want: "Frame 1/3 at 00:00.000 for 100 ms"},
+		{name: "later frame", page: Page{Start: 1250 * time.Millisecond, Delay: 40 * time.Millisecond}, i: 9, n: 10, want: "Frame 10/10 at 00:01.250 for 40 ms"},
+		{name: "past a minute", page: Page{Start: 61500 * time.Millisecond, Delay: time.Second}, i: 1, n: 2, want: "Frame 2/2 at 01:01.500 for 1000 ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.page.Caption(tt.i, tt.n); got != tt.want {
+				t.Errorf("Caption() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNRGBA(t *testing.T) {
+	src := image.NewRGBA(image.Rect(5, 5, 7, 6))
+	src.Set(5, 5, color.RGBA{R: 255, A: 255})
+	src.Set(6, 5, color.RGBA{R: 64, A: 128})
+
+	got := nrgba(src)
+	if got.Rect != image.Rect(0, 0, 2, 1) {
+		t.Fatalf("nrgba() bounds = %v, want moved to the origin", got.Rect)
+	}
+	if c := got.NRGBAAt(1, 0); c.R < 126 || c.R > 129 || c.A != 128 {
+		t.Errorf("nrgba() pixel = %v, want straight alpha red", c)
+	}
+	if opaque(got) {
+		t.Error("opaque() = true for a translucent pixel")
+	}
+}
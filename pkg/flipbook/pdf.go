@@ -0,0 +1,127 @@
+package flipbook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// captionBand is the height in points of the band below the frame
+	// holding its timing
+	captionBand = 20
+	// captionSize is the font size of the timing in points
+	captionSize = 9
+	// minPageWidth keeps the timing of small frames readable, in points
+	minPageWidth = 216
+)
+
+// pdfWriter writes numbered PDF objects, recording their offsets for the
+// cross-reference table
+type pdfWriter struct {
+	w       *bufio.Writer
+	offset  int
+	offsets []int
+}
+
+// printf writes formatted text, counting the bytes written
+func (p *pdfWriter) printf(format string, args ...any) {
+	n, _ := fmt.Fprintf(p.w, format, args...)
+	p.offset += n
+}
+
+// write writes data, counting the bytes written
+func (p *pdfWriter) write(data []byte) {
+	n, _ := p.w.Write(data)
+	p.offset += n
+}
+
+// reserve returns the number of a new object written later
+func (p *pdfWriter) reserve() int {
+	p.offsets = append(p.offsets, 0)
+	return len(p.offsets)
+}
+
+// object starts writing the object with the given number
+func (p *pdfWriter) object(n int) {
+	p.offsets[n-1] = p.offset
+	p.printf("%d 0 obj\n", n)
+}
+
+// stream writes an object holding a stream of data with the given dictionary entries
+func (p *pdfWriter) stream(n int, dict string, data []byte) {
+	p.object(n)
+	p.printf("<< %s /Length %d >>\nstream\n", dict, len(data))
+	p.write(data)
+	p.printf("\nendstream\nendobj\n")
+}
+
+// WritePDF writes the pages as a PDF document, one frame per page at 72
+// pixels per inch, with its timing written below it
+func WritePDF(w io.Writer, pages []Page) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to write")
+	}
+	p := &pdfWriter{w: bufio.NewWriter(w)}
+	p.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+
+	catalog, tree, font := p.reserve(), p.reserve(), p.reserve()
+	p.object(catalog)
+	p.printf("<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", tree)
+	p.object(font)
+	p.printf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>\nendobj\n")
+
+	kids := make([]string, len(pages))
+	for i, page := range pages {
+		img := nrgba(page.Image)
+		w, h := img.Rect.Dx(), img.Rect.Dy()
+
+		// Color and alpha are separate images
+		rgb := make([]byte, 0, w*h*3)
+		alpha := make([]byte, 0, w*h)
+		for j := 0; j < len(img.Pix); j += 4 {
+			rgb = append(rgb, img.Pix[j], img.Pix[j+1], img.Pix[j+2])
+			alpha = append(alpha, img.Pix[j+3])
+		}
+		image := p.reserve()
+		smask := ""
+		if !opaque(img) {
+			mask := p.reserve()
+			p.stream(mask, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode", w, h), deflate(alpha))
+			smask = fmt.Sprintf(" /SMask %d 0 R", mask)
+		}
+		p.stream(image, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode%s", w, h, smask), deflate(rgb))
+
+		contents := p.reserve()
+		text := fmt.Sprintf("q %d 0 0 %d 0 %d cm /Im0 Do Q\nBT /F1 %d Tf 4 %d Td (%s) Tj ET\n",
+			w, h, captionBand, captionSize, (captionBand-captionSize)/2+1, escapePDF(page.Caption(i, len(pages))))
+		p.stream(contents, "", []byte(text))
+
+		pageObj := p.reserve()
+		p.object(pageObj)
+		p.printf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Contents %d 0 R /Resources << /XObject << /Im0 %d 0 R >> /Font << /F1 %d 0 R >> >> >>\nendobj\n",
+			tree, max(w, minPageWidth), h+captionBand, contents, image, font)
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj)
+	}
+
+	p.object(tree)
+	p.printf("<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+
+	// Cross-reference table, every entry being 20 bytes long
+	xref := p.offset
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets)+1)
+	for _, offset := range p.offsets {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets)+1, catalog, xref)
+	if err := p.w.Flush(); err != nil {
+		return fmt.Errorf("error writing PDF: %v", err)
+	}
+	return nil
+}
+
+// escapePDF escapes the characters of s delimiting PDF strings
+func escapePDF(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
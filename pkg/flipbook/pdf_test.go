@@ -0,0 +1,63 @@
+package flipbook
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, testPages(3)); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	pdf := buf.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("WritePDF() does not write a PDF header and trailer")
+	}
+	if !strings.Contains(pdf, "/Count 3") {
+		t.Error("WritePDF() does not write 3 pages")
+	}
+	for _, caption := range []string{"(Frame 1/3 at 00:00.000 for 100 ms)", "(Frame 3/3 at 00:00.200 for 100 ms)"} {
+		if !strings.Contains(pdf, caption) {
+			t.Errorf("WritePDF() lacks the timing %s", caption)
+		}
+	}
+	// Only the transparent first frame has an alpha mask
+	if n := strings.Count(pdf, "/SMask"); n != 1 {
+		t.Errorf("WritePDF() writes %d alpha masks, want 1", n)
+	}
+	// Small frames get a page wide enough for their timing
+	if !strings.Contains(pdf, "/MediaBox [0 0 216 40]") {
+		t.Error("WritePDF() does not widen the pages of small frames")
+	}
+
+	// Every cross-reference entry points to its object
+	start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(pdf)[1])
+	if err != nil {
+		t.Fatalf("Failed to read startxref: %v", err)
+	}
+	lines := strings.Split(pdf[start:], "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for n := 1; n < count; n++ {
+		offset, _ := strconv.Atoi(lines[2+n][:10])
+		if want := strconv.Itoa(n) + " 0 obj"; !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("cross-reference of object %d points to %q", n, pdf[offset:offset+len(want)])
+		}
+	}
+}
+
+func TestWritePDFNoPages(t *testing.T) {
+	if err := WritePDF(&bytes.Buffer{}, nil); err == nil {
+		t.Error("WritePDF() error = nil for no pages")
+	}
+}
+
+func TestEscapePDF(t *testing.T) {
+	if got, want := escapePDF(`a (b) \c`), `a \(b\) \\c`; got != want {
+		t.Errorf("escapePDF() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,179 @@
+package flipbook
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TIFF field types
+const (
+	tiffShort = 3
+	tiffLong  = 4
+	tiffASCII = 2
+)
+
+// TIFF tags written for every page
+const (
+	tagNewSubfileType   = 254
+	tagImageWidth       = 256
+	tagImageLength      = 257
+	tagBitsPerSample    = 258
+	tagCompression      = 259
+	tagPhotometric      = 262
+	tagImageDescription = 270
+	tagStripOffsets     = 273
+	tagSamplesPerPixel  = 277
+	tagRowsPerStrip     = 278
+	tagStripByteCounts  = 279
+	tagPlanarConfig     = 284
+	tagPageNumber       = 297
+	tagExtraSamples     = 338
+)
+
+const (
+	// subfilePage marks an image as a page of a multi-page document
+	subfilePage = 2
+	// compressionDeflate is zlib compression
+	compressionDeflate = 8
+	// photometricRGB is RGB color
+	photometricRGB = 2
+	// extraUnassociatedAlpha is straight alpha
+	extraUnassociatedAlpha = 2
+)
+
+// tiffEntry is a field of an image file directory
+type tiffEntry struct {
+	tag, typ uint16
+	// values are the shorts or longs of the field, or ascii its text
+	values []uint32
+	ascii  string
+}
+
+// size returns the number of bytes of the values of e
+func (e tiffEntry) size() int {
+	switch e.typ {
+	case tiffASCII:
+		return len(e.ascii) + 1
+	case tiffShort:
+		return 2 * len(e.values)
+	default:
+		return 4 * len(e.values)
+	}
+}
+
+// count returns the number of values of e
+func (e tiffEntry) count() int {
+	if e.typ == tiffASCII {
+		return len(e.ascii) + 1
+	}
+	return len(e.values)
+}
+
+// encode returns the values of e in little endian order
+func (e tiffEntry) encode() []byte {
+	if e.typ == tiffASCII {
+		return append([]byte(e.ascii), 0)
+	}
+	data := make([]byte, 0, e.size())
+	for _, v := range e.values {
+		if e.typ == tiffShort {
+			data = binary.LittleEndian.AppendUint16(data, uint16(v))
+		} else {
+			data = binary.LittleEndian.AppendUint32(data, v)
+		}
+	}
+	return data
+}
+
+// WriteTIFF writes the pages as a multi-page TIFF, one frame per page with
+// straight alpha and deflate compression, its timing in the image description
+func WriteTIFF(w io.Writer, pages []Page) error {
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages to write")
+	}
+	bw := bufio.NewWriter(w)
+	bw.Write([]byte{'I', 'I', 42, 0})
+	bw.Write(binary.LittleEndian.AppendUint32(nil, 8))
+
+	// Every page is laid out as its directory, the values too long to fit in
+	// it, and its pixels, the directory pointing to the next page
+	offset := 8
+	for i, page := range pages {
+		img := nrgba(page.Image)
+		pixels := deflate(img.Pix)
+		w, h := uint32(img.Rect.Dx()), uint32(img.Rect.Dy())
+
+		entries := []tiffEntry{
+			{tag: tagNewSubfileType, typ: tiffLong, values: []uint32{subfilePage}},
+			{tag: tagImageWidth, typ: tiffLong, values: []uint32{w}},
+			{tag: tagImageLength, typ: tiffLong, values: []uint32{h}},
+			{tag: tagBitsPerSample, typ: tiffShort, values: []uint32{8, 8, 8, 8}},
+			{tag: tagCompression, typ: tiffShort, values: []uint32{compressionDeflate}},
+			{tag: tagPhotometric, typ: tiffShort, values: []uint32{photometricRGB}},
+			{tag: tagImageDescription, typ: tiffASCII, ascii: page.Caption(i, len(pages))},
+			{tag: tagStripOffsets, typ: tiffLong, values: []uint32{0}},
+			{tag: tagSamplesPerPixel, typ: tiffShort, values: []uint32{4}},
+			{tag: tagRowsPerStrip, typ: tiffLong, values: []uint32{h}},
+			{tag: tagStripByteCounts, typ: tiffLong, values: []uint32{uint32(len(pixels))}},
+			{tag: tagPlanarConfig, typ: tiffShort, values: []uint32{1}},
+			{tag: tagPageNumber, typ: tiffShort, values: []uint32{uint32(i), uint32(len(pages))}},
+			{tag: tagExtraSamples, typ: tiffShort, values: []uint32{extraUnassociatedAlpha}},
+		}
+		sort.Slice(entries, func(a, b int) bool { return entries[a].tag < entries[b].tag })
+
+		// Values longer than 4 bytes follow the directory, aligned on words
+		dirSize := 2 + 12*len(entries) + 4
+		extra := offset + dirSize
+		var extras []byte
+		extraOffsets := make([]uint32, len(entries))
+		for j, e := range entries {
+			if e.size() > 4 {
+				extraOffsets[j] = uint32(extra + len(extras))
+				extras = append(extras, e.encode()...)
+				if len(extras)%2 == 1 {
+					extras = append(extras, 0)
+				}
+			}
+		}
+		dataOffset := extra + len(extras)
+		next := 0
+		if i+1 < len(pages) {
+			next = dataOffset + len(pixels)
+			next += next % 2
+		}
+
+		dir := binary.LittleEndian.AppendUint16(nil, uint16(len(entries)))
+		for j, e := range entries {
+			if e.tag == tagStripOffsets {
+				e.values = []uint32{uint32(dataOffset)}
+			}
+			dir = binary.LittleEndian.AppendUint16(dir, e.tag)
+			dir = binary.LittleEndian.AppendUint16(dir, e.typ)
+			dir = binary.LittleEndian.AppendUint32(dir, uint32(e.count()))
+			if e.size() > 4 {
+				dir = binary.LittleEndian.AppendUint32(dir, extraOffsets[j])
+			} else {
+				value := make([]byte, 4)
+				copy(value, e.encode())
+				dir = append(dir, value...)
+			}
+		}
+		dir = binary.LittleEndian.AppendUint32(dir, uint32(next))
+
+		bw.Write(dir)
+		bw.Write(extras)
+		bw.Write(pixels)
+		offset = dataOffset + len(pixels)
+		if offset%2 == 1 {
+			bw.WriteByte(0)
+			offset++
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("error writing TIFF: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package flipbook
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// tiffPage is a page read back from a TIFF
+type tiffPage struct {
+	fields map[uint16][]byte
+	pixels []byte
+}
+
+// readTIFF reads the pages of a little endian TIFF with deflated strips
+func readTIFF(t *testing.T, data []byte) []tiffPage {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte{'I', 'I', 42, 0}) {
+		t.Fatal("not a little endian TIFF")
+	}
+	var pages []tiffPage
+	for offset := binary.LittleEndian.Uint32(data[4:]); offset != 0; {
+		page := tiffPage{fields: make(map[uint16][]byte)}
+		n := int(binary.LittleEndian.Uint16(data[offset:]))
+		for i := 0; i < n; i++ {
+			entry := data[int(offset)+2+12*i:]
+			tag, typ, count := binary.LittleEndian.Uint16(entry), binary.LittleEndian.Uint16(entry[2:]), binary.LittleEndian.Uint32(entry[4:])
+			size := int(count)
+			switch typ {
+			case tiffShort:
+				size *= 2
+			case tiffLong:
+				size *= 4
+			}
+			value := entry[8:12]
+			if size > 4 {
+				value = data[binary.LittleEndian.Uint32(entry[8:]):]
+			}
+			page.fields[tag] = value[:size]
+		}
+		strip := data[binary.LittleEndian.Uint32(page.fields[tagStripOffsets]):]
+		r, err := zlib.NewReader(bytes.NewReader(strip[:binary.LittleEndian.Uint32(page.fields[tagStripByteCounts])]))
+		if err != nil {
+			t.Fatalf("Failed to inflate strip: %v", err)
+		}
+		if page.pixels, err = io.ReadAll(r); err != nil {
+			t.Fatalf("Failed to inflate strip: %v", err)
+		}
+		pages = append(pages, page)
+		offset = binary.LittleEndian.Uint32(data[int(offset)+2+12*n:])
+	}
+	return pages
+}
+
+func TestWriteTIFF(t *testing.T) {
+	want := testPages(3)
+	var buf bytes.Buffer
+	if err := WriteTIFF(&buf, want); err != nil {
+		t.Fatalf("WriteTIFF() error = %v", err)
+	}
+
+	pages := readTIFF(t, buf.Bytes())
+	if len(pages) != len(want) {
+		t.Fatalf("WriteTIFF() wrote %d pages, want %d", len(pages), len(want))
+	}
+	for i, page := range pages {
+		if got := binary.LittleEndian.Uint32(page.fields[tagImageWidth]); got != 30 {
+			t.Errorf("page %d width = %d, want 30", i, got)
+		}
+		if got := binary.LittleEndian.Uint16(page.fields[tagPageNumber]); int(got) != i {
+			t.Errorf("page %d number = %d", i, got)
+		}
+		if got, caption := string(page.fields[tagImageDescription]), want[i].Caption(i, len(want))+"\x00"; got != caption {
+			t.Errorf("page %d description = %q, want %q", i, got, caption)
+		}
+		if !bytes.Equal(page.pixels, nrgba(want[i].Image).Pix) {
+			t.Errorf("page %d pixels differ from the frame", i)
+		}
+	}
+}
+
+func TestWriteTIFFNoPages(t *testing.T) {
+	if err := WriteTIFF(&bytes.Buffer{}, nil); err == nil {
+		t.Error("WriteTIFF() error = nil for no pages")
+	}
+}
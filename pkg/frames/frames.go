@@ -0,0 +1,42 @@
+package frames
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+// Func draws the frame at time t, with t in [0, 1)
+type Func func(t float64) image.Image
+
+// Generate calls fn for count evenly spaced times. t is i/count so the last
+// frame leads back into the first one, which makes seamless loops easy.
+func Generate(fn Func, count int) ([]converter.Frame, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("no frame function specified")
+	}
+	if count <= 0 {
+		return nil, fmt.Errorf("frame count must be positive")
+	}
+
+	frames := make([]converter.Frame, count)
+	for i := range frames {
+		img := fn(float64(i) / float64(count))
+		if img == nil {
+			return nil, fmt.Errorf("frame function returned no image for frame %d", i)
+		}
+		frames[i] = converter.Frame{Image: img}
+	}
+	return frames, nil
+}
+
+// Render generates count frames with fn and writes them as a GIF to outputFile.
+// Every frame is shown for opts.Delay milliseconds.
+func Render(fn Func, count int, outputFile string, opts converter.Options) (*converter.Report, error) {
+	frames, err := Generate(fn, count)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertFrames(frames, outputFile, opts)
+}
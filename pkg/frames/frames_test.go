@@ -0,0 +1,79 @@
+package frames
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+// bar draws a bar whose width follows t
+func bar(t float64) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < int(t*20); x++ {
+			img.Set(x, y, color.RGBA{0, 128, 255, 255})
+		}
+	}
+	return img
+}
+
+func TestGenerate(t *testing.T) {
+	var times []float64
+	frames, err := Generate(func(t float64) image.Image {
+		times = append(times, t)
+		return bar(t)
+	}, 4)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(frames) != 4 {
+		t.Fatalf("Generate() got %d frames, want 4", len(frames))
+	}
+	want := []float64{0, 0.25, 0.5, 0.75}
+	for i := range want {
+		if times[i] != want[i] {
+			t.Errorf("frame %d t = %v, want %v", i, times[i], want[i])
+		}
+	}
+
+	tests := []struct {
+		name  string
+		fn    Func
+		count int
+	}{
+		{"nil function", nil, 2},
+		{"zero count", bar, 0},
+		{"nil image", func(float64) image.Image { return nil }, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.fn, tt.count); err == nil {
+				t.Error("Generate() expected error")
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputFile := filepath.Join(tempDir, "bar.gif")
+	report, err := Render(bar, 5, outputFile, converter.Options{Delay: 50})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if report.Frames != 5 || report.Width != 20 || report.Height != 4 {
+		t.Errorf("Render() report = %+v, want 5 frames of 20x4", report)
+	}
+	if _, err := os.Stat(outputFile); err != nil {
+		t.Errorf("output file not created: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package gamut
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Gamut is an RGB color space with D65 white point
+type Gamut string
+
+const (
+	// SRGB is the color space of the web, which GIFs are shown in
+	SRGB Gamut = "srgb"
+	// P3 is Display P3, the wide gamut of Apple displays, with the sRGB transfer curve
+	P3 Gamut = "p3"
+	// Rec2020 is the ITU-R BT.2020 gamut of HDR video
+	Rec2020 Gamut = "rec2020"
+)
+
+// Gamuts lists every supported gamut, from the narrowest to the widest
+var Gamuts = []Gamut{SRGB, P3, Rec2020}
+
+// toXYZ holds the matrices converting linear RGB to CIE XYZ
+var toXYZ = map[Gamut][3][3]float64{
+	SRGB: {
+		{0.4124564, 0.3575761, 0.1804375},
+		{0.2126729, 0.7151522, 0.0721750},
+		{0.0193339, 0.1191920, 0.9503041},
+	},
+	P3: {
+		{0.4865709, 0.2656677, 0.1982173},
+		{0.2289746, 0.6917385, 0.0792869},
+		{0.0000000, 0.0451134, 1.0439444},
+	},
+	Rec2020: {
+		{0.6369580, 0.1446169, 0.1688810},
+		{0.2627002, 0.6779981, 0.0593017},
+		{0.0000000, 0.0280727, 1.0609851},
+	},
+}
+
+// clipTolerance absorbs the rounding of 8-bit colors on the gamut boundary
+const clipTolerance = 1e-3
+
+// Parse returns the gamut with the given name
+func Parse(name string) (Gamut, error) {
+	if _, ok := toXYZ[Gamut(name)]; ok {
+		return Gamut(name), nil
+	}
+	return "", fmt.Errorf("unknown gamut %q: expected %s, %s or %s", name, SRGB, P3, Rec2020)
+}
+
+// Proof returns c, encoded in the source gamut, as it appears once mapped to
+// the target gamut, clipping the colors the target cannot show, and encoded
+// back in the source gamut. It also reports whether c was clipped. Unknown
+// gamuts leave c unchanged.
+func Proof(c color.Color, source, target Gamut) (color.RGBA, bool) {
+	nrgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	from, okFrom := toXYZ[source]
+	to, okTo := toXYZ[target]
+	if !okFrom || !okTo || source == target {
+		return color.RGBAModel.Convert(nrgba).(color.RGBA), false
+	}
+
+	lin := [3]float64{decode(nrgba.R, source), decode(nrgba.G, source), decode(nrgba.B, source)}
+	mapped := multiply(invert(to), multiply(from, lin))
+	clipped := false
+	for i, v := range mapped {
+		if v < -clipTolerance || v > 1+clipTolerance {
+			clipped = true
+		}
+		mapped[i] = math.Max(0, math.Min(1, v))
+	}
+	back := multiply(invert(from), multiply(to, mapped))
+
+	result := color.NRGBA{encode(back[0], source), encode(back[1], source), encode(back[2], source), nrgba.A}
+	return color.RGBAModel.Convert(result).(color.RGBA), clipped
+}
+
+// multiply returns m times v
+func multiply(m [3][3]float64, v [3]float64) [3]float64 {
+	var out [3]float64
+	for i := range out {
+		out[i] = m[i][0]*v[0] + m[i][1]*v[1] + m[i][2]*v[2]
+	}
+	return out
+}
+
+// invert returns the inverse of m
+func invert(m [3][3]float64) [3][3]float64 {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	var inv [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			// Cofactor of m[j][i], transposed
+			a, b := (j+1)%3, (j+2)%3
+			c, d := (i+1)%3, (i+2)%3
+			inv[i][j] = (m[a][c]*m[b][d] - m[a][d]*m[b][c]) / det
+		}
+	}
+	return inv
+}
+
+// decode returns the linear light of an encoded channel of gamut g
+func decode(v uint8, g Gamut) float64 {
+	c := float64(v) / 255
+	if g == Rec2020 {
+		if c < 4.5*0.018053968510807 {
+			return c / 4.5
+		}
+		return math.Pow((c+0.09929682680944)/1.09929682680944, 1/0.45)
+	}
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// encode returns the encoded channel of gamut g for linear light v
+func encode(v float64, g Gamut) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if g == Rec2020 {
+		if v < 0.018053968510807 {
+			v *= 4.5
+		} else {
+			v = 1.09929682680944*math.Pow(v, 0.45) - 0.09929682680944
+		}
+	} else if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(v * 255))
+}
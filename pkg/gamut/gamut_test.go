@@ -0,0 +1,74 @@
+package gamut
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Gamut
+		wantErr bool
+	}{
+		{name: "srgb", want: SRGB},
+		{name: "p3", want: P3},
+		{name: "rec2020", want: Rec2020},
+		{name: "adobe-rgb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProof(t *testing.T) {
+	tests := []struct {
+		name        string
+		c           color.RGBA
+		source      Gamut
+		target      Gamut
+		wantClipped bool
+		wantSame    bool
+	}{
+		{name: "same gamut", c: color.RGBA{255, 0, 0, 255}, source: P3, target: P3, wantSame: true},
+		{name: "gray survives", c: color.RGBA{128, 128, 128, 255}, source: P3, target: SRGB, wantSame: true},
+		{name: "p3 red is clipped in srgb", c: color.RGBA{255, 0, 0, 255}, source: P3, target: SRGB, wantClipped: true},
+		{name: "srgb red fits in p3", c: color.RGBA{255, 0, 0, 255}, source: SRGB, target: P3, wantSame: true},
+		{name: "muted p3 color fits in srgb", c: color.RGBA{180, 120, 110, 255}, source: P3, target: SRGB, wantSame: true},
+		{name: "rec2020 green is clipped in p3", c: color.RGBA{0, 255, 0, 255}, source: Rec2020, target: P3, wantClipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clipped := Proof(tt.c, tt.source, tt.target)
+			if clipped != tt.wantClipped {
+				t.Errorf("Proof() clipped = %v, want %v", clipped, tt.wantClipped)
+			}
+			if same := distance(got, tt.c) <= 1; same != tt.wantSame {
+				t.Errorf("Proof() = %v from %v, want unchanged %v", got, tt.c, tt.wantSame)
+			}
+		})
+	}
+}
+
+// distance returns the largest channel difference of a and b
+func distance(a, b color.RGBA) int {
+	d := 0
+	for _, pair := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		diff := int(pair[0]) - int(pair[1])
+		if diff < 0 {
+			diff = -diff
+		}
+		d = max(d, diff)
+	}
+	return d
+}
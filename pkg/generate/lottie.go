@@ -0,0 +1,86 @@
+package generate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/lottie"
+)
+
+// TestPatternLottie returns the test pattern of opts as a vector Lottie
+// animation, a frame shown every delay
+func TestPatternLottie(opts TestPatternOptions, delay time.Duration) (*lottie.Animation, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if delay <= 0 {
+		return nil, fmt.Errorf("delay must be positive")
+	}
+
+	bounds := image.Rect(0, 0, opts.Width, opts.Height)
+	anim := lottie.New("test pattern "+opts.Pattern, opts.Width, opts.Height, time.Duration(opts.Frames)*delay, float64(time.Second)/float64(delay))
+	// scroll moves a layer drawn twice side by side left by frame/frames of the width
+	scroll := func(layer *lottie.Layer) {
+		keys := make([]lottie.PositionKey, opts.Frames)
+		for i := range keys {
+			keys[i] = lottie.PositionKey{At: time.Duration(i) * delay, Offset: image.Pt(-i*opts.Width/opts.Frames, 0)}
+		}
+		anim.Move(layer, keys)
+	}
+
+	switch opts.Pattern {
+	case PatternSolid:
+		anim.AddShapes("solid", []lottie.Shape{{Rect: bounds, Color: opts.Color}})
+	case PatternBars:
+		var shapes []lottie.Shape
+		for _, offset := range []int{0, opts.Width} {
+			for i, c := range barColors {
+				// The pixels of bar i, as drawBars maps them
+				left := (i*opts.Width + len(barColors) - 1) / len(barColors)
+				right := ((i+1)*opts.Width + len(barColors) - 1) / len(barColors)
+				shapes = append(shapes, lottie.Shape{Rect: image.Rect(offset+left, 0, offset+right, opts.Height), Color: c})
+			}
+		}
+		scroll(anim.AddShapes("bars", shapes))
+	case PatternGradient:
+		var shapes []lottie.Shape
+		for _, offset := range []int{0, opts.Width} {
+			shapes = append(shapes, lottie.Shape{Rect: bounds.Add(image.Pt(offset, 0)), Color: color.Black, GradientTo: color.White})
+		}
+		scroll(anim.AddShapes("gradient", shapes))
+	case PatternCheckerboard:
+		anim.AddShapes("background", []lottie.Shape{{Rect: bounds, Color: color.Black}})
+		// The white squares of even and odd frames alternate
+		for parity, name := range []string{"even squares", "odd squares"} {
+			var shapes []lottie.Shape
+			for y := 0; y < opts.Height; y += opts.CellSize {
+				for x := 0; x < opts.Width; x += opts.CellSize {
+					if (x/opts.CellSize+y/opts.CellSize+parity)%2 == 0 {
+						shapes = append(shapes, lottie.Shape{Rect: image.Rect(x, y, x+opts.CellSize, y+opts.CellSize).Intersect(bounds), Color: color.White})
+					}
+				}
+			}
+			keys := make([]lottie.OpacityKey, opts.Frames)
+			for i := range keys {
+				keys[i] = lottie.OpacityKey{At: time.Duration(i) * delay}
+				if i%2 == parity {
+					keys[i].Opacity = 100
+				}
+			}
+			anim.Fade(anim.AddShapes(name, shapes), keys)
+		}
+	case PatternBox:
+		anim.AddShapes("background", []lottie.Shape{{Rect: bounds, Color: boxBackground}})
+		start := boxRect(bounds, 0, opts.Frames)
+		box := anim.AddShapes("box", []lottie.Shape{{Rect: start, Color: opts.Color}})
+		keys := make([]lottie.PositionKey, opts.Frames)
+		for i := range keys {
+			keys[i] = lottie.PositionKey{At: time.Duration(i) * delay, Offset: boxRect(bounds, i, opts.Frames).Min.Sub(start.Min)}
+		}
+		anim.Move(box, keys)
+	}
+	return anim, nil
+}
@@ -0,0 +1,122 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"testing"
+	"time"
+)
+
+// lottieLayer is the part of a Lottie layer checked by the tests
+type lottieLayer struct {
+	Name      string            `json:"nm"`
+	Shapes    []json.RawMessage `json:"shapes"`
+	Transform struct {
+		Opacity  json.RawMessage `json:"o"`
+		Position struct {
+			Animated int             `json:"a"`
+			Value    json.RawMessage `json:"k"`
+		} `json:"p"`
+	} `json:"ks"`
+}
+
+func TestTestPatternLottie(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       TestPatternOptions
+		wantLayers []string
+		// wantShapes is the number of rectangles of the top layer
+		wantShapes int
+		wantMoving bool
+	}{
+		{name: "solid", opts: TestPatternOptions{Pattern: PatternSolid}, wantLayers: []string{"solid"}, wantShapes: 1},
+		{name: "bars", opts: TestPatternOptions{Pattern: PatternBars, Width: 80, Height: 10, Frames: 8}, wantLayers: []string{"bars"}, wantShapes: 16, wantMoving: true},
+		{name: "gradient", opts: TestPatternOptions{Pattern: PatternGradient, Width: 64, Height: 4, Frames: 4}, wantLayers: []string{"gradient"}, wantShapes: 2, wantMoving: true},
+		{name: "checkerboard", opts: TestPatternOptions{Pattern: PatternCheckerboard, Width: 32, Height: 16, Frames: 2, CellSize: 8}, wantLayers: []string{"odd squares", "even squares", "background"}, wantShapes: 4},
+		{name: "box", opts: TestPatternOptions{Pattern: PatternBox, Width: 40, Height: 20, Frames: 4}, wantLayers: []string{"box", "background"}, wantShapes: 1, wantMoving: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim, err := TestPatternLottie(tt.opts, 100*time.Millisecond)
+			if err != nil {
+				t.Fatalf("TestPatternLottie() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := anim.Write(&buf); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			var got struct {
+				FrameRate float64       `json:"fr"`
+				OutPoint  float64       `json:"op"`
+				Layers    []lottieLayer `json:"layers"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to decode animation: %v", err)
+			}
+
+			opts, _ := tt.opts.withDefaults()
+			if got.FrameRate != 10 || got.OutPoint != float64(opts.Frames) {
+				t.Errorf("frame rate and out point = %v, %v, want a frame per pattern frame", got.FrameRate, got.OutPoint)
+			}
+			if len(got.Layers) != len(tt.wantLayers) {
+				t.Fatalf("got %d layers, want %v", len(got.Layers), tt.wantLayers)
+			}
+			for i, layer := range got.Layers {
+				if layer.Name != tt.wantLayers[i] {
+					t.Errorf("layer %d = %q, want %q", i, layer.Name, tt.wantLayers[i])
+				}
+			}
+			top := got.Layers[0]
+			if len(top.Shapes) != tt.wantShapes {
+				t.Errorf("top layer has %d shapes, want %d", len(top.Shapes), tt.wantShapes)
+			}
+			if moving := top.Transform.Position.Animated == 1; moving != tt.wantMoving {
+				t.Errorf("top layer moving = %v, want %v", moving, tt.wantMoving)
+			}
+		})
+	}
+}
+
+func TestTestPatternLottieBox(t *testing.T) {
+	opts := TestPatternOptions{Pattern: PatternBox, Width: 40, Height: 20, Frames: 4}
+	anim, err := TestPatternLottie(opts, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TestPatternLottie() error = %v", err)
+	}
+	var buf bytes.Buffer
+	anim.Write(&buf)
+	var got struct {
+		Layers []lottieLayer `json:"layers"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode animation: %v", err)
+	}
+	var keys []struct {
+		Time  float64   `json:"t"`
+		Value []float64 `json:"s"`
+	}
+	if err := json.Unmarshal(got.Layers[0].Transform.Position.Value, &keys); err != nil {
+		t.Fatalf("Failed to decode box keyframes: %v", err)
+	}
+
+	// The box is where the frames draw it
+	bounds := image.Rect(0, 0, opts.Width, opts.Height)
+	start := boxRect(bounds, 0, opts.Frames)
+	for i, key := range keys {
+		want := boxRect(bounds, i, opts.Frames).Min.Sub(start.Min)
+		if key.Time != float64(i) || key.Value[0] != float64(want.X) || key.Value[1] != float64(want.Y) {
+			t.Errorf("keyframe %d = %+v, want offset %v at frame %d", i, key, want, i)
+		}
+	}
+}
+
+func TestTestPatternLottieErrors(t *testing.T) {
+	if _, err := TestPatternLottie(TestPatternOptions{Pattern: "zebra"}, time.Second); err == nil {
+		t.Error("TestPatternLottie() error = nil for an invalid pattern")
+	}
+	if _, err := TestPatternLottie(TestPatternOptions{Pattern: PatternBars}, 0); err == nil {
+		t.Error("TestPatternLottie() error = nil without a delay")
+	}
+}
@@ -0,0 +1,191 @@
+package generate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QR animation modes
+const (
+	// QRFinder keeps a single code and cycles the colors of its finder patterns
+	QRFinder = "finder"
+	// QRParts splits the data over one code per frame, each prefixed with "i/n:"
+	QRParts = "parts"
+)
+
+const (
+	// DefaultQRFrames is the number of frames used when QROptions.Frames is unset
+	DefaultQRFrames = 12
+	// DefaultQRScale is the size of a module in pixels used when QROptions.Scale is unset
+	DefaultQRScale = 8
+	// finderSize is the width of a finder pattern in modules
+	finderSize = 7
+)
+
+// QROptions holds the settings used by QR
+type QROptions struct {
+	// Frames is the number of frames, or of parts in QRParts mode
+	Frames int
+	// Mode is QRFinder or QRParts
+	Mode string
+	// Scale is the size of a module in pixels
+	Scale int
+	// Level is the error recovery level: L, M, Q or H
+	Level string
+}
+
+// QR generates an animated QR code for data
+func QR(data string, opts QROptions) ([]converter.Frame, error) {
+	if data == "" {
+		return nil, fmt.Errorf("no data to encode")
+	}
+	if opts.Frames == 0 {
+		opts.Frames = DefaultQRFrames
+	}
+	if opts.Frames < 0 {
+		return nil, fmt.Errorf("frame count must be positive")
+	}
+	if opts.Scale == 0 {
+		opts.Scale = DefaultQRScale
+	}
+	if opts.Scale < 0 {
+		return nil, fmt.Errorf("scale must be positive")
+	}
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Mode {
+	case "", QRFinder:
+		code, err := qrcode.New(data, level)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding QR code: %v", err)
+		}
+		bitmap := code.Bitmap()
+		frames := make([]converter.Frame, opts.Frames)
+		for i := range frames {
+			finder := hsv(float64(i)/float64(opts.Frames), 0.8, 0.55)
+			frames[i] = converter.Frame{Image: drawQR(bitmap, opts.Scale, finder)}
+		}
+		return frames, nil
+	case QRParts:
+		parts := splitParts(data, opts.Frames)
+		payloads := make([]string, len(parts))
+		for i, part := range parts {
+			payloads[i] = fmt.Sprintf("%d/%d:%s", i+1, len(parts), part)
+		}
+
+		// Encode every part with the same version so all frames have the same size
+		version := 1
+		for _, payload := range payloads {
+			code, err := qrcode.New(payload, level)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding QR code: %v", err)
+			}
+			version = max(version, code.VersionNumber)
+		}
+		frames := make([]converter.Frame, len(payloads))
+		for i, payload := range payloads {
+			code, err := qrcode.NewWithForcedVersion(payload, version, level)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding QR code: %v", err)
+			}
+			frames[i] = converter.Frame{Image: drawQR(code.Bitmap(), opts.Scale, color.Black)}
+		}
+		return frames, nil
+	}
+	return nil, fmt.Errorf("invalid QR mode %q: expected %s or %s", opts.Mode, QRFinder, QRParts)
+}
+
+// parseLevel converts an L/M/Q/H recovery level
+func parseLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "", "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("invalid recovery level %q: expected L, M, Q or H", level)
+}
+
+// splitParts splits data into at most n chunks of similar size, never splitting a rune
+func splitParts(data string, n int) []string {
+	runes := []rune(data)
+	n = min(n, len(runes))
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = string(runes[i*len(runes)/n : (i+1)*len(runes)/n])
+	}
+	return parts
+}
+
+// drawQR renders a QR bitmap (including its quiet zone), drawing the finder patterns in finder
+func drawQR(bitmap [][]bool, scale int, finder color.Color) *image.RGBA {
+	size := len(bitmap)
+	img := image.NewRGBA(image.Rect(0, 0, size*scale, size*scale))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// The quiet zone is as wide on every side
+	border := 0
+	for border < size && !bitmap[border][border] {
+		border++
+	}
+	inFinder := func(x, y int) bool {
+		far := size - border - finderSize
+		inX := func(v, from int) bool { return v >= from && v < from+finderSize }
+		return (inX(x, border) && inX(y, border)) || (inX(x, far) && inX(y, border)) || (inX(x, border) && inX(y, far))
+	}
+
+	black := image.NewUniform(color.Black)
+	finderColor := image.NewUniform(finder)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			src := black
+			if inFinder(x, y) {
+				src = finderColor
+			}
+			rect := image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale)
+			draw.Draw(img, rect, src, image.Point{}, draw.Src)
+		}
+	}
+	return img
+}
+
+// hsv converts a hue in [0, 1), saturation and value to a color
+func hsv(h, s, v float64) color.RGBA {
+	h = math.Mod(h, 1) * 6
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g, b = c, x, 0
+	case 1:
+		r, g, b = x, c, 0
+	case 2:
+		r, g, b = 0, c, x
+	case 3:
+		r, g, b = 0, x, c
+	case 4:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := v - c
+	return color.RGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 255}
+}
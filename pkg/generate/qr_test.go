@@ -0,0 +1,87 @@
+package generate
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestQRFinder(t *testing.T) {
+	frames, err := QR("https://github.com/jparrill/go-togif", QROptions{Frames: 4, Scale: 2})
+	if err != nil {
+		t.Fatalf("QR() error = %v", err)
+	}
+	if len(frames) != 4 {
+		t.Fatalf("QR() got %d frames, want 4", len(frames))
+	}
+
+	// The quiet zone is white and the finder pattern color changes between frames
+	first, second := frames[0].Image.(*image.RGBA), frames[1].Image.(*image.RGBA)
+	if c := first.RGBAAt(0, 0); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("quiet zone = %v, want white", c)
+	}
+	finder := image.Pt(4*2, 4*2)
+	if first.RGBAAt(finder.X, finder.Y) == second.RGBAAt(finder.X, finder.Y) {
+		t.Error("finder pattern color did not change between frames")
+	}
+	if first.Bounds() != second.Bounds() {
+		t.Errorf("frame sizes differ: %v and %v", first.Bounds(), second.Bounds())
+	}
+}
+
+func TestQRParts(t *testing.T) {
+	data := strings.Repeat("0123456789", 20)
+	frames, err := QR(data, QROptions{Frames: 3, Mode: QRParts, Level: "L"})
+	if err != nil {
+		t.Fatalf("QR() error = %v", err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("QR() got %d frames, want 3", len(frames))
+	}
+	for i, frame := range frames {
+		if frame.Image.Bounds() != frames[0].Image.Bounds() {
+			t.Errorf("frame %d bounds = %v, want %v", i, frame.Image.Bounds(), frames[0].Image.Bounds())
+		}
+	}
+}
+
+func TestQRErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts QROptions
+	}{
+		{"no data", "", QROptions{}},
+		{"invalid mode", "x", QROptions{Mode: "spin"}},
+		{"invalid level", "x", QROptions{Level: "Z"}},
+		{"negative frames", "x", QROptions{Frames: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := QR(tt.data, tt.opts); err == nil {
+				t.Error("QR() expected error")
+			}
+		})
+	}
+}
+
+func TestSplitParts(t *testing.T) {
+	tests := []struct {
+		data string
+		n    int
+		want []string
+	}{
+		{"abcdef", 3, []string{"ab", "cd", "ef"}},
+		{"abc", 5, []string{"a", "b", "c"}},
+		{"héllo", 2, []string{"hé", "llo"}},
+	}
+
+	for _, tt := range tests {
+		got := splitParts(tt.data, tt.n)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("splitParts(%q, %d) = %q, want %q", tt.data, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package generate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+)
+
+// Test patterns
+const (
+	// PatternSolid fills every frame with a single color
+	PatternSolid = "solid"
+	// PatternBars draws the 8 classic color bars, scrolling horizontally
+	PatternBars = "bars"
+	// PatternGradient draws a horizontal gray ramp, scrolling horizontally
+	PatternGradient = "gradient"
+	// PatternCheckerboard draws a checkerboard whose squares invert every frame
+	PatternCheckerboard = "checkerboard"
+	// PatternBox moves a box across the frame and back
+	PatternBox = "box"
+)
+
+// Patterns lists the available test patterns
+var Patterns = []string{PatternSolid, PatternBars, PatternGradient, PatternCheckerboard, PatternBox}
+
+const (
+	// DefaultPatternWidth and DefaultPatternHeight are used when the size is unset
+	DefaultPatternWidth  = 320
+	DefaultPatternHeight = 240
+	// DefaultPatternFrames is the number of frames used when TestPatternOptions.Frames is unset
+	DefaultPatternFrames = 10
+	// DefaultCellSize is the checkerboard square size used when TestPatternOptions.CellSize is unset
+	DefaultCellSize = 16
+)
+
+// boxBackground is the color around the moving box
+var boxBackground = color.RGBA{64, 64, 64, 255}
+
+// barColors are the classic 75% color bars, left to right
+var barColors = []color.RGBA{
+	{191, 191, 191, 255}, // gray
+	{191, 191, 0, 255},   // yellow
+	{0, 191, 191, 255},   // cyan
+	{0, 191, 0, 255},     // green
+	{191, 0, 191, 255},   // magenta
+	{191, 0, 0, 255},     // red
+	{0, 0, 191, 255},     // blue
+	{0, 0, 0, 255},       // black
+}
+
+// TestPatternOptions holds the settings used by TestPattern
+type TestPatternOptions struct {
+	// Pattern is one of Patterns
+	Pattern string
+	Width   int
+	Height  int
+	Frames  int
+	// Color is used by the solid pattern and for the moving box, white when unset
+	Color color.Color
+	// CellSize is the size of the checkerboard squares in pixels
+	CellSize int
+}
+
+// withDefaults returns opts with the unset settings filled in, checking them
+func (opts TestPatternOptions) withDefaults() (TestPatternOptions, error) {
+	if opts.Width == 0 {
+		opts.Width = DefaultPatternWidth
+	}
+	if opts.Height == 0 {
+		opts.Height = DefaultPatternHeight
+	}
+	if opts.Frames == 0 {
+		opts.Frames = DefaultPatternFrames
+	}
+	if opts.CellSize == 0 {
+		opts.CellSize = DefaultCellSize
+	}
+	if opts.Color == nil {
+		opts.Color = color.White
+	}
+	if opts.Width < 0 || opts.Height < 0 || opts.Frames < 0 || opts.CellSize < 0 {
+		return opts, fmt.Errorf("size, frame count and cell size must be positive")
+	}
+	if !slices.Contains(Patterns, opts.Pattern) {
+		return opts, fmt.Errorf("invalid pattern %q: expected one of %v", opts.Pattern, Patterns)
+	}
+	return opts, nil
+}
+
+// TestPattern generates a deterministic test pattern animation
+func TestPattern(opts TestPatternOptions) ([]converter.Frame, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	var render func(img *image.RGBA, frame int)
+	switch opts.Pattern {
+	case PatternSolid:
+		render = func(img *image.RGBA, frame int) { fill(img, img.Bounds(), opts.Color) }
+	case PatternBars:
+		render = func(img *image.RGBA, frame int) { drawBars(img, frame, opts.Frames) }
+	case PatternGradient:
+		render = func(img *image.RGBA, frame int) { drawGradient(img, frame, opts.Frames) }
+	case PatternCheckerboard:
+		render = func(img *image.RGBA, frame int) { drawCheckerboard(img, frame, opts.CellSize) }
+	case PatternBox:
+		render = func(img *image.RGBA, frame int) { drawBox(img, frame, opts.Frames, opts.Color) }
+	}
+
+	frames := make([]converter.Frame, opts.Frames)
+	for i := range frames {
+		img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+		render(img, i)
+		frames[i] = converter.Frame{Image: img}
+	}
+	return frames, nil
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawBars draws the color bars shifted by frame/frames of the width
+func drawBars(img *image.RGBA, frame, frames int) {
+	width := img.Bounds().Dx()
+	shift := frame * width / frames
+	for x := 0; x < width; x++ {
+		c := barColors[((x+shift)%width)*len(barColors)/width]
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawGradient draws a black to white ramp shifted by frame/frames of the width
+func drawGradient(img *image.RGBA, frame, frames int) {
+	width := img.Bounds().Dx()
+	shift := frame * width / frames
+	for x := 0; x < width; x++ {
+		v := uint8(((x + shift) % width) * 255 / max(1, width-1))
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+}
+
+// drawCheckerboard draws black and white squares, inverted on odd frames
+func drawCheckerboard(img *image.RGBA, frame, cell int) {
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < img.Bounds().Dx(); x++ {
+			c := color.RGBA{0, 0, 0, 255}
+			if (x/cell+y/cell+frame)%2 == 0 {
+				c = color.RGBA{255, 255, 255, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawBox moves a box from the left edge to the right edge and back over the frames
+func drawBox(img *image.RGBA, frame, frames int, c color.Color) {
+	bounds := img.Bounds()
+	fill(img, bounds, boxBackground)
+	fill(img, boxRect(bounds, frame, frames), c)
+}
+
+// boxRect returns where drawBox draws the box in the given frame
+func boxRect(bounds image.Rectangle, frame, frames int) image.Rectangle {
+	size := max(1, min(bounds.Dx(), bounds.Dy())/4)
+	travel := bounds.Dx() - size
+	// Position along a there-and-back trip, in [0, 1]
+	progress := 0.0
+	if frames > 1 {
+		progress = float64(frame) / float64(frames) * 2
+		if progress > 1 {
+			progress = 2 - progress
+		}
+	}
+	x := int(progress * float64(travel))
+	y := (bounds.Dy() - size) / 2
+	return image.Rect(x, y, x+size, y+size)
+}
@@ -0,0 +1,107 @@
+package generate
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTestPattern(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  TestPatternOptions
+		check func(t *testing.T, frames []*image.RGBA)
+	}{
+		{
+			name: "solid",
+			opts: TestPatternOptions{Pattern: PatternSolid, Color: color.RGBA{255, 0, 0, 255}},
+			check: func(t *testing.T, frames []*image.RGBA) {
+				if c := frames[0].RGBAAt(10, 10); c != (color.RGBA{255, 0, 0, 255}) {
+					t.Errorf("solid color = %v, want red", c)
+				}
+			},
+		},
+		{
+			name: "bars",
+			opts: TestPatternOptions{Pattern: PatternBars, Width: 80, Height: 10, Frames: 8},
+			check: func(t *testing.T, frames []*image.RGBA) {
+				if c := frames[0].RGBAAt(0, 0); c != barColors[0] {
+					t.Errorf("first bar = %v, want %v", c, barColors[0])
+				}
+				// Each frame scrolls by one bar
+				if c := frames[1].RGBAAt(0, 0); c != barColors[1] {
+					t.Errorf("first bar of frame 1 = %v, want %v", c, barColors[1])
+				}
+			},
+		},
+		{
+			name: "gradient",
+			opts: TestPatternOptions{Pattern: PatternGradient, Width: 256, Height: 4, Frames: 2},
+			check: func(t *testing.T, frames []*image.RGBA) {
+				if c := frames[0].RGBAAt(0, 0); c.R != 0 {
+					t.Errorf("gradient start = %v, want black", c)
+				}
+				if c := frames[0].RGBAAt(255, 0); c.R != 255 {
+					t.Errorf("gradient end = %v, want white", c)
+				}
+			},
+		},
+		{
+			name: "checkerboard",
+			opts: TestPatternOptions{Pattern: PatternCheckerboard, Width: 32, Height: 32, Frames: 2, CellSize: 8},
+			check: func(t *testing.T, frames []*image.RGBA) {
+				if frames[0].RGBAAt(0, 0) == frames[0].RGBAAt(8, 0) {
+					t.Error("adjacent squares have the same color")
+				}
+				if frames[0].RGBAAt(0, 0) == frames[1].RGBAAt(0, 0) {
+					t.Error("squares did not invert between frames")
+				}
+			},
+		},
+		{
+			name: "box",
+			opts: TestPatternOptions{Pattern: PatternBox, Width: 100, Height: 40, Frames: 4},
+			check: func(t *testing.T, frames []*image.RGBA) {
+				white := color.RGBA{255, 255, 255, 255}
+				if c := frames[0].RGBAAt(0, 20); c != white {
+					t.Errorf("box at frame 0 = %v, want white at the left edge", c)
+				}
+				if c := frames[2].RGBAAt(99, 20); c != white {
+					t.Errorf("box at frame 2 = %v, want white at the right edge", c)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, err := TestPattern(tt.opts)
+			if err != nil {
+				t.Fatalf("TestPattern() error = %v", err)
+			}
+			images := make([]*image.RGBA, len(frames))
+			for i, frame := range frames {
+				images[i] = frame.Image.(*image.RGBA)
+			}
+			tt.check(t, images)
+		})
+	}
+}
+
+func TestTestPatternErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts TestPatternOptions
+	}{
+		{"unknown pattern", TestPatternOptions{Pattern: "zebra"}},
+		{"negative size", TestPatternOptions{Pattern: PatternSolid, Width: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TestPattern(tt.opts); err == nil {
+				t.Error("TestPattern() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package gifutil reads existing GIFs as a sequence of fully composited frames
+package gifutil
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+// Frame is a composited GIF frame, as a player would display it
+type Frame struct {
+	// Index is the position of the frame in the GIF, starting at 0
+	Index int
+	// Image covers the whole logical screen of the GIF
+	Image *image.RGBA
+	// Delay is how long the frame is shown
+	Delay time.Duration
+}
+
+// Reader yields the frames of a decoded GIF one at a time, compositing each
+// frame over the previous ones according to its disposal method
+type Reader struct {
+	g      *gif.GIF
+	canvas *image.RGBA
+	next   int
+}
+
+// NewReader decodes a GIF from r within decode.DefaultLimits
+func NewReader(r io.Reader) (*Reader, error) {
+	g, err := decode.GIF(r, decode.DefaultLimits)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding GIF: %v", err)
+	}
+	return &Reader{
+		g:      g,
+		canvas: image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height)),
+	}, nil
+}
+
+// Open decodes the GIF file at path
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	r, err := NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return r, nil
+}
+
+// Len returns the number of frames in the GIF
+func (r *Reader) Len() int {
+	return len(r.g.Image)
+}
+
+// Bounds returns the logical screen of the GIF, which every frame covers
+func (r *Reader) Bounds() image.Rectangle {
+	return r.canvas.Bounds()
+}
+
+// LoopCount returns the loop count stored in the GIF: 0 loops forever,
+// -1 plays once and n plays n+1 times
+func (r *Reader) LoopCount() int {
+	return r.g.LoopCount
+}
+
+// GIF returns the decoded GIF, with its frames as stored in the file
+func (r *Reader) GIF() *gif.GIF {
+	return r.g
+}
+
+// Next returns the next composited frame, or io.EOF after the last one
+func (r *Reader) Next() (Frame, error) {
+	if r.next >= len(r.g.Image) {
+		return Frame{}, io.EOF
+	}
+	i := r.next
+	r.next++
+
+	img := r.g.Image[i]
+	disposal := byte(gif.DisposalNone)
+	if i < len(r.g.Disposal) {
+		disposal = r.g.Disposal[i]
+	}
+
+	// Keep the canvas to restore it once the frame has been shown
+	var previous *image.RGBA
+	if disposal == gif.DisposalPrevious {
+		previous = clone(r.canvas)
+	}
+
+	// Transparent pixels let the previous frames show through
+	draw.Draw(r.canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+	frame := Frame{Index: i, Image: clone(r.canvas)}
+	if i < len(r.g.Delay) {
+		frame.Delay = time.Duration(r.g.Delay[i]) * 10 * time.Millisecond
+	}
+
+	switch disposal {
+	case gif.DisposalBackground:
+		// Like browsers, clear to transparent rather than to the background color
+		draw.Draw(r.canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+	case gif.DisposalPrevious:
+		r.canvas = previous
+	}
+	return frame, nil
+}
+
+// ReadAll decodes a GIF from r and returns all its composited frames
+func ReadAll(r io.Reader) ([]Frame, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return reader.All()
+}
+
+// All returns the remaining composited frames
+func (r *Reader) All() ([]Frame, error) {
+	frames := make([]Frame, 0, r.Len()-r.next)
+	for {
+		frame, err := r.Next()
+		if err == io.EOF {
+			return frames, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+}
+
+// clone returns a copy of img
+func clone(img *image.RGBA) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	copy(out.Pix, img.Pix)
+	return out
+}
@@ -0,0 +1,147 @@
+package gifutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	red  = color.RGBA{255, 0, 0, 255}
+	blue = color.RGBA{0, 0, 255, 255}
+	none = color.RGBA{}
+)
+
+// encode builds a 2x2 GIF whose first frame is red, followed by a blue pixel at
+// (1,1) with the given disposal and an empty, fully transparent frame
+func encode(t testing.TB, disposal byte) []byte {
+	t.Helper()
+	palette := color.Palette{color.Transparent, red, blue}
+
+	first := image.NewPaletted(image.Rect(0, 0, 2, 2), palette)
+	for i := range first.Pix {
+		first.Pix[i] = 1
+	}
+	second := image.NewPaletted(image.Rect(1, 1, 2, 2), palette)
+	second.Pix[0] = 2
+	third := image.NewPaletted(image.Rect(0, 0, 1, 1), palette)
+
+	var buf bytes.Buffer
+	err := gif.EncodeAll(&buf, &gif.GIF{
+		Image:     []*image.Paletted{first, second, third},
+		Delay:     []int{10, 20, 30},
+		Disposal:  []byte{gif.DisposalNone, disposal, gif.DisposalNone},
+		LoopCount: 3,
+		Config:    image.Config{ColorModel: palette, Width: 2, Height: 2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReaderDisposal(t *testing.T) {
+	tests := []struct {
+		name     string
+		disposal byte
+		// want is the color of (1,1) in the last frame
+		want color.RGBA
+	}{
+		{name: "none keeps the pixel", disposal: gif.DisposalNone, want: blue},
+		{name: "background clears the pixel", disposal: gif.DisposalBackground, want: none},
+		{name: "previous restores red", disposal: gif.DisposalPrevious, want: red},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, err := ReadAll(bytes.NewReader(encode(t, tt.disposal)))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if len(frames) != 3 {
+				t.Fatalf("got %d frames, want 3", len(frames))
+			}
+
+			// The second frame is always composited over the first one
+			if got := frames[1].Image.RGBAAt(0, 0); got != red {
+				t.Errorf("frame 1 (0,0) = %v, want %v", got, red)
+			}
+			if got := frames[1].Image.RGBAAt(1, 1); got != blue {
+				t.Errorf("frame 1 (1,1) = %v, want %v", got, blue)
+			}
+			// The transparent third frame shows the disposed canvas
+			if got := frames[2].Image.RGBAAt(1, 1); got != tt.want {
+				t.Errorf("frame 2 (1,1) = %v, want %v", got, tt.want)
+			}
+			if got := frames[2].Image.RGBAAt(0, 0); got != red {
+				t.Errorf("frame 2 (0,0) = %v, want %v", got, red)
+			}
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	r, err := NewReader(bytes.NewReader(encode(t, gif.DisposalNone)))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if r.Len() != 3 || r.Bounds() != image.Rect(0, 0, 2, 2) || r.LoopCount() != 3 {
+		t.Errorf("Len() = %d, Bounds() = %v, LoopCount() = %d, want 3, 2x2, 3", r.Len(), r.Bounds(), r.LoopCount())
+	}
+
+	for i := 0; i < 3; i++ {
+		frame, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if frame.Index != i || frame.Delay != time.Duration(i+1)*100*time.Millisecond {
+			t.Errorf("frame %d: Index = %d, Delay = %v", i, frame.Index, frame.Delay)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next() after the last frame error = %v, want io.EOF", err)
+	}
+
+	// Frames are independent copies of the canvas
+	frames, err := ReadAll(bytes.NewReader(encode(t, gif.DisposalNone)))
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	frames[0].Image.SetRGBA(0, 0, blue)
+	if got := frames[1].Image.RGBAAt(0, 0); got != red {
+		t.Errorf("frame 1 changed with frame 0: %v", got)
+	}
+
+	if _, err := NewReader(strings.NewReader("not a gif")); err == nil {
+		t.Error("NewReader() expected error for invalid data")
+	}
+	if _, err := Open("missing.gif"); err == nil {
+		t.Error("Open() expected error for a missing file")
+	}
+}
+
+func FuzzNewReader(f *testing.F) {
+	f.Add(encode(f, gif.DisposalPrevious))
+	f.Add([]byte("GIF89a"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r, err := NewReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		// Every frame of a decoded GIF composites
+		frames, err := r.All()
+		if err != nil {
+			return
+		}
+		for _, frame := range frames {
+			if frame.Image.Bounds() != r.Bounds() {
+				t.Fatalf("frame %d bounds = %v, want the screen %v", frame.Index, frame.Image.Bounds(), r.Bounds())
+			}
+		}
+	})
+}
@@ -0,0 +1,333 @@
+package lottie
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"time"
+)
+
+// Version is the Lottie schema version written
+const Version = "5.7.4"
+
+// Layer types
+const (
+	layerShape = 4
+	layerText  = 5
+)
+
+// Animation is a Lottie animation of shape and text layers, changing only at
+// hold keyframes like the frames of a GIF
+type Animation struct {
+	Version   string  `json:"v"`
+	FrameRate float64 `json:"fr"`
+	InPoint   float64 `json:"ip"`
+	OutPoint  float64 `json:"op"`
+	Width     int     `json:"w"`
+	Height    int     `json:"h"`
+	Name      string  `json:"nm,omitempty"`
+	ThreeD    int     `json:"ddd"`
+	Assets    []any   `json:"assets"`
+	Fonts     *fonts  `json:"fonts,omitempty"`
+	// Layers are listed top to bottom
+	Layers []*Layer `json:"layers"`
+}
+
+// Layer is a layer of an animation
+type Layer struct {
+	ThreeD     int       `json:"ddd"`
+	Index      int       `json:"ind"`
+	Type       int       `json:"ty"`
+	Name       string    `json:"nm"`
+	Stretch    float64   `json:"sr"`
+	Transform  transform `json:"ks"`
+	AutoOrient int       `json:"ao"`
+	Shapes     []any     `json:"shapes,omitempty"`
+	Text       *text     `json:"t,omitempty"`
+	InPoint    float64   `json:"ip"`
+	OutPoint   float64   `json:"op"`
+	StartTime  float64   `json:"st"`
+	BlendMode  int       `json:"bm"`
+}
+
+// property is a static or keyframed value
+type property struct {
+	Animated int `json:"a"`
+	Value    any `json:"k"`
+}
+
+// keyframe holds a value from its time until the next keyframe
+type keyframe struct {
+	Time  float64   `json:"t"`
+	Value []float64 `json:"s"`
+	Hold  int       `json:"h"`
+}
+
+type transform struct {
+	Opacity  property `json:"o"`
+	Rotation property `json:"r"`
+	Position property `json:"p"`
+	Anchor   property `json:"a"`
+	Scale    property `json:"s"`
+}
+
+type group struct {
+	Type  string `json:"ty"`
+	Items []any  `json:"it"`
+}
+
+type rect struct {
+	Type      string   `json:"ty"`
+	Direction int      `json:"d"`
+	Size      property `json:"s"`
+	Position  property `json:"p"`
+	Roundness property `json:"r"`
+}
+
+type fill struct {
+	Type    string   `json:"ty"`
+	Color   property `json:"c"`
+	Opacity property `json:"o"`
+	Rule    int      `json:"r"`
+}
+
+type gradientFill struct {
+	Type    string        `json:"ty"`
+	Opacity property      `json:"o"`
+	Rule    int           `json:"r"`
+	Start   property      `json:"s"`
+	End     property      `json:"e"`
+	Kind    int           `json:"t"`
+	Stops   gradientStops `json:"g"`
+}
+
+type gradientStops struct {
+	Count  int      `json:"p"`
+	Colors property `json:"k"`
+}
+
+type shapeTransform struct {
+	Type     string   `json:"ty"`
+	Position property `json:"p"`
+	Anchor   property `json:"a"`
+	Scale    property `json:"s"`
+	Rotation property `json:"r"`
+	Opacity  property `json:"o"`
+}
+
+type fonts struct {
+	List []Font `json:"list"`
+}
+
+// Font is a font of text layers, drawn by the player from the fonts installed
+type Font struct {
+	Name   string  `json:"fName"`
+	Family string  `json:"fFamily"`
+	Style  string  `json:"fStyle"`
+	Ascent float64 `json:"ascent"`
+}
+
+type text struct {
+	Document textDocument `json:"d"`
+	Path     struct{}     `json:"p"`
+	More     textMore     `json:"m"`
+	Animator []any        `json:"a"`
+}
+
+type textDocument struct {
+	Keyframes []textKeyframe `json:"k"`
+}
+
+type textKeyframe struct {
+	Style textStyle `json:"s"`
+	Time  float64   `json:"t"`
+}
+
+type textStyle struct {
+	Size       float64   `json:"s"`
+	Font       string    `json:"f"`
+	Text       string    `json:"t"`
+	Justify    int       `json:"j"`
+	Tracking   int       `json:"tr"`
+	LineHeight float64   `json:"lh"`
+	Shift      int       `json:"ls"`
+	Color      []float64 `json:"fc"`
+}
+
+type textMore struct {
+	Grouping  int      `json:"g"`
+	Alignment property `json:"a"`
+}
+
+// Shape is a filled rectangle of a shape layer
+type Shape struct {
+	Rect  image.Rectangle
+	Color color.Color
+	// GradientTo, when set, fades the fill from Color on the left to it on the right
+	GradientTo color.Color
+}
+
+// TextKey shows Text from At on
+type TextKey struct {
+	At   time.Duration
+	Text string
+}
+
+// PositionKey moves a layer by Offset from At on
+type PositionKey struct {
+	At     time.Duration
+	Offset image.Point
+}
+
+// OpacityKey sets the opacity of a layer, from 0 to 100, from At on
+type OpacityKey struct {
+	At      time.Duration
+	Opacity float64
+}
+
+// New returns an empty animation of the given size and duration, timed at fps
+// frames per second
+func New(name string, width, height int, duration time.Duration, fps float64) *Animation {
+	a := &Animation{
+		Version:   Version,
+		FrameRate: fps,
+		Width:     width,
+		Height:    height,
+		Name:      name,
+		Assets:    []any{},
+		Layers:    []*Layer{},
+	}
+	a.OutPoint = a.frame(duration)
+	return a
+}
+
+// frame returns the animation frame shown at d
+func (a *Animation) frame(d time.Duration) float64 {
+	return math.Round(d.Seconds()*a.FrameRate*1000) / 1000
+}
+
+// add puts layer above the others, shown for the whole animation
+func (a *Animation) add(layer *Layer) *Layer {
+	layer.Index = len(a.Layers) + 1
+	layer.Stretch = 1
+	layer.OutPoint = a.OutPoint
+	layer.Transform = transform{
+		Opacity:  property{Value: 100},
+		Rotation: property{Value: 0},
+		Position: property{Value: []float64{0, 0, 0}},
+		Anchor:   property{Value: []float64{0, 0, 0}},
+		Scale:    property{Value: []float64{100, 100, 100}},
+	}
+	a.Layers = append([]*Layer{layer}, a.Layers...)
+	return layer
+}
+
+// AddShapes adds a layer of filled rectangles above the others
+func (a *Animation) AddShapes(name string, shapes []Shape) *Layer {
+	items := make([]any, len(shapes))
+	for i, s := range shapes {
+		r := rect{
+			Type:      "rc",
+			Direction: 1,
+			Size:      property{Value: []float64{float64(s.Rect.Dx()), float64(s.Rect.Dy())}},
+			Position:  property{Value: []float64{float64(s.Rect.Min.X+s.Rect.Max.X) / 2, float64(s.Rect.Min.Y+s.Rect.Max.Y) / 2}},
+			Roundness: property{Value: 0},
+		}
+		var paint any = fill{Type: "fl", Color: property{Value: rgba(s.Color)}, Opacity: property{Value: 100}, Rule: 1}
+		if s.GradientTo != nil {
+			start, end := rgba(s.Color), rgba(s.GradientTo)
+			y := float64(s.Rect.Min.Y+s.Rect.Max.Y) / 2
+			paint = gradientFill{
+				Type:    "gf",
+				Opacity: property{Value: 100},
+				Rule:    1,
+				Start:   property{Value: []float64{float64(s.Rect.Min.X), y}},
+				End:     property{Value: []float64{float64(s.Rect.Max.X), y}},
+				Kind:    1,
+				Stops:   gradientStops{Count: 2, Colors: property{Value: []float64{0, start[0], start[1], start[2], 1, end[0], end[1], end[2]}}},
+			}
+		}
+		items[i] = group{Type: "gr", Items: []any{r, paint, shapeTransform{
+			Type:     "tr",
+			Position: property{Value: []float64{0, 0}},
+			Anchor:   property{Value: []float64{0, 0}},
+			Scale:    property{Value: []float64{100, 100}},
+			Rotation: property{Value: 0},
+			Opacity:  property{Value: 100},
+		}}}
+	}
+	return a.add(&Layer{Type: layerShape, Name: name, Shapes: items})
+}
+
+// AddText adds a layer of single line text above the others, its baseline
+// starting at at, showing the text of each key from its time on
+func (a *Animation) AddText(name string, font Font, size float64, c color.Color, at image.Point, keys []TextKey) *Layer {
+	if a.Fonts == nil {
+		a.Fonts = &fonts{List: []Font{}}
+	}
+	known := false
+	for _, f := range a.Fonts.List {
+		known = known || f.Name == font.Name
+	}
+	if !known {
+		a.Fonts.List = append(a.Fonts.List, font)
+	}
+
+	t := &text{More: textMore{Alignment: property{Value: []float64{0, 0}}}, Animator: []any{}}
+	for _, key := range keys {
+		t.Document.Keyframes = append(t.Document.Keyframes, textKeyframe{
+			Time: a.frame(key.At),
+			Style: textStyle{
+				Size:       size,
+				Font:       font.Name,
+				Text:       key.Text,
+				LineHeight: size * 1.2,
+				Color:      rgba(c)[:3],
+			},
+		})
+	}
+	layer := a.add(&Layer{Type: layerText, Name: name, Text: t})
+	layer.Transform.Position = property{Value: []float64{float64(at.X), float64(at.Y), 0}}
+	return layer
+}
+
+// Show shows layer from from until to only
+func (a *Animation) Show(layer *Layer, from, to time.Duration) {
+	layer.InPoint, layer.OutPoint = a.frame(from), a.frame(to)
+}
+
+// Move moves layer by the offset of each key from its time on
+func (a *Animation) Move(layer *Layer, keys []PositionKey) {
+	frames := make([]keyframe, len(keys))
+	for i, key := range keys {
+		frames[i] = keyframe{Time: a.frame(key.At), Value: []float64{float64(key.Offset.X), float64(key.Offset.Y), 0}, Hold: 1}
+	}
+	layer.Transform.Position = property{Animated: 1, Value: frames}
+}
+
+// Fade sets the opacity of layer to that of each key from its time on
+func (a *Animation) Fade(layer *Layer, keys []OpacityKey) {
+	frames := make([]keyframe, len(keys))
+	for i, key := range keys {
+		frames[i] = keyframe{Time: a.frame(key.At), Value: []float64{key.Opacity}, Hold: 1}
+	}
+	layer.Transform.Opacity = property{Animated: 1, Value: frames}
+}
+
+// Write writes the animation as Lottie JSON
+func (a *Animation) Write(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(a); err != nil {
+		return fmt.Errorf("error writing Lottie animation: %v", err)
+	}
+	return nil
+}
+
+// rgba returns c as the 0 to 1 components Lottie colors are made of
+func rgba(c color.Color) []float64 {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	round := func(v uint8) float64 { return math.Round(float64(v)/255*1000) / 1000 }
+	return []float64{round(n.R), round(n.G), round(n.B), round(n.A)}
+}
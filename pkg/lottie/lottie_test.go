@@ -0,0 +1,108 @@
+package lottie
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnimation(t *testing.T) {
+	anim := New("demo", 40, 20, time.Second, 10)
+	background := anim.AddShapes("background", []Shape{{Rect: image.Rect(0, 0, 40, 20), Color: color.Black}})
+	font := Font{Name: "GoMono", Family: "Go Mono, monospace", Style: "Regular"}
+	label := anim.AddText("label", font, 12, color.White, image.Pt(2, 14), []TextKey{{Text: "a"}, {At: 500 * time.Millisecond, Text: "ab"}})
+	anim.AddText("more", font, 12, color.White, image.Pt(2, 18), []TextKey{{Text: "c"}})
+	anim.Show(label, 200*time.Millisecond, time.Second)
+	anim.Move(background, []PositionKey{{Offset: image.Pt(0, 0)}, {At: 300 * time.Millisecond, Offset: image.Pt(-5, 0)}})
+	anim.Fade(label, []OpacityKey{{Opacity: 100}, {At: 800 * time.Millisecond}})
+
+	var buf bytes.Buffer
+	if err := anim.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var got struct {
+		FrameRate float64 `json:"fr"`
+		OutPoint  float64 `json:"op"`
+		Fonts     struct {
+			List []Font `json:"list"`
+		} `json:"fonts"`
+		Layers []struct {
+			Name      string  `json:"nm"`
+			Type      int     `json:"ty"`
+			InPoint   float64 `json:"ip"`
+			OutPoint  float64 `json:"op"`
+			Transform struct {
+				Opacity  property `json:"o"`
+				Position struct {
+					Animated int             `json:"a"`
+					Value    json.RawMessage `json:"k"`
+				} `json:"p"`
+			} `json:"ks"`
+			Text struct {
+				Document struct {
+					Keyframes []textKeyframe `json:"k"`
+				} `json:"d"`
+			} `json:"t"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode animation: %v", err)
+	}
+
+	if got.FrameRate != 10 || got.OutPoint != 10 {
+		t.Errorf("frame rate and out point = %v, %v, want 10, 10", got.FrameRate, got.OutPoint)
+	}
+	if len(got.Fonts.List) != 1 {
+		t.Errorf("got %d fonts, want the shared font once", len(got.Fonts.List))
+	}
+	var names []string
+	for _, layer := range got.Layers {
+		names = append(names, layer.Name)
+	}
+	if want := []string{"more", "label", "background"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("layers = %v, want the last added on top %v", names, want)
+	}
+
+	labelLayer, backgroundLayer := got.Layers[1], got.Layers[2]
+	if labelLayer.Type != layerText || backgroundLayer.Type != layerShape {
+		t.Errorf("layer types = %d, %d, want text and shape", labelLayer.Type, backgroundLayer.Type)
+	}
+	if labelLayer.InPoint != 2 || labelLayer.OutPoint != 10 {
+		t.Errorf("label shown from %v to %v, want 2 to 10", labelLayer.InPoint, labelLayer.OutPoint)
+	}
+	if keys := labelLayer.Text.Document.Keyframes; len(keys) != 2 || keys[1].Time != 5 || keys[1].Style.Text != "ab" {
+		t.Errorf("label text keyframes = %+v, want ab at frame 5", keys)
+	}
+	wantMove := []keyframe{{Time: 0, Value: []float64{0, 0, 0}, Hold: 1}, {Time: 3, Value: []float64{-5, 0, 0}, Hold: 1}}
+	var move []keyframe
+	if err := json.Unmarshal(backgroundLayer.Transform.Position.Value, &move); err != nil || backgroundLayer.Transform.Position.Animated != 1 || !reflect.DeepEqual(move, wantMove) {
+		t.Errorf("background position = %s, want hold keyframes %+v", backgroundLayer.Transform.Position.Value, wantMove)
+	}
+	if labelLayer.Transform.Opacity.Animated != 1 {
+		t.Error("label opacity is not animated")
+	}
+}
+
+func TestRGBA(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.Color
+		want []float64
+	}{
+		{name: "white", c: color.White, want: []float64{1, 1, 1, 1}},
+		{name: "transparent", c: color.Transparent, want: []float64{0, 0, 0, 0}},
+		{name: "orange", c: color.RGBA{255, 128, 0, 255}, want: []float64{1, 0.502, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rgba(tt.c); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rgba() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
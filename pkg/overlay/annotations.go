@@ -0,0 +1,178 @@
+package overlay
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Annotation types supported in annotation files
+const (
+	AnnotationArrow     = "arrow"
+	AnnotationRect      = "rect"
+	AnnotationHighlight = "highlight"
+	AnnotationText      = "text"
+)
+
+// Annotation is a vector overlay drawn on a range of frames
+type Annotation struct {
+	// Type is one of arrow, rect, highlight or text
+	Type string `json:"type"`
+	// Frames is the inclusive range of frames ("12" or "0-30") the annotation is shown on, all frames if empty
+	Frames string `json:"frames,omitempty"`
+	// X and Y are the top-left corner of rect, highlight and text, or the start of an arrow
+	X int `json:"x"`
+	Y int `json:"y"`
+	// Width and Height size rect and highlight annotations
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// ToX and ToY are the point an arrow points at
+	ToX int `json:"toX,omitempty"`
+	ToY int `json:"toY,omitempty"`
+	// Text is the label of text annotations
+	Text string `json:"text,omitempty"`
+	// Size is the font size of text annotations in pixels
+	Size float64 `json:"size,omitempty"`
+	// Color as #RRGGBB or #RRGGBBAA
+	Color string `json:"color,omitempty"`
+	// Background of text annotations as #RRGGBB or #RRGGBBAA
+	Background string `json:"background,omitempty"`
+	// Thickness of arrows and rectangle outlines in pixels
+	Thickness float64 `json:"thickness,omitempty"`
+
+	first, last int
+	color       color.Color
+	background  color.Color
+}
+
+// annotationFile is the layout of an annotations JSON file
+type annotationFile struct {
+	Annotations []Annotation `json:"annotations"`
+}
+
+// LoadAnnotations reads and validates an annotations JSON file
+func LoadAnnotations(path string) ([]Annotation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading annotations %s: %v", path, err)
+	}
+
+	var file annotationFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing annotations %s: %v", path, err)
+	}
+
+	for i := range file.Annotations {
+		if err := file.Annotations[i].prepare(); err != nil {
+			return nil, fmt.Errorf("annotation %d in %s: %v", i, path, err)
+		}
+	}
+	return file.Annotations, nil
+}
+
+// prepare validates the annotation and parses its frame range and colors
+func (a *Annotation) prepare() error {
+	switch a.Type {
+	case AnnotationArrow, AnnotationRect, AnnotationHighlight, AnnotationText:
+	default:
+		return fmt.Errorf("unknown annotation type %q", a.Type)
+	}
+	if (a.Type == AnnotationRect || a.Type == AnnotationHighlight) && (a.Width <= 0 || a.Height <= 0) {
+		return fmt.Errorf("%s annotations need a positive width and height", a.Type)
+	}
+	if a.Type == AnnotationText && strings.TrimSpace(a.Text) == "" {
+		return fmt.Errorf("text annotations need a text")
+	}
+
+	a.first, a.last = 0, -1
+	if a.Frames != "" {
+		first, last, err := ParseFrameRange(a.Frames)
+		if err != nil {
+			return err
+		}
+		a.first, a.last = first, last
+	}
+
+	var err error
+	a.color = defaultAnnotationColor(a.Type)
+	if a.Color != "" {
+		if a.color, err = ParseHexColor(a.Color); err != nil {
+			return err
+		}
+	}
+	a.background = DefaultCaptionStyle.Background
+	if a.Background != "" {
+		if a.background, err = ParseHexColor(a.Background); err != nil {
+			return err
+		}
+	}
+	if a.Thickness <= 0 {
+		a.Thickness = 3
+	}
+	return nil
+}
+
+// defaultAnnotationColor is a red for outlines and a translucent yellow for highlights
+func defaultAnnotationColor(kind string) color.Color {
+	switch kind {
+	case AnnotationHighlight:
+		return color.RGBA{128, 115, 0, 128}
+	case AnnotationText:
+		return DefaultCaptionStyle.Foreground
+	default:
+		return color.RGBA{230, 40, 40, 255}
+	}
+}
+
+// ShownOn reports whether the annotation is visible on the given frame
+func (a Annotation) ShownOn(frame int) bool {
+	if a.last < 0 {
+		return true
+	}
+	return frame >= a.first && frame <= a.last
+}
+
+// Draw renders the annotation onto dst
+func (a Annotation) Draw(dst draw.Image) error {
+	switch a.Type {
+	case AnnotationArrow:
+		DrawArrow(dst, Point{float64(a.X), float64(a.Y)}, Point{float64(a.ToX), float64(a.ToY)}, a.Thickness, a.color)
+	case AnnotationRect:
+		DrawRect(dst, image.Rect(a.X, a.Y, a.X+a.Width, a.Y+a.Height), a.Thickness, a.color)
+	case AnnotationHighlight:
+		FillRect(dst, image.Rect(a.X, a.Y, a.X+a.Width, a.Y+a.Height), a.color)
+	case AnnotationText:
+		size := a.Size
+		if size <= 0 {
+			size = CaptionSize(dst.Bounds())
+		}
+		_, err := DrawLabel(dst, a.Text, a.X, a.Y, size, CaptionStyle{Foreground: a.color, Background: a.background})
+		return err
+	}
+	return nil
+}
+
+// ParseFrameRange parses "N" or "N-M" into an inclusive range of frame indices
+func ParseFrameRange(s string) (int, int, error) {
+	firstStr, lastStr, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	first, err := strconv.Atoi(strings.TrimSpace(firstStr))
+	if err != nil || first < 0 {
+		return 0, 0, fmt.Errorf("invalid frame index %q", firstStr)
+	}
+	if !isRange {
+		return first, first, nil
+	}
+	last, err := strconv.Atoi(strings.TrimSpace(lastStr))
+	if err != nil || last < 0 {
+		return 0, 0, fmt.Errorf("invalid frame index %q", lastStr)
+	}
+	if last < first {
+		return 0, 0, fmt.Errorf("range end %d is before range start %d", last, first)
+	}
+	return first, last, nil
+}
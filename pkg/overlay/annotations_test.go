@@ -0,0 +1,141 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeAnnotations(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "annotations.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write annotations: %v", err)
+	}
+	return path
+}
+
+func TestLoadAnnotations(t *testing.T) {
+	// Create a temporary directory for test files
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "All annotation types",
+			content: `{"annotations": [
+				{"type": "arrow", "frames": "0-10", "x": 1, "y": 1, "toX": 20, "toY": 20},
+				{"type": "rect", "x": 5, "y": 5, "width": 10, "height": 10, "color": "#00ff00"},
+				{"type": "highlight", "frames": "3", "x": 0, "y": 0, "width": 4, "height": 4},
+				{"type": "text", "x": 2, "y": 2, "text": "Click here", "size": 12}
+			]}`,
+			want: 4,
+		},
+		{name: "Invalid JSON", content: `{"annotations": [`, wantErr: true},
+		{name: "Unknown type", content: `{"annotations": [{"type": "circle"}]}`, wantErr: true},
+		{name: "Rect without size", content: `{"annotations": [{"type": "rect", "x": 1, "y": 1}]}`, wantErr: true},
+		{name: "Text without text", content: `{"annotations": [{"type": "text"}]}`, wantErr: true},
+		{name: "Invalid frames", content: `{"annotations": [{"type": "arrow", "frames": "9-2"}]}`, wantErr: true},
+		{name: "Invalid color", content: `{"annotations": [{"type": "arrow", "color": "red"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeAnnotations(t, tempDir, tt.content)
+			got, err := LoadAnnotations(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadAnnotations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("LoadAnnotations() got %d annotations, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotationShownOn(t *testing.T) {
+	ranged := Annotation{Type: AnnotationArrow, Frames: "2-4"}
+	always := Annotation{Type: AnnotationArrow}
+	for _, a := range []*Annotation{&ranged, &always} {
+		if err := a.prepare(); err != nil {
+			t.Fatalf("prepare() error = %v", err)
+		}
+	}
+
+	for frame, want := range map[int]bool{0: false, 2: true, 4: true, 5: false} {
+		if got := ranged.ShownOn(frame); got != want {
+			t.Errorf("ShownOn(%d) = %v, want %v", frame, got, want)
+		}
+	}
+	if !always.ShownOn(100) {
+		t.Error("ShownOn() without frames should include every frame")
+	}
+}
+
+func TestAnnotationDraw(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+
+	tests := []struct {
+		name       string
+		annotation Annotation
+		inside     image.Point
+		outside    image.Point
+	}{
+		{
+			name:       "Arrow",
+			annotation: Annotation{Type: AnnotationArrow, X: 5, Y: 30, ToX: 55, ToY: 30, Thickness: 4},
+			inside:     image.Pt(30, 30),
+			outside:    image.Pt(30, 5),
+		},
+		{
+			name:       "Rect outline",
+			annotation: Annotation{Type: AnnotationRect, X: 10, Y: 10, Width: 30, Height: 30, Thickness: 2},
+			inside:     image.Pt(10, 20),
+			outside:    image.Pt(25, 25),
+		},
+		{
+			name:       "Highlight",
+			annotation: Annotation{Type: AnnotationHighlight, X: 10, Y: 10, Width: 10, Height: 10},
+			inside:     image.Pt(15, 15),
+			outside:    image.Pt(25, 25),
+		},
+		{
+			name:       "Text",
+			annotation: Annotation{Type: AnnotationText, X: 2, Y: 2, Text: "Hi", Size: 14},
+			inside:     image.Pt(3, 3),
+			outside:    image.Pt(55, 55),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 60, 60))
+			draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+
+			if err := tt.annotation.prepare(); err != nil {
+				t.Fatalf("prepare() error = %v", err)
+			}
+			if err := tt.annotation.Draw(img); err != nil {
+				t.Fatalf("Draw() error = %v", err)
+			}
+
+			if img.RGBAAt(tt.inside.X, tt.inside.Y) == white {
+				t.Errorf("Draw() left %v untouched", tt.inside)
+			}
+			if img.RGBAAt(tt.outside.X, tt.outside.Y) != white {
+				t.Errorf("Draw() changed %v", tt.outside)
+			}
+		})
+	}
+}
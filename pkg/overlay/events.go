@@ -0,0 +1,190 @@
+package overlay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"image/draw"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+)
+
+// Event types supported in events files
+const (
+	EventKey   = "key"
+	EventClick = "click"
+)
+
+const (
+	// keyHold is how long a keystroke badge stays visible
+	keyHold = time.Second
+	// clickHold is how long a click marker stays visible
+	clickHold = 600 * time.Millisecond
+	// maxKeys is the number of recent keystrokes shown together
+	maxKeys = 3
+)
+
+// clickColor is used for click markers
+var clickColor = color.RGBA{230, 40, 40, 255}
+
+// Event is a keystroke or click recorded during a capture
+type Event struct {
+	// Time is the position of the event in seconds since the start of the capture
+	Time float64 `json:"time"`
+	// Type is key or click
+	Type string `json:"type"`
+	// Key is the keystroke label for key events, e.g. "⌘+S"
+	Key string `json:"key,omitempty"`
+	// X and Y are the click position in frame coordinates
+	X int `json:"x,omitempty"`
+	Y int `json:"y,omitempty"`
+}
+
+// at returns the event time as a duration
+func (e Event) at() time.Duration {
+	return time.Duration(e.Time * float64(time.Second))
+}
+
+// LoadEvents reads an events file, either a JSON document {"events": [...]}
+// or JSON Lines with one event per line as streamed by recorders
+func LoadEvents(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading events %s: %v", path, err)
+	}
+
+	events, err := parseEvents(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing events %s: %v", path, err)
+	}
+	return events, nil
+}
+
+func parseEvents(data []byte) ([]Event, error) {
+	var events []Event
+
+	var doc struct {
+		Events []Event `json:"events"`
+	}
+	if err := json.Unmarshal(data, &doc); err == nil && doc.Events != nil {
+		events = doc.Events
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			events = append(events, event)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, event := range events {
+		switch event.Type {
+		case EventKey:
+			if strings.TrimSpace(event.Key) == "" {
+				return nil, fmt.Errorf("event %d: key events need a key", i)
+			}
+		case EventClick:
+		default:
+			return nil, fmt.Errorf("event %d: unknown event type %q", i, event.Type)
+		}
+		if event.Time < 0 {
+			return nil, fmt.Errorf("event %d: time must be non-negative", i)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+	return events, nil
+}
+
+// DrawEvents renders the keystrokes and clicks recently recorded at the given playback position:
+// keystroke badges in the top-right corner and growing rings around clicks
+func DrawEvents(dst draw.Image, events []Event, position time.Duration) error {
+	var keys []string
+	for _, event := range events {
+		age := position - event.at()
+		if age < 0 {
+			break
+		}
+		switch event.Type {
+		case EventKey:
+			if age < keyHold {
+				keys = append(keys, event.Key)
+			}
+		case EventClick:
+			if age < clickHold {
+				progress := float64(age) / float64(clickHold)
+				radius := 8 + 10*progress
+				DrawRing(dst, Point{float64(event.X), float64(event.Y)}, radius, 3, clickColor)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	if len(keys) > maxKeys {
+		keys = keys[len(keys)-maxKeys:]
+	}
+
+	bounds := dst.Bounds()
+	size := CaptionSize(bounds)
+	face, err := Face(size)
+	if err != nil {
+		return err
+	}
+	label := displayKeys(face, strings.Join(keys, "  "))
+	face.Close()
+
+	box, err := MeasureLabel(label, size)
+	if err != nil {
+		return err
+	}
+	margin := int(size / 2)
+	_, err = DrawLabel(dst, label, bounds.Max.X-box.X-margin, bounds.Min.Y+margin, size, DefaultCaptionStyle)
+	return err
+}
+
+// keySymbols spells out the modifier symbols missing from the embedded font
+var keySymbols = map[rune]string{
+	'⌘': "Cmd",
+	'⌥': "Opt",
+	'⌃': "Ctrl",
+	'⇧': "Shift",
+	'⏎': "Enter",
+	'↵': "Enter",
+	'⌫': "Backspace",
+	'⎋': "Esc",
+	'⇥': "Tab",
+}
+
+// displayKeys replaces the symbols face cannot render with their names
+func displayKeys(face font.Face, label string) string {
+	var b strings.Builder
+	for _, r := range label {
+		if _, ok := face.GlyphAdvance(r); !ok {
+			if name, known := keySymbols[r]; known {
+				b.WriteString(name)
+				continue
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
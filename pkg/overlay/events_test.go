@@ -0,0 +1,112 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+)
+
+func TestParseEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{
+			name:  "JSON document",
+			input: `{"events": [{"time": 2, "type": "click", "x": 5, "y": 5}, {"time": 1, "type": "key", "key": "⌘+S"}]}`,
+			want:  2,
+		},
+		{
+			name:  "JSON Lines",
+			input: "{\"time\": 0.5, \"type\": \"key\", \"key\": \"a\"}\n\n{\"time\": 1.5, \"type\": \"click\", \"x\": 1, \"y\": 2}\n",
+			want:  2,
+		},
+		{name: "Unknown type", input: `{"time": 1, "type": "scroll"}`, wantErr: true},
+		{name: "Key without label", input: `{"time": 1, "type": "key"}`, wantErr: true},
+		{name: "Negative time", input: `{"time": -1, "type": "click"}`, wantErr: true},
+		{name: "Invalid line", input: "{\"time\": 1, \"type\": \"click\"}\nnot json\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEvents([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("parseEvents() got %d events, want %d", len(got), tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].Time < got[i-1].Time {
+					t.Errorf("parseEvents() events are not sorted by time")
+				}
+			}
+		})
+	}
+}
+
+func TestDrawEvents(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	events := []Event{
+		{Time: 1, Type: EventKey, Key: "⌘+S"},
+		{Time: 1, Type: EventClick, X: 30, Y: 100},
+	}
+
+	tests := []struct {
+		name      string
+		position  time.Duration
+		wantKey   bool
+		wantClick bool
+	}{
+		{name: "Before the events", position: 500 * time.Millisecond},
+		{name: "Right after the events", position: 1100 * time.Millisecond, wantKey: true, wantClick: true},
+		{name: "Click marker expired", position: 1800 * time.Millisecond, wantKey: true},
+		{name: "Everything expired", position: 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 200, 150))
+			draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+
+			if err := DrawEvents(img, events, tt.position); err != nil {
+				t.Fatalf("DrawEvents() error = %v", err)
+			}
+
+			changed := func(r image.Rectangle) bool {
+				for y := r.Min.Y; y < r.Max.Y; y++ {
+					for x := r.Min.X; x < r.Max.X; x++ {
+						if img.RGBAAt(x, y) != white {
+							return true
+						}
+					}
+				}
+				return false
+			}
+
+			// Keystroke badges live in the top-right corner, the click ring around (30, 100)
+			if got := changed(image.Rect(100, 0, 200, 40)); got != tt.wantKey {
+				t.Errorf("DrawEvents() key badge drawn = %v, want %v", got, tt.wantKey)
+			}
+			if got := changed(image.Rect(0, 70, 60, 130)); got != tt.wantClick {
+				t.Errorf("DrawEvents() click marker drawn = %v, want %v", got, tt.wantClick)
+			}
+		})
+	}
+}
+
+func TestDisplayKeys(t *testing.T) {
+	face, err := Face(14)
+	if err != nil {
+		t.Fatalf("Face() error = %v", err)
+	}
+	defer face.Close()
+
+	if got := displayKeys(face, "⌘+⇧+S"); got != "Cmd+Shift+S" {
+		t.Errorf("displayKeys() = %q, want %q", got, "Cmd+Shift+S")
+	}
+}
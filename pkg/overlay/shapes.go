@@ -0,0 +1,174 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Point is a position in frame coordinates
+type Point struct {
+	X, Y float64
+}
+
+// FillPolygon fills the closed polygon described by points with c, antialiasing its edges
+func FillPolygon(dst draw.Image, points []Point, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+	bounds := dst.Bounds()
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	offset := func(p Point) (float32, float32) {
+		return float32(p.X - float64(bounds.Min.X)), float32(p.Y - float64(bounds.Min.Y))
+	}
+	z.MoveTo(offset(points[0]))
+	for _, p := range points[1:] {
+		z.LineTo(offset(p))
+	}
+	z.ClosePath()
+	z.Draw(dst, bounds, image.NewUniform(c), image.Point{})
+}
+
+// DrawLine draws a line from a to b with the given thickness
+func DrawLine(dst draw.Image, a, b Point, thickness float64, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	// Normal vector scaled to half the thickness
+	nx, ny := -dy/length*thickness/2, dx/length*thickness/2
+	FillPolygon(dst, []Point{
+		{a.X + nx, a.Y + ny},
+		{b.X + nx, b.Y + ny},
+		{b.X - nx, b.Y - ny},
+		{a.X - nx, a.Y - ny},
+	}, c)
+}
+
+// DrawArrow draws a line from a to b ending with an arrow head pointing at b
+func DrawArrow(dst draw.Image, a, b Point, thickness float64, c color.Color) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	ux, uy := dx/length, dy/length
+
+	// The head is proportional to the thickness but never longer than the arrow
+	headLength := math.Min(length, math.Max(4*thickness, 10))
+	headWidth := headLength * 0.6
+	base := Point{b.X - ux*headLength, b.Y - uy*headLength}
+
+	DrawLine(dst, a, base, thickness, c)
+	FillPolygon(dst, []Point{
+		b,
+		{base.X - uy*headWidth, base.Y + ux*headWidth},
+		{base.X + uy*headWidth, base.Y - ux*headWidth},
+	}, c)
+}
+
+// DrawRect draws the outline of r with the given thickness, kept inside r
+func DrawRect(dst draw.Image, r image.Rectangle, thickness float64, c color.Color) {
+	t := int(math.Max(1, math.Round(thickness)))
+	edges := []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+t),
+		image.Rect(r.Min.X, r.Max.Y-t, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, r.Min.Y+t, r.Min.X+t, r.Max.Y-t),
+		image.Rect(r.Max.X-t, r.Min.Y+t, r.Max.X, r.Max.Y-t),
+	}
+	for _, edge := range edges {
+		FillRect(dst, edge, c)
+	}
+}
+
+// FillRect blends c over r, so translucent colors can be used as highlights
+func FillRect(dst draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(dst, r.Intersect(dst.Bounds()), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// labelLayout measures the box needed to draw text as a label
+func labelLayout(face font.Face, text string) (lines []string, size image.Point, lineHeight, padding int) {
+	lineHeight = face.Metrics().Height.Ceil()
+	padding = max(2, lineHeight/6)
+
+	lines = splitLines(text)
+	width := 0
+	for _, line := range lines {
+		width = max(width, font.MeasureString(face, line).Ceil())
+	}
+	return lines, image.Pt(width+2*padding, len(lines)*lineHeight+2*padding), lineHeight, padding
+}
+
+// MeasureLabel returns the size of the box DrawLabel draws for text
+func MeasureLabel(text string, size float64) (image.Point, error) {
+	face, err := Face(size)
+	if err != nil {
+		return image.Point{}, err
+	}
+	defer face.Close()
+
+	_, box, _, _ := labelLayout(face, text)
+	return box, nil
+}
+
+// DrawLabel renders text with its top-left corner at (x, y) over a box of the style background
+func DrawLabel(dst draw.Image, text string, x, y int, size float64, style CaptionStyle) (image.Rectangle, error) {
+	face, err := Face(size)
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	defer face.Close()
+
+	lines, boxSize, lineHeight, padding := labelLayout(face, text)
+	box := image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x, y).Add(boxSize)}
+	FillRect(dst, box, style.Background)
+
+	ascent := face.Metrics().Ascent.Ceil()
+	drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(style.Foreground), Face: face}
+	for i, line := range lines {
+		drawer.Dot = fixed.P(x+padding, y+padding+i*lineHeight+ascent)
+		drawer.DrawString(line)
+	}
+	return box, nil
+}
+
+// DrawRing draws a circle outline centered on c with the given radius and thickness
+func DrawRing(dst draw.Image, center Point, radius, thickness float64, c color.Color) {
+	if radius <= 0 {
+		return
+	}
+	outer := radius + thickness/2
+	inner := math.Max(0, radius-thickness/2)
+
+	bounds := dst.Bounds()
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	circle := func(r float64, clockwise bool) {
+		const segments = 48
+		for i := 0; i <= segments; i++ {
+			angle := 2 * math.Pi * float64(i) / segments
+			if clockwise {
+				angle = -angle
+			}
+			x := float32(center.X + r*math.Cos(angle) - float64(bounds.Min.X))
+			y := float32(center.Y + r*math.Sin(angle) - float64(bounds.Min.Y))
+			if i == 0 {
+				z.MoveTo(x, y)
+			} else {
+				z.LineTo(x, y)
+			}
+		}
+		z.ClosePath()
+	}
+	// Winding the inner circle the other way punches the hole
+	circle(outer, false)
+	if inner > 0 {
+		circle(inner, true)
+	}
+	z.Draw(dst, bounds, image.NewUniform(c), image.Point{})
+}
@@ -0,0 +1,140 @@
+package overlay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cue is a single subtitle shown between Start (inclusive) and End (exclusive)
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// LoadSRT reads the subtitles of an SRT file
+func LoadSRT(path string) ([]Cue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening subtitles %s: %v", path, err)
+	}
+	defer file.Close()
+
+	cues, err := ParseSRT(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing subtitles %s: %v", path, err)
+	}
+	return cues, nil
+}
+
+// ParseSRT parses SubRip subtitles: blocks made of a sequence number, a
+// "00:00:01,000 --> 00:00:04,000" timing line and one or more text lines
+func ParseSRT(r io.Reader) ([]Cue, error) {
+	var cues []Cue
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+
+	var block []string
+	flush := func() error {
+		defer func() { block = nil }()
+		if len(block) == 0 {
+			return nil
+		}
+		// The sequence number is optional in practice, find the timing line
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			timing = 1
+		}
+		if timing >= len(block) || !strings.Contains(block[timing], "-->") {
+			return fmt.Errorf("line %d: missing timing line", lineNo)
+		}
+		startStr, endStr, _ := strings.Cut(block[timing], "-->")
+		start, err := parseSRTTime(startStr)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		end, err := parseSRTTime(endStr)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		if end < start {
+			return fmt.Errorf("line %d: cue ends before it starts", lineNo)
+		}
+		cues = append(cues, Cue{
+			Start: start,
+			End:   end,
+			Text:  strings.Join(block[timing+1:], "\n"),
+		})
+		return nil
+	}
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+// parseSRTTime parses an "HH:MM:SS,mmm" timestamp
+func parseSRTTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	// Some tools append positioning after the timestamp
+	if fields := strings.Fields(s); len(fields) > 0 {
+		s = fields[0]
+	}
+	clock, millis, _ := strings.Cut(strings.Replace(s, ".", ",", 1), ",")
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid SRT timestamp %q", s)
+	}
+
+	var total time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid SRT timestamp %q", s)
+		}
+		total += time.Duration(n) * units[i]
+	}
+	if millis != "" {
+		n, err := strconv.Atoi(millis)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid SRT timestamp %q", s)
+		}
+		total += time.Duration(n) * time.Millisecond
+	}
+	return total, nil
+}
+
+// TextAt returns the text of the cues active at the given position, joined by newlines
+func TextAt(cues []Cue, position time.Duration) string {
+	var texts []string
+	for _, cue := range cues {
+		if position >= cue.Start && position < cue.End {
+			texts = append(texts, cue.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,71 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSRT(t *testing.T) {
+	input := "\ufeff1\r\n00:00:01,000 --> 00:00:04,500\r\nHello\r\nworld\r\n\r\n2\n00:00:05,000 --> 00:00:06,000 X1:10\nSecond cue\n"
+
+	cues, err := ParseSRT(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseSRT() error = %v", err)
+	}
+
+	want := []Cue{
+		{Start: time.Second, End: 4500 * time.Millisecond, Text: "Hello\nworld"},
+		{Start: 5 * time.Second, End: 6 * time.Second, Text: "Second cue"},
+	}
+	if len(cues) != len(want) {
+		t.Fatalf("ParseSRT() got %d cues, want %d", len(cues), len(want))
+	}
+	for i := range want {
+		if cues[i] != want[i] {
+			t.Errorf("ParseSRT()[%d] = %+v, want %+v", i, cues[i], want[i])
+		}
+	}
+}
+
+func TestParseSRTErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Missing timing line", input: "1\nHello\n"},
+		{name: "Invalid timestamp", input: "1\n00:00:aa,000 --> 00:00:01,000\nHello\n"},
+		{name: "End before start", input: "1\n00:00:05,000 --> 00:00:01,000\nHello\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSRT(strings.NewReader(tt.input)); err == nil {
+				t.Error("ParseSRT() expected an error")
+			}
+		})
+	}
+}
+
+func TestTextAt(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 2 * time.Second, Text: "first"},
+		{Start: time.Second, End: 3 * time.Second, Text: "second"},
+	}
+
+	tests := []struct {
+		position time.Duration
+		want     string
+	}{
+		{position: 500 * time.Millisecond, want: "first"},
+		{position: 1500 * time.Millisecond, want: "first\nsecond"},
+		{position: 2 * time.Second, want: "second"},
+		{position: 3 * time.Second, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := TextAt(cues, tt.position); got != tt.want {
+			t.Errorf("TextAt(%v) = %q, want %q", tt.position, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package overlay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// CaptionStyle holds the colors used to draw captions
+type CaptionStyle struct {
+	// Foreground is the text color
+	Foreground color.Color
+	// Background is drawn behind the text to keep it readable on any frame
+	Background color.Color
+}
+
+// DefaultCaptionStyle draws white text over a translucent black box
+var DefaultCaptionStyle = CaptionStyle{
+	Foreground: color.RGBA{255, 255, 255, 255},
+	Background: color.RGBA{0, 0, 0, 180},
+}
+
+// ParseHexColor parses a "#RRGGBB" or "#RRGGBBAA" color, the leading # being optional
+func ParseHexColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return nil, fmt.Errorf("invalid color %q: expected #RRGGBB or #RRGGBBAA", s)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q: %v", s, err)
+	}
+	if len(hex) == 6 {
+		value = value<<8 | 0xff
+	}
+	c := color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}
+	return color.RGBAModel.Convert(c), nil
+}
+
+var (
+	regularOnce sync.Once
+	regularFont *opentype.Font
+	regularErr  error
+)
+
+// Face returns the Go Regular font at the given size in pixels
+func Face(size float64) (font.Face, error) {
+	regularOnce.Do(func() {
+		regularFont, regularErr = opentype.Parse(goregular.TTF)
+	})
+	if regularErr != nil {
+		return nil, fmt.Errorf("error parsing embedded font: %v", regularErr)
+	}
+	return opentype.NewFace(regularFont, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// CaptionSize returns a font size proportional to the height of the frame
+func CaptionSize(bounds image.Rectangle) float64 {
+	return max(12, float64(bounds.Dy())/18)
+}
+
+// DrawCaption renders text centered at the bottom of dst over a translucent box,
+// wrapping lines that do not fit the frame width. It returns the area covered by the box.
+func DrawCaption(dst draw.Image, text string, style CaptionStyle) (image.Rectangle, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return image.Rectangle{}, nil
+	}
+
+	bounds := dst.Bounds()
+	face, err := Face(CaptionSize(bounds))
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	padding := max(2, lineHeight/4)
+	maxWidth := bounds.Dx() - 4*padding
+
+	var lines []string
+	for _, line := range splitLines(text) {
+		lines = append(lines, wrapLine(face, line, maxWidth)...)
+	}
+
+	// Bottom-aligned box holding every line
+	boxHeight := len(lines)*lineHeight + 2*padding
+	boxWidth := 0
+	for _, line := range lines {
+		boxWidth = max(boxWidth, font.MeasureString(face, line).Ceil())
+	}
+	boxWidth += 2 * padding
+	box := image.Rect(
+		bounds.Min.X+(bounds.Dx()-boxWidth)/2,
+		bounds.Max.Y-boxHeight-padding,
+		bounds.Min.X+(bounds.Dx()+boxWidth)/2,
+		bounds.Max.Y-padding,
+	).Intersect(bounds)
+	draw.Draw(dst, box, image.NewUniform(style.Background), image.Point{}, draw.Over)
+
+	drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(style.Foreground), Face: face}
+	for i, line := range lines {
+		width := font.MeasureString(face, line).Ceil()
+		drawer.Dot = fixed.P(
+			bounds.Min.X+(bounds.Dx()-width)/2,
+			box.Min.Y+padding+i*lineHeight+metrics.Ascent.Ceil(),
+		)
+		drawer.DrawString(line)
+	}
+	return box, nil
+}
+
+// wrapLine splits line into words so that every resulting line fits maxWidth pixels
+func wrapLine(face font.Face, line string, maxWidth int) []string {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := words[0]
+	for _, word := range words[1:] {
+		candidate := current + " " + word
+		if font.MeasureString(face, candidate).Ceil() > maxWidth {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	return append(lines, current)
+}
+
+// splitLines splits text into trimmed lines
+func splitLines(text string) []string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
@@ -0,0 +1,81 @@
+package overlay
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestDrawCaption(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+
+	tests := []struct {
+		name        string
+		text        string
+		wantChanged bool
+	}{
+		{name: "Empty text", text: "  ", wantChanged: false},
+		{name: "Single line", text: "Hello", wantChanged: true},
+		{name: "Long text is wrapped", text: "a caption that is much too long to fit on a single line of this frame", wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 200, 120))
+			draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+
+			box, err := DrawCaption(img, tt.text, DefaultCaptionStyle)
+			if err != nil {
+				t.Fatalf("DrawCaption() error = %v", err)
+			}
+			if box.Empty() == tt.wantChanged {
+				t.Errorf("DrawCaption() box = %v, want empty %v", box, !tt.wantChanged)
+			}
+
+			// The caption lives at the bottom, the top of the frame must be untouched
+			if img.RGBAAt(100, 2) != white {
+				t.Errorf("DrawCaption() changed the top of the frame")
+			}
+
+			changed := false
+			for y := 60; y < 120 && !changed; y++ {
+				for x := 0; x < 200; x++ {
+					if img.RGBAAt(x, y) != white {
+						changed = true
+						break
+					}
+				}
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("DrawCaption() changed bottom = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{input: "#ff8000", want: color.RGBA{255, 128, 0, 255}},
+		{input: "00ff00", want: color.RGBA{0, 255, 0, 255}},
+		{input: "#00000080", want: color.RGBA{0, 0, 0, 128}},
+		{input: "#fff", wantErr: true},
+		{input: "#gggggg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHexColor(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHexColor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseHexColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package overlay
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"time"
+)
+
+// WAV sample formats
+const (
+	wavPCM        = 1
+	wavFloat      = 3
+	wavExtensible = 0xfffe
+)
+
+const (
+	// waveformBucket is the duration each level of a Waveform covers
+	waveformBucket = 10 * time.Millisecond
+	// waveformWindow is how much audio the strip shows, the playback
+	// position being in its middle
+	waveformWindow = 6 * time.Second
+)
+
+var (
+	// waveformBackground is behind the strip
+	waveformBackground = color.RGBA{0, 0, 0, 160}
+	// waveformPlayed and waveformAhead draw the audio before and after the
+	// playback position
+	waveformPlayed = color.RGBA{80, 200, 255, 255}
+	waveformAhead  = color.RGBA{40, 100, 128, 128}
+)
+
+// Waveform is the loudness of an audio track over time
+type Waveform struct {
+	// Levels are the peak amplitude of every waveformBucket of audio, from
+	// 0 to 1 for the loudest one
+	Levels []float64
+}
+
+// LoadWAV reads the waveform of a WAV file with integer PCM samples of 8 to
+// 32 bits or float samples, mixing its channels
+func LoadWAV(path string) (*Waveform, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading audio %s: %v", path, err)
+	}
+	w, err := parseWAV(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing audio %s: %v", path, err)
+	}
+	return w, nil
+}
+
+func parseWAV(data []byte) (*Waveform, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file")
+	}
+
+	var format, channels, rate, bits int
+	var samples []byte
+	for rest := data[12:]; len(rest) >= 8; {
+		id, size := string(rest[:4]), int(binary.LittleEndian.Uint32(rest[4:8]))
+		rest = rest[8:]
+		if size > len(rest) {
+			// Recorders stopped before finishing the file leave the data
+			// chunk size too large
+			size = len(rest)
+		}
+		chunk := rest[:size]
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("invalid fmt chunk length %d", size)
+			}
+			format = int(binary.LittleEndian.Uint16(chunk[0:2]))
+			channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			rate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			bits = int(binary.LittleEndian.Uint16(chunk[14:16]))
+			if format == wavExtensible && size >= 26 {
+				// The format is the start of the sub-format GUID
+				format = int(binary.LittleEndian.Uint16(chunk[24:26]))
+			}
+		case "data":
+			samples = chunk
+		}
+		rest = rest[min(len(rest), size+size%2):]
+	}
+
+	if channels == 0 || rate == 0 {
+		return nil, fmt.Errorf("missing or invalid fmt chunk")
+	}
+	sample, err := sampleReader(format, bits)
+	if err != nil {
+		return nil, err
+	}
+	if samples == nil {
+		return nil, fmt.Errorf("missing data chunk")
+	}
+
+	// Keep the peak of every bucket
+	size := bits / 8
+	frameSize := size * channels
+	perBucket := max(1, rate*int(waveformBucket/time.Millisecond)/1000)
+	frames := len(samples) / frameSize
+	w := &Waveform{Levels: make([]float64, (frames+perBucket-1)/perBucket)}
+	loudest := 0.0
+	for i := 0; i < frames; i++ {
+		level := &w.Levels[i/perBucket]
+		for c := 0; c < channels; c++ {
+			offset := i*frameSize + c*size
+			*level = max(*level, math.Abs(sample(samples[offset:offset+size])))
+		}
+		loudest = max(loudest, *level)
+	}
+	if loudest > 0 {
+		for i := range w.Levels {
+			w.Levels[i] /= loudest
+		}
+	}
+	return w, nil
+}
+
+// sampleReader returns the function reading a sample of the format as a
+// value from -1 to 1
+func sampleReader(format, bits int) (func([]byte) float64, error) {
+	switch {
+	case format == wavPCM && bits == 8:
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case format == wavPCM && bits == 16:
+		return func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15) }, nil
+	case format == wavPCM && bits == 24:
+		return func(b []byte) float64 {
+			return float64(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / (1 << 23)
+		}, nil
+	case format == wavPCM && bits == 32:
+		return func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31) }, nil
+	case format == wavFloat && bits == 32:
+		return func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }, nil
+	case format == wavFloat && bits == 64:
+		return func(b []byte) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b)) }, nil
+	}
+	return nil, fmt.Errorf("unsupported WAV format %d with %d bits per sample: use PCM or float samples", format, bits)
+}
+
+// level returns the loudest level of the audio between from and to, 0
+// outside the track
+func (w *Waveform) level(from, to time.Duration) float64 {
+	first := max(0, int(from/waveformBucket))
+	last := min(len(w.Levels), int((to+waveformBucket-1)/waveformBucket))
+	level := 0.0
+	for i := first; i < last; i++ {
+		level = max(level, w.Levels[i])
+	}
+	return level
+}
+
+// WaveformStrip returns the strip DrawWaveform draws along the bottom of
+// bounds
+func WaveformStrip(bounds image.Rectangle) image.Rectangle {
+	height := int(1.5 * CaptionSize(bounds))
+	return image.Rect(bounds.Min.X, bounds.Max.Y-height, bounds.Max.X, bounds.Max.Y)
+}
+
+// DrawWaveform draws the audio around the playback position as a strip
+// along the bottom of dst, scrolling so that the position stays in its
+// middle: bars of the loudness of the audio, bright for what was played
+func DrawWaveform(dst draw.Image, w *Waveform, position time.Duration) {
+	strip := WaveformStrip(dst.Bounds())
+	FillRect(dst, strip, waveformBackground)
+
+	middle := strip.Min.X + strip.Dx()/2
+	center := strip.Min.Y + strip.Dy()/2
+	perPixel := waveformWindow / time.Duration(max(1, strip.Dx()))
+	for x := strip.Min.X; x < strip.Max.X; x++ {
+		from := position + time.Duration(x-middle)*perPixel
+		if from < 0 {
+			continue
+		}
+		c := waveformPlayed
+		if x > middle {
+			c = waveformAhead
+		}
+		// Silence shows as a line
+		half := int(w.level(from, from+perPixel) * float64(strip.Dy()-2) / 2)
+		FillRect(dst, image.Rect(x, center-half, x+1, center+half+1), c)
+	}
+	FillRect(dst, image.Rect(middle, strip.Min.Y, middle+1, strip.Max.Y), color.RGBA{255, 255, 255, 255})
+}
@@ -0,0 +1,139 @@
+package overlay
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodeWAV encodes mono samples from -1 to 1 at 1000 samples per second
+func encodeWAV(format, bits int, samples []float64) []byte {
+	var data []byte
+	for _, s := range samples {
+		switch {
+		case format == wavFloat:
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(s)))
+		case bits == 8:
+			data = append(data, uint8(128+s*127))
+		case bits == 16:
+			data = binary.LittleEndian.AppendUint16(data, uint16(int16(s*32767)))
+		case bits == 24:
+			v := uint32(int32(s * 8388607))
+			data = append(data, byte(v), byte(v>>8), byte(v>>16))
+		}
+	}
+
+	fmtChunk := binary.LittleEndian.AppendUint16(nil, uint16(format))
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, 1)
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, 1000)
+	fmtChunk = binary.LittleEndian.AppendUint32(fmtChunk, uint32(1000*bits/8))
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, uint16(bits/8))
+	fmtChunk = binary.LittleEndian.AppendUint16(fmtChunk, uint16(bits))
+
+	wav := []byte("RIFF\x00\x00\x00\x00WAVE")
+	for _, chunk := range []struct {
+		id   string
+		data []byte
+	}{{"fmt ", fmtChunk}, {"data", data}} {
+		wav = append(wav, chunk.id...)
+		wav = binary.LittleEndian.AppendUint32(wav, uint32(len(chunk.data)))
+		wav = append(wav, chunk.data...)
+		if len(chunk.data)%2 == 1 {
+			wav = append(wav, 0)
+		}
+	}
+	binary.LittleEndian.PutUint32(wav[4:], uint32(len(wav)-8))
+	return wav
+}
+
+// silenceThenTone returns half a second of silence then half a second of a
+// tone at half the full scale
+func silenceThenTone() []float64 {
+	samples := make([]float64, 1000)
+	for i := 500; i < len(samples); i++ {
+		samples[i] = 0.5 * math.Sin(float64(i))
+	}
+	return samples
+}
+
+func TestParseWAV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "8 bits", data: encodeWAV(wavPCM, 8, silenceThenTone())},
+		{name: "16 bits", data: encodeWAV(wavPCM, 16, silenceThenTone())},
+		{name: "24 bits", data: encodeWAV(wavPCM, 24, silenceThenTone())},
+		{name: "float", data: encodeWAV(wavFloat, 32, silenceThenTone())},
+		{name: "truncated data", data: encodeWAV(wavPCM, 16, silenceThenTone())[:1500]},
+		{name: "not a WAV", data: []byte("RIFF\x00\x00\x00\x00AVI "), wantErr: "not a WAV file"},
+		{name: "unsupported format", data: encodeWAV(2, 16, nil), wantErr: "unsupported WAV format 2"},
+		{name: "no fmt chunk", data: []byte("RIFF\x04\x00\x00\x00WAVE"), wantErr: "missing or invalid fmt chunk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWAV(tt.data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseWAV() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseWAV() error = %v", err)
+			}
+
+			// 10 samples per bucket, the tone normalized to the full scale
+			if len(got.Levels) == 0 || len(got.Levels) > 100 {
+				t.Fatalf("parseWAV() got %d levels, want up to 100", len(got.Levels))
+			}
+			for i, level := range got.Levels {
+				if i < 50 && level > 0.02 {
+					t.Errorf("level %d = %.2f during the silence, want 0", i, level)
+				}
+				if i >= 50 && level < 0.9 {
+					t.Errorf("level %d = %.2f during the tone, want about 1", i, level)
+				}
+			}
+		})
+	}
+}
+
+func TestDrawWaveform(t *testing.T) {
+	w, err := parseWAV(encodeWAV(wavPCM, 16, silenceThenTone()))
+	if err != nil {
+		t.Fatalf("parseWAV() error = %v", err)
+	}
+
+	white := color.RGBA{255, 255, 255, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 200, 150))
+	draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+	DrawWaveform(img, w, 500*time.Millisecond)
+
+	// The 18 pixels high strip shows 30ms per pixel, the tone starting
+	// right after the playback position in the middle
+	strip := WaveformStrip(img.Bounds())
+	if strip != image.Rect(0, 132, 200, 150) {
+		t.Fatalf("WaveformStrip() = %v, want (0,132)-(200,150)", strip)
+	}
+	if img.RGBAAt(10, 145) == white {
+		t.Errorf("DrawWaveform() left the strip background undrawn")
+	}
+	y := 136
+	if bar, silence := img.RGBAAt(110, y), img.RGBAAt(90, y); bar == silence {
+		t.Errorf("DrawWaveform() tone pixel = %v, same as the silence before it", bar)
+	}
+	if img.RGBAAt(100, y) != white {
+		t.Errorf("DrawWaveform() playback position = %v, want a white line", img.RGBAAt(100, y))
+	}
+	if img.RGBAAt(10, 100) != white {
+		t.Errorf("DrawWaveform() drew above the strip")
+	}
+}
@@ -0,0 +1,63 @@
+package quality
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// MaxPSNR is the PSNR of identical images, whose error is zero
+const MaxPSNR = 100
+
+// PSNR returns the peak signal-to-noise ratio of img to reference in dB, from
+// the mean squared error of their red, green and blue components; above 40 dB
+// differences are hard to see. Both are aligned on their top left corner and
+// compared over the area they share; transparent pixels count as black.
+func PSNR(img, reference image.Image) float64 {
+	mse, ok := meanSquaredError(rgba(img), rgba(reference))
+	if !ok {
+		return 0
+	}
+	if mse == 0 {
+		return MaxPSNR
+	}
+	return min(MaxPSNR, 10*math.Log10(255*255/mse))
+}
+
+// MSE returns the mean squared error of the red, green and blue components of
+// img to reference, from 0 for identical images to 65025, comparing them like
+// PSNR does; it is 0 when they share no area
+func MSE(img, reference image.Image) float64 {
+	mse, _ := meanSquaredError(rgba(img), rgba(reference))
+	return mse
+}
+
+// meanSquaredError returns the mean squared error of the color components of
+// a and b over the area they share, reporting false when they share none
+func meanSquaredError(a, b *image.RGBA) (float64, bool) {
+	w := min(a.Rect.Dx(), b.Rect.Dx())
+	h := min(a.Rect.Dy(), b.Rect.Dy())
+	if w == 0 || h == 0 {
+		return 0, false
+	}
+
+	var sum float64
+	for y := 0; y < h; y++ {
+		rowA := a.Pix[y*a.Stride : y*a.Stride+4*w]
+		rowB := b.Pix[y*b.Stride : y*b.Stride+4*w]
+		for i := 0; i < len(rowA); i += 4 {
+			for c := 0; c < 3; c++ {
+				d := float64(rowA[i+c]) - float64(rowB[i+c])
+				sum += d * d
+			}
+		}
+	}
+	return sum / float64(3*w*h), true
+}
+
+// rgba returns img moved to the origin, premultiplied by its alpha
+func rgba(img image.Image) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(out, out.Rect, img, img.Bounds().Min, draw.Src)
+	return out
+}
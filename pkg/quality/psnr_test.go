@@ -0,0 +1,74 @@
+package quality
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestPSNR(t *testing.T) {
+	gray := func(v uint8) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for i := range img.Pix {
+			img.Pix[i] = v
+		}
+		return img
+	}
+	reference := gray(128)
+	moved := image.NewNRGBA(image.Rect(5, 5, 21, 21))
+	for y := 5; y < 21; y++ {
+		for x := 5; x < 21; x++ {
+			moved.Set(x, y, color.NRGBA{128, 128, 128, 255})
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want float64
+	}{
+		{name: "identical", img: reference, want: MaxPSNR},
+		{name: "other bounds", img: moved, want: MaxPSNR},
+		// An error of 1 in every component
+		{name: "off by one", img: gray(129), want: 10 * math.Log10(255*255)},
+		{name: "off by 16", img: gray(144), want: 10 * math.Log10(255*255/256.0)},
+		{name: "empty", img: image.NewRGBA(image.Rect(0, 0, 0, 0)), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PSNR(tt.img, reference); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PSNR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMSE(t *testing.T) {
+	gray := func(v uint8) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		for i := range img.Pix {
+			img.Pix[i] = v
+		}
+		return img
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want float64
+	}{
+		{name: "identical", img: gray(100), want: 0},
+		{name: "off by 3", img: gray(103), want: 9},
+		{name: "empty", img: image.NewRGBA(image.Rect(0, 0, 0, 0)), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MSE(tt.img, gray(100)); got != tt.want {
+				t.Errorf("MSE() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package quality scores how closely an image reproduces a reference image
+package quality
+
+import (
+	"image"
+	"image/draw"
+)
+
+const (
+	// ssimWindow is the side of the square windows SSIM compares
+	ssimWindow = 8
+	// ssimStride is the distance between two windows
+	ssimStride = 4
+	// ssimC1 and ssimC2 stabilize the division of dark and flat windows,
+	// (0.01*255)² and (0.03*255)²
+	ssimC1 = 6.5025
+	ssimC2 = 58.5225
+)
+
+// SSIM returns the mean structural similarity of the luma of img and
+// reference, from -1 to 1 for identical images, comparing overlapping 8x8
+// windows. Both are aligned on their top left corner and compared over the
+// area they share; transparent pixels count as black.
+func SSIM(img, reference image.Image) float64 {
+	a, b := luma(img), luma(reference)
+	w := min(a.Rect.Dx(), b.Rect.Dx())
+	h := min(a.Rect.Dy(), b.Rect.Dy())
+	if w == 0 || h == 0 {
+		return 0
+	}
+
+	// Images smaller than a window are a single window
+	winW, winH := min(ssimWindow, w), min(ssimWindow, h)
+	total, windows := 0.0, 0
+	for y := 0; y+winH <= h; y += ssimStride {
+		for x := 0; x+winW <= w; x += ssimStride {
+			total += windowSSIM(a, b, x, y, winW, winH)
+			windows++
+		}
+	}
+	return total / float64(windows)
+}
+
+// windowSSIM returns the structural similarity of the w x h windows of a and
+// b at x, y
+func windowSSIM(a, b *image.Gray, x, y, w, h int) float64 {
+	var sumA, sumB, sumAA, sumBB, sumAB float64
+	for j := y; j < y+h; j++ {
+		rowA := a.Pix[j*a.Stride+x : j*a.Stride+x+w]
+		rowB := b.Pix[j*b.Stride+x : j*b.Stride+x+w]
+		for i := range rowA {
+			va, vb := float64(rowA[i]), float64(rowB[i])
+			sumA += va
+			sumB += vb
+			sumAA += va * va
+			sumBB += vb * vb
+			sumAB += va * vb
+		}
+	}
+	n := float64(w * h)
+	meanA, meanB := sumA/n, sumB/n
+	varA := sumAA/n - meanA*meanA
+	varB := sumBB/n - meanB*meanB
+	cov := sumAB/n - meanA*meanB
+	return (2*meanA*meanB + ssimC1) * (2*cov + ssimC2) /
+		((meanA*meanA + meanB*meanB + ssimC1) * (varA + varB + ssimC2))
+}
+
+// luma returns the luma of img moved to the origin
+func luma(img image.Image) *image.Gray {
+	gray := image.NewGray(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(gray, gray.Rect, img, img.Bounds().Min, draw.Src)
+	return gray
+}
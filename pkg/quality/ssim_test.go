@@ -0,0 +1,57 @@
+package quality
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func TestSSIM(t *testing.T) {
+	gradient := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			gradient.SetGray(x, y, color.Gray{Y: uint8(x * 8)})
+		}
+	}
+	// noise returns gradient with random changes of up to amount
+	rng := rand.New(rand.NewSource(1))
+	noise := func(amount int) *image.Gray {
+		noisy := image.NewGray(gradient.Rect)
+		for i, v := range gradient.Pix {
+			noisy.Pix[i] = uint8(max(0, min(255, int(v)+rng.Intn(2*amount+1)-amount)))
+		}
+		return noisy
+	}
+	light, heavy := noise(8), noise(40)
+	moved := image.NewRGBA(image.Rect(10, 10, 42, 42))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			moved.Set(x+10, y+10, gradient.GrayAt(x, y))
+		}
+	}
+
+	tests := []struct {
+		name     string
+		img      image.Image
+		min, max float64
+	}{
+		{name: "identical", img: gradient, min: 0.9999, max: 1},
+		{name: "other bounds", img: moved, min: 0.9999, max: 1},
+		{name: "light noise", img: light, min: 0.5, max: 0.99},
+		{name: "heavy noise", img: heavy, min: 0, max: 0.9},
+		{name: "empty", img: image.NewGray(image.Rect(0, 0, 0, 0)), min: 0, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SSIM(tt.img, gradient); got < tt.min || got > tt.max {
+				t.Errorf("SSIM() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+
+	if SSIM(heavy, gradient) >= SSIM(light, gradient) {
+		t.Errorf("heavy noise scored %v, above light noise %v", SSIM(heavy, gradient), SSIM(light, gradient))
+	}
+}
@@ -0,0 +1,120 @@
+package termrender
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxTerminalSize bounds the columns and rows of a recording, far above real
+// terminals, so a crafted header cannot allocate a gigantic screen
+const maxTerminalSize = 1000
+
+// Header is the first line of an asciinema v2 recording
+type Header struct {
+	Version int    `json:"version"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Title   string `json:"title,omitempty"`
+	// IdleTimeLimit caps the pauses between events, in seconds
+	IdleTimeLimit float64 `json:"idle_time_limit,omitempty"`
+	Theme         *Colors `json:"theme,omitempty"`
+}
+
+// Colors is the optional theme embedded in a recording header
+type Colors struct {
+	Foreground string `json:"fg"`
+	Background string `json:"bg"`
+	// Palette holds 8 or 16 colon separated colors
+	Palette string `json:"palette"`
+}
+
+// Event is an output event of a recording
+type Event struct {
+	// Time is the number of seconds since the start of the recording
+	Time float64
+	// Data is the text written to the terminal
+	Data string
+}
+
+// Cast is a parsed asciinema recording
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// LoadCast reads an asciinema v2 .cast file
+func LoadCast(path string) (*Cast, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording %s: %v", path, err)
+	}
+	defer file.Close()
+
+	cast, err := ParseCast(file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing recording %s: %v", path, err)
+	}
+	return cast, nil
+}
+
+// ParseCast parses an asciinema v2 recording: a JSON header line followed by
+// [time, type, data] event lines. Only output ("o") events are kept.
+func ParseCast(r io.Reader) (*Cast, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("empty recording")
+	}
+
+	cast := &Cast{}
+	if err := json.Unmarshal(scanner.Bytes(), &cast.Header); err != nil {
+		return nil, fmt.Errorf("invalid header: %v", err)
+	}
+	if cast.Header.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version %d (only version 2 is supported)", cast.Header.Version)
+	}
+	if cast.Header.Width <= 0 || cast.Header.Height <= 0 || cast.Header.Width > maxTerminalSize || cast.Header.Height > maxTerminalSize {
+		return nil, fmt.Errorf("invalid terminal size %dx%d", cast.Header.Width, cast.Header.Height)
+	}
+
+	lineNo := 1
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var raw []json.RawMessage
+		if err := json.Unmarshal([]byte(line), &raw); err != nil || len(raw) != 3 {
+			return nil, fmt.Errorf("line %d: expected [time, type, data]", lineNo)
+		}
+		var event Event
+		var kind string
+		if err := json.Unmarshal(raw[0], &event.Time); err != nil {
+			return nil, fmt.Errorf("line %d: invalid time: %v", lineNo, err)
+		}
+		if err := json.Unmarshal(raw[1], &kind); err != nil {
+			return nil, fmt.Errorf("line %d: invalid type: %v", lineNo, err)
+		}
+		if err := json.Unmarshal(raw[2], &event.Data); err != nil {
+			return nil, fmt.Errorf("line %d: invalid data: %v", lineNo, err)
+		}
+		if kind != "o" {
+			continue
+		}
+		cast.Events = append(cast.Events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cast, nil
+}
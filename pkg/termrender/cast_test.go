@@ -0,0 +1,122 @@
+package termrender
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCast(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantEvents int
+		wantErr    bool
+	}{
+		{
+			name: "output and input events",
+			input: `{"version": 2, "width": 80, "height": 24}
+[0.1, "o", "hello"]
+[0.2, "i", "x"]
+[0.5, "o", "\r\n"]
+`,
+			wantEvents: 2,
+		},
+		{
+			name:       "header only",
+			input:      `{"version": 2, "width": 10, "height": 5}`,
+			wantEvents: 0,
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:    "version 1",
+			input:   `{"version": 1, "width": 80, "height": 24}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing size",
+			input:   `{"version": 2}`,
+			wantErr: true,
+		},
+		{
+			name:    "oversized terminal",
+			input:   `{"version": 2, "width": 100000, "height": 100000}`,
+			wantErr: true,
+		},
+		{
+			name: "malformed event",
+			input: `{"version": 2, "width": 80, "height": 24}
+[0.1, "o"]
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cast, err := ParseCast(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCast() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(cast.Events) != tt.wantEvents {
+				t.Errorf("ParseCast() got %d events, want %d", len(cast.Events), tt.wantEvents)
+			}
+		})
+	}
+}
+
+func FuzzParseCast(f *testing.F) {
+	f.Add(`{"version": 2, "width": 20, "height": 3}
+[0.0, "o", "$ ls\r\n\u001b[1;31mfile\u001b[0m"]
+[0.5, "o", "\u001b[2J\u001b[H"]
+`)
+	f.Add(`{"version": 2, "width": 1, "height": 1}`)
+	f.Fuzz(func(t *testing.T, input string) {
+		cast, err := ParseCast(strings.NewReader(input))
+		if err != nil {
+			return
+		}
+		if cast.Header.Width > maxTerminalSize || cast.Header.Height > maxTerminalSize {
+			t.Fatalf("ParseCast() accepted a %dx%d terminal", cast.Header.Width, cast.Header.Height)
+		}
+	})
+}
+
+func TestLoadCast(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "session.cast")
+	content := `{"version": 2, "width": 20, "height": 4, "theme": {"fg": "#ffffff", "bg": "#000000", "palette": "#000000:#ff0000:#00ff00:#ffff00:#0000ff:#ff00ff:#00ffff:#ffffff"}}
+[0.0, "o", "$ ls\r\n"]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write recording: %v", err)
+	}
+
+	cast, err := LoadCast(path)
+	if err != nil {
+		t.Fatalf("LoadCast() error = %v", err)
+	}
+	if cast.Header.Width != 20 || cast.Header.Height != 4 {
+		t.Errorf("LoadCast() size = %dx%d, want 20x4", cast.Header.Width, cast.Header.Height)
+	}
+	if cast.Header.Theme == nil || cast.Header.Theme.Foreground != "#ffffff" {
+		t.Errorf("LoadCast() theme = %+v, want foreground #ffffff", cast.Header.Theme)
+	}
+
+	if _, err := LoadCast(filepath.Join(tempDir, "missing.cast")); err == nil {
+		t.Error("LoadCast() expected error for a missing file")
+	}
+}
@@ -0,0 +1,279 @@
+package termrender
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// DefaultFontSize is the font size in pixels used when Options.FontSize is unset
+	DefaultFontSize = 16
+	// DefaultFPS is the frame rate used when Options.FPS is unset
+	DefaultFPS = 10
+	// lastFrameHold is how long the final screen stays visible
+	lastFrameHold = time.Second
+)
+
+// Options holds the settings used by Render
+type Options struct {
+	// FontSize is the size of the embedded monospace font in pixels
+	FontSize float64
+	// FPS is the maximum number of frames per second, output written within
+	// the same frame interval is merged
+	FPS int
+	// Theme overrides both the default theme and the one of the recording
+	Theme *Theme
+	// MaxFrames stops the rendering with a *decode.LimitError before drawing
+	// more frames, 0 for no limit
+	MaxFrames int
+}
+
+// Frame is a rendered screen and how long it is shown
+type Frame struct {
+	Image *image.RGBA
+	Delay time.Duration
+}
+
+// Renderer draws terminal screens with the embedded Go Mono fonts
+type Renderer struct {
+	theme      Theme
+	regular    font.Face
+	bold       font.Face
+	cellWidth  int
+	cellHeight int
+	ascent     int
+	padding    int
+}
+
+// NewRenderer loads the fonts at the given size
+func NewRenderer(size float64, theme Theme) (*Renderer, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("font size must be positive")
+	}
+	regular, err := loadFace(gomono.TTF, size)
+	if err != nil {
+		return nil, err
+	}
+	bold, err := loadFace(gomonobold.TTF, size)
+	if err != nil {
+		return nil, err
+	}
+
+	advance, _ := regular.GlyphAdvance('M')
+	metrics := regular.Metrics()
+	return &Renderer{
+		theme:      theme,
+		regular:    regular,
+		bold:       bold,
+		cellWidth:  advance.Ceil(),
+		cellHeight: metrics.Height.Ceil(),
+		ascent:     metrics.Ascent.Ceil(),
+		padding:    int(math.Round(size / 2)),
+	}, nil
+}
+
+func loadFace(ttf []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing embedded font: %v", err)
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+// Bounds returns the size of the image rendered for a terminal of cols x rows
+func (r *Renderer) Bounds(cols, rows int) image.Rectangle {
+	return image.Rect(0, 0, cols*r.cellWidth+2*r.padding, rows*r.cellHeight+2*r.padding)
+}
+
+// Cell returns the rectangle of the cell at column x and row y
+func (r *Renderer) Cell(x, y int) image.Rectangle {
+	left := r.padding + x*r.cellWidth
+	top := r.padding + y*r.cellHeight
+	return image.Rect(left, top, left+r.cellWidth, top+r.cellHeight)
+}
+
+// Baseline returns the offset of the text baseline from the top of a cell
+func (r *Renderer) Baseline() int {
+	return r.ascent
+}
+
+// Draw renders the current screen of the terminal
+func (r *Renderer) Draw(t *Terminal) *image.RGBA {
+	img := image.NewRGBA(r.Bounds(t.cols, t.rows))
+	draw.Draw(img, img.Bounds(), image.NewUniform(r.theme.Background), image.Point{}, draw.Src)
+
+	for y, row := range t.cells {
+		for x, c := range row {
+			cursor := t.cursorVisible && x == min(t.x, t.cols-1) && y == t.y
+			r.drawCell(img, x, y, c, cursor)
+		}
+	}
+	return img
+}
+
+func (r *Renderer) drawCell(img *image.RGBA, x, y int, c cell, cursor bool) {
+	fgIndex := c.fg
+	// Bold text uses the bright variants of the 8 basic colors
+	if c.bold && fgIndex >= 0 && fgIndex < 8 {
+		fgIndex += 8
+	}
+	fg := r.theme.Color(fgIndex, true)
+	bg := r.theme.Color(c.bg, false)
+	if c.reverse != cursor {
+		fg, bg = bg, fg
+	}
+
+	rect := r.Cell(x, y)
+	left, top := rect.Min.X, rect.Min.Y
+	if bg != r.theme.Background {
+		draw.Draw(img, rect, image.NewUniform(bg), image.Point{}, draw.Src)
+	}
+
+	if c.r != ' ' && c.r != 0 {
+		face := r.regular
+		if c.bold {
+			face = r.bold
+		}
+		d := &font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(fg),
+			Face: face,
+			Dot:  fixed.P(left, top+r.ascent),
+		}
+		d.DrawString(string(c.r))
+	}
+
+	if c.underline {
+		line := image.Rect(left, top+r.ascent+1, left+r.cellWidth, top+r.ascent+2)
+		draw.Draw(img, line, image.NewUniform(fg), image.Point{}, draw.Src)
+	}
+}
+
+// Render replays a recording and returns its frames. Output written within
+// the same 1/FPS interval is merged into a single frame, and intervals that
+// leave the screen unchanged do not produce frames.
+func Render(cast *Cast, opts Options) ([]Frame, error) {
+	if opts.FontSize == 0 {
+		opts.FontSize = DefaultFontSize
+	}
+	if opts.FPS == 0 {
+		opts.FPS = DefaultFPS
+	}
+	if opts.FPS < 0 {
+		return nil, fmt.Errorf("fps must be positive")
+	}
+
+	theme := DefaultTheme
+	if opts.Theme != nil {
+		theme = *opts.Theme
+	} else if cast.Header.Theme != nil {
+		var err error
+		if theme, err = ParseTheme(*cast.Header.Theme); err != nil {
+			return nil, err
+		}
+	}
+
+	renderer, err := NewRenderer(opts.FontSize, theme)
+	if err != nil {
+		return nil, err
+	}
+
+	term := NewTerminal(cast.Header.Width, cast.Header.Height)
+	interval := time.Second / time.Duration(opts.FPS)
+
+	var frames []Frame
+	var times []time.Duration
+	var last *snapshot
+	emit := func(at time.Duration) error {
+		snap := takeSnapshot(term)
+		if last != nil && snap.equal(last) {
+			return nil
+		}
+		if opts.MaxFrames > 0 && len(frames) == opts.MaxFrames {
+			return &decode.LimitError{Limit: decode.LimitFrames, Max: int64(opts.MaxFrames)}
+		}
+		last = snap
+		frames = append(frames, Frame{Image: renderer.Draw(term)})
+		times = append(times, at)
+		return nil
+	}
+
+	// Replay the events on a timeline where long pauses are capped
+	var clock, previous time.Duration
+	limit := time.Duration(cast.Header.IdleTimeLimit * float64(time.Second))
+	bucket := time.Duration(-1)
+	for _, event := range cast.Events {
+		at := time.Duration(event.Time * float64(time.Second))
+		gap := max(0, at-previous)
+		if limit > 0 {
+			gap = min(gap, limit)
+		}
+		clock += gap
+		previous = at
+
+		// Flush the previous interval before writing to a new one
+		current := clock / interval * interval
+		if current != bucket && bucket >= 0 {
+			if err := emit(bucket); err != nil {
+				return nil, err
+			}
+		}
+		bucket = current
+		term.Write(event.Data)
+	}
+	if err := emit(max(0, bucket)); err != nil {
+		return nil, err
+	}
+
+	for i := range frames {
+		if i+1 < len(frames) {
+			frames[i].Delay = times[i+1] - times[i]
+		} else {
+			frames[i].Delay = lastFrameHold
+		}
+	}
+	return frames, nil
+}
+
+// snapshot is a copy of the visible state of a terminal
+type snapshot struct {
+	cells         [][]cell
+	x, y          int
+	cursorVisible bool
+}
+
+func takeSnapshot(t *Terminal) *snapshot {
+	cells := make([][]cell, len(t.cells))
+	for y, row := range t.cells {
+		cells[y] = append([]cell(nil), row...)
+	}
+	return &snapshot{cells: cells, x: t.x, y: t.y, cursorVisible: t.cursorVisible}
+}
+
+func (s *snapshot) equal(o *snapshot) bool {
+	if s.x != o.x || s.y != o.y || s.cursorVisible != o.cursorVisible {
+		return false
+	}
+	for y := range s.cells {
+		for x := range s.cells[y] {
+			if s.cells[y][x] != o.cells[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
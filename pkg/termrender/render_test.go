@@ -0,0 +1,160 @@
+package termrender
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/decode"
+)
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		colors  Colors
+		wantErr bool
+	}{
+		{
+			name:   "8 colors",
+			colors: Colors{Foreground: "#ffffff", Background: "#000000", Palette: "#000000:#ff0000:#00ff00:#ffff00:#0000ff:#ff00ff:#00ffff:#ffffff"},
+		},
+		{
+			name:    "invalid palette length",
+			colors:  Colors{Foreground: "#ffffff", Background: "#000000", Palette: "#000000:#ff0000"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid foreground",
+			colors:  Colors{Foreground: "white", Background: "#000000", Palette: testPalette()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, err := ParseTheme(tt.colors)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTheme() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			// 8 color palettes are reused for the bright variants
+			if theme.Palette[9] != theme.Palette[1] {
+				t.Errorf("bright red = %v, want %v", theme.Palette[9], theme.Palette[1])
+			}
+		})
+	}
+}
+
+// testPalette returns a valid 16 color palette for tests
+func testPalette() string {
+	return strings.Repeat("#101010:", 15) + "#101010"
+}
+
+func TestThemeColor(t *testing.T) {
+	theme := DefaultTheme
+	tests := []struct {
+		name string
+		c    int32
+		want color.RGBA
+	}{
+		{"ansi", 1, theme.Palette[1]},
+		{"cube", 16 + 36*5, color.RGBA{255, 0, 0, 255}},
+		{"grayscale", 232, color.RGBA{8, 8, 8, 255}},
+		{"rgb", colorRGBFlag | 0x102030, color.RGBA{0x10, 0x20, 0x30, 255}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := theme.Color(tt.c, true); got != tt.want {
+				t.Errorf("Color(%d) = %v, want %v", tt.c, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	cast, err := ParseCast(strings.NewReader(`{"version": 2, "width": 20, "height": 3, "idle_time_limit": 2}
+[0.00, "o", "$ "]
+[0.02, "o", "l"]
+[0.50, "o", "s"]
+[0.51, "o", "\u001b[?25l"]
+[0.52, "o", "\u001b[?25h"]
+[10.0, "o", "\r\nfile.txt\r\n$ "]
+`))
+	if err != nil {
+		t.Fatalf("ParseCast() error = %v", err)
+	}
+
+	frames, err := Render(cast, Options{FontSize: 12, FPS: 10})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	// The prompt and "l" are merged, hiding and showing the cursor within
+	// the same interval leaves the screen unchanged
+	if len(frames) != 3 {
+		t.Fatalf("Render() got %d frames, want 3", len(frames))
+	}
+	wantDelays := []time.Duration{500 * time.Millisecond, 2 * time.Second, time.Second}
+	for i, frame := range frames {
+		if frame.Delay != wantDelays[i] {
+			t.Errorf("frame %d delay = %v, want %v", i, frame.Delay, wantDelays[i])
+		}
+	}
+
+	renderer, err := NewRenderer(12, DefaultTheme)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	want := renderer.Bounds(20, 3)
+	if frames[0].Image.Bounds() != want {
+		t.Errorf("frame bounds = %v, want %v", frames[0].Image.Bounds(), want)
+	}
+	if corner := frames[0].Image.RGBAAt(0, 0); corner != DefaultTheme.Background {
+		t.Errorf("padding color = %v, want theme background %v", corner, DefaultTheme.Background)
+	}
+}
+
+func TestRenderMaxFrames(t *testing.T) {
+	cast, err := ParseCast(strings.NewReader(`{"version": 2, "width": 10, "height": 2}
+[0.0, "o", "1"]
+[0.5, "o", "2"]
+[1.0, "o", "3"]
+`))
+	if err != nil {
+		t.Fatalf("ParseCast() error = %v", err)
+	}
+
+	if frames, err := Render(cast, Options{MaxFrames: 3}); err != nil || len(frames) != 3 {
+		t.Fatalf("Render() = %d frames, %v, want 3", len(frames), err)
+	}
+	_, err = Render(cast, Options{MaxFrames: 2})
+	limit, ok := err.(*decode.LimitError)
+	if !ok || limit.Limit != decode.LimitFrames || limit.Max != 2 {
+		t.Errorf("Render() error = %#v, want a frames LimitError", err)
+	}
+}
+
+func TestRendererCell(t *testing.T) {
+	renderer, err := NewRenderer(12, DefaultTheme)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	first, next := renderer.Cell(0, 0), renderer.Cell(2, 1)
+	if first.Min != (image.Point{X: renderer.padding, Y: renderer.padding}) {
+		t.Errorf("Cell(0, 0) = %v, want at the padding", first)
+	}
+	if next.Min.X != first.Min.X+2*first.Dx() || next.Min.Y != first.Min.Y+first.Dy() {
+		t.Errorf("Cell(2, 1) = %v, want 2 cells right and 1 down of %v", next, first)
+	}
+	if !renderer.Bounds(3, 2).Inset(renderer.padding).Eq(first.Union(next)) {
+		t.Errorf("cells %v and %v do not fill the bounds %v", first, next, renderer.Bounds(3, 2))
+	}
+	if b := renderer.Baseline(); b <= 0 || b >= first.Dy() {
+		t.Errorf("Baseline() = %d, want within a cell of height %d", b, first.Dy())
+	}
+}
@@ -0,0 +1,507 @@
+package termrender
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Colors of a cell are either the theme default, one of the 256 indexed colors or a 24-bit value
+const (
+	colorDefault = -1
+	colorRGBFlag = 1 << 24
+)
+
+// cell is a single character position of the screen
+type cell struct {
+	r         rune
+	fg, bg    int32
+	bold      bool
+	underline bool
+	reverse   bool
+}
+
+// blankCell is an empty cell using the given background
+func blankCell(bg int32) cell {
+	return cell{r: ' ', fg: colorDefault, bg: bg}
+}
+
+// parser states
+const (
+	stateGround = iota
+	stateEscape
+	stateCSI
+	stateOSC
+	stateOSCEscape
+	stateCharset
+)
+
+// Terminal is a minimal VT100/xterm emulator good enough to replay shell sessions
+type Terminal struct {
+	cols, rows int
+	cells      [][]cell
+	// alternate holds the primary screen while the alternate screen is active
+	alternate [][]cell
+
+	x, y          int
+	savedX        int
+	savedY        int
+	pen           cell
+	cursorVisible bool
+	scrollTop     int
+	scrollBottom  int
+
+	state  int
+	params strings.Builder
+}
+
+// NewTerminal creates a cleared terminal of the given size
+func NewTerminal(cols, rows int) *Terminal {
+	t := &Terminal{cols: cols, rows: rows}
+	t.reset()
+	return t
+}
+
+func (t *Terminal) reset() {
+	t.cells = t.blankScreen()
+	t.alternate = nil
+	t.x, t.y = 0, 0
+	t.savedX, t.savedY = 0, 0
+	t.pen = blankCell(colorDefault)
+	t.cursorVisible = true
+	t.scrollTop, t.scrollBottom = 0, t.rows-1
+	t.state = stateGround
+}
+
+func (t *Terminal) blankScreen() [][]cell {
+	cells := make([][]cell, t.rows)
+	for y := range cells {
+		cells[y] = t.blankLine()
+	}
+	return cells
+}
+
+func (t *Terminal) blankLine() []cell {
+	line := make([]cell, t.cols)
+	for x := range line {
+		line[x] = blankCell(t.pen.bg)
+	}
+	return line
+}
+
+// Size returns the number of columns and rows
+func (t *Terminal) Size() (int, int) {
+	return t.cols, t.rows
+}
+
+// Write feeds output to the terminal
+func (t *Terminal) Write(data string) {
+	for _, r := range data {
+		t.feed(r)
+	}
+}
+
+func (t *Terminal) feed(r rune) {
+	switch t.state {
+	case stateEscape:
+		t.escape(r)
+		return
+	case stateCSI:
+		if r >= 0x40 && r <= 0x7e {
+			t.state = stateGround
+			t.csi(r, t.params.String())
+			return
+		}
+		t.params.WriteRune(r)
+		return
+	case stateOSC:
+		// Operating system commands (window title...) are ignored
+		switch r {
+		case 0x07:
+			t.state = stateGround
+		case 0x1b:
+			t.state = stateOSCEscape
+		}
+		return
+	case stateOSCEscape:
+		t.state = stateGround
+		return
+	case stateCharset:
+		t.state = stateGround
+		return
+	}
+
+	switch r {
+	case 0x1b:
+		t.state = stateEscape
+	case '\r':
+		t.x = 0
+	case '\n', '\v', '\f':
+		t.lineFeed()
+	case '\b':
+		if t.x > 0 {
+			t.x = min(t.x, t.cols) - 1
+		}
+	case '\t':
+		t.x = min(t.cols-1, (t.x/8+1)*8)
+	case 0x07, 0x00, 0x0e, 0x0f:
+		// Bell and shift in/out have no visual effect
+	default:
+		if r < 0x20 || r == 0x7f {
+			return
+		}
+		t.put(r)
+	}
+}
+
+func (t *Terminal) put(r rune) {
+	// Wrap when the previous character filled the last column
+	if t.x >= t.cols {
+		t.x = 0
+		t.lineFeed()
+	}
+	c := t.pen
+	c.r = r
+	t.cells[t.y][t.x] = c
+	t.x++
+}
+
+func (t *Terminal) lineFeed() {
+	if t.y == t.scrollBottom {
+		t.scrollUp(1)
+		return
+	}
+	if t.y < t.rows-1 {
+		t.y++
+	}
+}
+
+func (t *Terminal) reverseIndex() {
+	if t.y == t.scrollTop {
+		t.scrollDown(1)
+		return
+	}
+	if t.y > 0 {
+		t.y--
+	}
+}
+
+// scrollUp moves the lines of the scroll region up by n, adding blank lines at the bottom
+func (t *Terminal) scrollUp(n int) {
+	n = min(n, t.scrollBottom-t.scrollTop+1)
+	copy(t.cells[t.scrollTop:], t.cells[t.scrollTop+n:t.scrollBottom+1])
+	for y := t.scrollBottom - n + 1; y <= t.scrollBottom; y++ {
+		t.cells[y] = t.blankLine()
+	}
+}
+
+// scrollDown moves the lines of the scroll region down by n, adding blank lines at the top
+func (t *Terminal) scrollDown(n int) {
+	n = min(n, t.scrollBottom-t.scrollTop+1)
+	copy(t.cells[t.scrollTop+n:t.scrollBottom+1], t.cells[t.scrollTop:t.scrollBottom+1-n])
+	for y := t.scrollTop; y < t.scrollTop+n; y++ {
+		t.cells[y] = t.blankLine()
+	}
+}
+
+func (t *Terminal) escape(r rune) {
+	t.state = stateGround
+	switch r {
+	case '[':
+		t.state = stateCSI
+		t.params.Reset()
+	case ']':
+		t.state = stateOSC
+	case '(', ')', '*', '+':
+		t.state = stateCharset
+	case '7':
+		t.savedX, t.savedY = t.x, t.y
+	case '8':
+		t.x, t.y = t.savedX, t.savedY
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.x = 0
+		t.lineFeed()
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.reset()
+	}
+}
+
+// parseParams splits CSI parameters, missing values default to def
+func parseParams(s string, def int) []int {
+	if s == "" {
+		return []int{def}
+	}
+	parts := strings.Split(s, ";")
+	params := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || part == "" {
+			n = def
+		}
+		params[i] = n
+	}
+	return params
+}
+
+func (t *Terminal) csi(final rune, raw string) {
+	private := strings.HasPrefix(raw, "?")
+	raw = strings.TrimLeft(raw, "?>=!")
+	params := parseParams(raw, 0)
+	// n returns parameter i, treating 0 as 1 like most cursor commands do
+	n := func(i int) int {
+		if i < len(params) && params[i] > 0 {
+			return params[i]
+		}
+		return 1
+	}
+
+	switch final {
+	case 'A':
+		t.y = max(t.scrollTopFor(t.y), t.y-n(0))
+	case 'B', 'e':
+		t.y = min(t.scrollBottomFor(t.y), t.y+n(0))
+	case 'C', 'a':
+		t.x = min(t.cols-1, t.x+n(0))
+	case 'D':
+		t.x = max(0, min(t.x, t.cols-1)-n(0))
+	case 'E':
+		t.x = 0
+		t.y = min(t.rows-1, t.y+n(0))
+	case 'F':
+		t.x = 0
+		t.y = max(0, t.y-n(0))
+	case 'G', '`':
+		t.x = clamp(n(0)-1, 0, t.cols-1)
+	case 'd':
+		t.y = clamp(n(0)-1, 0, t.rows-1)
+	case 'H', 'f':
+		t.y = clamp(n(0)-1, 0, t.rows-1)
+		t.x = clamp(n(1)-1, 0, t.cols-1)
+	case 'J':
+		t.eraseDisplay(params[0])
+	case 'K':
+		t.eraseLine(params[0])
+	case 'X':
+		for x := t.x; x < min(t.cols, t.x+n(0)); x++ {
+			t.cells[t.y][x] = blankCell(t.pen.bg)
+		}
+	case 'P':
+		t.deleteChars(n(0))
+	case '@':
+		t.insertChars(n(0))
+	case 'L':
+		t.insertLines(n(0))
+	case 'M':
+		t.deleteLines(n(0))
+	case 'S':
+		t.scrollUp(n(0))
+	case 'T':
+		t.scrollDown(n(0))
+	case 'r':
+		top, bottom := n(0)-1, t.rows-1
+		if len(params) > 1 && params[1] > 0 {
+			bottom = params[1] - 1
+		}
+		if top < bottom && bottom < t.rows {
+			t.scrollTop, t.scrollBottom = top, bottom
+			t.x, t.y = 0, 0
+		}
+	case 's':
+		t.savedX, t.savedY = t.x, t.y
+	case 'u':
+		t.x, t.y = t.savedX, t.savedY
+	case 'm':
+		t.sgr(parseParams(raw, 0))
+	case 'h', 'l':
+		if private {
+			t.setMode(params, final == 'h')
+		}
+	}
+}
+
+func (t *Terminal) scrollTopFor(y int) int {
+	if y >= t.scrollTop {
+		return t.scrollTop
+	}
+	return 0
+}
+
+func (t *Terminal) scrollBottomFor(y int) int {
+	if y <= t.scrollBottom {
+		return t.scrollBottom
+	}
+	return t.rows - 1
+}
+
+func (t *Terminal) setMode(params []int, enable bool) {
+	for _, mode := range params {
+		switch mode {
+		case 25:
+			t.cursorVisible = enable
+		case 47, 1047, 1049:
+			if enable && t.alternate == nil {
+				if mode == 1049 {
+					t.savedX, t.savedY = t.x, t.y
+				}
+				t.alternate = t.cells
+				t.cells = t.blankScreen()
+			} else if !enable && t.alternate != nil {
+				t.cells = t.alternate
+				t.alternate = nil
+				if mode == 1049 {
+					t.x, t.y = t.savedX, t.savedY
+				}
+			}
+		}
+	}
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for y := t.y + 1; y < t.rows; y++ {
+			t.cells[y] = t.blankLine()
+		}
+	case 1:
+		t.eraseLine(1)
+		for y := 0; y < t.y; y++ {
+			t.cells[y] = t.blankLine()
+		}
+	case 2, 3:
+		for y := range t.cells {
+			t.cells[y] = t.blankLine()
+		}
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	from, to := 0, t.cols
+	switch mode {
+	case 0:
+		from = min(t.x, t.cols)
+	case 1:
+		to = min(t.x+1, t.cols)
+	}
+	for x := from; x < to; x++ {
+		t.cells[t.y][x] = blankCell(t.pen.bg)
+	}
+}
+
+func (t *Terminal) deleteChars(n int) {
+	line := t.cells[t.y]
+	x := min(t.x, t.cols)
+	n = min(n, t.cols-x)
+	copy(line[x:], line[x+n:])
+	for i := t.cols - n; i < t.cols; i++ {
+		line[i] = blankCell(t.pen.bg)
+	}
+}
+
+func (t *Terminal) insertChars(n int) {
+	line := t.cells[t.y]
+	x := min(t.x, t.cols)
+	n = min(n, t.cols-x)
+	copy(line[x+n:], line[x:])
+	for i := x; i < x+n; i++ {
+		line[i] = blankCell(t.pen.bg)
+	}
+}
+
+func (t *Terminal) insertLines(n int) {
+	if t.y < t.scrollTop || t.y > t.scrollBottom {
+		return
+	}
+	top := t.scrollTop
+	t.scrollTop = t.y
+	t.scrollDown(min(n, t.scrollBottom-t.y+1))
+	t.scrollTop = top
+}
+
+func (t *Terminal) deleteLines(n int) {
+	if t.y < t.scrollTop || t.y > t.scrollBottom {
+		return
+	}
+	top := t.scrollTop
+	t.scrollTop = t.y
+	t.scrollUp(min(n, t.scrollBottom-t.y+1))
+	t.scrollTop = top
+}
+
+// sgr applies Select Graphic Rendition parameters to the pen
+func (t *Terminal) sgr(params []int) {
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		switch {
+		case p == 0:
+			t.pen = blankCell(colorDefault)
+		case p == 1:
+			t.pen.bold = true
+		case p == 4:
+			t.pen.underline = true
+		case p == 7:
+			t.pen.reverse = true
+		case p == 22:
+			t.pen.bold = false
+		case p == 24:
+			t.pen.underline = false
+		case p == 27:
+			t.pen.reverse = false
+		case p >= 30 && p <= 37:
+			t.pen.fg = int32(p - 30)
+		case p == 39:
+			t.pen.fg = colorDefault
+		case p >= 40 && p <= 47:
+			t.pen.bg = int32(p - 40)
+		case p == 49:
+			t.pen.bg = colorDefault
+		case p >= 90 && p <= 97:
+			t.pen.fg = int32(p - 90 + 8)
+		case p >= 100 && p <= 107:
+			t.pen.bg = int32(p - 100 + 8)
+		case p == 38 || p == 48:
+			c, consumed := extendedColor(params[i+1:])
+			i += consumed
+			if c == colorDefault {
+				continue
+			}
+			if p == 38 {
+				t.pen.fg = c
+			} else {
+				t.pen.bg = c
+			}
+		}
+	}
+}
+
+// extendedColor parses the "5;n" or "2;r;g;b" parameters following 38 or 48
+func extendedColor(params []int) (int32, int) {
+	if len(params) >= 2 && params[0] == 5 {
+		return int32(clamp(params[1], 0, 255)), 2
+	}
+	if len(params) >= 4 && params[0] == 2 {
+		r, g, b := clamp(params[1], 0, 255), clamp(params[2], 0, 255), clamp(params[3], 0, 255)
+		return int32(colorRGBFlag | r<<16 | g<<8 | b), 4
+	}
+	return colorDefault, len(params)
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(hi, v))
+}
+
+// Text returns the screen content as text, trailing spaces trimmed, mostly useful for tests
+func (t *Terminal) Text() string {
+	lines := make([]string, t.rows)
+	for y, row := range t.cells {
+		var b strings.Builder
+		for _, c := range row {
+			b.WriteRune(c.r)
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,155 @@
+package termrender
+
+import (
+	"testing"
+)
+
+func TestTerminalText(t *testing.T) {
+	tests := []struct {
+		name  string
+		cols  int
+		rows  int
+		input string
+		want  string
+	}{
+		{
+			name:  "plain text",
+			cols:  10,
+			rows:  3,
+			input: "hello\r\nworld",
+			want:  "hello\nworld",
+		},
+		{
+			name:  "wrapping",
+			cols:  4,
+			rows:  3,
+			input: "abcdefg",
+			want:  "abcd\nefg",
+		},
+		{
+			name:  "scrolling",
+			cols:  5,
+			rows:  2,
+			input: "one\r\ntwo\r\nthree",
+			want:  "two\nthree",
+		},
+		{
+			name:  "carriage return overwrite",
+			cols:  10,
+			rows:  1,
+			input: "loading\rdone\x1b[K",
+			want:  "done",
+		},
+		{
+			name:  "cursor positioning",
+			cols:  10,
+			rows:  3,
+			input: "\x1b[3;5Hx\x1b[1;1Hy",
+			want:  "y\n\n    x",
+		},
+		{
+			name:  "clear screen",
+			cols:  10,
+			rows:  2,
+			input: "junk\x1b[2J\x1b[Hclean",
+			want:  "clean",
+		},
+		{
+			name:  "backspace and delete chars",
+			cols:  10,
+			rows:  1,
+			input: "abcd\b\b\x1b[P",
+			want:  "abd",
+		},
+		{
+			name:  "colors do not print",
+			cols:  10,
+			rows:  1,
+			input: "\x1b[1;31mred\x1b[0m \x1b[38;2;1;2;3mrgb\x1b[m",
+			want:  "red rgb",
+		},
+		{
+			name:  "window title is ignored",
+			cols:  10,
+			rows:  1,
+			input: "\x1b]0;title\x07ok",
+			want:  "ok",
+		},
+		{
+			name:  "alternate screen is restored",
+			cols:  10,
+			rows:  2,
+			input: "shell\x1b[?1049h\x1b[Hvim\x1b[?1049l",
+			want:  "shell",
+		},
+		{
+			name:  "scroll up and down",
+			cols:  10,
+			rows:  3,
+			input: "a\r\nb\r\nc\x1b[2S\x1b[1T",
+			want:  "\nc",
+		},
+		{
+			name:  "delete lines",
+			cols:  10,
+			rows:  3,
+			input: "a\r\nb\r\nc\x1b[2;1H\x1b[M",
+			want:  "a\nc",
+		},
+		// Huge counts are clamped to the scroll region rather than looped over
+		{
+			name:  "huge scroll up count",
+			cols:  10,
+			rows:  3,
+			input: "a\r\nb\r\nc\x1b[200000000S",
+			want:  "",
+		},
+		{
+			name:  "huge scroll down count",
+			cols:  10,
+			rows:  3,
+			input: "a\r\nb\r\nc\x1b[200000000T",
+			want:  "",
+		},
+		{
+			name:  "huge insert lines count",
+			cols:  10,
+			rows:  3,
+			input: "a\r\nb\r\nc\x1b[2;1H\x1b[200000000L",
+			want:  "a",
+		},
+		{
+			name:  "tab stops",
+			cols:  20,
+			rows:  1,
+			input: "a\tb",
+			want:  "a       b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := NewTerminal(tt.cols, tt.rows)
+			term.Write(tt.input)
+			if got := term.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalSGR(t *testing.T) {
+	term := NewTerminal(10, 1)
+	term.Write("\x1b[1;4;31;42mA\x1b[38;5;200;48;2;10;20;30mB\x1b[0mC")
+
+	a, b, c := term.cells[0][0], term.cells[0][1], term.cells[0][2]
+	if !a.bold || !a.underline || a.fg != 1 || a.bg != 2 {
+		t.Errorf("cell A = %+v, want bold underlined red on green", a)
+	}
+	if b.fg != 200 || b.bg != colorRGBFlag|10<<16|20<<8|30 {
+		t.Errorf("cell B = %+v, want indexed 200 on rgb(10,20,30)", b)
+	}
+	if c.bold || c.underline || c.fg != colorDefault || c.bg != colorDefault {
+		t.Errorf("cell C = %+v, want default attributes", c)
+	}
+}
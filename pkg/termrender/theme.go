@@ -0,0 +1,94 @@
+package termrender
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"github.com/jparrill/go-togif/pkg/overlay"
+)
+
+// Theme holds the colors used to draw the terminal
+type Theme struct {
+	Foreground color.RGBA
+	Background color.RGBA
+	// Palette holds the 16 ANSI colors, normal then bright
+	Palette [16]color.RGBA
+}
+
+// DefaultTheme matches the default asciinema player theme
+var DefaultTheme = mustTheme(Colors{
+	Foreground: "#cccccc",
+	Background: "#121314",
+	Palette: "#000000:#dd3c69:#4ebf22:#ddaf3c:#26b0d7:#b954e1:#54e1b9:#d9d9d9:" +
+		"#4d4d4d:#dd3c69:#4ebf22:#ddaf3c:#26b0d7:#b954e1:#54e1b9:#ffffff",
+})
+
+func mustTheme(c Colors) Theme {
+	theme, err := ParseTheme(c)
+	if err != nil {
+		panic(err)
+	}
+	return theme
+}
+
+// ParseTheme converts the theme of a recording header. The palette may list
+// 8 colors, in which case they are reused for the bright variants.
+func ParseTheme(c Colors) (Theme, error) {
+	var theme Theme
+	var err error
+	if theme.Foreground, err = parseColor(c.Foreground); err != nil {
+		return theme, fmt.Errorf("invalid theme foreground: %v", err)
+	}
+	if theme.Background, err = parseColor(c.Background); err != nil {
+		return theme, fmt.Errorf("invalid theme background: %v", err)
+	}
+
+	entries := strings.Split(c.Palette, ":")
+	if len(entries) != 8 && len(entries) != 16 {
+		return theme, fmt.Errorf("invalid theme palette: expected 8 or 16 colors, got %d", len(entries))
+	}
+	for i := range theme.Palette {
+		if theme.Palette[i], err = parseColor(entries[i%len(entries)]); err != nil {
+			return theme, fmt.Errorf("invalid theme palette: %v", err)
+		}
+	}
+	return theme, nil
+}
+
+func parseColor(s string) (color.RGBA, error) {
+	c, err := overlay.ParseHexColor(s)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return color.RGBAModel.Convert(c).(color.RGBA), nil
+}
+
+// Color resolves a cell color: the theme default, an indexed color of the
+// xterm 256 color palette or a 24-bit value
+func (t Theme) Color(c int32, foreground bool) color.RGBA {
+	switch {
+	case c == colorDefault && foreground:
+		return t.Foreground
+	case c == colorDefault:
+		return t.Background
+	case c&colorRGBFlag != 0:
+		return color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xff}
+	case c < 16:
+		return t.Palette[c]
+	case c < 232:
+		// 6x6x6 color cube
+		c -= 16
+		level := func(v int32) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return color.RGBA{R: level(c / 36), G: level(c / 6 % 6), B: level(c % 6), A: 0xff}
+	default:
+		// Grayscale ramp
+		v := uint8(8 + (c-232)*10)
+		return color.RGBA{R: v, G: v, B: v, A: 0xff}
+	}
+}
@@ -0,0 +1,101 @@
+package testsupport
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+)
+
+// Expect lists the properties a GIF must have, unset ones are not checked
+type Expect struct {
+	// Frames is the number of frames
+	Frames int
+	// Delays is the delay of every frame
+	Delays []time.Duration
+	// Width and Height are the size of the logical screen
+	Width  int
+	Height int
+	// MaxColors is the most distinct colors the displayed frames may show
+	MaxColors int
+	// MinBytes and MaxBytes bound the file size
+	MinBytes int64
+	MaxBytes int64
+}
+
+// CheckGIF returns how the GIF at path differs from want, nil when it
+// matches
+func CheckGIF(path string, want Expect) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error checking GIF: %v", err)
+	}
+	reader, err := gifutil.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if want.MinBytes > 0 && info.Size() < want.MinBytes {
+		problems = append(problems, fmt.Sprintf("size is %d bytes, want at least %d", info.Size(), want.MinBytes))
+	}
+	if want.MaxBytes > 0 && info.Size() > want.MaxBytes {
+		problems = append(problems, fmt.Sprintf("size is %d bytes, want at most %d", info.Size(), want.MaxBytes))
+	}
+	if want.Frames > 0 && reader.Len() != want.Frames {
+		problems = append(problems, fmt.Sprintf("has %d frames, want %d", reader.Len(), want.Frames))
+	}
+	bounds := reader.Bounds()
+	if (want.Width > 0 && bounds.Dx() != want.Width) || (want.Height > 0 && bounds.Dy() != want.Height) {
+		problems = append(problems, fmt.Sprintf("is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), want.Width, want.Height))
+	}
+	if want.Delays != nil && len(want.Delays) != reader.Len() {
+		problems = append(problems, fmt.Sprintf("has %d frames, want %d delays", reader.Len(), len(want.Delays)))
+	}
+
+	colors := make(map[color.RGBA]bool)
+	for {
+		frame, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if frame.Index < len(want.Delays) && frame.Delay != want.Delays[frame.Index] {
+			problems = append(problems, fmt.Sprintf("frame %d is shown for %v, want %v", frame.Index, frame.Delay, want.Delays[frame.Index]))
+		}
+		if want.MaxColors > 0 {
+			addColors(colors, frame.Image)
+		}
+	}
+	if want.MaxColors > 0 && len(colors) > want.MaxColors {
+		problems = append(problems, fmt.Sprintf("shows %d colors, want at most %d", len(colors), want.MaxColors))
+	}
+	return problems, nil
+}
+
+// AssertGIF fails the test, listing every difference, unless the GIF at
+// path matches want
+func AssertGIF(tb testing.TB, path string, want Expect) {
+	tb.Helper()
+	problems, err := CheckGIF(path, want)
+	if err != nil {
+		tb.Fatalf("testsupport: %v", err)
+	}
+	for _, problem := range problems {
+		tb.Errorf("%s %s", path, problem)
+	}
+}
+
+// addColors adds the colors of the pixels of img to colors
+func addColors(colors map[color.RGBA]bool, img *image.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		colors[color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}] = true
+	}
+}
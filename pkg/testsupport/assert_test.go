@@ -0,0 +1,63 @@
+package testsupport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/generate"
+)
+
+func TestAssertGIF(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputFile := filepath.Join(tempDir, "bars.gif")
+	if _, err := converter.ConvertFrames(Frames(t, generate.PatternBars, 3, 40, 20), outputFile, converter.Options{Delay: 50}); err != nil {
+		t.Fatalf("ConvertFrames() error = %v", err)
+	}
+	delays := []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+
+	tests := []struct {
+		name string
+		want Expect
+		// wantErrors are parts of the expected failures, in order
+		wantErrors []string
+	}{
+		{name: "matching", want: Expect{Frames: 3, Delays: delays, Width: 40, Height: 20, MaxColors: 8, MinBytes: 100, MaxBytes: 10000}},
+		{name: "nothing checked", want: Expect{}},
+		{name: "frame count", want: Expect{Frames: 4}, wantErrors: []string{"has 3 frames, want 4"}},
+		{name: "delays", want: Expect{Delays: []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond}}, wantErrors: []string{"frame 2 is shown for 50ms, want 100ms"}},
+		{name: "delay count", want: Expect{Delays: delays[:2]}, wantErrors: []string{"want 2 delays"}},
+		{name: "size", want: Expect{Width: 40, Height: 30}, wantErrors: []string{"is 40x20, want 40x30"}},
+		{name: "colors", want: Expect{MaxColors: 4}, wantErrors: []string{"shows 8 colors, want at most 4"}},
+		{name: "file size", want: Expect{MinBytes: 1 << 20, MaxBytes: 10}, wantErrors: []string{"want at least", "want at most"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			AssertGIF(rec, outputFile, tt.want)
+			if len(rec.errors) != len(tt.wantErrors) {
+				t.Fatalf("AssertGIF() errors = %q, want %d", rec.errors, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				if !strings.Contains(rec.errors[i], want) {
+					t.Errorf("error %d = %q, want %q", i, rec.errors[i], want)
+				}
+			}
+		})
+	}
+
+	rec := &recorder{}
+	AssertGIF(rec, filepath.Join(tempDir, "missing.gif"), Expect{})
+	if !rec.fatal {
+		t.Errorf("AssertGIF() of a missing file did not fail the test")
+	}
+}
@@ -0,0 +1,72 @@
+// Package testsupport helps programs embedding go-togif write regression
+// tests: it generates deterministic frame sets and checks the GIFs produced
+// from them against expectations or golden files
+package testsupport
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/generate"
+)
+
+// Frames returns count frames of the given generate test pattern, e.g.
+// generate.PatternBars, failing the test on invalid settings
+func Frames(tb testing.TB, pattern string, count, width, height int) []converter.Frame {
+	tb.Helper()
+	frames, err := generate.TestPattern(generate.TestPatternOptions{Pattern: pattern, Width: width, Height: height, Frames: count})
+	if err != nil {
+		tb.Fatalf("testsupport: %v", err)
+	}
+	return frames
+}
+
+// NoiseFrames returns count frames of random opaque pixels, the same for the
+// same seed; they use far more than 256 colors, like photos
+func NoiseFrames(seed int64, count, width, height int) []converter.Frame {
+	rng := rand.New(rand.NewSource(seed))
+	frames := make([]converter.Frame, count)
+	for i := range frames {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		rng.Read(img.Pix)
+		for p := 3; p < len(img.Pix); p += 4 {
+			img.Pix[p] = 0xff
+		}
+		frames[i] = converter.Frame{Image: img}
+	}
+	return frames
+}
+
+// WritePNGs writes frames to dir as frame-000.png, frame-001.png... and
+// returns their paths, in order, to test conversions of files
+func WritePNGs(tb testing.TB, dir string, frames []converter.Frame) []string {
+	tb.Helper()
+	paths := make([]string, len(frames))
+	for i, frame := range frames {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("frame-%03d.png", i))
+		if err := writePNG(paths[i], frame.Image); err != nil {
+			tb.Fatalf("testsupport: %v", err)
+		}
+	}
+	return paths
+}
+
+// writePNG encodes img to path
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("error encoding PNG %s: %v", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,84 @@
+package testsupport
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/generate"
+)
+
+// recorder records the failures of a test instead of failing it
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Logf(format string, args ...any) {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf records the failure, the helper under test must not go on after it
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestFrames(t *testing.T) {
+	frames := Frames(t, generate.PatternBars, 4, 32, 16)
+	if len(frames) != 4 || frames[0].Image.Bounds().Dx() != 32 || frames[0].Image.Bounds().Dy() != 16 {
+		t.Fatalf("Frames() = %d frames of %v, want 4 of 32x16", len(frames), frames[0].Image.Bounds())
+	}
+
+	rec := &recorder{}
+	Frames(rec, "plaid", 4, 32, 16)
+	if !rec.fatal {
+		t.Errorf("Frames() with an unknown pattern did not fail the test")
+	}
+}
+
+func TestNoiseFrames(t *testing.T) {
+	a, b, c := NoiseFrames(1, 2, 8, 8), NoiseFrames(1, 2, 8, 8), NoiseFrames(2, 2, 8, 8)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("NoiseFrames() differs for the same seed")
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("NoiseFrames() is the same for different seeds")
+	}
+	if _, _, _, alpha := a[1].Image.At(3, 5).RGBA(); alpha != 0xffff {
+		t.Errorf("NoiseFrames() alpha = %d, want opaque", alpha)
+	}
+}
+
+func TestWritePNGs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	frames := NoiseFrames(1, 3, 4, 4)
+	paths := WritePNGs(t, tempDir, frames)
+	if len(paths) != 3 {
+		t.Fatalf("WritePNGs() = %v, want 3 paths", paths)
+	}
+	file, err := os.Open(paths[2])
+	if err != nil {
+		t.Fatalf("Failed to open frame: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode frame: %v", err)
+	}
+	if img.At(1, 2) != frames[2].Image.At(1, 2) {
+		t.Errorf("frame 2 pixel = %v, want %v", img.At(1, 2), frames[2].Image.At(1, 2))
+	}
+}
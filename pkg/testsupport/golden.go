@@ -0,0 +1,99 @@
+package testsupport
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/gifutil"
+	"github.com/jparrill/go-togif/pkg/quality"
+)
+
+// UpdateEnv is the environment variable which, set to 1, makes AssertGolden
+// replace the golden files with the GIFs under test
+const UpdateEnv = "GOTOGIF_UPDATE_GOLDEN"
+
+// GoldenMinPSNR is the PSNR, in dB, below which a frame differs from its
+// golden frame; it tolerates the rounding differences of encoder changes
+const GoldenMinPSNR = 40
+
+// CompareGolden returns how the GIF at path differs from the golden GIF, nil
+// when they show the same frames for the same time
+func CompareGolden(path, golden string) ([]string, error) {
+	got, err := gifutil.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	want, err := gifutil.Open(golden)
+	if err != nil {
+		return nil, err
+	}
+
+	if got.Len() != want.Len() {
+		return []string{fmt.Sprintf("has %d frames, golden has %d", got.Len(), want.Len())}, nil
+	}
+	if got.Bounds() != want.Bounds() {
+		return []string{fmt.Sprintf("is %dx%d, golden is %dx%d", got.Bounds().Dx(), got.Bounds().Dy(), want.Bounds().Dx(), want.Bounds().Dy())}, nil
+	}
+
+	var problems []string
+	for {
+		gotFrame, err := got.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		wantFrame, err := want.Next()
+		if err != nil {
+			return nil, err
+		}
+		if gotFrame.Delay != wantFrame.Delay {
+			problems = append(problems, fmt.Sprintf("frame %d is shown for %v, golden for %v", gotFrame.Index, gotFrame.Delay, wantFrame.Delay))
+		}
+		if psnr := quality.PSNR(gotFrame.Image, wantFrame.Image); psnr < GoldenMinPSNR {
+			problems = append(problems, fmt.Sprintf("frame %d differs from golden, PSNR %.1f dB", gotFrame.Index, psnr))
+		}
+	}
+	return problems, nil
+}
+
+// AssertGolden fails the test unless the GIF at path shows the same frames as
+// the golden GIF. With GOTOGIF_UPDATE_GOLDEN=1, it writes path to golden
+// instead, creating its directory.
+func AssertGolden(tb testing.TB, path, golden string) {
+	tb.Helper()
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := copyFile(path, golden); err != nil {
+			tb.Fatalf("testsupport: %v", err)
+		}
+		tb.Logf("updated golden file %s", golden)
+		return
+	}
+
+	problems, err := CompareGolden(path, golden)
+	if err != nil {
+		tb.Fatalf("testsupport: %v (run with %s=1 to create the golden file)", err, UpdateEnv)
+	}
+	for _, problem := range problems {
+		tb.Errorf("%s %s", path, problem)
+	}
+}
+
+// copyFile copies src to dst
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %v", src, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("error creating directory: %v", err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("error writing file %s: %v", dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package testsupport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/generate"
+)
+
+func TestAssertGolden(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "go-togif-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	convert := func(name string, frames []converter.Frame, opts converter.Options) string {
+		path := filepath.Join(tempDir, name)
+		if _, err := converter.ConvertFrames(frames, path, opts); err != nil {
+			t.Fatalf("ConvertFrames() error = %v", err)
+		}
+		return path
+	}
+	box := Frames(t, generate.PatternBox, 3, 32, 16)
+	output := convert("box.gif", box, converter.Options{Delay: 100})
+	golden := filepath.Join(tempDir, "testdata", "box.golden.gif")
+
+	// A missing golden file tells how to create it
+	rec := &recorder{}
+	AssertGolden(rec, output, golden)
+	if !rec.fatal || !strings.Contains(rec.errors[0], UpdateEnv) {
+		t.Fatalf("AssertGolden() without golden file errors = %q, want a fatal hint at %s", rec.errors, UpdateEnv)
+	}
+
+	t.Setenv(UpdateEnv, "1")
+	AssertGolden(t, output, golden)
+	if _, err := os.Stat(golden); err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	t.Setenv(UpdateEnv, "")
+
+	tests := []struct {
+		name       string
+		path       string
+		wantErrors []string
+	}{
+		{name: "same", path: output},
+		{name: "other quantizer", path: convert("median-cut.gif", box, converter.Options{Delay: 100, Quantizer: converter.QuantizerMedianCut})},
+		{name: "slower", path: convert("slower.gif", box, converter.Options{Delay: 200}), wantErrors: []string{"frame 0 is shown for 200ms", "frame 1", "frame 2"}},
+		{name: "other frames", path: convert("bars.gif", Frames(t, generate.PatternBars, 3, 32, 16), converter.Options{Delay: 100}), wantErrors: []string{"frame 0 differs", "frame 1 differs", "frame 2 differs"}},
+		{name: "fewer frames", path: convert("short.gif", box[:2], converter.Options{Delay: 100}), wantErrors: []string{"has 2 frames, golden has 3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			AssertGolden(rec, tt.path, golden)
+			if len(rec.errors) != len(tt.wantErrors) {
+				t.Fatalf("AssertGolden() errors = %q, want %d", rec.errors, len(tt.wantErrors))
+			}
+			for i, want := range tt.wantErrors {
+				if !strings.Contains(rec.errors[i], want) {
+					t.Errorf("error %d = %q, want %q", i, rec.errors[i], want)
+				}
+			}
+		})
+	}
+}
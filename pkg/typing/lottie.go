@@ -0,0 +1,122 @@
+package typing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/jparrill/go-togif/pkg/lottie"
+)
+
+// lottieFPS is the frame rate keyframes of Lottie exports are timed at
+const lottieFPS = 60
+
+// Fonts of the text layers of Lottie exports, the embedded fonts of the GIF
+// when the player has them installed
+var (
+	lottieRegular = lottie.Font{Name: "GoMono", Family: "Go Mono, monospace", Style: "Regular", Ascent: 75}
+	lottieBold    = lottie.Font{Name: "GoMono-Bold", Family: "Go Mono, monospace", Style: "Bold", Ascent: 75}
+)
+
+// run is text of a single line and style, typed a character at a time
+type run struct {
+	col, row int
+	color    color.RGBA
+	bold     bool
+	text     string
+	keys     []lottie.TextKey
+}
+
+// Lottie returns the typing animation of text as a vector Lottie animation,
+// timed like the frames of Generate: a text layer per highlighted run of a
+// line, revealing its characters as they are typed, and a cursor layer
+func Lottie(text string, opts Options) (*lottie.Animation, error) {
+	s, opts, err := prepare(text, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*run
+	var cursor []lottie.PositionKey
+	var current *run
+	col, row, typed := 0, 0, 0
+	for _, token := range s.tokens {
+		entry := s.style.Get(token.Type)
+		c := s.theme.Foreground
+		if entry.Colour.IsSet() {
+			c = color.RGBA{R: entry.Colour.Red(), G: entry.Colour.Green(), B: entry.Colour.Blue(), A: 0xff}
+		}
+		bold := entry.Bold == chroma.Yes
+		for _, r := range token.Value {
+			at := time.Duration(typed) * s.charDelay
+			typed++
+			if r == '\n' {
+				current = nil
+				col, row = 0, row+1
+			} else {
+				if current == nil || current.color != c || current.bold != bold {
+					current = &run{col: col, row: row, color: c, bold: bold}
+					runs = append(runs, current)
+				}
+				current.text += string(r)
+				current.keys = append(current.keys, lottie.TextKey{At: at, Text: current.text})
+				col++
+			}
+			cursor = append(cursor, lottie.PositionKey{At: at, Offset: s.renderer.Cell(min(col, s.cols-1), row).Min.Sub(s.renderer.Cell(0, 0).Min)})
+		}
+	}
+
+	// The last frame is held when the cursor does not blink
+	typingEnd := time.Duration(typed) * s.charDelay
+	duration := typingEnd + opts.Hold
+	if opts.NoBlink {
+		duration -= s.charDelay
+	}
+
+	bounds := s.renderer.Bounds(s.cols, s.rows)
+	anim := lottie.New("typing", bounds.Dx(), bounds.Dy(), duration, lottieFPS)
+	anim.AddShapes("background", []lottie.Shape{{Rect: bounds, Color: s.theme.Background}})
+	for _, r := range runs {
+		if strings.TrimSpace(r.text) == "" {
+			continue
+		}
+		font := lottieRegular
+		if r.bold {
+			font = lottieBold
+		}
+		at := s.renderer.Cell(r.col, r.row).Min.Add(image.Pt(0, s.renderer.Baseline()))
+		layer := anim.AddText(runName(r), font, opts.FontSize, r.color, at, r.keys)
+		anim.Show(layer, r.keys[0].At, duration)
+	}
+
+	// The cursor is a block in the foreground color, blinking once the snippet is typed
+	block := anim.AddShapes("cursor", []lottie.Shape{{Rect: s.renderer.Cell(0, 0), Color: s.theme.Foreground}})
+	anim.Move(block, cursor)
+	if !opts.NoBlink {
+		keys := []lottie.OpacityKey{{At: 0, Opacity: 100}}
+		visible := true
+		for held := time.Duration(0); held < opts.Hold; held += blinkInterval {
+			visible = !visible
+			key := lottie.OpacityKey{At: typingEnd + held}
+			if visible {
+				key.Opacity = 100
+			}
+			keys = append(keys, key)
+		}
+		anim.Fade(block, keys)
+	}
+	return anim, nil
+}
+
+// runName names the layer of r after its line and text, like the layers of
+// animation tools
+func runName(r *run) string {
+	name := []rune(strings.TrimSpace(r.text))
+	if len(name) > 20 {
+		name = name[:20]
+	}
+	return fmt.Sprintf("line %d: %s", r.row+1, string(name))
+}
@@ -0,0 +1,59 @@
+package typing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLottie(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         Options
+		wantDuration time.Duration
+	}{
+		// 5 characters including the line break at 20 per second, then the hold
+		{name: "blinking", opts: Options{Language: "text", Hold: time.Second}, wantDuration: 250*time.Millisecond + time.Second},
+		// The last character is held instead
+		{name: "no blink", opts: Options{Language: "text", Hold: time.Second, NoBlink: true}, wantDuration: 200*time.Millisecond + time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anim, err := Lottie("ab\ncd", tt.opts)
+			if err != nil {
+				t.Fatalf("Lottie() error = %v", err)
+			}
+			if want := tt.wantDuration.Seconds() * lottieFPS; anim.OutPoint != want {
+				t.Errorf("out point = %v, want %v", anim.OutPoint, want)
+			}
+
+			var names []string
+			for _, layer := range anim.Layers {
+				names = append(names, layer.Name)
+			}
+			want := []string{"cursor", "line 2: cd", "line 1: ab", "background"}
+			if len(names) != len(want) {
+				t.Fatalf("layers = %v, want %v", names, want)
+			}
+			for i := range want {
+				if names[i] != want[i] {
+					t.Errorf("layer %d = %q, want %q", i, names[i], want[i])
+				}
+			}
+
+			// The second line appears when its first character is typed
+			if in := anim.Layers[1].InPoint; in != 0.15*lottieFPS {
+				t.Errorf("second line shown from frame %v, want %v", in, 0.15*lottieFPS)
+			}
+		})
+	}
+}
+
+func TestLottieErrors(t *testing.T) {
+	if _, err := Lottie("   ", Options{}); err == nil {
+		t.Error("Lottie() error = nil without text")
+	}
+	if _, err := Lottie("x", Options{Style: "nope"}); err == nil {
+		t.Error("Lottie() error = nil for an unknown style")
+	}
+}
@@ -0,0 +1,205 @@
+package typing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/jparrill/go-togif/pkg/converter"
+	"github.com/jparrill/go-togif/pkg/termrender"
+)
+
+const (
+	// DefaultSpeed is the typing speed in characters per second
+	DefaultSpeed = 20
+	// DefaultStyle is the chroma style used when Options.Style is unset
+	DefaultStyle = "monokai"
+	// DefaultHold is how long the finished snippet stays visible
+	DefaultHold = 2 * time.Second
+	// blinkInterval is how long the cursor stays on or off while blinking
+	blinkInterval = 500 * time.Millisecond
+	// tabWidth is the number of spaces a tab is expanded to
+	tabWidth = 4
+)
+
+// Options holds the settings used by Generate
+type Options struct {
+	// Language is the chroma lexer name, detected from Filename or the text when empty
+	Language string
+	// Filename helps detecting the language, e.g. "main.go"
+	Filename string
+	// Style is the chroma style name
+	Style string
+	// Speed is the number of characters typed per second
+	Speed float64
+	// FontSize is the font size in pixels
+	FontSize float64
+	// Hold is how long the finished snippet is shown before the animation loops
+	Hold time.Duration
+	// NoBlink keeps the cursor solid while the finished snippet is shown
+	NoBlink bool
+}
+
+// snippet is a highlighted text ready to be typed
+type snippet struct {
+	tokens   []chroma.Token
+	style    *chroma.Style
+	theme    termrender.Theme
+	renderer *termrender.Renderer
+	// cols and rows fit the snippet plus the cursor after the longest line
+	cols, rows int
+	charDelay  time.Duration
+}
+
+// prepare fills in the unset settings of opts and highlights text
+func prepare(text string, opts Options) (*snippet, Options, error) {
+	if opts.Speed == 0 {
+		opts.Speed = DefaultSpeed
+	}
+	if opts.Speed < 0 {
+		return nil, opts, fmt.Errorf("speed must be positive")
+	}
+	if opts.FontSize == 0 {
+		opts.FontSize = termrender.DefaultFontSize
+	}
+	if opts.Hold == 0 {
+		opts.Hold = DefaultHold
+	}
+	if opts.Style == "" {
+		opts.Style = DefaultStyle
+	}
+
+	text = normalize(text)
+	if text == "" {
+		return nil, opts, fmt.Errorf("no text to type")
+	}
+
+	lexer, err := findLexer(text, opts)
+	if err != nil {
+		return nil, opts, err
+	}
+	style, ok := styles.Registry[opts.Style]
+	if !ok {
+		return nil, opts, fmt.Errorf("unknown style %q", opts.Style)
+	}
+
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, text)
+	if err != nil {
+		return nil, opts, fmt.Errorf("error highlighting text: %v", err)
+	}
+
+	colors := theme(style)
+	renderer, err := termrender.NewRenderer(opts.FontSize, colors)
+	if err != nil {
+		return nil, opts, err
+	}
+
+	s := &snippet{tokens: tokens.Tokens(), style: style, theme: colors, renderer: renderer, cols: 1}
+	for _, line := range strings.Split(text, "\n") {
+		s.cols = max(s.cols, utf8.RuneCountInString(line)+1)
+		s.rows++
+	}
+	s.charDelay = time.Duration(float64(time.Second) / opts.Speed)
+	return s, opts, nil
+}
+
+// Generate synthesizes a typing animation of text, one frame per typed character
+func Generate(text string, opts Options) ([]converter.Frame, error) {
+	s, opts, err := prepare(text, opts)
+	if err != nil {
+		return nil, err
+	}
+	term := termrender.NewTerminal(s.cols, s.rows)
+
+	var frames []converter.Frame
+	for _, token := range s.tokens {
+		sgr := sgrFor(s.style.Get(token.Type))
+		for _, r := range token.Value {
+			if r == '\n' {
+				term.Write("\r\n")
+			} else {
+				term.Write(sgr + string(r))
+			}
+			frames = append(frames, converter.Frame{Image: s.renderer.Draw(term), Delay: s.charDelay})
+		}
+	}
+
+	// Show the finished snippet, blinking the cursor
+	if opts.NoBlink {
+		frames[len(frames)-1].Delay = opts.Hold
+		return frames, nil
+	}
+	visible := true
+	for held := time.Duration(0); held < opts.Hold; held += blinkInterval {
+		visible = !visible
+		if visible {
+			term.Write("\x1b[?25h")
+		} else {
+			term.Write("\x1b[?25l")
+		}
+		frames = append(frames, converter.Frame{
+			Image: s.renderer.Draw(term),
+			Delay: min(blinkInterval, opts.Hold-held),
+		})
+	}
+	return frames, nil
+}
+
+// normalize converts line endings and tabs, dropping trailing blank lines
+func normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", strings.Repeat(" ", tabWidth))
+	return strings.TrimRight(text, "\n ")
+}
+
+// findLexer returns the lexer for the configured language, detecting it when unset
+func findLexer(text string, opts Options) (chroma.Lexer, error) {
+	if opts.Language != "" {
+		lexer := lexers.Get(opts.Language)
+		if lexer == nil {
+			return nil, fmt.Errorf("unknown language %q", opts.Language)
+		}
+		return lexer, nil
+	}
+	if opts.Filename != "" {
+		if lexer := lexers.Match(opts.Filename); lexer != nil {
+			return lexer, nil
+		}
+	}
+	if lexer := lexers.Analyse(text); lexer != nil {
+		return lexer, nil
+	}
+	return lexers.Fallback, nil
+}
+
+// theme builds a terminal theme from the background entry of a chroma style
+func theme(style *chroma.Style) termrender.Theme {
+	t := termrender.DefaultTheme
+	entry := style.Get(chroma.Background)
+	if entry.Colour.IsSet() {
+		t.Foreground.R, t.Foreground.G, t.Foreground.B = entry.Colour.Red(), entry.Colour.Green(), entry.Colour.Blue()
+	}
+	if entry.Background.IsSet() {
+		t.Background.R, t.Background.G, t.Background.B = entry.Background.Red(), entry.Background.Green(), entry.Background.Blue()
+	}
+	return t
+}
+
+// sgrFor returns the escape sequence selecting the colors and attributes of a style entry
+func sgrFor(entry chroma.StyleEntry) string {
+	params := []string{"0"}
+	if entry.Bold == chroma.Yes {
+		params = append(params, "1")
+	}
+	if entry.Underline == chroma.Yes {
+		params = append(params, "4")
+	}
+	if entry.Colour.IsSet() {
+		params = append(params, fmt.Sprintf("38;2;%d;%d;%d", entry.Colour.Red(), entry.Colour.Green(), entry.Colour.Blue()))
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
@@ -0,0 +1,97 @@
+package typing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+func TestGenerate(t *testing.T) {
+	text := "package main\n\nfunc main() {}\n"
+	frames, err := Generate(text, Options{Filename: "main.go", Speed: 10, Hold: time.Second, FontSize: 12})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// One frame per character, then two blink frames
+	typed := len(strings.TrimRight(text, "\n"))
+	if len(frames) != typed+2 {
+		t.Fatalf("Generate() got %d frames, want %d", len(frames), typed+2)
+	}
+	if frames[0].Delay != 100*time.Millisecond {
+		t.Errorf("typing delay = %v, want 100ms", frames[0].Delay)
+	}
+	if last := frames[len(frames)-1].Delay; last != blinkInterval {
+		t.Errorf("blink delay = %v, want %v", last, blinkInterval)
+	}
+
+	// All frames share the size of the finished snippet
+	bounds := frames[0].Image.Bounds()
+	for i, frame := range frames {
+		if frame.Image.Bounds() != bounds {
+			t.Fatalf("frame %d bounds = %v, want %v", i, frame.Image.Bounds(), bounds)
+		}
+	}
+}
+
+func TestGenerateNoBlink(t *testing.T) {
+	frames, err := Generate("hi", Options{Language: "text", NoBlink: true, Hold: 3 * time.Second})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("Generate() got %d frames, want 2", len(frames))
+	}
+	if frames[1].Delay != 3*time.Second {
+		t.Errorf("last frame delay = %v, want 3s", frames[1].Delay)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+	}{
+		{"empty text", " \n", Options{}},
+		{"unknown language", "x", Options{Language: "not-a-language"}},
+		{"unknown style", "x", Options{Style: "not-a-style"}},
+		{"negative speed", "x", Options{Speed: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.text, tt.opts); err == nil {
+				t.Error("Generate() expected error")
+			}
+		})
+	}
+}
+
+func TestSGRFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry chroma.StyleEntry
+		want  string
+	}{
+		{"plain", chroma.StyleEntry{}, "\x1b[0m"},
+		{"bold color", chroma.StyleEntry{Bold: chroma.Yes, Colour: chroma.NewColour(255, 0, 16)}, "\x1b[0;1;38;2;255;0;16m"},
+		{"underline", chroma.StyleEntry{Underline: chroma.Yes}, "\x1b[0;4m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sgrFor(tt.entry); got != tt.want {
+				t.Errorf("sgrFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := normalize("a\r\n\tb\n\n"); got != "a\n    b" {
+		t.Errorf("normalize() = %q, want %q", got, "a\n    b")
+	}
+}
@@ -125,9 +125,9 @@ func (m model) View() string {
 			for i, file := range m.processedFiles[:m.totalFiles] {
 				// Remove the "temp/" prefix for cleaner output
 				displayFile := strings.TrimPrefix(file, "temp/")
-				if displayFile == file && len(file) > 50 {
+				if displayFile == file {
 					// If it's not in temp/ and the path is too long, truncate it
-					displayFile = "..." + file[len(file)-47:]
+					displayFile = truncateLeft(file, 50)
 				}
 
 				indexStr := fmt.Sprintf("%*d", maxIndexWidth, i+1)
@@ -156,6 +156,16 @@ func (m model) View() string {
 	return s.String()
 }
 
+// truncateLeft shortens s to at most width characters by replacing its start
+// with "...", counting runes so multi-byte characters are never split
+func truncateLeft(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return "..." + string(runes[len(runes)-(width-3):])
+}
+
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
 		return tickMsg(t)
@@ -164,8 +174,17 @@ func tickCmd() tea.Cmd {
 
 // RunUI starts the UI and returns a channel to send progress updates
 func RunUI(debug bool, totalFiles int) chan ProgressMsg {
+	progressChan, _ := StartUI(debug, totalFiles)
+	return progressChan
+}
+
+// StartUI starts the UI and returns a channel to send progress updates,
+// along with a channel that is closed once the UI has exited
+func StartUI(debug bool, totalFiles int) (chan ProgressMsg, <-chan struct{}) {
 	progressChan := make(chan ProgressMsg)
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		p := tea.NewProgram(initialModel(debug, totalFiles))
 		go func() {
 			for msg := range progressChan {
@@ -176,5 +195,5 @@ func RunUI(debug bool, totalFiles int) chan ProgressMsg {
 			fmt.Printf("Error running UI: %v\n", err)
 		}
 	}()
-	return progressChan
+	return progressChan, done
 }
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -193,3 +194,29 @@ func TestModelView(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestTruncateLeft(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{name: "short", input: "frame.png", width: 50, want: "frame.png"},
+		{name: "ascii", input: "/very/long/path/frame.png", width: 12, want: "...frame.png"},
+		{name: "multi-byte runes", input: "/captures/été/フレーム-001.png", width: 15, want: "...フレーム-001.png"},
+		{name: "exact width", input: "ÉÉÉÉÉ", width: 5, want: "ÉÉÉÉÉ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateLeft(tt.input, tt.width)
+			if got != tt.want {
+				t.Errorf("truncateLeft(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateLeft(%q, %d) = %q is not valid UTF-8", tt.input, tt.width, got)
+			}
+		})
+	}
+}